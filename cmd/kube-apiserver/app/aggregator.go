@@ -280,6 +280,10 @@ var apiVersionPriorities = map[schema.GroupVersion]priority{
 	{Group: "auditregistration.k8s.io", Version: "v1alpha1"}:    {group: 16400, version: 1},
 	{Group: "node.k8s.io", Version: "v1alpha1"}:                 {group: 16300, version: 1},
 	{Group: "node.k8s.io", Version: "v1beta1"}:                  {group: 16300, version: 9},
+	{Group: "transaction.k8s.io", Version: "v1alpha1"}:          {group: 16200, version: 9},
+	{Group: "migration.k8s.io", Version: "v1alpha1"}:            {group: 16100, version: 9},
+	{Group: "usage.k8s.io", Version: "v1alpha1"}:                {group: 16000, version: 9},
+	{Group: "trash.k8s.io", Version: "v1alpha1"}:                {group: 15900, version: 9},
 	// Append a new group to the end of the list if unsure.
 	// You can use min(existing group)-100 as the initial value for a group.
 	// Version can be set to 9 (to have space around) for a new group.
@@ -283,15 +283,19 @@ func CreateKubeAPIServerConfig(
 	var genericConfig *genericapiserver.Config
 	var storageFactory *serverstorage.DefaultStorageFactory
 	var versionedInformers clientgoinformers.SharedInformerFactory
-	genericConfig, versionedInformers, insecureServingInfo, serviceResolver, pluginInitializers, admissionPostStartHook, storageFactory, lastErr = buildGenericConfig(s.ServerRunOptions, proxyTransport)
+	var encryptionConfigPostStartHook genericapiserver.PostStartHookFunc
+	genericConfig, versionedInformers, insecureServingInfo, serviceResolver, pluginInitializers, admissionPostStartHook, storageFactory, encryptionConfigPostStartHook, lastErr = buildGenericConfig(s.ServerRunOptions, proxyTransport)
 	if lastErr != nil {
 		return
 	}
 
-	if _, port, err := net.SplitHostPort(s.Etcd.StorageConfig.Transport.ServerList[0]); err == nil && port != "0" && len(port) != 0 {
-		if err := utilwait.PollImmediate(etcdRetryInterval, etcdRetryLimit*etcdRetryInterval, preflight.EtcdConnection{ServerList: s.Etcd.StorageConfig.Transport.ServerList}.CheckEtcdServers); err != nil {
-			lastErr = fmt.Errorf("error waiting for etcd connection: %v", err)
-			return
+	// the embedded storage backend has no servers to wait for
+	if len(s.Etcd.StorageConfig.Transport.ServerList) > 0 {
+		if _, port, err := net.SplitHostPort(s.Etcd.StorageConfig.Transport.ServerList[0]); err == nil && port != "0" && len(port) != 0 {
+			if err := utilwait.PollImmediate(etcdRetryInterval, etcdRetryLimit*etcdRetryInterval, preflight.EtcdConnection{ServerList: s.Etcd.StorageConfig.Transport.ServerList}.CheckEtcdServers); err != nil {
+				lastErr = fmt.Errorf("error waiting for etcd connection: %v", err)
+				return
+			}
 		}
 	}
 
@@ -334,6 +338,8 @@ func CreateKubeAPIServerConfig(
 
 			APIResourceConfigSource: storageFactory.APIResourceConfigSource,
 			StorageFactory:          storageFactory,
+
+			EncryptionConfigPostStartHook: encryptionConfigPostStartHook,
 			EventTTL:                s.EventTTL,
 			KubeletClientConfig:     s.KubeletConfig,
 			EnableLogsSupport:       s.EnableLogsHandler,
@@ -378,6 +384,7 @@ func buildGenericConfig(
 	pluginInitializers []admission.PluginInitializer,
 	admissionPostStartHook genericapiserver.PostStartHookFunc,
 	storageFactory *serverstorage.DefaultStorageFactory,
+	encryptionConfigPostStartHook genericapiserver.PostStartHookFunc,
 	lastErr error,
 ) {
 	genericConfig = genericapiserver.NewConfig(legacyscheme.Codecs)
@@ -424,6 +431,10 @@ func buildGenericConfig(
 	if lastErr != nil {
 		return
 	}
+	encryptionConfigPostStartHook = completedStorageFactoryConfig.EncryptionConfigPostStartHook()
+	if kmsHealthzChecker := completedStorageFactoryConfig.KMSHealthzChecker(); kmsHealthzChecker != nil {
+		genericConfig.HealthzChecks = append(genericConfig.HealthzChecks, kmsHealthzChecker)
+	}
 	if lastErr = s.Etcd.ApplyWithStorageFactoryTo(storageFactory, genericConfig); lastErr != nil {
 		return
 	}
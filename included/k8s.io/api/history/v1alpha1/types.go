@@ -0,0 +1,68 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ObjectHistory is the history of an object: its revisions replaced by
+// updates and deletions that are still retained, oldest first. It is served
+// by the history subresource of the resources whose history is kept.
+type ObjectHistory struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata, with the name and namespace of the object.
+	// More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Revisions are the retained revisions of the object, oldest first.
+	Revisions []ObjectRevision `json:"revisions"`
+}
+
+// RevisionOperation is the operation that replaced a revision.
+type RevisionOperation string
+
+const (
+	// RevisionOperationUpdate is an update of the object.
+	RevisionOperationUpdate RevisionOperation = "Update"
+	// RevisionOperationDelete is the deletion of the object.
+	RevisionOperationDelete RevisionOperation = "Delete"
+)
+
+// ObjectRevision is a revision of an object, and the operation that replaced
+// it.
+type ObjectRevision struct {
+	// ResourceVersion is the resourceVersion of the object as of the revision.
+	ResourceVersion string `json:"resourceVersion"`
+	// Object is the object as of the revision.
+	Object runtime.RawExtension `json:"object"`
+	// Operation is the operation that replaced the revision, Update or Delete.
+	Operation RevisionOperation `json:"operation"`
+	// User is the name of the user who wrote the revision.
+	// +optional
+	User string `json:"user,omitempty"`
+	// Timestamp is the time the revision was replaced.
+	Timestamp metav1.Time `json:"timestamp"`
+	// Patch is the JSON merge patch from the revision to the next one, or to
+	// the current object for the last revision. It is not set for deletions.
+	// +optional
+	Patch string `json:"patch,omitempty"`
+}
@@ -0,0 +1,54 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+// This file contains a collection of methods that can be used from go-restful to
+// generate Swagger API documentation for its models. Please read this PR for more
+// information on the implementation: https://github.com/emicklei/go-restful/pull/215
+//
+// TODOs are ignored from the parser (e.g. TODO(andronat):... || TODO:...) if and only if
+// they are on one line! For multiple line or blocks that you want to ignore use ---.
+// Any context after a --- is ignored.
+//
+// Those methods can be generated by using hack/update-generated-swagger-docs.sh
+
+// AUTO-GENERATED FUNCTIONS START HERE. DO NOT EDIT.
+var map_ObjectHistory = map[string]string{
+	"":          "ObjectHistory is the history of an object: its revisions replaced by updates and deletions that are still retained, oldest first. It is served by the history subresource of the resources whose history is kept.",
+	"metadata":  "Standard object's metadata, with the name and namespace of the object. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#metadata",
+	"revisions": "Revisions are the retained revisions of the object, oldest first.",
+}
+
+func (ObjectHistory) SwaggerDoc() map[string]string {
+	return map_ObjectHistory
+}
+
+var map_ObjectRevision = map[string]string{
+	"":                "ObjectRevision is a revision of an object, and the operation that replaced it.",
+	"resourceVersion": "ResourceVersion is the resourceVersion of the object as of the revision.",
+	"object":          "Object is the object as of the revision.",
+	"operation":       "Operation is the operation that replaced the revision, Update or Delete.",
+	"user":            "User is the name of the user who wrote the revision.",
+	"timestamp":       "Timestamp is the time the revision was replaced.",
+	"patch":           "Patch is the JSON merge patch from the revision to the next one, or to the current object for the last revision. It is not set for deletions.",
+}
+
+func (ObjectRevision) SwaggerDoc() map[string]string {
+	return map_ObjectRevision
+}
+
+// AUTO-GENERATED FUNCTIONS END HERE
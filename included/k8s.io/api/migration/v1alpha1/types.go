@@ -0,0 +1,126 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/core/v1"
+	metav1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// StorageVersionMigration is the migration of the stored objects of a
+// resource to its current storage version. Every object of the resource is
+// rewritten in storage, so that it is encoded in the version the resource is
+// stored in now. Migrations are created by the server whenever the storage
+// version hash of a resource changes, and can be created by users.
+type StorageVersionMigration struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec identifies the resource to migrate.
+	Spec StorageVersionMigrationSpec `json:"spec"`
+
+	// Status is the progress of the migration.
+	// +optional
+	Status StorageVersionMigrationStatus `json:"status,omitempty"`
+}
+
+// StorageVersionMigrationSpec identifies the resource to migrate.
+type StorageVersionMigrationSpec struct {
+	// Resource is the resource whose objects are migrated. It is immutable.
+	Resource GroupVersionResource `json:"resource"`
+}
+
+// GroupVersionResource identifies a resource.
+type GroupVersionResource struct {
+	// Group is the API group of the resource. The empty string is the core
+	// group.
+	// +optional
+	Group string `json:"group,omitempty"`
+	// Version is the API version of the resource. Objects are migrated to the
+	// storage version of the resource, whatever the version.
+	// +optional
+	Version string `json:"version,omitempty"`
+	// Resource is the name of the resource, e.g. "deployments".
+	Resource string `json:"resource"`
+}
+
+// MigrationConditionType is the type of a condition of a migration.
+type MigrationConditionType string
+
+const (
+	// MigrationRunning is true while the objects are migrated.
+	MigrationRunning MigrationConditionType = "Running"
+	// MigrationSucceeded is true once all objects were migrated.
+	MigrationSucceeded MigrationConditionType = "Succeeded"
+	// MigrationFailed is true if the migration was given up.
+	MigrationFailed MigrationConditionType = "Failed"
+)
+
+// MigrationCondition describes the state of a migration at a certain point.
+type MigrationCondition struct {
+	// Type of the condition, one of Running, Succeeded or Failed.
+	Type MigrationConditionType `json:"type"`
+	// Status of the condition, one of True, False or Unknown.
+	Status corev1.ConditionStatus `json:"status"`
+	// LastUpdateTime is the last time the condition was updated.
+	// +optional
+	LastUpdateTime metav1.Time `json:"lastUpdateTime,omitempty"`
+	// Reason is a machine readable reason for the condition's last transition.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// Message is a human readable description of the details of the last
+	// transition.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// StorageVersionMigrationStatus is the progress of a migration.
+type StorageVersionMigrationStatus struct {
+	// Conditions are the latest observations of the state of the migration.
+	// +optional
+	Conditions []MigrationCondition `json:"conditions,omitempty"`
+	// StorageVersionHash is the storage version hash of the resource, as
+	// published in discovery, that the objects are migrated to.
+	// +optional
+	StorageVersionHash string `json:"storageVersionHash,omitempty"`
+	// ContinueToken is the token of the next chunk of objects to migrate. A
+	// migration that is interrupted, e.g. by a restart of the server, resumes
+	// from it.
+	// +optional
+	ContinueToken string `json:"continueToken,omitempty"`
+	// MigratedObjects is the number of objects migrated so far.
+	// +optional
+	MigratedObjects int64 `json:"migratedObjects,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// StorageVersionMigrationList is a list of StorageVersionMigration objects.
+type StorageVersionMigrationList struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard list metadata.
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	// Items is the list of StorageVersionMigration objects.
+	Items []StorageVersionMigration `json:"items"`
+}
@@ -0,0 +1,96 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+// This file contains a collection of methods that can be used from go-restful to
+// generate Swagger API documentation for its models. Please read this PR for more
+// information on the implementation: https://github.com/emicklei/go-restful/pull/215
+//
+// TODOs are ignored from the parser (e.g. TODO(andronat):... || TODO:...) if and only if
+// they are on one line! For multiple line or blocks that you want to ignore use ---.
+// Any context after a --- is ignored.
+//
+// Those methods can be generated by using hack/update-generated-swagger-docs.sh
+
+// AUTO-GENERATED FUNCTIONS START HERE. DO NOT EDIT.
+var map_GroupVersionResource = map[string]string{
+	"":         "GroupVersionResource identifies a resource.",
+	"group":    "Group is the API group of the resource. The empty string is the core group.",
+	"version":  "Version is the API version of the resource. Objects are migrated to the storage version of the resource, whatever the version.",
+	"resource": "Resource is the name of the resource, e.g. \"deployments\".",
+}
+
+func (GroupVersionResource) SwaggerDoc() map[string]string {
+	return map_GroupVersionResource
+}
+
+var map_MigrationCondition = map[string]string{
+	"":               "MigrationCondition describes the state of a migration at a certain point.",
+	"type":           "Type of the condition, one of Running, Succeeded or Failed.",
+	"status":         "Status of the condition, one of True, False or Unknown.",
+	"lastUpdateTime": "LastUpdateTime is the last time the condition was updated.",
+	"reason":         "Reason is a machine readable reason for the condition's last transition.",
+	"message":        "Message is a human readable description of the details of the last transition.",
+}
+
+func (MigrationCondition) SwaggerDoc() map[string]string {
+	return map_MigrationCondition
+}
+
+var map_StorageVersionMigration = map[string]string{
+	"":         "StorageVersionMigration is the migration of the stored objects of a resource to its current storage version. Every object of the resource is rewritten in storage, so that it is encoded in the version the resource is stored in now. Migrations are created by the server whenever the storage version hash of a resource changes, and can be created by users.",
+	"metadata": "More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#metadata",
+	"spec":     "Spec identifies the resource to migrate.",
+	"status":   "Status is the progress of the migration.",
+}
+
+func (StorageVersionMigration) SwaggerDoc() map[string]string {
+	return map_StorageVersionMigration
+}
+
+var map_StorageVersionMigrationList = map[string]string{
+	"":         "StorageVersionMigrationList is a list of StorageVersionMigration objects.",
+	"metadata": "Standard list metadata.",
+	"items":    "Items is the list of StorageVersionMigration objects.",
+}
+
+func (StorageVersionMigrationList) SwaggerDoc() map[string]string {
+	return map_StorageVersionMigrationList
+}
+
+var map_StorageVersionMigrationSpec = map[string]string{
+	"":         "StorageVersionMigrationSpec identifies the resource to migrate.",
+	"resource": "Resource is the resource whose objects are migrated. It is immutable.",
+}
+
+func (StorageVersionMigrationSpec) SwaggerDoc() map[string]string {
+	return map_StorageVersionMigrationSpec
+}
+
+var map_StorageVersionMigrationStatus = map[string]string{
+	"":                   "StorageVersionMigrationStatus is the progress of a migration.",
+	"conditions":         "Conditions are the latest observations of the state of the migration.",
+	"storageVersionHash": "StorageVersionHash is the storage version hash of the resource, as published in discovery, that the objects are migrated to.",
+	"continueToken":      "ContinueToken is the token of the next chunk of objects to migrate. A migration that is interrupted, e.g. by a restart of the server, resumes from it.",
+	"migratedObjects":    "MigratedObjects is the number of objects migrated so far.",
+}
+
+func (StorageVersionMigrationStatus) SwaggerDoc() map[string]string {
+	return map_StorageVersionMigrationStatus
+}
+
+// AUTO-GENERATED FUNCTIONS END HERE
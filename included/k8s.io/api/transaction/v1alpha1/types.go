@@ -0,0 +1,116 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +genclient:onlyVerbs=create
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// Transaction creates, updates and deletes a list of objects atomically: either
+// all operations are committed at a single resourceVersion, or none of them is.
+// The objects may be of different resources, including custom resources, but
+// must be stored in the same storage backend. Every operation is authorized and
+// admitted like the equivalent request on the object. Transactions are not
+// persisted.
+type Transaction struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec holds the operations of the transaction.
+	Spec TransactionSpec `json:"spec"`
+
+	// Status is filled in by the server with the results of the committed
+	// operations.
+	// +optional
+	Status TransactionStatus `json:"status,omitempty"`
+}
+
+// TransactionSpec is the list of operations of a transaction.
+type TransactionSpec struct {
+	// Operations are the operations of the transaction. An object may only be
+	// the target of one operation.
+	Operations []Operation `json:"operations"`
+}
+
+// OperationType is the type of an operation of a transaction.
+type OperationType string
+
+const (
+	// OperationCreate creates the object of the operation.
+	OperationCreate OperationType = "Create"
+	// OperationUpdate replaces an existing object with the object of the
+	// operation.
+	OperationUpdate OperationType = "Update"
+	// OperationDelete deletes an existing object. Objects that would be
+	// deleted gracefully or that have finalizers cannot be deleted in a
+	// transaction.
+	OperationDelete OperationType = "Delete"
+)
+
+// Operation is the creation, update or deletion of an object.
+type Operation struct {
+	// Type is the type of the operation, one of Create, Update or Delete.
+	Type OperationType `json:"type"`
+	// Group is the API group of the resource of the object. The empty string
+	// is the core group.
+	// +optional
+	Group string `json:"group,omitempty"`
+	// Version is the API version of the resource of the object, which is also
+	// the version of the object in Object and in the result.
+	Version string `json:"version"`
+	// Resource is the resource of the object, e.g. "configmaps".
+	Resource string `json:"resource"`
+	// Namespace is the namespace of the object, which must be set for
+	// namespaced resources only.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	// Name is the name of the object. It is required for updates and
+	// deletions, and defaults to the name of the object for creations.
+	// +optional
+	Name string `json:"name,omitempty"`
+	// Object is the object to create, or to replace the existing object with.
+	// It is required for creations and updates, and must not be set for
+	// deletions.
+	// +optional
+	Object runtime.RawExtension `json:"object,omitempty"`
+	// Preconditions must be fulfilled by the object to delete. They must not
+	// be set for creations and updates.
+	// +optional
+	Preconditions *metav1.Preconditions `json:"preconditions,omitempty"`
+}
+
+// TransactionStatus holds the results of a committed transaction.
+type TransactionStatus struct {
+	// Results holds the result of each operation, in the order of the
+	// operations.
+	// +optional
+	Results []OperationResult `json:"results,omitempty"`
+}
+
+// OperationResult is the result of a committed operation.
+type OperationResult struct {
+	// Object is the object stored by a creation or update, or the deleted
+	// object of a deletion.
+	Object runtime.RawExtension `json:"object"`
+}
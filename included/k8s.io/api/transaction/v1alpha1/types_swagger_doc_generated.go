@@ -0,0 +1,84 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+// This file contains a collection of methods that can be used from go-restful to
+// generate Swagger API documentation for its models. Please read this PR for more
+// information on the implementation: https://github.com/emicklei/go-restful/pull/215
+//
+// TODOs are ignored from the parser (e.g. TODO(andronat):... || TODO:...) if and only if
+// they are on one line! For multiple line or blocks that you want to ignore use ---.
+// Any context after a --- is ignored.
+//
+// Those methods can be generated by using hack/update-generated-swagger-docs.sh
+
+// AUTO-GENERATED FUNCTIONS START HERE. DO NOT EDIT.
+var map_Operation = map[string]string{
+	"":              "Operation is the creation, update or deletion of an object.",
+	"type":          "Type is the type of the operation, one of Create, Update or Delete.",
+	"group":         "Group is the API group of the resource of the object. The empty string is the core group.",
+	"version":       "Version is the API version of the resource of the object, which is also the version of the object in Object and in the result.",
+	"resource":      "Resource is the resource of the object, e.g. \"configmaps\".",
+	"namespace":     "Namespace is the namespace of the object, which must be set for namespaced resources only.",
+	"name":          "Name is the name of the object. It is required for updates and deletions, and defaults to the name of the object for creations.",
+	"object":        "Object is the object to create, or to replace the existing object with. It is required for creations and updates, and must not be set for deletions.",
+	"preconditions": "Preconditions must be fulfilled by the object to delete. They must not be set for creations and updates.",
+}
+
+func (Operation) SwaggerDoc() map[string]string {
+	return map_Operation
+}
+
+var map_OperationResult = map[string]string{
+	"":       "OperationResult is the result of a committed operation.",
+	"object": "Object is the object stored by a creation or update, or the deleted object of a deletion.",
+}
+
+func (OperationResult) SwaggerDoc() map[string]string {
+	return map_OperationResult
+}
+
+var map_Transaction = map[string]string{
+	"":         "Transaction creates, updates and deletes a list of objects atomically: either all operations are committed at a single resourceVersion, or none of them is. The objects may be of different resources, including custom resources, but must be stored in the same storage backend. Every operation is authorized and admitted like the equivalent request on the object. Transactions are not persisted.",
+	"metadata": "More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#metadata",
+	"spec":     "Spec holds the operations of the transaction.",
+	"status":   "Status is filled in by the server with the results of the committed operations.",
+}
+
+func (Transaction) SwaggerDoc() map[string]string {
+	return map_Transaction
+}
+
+var map_TransactionSpec = map[string]string{
+	"":           "TransactionSpec is the list of operations of a transaction.",
+	"operations": "Operations are the operations of the transaction. An object may only be the target of one operation.",
+}
+
+func (TransactionSpec) SwaggerDoc() map[string]string {
+	return map_TransactionSpec
+}
+
+var map_TransactionStatus = map[string]string{
+	"":        "TransactionStatus holds the results of a committed transaction.",
+	"results": "Results holds the result of each operation, in the order of the operations.",
+}
+
+func (TransactionStatus) SwaggerDoc() map[string]string {
+	return map_TransactionStatus
+}
+
+// AUTO-GENERATED FUNCTIONS END HERE
@@ -0,0 +1,105 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/types"
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +genclient:onlyVerbs=get,list
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// DeletedObject is an object kept in the trash of its resource after its
+// deletion, until it is restored by the undelete subresource of the resource
+// or purged at the end of the retention of the trash. It is named after the
+// UID of the deleted object and labeled with its labels. DeletedObjects are
+// computed by the server from the trash of the resources, so they are
+// read-only.
+type DeletedObject struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Resource is the resource of the deleted object.
+	Resource GroupResource `json:"resource"`
+	// Object identifies the deleted object.
+	Object ObjectReference `json:"object"`
+	// DeletedBy is the name of the user who deleted the object.
+	// +optional
+	DeletedBy string `json:"deletedBy,omitempty"`
+	// DeletedAt is when the object was deleted.
+	DeletedAt metav1.Time `json:"deletedAt"`
+	// PurgeAt is when the object is purged from the trash, after which it can
+	// no longer be restored.
+	PurgeAt metav1.Time `json:"purgeAt"`
+}
+
+// GroupResource identifies a resource.
+type GroupResource struct {
+	// Group is the API group of the resource. The empty string is the core
+	// group.
+	// +optional
+	Group string `json:"group,omitempty"`
+	// Resource is the name of the resource, e.g. "namespaces".
+	Resource string `json:"resource"`
+}
+
+// ObjectReference identifies an object of a resource.
+type ObjectReference struct {
+	// Namespace is the namespace of the object, empty for cluster-scoped
+	// resources.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	// Name is the name of the object.
+	Name string `json:"name"`
+	// UID is the UID of the object.
+	UID types.UID `json:"uid"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// DeletedObjectList is a list of DeletedObject objects.
+type DeletedObjectList struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard list metadata.
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	// Items is the list of DeletedObject objects.
+	Items []DeletedObject `json:"items"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// Undelete restores a deleted object from the trash of its resource, with
+// its UID, labels and the rest of its state at its deletion. It is the body of
+// the undelete subresource of the resources whose deleted objects are kept in
+// the trash, and is named after the object.
+type Undelete struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// UID is the UID of the deleted object to restore, for when several
+	// objects with its name were deleted. The most recently deleted one is
+	// restored if empty.
+	// +optional
+	UID types.UID `json:"uid,omitempty"`
+}
@@ -0,0 +1,85 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+// This file contains a collection of methods that can be used from go-restful to
+// generate Swagger API documentation for its models. Please read this PR for more
+// information on the implementation: https://github.com/emicklei/go-restful/pull/215
+//
+// TODOs are ignored from the parser (e.g. TODO(andronat):... || TODO:...) if and only if
+// they are on one line! For multiple line or blocks that you want to ignore use ---.
+// Any context after a --- is ignored.
+//
+// Those methods can be generated by using hack/update-generated-swagger-docs.sh
+
+// AUTO-GENERATED FUNCTIONS START HERE. DO NOT EDIT.
+var map_DeletedObject = map[string]string{
+	"":          "DeletedObject is an object kept in the trash of its resource after its deletion, until it is restored by the undelete subresource of the resource or purged at the end of the retention of the trash. It is named after the UID of the deleted object and labeled with its labels. DeletedObjects are computed by the server from the trash of the resources, so they are read-only.",
+	"metadata":  "More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#metadata",
+	"resource":  "Resource is the resource of the deleted object.",
+	"object":    "Object identifies the deleted object.",
+	"deletedBy": "DeletedBy is the name of the user who deleted the object.",
+	"deletedAt": "DeletedAt is when the object was deleted.",
+	"purgeAt":   "PurgeAt is when the object is purged from the trash, after which it can no longer be restored.",
+}
+
+func (DeletedObject) SwaggerDoc() map[string]string {
+	return map_DeletedObject
+}
+
+var map_DeletedObjectList = map[string]string{
+	"":         "DeletedObjectList is a list of DeletedObject objects.",
+	"metadata": "Standard list metadata.",
+	"items":    "Items is the list of DeletedObject objects.",
+}
+
+func (DeletedObjectList) SwaggerDoc() map[string]string {
+	return map_DeletedObjectList
+}
+
+var map_GroupResource = map[string]string{
+	"":         "GroupResource identifies a resource.",
+	"group":    "Group is the API group of the resource. The empty string is the core group.",
+	"resource": "Resource is the name of the resource, e.g. \"namespaces\".",
+}
+
+func (GroupResource) SwaggerDoc() map[string]string {
+	return map_GroupResource
+}
+
+var map_ObjectReference = map[string]string{
+	"":          "ObjectReference identifies an object of a resource.",
+	"namespace": "Namespace is the namespace of the object, empty for cluster-scoped resources.",
+	"name":      "Name is the name of the object.",
+	"uid":       "UID is the UID of the object.",
+}
+
+func (ObjectReference) SwaggerDoc() map[string]string {
+	return map_ObjectReference
+}
+
+var map_Undelete = map[string]string{
+	"":         "Undelete restores a deleted object from the trash of its resource, with its UID, labels and the rest of its state at its deletion. It is the body of the undelete subresource of the resources whose deleted objects are kept in the trash, and is named after the object.",
+	"metadata": "More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#metadata",
+	"uid":      "UID is the UID of the deleted object to restore, for when several objects with its name were deleted. The most recently deleted one is restored if empty.",
+}
+
+func (Undelete) SwaggerDoc() map[string]string {
+	return map_Undelete
+}
+
+// AUTO-GENERATED FUNCTIONS END HERE
@@ -0,0 +1,62 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+// This file contains a collection of methods that can be used from go-restful to
+// generate Swagger API documentation for its models. Please read this PR for more
+// information on the implementation: https://github.com/emicklei/go-restful/pull/215
+//
+// TODOs are ignored from the parser (e.g. TODO(andronat):... || TODO:...) if and only if
+// they are on one line! For multiple line or blocks that you want to ignore use ---.
+// Any context after a --- is ignored.
+//
+// Those methods can be generated by using hack/update-generated-swagger-docs.sh
+
+// AUTO-GENERATED FUNCTIONS START HERE. DO NOT EDIT.
+var map_GroupResource = map[string]string{
+	"":         "GroupResource identifies a resource.",
+	"group":    "Group is the API group of the resource. The empty string is the core group.",
+	"resource": "Resource is the name of the resource, e.g. \"configmaps\".",
+}
+
+func (GroupResource) SwaggerDoc() map[string]string {
+	return map_GroupResource
+}
+
+var map_StorageUsage = map[string]string{
+	"":         "StorageUsage is the number and size of the stored objects of a resource in a namespace. It is named after the resource, e.g. \"configmaps\" or \"deployments.apps\", and computed by the server from the accounting of the writes to storage, so it is read-only.",
+	"metadata": "More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#metadata",
+	"resource": "Resource is the resource whose objects are accounted.",
+	"objects":  "Objects is the number of objects of the resource in the namespace.",
+	"bytes":    "Bytes is the total size of the objects of the resource in the namespace, as they are stored, that is after they are encoded and transformed, e.g. encrypted or compressed.",
+}
+
+func (StorageUsage) SwaggerDoc() map[string]string {
+	return map_StorageUsage
+}
+
+var map_StorageUsageList = map[string]string{
+	"":         "StorageUsageList is a list of StorageUsage objects.",
+	"metadata": "Standard list metadata.",
+	"items":    "Items is the list of StorageUsage objects.",
+}
+
+func (StorageUsageList) SwaggerDoc() map[string]string {
+	return map_StorageUsageList
+}
+
+// AUTO-GENERATED FUNCTIONS END HERE
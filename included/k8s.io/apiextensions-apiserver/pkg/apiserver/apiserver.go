@@ -192,6 +192,9 @@ func (c completedConfig) New(delegationTarget genericapiserver.DelegationTarget)
 	if err != nil {
 		return nil, err
 	}
+	if s.GenericAPIServer.StorageRegistry != nil {
+		s.GenericAPIServer.StorageRegistry.AddResolver(crdHandler.resolveStorage)
+	}
 	s.GenericAPIServer.Handler.NonGoRestfulMux.Handle("/apis", crdHandler)
 	s.GenericAPIServer.Handler.NonGoRestfulMux.HandlePrefix("/apis/", crdHandler)
 
@@ -43,6 +43,7 @@ import (
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiextensions-apiserver/pkg/registry/customresource"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiextensions-apiserver/pkg/registry/customresource/tableconvertor"
 
+	trashv1alpha1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/trash/v1alpha1"
 	apiequality "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/api/equality"
 	apierrors "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/api/errors"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/api/meta"
@@ -68,6 +69,7 @@ import (
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/features"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/registry/generic"
 	genericregistry "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/registry/generic/registry"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/registry/rest"
 	genericfilters "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/server/filters"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/storagebackend"
 	utilfeature "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/util/feature"
@@ -131,6 +133,9 @@ type crdInfo struct {
 	// Status scope per version
 	statusRequestScopes map[string]*handlers.RequestScope
 
+	// Undelete scope per version
+	undeleteRequestScopes map[string]*handlers.RequestScope
+
 	// storageVersion is the CRD version used when storing the object in etcd.
 	storageVersion string
 
@@ -188,6 +193,14 @@ func NewCustomResourceDefinitionHandler(
 // and on the client side (by restarting the watch)
 var longRunningFilter = genericfilters.BasicLongRunningRequestCheck(sets.NewString("watch"), sets.NewString())
 
+// undeleteScheme decodes the Undelete bodies of the undelete subresource of
+// the custom resources whose deleted objects are kept in the trash.
+var undeleteScheme = func() *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(trashv1alpha1.AddToScheme(scheme))
+	return scheme
+}()
+
 func (r *crdHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	ctx := req.Context()
 	requestInfo, ok := apirequest.RequestInfoFrom(ctx)
@@ -269,6 +282,8 @@ func (r *crdHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 		handlerFunc = r.serveStatus(w, req, requestInfo, crdInfo, terminating, supportedTypes)
 	case subresource == "scale" && subresources != nil && subresources.Scale != nil:
 		handlerFunc = r.serveScale(w, req, requestInfo, crdInfo, terminating, supportedTypes)
+	case subresource == "undelete" && crdInfo.storages[requestInfo.APIVersion].Undelete != nil:
+		handlerFunc = r.serveUndelete(w, req, requestInfo, crdInfo, terminating)
 	case len(subresource) == 0:
 		handlerFunc = r.serveResource(w, req, requestInfo, crdInfo, terminating, supportedTypes)
 	default:
@@ -353,6 +368,23 @@ func (r *crdHandler) serveScale(w http.ResponseWriter, req *http.Request, reques
 	}
 }
 
+func (r *crdHandler) serveUndelete(w http.ResponseWriter, req *http.Request, requestInfo *apirequest.RequestInfo, crdInfo *crdInfo, terminating bool) http.HandlerFunc {
+	requestScope := crdInfo.undeleteRequestScopes[requestInfo.APIVersion]
+	storage := crdInfo.storages[requestInfo.APIVersion].Undelete
+
+	switch requestInfo.Verb {
+	case "create":
+		if terminating {
+			http.Error(w, fmt.Sprintf("%v not allowed while CustomResourceDefinition is terminating", requestInfo.Verb), http.StatusMethodNotAllowed)
+			return nil
+		}
+		return handlers.CreateNamedResource(storage, requestScope, r.admission)
+	default:
+		http.Error(w, fmt.Sprintf("unhandled verb %q", requestInfo.Verb), http.StatusMethodNotAllowed)
+		return nil
+	}
+}
+
 func (r *crdHandler) updateCustomResourceDefinition(oldObj, newObj interface{}) {
 	oldCRD := oldObj.(*apiextensions.CustomResourceDefinition)
 	newCRD := newObj.(*apiextensions.CustomResourceDefinition)
@@ -469,6 +501,44 @@ func (r *crdHandler) GetCustomResourceListerCollectionDeleter(crd *apiextensions
 	return info.storages[info.storageVersion].CustomResource, nil
 }
 
+// resolveStorage returns the storage of the custom resource served in the
+// given version, or nil if no established CRD serves it. It is added as a
+// resolver to the storage registry of the server so that custom resources can
+// be changed in transactions.
+func (r *crdHandler) resolveStorage(resource schema.GroupVersionResource) (*rest.RegisteredStorage, error) {
+	crd, err := r.crdLister.Get(resource.Resource + "." + resource.Group)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !apiextensions.HasServedCRDVersion(crd, resource.Version) {
+		return nil, nil
+	}
+	if !apiextensions.IsCRDConditionTrue(crd, apiextensions.NamesAccepted) &&
+		!apiextensions.IsCRDConditionTrue(crd, apiextensions.Established) {
+		return nil, nil
+	}
+	if apiextensions.IsCRDConditionTrue(crd, apiextensions.Terminating) {
+		return nil, apierrors.NewMethodNotSupported(resource.GroupResource(), "transaction")
+	}
+
+	crdInfo, err := r.getOrCreateServingInfoFor(crd)
+	if err != nil {
+		return nil, err
+	}
+	requestScope := crdInfo.requestScopes[resource.Version]
+	return &rest.RegisteredStorage{
+		Storage:          crdInfo.storages[resource.Version].CustomResource,
+		Kind:             requestScope.Kind,
+		NamespaceScoped:  crd.Spec.Scope == apiextensions.NamespaceScoped,
+		Serializer:       requestScope.Serializer,
+		HubGroupVersion:  requestScope.HubGroupVersion,
+		ObjectInterfaces: requestScope,
+	}, nil
+}
+
 func (r *crdHandler) getOrCreateServingInfoFor(crd *apiextensions.CustomResourceDefinition) (*crdInfo, error) {
 	storageMap := r.customStorage.Load().(crdStorageMap)
 	if ret, ok := storageMap[crd.UID]; ok {
@@ -493,6 +563,7 @@ func (r *crdHandler) getOrCreateServingInfoFor(crd *apiextensions.CustomResource
 	storages := map[string]customresource.CustomResourceStorage{}
 	statusScopes := map[string]*handlers.RequestScope{}
 	scaleScopes := map[string]*handlers.RequestScope{}
+	undeleteScopes := map[string]*handlers.RequestScope{}
 
 	equivalentResourceRegistry := runtime.NewEquivalentResourceRegistry()
 
@@ -663,6 +734,8 @@ func (r *crdHandler) getOrCreateServingInfoFor(crd *apiextensions.CustomResource
 			TableConvertor: storages[v.Name].CustomResource,
 
 			Authorizer: r.authorizer,
+
+			SelectableFields: sets.NewString(storages[v.Name].CustomResource.SelectableFields()...),
 		}
 		if utilfeature.DefaultFeatureGate.Enabled(features.ServerSideApply) {
 			reqScope := *requestScopes[v.Name]
@@ -708,17 +781,44 @@ func (r *crdHandler) getOrCreateServingInfoFor(crd *apiextensions.CustomResource
 			SelfLinkPathSuffix: "/status",
 		}
 		statusScopes[v.Name] = &statusScope
+
+		// override undelete subresource values, for the typed Undelete body
+		// shallow copy
+		if storages[v.Name].Undelete != nil {
+			equivalentResourceRegistry.RegisterKindFor(resource, "undelete", trashv1alpha1.SchemeGroupVersion.WithKind("Undelete"))
+
+			undeleteScope := *requestScopes[v.Name]
+			undeleteScope.Subresource = "undelete"
+			undeleteScope.Serializer = serializer.NewCodecFactory(undeleteScheme)
+			undeleteScope.Creater = undeleteScheme
+			undeleteScope.Convertor = undeleteScheme
+			undeleteScope.Defaulter = undeleteScheme
+			undeleteScope.Typer = undeleteScheme
+			undeleteScope.UnsafeConvertor = runtime.UnsafeObjectConvertor(undeleteScheme)
+			undeleteScope.Kind = trashv1alpha1.SchemeGroupVersion.WithKind("Undelete")
+			// Undelete has no internal version.
+			undeleteScope.HubGroupVersion = trashv1alpha1.SchemeGroupVersion
+			undeleteScope.FieldManager = nil
+			undeleteScope.Namer = handlers.ContextBasedNaming{
+				SelfLinker:         meta.NewAccessor(),
+				ClusterScoped:      clusterScoped,
+				SelfLinkPathPrefix: selfLinkPrefix,
+				SelfLinkPathSuffix: "/undelete",
+			}
+			undeleteScopes[v.Name] = &undeleteScope
+		}
 	}
 
 	ret := &crdInfo{
-		spec:                &crd.Spec,
-		acceptedNames:       &crd.Status.AcceptedNames,
-		storages:            storages,
-		requestScopes:       requestScopes,
-		scaleRequestScopes:  scaleScopes,
-		statusRequestScopes: statusScopes,
-		storageVersion:      storageVersion,
-		waitGroup:           &utilwaitgroup.SafeWaitGroup{},
+		spec:                  &crd.Spec,
+		acceptedNames:         &crd.Status.AcceptedNames,
+		storages:              storages,
+		requestScopes:         requestScopes,
+		scaleRequestScopes:    scaleScopes,
+		statusRequestScopes:   statusScopes,
+		undeleteRequestScopes: undeleteScopes,
+		storageVersion:        storageVersion,
+		waitGroup:             &utilwaitgroup.SafeWaitGroup{},
 	}
 
 	// Copy because we cannot write to storageMap without a race
@@ -33,11 +33,13 @@ import (
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/registry/rest"
 )
 
-// CustomResourceStorage includes dummy storage for CustomResources, and their Status and Scale subresources.
+// CustomResourceStorage includes dummy storage for CustomResources, and their Status, Scale and Undelete subresources.
 type CustomResourceStorage struct {
 	CustomResource *REST
 	Status         *StatusREST
 	Scale          *ScaleREST
+	// Undelete is set if the deleted custom resources are kept in the trash.
+	Undelete *genericregistry.UndeleteREST
 }
 
 func NewStorage(resource schema.GroupResource, kind, listKind schema.GroupVersionKind, strategy customResourceStrategy, optsGetter generic.RESTOptionsGetter, categories []string, tableConvertor rest.TableConvertor) CustomResourceStorage {
@@ -51,6 +53,10 @@ func NewStorage(resource schema.GroupResource, kind, listKind schema.GroupVersio
 		s.Status = customResourceStatusREST
 	}
 
+	if undelete, ok := customResourceREST.UndeleteStorage().(*genericregistry.UndeleteREST); ok {
+		s.Undelete = undelete
+	}
+
 	if scale := strategy.scale; scale != nil {
 		var labelSelectorPath string
 		if scale.LabelSelectorPath != nil {
@@ -29,11 +29,12 @@ func TestListOptions(t *testing.T) {
 	// verify round trip conversion
 	ten := int64(10)
 	in := &metav1.ListOptions{
-		LabelSelector:   "a=1",
-		FieldSelector:   "b=1",
-		ResourceVersion: "10",
-		TimeoutSeconds:  &ten,
-		Watch:           true,
+		LabelSelector:      "a=1",
+		FieldSelector:      "b=1",
+		AnnotationSelector: "c=1",
+		ResourceVersion:    "10",
+		TimeoutSeconds:     &ten,
+		Watch:              true,
 	}
 	out := &ListOptions{}
 	if err := scheme.Convert(in, out, nil); err != nil {
@@ -51,6 +52,7 @@ func TestListOptions(t *testing.T) {
 	for i, failingObject := range []*metav1.ListOptions{
 		{LabelSelector: "a!!!"},
 		{FieldSelector: "a!!!"},
+		{AnnotationSelector: "a!!!"},
 	} {
 		out = &ListOptions{}
 		if err := scheme.Convert(failingObject, out, nil); err == nil {
@@ -33,6 +33,8 @@ type ListOptions struct {
 	LabelSelector labels.Selector
 	// A selector based on fields
 	FieldSelector fields.Selector
+	// A selector based on annotations
+	AnnotationSelector labels.Selector
 	// If true, watch for changes to this list
 	Watch bool
 	// allowWatchBookmarks requests watch events with type "BOOKMARK".
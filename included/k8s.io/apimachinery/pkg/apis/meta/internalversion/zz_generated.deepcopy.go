@@ -69,6 +69,9 @@ func (in *ListOptions) DeepCopyInto(out *ListOptions) {
 	if in.FieldSelector != nil {
 		out.FieldSelector = in.FieldSelector.DeepCopySelector()
 	}
+	if in.AnnotationSelector != nil {
+		out.AnnotationSelector = in.AnnotationSelector.DeepCopySelector()
+	}
 	if in.TimeoutSeconds != nil {
 		in, out := &in.TimeoutSeconds, &out.TimeoutSeconds
 		*out = new(int64)
@@ -1262,6 +1262,12 @@ func (m *ListOptions) MarshalTo(dAtA []byte) (int, error) {
 		dAtA[i] = 0
 	}
 	i++
+	dAtA[i] = 0xc2
+	i++
+	dAtA[i] = 0x3e
+	i++
+	i = encodeVarintGenerated(dAtA, i, uint64(len(m.AnnotationSelector)))
+	i += copy(dAtA[i:], m.AnnotationSelector)
 	return i, nil
 }
 
@@ -2416,6 +2422,8 @@ func (m *ListOptions) Size() (n int) {
 	l = len(m.Continue)
 	n += 1 + l + sovGenerated(uint64(l))
 	n += 2
+	l = len(m.AnnotationSelector)
+	n += 2 + l + sovGenerated(uint64(l))
 	return n
 }
 
@@ -2966,6 +2974,7 @@ func (this *ListOptions) String() string {
 		`Limit:` + fmt.Sprintf("%v", this.Limit) + `,`,
 		`Continue:` + fmt.Sprintf("%v", this.Continue) + `,`,
 		`AllowWatchBookmarks:` + fmt.Sprintf("%v", this.AllowWatchBookmarks) + `,`,
+		`AnnotationSelector:` + fmt.Sprintf("%v", this.AnnotationSelector) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -6459,6 +6468,35 @@ func (m *ListOptions) Unmarshal(dAtA []byte) error {
 				}
 			}
 			m.AllowWatchBookmarks = bool(v != 0)
+		case 1000:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AnnotationSelector", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGenerated
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.AnnotationSelector = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipGenerated(dAtA[iNdEx:])
@@ -9526,182 +9564,182 @@ func init() {
 }
 
 var fileDescriptorGenerated = []byte{
-	// 2820 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xcc, 0x1a, 0xcf, 0x6f, 0x1c, 0x57,
-	0xd9, 0xb3, 0xeb, 0x5d, 0xef, 0x7e, 0xeb, 0x4d, 0xec, 0x97, 0x04, 0xb6, 0x46, 0x78, 0xdd, 0x29,
-	0xaa, 0x52, 0x48, 0xd7, 0x4d, 0x4a, 0xab, 0x90, 0xd2, 0x82, 0xd7, 0x76, 0x52, 0xd3, 0xb8, 0xb1,
-	0x9e, 0x93, 0x20, 0x42, 0x84, 0x3a, 0xde, 0x79, 0x5e, 0x0f, 0x9e, 0x9d, 0x99, 0xbe, 0x37, 0xeb,
-	0xc4, 0x70, 0xa0, 0x07, 0x10, 0x20, 0x41, 0xd5, 0x23, 0x27, 0xd4, 0x0a, 0xfe, 0x02, 0x4e, 0x9c,
-	0x38, 0x55, 0xa2, 0x17, 0xa4, 0x4a, 0x5c, 0x2a, 0x81, 0x56, 0xad, 0x41, 0x82, 0x1b, 0xe2, 0xea,
-	0x13, 0x7a, 0xbf, 0x66, 0xde, 0xec, 0x7a, 0xe3, 0x59, 0x52, 0x2a, 0x4e, 0x3b, 0xf3, 0xfd, 0x7e,
-	0xef, 0x7d, 0xef, 0xfb, 0x35, 0x0b, 0x9b, 0xfb, 0x57, 0x59, 0xcb, 0x0b, 0x97, 0xf7, 0xfb, 0x3b,
-	0x84, 0x06, 0x24, 0x26, 0x6c, 0xf9, 0x80, 0x04, 0x6e, 0x48, 0x97, 0x15, 0xc2, 0x89, 0xbc, 0x9e,
-	0xd3, 0xd9, 0xf3, 0x02, 0x42, 0x0f, 0x97, 0xa3, 0xfd, 0x2e, 0x07, 0xb0, 0xe5, 0x1e, 0x89, 0x9d,
-	0xe5, 0x83, 0xcb, 0xcb, 0x5d, 0x12, 0x10, 0xea, 0xc4, 0xc4, 0x6d, 0x45, 0x34, 0x8c, 0x43, 0xf4,
-	0x25, 0xc9, 0xd5, 0x32, 0xb9, 0x5a, 0xd1, 0x7e, 0x97, 0x03, 0x58, 0x8b, 0x73, 0xb5, 0x0e, 0x2e,
-	0x2f, 0x3c, 0xdb, 0xf5, 0xe2, 0xbd, 0xfe, 0x4e, 0xab, 0x13, 0xf6, 0x96, 0xbb, 0x61, 0x37, 0x5c,
-	0x16, 0xcc, 0x3b, 0xfd, 0x5d, 0xf1, 0x26, 0x5e, 0xc4, 0x93, 0x14, 0xba, 0x30, 0xd6, 0x14, 0xda,
-	0x0f, 0x62, 0xaf, 0x47, 0x86, 0xad, 0x58, 0x78, 0xf1, 0x34, 0x06, 0xd6, 0xd9, 0x23, 0x3d, 0x67,
-	0x98, 0xcf, 0xfe, 0x63, 0x11, 0x2a, 0x2b, 0x5b, 0x1b, 0x37, 0x68, 0xd8, 0x8f, 0xd0, 0x12, 0x4c,
-	0x07, 0x4e, 0x8f, 0x34, 0xac, 0x25, 0xeb, 0x62, 0xb5, 0x3d, 0xfb, 0xc1, 0xa0, 0x39, 0x75, 0x34,
-	0x68, 0x4e, 0xbf, 0xee, 0xf4, 0x08, 0x16, 0x18, 0xe4, 0x43, 0xe5, 0x80, 0x50, 0xe6, 0x85, 0x01,
-	0x6b, 0x14, 0x96, 0x8a, 0x17, 0x6b, 0x57, 0x5e, 0x69, 0xe5, 0x59, 0x7f, 0x4b, 0x28, 0xb8, 0x2b,
-	0x59, 0xaf, 0x87, 0x74, 0xcd, 0x63, 0x9d, 0xf0, 0x80, 0xd0, 0xc3, 0xf6, 0x9c, 0xd2, 0x52, 0x51,
-	0x48, 0x86, 0x13, 0x0d, 0xe8, 0xc7, 0x16, 0xcc, 0x45, 0x94, 0xec, 0x12, 0x4a, 0x89, 0xab, 0xf0,
-	0x8d, 0xe2, 0x92, 0xf5, 0x29, 0xa8, 0x6d, 0x28, 0xb5, 0x73, 0x5b, 0x43, 0xf2, 0xf1, 0x88, 0x46,
-	0xf4, 0x1b, 0x0b, 0x16, 0x18, 0xa1, 0x07, 0x84, 0xae, 0xb8, 0x2e, 0x25, 0x8c, 0xb5, 0x0f, 0x57,
-	0x7d, 0x8f, 0x04, 0xf1, 0xea, 0xc6, 0x1a, 0x66, 0x8d, 0x69, 0xb1, 0x0f, 0xdf, 0xc8, 0x67, 0xd0,
-	0xf6, 0x38, 0x39, 0x6d, 0x5b, 0x59, 0xb4, 0x30, 0x96, 0x84, 0xe1, 0x47, 0x98, 0x61, 0xef, 0xc2,
-	0xac, 0x3e, 0xc8, 0x9b, 0x1e, 0x8b, 0xd1, 0x5d, 0x28, 0x77, 0xf9, 0x0b, 0x6b, 0x58, 0xc2, 0xc0,
-	0x56, 0x3e, 0x03, 0xb5, 0x8c, 0xf6, 0x19, 0x65, 0x4f, 0x59, 0xbc, 0x32, 0xac, 0xa4, 0xd9, 0x3f,
-	0x9f, 0x86, 0xda, 0xca, 0xd6, 0x06, 0x26, 0x2c, 0xec, 0xd3, 0x0e, 0xc9, 0xe1, 0x34, 0x57, 0x00,
-	0xf8, 0x2f, 0x8b, 0x9c, 0x0e, 0x71, 0x1b, 0x85, 0x25, 0xeb, 0x62, 0xa5, 0x8d, 0x14, 0x1d, 0xbc,
-	0x9e, 0x60, 0xb0, 0x41, 0xc5, 0xa5, 0xee, 0x7b, 0x81, 0x2b, 0x4e, 0xdb, 0x90, 0xfa, 0x9a, 0x17,
-	0xb8, 0x58, 0x60, 0xd0, 0x4d, 0x28, 0x1d, 0x10, 0xba, 0xc3, 0xf7, 0x9f, 0x3b, 0xc4, 0x57, 0xf2,
-	0x2d, 0xef, 0x2e, 0x67, 0x69, 0x57, 0x8f, 0x06, 0xcd, 0x92, 0x78, 0xc4, 0x52, 0x08, 0x6a, 0x01,
-	0xb0, 0xbd, 0x90, 0xc6, 0xc2, 0x9c, 0x46, 0x69, 0xa9, 0x78, 0xb1, 0xda, 0x3e, 0xc3, 0xed, 0xdb,
-	0x4e, 0xa0, 0xd8, 0xa0, 0x40, 0x57, 0x61, 0x96, 0x79, 0x41, 0xb7, 0xef, 0x3b, 0x94, 0x03, 0x1a,
-	0x65, 0x61, 0xe7, 0x79, 0x65, 0xe7, 0xec, 0xb6, 0x81, 0xc3, 0x19, 0x4a, 0xae, 0xa9, 0xe3, 0xc4,
-	0xa4, 0x1b, 0x52, 0x8f, 0xb0, 0xc6, 0x4c, 0xaa, 0x69, 0x35, 0x81, 0x62, 0x83, 0x02, 0x3d, 0x05,
-	0x25, 0xb1, 0xf3, 0x8d, 0x8a, 0x50, 0x51, 0x57, 0x2a, 0x4a, 0xe2, 0x58, 0xb0, 0xc4, 0xa1, 0x67,
-	0x60, 0x46, 0xdd, 0x9a, 0x46, 0x55, 0x90, 0x9d, 0x55, 0x64, 0x33, 0xda, 0xad, 0x35, 0x1e, 0x7d,
-	0x0b, 0x10, 0x8b, 0x43, 0xea, 0x74, 0x89, 0x42, 0xbd, 0xea, 0xb0, 0xbd, 0x06, 0x08, 0xae, 0x05,
-	0xc5, 0x85, 0xb6, 0x47, 0x28, 0xf0, 0x09, 0x5c, 0xf6, 0xef, 0x2c, 0x38, 0x6b, 0xf8, 0x82, 0xf0,
-	0xbb, 0xab, 0x30, 0xdb, 0x35, 0x6e, 0x9d, 0xf2, 0x8b, 0x64, 0x67, 0xcc, 0x1b, 0x89, 0x33, 0x94,
-	0x88, 0x40, 0x95, 0x2a, 0x49, 0x3a, 0xba, 0x5c, 0xce, 0xed, 0xb4, 0xda, 0x86, 0x54, 0x93, 0x01,
-	0x64, 0x38, 0x95, 0x6c, 0xff, 0xc3, 0x12, 0x0e, 0xac, 0xe3, 0x0d, 0xba, 0x68, 0xc4, 0x34, 0x4b,
-	0x1c, 0xc7, 0xec, 0x98, 0x78, 0x74, 0x4a, 0x20, 0x28, 0xfc, 0x5f, 0x04, 0x82, 0x6b, 0x95, 0x5f,
-	0xbd, 0xdb, 0x9c, 0x7a, 0xeb, 0xaf, 0x4b, 0x53, 0x76, 0x0f, 0xea, 0xab, 0x94, 0x38, 0x31, 0xb9,
-	0x15, 0xc5, 0x62, 0x01, 0x36, 0x94, 0x5d, 0x7a, 0x88, 0xfb, 0x81, 0x5a, 0x28, 0xf0, 0xfb, 0xbd,
-	0x26, 0x20, 0x58, 0x61, 0xf8, 0xf9, 0xed, 0x7a, 0xc4, 0x77, 0x37, 0x9d, 0xc0, 0xe9, 0x12, 0xaa,
-	0x6e, 0x60, 0xb2, 0xab, 0xd7, 0x0d, 0x1c, 0xce, 0x50, 0xda, 0x3f, 0x2d, 0x42, 0x7d, 0x8d, 0xf8,
-	0x24, 0xd5, 0x77, 0x1d, 0x50, 0x97, 0x3a, 0x1d, 0xb2, 0x45, 0xa8, 0x17, 0xba, 0xdb, 0xa4, 0x13,
-	0x06, 0x2e, 0x13, 0x1e, 0x51, 0x6c, 0x7f, 0x8e, 0xfb, 0xd9, 0x8d, 0x11, 0x2c, 0x3e, 0x81, 0x03,
-	0xf9, 0x50, 0x8f, 0xa8, 0x78, 0xf6, 0x62, 0x95, 0x7b, 0xf8, 0x9d, 0x7f, 0x3e, 0xdf, 0x56, 0x6f,
-	0x99, 0xac, 0xed, 0xf9, 0xa3, 0x41, 0xb3, 0x9e, 0x01, 0xe1, 0xac, 0x70, 0xf4, 0x4d, 0x98, 0x0b,
-	0x69, 0xb4, 0xe7, 0x04, 0x6b, 0x24, 0x22, 0x81, 0x4b, 0x82, 0x98, 0x89, 0x5d, 0xa8, 0xb4, 0xcf,
-	0xf3, 0x8c, 0x71, 0x6b, 0x08, 0x87, 0x47, 0xa8, 0xd1, 0x3d, 0x98, 0x8f, 0x68, 0x18, 0x39, 0x5d,
-	0x87, 0x4b, 0xdc, 0x0a, 0x7d, 0xaf, 0x73, 0x28, 0xe2, 0x54, 0xb5, 0x7d, 0xe9, 0x68, 0xd0, 0x9c,
-	0xdf, 0x1a, 0x46, 0x1e, 0x0f, 0x9a, 0xe7, 0xc4, 0xd6, 0x71, 0x48, 0x8a, 0xc4, 0xa3, 0x62, 0x8c,
-	0x33, 0x2c, 0x8d, 0x3b, 0x43, 0x7b, 0x03, 0x2a, 0x6b, 0x7d, 0x2a, 0xb8, 0xd0, 0xcb, 0x50, 0x71,
-	0xd5, 0xb3, 0xda, 0xf9, 0x27, 0x75, 0xca, 0xd5, 0x34, 0xc7, 0x83, 0x66, 0x9d, 0x17, 0x09, 0x2d,
-	0x0d, 0xc0, 0x09, 0x8b, 0x7d, 0x1f, 0xea, 0xeb, 0x0f, 0xa3, 0x90, 0xc6, 0xfa, 0x4c, 0x9f, 0x86,
-	0x32, 0x11, 0x00, 0x21, 0xad, 0x92, 0xe6, 0x09, 0x49, 0x86, 0x15, 0x96, 0xc7, 0x2d, 0xf2, 0xd0,
-	0xe9, 0xc4, 0x2a, 0xe0, 0x27, 0x71, 0x6b, 0x9d, 0x03, 0xb1, 0xc4, 0xd9, 0xef, 0x5b, 0x50, 0x16,
-	0x1e, 0xc5, 0xd0, 0x6d, 0x28, 0xf6, 0x9c, 0x48, 0x25, 0xab, 0x17, 0xf2, 0x9d, 0xac, 0x64, 0x6d,
-	0x6d, 0x3a, 0xd1, 0x7a, 0x10, 0xd3, 0xc3, 0x76, 0x4d, 0x29, 0x29, 0x6e, 0x3a, 0x11, 0xe6, 0xe2,
-	0x16, 0x5c, 0xa8, 0x68, 0x2c, 0x9a, 0x83, 0xe2, 0x3e, 0x39, 0x94, 0x01, 0x09, 0xf3, 0x47, 0xd4,
-	0x86, 0xd2, 0x81, 0xe3, 0xf7, 0x89, 0xf2, 0xa7, 0x4b, 0x93, 0x68, 0xc5, 0x92, 0xf5, 0x5a, 0xe1,
-	0xaa, 0x65, 0xdf, 0x02, 0xb8, 0x41, 0x92, 0x1d, 0x5a, 0x81, 0xb3, 0x3a, 0xda, 0x64, 0x83, 0xe0,
-	0xe7, 0x95, 0x79, 0x67, 0x71, 0x16, 0x8d, 0x87, 0xe9, 0xed, 0xfb, 0x50, 0x15, 0x81, 0x92, 0xe7,
-	0xbb, 0x34, 0x03, 0x58, 0x8f, 0xc8, 0x00, 0x3a, 0x61, 0x16, 0xc6, 0x25, 0x4c, 0x23, 0x2e, 0xf8,
-	0x50, 0x97, 0xbc, 0x3a, 0x87, 0xe7, 0xd2, 0x70, 0x09, 0x2a, 0xda, 0x4c, 0xa5, 0x25, 0xa9, 0xdd,
-	0xb4, 0x20, 0x9c, 0x50, 0x18, 0xda, 0xf6, 0x20, 0x13, 0xf4, 0xf3, 0x29, 0x33, 0x12, 0x5a, 0xe1,
-	0xd1, 0x09, 0xcd, 0xd0, 0xf4, 0x23, 0x68, 0x8c, 0x2b, 0xf8, 0x1e, 0x23, 0x2d, 0xe5, 0x37, 0xc5,
-	0x7e, 0xdb, 0x82, 0x39, 0x53, 0x52, 0xfe, 0xe3, 0xcb, 0xaf, 0xe4, 0xf4, 0xd2, 0xc8, 0xd8, 0x91,
-	0x5f, 0x5b, 0x70, 0x3e, 0xb3, 0xb4, 0x89, 0x4e, 0x7c, 0x02, 0xa3, 0x4c, 0xe7, 0x28, 0x4e, 0xe0,
-	0x1c, 0xcb, 0x50, 0xdb, 0x08, 0xbc, 0xd8, 0x73, 0x7c, 0xef, 0x07, 0x84, 0x9e, 0x5e, 0x4c, 0xda,
-	0x7f, 0xb0, 0x60, 0xd6, 0xe0, 0x60, 0xe8, 0x3e, 0xcc, 0xf0, 0xb8, 0xeb, 0x05, 0x5d, 0x15, 0x3b,
-	0x72, 0xd6, 0x0c, 0x86, 0x90, 0x74, 0x5d, 0x5b, 0x52, 0x12, 0xd6, 0x22, 0xd1, 0x16, 0x94, 0x29,
-	0x61, 0x7d, 0x3f, 0x9e, 0x2c, 0x44, 0x6c, 0xc7, 0x4e, 0xdc, 0x67, 0x32, 0x36, 0x63, 0xc1, 0x8f,
-	0x95, 0x1c, 0xfb, 0xcf, 0x05, 0xa8, 0xdf, 0x74, 0x76, 0x88, 0xbf, 0x4d, 0x7c, 0xd2, 0x89, 0x43,
-	0x8a, 0x7e, 0x08, 0xb5, 0x9e, 0x13, 0x77, 0xf6, 0x04, 0x54, 0x97, 0xeb, 0x6b, 0xf9, 0x14, 0x65,
-	0x24, 0xb5, 0x36, 0x53, 0x31, 0x32, 0x20, 0x9e, 0x53, 0x0b, 0xab, 0x19, 0x18, 0x6c, 0x6a, 0x13,
-	0x3d, 0x96, 0x78, 0x5f, 0x7f, 0x18, 0xf1, 0x5a, 0x62, 0xf2, 0xd6, 0x2e, 0x63, 0x02, 0x26, 0x6f,
-	0xf6, 0x3d, 0x4a, 0x7a, 0x24, 0x88, 0xd3, 0x1e, 0x6b, 0x73, 0x48, 0x3e, 0x1e, 0xd1, 0xb8, 0xf0,
-	0x0a, 0xcc, 0x0d, 0x1b, 0x7f, 0x42, 0xbc, 0x3e, 0x6f, 0xc6, 0xeb, 0xaa, 0x19, 0x81, 0x7f, 0x6b,
-	0x41, 0x63, 0x9c, 0x21, 0xe8, 0x8b, 0x86, 0xa0, 0x34, 0x47, 0xbc, 0x46, 0x0e, 0xa5, 0xd4, 0x75,
-	0xa8, 0x84, 0x11, 0xef, 0x8a, 0x43, 0xaa, 0xfc, 0xfc, 0x19, 0xed, 0xbb, 0xb7, 0x14, 0xfc, 0x78,
-	0xd0, 0xbc, 0x90, 0x11, 0xaf, 0x11, 0x38, 0x61, 0xe5, 0x89, 0x59, 0xd8, 0xc3, 0x8b, 0x85, 0x24,
-	0x31, 0xdf, 0x15, 0x10, 0xac, 0x30, 0xf6, 0xef, 0x2d, 0x98, 0x16, 0x55, 0xf2, 0x7d, 0xa8, 0xf0,
-	0xfd, 0x73, 0x9d, 0xd8, 0x11, 0x76, 0xe5, 0xee, 0xcf, 0x38, 0xf7, 0x26, 0x89, 0x9d, 0xf4, 0x7e,
-	0x69, 0x08, 0x4e, 0x24, 0x22, 0x0c, 0x25, 0x2f, 0x26, 0x3d, 0x7d, 0x90, 0xcf, 0x8e, 0x15, 0xad,
-	0xa6, 0x03, 0x2d, 0xec, 0x3c, 0x58, 0x7f, 0x18, 0x93, 0x80, 0x1f, 0x46, 0x1a, 0x0c, 0x36, 0xb8,
-	0x0c, 0x2c, 0x45, 0xd9, 0xff, 0xb6, 0x20, 0x51, 0xc5, 0xaf, 0x3b, 0x23, 0xfe, 0xee, 0x4d, 0x2f,
-	0xd8, 0x57, 0xdb, 0x9a, 0x98, 0xb3, 0xad, 0xe0, 0x38, 0xa1, 0x38, 0x29, 0x21, 0x16, 0x26, 0x4b,
-	0x88, 0x5c, 0x61, 0x27, 0x0c, 0x62, 0x2f, 0xe8, 0x8f, 0xc4, 0x97, 0x55, 0x05, 0xc7, 0x09, 0x05,
-	0xaf, 0x3b, 0x29, 0xe9, 0x39, 0x5e, 0xe0, 0x05, 0x5d, 0xbe, 0x88, 0xd5, 0xb0, 0x1f, 0xc4, 0xa2,
-	0x00, 0x53, 0x75, 0x27, 0x1e, 0xc1, 0xe2, 0x13, 0x38, 0xec, 0x3f, 0x15, 0xa1, 0xc6, 0xd7, 0xac,
-	0x33, 0xfb, 0x4b, 0x50, 0xf7, 0x4d, 0x2f, 0x50, 0x6b, 0xbf, 0xa0, 0x4c, 0xc9, 0xde, 0x6b, 0x9c,
-	0xa5, 0xe5, 0xcc, 0xa2, 0x5c, 0x4e, 0x98, 0x0b, 0x59, 0xe6, 0xeb, 0x26, 0x12, 0x67, 0x69, 0x79,
-	0xbc, 0x7e, 0xc0, 0xef, 0x87, 0x2a, 0x44, 0x93, 0x23, 0xfa, 0x36, 0x07, 0x62, 0x89, 0x3b, 0x69,
-	0x9f, 0xa7, 0x27, 0xdc, 0xe7, 0x6b, 0x70, 0x86, 0x3b, 0x44, 0xd8, 0x8f, 0x75, 0xb5, 0x5e, 0x12,
-	0xbb, 0x86, 0x8e, 0x06, 0xcd, 0x33, 0xb7, 0x33, 0x18, 0x3c, 0x44, 0xc9, 0x6d, 0xf4, 0xbd, 0x9e,
-	0x17, 0x37, 0x66, 0x04, 0x4b, 0x62, 0xe3, 0x4d, 0x0e, 0xc4, 0x12, 0x97, 0x39, 0xc8, 0xca, 0xa9,
-	0x07, 0xb9, 0x09, 0xe7, 0x1c, 0xdf, 0x0f, 0x1f, 0x88, 0x65, 0xb6, 0xc3, 0x70, 0xbf, 0xe7, 0xd0,
-	0x7d, 0x26, 0x7a, 0xdc, 0x4a, 0xfb, 0x0b, 0x8a, 0xf1, 0xdc, 0xca, 0x28, 0x09, 0x3e, 0x89, 0xcf,
-	0xfe, 0x67, 0x01, 0x90, 0xec, 0x56, 0x5c, 0x59, 0xc4, 0xc9, 0x40, 0xf3, 0x0c, 0xcc, 0xf4, 0x54,
-	0xb7, 0x63, 0x65, 0xf3, 0x9c, 0x6e, 0x74, 0x34, 0x1e, 0x6d, 0x42, 0x55, 0x5e, 0xf8, 0xd4, 0x89,
-	0x97, 0x15, 0x71, 0xf5, 0x96, 0x46, 0x1c, 0x0f, 0x9a, 0x0b, 0x19, 0x35, 0x09, 0xe6, 0xf6, 0x61,
-	0x44, 0x70, 0x2a, 0x01, 0x5d, 0x01, 0x70, 0x22, 0xcf, 0x1c, 0x6d, 0x55, 0xd3, 0xd1, 0x48, 0xda,
-	0xa4, 0x62, 0x83, 0x0a, 0xbd, 0x0a, 0xd3, 0x7c, 0xe3, 0xd5, 0xdc, 0xe3, 0xcb, 0xf9, 0xc2, 0x06,
-	0x3f, 0xba, 0x76, 0x85, 0xe7, 0x52, 0xfe, 0x84, 0x85, 0x04, 0x74, 0x0f, 0xca, 0xc2, 0xcb, 0xe4,
-	0x21, 0x4f, 0x58, 0xff, 0x8a, 0x66, 0x48, 0x15, 0xef, 0xc7, 0xc9, 0x13, 0x56, 0x12, 0xed, 0x37,
-	0xa1, 0xba, 0xe9, 0x75, 0x68, 0xc8, 0xd5, 0xf1, 0x0d, 0x66, 0x99, 0xe6, 0x2f, 0xd9, 0x60, 0xed,
-	0x4b, 0x1a, 0xcf, 0x9d, 0x28, 0x70, 0x82, 0x50, 0xb6, 0x78, 0xa5, 0xd4, 0x89, 0x5e, 0xe7, 0x40,
-	0x2c, 0x71, 0xd7, 0xce, 0xf3, 0xfa, 0xe1, 0x67, 0xef, 0x35, 0xa7, 0xde, 0x79, 0xaf, 0x39, 0xf5,
-	0xee, 0x7b, 0xaa, 0x96, 0xf8, 0x7b, 0x0d, 0xe0, 0xd6, 0xce, 0xf7, 0x49, 0x47, 0xc6, 0xa8, 0xd3,
-	0x07, 0x53, 0xbc, 0x26, 0x54, 0xf3, 0x50, 0x31, 0xc4, 0x29, 0x0c, 0xd5, 0x84, 0x06, 0x0e, 0x67,
-	0x28, 0xd1, 0x32, 0x54, 0x93, 0x61, 0x95, 0x3a, 0xb6, 0x79, 0xed, 0x06, 0xc9, 0x44, 0x0b, 0xa7,
-	0x34, 0x99, 0x80, 0x39, 0x7d, 0x6a, 0xc0, 0x6c, 0x43, 0xb1, 0xef, 0xb9, 0xe2, 0x54, 0xaa, 0xed,
-	0xe7, 0x74, 0xc2, 0xba, 0xb3, 0xb1, 0x76, 0x3c, 0x68, 0x3e, 0x39, 0x6e, 0xd2, 0x1b, 0x1f, 0x46,
-	0x84, 0xb5, 0xee, 0x6c, 0xac, 0x61, 0xce, 0x7c, 0x52, 0x30, 0x28, 0x4f, 0x18, 0x0c, 0xae, 0x00,
-	0xa8, 0x55, 0x73, 0x6e, 0x79, 0xab, 0x13, 0xef, 0xbc, 0x91, 0x60, 0xb0, 0x41, 0x85, 0x18, 0xcc,
-	0x77, 0x28, 0x91, 0xce, 0xee, 0xf5, 0x08, 0x8b, 0x9d, 0x9e, 0x1c, 0x5d, 0x4d, 0xe6, 0xaa, 0x4f,
-	0x28, 0x35, 0xf3, 0xab, 0xc3, 0xc2, 0xf0, 0xa8, 0x7c, 0x14, 0xc2, 0xbc, 0xab, 0xba, 0xe7, 0x54,
-	0x69, 0x75, 0x62, 0xa5, 0x17, 0xb8, 0xc2, 0xb5, 0x61, 0x41, 0x78, 0x54, 0x36, 0xfa, 0x1e, 0x2c,
-	0x68, 0xe0, 0xe8, 0x08, 0x43, 0x0c, 0xd3, 0x8a, 0xed, 0xc5, 0xa3, 0x41, 0x73, 0x61, 0x6d, 0x2c,
-	0x15, 0x7e, 0x84, 0x04, 0xe4, 0x42, 0xd9, 0x97, 0xd5, 0x60, 0x4d, 0x64, 0xf0, 0xaf, 0xe7, 0x5b,
-	0x45, 0xea, 0xfd, 0x2d, 0xb3, 0x0a, 0x4c, 0x5a, 0x74, 0x55, 0x00, 0x2a, 0xd9, 0xe8, 0x21, 0xd4,
-	0x9c, 0x20, 0x08, 0x63, 0x47, 0x0e, 0x55, 0x66, 0x85, 0xaa, 0x95, 0x89, 0x55, 0xad, 0xa4, 0x32,
-	0x86, 0xaa, 0x4e, 0x03, 0x83, 0x4d, 0x55, 0xe8, 0x01, 0x9c, 0x0d, 0x1f, 0x04, 0x84, 0x62, 0xb2,
-	0x4b, 0x28, 0x09, 0x3a, 0x84, 0x35, 0xea, 0x42, 0xfb, 0x57, 0x73, 0x6a, 0xcf, 0x30, 0xa7, 0x2e,
-	0x9d, 0x85, 0x33, 0x3c, 0xac, 0x05, 0xb5, 0x00, 0x76, 0xbd, 0x40, 0xf5, 0x0e, 0x8d, 0x33, 0xe9,
-	0xf4, 0xf5, 0x7a, 0x02, 0xc5, 0x06, 0x05, 0x7a, 0x01, 0x6a, 0x1d, 0xbf, 0xcf, 0x62, 0x22, 0xc7,
-	0xbc, 0x67, 0xc5, 0x0d, 0x4a, 0xd6, 0xb7, 0x9a, 0xa2, 0xb0, 0x49, 0x87, 0xf6, 0x60, 0xd6, 0x33,
-	0x9a, 0x94, 0xc6, 0x9c, 0xf0, 0xc5, 0x2b, 0x13, 0x77, 0x26, 0xac, 0x3d, 0xc7, 0x23, 0x91, 0x09,
-	0xc1, 0x19, 0xc9, 0xa8, 0x0f, 0xf5, 0x9e, 0x99, 0x6a, 0x1a, 0xf3, 0x62, 0x1f, 0xaf, 0xe6, 0x53,
-	0x35, 0x9a, 0x0c, 0xd3, 0x7a, 0x24, 0x83, 0xc3, 0x59, 0x2d, 0x0b, 0x5f, 0x83, 0xda, 0x7f, 0x59,
-	0xaa, 0xf3, 0x52, 0x7f, 0xd8, 0x63, 0x26, 0x2a, 0xf5, 0xdf, 0x2f, 0xc0, 0x99, 0xec, 0x39, 0x27,
-	0x2d, 0xb1, 0x35, 0xf6, 0x6b, 0x81, 0x4e, 0x06, 0xc5, 0xb1, 0xc9, 0x40, 0xc5, 0xdc, 0xe9, 0xc7,
-	0x89, 0xb9, 0xd9, 0x74, 0x5e, 0xca, 0x95, 0xce, 0x5b, 0x00, 0xbc, 0xdc, 0xa1, 0xa1, 0xef, 0x13,
-	0x2a, 0x42, 0x74, 0x45, 0x7d, 0x0f, 0x48, 0xa0, 0xd8, 0xa0, 0xe0, 0xb5, 0xed, 0x8e, 0x1f, 0x76,
-	0xf6, 0xc5, 0x16, 0xe8, 0xf0, 0x22, 0x82, 0x73, 0x45, 0xd6, 0xb6, 0xed, 0x11, 0x2c, 0x3e, 0x81,
-	0xc3, 0x3e, 0x84, 0x0b, 0x5b, 0x0e, 0xe5, 0x8e, 0x94, 0x5e, 0x65, 0xd1, 0x3c, 0xbc, 0x31, 0xd2,
-	0x9a, 0x3c, 0x37, 0x69, 0x48, 0x48, 0x17, 0x9d, 0xc2, 0xd2, 0xf6, 0xc4, 0xfe, 0x8b, 0x05, 0x4f,
-	0x9c, 0xa8, 0xfb, 0x33, 0x68, 0x8d, 0xde, 0xc8, 0xb6, 0x46, 0x2f, 0xe5, 0x1c, 0x21, 0x9f, 0x64,
-	0xed, 0x98, 0x46, 0x69, 0x06, 0x4a, 0x5b, 0xbc, 0xec, 0xb4, 0x7f, 0x69, 0xc1, 0xac, 0x78, 0x9a,
-	0x64, 0xfc, 0xde, 0x84, 0xd2, 0x6e, 0xa8, 0x47, 0x6c, 0x15, 0xf9, 0xa5, 0xea, 0x3a, 0x07, 0x60,
-	0x09, 0x7f, 0x8c, 0xf9, 0xfc, 0xdb, 0x16, 0x64, 0x07, 0xdf, 0xe8, 0x15, 0xe9, 0xf3, 0x56, 0x32,
-	0x99, 0x9e, 0xd0, 0xdf, 0x5f, 0x1e, 0xd7, 0xd8, 0x9d, 0xcb, 0x35, 0xe5, 0xbc, 0x04, 0x55, 0x1c,
-	0x86, 0xf1, 0x96, 0x13, 0xef, 0x31, 0xbe, 0xf0, 0x88, 0x3f, 0xa8, 0xbd, 0x11, 0x0b, 0x17, 0x18,
-	0x2c, 0xe1, 0xf6, 0x2f, 0x2c, 0x78, 0x62, 0xec, 0x27, 0x11, 0x7e, 0xf5, 0x3a, 0xc9, 0x9b, 0x5a,
-	0x51, 0xe2, 0x85, 0x29, 0x1d, 0x36, 0xa8, 0x78, 0x47, 0x96, 0xf9, 0x8e, 0x32, 0xdc, 0x91, 0x65,
-	0xb4, 0xe1, 0x2c, 0xad, 0xfd, 0xaf, 0x02, 0x94, 0xe5, 0x98, 0xe7, 0x7f, 0xec, 0xb1, 0x4f, 0x43,
-	0x99, 0x09, 0x3d, 0xca, 0xbc, 0x24, 0x9b, 0x4b, 0xed, 0x58, 0x61, 0x45, 0x17, 0x43, 0x18, 0x73,
-	0xba, 0x3a, 0xca, 0xa5, 0x5d, 0x8c, 0x04, 0x63, 0x8d, 0x47, 0x2f, 0x42, 0x99, 0x12, 0x87, 0x25,
-	0xfd, 0xe1, 0xa2, 0x16, 0x89, 0x05, 0xf4, 0x78, 0xd0, 0x9c, 0x55, 0xc2, 0xc5, 0x3b, 0x56, 0xd4,
-	0xe8, 0x1e, 0xcc, 0xb8, 0x24, 0x76, 0x3c, 0x5f, 0x77, 0x0c, 0xcf, 0x4f, 0x32, 0x0e, 0x5b, 0x93,
-	0xac, 0xed, 0x1a, 0xb7, 0x49, 0xbd, 0x60, 0x2d, 0x90, 0x47, 0xe8, 0x4e, 0xe8, 0xca, 0x2f, 0xa9,
-	0xa5, 0x34, 0x42, 0xaf, 0x86, 0x2e, 0xc1, 0x02, 0x63, 0xbf, 0x63, 0x41, 0x4d, 0x4a, 0x5a, 0x75,
-	0xfa, 0x8c, 0xa0, 0xcb, 0xc9, 0x2a, 0xe4, 0x71, 0xeb, 0x9a, 0x71, 0x9a, 0x77, 0x59, 0xc7, 0x83,
-	0x66, 0x55, 0x90, 0x89, 0x96, 0x4b, 0x2f, 0xc0, 0xd8, 0xa3, 0xc2, 0x29, 0x7b, 0xf4, 0x14, 0x94,
-	0xc4, 0xed, 0x51, 0x9b, 0x99, 0xdc, 0x75, 0x71, 0xc1, 0xb0, 0xc4, 0xd9, 0x1f, 0x17, 0xa0, 0x9e,
-	0x59, 0x5c, 0x8e, 0xae, 0x23, 0x19, 0xbd, 0x16, 0x72, 0x8c, 0xf3, 0xc7, 0x7f, 0xff, 0xfe, 0x0e,
-	0x94, 0x3b, 0x7c, 0x7d, 0xfa, 0x0f, 0x08, 0x97, 0x27, 0x39, 0x0a, 0xb1, 0x33, 0xa9, 0x27, 0x89,
-	0x57, 0x86, 0x95, 0x40, 0x74, 0x03, 0xe6, 0x29, 0x89, 0xe9, 0xe1, 0xca, 0x6e, 0x4c, 0xa8, 0x39,
-	0x07, 0x28, 0xa5, 0x75, 0x39, 0x1e, 0x26, 0xc0, 0xa3, 0x3c, 0x3a, 0xa7, 0x96, 0x1f, 0x23, 0xa7,
-	0xda, 0x3b, 0x30, 0x7b, 0xdb, 0xd9, 0xf1, 0x93, 0x6f, 0x8a, 0x18, 0xea, 0x5e, 0xd0, 0xf1, 0xfb,
-	0x2e, 0x91, 0xd1, 0x58, 0x47, 0x2f, 0x7d, 0x69, 0x37, 0x4c, 0xe4, 0xf1, 0xa0, 0x79, 0x2e, 0x03,
-	0x90, 0x1f, 0xd1, 0x70, 0x56, 0x84, 0xed, 0xc3, 0xf4, 0x67, 0xd8, 0xa7, 0x7e, 0x17, 0xaa, 0x69,
-	0x27, 0xf1, 0x29, 0xab, 0xb4, 0xdf, 0x80, 0x0a, 0xf7, 0x78, 0xdd, 0x01, 0x9f, 0x52, 0x16, 0x65,
-	0x0b, 0x96, 0x42, 0x9e, 0x82, 0xc5, 0xee, 0x41, 0xfd, 0x4e, 0xe4, 0x3e, 0xe6, 0x57, 0xe5, 0x42,
-	0xee, 0xac, 0x75, 0x05, 0xe4, 0x3f, 0x35, 0x78, 0x82, 0x90, 0x99, 0xdb, 0x48, 0x10, 0x66, 0xe2,
-	0x35, 0xbe, 0x2a, 0xfc, 0xc4, 0x02, 0x10, 0xa3, 0x9f, 0xf5, 0x03, 0x12, 0xc4, 0x7c, 0x1f, 0xb8,
-	0x53, 0x0d, 0xef, 0x83, 0x88, 0x0c, 0x02, 0x83, 0xee, 0x40, 0x39, 0x94, 0xde, 0x24, 0xc7, 0xfc,
-	0x13, 0x4e, 0x4c, 0x93, 0x8b, 0x24, 0xfd, 0x09, 0x2b, 0x61, 0xed, 0x8b, 0x1f, 0x7c, 0xb2, 0x38,
-	0xf5, 0xe1, 0x27, 0x8b, 0x53, 0x1f, 0x7d, 0xb2, 0x38, 0xf5, 0xd6, 0xd1, 0xa2, 0xf5, 0xc1, 0xd1,
-	0xa2, 0xf5, 0xe1, 0xd1, 0xa2, 0xf5, 0xd1, 0xd1, 0xa2, 0xf5, 0xf1, 0xd1, 0xa2, 0xf5, 0xce, 0xdf,
-	0x16, 0xa7, 0xee, 0x15, 0x0e, 0x2e, 0xff, 0x27, 0x00, 0x00, 0xff, 0xff, 0xe5, 0xe0, 0x33, 0x2e,
-	0x95, 0x26, 0x00, 0x00,
+	// 2830 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xcc, 0x1a, 0xcd, 0x6f, 0x1c, 0x57,
+	0xdd, 0xb3, 0x5f, 0xde, 0xfd, 0xad, 0x37, 0xb1, 0x5f, 0x12, 0x98, 0x1a, 0xe1, 0x75, 0xa7, 0xa8,
+	0x4a, 0x21, 0x5d, 0x37, 0x29, 0xad, 0x42, 0x4a, 0x0b, 0x5e, 0xdb, 0x49, 0x4d, 0xe3, 0xda, 0x7a,
+	0x4e, 0x82, 0x08, 0x11, 0xea, 0x78, 0xe7, 0x79, 0x3d, 0x78, 0x76, 0x66, 0xfa, 0xde, 0xac, 0x13,
+	0xc3, 0x81, 0x1e, 0x40, 0x80, 0x04, 0x55, 0x8f, 0x88, 0x03, 0x6a, 0x05, 0x7f, 0x01, 0x27, 0x4e,
+	0x9c, 0x2a, 0xd1, 0x63, 0x25, 0x2e, 0x95, 0x40, 0x56, 0x63, 0x90, 0xe8, 0x0d, 0x71, 0xf5, 0x09,
+	0xbd, 0x8f, 0x99, 0x79, 0xb3, 0x1f, 0xf1, 0x6c, 0x53, 0x2a, 0x4e, 0xde, 0xf9, 0x7d, 0xbf, 0xf7,
+	0x7e, 0xef, 0xf7, 0xf5, 0x0c, 0x1b, 0xfb, 0x57, 0x59, 0xcb, 0x0d, 0x96, 0xf6, 0xfb, 0x3b, 0x84,
+	0xfa, 0x24, 0x22, 0x6c, 0xe9, 0x80, 0xf8, 0x4e, 0x40, 0x97, 0x14, 0xc2, 0x0e, 0xdd, 0x9e, 0xdd,
+	0xd9, 0x73, 0x7d, 0x42, 0x0f, 0x97, 0xc2, 0xfd, 0x2e, 0x07, 0xb0, 0xa5, 0x1e, 0x89, 0xec, 0xa5,
+	0x83, 0xcb, 0x4b, 0x5d, 0xe2, 0x13, 0x6a, 0x47, 0xc4, 0x69, 0x85, 0x34, 0x88, 0x02, 0xf4, 0x15,
+	0xc9, 0xd5, 0xd2, 0xb9, 0x5a, 0xe1, 0x7e, 0x97, 0x03, 0x58, 0x8b, 0x73, 0xb5, 0x0e, 0x2e, 0xcf,
+	0x3f, 0xdb, 0x75, 0xa3, 0xbd, 0xfe, 0x4e, 0xab, 0x13, 0xf4, 0x96, 0xba, 0x41, 0x37, 0x58, 0x12,
+	0xcc, 0x3b, 0xfd, 0x5d, 0xf1, 0x25, 0x3e, 0xc4, 0x2f, 0x29, 0x74, 0x7e, 0xac, 0x29, 0xb4, 0xef,
+	0x47, 0x6e, 0x8f, 0x0c, 0x5a, 0x31, 0xff, 0xe2, 0x69, 0x0c, 0xac, 0xb3, 0x47, 0x7a, 0xf6, 0x20,
+	0x9f, 0xf5, 0x97, 0x22, 0x54, 0x97, 0xb7, 0xd6, 0x6f, 0xd0, 0xa0, 0x1f, 0xa2, 0x45, 0x28, 0xf9,
+	0x76, 0x8f, 0x98, 0xc6, 0xa2, 0x71, 0xb1, 0xd6, 0x9e, 0xf9, 0xe0, 0xa8, 0x39, 0x75, 0x7c, 0xd4,
+	0x2c, 0xbd, 0x6e, 0xf7, 0x08, 0x16, 0x18, 0xe4, 0x41, 0xf5, 0x80, 0x50, 0xe6, 0x06, 0x3e, 0x33,
+	0x0b, 0x8b, 0xc5, 0x8b, 0xf5, 0x2b, 0xaf, 0xb4, 0xf2, 0xac, 0xbf, 0x25, 0x14, 0xdc, 0x91, 0xac,
+	0xd7, 0x03, 0xba, 0xea, 0xb2, 0x4e, 0x70, 0x40, 0xe8, 0x61, 0x7b, 0x56, 0x69, 0xa9, 0x2a, 0x24,
+	0xc3, 0x89, 0x06, 0xf4, 0x53, 0x03, 0x66, 0x43, 0x4a, 0x76, 0x09, 0xa5, 0xc4, 0x51, 0x78, 0xb3,
+	0xb8, 0x68, 0x7c, 0x06, 0x6a, 0x4d, 0xa5, 0x76, 0x76, 0x6b, 0x40, 0x3e, 0x1e, 0xd2, 0x88, 0x7e,
+	0x6f, 0xc0, 0x3c, 0x23, 0xf4, 0x80, 0xd0, 0x65, 0xc7, 0xa1, 0x84, 0xb1, 0xf6, 0xe1, 0x8a, 0xe7,
+	0x12, 0x3f, 0x5a, 0x59, 0x5f, 0xc5, 0xcc, 0x2c, 0x89, 0x7d, 0xf8, 0x56, 0x3e, 0x83, 0xb6, 0xc7,
+	0xc9, 0x69, 0x5b, 0xca, 0xa2, 0xf9, 0xb1, 0x24, 0x0c, 0x3f, 0xc2, 0x0c, 0x6b, 0x17, 0x66, 0xe2,
+	0x83, 0xbc, 0xe9, 0xb2, 0x08, 0xdd, 0x81, 0x4a, 0x97, 0x7f, 0x30, 0xd3, 0x10, 0x06, 0xb6, 0xf2,
+	0x19, 0x18, 0xcb, 0x68, 0x9f, 0x51, 0xf6, 0x54, 0xc4, 0x27, 0xc3, 0x4a, 0x9a, 0xf5, 0xcb, 0x12,
+	0xd4, 0x97, 0xb7, 0xd6, 0x31, 0x61, 0x41, 0x9f, 0x76, 0x48, 0x0e, 0xa7, 0xb9, 0x02, 0xc0, 0xff,
+	0xb2, 0xd0, 0xee, 0x10, 0xc7, 0x2c, 0x2c, 0x1a, 0x17, 0xab, 0x6d, 0xa4, 0xe8, 0xe0, 0xf5, 0x04,
+	0x83, 0x35, 0x2a, 0x2e, 0x75, 0xdf, 0xf5, 0x1d, 0xb3, 0x98, 0x95, 0xfa, 0x9a, 0xeb, 0x3b, 0x58,
+	0x60, 0xd0, 0x4d, 0x28, 0x1f, 0x10, 0xba, 0xc3, 0xf7, 0x9f, 0x3b, 0xc4, 0xd7, 0xf2, 0x2d, 0xef,
+	0x0e, 0x67, 0x69, 0xd7, 0x8e, 0x8f, 0x9a, 0x65, 0xf1, 0x13, 0x4b, 0x21, 0xa8, 0x05, 0xc0, 0xf6,
+	0x02, 0x1a, 0x09, 0x73, 0xcc, 0xf2, 0x62, 0xf1, 0x62, 0xad, 0x7d, 0x86, 0xdb, 0xb7, 0x9d, 0x40,
+	0xb1, 0x46, 0x81, 0xae, 0xc2, 0x0c, 0x73, 0xfd, 0x6e, 0xdf, 0xb3, 0x29, 0x07, 0x98, 0x15, 0x61,
+	0xe7, 0x79, 0x65, 0xe7, 0xcc, 0xb6, 0x86, 0xc3, 0x19, 0x4a, 0xae, 0xa9, 0x63, 0x47, 0xa4, 0x1b,
+	0x50, 0x97, 0x30, 0x73, 0x3a, 0xd5, 0xb4, 0x92, 0x40, 0xb1, 0x46, 0x81, 0x9e, 0x82, 0xb2, 0xd8,
+	0x79, 0xb3, 0x2a, 0x54, 0x34, 0x94, 0x8a, 0xb2, 0x38, 0x16, 0x2c, 0x71, 0xe8, 0x19, 0x98, 0x56,
+	0xb7, 0xc6, 0xac, 0x09, 0xb2, 0xb3, 0x8a, 0x6c, 0x3a, 0x76, 0xeb, 0x18, 0x8f, 0xbe, 0x03, 0x88,
+	0x45, 0x01, 0xb5, 0xbb, 0x44, 0xa1, 0x5e, 0xb5, 0xd9, 0x9e, 0x09, 0x82, 0x6b, 0x5e, 0x71, 0xa1,
+	0xed, 0x21, 0x0a, 0x3c, 0x82, 0xcb, 0xfa, 0xa3, 0x01, 0x67, 0x35, 0x5f, 0x10, 0x7e, 0x77, 0x15,
+	0x66, 0xba, 0xda, 0xad, 0x33, 0x8d, 0xec, 0xce, 0xe8, 0x37, 0x12, 0x67, 0x28, 0x11, 0x81, 0x1a,
+	0x55, 0x92, 0xe2, 0xe8, 0x72, 0x39, 0xb7, 0xd3, 0xc6, 0x36, 0xa4, 0x9a, 0x34, 0x20, 0xc3, 0xa9,
+	0x64, 0xeb, 0x5f, 0x86, 0x70, 0xe0, 0x3b, 0x71, 0x94, 0xb9, 0xa8, 0xc5, 0x34, 0x43, 0x1c, 0xc7,
+	0xcc, 0x98, 0x78, 0x74, 0x4a, 0x20, 0x28, 0xfc, 0x5f, 0x04, 0x82, 0x6b, 0xd5, 0xdf, 0xbc, 0xdb,
+	0x9c, 0x7a, 0xeb, 0xef, 0x8b, 0x53, 0x56, 0x0f, 0x1a, 0x2b, 0x94, 0xd8, 0x11, 0xd9, 0x0c, 0x23,
+	0xb1, 0x00, 0x0b, 0x2a, 0x0e, 0x3d, 0xc4, 0x7d, 0x5f, 0x2d, 0x14, 0xf8, 0xfd, 0x5e, 0x15, 0x10,
+	0xac, 0x30, 0xfc, 0xfc, 0x76, 0x5d, 0xe2, 0x39, 0x1b, 0xb6, 0x6f, 0x77, 0x09, 0x55, 0x37, 0x30,
+	0xd9, 0xd5, 0xeb, 0x1a, 0x0e, 0x67, 0x28, 0xad, 0x9f, 0x17, 0xa1, 0xb1, 0x4a, 0x3c, 0x92, 0xea,
+	0xbb, 0x0e, 0xa8, 0x4b, 0xed, 0x0e, 0xd9, 0x22, 0xd4, 0x0d, 0x9c, 0x6d, 0xd2, 0x09, 0x7c, 0x87,
+	0x09, 0x8f, 0x28, 0xb6, 0xbf, 0xc0, 0xfd, 0xec, 0xc6, 0x10, 0x16, 0x8f, 0xe0, 0x40, 0x1e, 0x34,
+	0x42, 0x2a, 0x7e, 0xbb, 0x91, 0xca, 0x3d, 0xfc, 0xce, 0x3f, 0x9f, 0x6f, 0xab, 0xb7, 0x74, 0xd6,
+	0xf6, 0xdc, 0xf1, 0x51, 0xb3, 0x91, 0x01, 0xe1, 0xac, 0x70, 0xf4, 0x6d, 0x98, 0x0d, 0x68, 0xb8,
+	0x67, 0xfb, 0xab, 0x24, 0x24, 0xbe, 0x43, 0xfc, 0x88, 0x89, 0x5d, 0xa8, 0xb6, 0xcf, 0xf3, 0x8c,
+	0xb1, 0x39, 0x80, 0xc3, 0x43, 0xd4, 0xe8, 0x2e, 0xcc, 0x85, 0x34, 0x08, 0xed, 0xae, 0xcd, 0x25,
+	0x6e, 0x05, 0x9e, 0xdb, 0x39, 0x14, 0x71, 0xaa, 0xd6, 0xbe, 0x74, 0x7c, 0xd4, 0x9c, 0xdb, 0x1a,
+	0x44, 0x9e, 0x1c, 0x35, 0xcf, 0x89, 0xad, 0xe3, 0x90, 0x14, 0x89, 0x87, 0xc5, 0x68, 0x67, 0x58,
+	0x1e, 0x77, 0x86, 0xd6, 0x3a, 0x54, 0x57, 0xfb, 0x54, 0x70, 0xa1, 0x97, 0xa1, 0xea, 0xa8, 0xdf,
+	0x6a, 0xe7, 0x9f, 0x8c, 0x53, 0x6e, 0x4c, 0x73, 0x72, 0xd4, 0x6c, 0xf0, 0x22, 0xa1, 0x15, 0x03,
+	0x70, 0xc2, 0x62, 0xdd, 0x83, 0xc6, 0xda, 0x83, 0x30, 0xa0, 0x51, 0x7c, 0xa6, 0x4f, 0x43, 0x85,
+	0x08, 0x80, 0x90, 0x56, 0x4d, 0xf3, 0x84, 0x24, 0xc3, 0x0a, 0xcb, 0xe3, 0x16, 0x79, 0x60, 0x77,
+	0x22, 0x15, 0xf0, 0x93, 0xb8, 0xb5, 0xc6, 0x81, 0x58, 0xe2, 0xac, 0xf7, 0x0d, 0xa8, 0x08, 0x8f,
+	0x62, 0xe8, 0x16, 0x14, 0x7b, 0x76, 0xa8, 0x92, 0xd5, 0x0b, 0xf9, 0x4e, 0x56, 0xb2, 0xb6, 0x36,
+	0xec, 0x70, 0xcd, 0x8f, 0xe8, 0x61, 0xbb, 0xae, 0x94, 0x14, 0x37, 0xec, 0x10, 0x73, 0x71, 0xf3,
+	0x0e, 0x54, 0x63, 0x2c, 0x9a, 0x85, 0xe2, 0x3e, 0x39, 0x94, 0x01, 0x09, 0xf3, 0x9f, 0xa8, 0x0d,
+	0xe5, 0x03, 0xdb, 0xeb, 0x13, 0xe5, 0x4f, 0x97, 0x26, 0xd1, 0x8a, 0x25, 0xeb, 0xb5, 0xc2, 0x55,
+	0xc3, 0xda, 0x04, 0xb8, 0x41, 0x92, 0x1d, 0x5a, 0x86, 0xb3, 0x71, 0xb4, 0xc9, 0x06, 0xc1, 0x2f,
+	0x2a, 0xf3, 0xce, 0xe2, 0x2c, 0x1a, 0x0f, 0xd2, 0x5b, 0xf7, 0xa0, 0x26, 0x02, 0x25, 0xcf, 0x77,
+	0x69, 0x06, 0x30, 0x1e, 0x91, 0x01, 0xe2, 0x84, 0x59, 0x18, 0x97, 0x30, 0xb5, 0xb8, 0xe0, 0x41,
+	0x43, 0xf2, 0xc6, 0x39, 0x3c, 0x97, 0x86, 0x4b, 0x50, 0x8d, 0xcd, 0x54, 0x5a, 0x92, 0xda, 0x2d,
+	0x16, 0x84, 0x13, 0x0a, 0x4d, 0xdb, 0x1e, 0x64, 0x82, 0x7e, 0x3e, 0x65, 0x5a, 0x42, 0x2b, 0x3c,
+	0x3a, 0xa1, 0x69, 0x9a, 0x7e, 0x02, 0xe6, 0xb8, 0x82, 0xef, 0x31, 0xd2, 0x52, 0x7e, 0x53, 0xac,
+	0xb7, 0x0d, 0x98, 0xd5, 0x25, 0xe5, 0x3f, 0xbe, 0xfc, 0x4a, 0x4e, 0x2f, 0x8d, 0xb4, 0x1d, 0xf9,
+	0x9d, 0x01, 0xe7, 0x33, 0x4b, 0x9b, 0xe8, 0xc4, 0x27, 0x30, 0x4a, 0x77, 0x8e, 0xe2, 0x04, 0xce,
+	0xb1, 0x04, 0xf5, 0x75, 0xdf, 0x8d, 0x5c, 0xdb, 0x73, 0x7f, 0x44, 0xe8, 0xe9, 0xc5, 0xa4, 0xf5,
+	0x67, 0x03, 0x66, 0x34, 0x0e, 0x86, 0xee, 0xc1, 0x34, 0x8f, 0xbb, 0xae, 0xdf, 0x35, 0x8d, 0x49,
+	0x6a, 0x06, 0x4d, 0x48, 0xba, 0xae, 0x2d, 0x29, 0x09, 0xc7, 0x22, 0xd1, 0x16, 0x54, 0x28, 0x61,
+	0x7d, 0x2f, 0x9a, 0x2c, 0x44, 0x6c, 0x47, 0x76, 0xd4, 0x67, 0x32, 0x36, 0x63, 0xc1, 0x8f, 0x95,
+	0x1c, 0xeb, 0xaf, 0x05, 0x68, 0xdc, 0xb4, 0x77, 0x88, 0xb7, 0x4d, 0x3c, 0xd2, 0x89, 0x02, 0x8a,
+	0x7e, 0x0c, 0xf5, 0x9e, 0x1d, 0x75, 0xf6, 0x04, 0x34, 0x2e, 0xd7, 0x57, 0xf3, 0x29, 0xca, 0x48,
+	0x6a, 0x6d, 0xa4, 0x62, 0x64, 0x40, 0x3c, 0xa7, 0x16, 0x56, 0xd7, 0x30, 0x58, 0xd7, 0x26, 0x7a,
+	0x2c, 0xf1, 0xbd, 0xf6, 0x20, 0xa4, 0x84, 0x7d, 0x8a, 0xd6, 0x2e, 0x63, 0x02, 0x26, 0x6f, 0xf6,
+	0x5d, 0x4a, 0x7a, 0xc4, 0x8f, 0xd2, 0x1e, 0x6b, 0x63, 0x40, 0x3e, 0x1e, 0xd2, 0x38, 0xff, 0x0a,
+	0xcc, 0x0e, 0x1a, 0x3f, 0x22, 0x5e, 0x9f, 0xd7, 0xe3, 0x75, 0x4d, 0x8f, 0xc0, 0x7f, 0x30, 0xc0,
+	0x1c, 0x67, 0x08, 0xfa, 0xb2, 0x26, 0x28, 0xcd, 0x11, 0xaf, 0x91, 0x43, 0x29, 0x75, 0x0d, 0xaa,
+	0x41, 0xc8, 0xbb, 0xe2, 0x80, 0x2a, 0x3f, 0x7f, 0x26, 0xf6, 0xdd, 0x4d, 0x05, 0x3f, 0x39, 0x6a,
+	0x5e, 0xc8, 0x88, 0x8f, 0x11, 0x38, 0x61, 0xe5, 0x89, 0x59, 0xd8, 0xc3, 0x8b, 0x85, 0x24, 0x31,
+	0xdf, 0x11, 0x10, 0xac, 0x30, 0xd6, 0x9f, 0x0c, 0x28, 0x89, 0x2a, 0xf9, 0x1e, 0x54, 0xf9, 0xfe,
+	0x39, 0x76, 0x64, 0x0b, 0xbb, 0x72, 0xf7, 0x67, 0x9c, 0x7b, 0x83, 0x44, 0x76, 0x7a, 0xbf, 0x62,
+	0x08, 0x4e, 0x24, 0x22, 0x0c, 0x65, 0x37, 0x22, 0xbd, 0xf8, 0x20, 0x9f, 0x1d, 0x2b, 0x5a, 0x4d,
+	0x07, 0x5a, 0xd8, 0xbe, 0xbf, 0xf6, 0x20, 0x22, 0x3e, 0x3f, 0x8c, 0x34, 0x18, 0xac, 0x73, 0x19,
+	0x58, 0x8a, 0xb2, 0xfe, 0x63, 0x40, 0xa2, 0x8a, 0x5f, 0x77, 0x46, 0xbc, 0xdd, 0x9b, 0xae, 0xbf,
+	0xaf, 0xb6, 0x35, 0x31, 0x67, 0x5b, 0xc1, 0x71, 0x42, 0x31, 0x2a, 0x21, 0x16, 0x26, 0x4b, 0x88,
+	0x5c, 0x61, 0x27, 0xf0, 0x23, 0xd7, 0xef, 0x0f, 0xc5, 0x97, 0x15, 0x05, 0xc7, 0x09, 0x05, 0xaf,
+	0x3b, 0x29, 0xe9, 0xd9, 0xae, 0xef, 0xfa, 0x5d, 0xbe, 0x88, 0x95, 0xa0, 0xef, 0x47, 0x66, 0x29,
+	0xad, 0x3b, 0xf1, 0x10, 0x16, 0x8f, 0xe0, 0xb0, 0x7e, 0x5b, 0x82, 0x3a, 0x5f, 0x73, 0x9c, 0xd9,
+	0x5f, 0x82, 0x86, 0xa7, 0x7b, 0x81, 0x5a, 0xfb, 0x05, 0x65, 0x4a, 0xf6, 0x5e, 0xe3, 0x2c, 0x2d,
+	0x67, 0x16, 0xe5, 0x72, 0xc2, 0x5c, 0xc8, 0x32, 0x5f, 0xd7, 0x91, 0x38, 0x4b, 0xcb, 0xe3, 0xf5,
+	0x7d, 0x7e, 0x3f, 0x54, 0x21, 0x9a, 0x1c, 0xd1, 0x77, 0x39, 0x10, 0x4b, 0xdc, 0xa8, 0x7d, 0x2e,
+	0x4d, 0xb8, 0xcf, 0xd7, 0xe0, 0x0c, 0x77, 0x88, 0xa0, 0x1f, 0xc5, 0xd5, 0x7a, 0x59, 0xec, 0x1a,
+	0x3a, 0x3e, 0x6a, 0x9e, 0xb9, 0x95, 0xc1, 0xe0, 0x01, 0x4a, 0x6e, 0xa3, 0xe7, 0xf6, 0xdc, 0xc8,
+	0x9c, 0x16, 0x2c, 0x89, 0x8d, 0x37, 0x39, 0x10, 0x4b, 0x5c, 0xe6, 0x20, 0xab, 0xa7, 0x1e, 0xe4,
+	0x06, 0x9c, 0xb3, 0x3d, 0x2f, 0xb8, 0x2f, 0x96, 0xd9, 0x0e, 0x82, 0xfd, 0x9e, 0x4d, 0xf7, 0x99,
+	0xe8, 0x71, 0xab, 0xed, 0x2f, 0x29, 0xc6, 0x73, 0xcb, 0xc3, 0x24, 0x78, 0x14, 0x1f, 0x7a, 0x0d,
+	0x90, 0xed, 0xfb, 0x41, 0x24, 0x4a, 0xdb, 0xe4, 0x1c, 0x3e, 0x99, 0xce, 0x36, 0xbf, 0xcb, 0x43,
+	0x24, 0x78, 0x04, 0x9b, 0xf5, 0x49, 0x01, 0x90, 0x6c, 0x7d, 0x1c, 0x59, 0x11, 0xca, 0xa8, 0xf5,
+	0x0c, 0x4c, 0xf7, 0x54, 0xeb, 0x64, 0x64, 0x93, 0x66, 0xdc, 0x35, 0xc5, 0x78, 0xb4, 0x01, 0x35,
+	0x19, 0x3d, 0xd2, 0x1b, 0xb1, 0xa4, 0x88, 0x6b, 0x9b, 0x31, 0xe2, 0xe4, 0xa8, 0x39, 0x9f, 0x51,
+	0x93, 0x60, 0x6e, 0x1d, 0x86, 0x04, 0xa7, 0x12, 0xf8, 0x9c, 0xc5, 0x0e, 0x5d, 0x7d, 0x4e, 0x56,
+	0x4b, 0xe7, 0x2c, 0x69, 0xc7, 0x8b, 0x35, 0x2a, 0xf4, 0x2a, 0x94, 0xf8, 0x29, 0xaa, 0x21, 0xca,
+	0x57, 0xf3, 0xc5, 0x20, 0xee, 0x07, 0xed, 0x2a, 0x4f, 0xcc, 0xfc, 0x17, 0x16, 0x12, 0xd0, 0x5d,
+	0xa8, 0x08, 0x97, 0x95, 0x1e, 0x33, 0x61, 0x31, 0x2d, 0x3a, 0x2b, 0xd5, 0x09, 0x9c, 0x24, 0xbf,
+	0xb0, 0x92, 0x68, 0xbd, 0x09, 0xb5, 0x0d, 0xb7, 0x43, 0x03, 0xae, 0x8e, 0x6f, 0x30, 0xcb, 0x74,
+	0x92, 0xc9, 0x06, 0xc7, 0x8e, 0x39, 0xcd, 0x52, 0x8f, 0xf4, 0x6d, 0x3f, 0x90, 0xfd, 0x62, 0x39,
+	0xf5, 0xc8, 0xd7, 0x39, 0x10, 0x4b, 0xdc, 0xb5, 0xf3, 0xbc, 0x18, 0xf9, 0xc5, 0x7b, 0xcd, 0xa9,
+	0x77, 0xde, 0x6b, 0x4e, 0xbd, 0xfb, 0x9e, 0x2a, 0x4c, 0xfe, 0x59, 0x07, 0xd8, 0xdc, 0xf9, 0x21,
+	0xe9, 0xc8, 0x80, 0x77, 0xfa, 0x94, 0x8b, 0x17, 0x98, 0x6a, 0xb8, 0xca, 0xa1, 0x66, 0x61, 0xa0,
+	0xc0, 0xd4, 0x70, 0x38, 0x43, 0x89, 0x96, 0xa0, 0x96, 0x4c, 0xbe, 0xd4, 0xb1, 0xcd, 0xc5, 0x6e,
+	0x90, 0x8c, 0xc7, 0x70, 0x4a, 0x93, 0x89, 0xbe, 0xa5, 0x53, 0xa3, 0x6f, 0x1b, 0x8a, 0x7d, 0xd7,
+	0x11, 0xa7, 0x52, 0x6b, 0x3f, 0x17, 0x67, 0xbf, 0xdb, 0xeb, 0xab, 0x27, 0x47, 0xcd, 0x27, 0xc7,
+	0x8d, 0x8d, 0xa3, 0xc3, 0x90, 0xb0, 0xd6, 0xed, 0xf5, 0x55, 0xcc, 0x99, 0x47, 0x45, 0x96, 0xca,
+	0x84, 0x91, 0xe5, 0x0a, 0x80, 0x5a, 0x35, 0xe7, 0x96, 0x21, 0x22, 0xf1, 0xce, 0x1b, 0x09, 0x06,
+	0x6b, 0x54, 0x88, 0xc1, 0x5c, 0x87, 0x12, 0xe9, 0xec, 0x6e, 0x8f, 0xb0, 0xc8, 0xee, 0xc9, 0x39,
+	0xd8, 0x64, 0xae, 0xfa, 0x84, 0x52, 0x33, 0xb7, 0x32, 0x28, 0x0c, 0x0f, 0xcb, 0x47, 0x01, 0xcc,
+	0x39, 0xaa, 0x15, 0x4f, 0x95, 0xd6, 0x26, 0x56, 0x7a, 0x81, 0x2b, 0x5c, 0x1d, 0x14, 0x84, 0x87,
+	0x65, 0xa3, 0x1f, 0xc0, 0x7c, 0x0c, 0x1c, 0x9e, 0x87, 0x88, 0xc9, 0x5c, 0xb1, 0xbd, 0xc0, 0x07,
+	0x42, 0xab, 0x63, 0xa9, 0xf0, 0x23, 0x24, 0x20, 0x07, 0x2a, 0x9e, 0x2c, 0x2d, 0xeb, 0xa2, 0x1c,
+	0xf8, 0x66, 0xbe, 0x55, 0xa4, 0xde, 0xdf, 0xd2, 0x4b, 0xca, 0xa4, 0xdf, 0x97, 0x40, 0xac, 0x64,
+	0xa3, 0x07, 0x50, 0x4f, 0x83, 0x24, 0x33, 0x67, 0x84, 0xaa, 0xe5, 0x89, 0x55, 0xa5, 0xc1, 0x77,
+	0xb0, 0x84, 0xd5, 0x30, 0x58, 0x57, 0x85, 0xee, 0xc3, 0xd9, 0xe0, 0xbe, 0x4f, 0x28, 0xe6, 0x73,
+	0x7b, 0xe2, 0xf3, 0xe9, 0x61, 0x43, 0x68, 0xff, 0x7a, 0x4e, 0xed, 0x19, 0xe6, 0xd4, 0xa5, 0xb3,
+	0x70, 0x86, 0x07, 0xb5, 0xf0, 0x51, 0xee, 0xae, 0xeb, 0xab, 0x46, 0xc4, 0x3c, 0x93, 0x8e, 0x72,
+	0xaf, 0x27, 0x50, 0xac, 0x51, 0xa0, 0x17, 0xa0, 0xde, 0xf1, 0xfa, 0x2c, 0x22, 0x72, 0x66, 0x7c,
+	0x56, 0xdc, 0xa0, 0x64, 0x7d, 0x2b, 0x29, 0x0a, 0xeb, 0x74, 0x68, 0x0f, 0x66, 0x5c, 0xad, 0xe3,
+	0x31, 0x67, 0x85, 0x2f, 0x5e, 0x99, 0xb8, 0xcd, 0x61, 0xed, 0x59, 0x1e, 0x89, 0x74, 0x08, 0xce,
+	0x48, 0x46, 0x7d, 0x68, 0xf4, 0xf4, 0x54, 0x63, 0xce, 0x89, 0x7d, 0xbc, 0x9a, 0x4f, 0xd5, 0x70,
+	0x32, 0x4c, 0x8b, 0x9b, 0x0c, 0x0e, 0x67, 0xb5, 0xcc, 0x7f, 0x03, 0xea, 0x9f, 0xb2, 0xee, 0xe7,
+	0x7d, 0xc3, 0xa0, 0xc7, 0x4c, 0xd4, 0x37, 0xbc, 0x5f, 0x80, 0x33, 0xd9, 0x73, 0x4e, 0xfa, 0x6b,
+	0x63, 0xec, 0xd3, 0x43, 0x9c, 0x0c, 0x8a, 0x63, 0x93, 0x81, 0x8a, 0xb9, 0xa5, 0xc7, 0x89, 0xb9,
+	0xd9, 0x74, 0x5e, 0xce, 0x95, 0xce, 0xf9, 0xe3, 0x42, 0xe0, 0x47, 0x34, 0xf0, 0x3c, 0x42, 0x45,
+	0x88, 0xae, 0xaa, 0xc7, 0x85, 0x04, 0x8a, 0x35, 0x0a, 0x5e, 0x28, 0xef, 0x78, 0x41, 0x67, 0x5f,
+	0x6c, 0x41, 0x1c, 0x5e, 0x44, 0x70, 0xae, 0xca, 0x42, 0xb9, 0x3d, 0x84, 0xc5, 0x23, 0x38, 0xac,
+	0x43, 0xb8, 0xb0, 0x65, 0x53, 0xee, 0x48, 0xe9, 0x55, 0x16, 0x9d, 0xc8, 0x1b, 0x43, 0x7d, 0xce,
+	0x73, 0x93, 0x86, 0x84, 0x74, 0xd1, 0x29, 0x2c, 0xed, 0x75, 0xac, 0xbf, 0x19, 0xf0, 0xc4, 0x48,
+	0xdd, 0x9f, 0x43, 0x9f, 0xf5, 0x46, 0xb6, 0xcf, 0x7a, 0x29, 0xe7, 0x3c, 0x7a, 0x94, 0xb5, 0x63,
+	0xba, 0xae, 0x69, 0x28, 0x6f, 0xf1, 0x1a, 0xd6, 0xfa, 0xb5, 0x01, 0x33, 0xe2, 0xd7, 0x24, 0xb3,
+	0xfc, 0x26, 0x94, 0x77, 0x83, 0x78, 0x5e, 0x57, 0x95, 0xcf, 0x5e, 0xd7, 0x39, 0x00, 0x4b, 0xf8,
+	0x63, 0x0c, 0xfb, 0xdf, 0x36, 0x20, 0x3b, 0x45, 0x47, 0xaf, 0x48, 0x9f, 0x37, 0x92, 0x31, 0xf7,
+	0x84, 0xfe, 0xfe, 0xf2, 0xb8, 0x2e, 0xf1, 0x5c, 0xae, 0x91, 0xe9, 0x25, 0xa8, 0xe1, 0x20, 0x88,
+	0xb6, 0xec, 0x68, 0x8f, 0xf1, 0x85, 0x87, 0xfc, 0x87, 0xda, 0x1b, 0xb1, 0x70, 0x81, 0xc1, 0x12,
+	0x6e, 0xfd, 0xca, 0x80, 0x27, 0xc6, 0xbe, 0xaf, 0xf0, 0xab, 0xd7, 0x49, 0xbe, 0xd4, 0x8a, 0x12,
+	0x2f, 0x4c, 0xe9, 0xb0, 0x46, 0xc5, 0xdb, 0xbb, 0xcc, 0xa3, 0xcc, 0x60, 0x7b, 0x97, 0xd1, 0x86,
+	0xb3, 0xb4, 0xd6, 0xbf, 0x0b, 0x50, 0x91, 0x33, 0xa3, 0xff, 0xb1, 0xc7, 0x3e, 0x0d, 0x15, 0x26,
+	0xf4, 0x28, 0xf3, 0x92, 0x6c, 0x2e, 0xb5, 0x63, 0x85, 0x15, 0x5d, 0x0c, 0x61, 0xcc, 0xee, 0xc6,
+	0x51, 0x2e, 0xed, 0x62, 0x24, 0x18, 0xc7, 0x78, 0xf4, 0x22, 0x1f, 0x91, 0xd9, 0x2c, 0x69, 0x36,
+	0x17, 0x62, 0x91, 0x58, 0x40, 0x4f, 0xf8, 0x73, 0xa8, 0x14, 0x2e, 0xbe, 0xb1, 0xa2, 0x46, 0x77,
+	0x61, 0xda, 0x21, 0x91, 0xed, 0x7a, 0x71, 0xc7, 0xf0, 0xfc, 0x24, 0xb3, 0xb5, 0x55, 0xc9, 0xda,
+	0xae, 0x73, 0x9b, 0xd4, 0x07, 0x8e, 0x05, 0xf2, 0x08, 0xdd, 0x09, 0x1c, 0xf9, 0x2c, 0x5b, 0x4e,
+	0x23, 0xf4, 0x4a, 0xe0, 0x10, 0x2c, 0x30, 0xd6, 0x3b, 0x06, 0xd4, 0xa5, 0xa4, 0x15, 0xbb, 0xcf,
+	0x08, 0xba, 0x9c, 0xac, 0x42, 0x1e, 0x77, 0x5c, 0x33, 0x96, 0x78, 0x97, 0x75, 0x72, 0xd4, 0xac,
+	0x09, 0x32, 0xfe, 0x91, 0x2c, 0x40, 0xdb, 0xa3, 0xc2, 0x29, 0x7b, 0xf4, 0x14, 0x94, 0xc5, 0xed,
+	0x51, 0x9b, 0x99, 0xdc, 0x75, 0x71, 0xc1, 0xb0, 0xc4, 0x59, 0x1f, 0x17, 0xa0, 0x91, 0x59, 0x5c,
+	0x8e, 0xae, 0x23, 0x99, 0xe3, 0x16, 0x72, 0xbc, 0x0d, 0x8c, 0x7f, 0x4c, 0xff, 0x1e, 0x54, 0x3a,
+	0x7c, 0x7d, 0xf1, 0x7f, 0x33, 0x5c, 0x9e, 0xe4, 0x28, 0xc4, 0xce, 0xa4, 0x9e, 0x24, 0x3e, 0x19,
+	0x56, 0x02, 0xd1, 0x0d, 0x98, 0xa3, 0x24, 0xa2, 0x87, 0xcb, 0xbb, 0x11, 0xa1, 0xfa, 0x50, 0xa1,
+	0x9c, 0xd6, 0xe5, 0x78, 0x90, 0x00, 0x0f, 0xf3, 0xc4, 0x39, 0xb5, 0xf2, 0x18, 0x39, 0xd5, 0xda,
+	0x81, 0x99, 0x5b, 0xf6, 0x8e, 0x97, 0x3c, 0x50, 0x62, 0x68, 0xb8, 0x7e, 0xc7, 0xeb, 0x3b, 0x44,
+	0x46, 0xe3, 0x38, 0x7a, 0xc5, 0x97, 0x76, 0x5d, 0x47, 0xf2, 0x47, 0xba, 0x0c, 0x40, 0xbe, 0xc8,
+	0xe1, 0xac, 0x08, 0xcb, 0x83, 0xd2, 0xe7, 0xd8, 0xa7, 0x7e, 0x1f, 0x6a, 0x69, 0x27, 0xf1, 0x19,
+	0xab, 0xb4, 0xde, 0x80, 0x2a, 0xf7, 0xf8, 0xb8, 0x03, 0x3e, 0xa5, 0x2c, 0xca, 0x16, 0x2c, 0x85,
+	0x3c, 0x05, 0x0b, 0x7f, 0xa2, 0xbe, 0x1d, 0x3a, 0x8f, 0xf9, 0x44, 0x5d, 0xc8, 0x9d, 0xb5, 0xae,
+	0x80, 0xfc, 0xb7, 0x0f, 0x9e, 0x20, 0x64, 0xe6, 0xd6, 0x12, 0x84, 0x9e, 0x78, 0xb5, 0x27, 0x8a,
+	0x9f, 0x19, 0x00, 0x62, 0x8e, 0xb4, 0x76, 0xc0, 0x87, 0xc9, 0x8b, 0x50, 0xe2, 0x4e, 0x35, 0xb8,
+	0x0f, 0x22, 0x32, 0x08, 0x0c, 0xba, 0x0d, 0x95, 0x40, 0x7a, 0x93, 0x7c, 0x33, 0x98, 0x70, 0xfc,
+	0x9a, 0x5c, 0x24, 0xe9, 0x4f, 0x58, 0x09, 0x6b, 0x5f, 0xfc, 0xe0, 0xe1, 0xc2, 0xd4, 0x87, 0x0f,
+	0x17, 0xa6, 0x3e, 0x7a, 0xb8, 0x30, 0xf5, 0xd6, 0xf1, 0x82, 0xf1, 0xc1, 0xf1, 0x82, 0xf1, 0xe1,
+	0xf1, 0x82, 0xf1, 0xd1, 0xf1, 0x82, 0xf1, 0xf1, 0xf1, 0x82, 0xf1, 0xce, 0x3f, 0x16, 0xa6, 0xee,
+	0x16, 0x0e, 0x2e, 0xff, 0x77, 0x00, 0x82, 0xa9, 0x11, 0xa2, 0xe2, 0x26, 0x00, 0x00,
 }
@@ -17,11 +17,14 @@ limitations under the License.
 package v1
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/aaron-prindle/krmapiserver/included/github.com/gogo/protobuf/protoc-gen-gogo/descriptor"
 	fuzz "github.com/aaron-prindle/krmapiserver/included/github.com/google/gofuzz"
 )
 
@@ -60,3 +63,47 @@ want: %v`, got, update)
 		})
 	}
 }
+
+func TestListOptionsDescriptorHasAllFields(t *testing.T) {
+	_, md := descriptor.ForMessage(&ListOptions{})
+	fields := map[int32]string{}
+	for _, f := range md.Field {
+		fields[f.GetNumber()] = f.GetName()
+	}
+	typ := reflect.TypeOf(ListOptions{})
+	for i := 0; i < typ.NumField(); i++ {
+		tag := typ.Field(i).Tag.Get("protobuf")
+		if len(tag) == 0 {
+			continue
+		}
+		// e.g. bytes,1000,opt,name=annotationSelector
+		parts := strings.Split(tag, ",")
+		var number int32
+		if _, err := fmt.Sscanf(parts[1], "%d", &number); err != nil {
+			t.Fatalf("invalid protobuf tag %q of %s: %v", tag, typ.Field(i).Name, err)
+		}
+		name := strings.TrimPrefix(parts[3], "name=")
+		if fields[number] != name {
+			t.Errorf("expected field %d of the ListOptions descriptor to be %q, got %q", number, name, fields[number])
+		}
+	}
+}
+
+func TestListOptionsProtobufRoundTrip(t *testing.T) {
+	in := ListOptions{LabelSelector: "a=b", AnnotationSelector: "c=d", ResourceVersion: "1"}
+	data, err := in.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The annotationSelector is field 1000, which upstream does not use.
+	if !bytes.Contains(data, []byte{0xc2, 0x3e, 3, 'c', '=', 'd'}) {
+		t.Errorf("expected the annotationSelector to be encoded as field 1000, got %v", data)
+	}
+	out := ListOptions{}
+	if err := out.Unmarshal(data); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("expected %#v, got %#v", in, out)
+	}
+}
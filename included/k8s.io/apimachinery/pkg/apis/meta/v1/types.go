@@ -350,6 +350,10 @@ type ListOptions struct {
 	// Defaults to everything.
 	// +optional
 	FieldSelector string `json:"fieldSelector,omitempty" protobuf:"bytes,2,opt,name=fieldSelector"`
+	// A selector to restrict the list of returned objects by their annotations.
+	// It uses the syntax of label selectors. Defaults to everything.
+	// +optional
+	AnnotationSelector string `json:"annotationSelector,omitempty" protobuf:"bytes,1000,opt,name=annotationSelector"`
 
 	// +k8s:deprecated=includeUninitialized,protobuf=6
 
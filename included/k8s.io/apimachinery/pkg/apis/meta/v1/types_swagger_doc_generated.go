@@ -212,6 +212,7 @@ var map_ListOptions = map[string]string{
 	"":                    "ListOptions is the query options to a standard REST list call.",
 	"labelSelector":       "A selector to restrict the list of returned objects by their labels. Defaults to everything.",
 	"fieldSelector":       "A selector to restrict the list of returned objects by their fields. Defaults to everything.",
+	"annotationSelector":  "A selector to restrict the list of returned objects by their annotations. It uses the syntax of label selectors. Defaults to everything.",
 	"watch":               "Watch for changes to the described resources and return them as a stream of add, update, and remove notifications. Specify resourceVersion.",
 	"allowWatchBookmarks": "allowWatchBookmarks requests watch events with type \"BOOKMARK\". Servers that do not implement bookmarks may ignore this flag and bookmarks are sent at the server's discretion. Clients should not assume bookmarks are returned at any specific interval, nor may they assume the server will send any BOOKMARK event during a session. If this is not a watch, this field is ignored. If the feature gate WatchBookmarks is not enabled in apiserver, this field is ignored.\n\nThis field is alpha and can be changed or removed without notice.",
 	"resourceVersion":     "When specified with a watch call, shows changes that occur after that particular version of a resource. Defaults to changes from the beginning of history. When specified for list: - if unset, then the result is returned from remote storage based on quorum-read flag; - if it's 0, then we simply return what we currently have in cache, no guarantee; - if set to non zero, then the result is at least as fresh as given rv.",
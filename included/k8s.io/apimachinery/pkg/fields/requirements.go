@@ -27,4 +27,7 @@ type Requirement struct {
 	Operator selection.Operator
 	Field    string
 	Value    string
+	// Values holds the values of the set-based operators selection.In and
+	// selection.NotIn, which leave Value empty.
+	Values []string
 }
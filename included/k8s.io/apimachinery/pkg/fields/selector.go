@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/selection"
@@ -174,6 +175,182 @@ func (t *notHasTerm) DeepCopySelector() Selector {
 	return out
 }
 
+// setTerm matches fields whose value is (selection.In), or is not
+// (selection.NotIn), one of a set of values. Like label selectors, an absent
+// field is never in a set.
+type setTerm struct {
+	field    string
+	operator selection.Operator
+	values   []string
+}
+
+func (t *setTerm) Matches(ls Fields) bool {
+	in := false
+	if ls.Has(t.field) {
+		value := ls.Get(t.field)
+		for _, v := range t.values {
+			if v == value {
+				in = true
+				break
+			}
+		}
+	}
+	if t.operator == selection.NotIn {
+		return !in
+	}
+	return in
+}
+
+func (t *setTerm) Empty() bool {
+	return false
+}
+
+func (t *setTerm) RequiresExactMatch(field string) (value string, found bool) {
+	if t.field == field && t.operator == selection.In && len(t.values) == 1 {
+		return t.values[0], true
+	}
+	return "", false
+}
+
+func (t *setTerm) Transform(fn TransformFunc) (Selector, error) {
+	var field string
+	values := make([]string, 0, len(t.values))
+	for _, v := range t.values {
+		f, value, err := fn(t.field, v)
+		if err != nil {
+			return nil, err
+		}
+		if len(f) == 0 && len(value) == 0 {
+			continue
+		}
+		if len(field) > 0 && f != field {
+			return nil, fmt.Errorf("field %s of selector term %s was transformed to both %s and %s", t.field, t, field, f)
+		}
+		field = f
+		values = append(values, value)
+	}
+	if len(values) == 0 {
+		return Everything(), nil
+	}
+	return newSetTerm(field, t.operator, values), nil
+}
+
+func (t *setTerm) Requirements() Requirements {
+	return []Requirement{{
+		Field:    t.field,
+		Operator: t.operator,
+		Values:   t.values,
+	}}
+}
+
+func (t *setTerm) String() string {
+	values := make([]string, 0, len(t.values))
+	for _, v := range t.values {
+		values = append(values, EscapeValue(v))
+	}
+	return fmt.Sprintf("%v %v (%v)", t.field, t.operator, strings.Join(values, ","))
+}
+
+func (t *setTerm) DeepCopySelector() Selector {
+	if t == nil {
+		return nil
+	}
+	out := new(setTerm)
+	*out = *t
+	out.values = append([]string(nil), t.values...)
+	return out
+}
+
+// newSetTerm returns a setTerm of the sorted, deduplicated values.
+func newSetTerm(field string, operator selection.Operator, values []string) *setTerm {
+	sorted := make([]string, 0, len(values))
+	seen := make(map[string]bool, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			sorted = append(sorted, v)
+		}
+	}
+	sort.Strings(sorted)
+	return &setTerm{field: field, operator: operator, values: sorted}
+}
+
+// numericTerm matches fields whose value is a number greater than
+// (selection.GreaterThan), or less than (selection.LessThan), a number. Absent
+// fields and fields that are not numbers never match.
+type numericTerm struct {
+	field    string
+	operator selection.Operator
+	value    string
+	number   float64
+}
+
+func (t *numericTerm) Matches(ls Fields) bool {
+	if !ls.Has(t.field) {
+		return false
+	}
+	number, err := strconv.ParseFloat(ls.Get(t.field), 64)
+	if err != nil {
+		return false
+	}
+	if t.operator == selection.GreaterThan {
+		return number > t.number
+	}
+	return number < t.number
+}
+
+func (t *numericTerm) Empty() bool {
+	return false
+}
+
+func (t *numericTerm) RequiresExactMatch(field string) (value string, found bool) {
+	return "", false
+}
+
+func (t *numericTerm) Transform(fn TransformFunc) (Selector, error) {
+	field, value, err := fn(t.field, t.value)
+	if err != nil {
+		return nil, err
+	}
+	if len(field) == 0 && len(value) == 0 {
+		return Everything(), nil
+	}
+	return newNumericTerm(field, t.operator, value)
+}
+
+func (t *numericTerm) Requirements() Requirements {
+	return []Requirement{{
+		Field:    t.field,
+		Operator: t.operator,
+		Value:    t.value,
+	}}
+}
+
+func (t *numericTerm) String() string {
+	if t.operator == selection.GreaterThan {
+		return fmt.Sprintf("%v%v%v", t.field, greaterThanOperator, t.value)
+	}
+	return fmt.Sprintf("%v%v%v", t.field, lessThanOperator, t.value)
+}
+
+func (t *numericTerm) DeepCopySelector() Selector {
+	if t == nil {
+		return nil
+	}
+	out := new(numericTerm)
+	*out = *t
+	return out
+}
+
+// newNumericTerm returns a numericTerm, or an error if value is not a number.
+func newNumericTerm(field string, operator selection.Operator, value string) (*numericTerm, error) {
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value %q for field %s: %s requires a number", value, field, operator)
+	}
+	return &numericTerm{field: field, operator: operator, value: value, number: number}, nil
+}
+
 type andTerm []Selector
 
 func (t andTerm) Matches(ls Fields) bool {
@@ -356,7 +533,10 @@ func ParseSelectorOrDie(s string) Selector {
 }
 
 // ParseSelector takes a string representing a selector and returns an
-// object suitable for matching, or an error.
+// object suitable for matching, or an error. Besides the equality operators
+// =, == and !=, a term may use the numeric operators > and <, e.g.
+// "spec.replicas>3", or the set-based operators in and notin, e.g.
+// "status.phase in (Pending,Running)".
 func ParseSelector(selector string) (Selector, error) {
 	return parseSelector(selector,
 		func(lhs, rhs string) (newLhs, newRhs string, err error) {
@@ -382,12 +562,19 @@ func splitTerms(fieldSelector string) []string {
 	terms := make([]string, 0, 1)
 	startIndex := 0
 	inSlash := false
+	// inSet is true within the parenthesized values of a set-based term,
+	// whose commas separate values rather than terms.
+	inSet := false
 	for i, c := range fieldSelector {
 		switch {
 		case inSlash:
 			inSlash = false
 		case c == '\\':
 			inSlash = true
+		case inSet:
+			inSet = c != ')'
+		case c == '(':
+			inSet = isSetOperand(fieldSelector[startIndex:i])
 		case c == ',':
 			terms = append(terms, fieldSelector[startIndex:i])
 			startIndex = i + 1
@@ -403,12 +590,44 @@ const (
 	notEqualOperator    = "!="
 	doubleEqualOperator = "=="
 	equalOperator       = "="
+	greaterThanOperator = ">"
+	lessThanOperator    = "<"
+	inOperator          = " in "
+	notInOperator       = " notin "
 )
 
 // termOperators holds the recognized operators supported in fieldSelectors.
 // doubleEqualOperator and equal are equivalent, but doubleEqualOperator is checked first
 // to avoid leaving a leading = character on the rhs value.
-var termOperators = []string{notEqualOperator, doubleEqualOperator, equalOperator}
+var termOperators = []string{notEqualOperator, doubleEqualOperator, equalOperator, greaterThanOperator, lessThanOperator, inOperator, notInOperator}
+
+// isSetOperand returns whether a parenthesis following the given start of a term opens
+// the values of a set-based term.
+func isSetOperand(term string) bool {
+	term = strings.TrimRight(term, " ")
+	return strings.HasSuffix(term, strings.TrimRight(inOperator, " ")) || strings.HasSuffix(term, strings.TrimRight(notInOperator, " "))
+}
+
+// parseSetValues returns the values of the parenthesized, comma separated rhs of a
+// set-based term.
+func parseSetValues(rhs string) ([]string, error) {
+	rhs = strings.TrimSpace(rhs)
+	if len(rhs) < 2 || rhs[0] != '(' || rhs[len(rhs)-1] != ')' {
+		return nil, fmt.Errorf("values of a set must be enclosed in parentheses")
+	}
+	var values []string
+	for _, v := range splitTerms(rhs[1 : len(rhs)-1]) {
+		value, err := UnescapeValue(strings.TrimSpace(v))
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("a set must contain at least one value")
+	}
+	return values, nil
+}
 
 // splitTerm returns the lhs, operator, and rhs parsed from the given term, along with an indicator of whether the parse was successful.
 // no escaping of special characters is supported in the lhs value, so the first occurrence of a recognized operator is used as the split point.
@@ -437,6 +656,18 @@ func parseSelector(selector string, fn TransformFunc) (Selector, error) {
 		if !ok {
 			return nil, fmt.Errorf("invalid selector: '%s'; can't understand '%s'", selector, part)
 		}
+		if op == inOperator || op == notInOperator {
+			values, err := parseSetValues(rhs)
+			if err != nil {
+				return nil, fmt.Errorf("invalid selector: '%s'; %v", selector, err)
+			}
+			operator := selection.In
+			if op == notInOperator {
+				operator = selection.NotIn
+			}
+			items = append(items, newSetTerm(lhs, operator, values))
+			continue
+		}
 		unescapedRHS, err := UnescapeValue(rhs)
 		if err != nil {
 			return nil, err
@@ -448,6 +679,16 @@ func parseSelector(selector string, fn TransformFunc) (Selector, error) {
 			items = append(items, &hasTerm{field: lhs, value: unescapedRHS})
 		case equalOperator:
 			items = append(items, &hasTerm{field: lhs, value: unescapedRHS})
+		case greaterThanOperator, lessThanOperator:
+			operator := selection.GreaterThan
+			if op == lessThanOperator {
+				operator = selection.LessThan
+			}
+			term, err := newNumericTerm(lhs, operator, unescapedRHS)
+			if err != nil {
+				return nil, fmt.Errorf("invalid selector: '%s'; %v", selector, err)
+			}
+			items = append(items, term)
 		default:
 			return nil, fmt.Errorf("invalid selector: '%s'; can't understand '%s'", selector, part)
 		}
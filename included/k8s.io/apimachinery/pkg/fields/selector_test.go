@@ -43,6 +43,11 @@ func TestSplitTerms(t *testing.T) {
 
 		// Multi-byte
 		`함=수,목=록`: {`함=수`, `목=록`},
+
+		// Set-based terms
+		`x in (a,b),y=c`:     {`x in (a,b)`, `y=c`},
+		`x notin (a\,b),y=c`: {`x notin (a\,b)`, `y=c`},
+		`x=(a,b)`:            {`x=(a`, `b)`}, // parentheses only group the values of sets
 	}
 
 	for selector, expectedTerms := range testcases {
@@ -60,9 +65,13 @@ func TestSplitTerm(t *testing.T) {
 		ok  bool
 	}{
 		// Simple terms
-		`a=value`:  {lhs: `a`, op: `=`, rhs: `value`, ok: true},
-		`b==value`: {lhs: `b`, op: `==`, rhs: `value`, ok: true},
-		`c!=value`: {lhs: `c`, op: `!=`, rhs: `value`, ok: true},
+		`a=value`:     {lhs: `a`, op: `=`, rhs: `value`, ok: true},
+		`b==value`:    {lhs: `b`, op: `==`, rhs: `value`, ok: true},
+		`c!=value`:    {lhs: `c`, op: `!=`, rhs: `value`, ok: true},
+		`d>1`:         {lhs: `d`, op: `>`, rhs: `1`, ok: true},
+		`e<1`:         {lhs: `e`, op: `<`, rhs: `1`, ok: true},
+		`f in (a)`:    {lhs: `f`, op: ` in `, rhs: `(a)`, ok: true},
+		`g notin (a)`: {lhs: `g`, op: ` notin `, rhs: `(a)`, ok: true},
 
 		// Empty or invalid terms
 		``:  {lhs: ``, op: ``, rhs: ``, ok: false},
@@ -137,13 +146,20 @@ func TestSelectorParse(t *testing.T) {
 		"x!=a,y=b",
 		`x=a||y\=b`,
 		`x=a\=\=b`,
+		"x in (a)",
+		"x in (a,b,c)",
+		"x notin (a,b),y=c",
+		"x>3",
+		"x<-1.5",
 	}
 	testBadStrings := []string{
 		"x=a||y=b",
 		"x==a==b",
 		"x=a,b",
-		"x in (a)",
-		"x in (a,b,c)",
+		"x in ()",
+		"x in a",
+		"x in (a",
+		"x>a",
 		"x",
 	}
 	for _, test := range testGoodStrings {
@@ -229,6 +245,27 @@ func TestSelectorMatches(t *testing.T) {
 	expectNoMatch(t, "foo=bar,foobar=bar,baz=blah", fieldset)
 }
 
+func TestSelectorMatchesSetAndNumericTerms(t *testing.T) {
+	fieldset := Set{
+		"status.phase":  "Running",
+		"spec.replicas": "5",
+		"spec.ratio":    "0.5",
+	}
+	expectMatch(t, "status.phase in (Pending,Running)", fieldset)
+	expectMatch(t, "status.phase notin (Failed)", fieldset)
+	expectMatch(t, "spec.missing notin (a)", fieldset)
+	expectMatch(t, "spec.replicas>3", fieldset)
+	expectMatch(t, "spec.replicas<10,spec.ratio<1", fieldset)
+	expectMatch(t, "spec.replicas>3,status.phase in (Running)", fieldset)
+	expectNoMatch(t, "status.phase in (Pending)", fieldset)
+	expectNoMatch(t, "status.phase notin (Pending,Running)", fieldset)
+	expectNoMatch(t, "spec.missing in (a)", fieldset)
+	expectNoMatch(t, "spec.replicas>5", fieldset)
+	expectNoMatch(t, "spec.replicas<5", fieldset)
+	expectNoMatch(t, "status.phase>1", fieldset)
+	expectNoMatch(t, "spec.missing<1", fieldset)
+}
+
 func TestOneTermEqualSelector(t *testing.T) {
 	if !OneTermEqualSelector("x", "y").Matches(Set{"x": "y"}) {
 		t.Errorf("No match when match expected.")
@@ -379,6 +416,18 @@ func TestTransform(t *testing.T) {
 			result:  "a=b,e=f",
 			isEmpty: false,
 		},
+		{
+			name:     "transform set and numeric terms",
+			selector: "a in (b,c),d>1",
+			transform: func(field, value string) (string, string, error) {
+				if field == "a" {
+					return "e", value, nil
+				}
+				return field, value, nil
+			},
+			result:  "e in (b,c),d>1",
+			isEmpty: false,
+		},
 	}
 
 	for i, tc := range testCases {
@@ -70,6 +70,27 @@ func (n nothingSelector) Add(_ ...Requirement) Selector      { return n }
 func (n nothingSelector) Requirements() (Requirements, bool) { return nil, false }
 func (n nothingSelector) DeepCopySelector() Selector         { return n }
 
+// RequiresExactMatch introspects whether selector requires a single specific
+// label to be set, and if so returns the value it requires.
+func RequiresExactMatch(selector Selector, label string) (value string, found bool) {
+	requirements, selectable := selector.Requirements()
+	if !selectable {
+		return "", false
+	}
+	for ix := range requirements {
+		if requirements[ix].key == label {
+			switch requirements[ix].operator {
+			case selection.Equals, selection.DoubleEquals, selection.In:
+				if len(requirements[ix].strValues) == 1 {
+					return requirements[ix].strValues[0], true
+				}
+			}
+			return "", false
+		}
+	}
+	return "", false
+}
+
 // Nothing returns a selector that matches no labels
 func Nothing() Selector {
 	return nothingSelector{}
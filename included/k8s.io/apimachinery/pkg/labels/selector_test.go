@@ -617,3 +617,35 @@ func TestSafeSort(t *testing.T) {
 		})
 	}
 }
+
+func TestRequiresExactMatch(t *testing.T) {
+	testCases := []struct {
+		selector      string
+		label         string
+		expectedValue string
+		expectedFound bool
+	}{
+		{selector: "key=value", label: "key", expectedValue: "value", expectedFound: true},
+		{selector: "key==value", label: "key", expectedValue: "value", expectedFound: true},
+		{selector: "key in (value)", label: "key", expectedValue: "value", expectedFound: true},
+		{selector: "other=value,key=value", label: "key", expectedValue: "value", expectedFound: true},
+		{selector: "key in (value1,value2)", label: "key", expectedFound: false},
+		{selector: "key!=value", label: "key", expectedFound: false},
+		{selector: "key", label: "key", expectedFound: false},
+		{selector: "other=value", label: "key", expectedFound: false},
+		{selector: "", label: "key", expectedFound: false},
+	}
+	for _, tc := range testCases {
+		selector, err := Parse(tc.selector)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", tc.selector, err)
+		}
+		value, found := RequiresExactMatch(selector, tc.label)
+		if value != tc.expectedValue || found != tc.expectedFound {
+			t.Errorf("%q: expected (%q, %v), got (%q, %v)", tc.selector, tc.expectedValue, tc.expectedFound, value, found)
+		}
+	}
+	if _, found := RequiresExactMatch(Nothing(), "key"); found {
+		t.Errorf("Nothing() should not require an exact match")
+	}
+}
@@ -46,6 +46,23 @@ type Encoder interface {
 	Encode(obj Object, w io.Writer) error
 }
 
+// Identifier identifies an encoding of objects. Two encodings must have the
+// same Identifier if and only if they produce the same output for every object.
+type Identifier string
+
+// CacheableObject allows an object to cache its serializations, so that an
+// object sent to many clients in the same encoding is only encoded once.
+type CacheableObject interface {
+	// CacheEncode writes the object to w in the encoding identified by id.
+	// The encode func is only called if the result for id is not cached yet,
+	// and it is passed a copy of the wrapped object that it may modify.
+	// Callers must pass the same encode func for the same id.
+	CacheEncode(id Identifier, encode func(Object, io.Writer) error, w io.Writer) error
+	// GetObject returns a deep copy of the wrapped object, which is owned by
+	// the caller.
+	GetObject() Object
+}
+
 // Decoder attempts to load an object from data.
 type Decoder interface {
 	// Decode attempts to deserialize the provided data using either the innate typing of the scheme or the
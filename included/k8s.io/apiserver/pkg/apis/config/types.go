@@ -36,6 +36,10 @@ type ResourceConfiguration struct {
 	// providers is a list of transformers to be used for reading and writing the resources to disk.
 	// eg: aesgcm, aescbc, secretbox, identity.
 	Providers []ProviderConfiguration
+	// compression is the configuration for compressing values before they are encrypted by the providers.
+	// Values are not compressed if it is not set.
+	// +optional
+	Compression *CompressionConfiguration
 }
 
 // ProviderConfiguration stores the provided configuration for an encryption provider.
@@ -50,6 +54,9 @@ type ProviderConfiguration struct {
 	Identity *IdentityConfiguration
 	// kms contains the name, cache size and path to configuration file for a KMS based envelope transformer.
 	KMS *KMSConfiguration
+	// hkdf is the configuration for the transformer that encrypts every value with its own key, derived
+	// from a master key.
+	HKDF *HKDFConfiguration
 }
 
 // AESConfiguration contains the API configuration for an AES transformer.
@@ -66,6 +73,36 @@ type SecretboxConfiguration struct {
 	Keys []Key
 }
 
+// HKDFConfiguration contains the API configuration for a transformer that derives a new key from a master
+// key with HKDF for every value it writes, using a random salt that is stored with the value. This lifts the
+// limit on the number of values that can be encrypted with the same key that AES-GCM with random nonces has.
+type HKDFConfiguration struct {
+	// cipher is the AEAD the values are encrypted with, either "aesgcm" or "chacha20poly1305".
+	// The default is "aesgcm".
+	// +optional
+	Cipher string
+	// keys is a list of master keys to be used for creating the HKDF transformer.
+	// Each key has to be 32 bytes long.
+	Keys []Key
+}
+
+// CompressionConfiguration contains the API configuration for compressing values before they are
+// encrypted by the providers of a resource. Values written before compression was configured, or
+// smaller than minSize, are stored uncompressed and stay readable.
+type CompressionConfiguration struct {
+	// algorithm is the compression algorithm, either "gzip" or "flate". The default is "gzip".
+	// +optional
+	Algorithm string
+	// minSize is the size in bytes from which values are compressed. The default is 1024.
+	// +optional
+	MinSize int32
+	// maxSize is the size in bytes up to which values are compressed, and the largest size a
+	// value read from storage may decompress to. Reading a value that decompresses to more
+	// fails. The default is 67108864 (64 MiB).
+	// +optional
+	MaxSize int32
+}
+
 // Key contains name and secret of the provided key for a transformer.
 type Key struct {
 	// name is the name of the key to be used while storing data to disk.
@@ -79,6 +116,11 @@ type IdentityConfiguration struct{}
 
 // KMSConfiguration contains the name, cache size and path to configuration file for a KMS based envelope transformer.
 type KMSConfiguration struct {
+	// apiVersion of the KeyManagementService, "v1" or "v2". The default is "v1".
+	// With "v2", the ID of the key encrypting each value is stored with it, and
+	// the health of the KMS plugin is reported by the kms-providers healthz check.
+	// +optional
+	APIVersion string
 	// name is the name of the KMS plugin to be used.
 	Name string
 	// cacheSize is the maximum number of secrets which are cached in memory. The default value is 1000.
@@ -46,6 +46,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*CompressionConfiguration)(nil), (*config.CompressionConfiguration)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1_CompressionConfiguration_To_config_CompressionConfiguration(a.(*CompressionConfiguration), b.(*config.CompressionConfiguration), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*config.CompressionConfiguration)(nil), (*CompressionConfiguration)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_config_CompressionConfiguration_To_v1_CompressionConfiguration(a.(*config.CompressionConfiguration), b.(*CompressionConfiguration), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*EncryptionConfiguration)(nil), (*config.EncryptionConfiguration)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1_EncryptionConfiguration_To_config_EncryptionConfiguration(a.(*EncryptionConfiguration), b.(*config.EncryptionConfiguration), scope)
 	}); err != nil {
@@ -56,6 +66,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*HKDFConfiguration)(nil), (*config.HKDFConfiguration)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1_HKDFConfiguration_To_config_HKDFConfiguration(a.(*HKDFConfiguration), b.(*config.HKDFConfiguration), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*config.HKDFConfiguration)(nil), (*HKDFConfiguration)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_config_HKDFConfiguration_To_v1_HKDFConfiguration(a.(*config.HKDFConfiguration), b.(*HKDFConfiguration), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*IdentityConfiguration)(nil), (*config.IdentityConfiguration)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1_IdentityConfiguration_To_config_IdentityConfiguration(a.(*IdentityConfiguration), b.(*config.IdentityConfiguration), scope)
 	}); err != nil {
@@ -139,6 +159,30 @@ func Convert_config_AESConfiguration_To_v1_AESConfiguration(in *config.AESConfig
 	return autoConvert_config_AESConfiguration_To_v1_AESConfiguration(in, out, s)
 }
 
+func autoConvert_v1_CompressionConfiguration_To_config_CompressionConfiguration(in *CompressionConfiguration, out *config.CompressionConfiguration, s conversion.Scope) error {
+	out.Algorithm = in.Algorithm
+	out.MinSize = in.MinSize
+	out.MaxSize = in.MaxSize
+	return nil
+}
+
+// Convert_v1_CompressionConfiguration_To_config_CompressionConfiguration is an autogenerated conversion function.
+func Convert_v1_CompressionConfiguration_To_config_CompressionConfiguration(in *CompressionConfiguration, out *config.CompressionConfiguration, s conversion.Scope) error {
+	return autoConvert_v1_CompressionConfiguration_To_config_CompressionConfiguration(in, out, s)
+}
+
+func autoConvert_config_CompressionConfiguration_To_v1_CompressionConfiguration(in *config.CompressionConfiguration, out *CompressionConfiguration, s conversion.Scope) error {
+	out.Algorithm = in.Algorithm
+	out.MinSize = in.MinSize
+	out.MaxSize = in.MaxSize
+	return nil
+}
+
+// Convert_config_CompressionConfiguration_To_v1_CompressionConfiguration is an autogenerated conversion function.
+func Convert_config_CompressionConfiguration_To_v1_CompressionConfiguration(in *config.CompressionConfiguration, out *CompressionConfiguration, s conversion.Scope) error {
+	return autoConvert_config_CompressionConfiguration_To_v1_CompressionConfiguration(in, out, s)
+}
+
 func autoConvert_v1_EncryptionConfiguration_To_config_EncryptionConfiguration(in *EncryptionConfiguration, out *config.EncryptionConfiguration, s conversion.Scope) error {
 	out.Resources = *(*[]config.ResourceConfiguration)(unsafe.Pointer(&in.Resources))
 	return nil
@@ -159,6 +203,28 @@ func Convert_config_EncryptionConfiguration_To_v1_EncryptionConfiguration(in *co
 	return autoConvert_config_EncryptionConfiguration_To_v1_EncryptionConfiguration(in, out, s)
 }
 
+func autoConvert_v1_HKDFConfiguration_To_config_HKDFConfiguration(in *HKDFConfiguration, out *config.HKDFConfiguration, s conversion.Scope) error {
+	out.Cipher = in.Cipher
+	out.Keys = *(*[]config.Key)(unsafe.Pointer(&in.Keys))
+	return nil
+}
+
+// Convert_v1_HKDFConfiguration_To_config_HKDFConfiguration is an autogenerated conversion function.
+func Convert_v1_HKDFConfiguration_To_config_HKDFConfiguration(in *HKDFConfiguration, out *config.HKDFConfiguration, s conversion.Scope) error {
+	return autoConvert_v1_HKDFConfiguration_To_config_HKDFConfiguration(in, out, s)
+}
+
+func autoConvert_config_HKDFConfiguration_To_v1_HKDFConfiguration(in *config.HKDFConfiguration, out *HKDFConfiguration, s conversion.Scope) error {
+	out.Cipher = in.Cipher
+	out.Keys = *(*[]Key)(unsafe.Pointer(&in.Keys))
+	return nil
+}
+
+// Convert_config_HKDFConfiguration_To_v1_HKDFConfiguration is an autogenerated conversion function.
+func Convert_config_HKDFConfiguration_To_v1_HKDFConfiguration(in *config.HKDFConfiguration, out *HKDFConfiguration, s conversion.Scope) error {
+	return autoConvert_config_HKDFConfiguration_To_v1_HKDFConfiguration(in, out, s)
+}
+
 func autoConvert_v1_IdentityConfiguration_To_config_IdentityConfiguration(in *IdentityConfiguration, out *config.IdentityConfiguration, s conversion.Scope) error {
 	return nil
 }
@@ -178,6 +244,7 @@ func Convert_config_IdentityConfiguration_To_v1_IdentityConfiguration(in *config
 }
 
 func autoConvert_v1_KMSConfiguration_To_config_KMSConfiguration(in *KMSConfiguration, out *config.KMSConfiguration, s conversion.Scope) error {
+	out.APIVersion = in.APIVersion
 	out.Name = in.Name
 	out.CacheSize = in.CacheSize
 	out.Endpoint = in.Endpoint
@@ -191,6 +258,7 @@ func Convert_v1_KMSConfiguration_To_config_KMSConfiguration(in *KMSConfiguration
 }
 
 func autoConvert_config_KMSConfiguration_To_v1_KMSConfiguration(in *config.KMSConfiguration, out *KMSConfiguration, s conversion.Scope) error {
+	out.APIVersion = in.APIVersion
 	out.Name = in.Name
 	out.CacheSize = in.CacheSize
 	out.Endpoint = in.Endpoint
@@ -231,6 +299,7 @@ func autoConvert_v1_ProviderConfiguration_To_config_ProviderConfiguration(in *Pr
 	out.Secretbox = (*config.SecretboxConfiguration)(unsafe.Pointer(in.Secretbox))
 	out.Identity = (*config.IdentityConfiguration)(unsafe.Pointer(in.Identity))
 	out.KMS = (*config.KMSConfiguration)(unsafe.Pointer(in.KMS))
+	out.HKDF = (*config.HKDFConfiguration)(unsafe.Pointer(in.HKDF))
 	return nil
 }
 
@@ -245,6 +314,7 @@ func autoConvert_config_ProviderConfiguration_To_v1_ProviderConfiguration(in *co
 	out.Secretbox = (*SecretboxConfiguration)(unsafe.Pointer(in.Secretbox))
 	out.Identity = (*IdentityConfiguration)(unsafe.Pointer(in.Identity))
 	out.KMS = (*KMSConfiguration)(unsafe.Pointer(in.KMS))
+	out.HKDF = (*HKDFConfiguration)(unsafe.Pointer(in.HKDF))
 	return nil
 }
 
@@ -256,6 +326,7 @@ func Convert_config_ProviderConfiguration_To_v1_ProviderConfiguration(in *config
 func autoConvert_v1_ResourceConfiguration_To_config_ResourceConfiguration(in *ResourceConfiguration, out *config.ResourceConfiguration, s conversion.Scope) error {
 	out.Resources = *(*[]string)(unsafe.Pointer(&in.Resources))
 	out.Providers = *(*[]config.ProviderConfiguration)(unsafe.Pointer(&in.Providers))
+	out.Compression = (*config.CompressionConfiguration)(unsafe.Pointer(in.Compression))
 	return nil
 }
 
@@ -267,6 +338,7 @@ func Convert_v1_ResourceConfiguration_To_config_ResourceConfiguration(in *Resour
 func autoConvert_config_ResourceConfiguration_To_v1_ResourceConfiguration(in *config.ResourceConfiguration, out *ResourceConfiguration, s conversion.Scope) error {
 	out.Resources = *(*[]string)(unsafe.Pointer(&in.Resources))
 	out.Providers = *(*[]ProviderConfiguration)(unsafe.Pointer(&in.Providers))
+	out.Compression = (*CompressionConfiguration)(unsafe.Pointer(in.Compression))
 	return nil
 }
 
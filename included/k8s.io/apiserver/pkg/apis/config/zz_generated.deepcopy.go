@@ -46,6 +46,22 @@ func (in *AESConfiguration) DeepCopy() *AESConfiguration {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CompressionConfiguration) DeepCopyInto(out *CompressionConfiguration) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CompressionConfiguration.
+func (in *CompressionConfiguration) DeepCopy() *CompressionConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(CompressionConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EncryptionConfiguration) DeepCopyInto(out *EncryptionConfiguration) {
 	*out = *in
@@ -78,6 +94,27 @@ func (in *EncryptionConfiguration) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HKDFConfiguration) DeepCopyInto(out *HKDFConfiguration) {
+	*out = *in
+	if in.Keys != nil {
+		in, out := &in.Keys, &out.Keys
+		*out = make([]Key, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HKDFConfiguration.
+func (in *HKDFConfiguration) DeepCopy() *HKDFConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(HKDFConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IdentityConfiguration) DeepCopyInto(out *IdentityConfiguration) {
 	*out = *in
@@ -159,6 +196,11 @@ func (in *ProviderConfiguration) DeepCopyInto(out *ProviderConfiguration) {
 		*out = new(KMSConfiguration)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.HKDF != nil {
+		in, out := &in.HKDF, &out.HKDF
+		*out = new(HKDFConfiguration)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -187,6 +229,11 @@ func (in *ResourceConfiguration) DeepCopyInto(out *ResourceConfiguration) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Compression != nil {
+		in, out := &in.Compression, &out.Compression
+		*out = new(CompressionConfiguration)
+		**out = **in
+	}
 	return
 }
 
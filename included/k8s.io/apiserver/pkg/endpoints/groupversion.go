@@ -74,6 +74,9 @@ type APIGroupVersion struct {
 
 	EquivalentResourceRegistry runtime.EquivalentResourceRegistry
 
+	// StorageRegistry, if set, records the storages of the installed resources.
+	StorageRegistry *rest.StorageRegistry
+
 	// Authorizer determines whether a user is allowed to make a certain request. The Handler does a preliminary
 	// authorization check using the request URI but it may be necessary to make additional checks, such as in
 	// the create-on-update case
@@ -113,6 +113,11 @@ func DeleteResource(r rest.GracefulDeleter, allowsOptions bool, scope *RequestSc
 			scope.err(err, w, req)
 			return
 		}
+		ifMatch, hasIfMatch, err := ifMatchResourceVersions(req.Header.Get("If-Match"))
+		if err != nil {
+			scope.err(err, w, req)
+			return
+		}
 		options.TypeMeta.SetGroupVersionKind(metav1.SchemeGroupVersion.WithKind("DeleteOptions"))
 
 		trace.Step("About to delete object from database")
@@ -120,7 +125,11 @@ func DeleteResource(r rest.GracefulDeleter, allowsOptions bool, scope *RequestSc
 		userInfo, _ := request.UserFrom(ctx)
 		staticAdmissionAttrs := admission.NewAttributesRecord(nil, nil, scope.Kind, namespace, name, scope.Resource, scope.Subresource, admission.Delete, options, dryrun.IsDryRun(options.DryRun), userInfo)
 		result, err := finishRequest(timeout, func() (runtime.Object, error) {
-			obj, deleted, err := r.Delete(ctx, name, rest.AdmissionToValidateObjectDeleteFunc(admit, staticAdmissionAttrs, scope), options)
+			deleteValidation := rest.AdmissionToValidateObjectDeleteFunc(admit, staticAdmissionAttrs, scope)
+			if hasIfMatch {
+				deleteValidation = ifMatchDeleteValidation(scope.Resource.GroupResource(), name, ifMatch, deleteValidation)
+			}
+			obj, deleted, err := r.Delete(ctx, name, deleteValidation, options)
 			wasDeleted = deleted
 			return obj, err
 		})
@@ -198,7 +207,7 @@ func DeleteCollection(r rest.CollectionDeleter, checkBody bool, scope *RequestSc
 		// TODO: DecodeParametersInto should do this.
 		if listOptions.FieldSelector != nil {
 			fn := func(label, value string) (newLabel, newValue string, err error) {
-				return scope.Convertor.ConvertFieldLabel(scope.Kind, label, value)
+				return scope.convertFieldLabel(label, value)
 			}
 			if listOptions.FieldSelector, err = listOptions.FieldSelector.Transform(fn); err != nil {
 				// TODO: allow bad request to set field causes based on query parameters
@@ -0,0 +1,210 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"strings"
+
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/api/errors"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/api/meta"
+	metav1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime/schema"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/util/sets"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/endpoints/handlers/negotiation"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/registry/rest"
+)
+
+// resourceETag returns a strong entity tag identifying the representation of obj that
+// will be written for req, or an empty string if obj carries no resourceVersion. The tag
+// is the resourceVersion followed by a hash of everything else that shapes the response
+// body: the negotiated media type, any conversion target and the query parameters.
+func resourceETag(obj runtime.Object, mediaType negotiation.MediaTypeOptions, req *http.Request) string {
+	if _, ok := obj.(*metav1.Status); ok {
+		return ""
+	}
+	resourceVersion, err := meta.NewAccessor().ResourceVersion(obj)
+	if err != nil || len(resourceVersion) == 0 {
+		return ""
+	}
+	h := fnv.New32a()
+	h.Write([]byte(mediaType.Accepted.MediaType))
+	if mediaType.Pretty {
+		h.Write([]byte(";pretty"))
+	}
+	if mediaType.Convert != nil {
+		h.Write([]byte(";as=" + mediaType.Convert.String()))
+	}
+	h.Write([]byte("?" + req.URL.Query().Encode()))
+	return fmt.Sprintf("%q", fmt.Sprintf("%s-%08x", resourceVersion, h.Sum32()))
+}
+
+// parseEntityTags splits the value of an If-Match or If-None-Match header into its entity
+// tags, with the opaque tag unquoted. weak reports whether each tag was marked weak.
+func parseEntityTags(header string) (tags []string, weak []bool, err error) {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if len(tag) == 0 {
+			continue
+		}
+		if tag == "*" {
+			tags = append(tags, tag)
+			weak = append(weak, false)
+			continue
+		}
+		isWeak := strings.HasPrefix(tag, "W/")
+		tag = strings.TrimPrefix(tag, "W/")
+		if len(tag) < 2 || !strings.HasPrefix(tag, `"`) || !strings.HasSuffix(tag, `"`) {
+			return nil, nil, fmt.Errorf("malformed entity tag %s", tag)
+		}
+		tags = append(tags, tag[1:len(tag)-1])
+		weak = append(weak, isWeak)
+	}
+	return tags, weak, nil
+}
+
+// notModified reports whether the If-None-Match header of req matches etag, in which
+// case the client already holds the current representation. Matching uses the weak
+// comparison required for If-None-Match.
+func notModified(req *http.Request, etag string) bool {
+	header := req.Header.Get("If-None-Match")
+	if len(header) == 0 || len(etag) == 0 {
+		return false
+	}
+	tags, _, err := parseEntityTags(header)
+	if err != nil {
+		return false
+	}
+	for _, tag := range tags {
+		if tag == "*" || fmt.Sprintf("%q", tag) == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// checkNotModified returns the ETag for the representation of result and, if the client
+// already holds it, answers the request with 304 Not Modified. written is true when the
+// response has been written. Otherwise the ETag is left for transformResponseObjectWithETag
+// to set once the representation could be produced, so that errors carry no ETag.
+func checkNotModified(w http.ResponseWriter, req *http.Request, mediaType negotiation.MediaTypeOptions, result runtime.Object) (etag string, written bool) {
+	etag = resourceETag(result, mediaType, req)
+	if !notModified(req, etag) {
+		return etag, false
+	}
+	w.Header().Set("ETag", etag)
+	w.WriteHeader(http.StatusNotModified)
+	return etag, true
+}
+
+// ifMatchResourceVersions returns the resourceVersions accepted by the value of an If-Match
+// header. ok is false if the header is absent. No resourceVersions means the header was
+// "*", which only requires the object to exist. Since the resourceVersion alone identifies
+// the state of the object, tags issued for different media types of the same
+// resourceVersion are all accepted, and the header matches if any of its tags does.
+func ifMatchResourceVersions(header string) (resourceVersions sets.String, ok bool, err error) {
+	if len(header) == 0 {
+		return nil, false, nil
+	}
+	tags, weak, err := parseEntityTags(header)
+	if err != nil {
+		return nil, false, errors.NewBadRequest(fmt.Sprintf("invalid If-Match header: %v", err))
+	}
+	if len(tags) == 0 {
+		return nil, false, errors.NewBadRequest("invalid If-Match header: no entity tags")
+	}
+	resourceVersions = sets.NewString()
+	for i, tag := range tags {
+		if tag == "*" {
+			if len(tags) > 1 {
+				return nil, false, errors.NewBadRequest("invalid If-Match header: \"*\" must be the only entity tag")
+			}
+			return nil, true, nil
+		}
+		if weak[i] {
+			return nil, false, errors.NewBadRequest("invalid If-Match header: weak entity tags cannot be used as preconditions")
+		}
+		idx := strings.LastIndex(tag, "-")
+		if idx <= 0 {
+			return nil, false, errors.NewBadRequest(fmt.Sprintf("invalid If-Match header: entity tag %q was not issued by this server", tag))
+		}
+		resourceVersions.Insert(tag[:idx])
+	}
+	return resourceVersions, true, nil
+}
+
+// checkIfMatch returns an error unless obj, the persisted state of the object, exists and,
+// if resourceVersions is not empty, is at one of them. A mismatch fails with 412
+// Precondition Failed rather than a conflict, as required for If-Match.
+func checkIfMatch(resource schema.GroupResource, name string, resourceVersions sets.String, obj runtime.Object) error {
+	exists, err := hasUID(obj)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errors.NewNotFound(resource, name)
+	}
+	if len(resourceVersions) == 0 {
+		return nil
+	}
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+	if !resourceVersions.Has(accessor.GetResourceVersion()) {
+		return &errors.StatusError{ErrStatus: metav1.Status{
+			Status: metav1.StatusFailure,
+			Code:   http.StatusPreconditionFailed,
+			Details: &metav1.StatusDetails{
+				Group: resource.Group,
+				Kind:  resource.Resource,
+				Name:  name,
+			},
+			Message: fmt.Sprintf("the object has been modified; If-Match requires resourceVersion %s, but the current resourceVersion is %s", strings.Join(resourceVersions.List(), " or "), accessor.GetResourceVersion()),
+		}}
+	}
+	return nil
+}
+
+// ifMatchPrecondition returns a transform that rejects an update unless the persisted
+// object matches the If-Match header. The transform runs against the current state within
+// the storage update, so the check is atomic with the write just like the
+// metadata.resourceVersion precondition.
+func ifMatchPrecondition(resource schema.GroupResource, name string, resourceVersions sets.String) rest.TransformFunc {
+	return func(_ context.Context, newObj, oldObj runtime.Object) (runtime.Object, error) {
+		if err := checkIfMatch(resource, name, resourceVersions, oldObj); err != nil {
+			return nil, err
+		}
+		return newObj, nil
+	}
+}
+
+// ifMatchDeleteValidation returns a delete validation that rejects the deletion unless the
+// persisted object matches the If-Match header before running deleteValidation. The
+// storage validates the state it deletes, so the check is atomic with the deletion.
+func ifMatchDeleteValidation(resource schema.GroupResource, name string, resourceVersions sets.String, deleteValidation rest.ValidateObjectFunc) rest.ValidateObjectFunc {
+	return func(obj runtime.Object) error {
+		if err := checkIfMatch(resource, name, resourceVersions, obj); err != nil {
+			return err
+		}
+		return deleteValidation(obj)
+	}
+}
@@ -0,0 +1,331 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	v1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/core/v1"
+	apierrors "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/api/errors"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/api/meta"
+	metainternalversion "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime/schema"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/util/sets"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/endpoints/handlers/negotiation"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/endpoints/request"
+	clientgoscheme "github.com/aaron-prindle/krmapiserver/included/k8s.io/client-go/kubernetes/scheme"
+)
+
+var etagMediaTypes = []string{
+	"application/json",
+	"application/yaml",
+	"application/vnd.kubernetes.protobuf",
+}
+
+type etagTestStorage struct {
+	pod *v1.Pod
+}
+
+func (s *etagTestStorage) Get(ctx context.Context, name string, options *metav1.GetOptions) (runtime.Object, error) {
+	return s.pod.DeepCopy(), nil
+}
+
+func (s *etagTestStorage) NewList() runtime.Object {
+	return &v1.PodList{}
+}
+
+func (s *etagTestStorage) List(ctx context.Context, options *metainternalversion.ListOptions) (runtime.Object, error) {
+	return &v1.PodList{
+		ListMeta: metav1.ListMeta{ResourceVersion: s.pod.ResourceVersion},
+		Items:    []v1.Pod{*s.pod.DeepCopy()},
+	}, nil
+}
+
+func newETagTestScope() *RequestScope {
+	return &RequestScope{
+		Namer: ContextBasedNaming{
+			SelfLinker:         meta.NewAccessor(),
+			SelfLinkPathPrefix: "/api/v1/",
+		},
+		Serializer:       clientgoscheme.Codecs,
+		Kind:             v1.SchemeGroupVersion.WithKind("Pod"),
+		Resource:         v1.SchemeGroupVersion.WithResource("pods"),
+		MetaGroupVersion: metav1.SchemeGroupVersion,
+	}
+}
+
+func newETagTestRequest(verb, name, accept, ifNoneMatch string) *http.Request {
+	path := "/api/v1/namespaces/default/pods"
+	if len(name) > 0 {
+		path += "/" + name
+	}
+	req := httptest.NewRequest("GET", path, nil)
+	req.Header.Set("Accept", accept)
+	if len(ifNoneMatch) > 0 {
+		req.Header.Set("If-None-Match", ifNoneMatch)
+	}
+	ctx := request.WithRequestInfo(req.Context(), &request.RequestInfo{
+		IsResourceRequest: true,
+		Verb:              verb,
+		APIGroup:          v1.GroupName,
+		APIVersion:        "v1",
+		Namespace:         "default",
+		Resource:          "pods",
+		Name:              name,
+	})
+	return req.WithContext(ctx)
+}
+
+func TestConditionalGetAndList(t *testing.T) {
+	storage := &etagTestStorage{pod: &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "foo", ResourceVersion: "10"},
+	}}
+	scope := newETagTestScope()
+	handlers := map[string]http.Handler{
+		"get":  GetResource(storage, nil, scope),
+		"list": ListResource(storage, nil, scope, false, 0),
+	}
+
+	for verb, handler := range handlers {
+		name := ""
+		if verb == "get" {
+			name = "foo"
+		}
+		storage.pod.ResourceVersion = "10"
+		etags := map[string]string{}
+		for _, mediaType := range etagMediaTypes {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, newETagTestRequest(verb, name, mediaType, ""))
+			if w.Code != http.StatusOK {
+				t.Fatalf("%s %s: expected 200, got %d: %s", verb, mediaType, w.Code, w.Body.String())
+			}
+			etag := w.Header().Get("ETag")
+			if len(etag) == 0 {
+				t.Fatalf("%s %s: expected an ETag", verb, mediaType)
+			}
+			for other, otherETag := range etags {
+				if etag == otherETag {
+					t.Errorf("%s: %s and %s share the ETag %s", verb, mediaType, other, etag)
+				}
+			}
+			etags[mediaType] = etag
+		}
+
+		for _, mediaType := range etagMediaTypes {
+			etag := etags[mediaType]
+			for _, ifNoneMatch := range []string{etag, "W/" + etag, `"other", ` + etag, "*"} {
+				w := httptest.NewRecorder()
+				handler.ServeHTTP(w, newETagTestRequest(verb, name, mediaType, ifNoneMatch))
+				if w.Code != http.StatusNotModified {
+					t.Errorf("%s %s with If-None-Match %s: expected 304, got %d", verb, mediaType, ifNoneMatch, w.Code)
+				}
+				if w.Body.Len() != 0 {
+					t.Errorf("%s %s with If-None-Match %s: expected an empty body, got %q", verb, mediaType, ifNoneMatch, w.Body.String())
+				}
+				if got := w.Header().Get("ETag"); got != etag {
+					t.Errorf("%s %s with If-None-Match %s: expected ETag %s, got %s", verb, mediaType, ifNoneMatch, etag, got)
+				}
+			}
+
+			// a tag issued for another media type does not match this representation
+			for other, otherETag := range etags {
+				if other == mediaType {
+					continue
+				}
+				w := httptest.NewRecorder()
+				handler.ServeHTTP(w, newETagTestRequest(verb, name, mediaType, otherETag))
+				if w.Code != http.StatusOK {
+					t.Errorf("%s %s with the ETag of %s: expected 200, got %d", verb, mediaType, other, w.Code)
+				}
+			}
+		}
+
+		storage.pod.ResourceVersion = "11"
+		for _, mediaType := range etagMediaTypes {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, newETagTestRequest(verb, name, mediaType, etags[mediaType]))
+			if w.Code != http.StatusOK {
+				t.Errorf("%s %s after an update: expected 200, got %d", verb, mediaType, w.Code)
+			}
+			if got := w.Header().Get("ETag"); got == etags[mediaType] || len(got) == 0 {
+				t.Errorf("%s %s after an update: expected a new ETag, got %q", verb, mediaType, got)
+			}
+		}
+	}
+}
+
+func TestNoETagOnError(t *testing.T) {
+	storage := &etagTestStorage{pod: &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "foo", ResourceVersion: "10"},
+	}}
+	scope := newETagTestScope()
+	handlers := map[string]http.Handler{
+		"get":  GetResource(storage, nil, scope),
+		"list": ListResource(storage, nil, scope, false, 0),
+	}
+	for verb, handler := range handlers {
+		name := ""
+		if verb == "get" {
+			name = "foo"
+		}
+		// fields cannot be selected from protobuf, so the object is never written
+		req := newETagTestRequest(verb, name, "application/vnd.kubernetes.protobuf", "")
+		req.URL.RawQuery = "fields=metadata.name"
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != http.StatusNotAcceptable {
+			t.Errorf("%s: expected 406, got %d: %s", verb, w.Code, w.Body.String())
+		}
+		if etag := w.Header().Get("ETag"); len(etag) != 0 {
+			t.Errorf("%s: expected no ETag on an error, got %s", verb, etag)
+		}
+	}
+}
+
+func TestResourceETag(t *testing.T) {
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo", ResourceVersion: "10"}}
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/default/pods/foo", nil)
+	for _, mediaType := range etagMediaTypes {
+		info, ok := runtime.SerializerInfoForMediaType(clientgoscheme.Codecs.SupportedMediaTypes(), mediaType)
+		if !ok {
+			t.Fatalf("no serializer for %s", mediaType)
+		}
+		options := negotiation.MediaTypeOptions{Accepted: info}
+		etag := resourceETag(pod, options, req)
+		if etag != resourceETag(pod.DeepCopy(), options, req) {
+			t.Errorf("%s: expected a stable ETag", mediaType)
+		}
+		rvs, ok, err := ifMatchResourceVersions(etag)
+		if err != nil || !ok || !rvs.Equal(sets.NewString("10")) {
+			t.Errorf("%s: expected the ETag %s to carry resourceVersion 10, got %v %v %v", mediaType, etag, rvs.List(), ok, err)
+		}
+
+		pretty := options
+		pretty.Pretty = true
+		if resourceETag(pod, pretty, req) == etag {
+			t.Errorf("%s: expected pretty printing to change the ETag", mediaType)
+		}
+		converted := options
+		converted.Convert = &schema.GroupVersionKind{Group: metav1.GroupName, Version: "v1", Kind: "PartialObjectMetadata"}
+		if resourceETag(pod, converted, req) == etag {
+			t.Errorf("%s: expected a conversion to change the ETag", mediaType)
+		}
+		if resourceETag(pod, options, httptest.NewRequest("GET", req.URL.Path+"?export=true", nil)) == etag {
+			t.Errorf("%s: expected query parameters to change the ETag", mediaType)
+		}
+		if etag := resourceETag(&v1.Pod{}, options, req); len(etag) != 0 {
+			t.Errorf("%s: expected no ETag without a resourceVersion, got %s", mediaType, etag)
+		}
+		if etag := resourceETag(&metav1.Status{ListMeta: metav1.ListMeta{ResourceVersion: "10"}}, options, req); len(etag) != 0 {
+			t.Errorf("%s: expected no ETag for a status, got %s", mediaType, etag)
+		}
+	}
+}
+
+func TestIfMatchResourceVersions(t *testing.T) {
+	tests := []struct {
+		header           string
+		resourceVersions []string
+		ok               bool
+		badRequest       bool
+	}{
+		{header: ""},
+		{header: `"10-0123abcd"`, resourceVersions: []string{"10"}, ok: true},
+		{header: `"10-0123abcd", "10-89abcdef"`, resourceVersions: []string{"10"}, ok: true},
+		{header: `"10-0123abcd", "11-0123abcd"`, resourceVersions: []string{"10", "11"}, ok: true},
+		{header: "*", ok: true},
+		{header: `W/"10-0123abcd"`, badRequest: true},
+		{header: `"10"`, badRequest: true},
+		{header: `10-0123abcd`, badRequest: true},
+		{header: `*, "10-0123abcd"`, badRequest: true},
+		{header: ` , `, badRequest: true},
+	}
+	for _, test := range tests {
+		resourceVersions, ok, err := ifMatchResourceVersions(test.header)
+		if test.badRequest {
+			if !apierrors.IsBadRequest(err) {
+				t.Errorf("%q: expected a bad request error, got %v", test.header, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", test.header, err)
+			continue
+		}
+		if !resourceVersions.Equal(sets.NewString(test.resourceVersions...)) || ok != test.ok {
+			t.Errorf("%q: expected %v %v, got %v %v", test.header, test.resourceVersions, test.ok, resourceVersions.List(), ok)
+		}
+	}
+}
+
+func isPreconditionFailed(err error) bool {
+	status, ok := err.(apierrors.APIStatus)
+	return ok && status.Status().Code == http.StatusPreconditionFailed
+}
+
+func TestIfMatchPrecondition(t *testing.T) {
+	resource := schema.GroupResource{Group: v1.GroupName, Resource: "pods"}
+	existing := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo", UID: "uid", ResourceVersion: "10"}}
+	updated := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo"}}
+
+	tests := []struct {
+		name             string
+		resourceVersions []string
+		oldObj           runtime.Object
+		check            func(error) bool
+	}{
+		{name: "matching resourceVersion", resourceVersions: []string{"10"}, oldObj: existing},
+		{name: "one of several resourceVersions", resourceVersions: []string{"9", "10"}, oldObj: existing},
+		{name: "any resourceVersion", oldObj: existing},
+		{name: "stale resourceVersion", resourceVersions: []string{"9"}, oldObj: existing, check: isPreconditionFailed},
+		{name: "missing object", resourceVersions: []string{"10"}, oldObj: &v1.Pod{}, check: apierrors.IsNotFound},
+		{name: "missing object with any resourceVersion", oldObj: &v1.Pod{}, check: apierrors.IsNotFound},
+	}
+	for _, test := range tests {
+		resourceVersions := sets.NewString(test.resourceVersions...)
+		obj, err := ifMatchPrecondition(resource, "foo", resourceVersions)(context.TODO(), updated, test.oldObj)
+		validated := false
+		deleteErr := ifMatchDeleteValidation(resource, "foo", resourceVersions, func(runtime.Object) error {
+			validated = true
+			return nil
+		})(test.oldObj)
+		if test.check != nil {
+			if !test.check(err) {
+				t.Errorf("%s: unexpected error: %v", test.name, err)
+			}
+			if !test.check(deleteErr) || validated {
+				t.Errorf("%s: unexpected delete validation error: %v", test.name, deleteErr)
+			}
+			continue
+		}
+		if err != nil || deleteErr != nil {
+			t.Errorf("%s: unexpected errors: %v, %v", test.name, err, deleteErr)
+			continue
+		}
+		if obj != updated {
+			t.Errorf("%s: expected the updated object to be passed through, got %#v", test.name, obj)
+		}
+		if !validated {
+			t.Errorf("%s: expected the deletion to be validated", test.name)
+		}
+	}
+}
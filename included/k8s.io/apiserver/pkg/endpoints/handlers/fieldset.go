@@ -0,0 +1,242 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/api/errors"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/api/meta"
+	metav1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/endpoints/handlers/negotiation"
+)
+
+// fieldsQueryParam is the query parameter carrying the paths of a sparse fieldset. It may
+// be repeated and each value may hold several comma separated paths.
+const fieldsQueryParam = "fields"
+
+// alwaysSelectedPaths are kept in every pruned object so that clients can still identify
+// objects and resume watches from them.
+var alwaysSelectedPaths = []string{
+	"apiVersion",
+	"kind",
+	"metadata.name",
+	"metadata.namespace",
+	"metadata.uid",
+	"metadata.resourceVersion",
+}
+
+// fieldset is the set of JSON paths a client asked the returned objects to be pruned to.
+// Each path is a list of field names, and a path that reaches a list applies its remaining
+// fields to every item of the list.
+type fieldset [][]string
+
+// fieldsetForTransform returns the sparse fieldset requested by a read, or nil if the
+// whole object was requested. Pruned objects are returned as unstructured content, so a
+// fieldset cannot be combined with a conversion and is only served as JSON or YAML, or as
+// server-sent events which carry JSON.
+func fieldsetForTransform(mediaType negotiation.MediaTypeOptions, req *http.Request) (fieldset, error) {
+	if req.Method != http.MethodGet {
+		return nil, nil
+	}
+	values, ok := req.URL.Query()[fieldsQueryParam]
+	if !ok {
+		return nil, nil
+	}
+	fields, err := parseFieldset(values)
+	if err != nil {
+		return nil, errors.NewBadRequest(err.Error())
+	}
+	if mediaType.Convert != nil {
+		return nil, errors.NewBadRequest(fmt.Sprintf("%s cannot be combined with a request for %s", fieldsQueryParam, mediaType.Convert.Kind))
+	}
+	switch mediaType.Accepted.MediaTypeSubType {
+	case "json", "yaml", "event-stream":
+	default:
+		return nil, newNotAcceptableError(fmt.Sprintf("%s is only supported for JSON and YAML, not %s", fieldsQueryParam, mediaType.Accepted.MediaType))
+	}
+	return fields, nil
+}
+
+// parseFieldset parses paths such as "status.phase" or ".metadata.labels". Paths are
+// deduplicated and sorted, so equivalent requests produce the same fieldset.
+func parseFieldset(values []string) (fieldset, error) {
+	seen := map[string]bool{}
+	var paths []string
+	for _, value := range values {
+		for _, path := range strings.Split(value, ",") {
+			path = strings.TrimPrefix(strings.TrimSpace(path), ".")
+			if len(path) == 0 {
+				return nil, fmt.Errorf("%s must not contain empty paths", fieldsQueryParam)
+			}
+			for _, field := range strings.Split(path, ".") {
+				if len(field) == 0 {
+					return nil, fmt.Errorf("%s contains an invalid path %q", fieldsQueryParam, path)
+				}
+			}
+			if !seen[path] {
+				seen[path] = true
+				paths = append(paths, path)
+			}
+		}
+	}
+	sort.Strings(paths)
+	fields := make(fieldset, 0, len(paths))
+	for _, path := range paths {
+		fields = append(fields, strings.Split(path, "."))
+	}
+	return fields, nil
+}
+
+// String returns the canonical form of the fieldset.
+func (f fieldset) String() string {
+	paths := make([]string, 0, len(f))
+	for _, path := range f {
+		paths = append(paths, strings.Join(path, "."))
+	}
+	return strings.Join(paths, ",")
+}
+
+// prune returns obj, or each item of obj if it is a list, reduced to the fields of the
+// fieldset, in the version requested by scope. obj is never modified, since watch events
+// may share it with other watchers.
+func (f fieldset) prune(obj runtime.Object, scope *RequestScope) (runtime.Object, error) {
+	if _, ok := obj.(*metav1.Status); ok {
+		return obj, nil
+	}
+	isList := meta.IsListType(obj)
+	versioned, err := scope.Convertor.ConvertToVersion(obj, scope.Kind.GroupVersion())
+	if err != nil {
+		return nil, err
+	}
+	var content map[string]interface{}
+	if u, ok := versioned.(runtime.Unstructured); ok {
+		content = u.UnstructuredContent()
+	} else if content, err = runtime.DefaultUnstructuredConverter.ToUnstructured(versioned); err != nil {
+		return nil, err
+	}
+	gvk := versioned.GetObjectKind().GroupVersionKind()
+	if gvk.Empty() {
+		gvk = scope.Kind
+		if isList {
+			gvk.Kind += "List"
+		}
+	}
+
+	var pruned map[string]interface{}
+	if isList {
+		pruned = map[string]interface{}{}
+		if listMeta, ok := content["metadata"]; ok {
+			pruned["metadata"] = runtime.DeepCopyJSONValue(listMeta)
+		}
+		items, _ := content["items"].([]interface{})
+		prunedItems := make([]interface{}, 0, len(items))
+		for _, item := range items {
+			if item, ok := item.(map[string]interface{}); ok {
+				prunedItems = append(prunedItems, f.pruneContent(item))
+			}
+		}
+		pruned["items"] = prunedItems
+	} else {
+		pruned = f.pruneContent(content)
+	}
+	// the pruned object must carry its kind, so that it is encoded as is rather than
+	// converted back into the typed object
+	pruned["apiVersion"], pruned["kind"] = gvk.ToAPIVersionAndKind()
+	return &unstructured.Unstructured{Object: pruned}, nil
+}
+
+// pruneContent returns a copy of the selected fields of the unstructured content of a
+// single object.
+func (f fieldset) pruneContent(content map[string]interface{}) map[string]interface{} {
+	pruned := map[string]interface{}{}
+	for _, path := range alwaysSelectedPaths {
+		if selected, ok := selectPath(content, strings.Split(path, ".")); ok {
+			mergeSelected(pruned, selected.(map[string]interface{}))
+		}
+	}
+	for _, path := range f {
+		if selected, ok := selectPath(content, path); ok {
+			mergeSelected(pruned, selected.(map[string]interface{}))
+		}
+	}
+	return pruned
+}
+
+// selectPath returns a copy of the parts of value reached by path, nested the same way as
+// in value, and false if path reaches nothing. Items of a list that path does not reach
+// are kept as empty objects so that the positions of the other items are preserved.
+func selectPath(value interface{}, path []string) (interface{}, bool) {
+	if len(path) == 0 {
+		return runtime.DeepCopyJSONValue(value), true
+	}
+	switch value := value.(type) {
+	case map[string]interface{}:
+		child, ok := value[path[0]]
+		if !ok {
+			return nil, false
+		}
+		selected, ok := selectPath(child, path[1:])
+		if !ok {
+			return nil, false
+		}
+		return map[string]interface{}{path[0]: selected}, true
+	case []interface{}:
+		items := make([]interface{}, len(value))
+		found := false
+		for i, item := range value {
+			if selected, ok := selectPath(item, path); ok {
+				items[i] = selected
+				found = true
+			} else {
+				items[i] = map[string]interface{}{}
+			}
+		}
+		return items, found
+	}
+	return nil, false
+}
+
+// mergeSelected merges the fields selected from one object by different paths.
+func mergeSelected(dst, src map[string]interface{}) {
+	for k, v := range src {
+		dst[k] = mergeSelectedValue(dst[k], v)
+	}
+}
+
+func mergeSelectedValue(dst, src interface{}) interface{} {
+	switch src := src.(type) {
+	case map[string]interface{}:
+		if dst, ok := dst.(map[string]interface{}); ok {
+			mergeSelected(dst, src)
+			return dst
+		}
+	case []interface{}:
+		if dst, ok := dst.([]interface{}); ok && len(dst) == len(src) {
+			for i := range src {
+				dst[i] = mergeSelectedValue(dst[i], src[i])
+			}
+			return dst
+		}
+	}
+	return src
+}
@@ -0,0 +1,272 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	v1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/core/v1"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/api/equality"
+	metav1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/util/diff"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/util/wait"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/watch"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/endpoints/handlers/negotiation"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/endpoints/request"
+	clientgoscheme "github.com/aaron-prindle/krmapiserver/included/k8s.io/client-go/kubernetes/scheme"
+	"github.com/aaron-prindle/krmapiserver/included/sigs.k8s.io/yaml"
+)
+
+func newFieldsetTestPod() *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       "default",
+			Name:            "foo",
+			UID:             "uid",
+			ResourceVersion: "10",
+			Labels:          map[string]string{"app": "foo"},
+			Annotations:     map[string]string{"note": "large"},
+		},
+		Spec: v1.PodSpec{NodeName: "node"},
+		Status: v1.PodStatus{
+			Phase: "Running",
+			Conditions: []v1.PodCondition{
+				{Type: "Ready", Status: "True", Message: "ready"},
+				{Type: "Initialized", Status: "True"},
+			},
+		},
+	}
+}
+
+func TestParseFieldset(t *testing.T) {
+	tests := []struct {
+		values   []string
+		expected string
+		err      bool
+	}{
+		{values: []string{"status.phase"}, expected: "status.phase"},
+		{values: []string{".status.phase,metadata.labels"}, expected: "metadata.labels,status.phase"},
+		{values: []string{"status.phase", "metadata.labels", " status.phase "}, expected: "metadata.labels,status.phase"},
+		{values: []string{""}, err: true},
+		{values: []string{"status..phase"}, err: true},
+		{values: []string{"status.phase,"}, err: true},
+	}
+	for _, test := range tests {
+		fields, err := parseFieldset(test.values)
+		if test.err {
+			if err == nil {
+				t.Errorf("%v: expected an error", test.values)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%v: unexpected error: %v", test.values, err)
+			continue
+		}
+		if fields.String() != test.expected {
+			t.Errorf("%v: expected %s, got %s", test.values, test.expected, fields.String())
+		}
+	}
+}
+
+func TestFieldsetPrune(t *testing.T) {
+	scope := newETagTestScope()
+	scope.Convertor = clientgoscheme.Scheme
+	pod := newFieldsetTestPod()
+	original := pod.DeepCopy()
+
+	fields, err := parseFieldset([]string{"status.phase,metadata.labels,status.conditions.type,spec.missing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata": map[string]interface{}{
+			"namespace":       "default",
+			"name":            "foo",
+			"uid":             "uid",
+			"resourceVersion": "10",
+			"labels":          map[string]interface{}{"app": "foo"},
+		},
+		"status": map[string]interface{}{
+			"phase": "Running",
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Ready"},
+				map[string]interface{}{"type": "Initialized"},
+			},
+		},
+	}
+
+	pruned, err := fields.prune(pod, scope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content := pruned.(runtime.Unstructured).UnstructuredContent(); !reflect.DeepEqual(expected, content) {
+		t.Errorf("unexpected pruned object: %s", diff.ObjectReflectDiff(expected, content))
+	}
+	if !equality.Semantic.DeepEqual(original, pod) {
+		t.Errorf("the pruned object was modified: %s", diff.ObjectReflectDiff(original, pod))
+	}
+
+	list := &v1.PodList{
+		ListMeta: metav1.ListMeta{ResourceVersion: "12", Continue: "token"},
+		Items:    []v1.Pod{*pod, *pod},
+	}
+	pruned, err = fields.prune(list, scope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := pruned.(runtime.Unstructured).UnstructuredContent()
+	if content["kind"] != "PodList" {
+		t.Errorf("expected a PodList, got %v", content["kind"])
+	}
+	if listMeta := content["metadata"].(map[string]interface{}); listMeta["resourceVersion"] != "12" || listMeta["continue"] != "token" {
+		t.Errorf("expected the list metadata to be kept, got %v", listMeta)
+	}
+	items := content["items"].([]interface{})
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(items))
+	}
+	delete(expected, "apiVersion")
+	delete(expected, "kind")
+	for _, item := range items {
+		if !reflect.DeepEqual(expected, item) {
+			t.Errorf("unexpected pruned item: %s", diff.ObjectReflectDiff(expected, item))
+		}
+	}
+
+	status := &metav1.Status{Status: metav1.StatusFailure}
+	if pruned, err := fields.prune(status, scope); err != nil || pruned != status {
+		t.Errorf("expected a status to be returned as is, got %#v %v", pruned, err)
+	}
+}
+
+func TestSparseFieldsetList(t *testing.T) {
+	storage := &etagTestStorage{pod: newFieldsetTestPod()}
+	scope := newETagTestScope()
+	scope.Convertor = clientgoscheme.Scheme
+	handler := ListResource(storage, nil, scope, false, 0)
+
+	for _, mediaType := range []string{"application/json", "application/yaml"} {
+		req := newETagTestRequest("list", "", mediaType, "")
+		req.URL.RawQuery = "fields=status.phase"
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d: %s", mediaType, w.Code, w.Body.String())
+		}
+		list := map[string]interface{}{}
+		if err := yaml.Unmarshal(w.Body.Bytes(), &list); err != nil {
+			t.Fatalf("%s: %v", mediaType, err)
+		}
+		items := list["items"].([]interface{})
+		if len(items) != 1 {
+			t.Fatalf("%s: expected 1 item, got %v", mediaType, list)
+		}
+		item := items[0].(map[string]interface{})
+		if _, ok := item["spec"]; ok {
+			t.Errorf("%s: expected spec to be pruned, got %v", mediaType, item)
+		}
+		if phase := item["status"].(map[string]interface{})["phase"]; phase != "Running" {
+			t.Errorf("%s: expected status.phase to be kept, got %v", mediaType, item)
+		}
+		if name := item["metadata"].(map[string]interface{})["name"]; name != "foo" {
+			t.Errorf("%s: expected metadata.name to be kept, got %v", mediaType, item)
+		}
+	}
+
+	req := newETagTestRequest("list", "", "application/vnd.kubernetes.protobuf", "")
+	req.URL.RawQuery = "fields=status.phase"
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusNotAcceptable {
+		t.Errorf("expected 406 for protobuf, got %d: %s", w.Code, w.Body.String())
+	}
+
+	req = newETagTestRequest("list", "", "application/json", "")
+	req.URL.RawQuery = "fields=status..phase"
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an invalid path, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestSparseFieldsetWatch(t *testing.T) {
+	scope := newETagTestScope()
+	scope.Convertor = clientgoscheme.Scheme
+	watcher := watch.NewFake()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		req = req.WithContext(request.WithRequestInfo(req.Context(), &request.RequestInfo{
+			IsResourceRequest: true,
+			Verb:              "watch",
+			APIGroup:          v1.GroupName,
+			APIVersion:        "v1",
+			Namespace:         "default",
+			Resource:          "pods",
+		}))
+		mediaType := negotiation.MediaTypeOptions{}
+		mediaType.Accepted, _ = runtime.SerializerInfoForMediaType(clientgoscheme.Codecs.SupportedMediaTypes(), "application/json")
+		serveWatch(watcher, scope, mediaType, req, w, 0, nil, "")
+	}))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/v1/namespaces/default/pods?watch=true&fields=status.phase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	go watcher.Add(newFieldsetTestPod())
+
+	event := struct {
+		Type   string
+		Object map[string]interface{}
+	}{}
+	done := make(chan error, 1)
+	go func() {
+		done <- json.NewDecoder(resp.Body).Decode(&event)
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(wait.ForeverTestTimeout):
+		t.Fatal("timed out waiting for a watch event")
+	}
+
+	if event.Type != string(watch.Added) {
+		t.Errorf("expected an ADDED event, got %s", event.Type)
+	}
+	if _, ok := event.Object["spec"]; ok {
+		t.Errorf("expected spec to be pruned, got %v", event.Object)
+	}
+	if phase := event.Object["status"].(map[string]interface{})["phase"]; phase != "Running" {
+		t.Errorf("expected status.phase to be kept, got %v", event.Object)
+	}
+	if rv := event.Object["metadata"].(map[string]interface{})["resourceVersion"]; rv != "10" {
+		t.Errorf("expected metadata.resourceVersion to be kept, got %v", event.Object)
+	}
+}
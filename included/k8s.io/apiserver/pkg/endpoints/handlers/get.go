@@ -71,8 +71,14 @@ func getResourceHandler(scope *RequestScope, getter getterFunc) http.HandlerFunc
 			return
 		}
 
+		etag, written := checkNotModified(w, req, outputMediaType, result)
+		if written {
+			trace.Step("Object not modified")
+			return
+		}
+
 		trace.Step("About to write a response")
-		transformResponseObject(ctx, scope, trace, req, w, http.StatusOK, outputMediaType, result)
+		transformResponseObjectWithETag(ctx, scope, trace, req, w, http.StatusOK, outputMediaType, result, etag)
 		trace.Step("Transformed response object")
 	}
 }
@@ -184,15 +190,20 @@ func ListResource(r rest.Lister, rw rest.Watcher, scope *RequestScope, forceWatc
 		ctx := req.Context()
 		ctx = request.WithNamespace(ctx, namespace)
 
-		outputMediaType, _, err := negotiation.NegotiateOutputMediaType(req, scope.Serializer, scope)
-		if err != nil {
+		opts := metainternalversion.ListOptions{}
+		if err := metainternalversion.ParameterCodec.DecodeParameters(req.URL.Query(), scope.MetaGroupVersion, &opts); err != nil {
+			err = errors.NewBadRequest(err.Error())
 			scope.err(err, w, req)
 			return
 		}
 
-		opts := metainternalversion.ListOptions{}
-		if err := metainternalversion.ParameterCodec.DecodeParameters(req.URL.Query(), scope.MetaGroupVersion, &opts); err != nil {
-			err = errors.NewBadRequest(err.Error())
+		// watches may also be served as server-sent events
+		negotiateOutputMediaType := negotiation.NegotiateOutputMediaType
+		if opts.Watch || forceWatch {
+			negotiateOutputMediaType = negotiation.NegotiateOutputMediaTypeWatch
+		}
+		outputMediaType, _, err := negotiateOutputMediaType(req, scope.Serializer, scope)
+		if err != nil {
 			scope.err(err, w, req)
 			return
 		}
@@ -201,7 +212,7 @@ func ListResource(r rest.Lister, rw rest.Watcher, scope *RequestScope, forceWatc
 		// TODO: DecodeParametersInto should do this.
 		if opts.FieldSelector != nil {
 			fn := func(label, value string) (newLabel, newValue string, err error) {
-				return scope.Convertor.ConvertFieldLabel(scope.Kind, label, value)
+				return scope.convertFieldLabel(label, value)
 			}
 			if opts.FieldSelector, err = opts.FieldSelector.Transform(fn); err != nil {
 				// TODO: allow bad request to set field causes based on query parameters
@@ -247,9 +258,51 @@ func ListResource(r rest.Lister, rw rest.Watcher, scope *RequestScope, forceWatc
 			if timeout == 0 && minRequestTimeout > 0 {
 				timeout = time.Duration(float64(minRequestTimeout) * (rand.Float64() + 1.0))
 			}
-			klog.V(3).Infof("Starting watch for %s, rv=%s labels=%s fields=%s timeout=%s", req.URL.Path, opts.ResourceVersion, opts.LabelSelector, opts.FieldSelector, timeout)
+			var initialObjects []runtime.Object
+			var initialResourceVersion string
+			if outputMediaType.Accepted.MediaType == negotiation.EventStreamMediaType {
+				// browsers resume a server-sent event stream by sending the id of the last
+				// event they received, which is its resourceVersion, unless the request
+				// asks for an explicit one
+				if len(opts.ResourceVersion) == 0 {
+					opts.ResourceVersion = req.Header.Get("Last-Event-ID")
+				}
+				// The existing objects a watch starts with are not ordered by
+				// resourceVersion, so they are listed first and the watch starts from
+				// the resourceVersion of the list.
+				if opts.ResourceVersion == "" || opts.ResourceVersion == "0" {
+					if r == nil {
+						scope.err(errors.NewBadRequest("a resourceVersion is required to watch this resource as server-sent events"), w, req)
+						return
+					}
+					listOpts := opts
+					listOpts.Watch = false
+					listOpts.Limit = 0
+					listOpts.Continue = ""
+					list, err := r.List(ctx, &listOpts)
+					if err != nil {
+						scope.err(err, w, req)
+						return
+					}
+					if initialObjects, err = meta.ExtractList(list); err != nil {
+						scope.err(err, w, req)
+						return
+					}
+					listMeta, err := meta.ListAccessor(list)
+					if err != nil {
+						scope.err(err, w, req)
+						return
+					}
+					initialResourceVersion = listMeta.GetResourceVersion()
+					opts.ResourceVersion = initialResourceVersion
+				}
+			}
+			klog.V(3).Infof("Starting watch for %s, rv=%s labels=%s fields=%s annotations=%s timeout=%s", req.URL.Path, opts.ResourceVersion, opts.LabelSelector, opts.FieldSelector, opts.AnnotationSelector, timeout)
 			ctx, cancel := context.WithTimeout(ctx, timeout)
 			defer cancel()
+			// The events are only encoded by serveWatch, so the storage can
+			// send objects whose serializations are shared with other watchers.
+			ctx = request.WithCacheableObjects(ctx)
 			watcher, err := rw.Watch(ctx, &opts)
 			if err != nil {
 				scope.err(err, w, req)
@@ -257,7 +310,7 @@ func ListResource(r rest.Lister, rw rest.Watcher, scope *RequestScope, forceWatc
 			}
 			requestInfo, _ := request.RequestInfoFrom(ctx)
 			metrics.RecordLongRunning(req, requestInfo, metrics.APIServerComponent, func() {
-				serveWatch(watcher, scope, outputMediaType, req, w, timeout)
+				serveWatch(watcher, scope, outputMediaType, req, w, timeout, initialObjects, initialResourceVersion)
 			})
 			return
 		}
@@ -272,7 +325,13 @@ func ListResource(r rest.Lister, rw rest.Watcher, scope *RequestScope, forceWatc
 		}
 		trace.Step("Listing from storage done")
 
-		transformResponseObject(ctx, scope, trace, req, w, http.StatusOK, outputMediaType, result)
+		etag, written := checkNotModified(w, req, outputMediaType, result)
+		if written {
+			trace.Step("List not modified")
+			return
+		}
+
+		transformResponseObjectWithETag(ctx, scope, trace, req, w, http.StatusOK, outputMediaType, result, etag)
 		trace.Step(fmt.Sprintf("Writing http response done (%d items)", meta.LenList(result)))
 	}
 }
@@ -37,13 +37,52 @@ func MediaTypesForSerializer(ns runtime.NegotiatedSerializer) (mediaTypes, strea
 			streamMediaTypes = append(streamMediaTypes, info.MediaType+";stream=watch")
 		}
 	}
+	if _, ok := eventStreamSerializerInfo(ns); ok {
+		streamMediaTypes = append(streamMediaTypes, EventStreamMediaType)
+	}
 	return mediaTypes, streamMediaTypes
 }
 
+// EventStreamMediaType is the media type of watches served as server-sent events. The
+// objects of the events are encoded with the JSON serializer.
+const EventStreamMediaType = "text/event-stream"
+
+// eventStreamSerializerInfo returns the JSON serializer of ns under the event stream media
+// type, or false if ns cannot stream JSON.
+func eventStreamSerializerInfo(ns runtime.NegotiatedSerializer) (runtime.SerializerInfo, bool) {
+	info, ok := runtime.SerializerInfoForMediaType(ns.SupportedMediaTypes(), runtime.ContentTypeJSON)
+	if !ok || info.StreamSerializer == nil {
+		return runtime.SerializerInfo{}, false
+	}
+	info.MediaType = EventStreamMediaType
+	info.MediaTypeType = "text"
+	info.MediaTypeSubType = "event-stream"
+	return info, true
+}
+
+// watchMediaTypes returns the media types a watch can be served with.
+func watchMediaTypes(ns runtime.NegotiatedSerializer) []runtime.SerializerInfo {
+	mediaTypes := ns.SupportedMediaTypes()
+	if info, ok := eventStreamSerializerInfo(ns); ok {
+		mediaTypes = append(append([]runtime.SerializerInfo{}, mediaTypes...), info)
+	}
+	return mediaTypes
+}
+
 // NegotiateOutputMediaType negotiates the output structured media type and a serializer, or
 // returns an error.
 func NegotiateOutputMediaType(req *http.Request, ns runtime.NegotiatedSerializer, restrictions EndpointRestrictions) (MediaTypeOptions, runtime.SerializerInfo, error) {
-	mediaType, ok := NegotiateMediaTypeOptions(req.Header.Get("Accept"), ns.SupportedMediaTypes(), restrictions)
+	return negotiateOutputMediaType(req, ns, ns.SupportedMediaTypes(), restrictions)
+}
+
+// NegotiateOutputMediaTypeWatch negotiates the output media type of a watch, which in
+// addition to the media types of ns may be served as server-sent events.
+func NegotiateOutputMediaTypeWatch(req *http.Request, ns runtime.NegotiatedSerializer, restrictions EndpointRestrictions) (MediaTypeOptions, runtime.SerializerInfo, error) {
+	return negotiateOutputMediaType(req, ns, watchMediaTypes(ns), restrictions)
+}
+
+func negotiateOutputMediaType(req *http.Request, ns runtime.NegotiatedSerializer, accepted []runtime.SerializerInfo, restrictions EndpointRestrictions) (MediaTypeOptions, runtime.SerializerInfo, error) {
+	mediaType, ok := NegotiateMediaTypeOptions(req.Header.Get("Accept"), accepted, restrictions)
 	if !ok {
 		supported, _ := MediaTypesForSerializer(ns)
 		return mediaType, runtime.SerializerInfo{}, NewNotAcceptableError(supported)
@@ -56,9 +95,11 @@ func NegotiateOutputMediaType(req *http.Request, ns runtime.NegotiatedSerializer
 	return mediaType, info, nil
 }
 
-// NegotiateOutputMediaTypeStream returns a stream serializer for the given request.
+// NegotiateOutputMediaTypeStream returns a stream serializer for the given request. A request
+// for EventStreamMediaType is given the JSON serializer under that media type, and the caller
+// is expected to frame the events as server-sent events.
 func NegotiateOutputMediaTypeStream(req *http.Request, ns runtime.NegotiatedSerializer, restrictions EndpointRestrictions) (runtime.SerializerInfo, error) {
-	mediaType, ok := NegotiateMediaTypeOptions(req.Header.Get("Accept"), ns.SupportedMediaTypes(), restrictions)
+	mediaType, ok := NegotiateMediaTypeOptions(req.Header.Get("Accept"), watchMediaTypes(ns), restrictions)
 	if !ok || mediaType.Accepted.StreamSerializer == nil {
 		_, supported := MediaTypesForSerializer(ns)
 		return runtime.SerializerInfo{}, NewNotAcceptableError(supported)
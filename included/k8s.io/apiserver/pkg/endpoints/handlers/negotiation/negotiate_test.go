@@ -280,3 +280,54 @@ func TestNegotiate(t *testing.T) {
 		}
 	}
 }
+
+func TestNegotiateEventStream(t *testing.T) {
+	req := &http.Request{Header: http.Header{"Accept": []string{EventStreamMediaType}}}
+	ns := &fakeNegotiater{
+		serializer:       fakeCodec,
+		streamSerializer: fakeCodec,
+		types:            []string{"application/json", "application/vnd.kubernetes.protobuf"},
+		streamTypes:      []string{"application/json", "application/vnd.kubernetes.protobuf"},
+	}
+
+	info, err := NegotiateOutputMediaTypeStream(req, ns, DefaultEndpointRestrictions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.MediaType != EventStreamMediaType || info.MediaTypeType != "text" || info.MediaTypeSubType != "event-stream" {
+		t.Errorf("unexpected media type: %#v", info)
+	}
+	if info.Serializer != fakeCodec || info.StreamSerializer == nil {
+		t.Errorf("expected the JSON serializers, got %#v", info)
+	}
+
+	if _, _, err := NegotiateOutputMediaType(req, ns, DefaultEndpointRestrictions); err == nil {
+		t.Errorf("expected server-sent events to be refused outside of watches")
+	}
+	mediaType, _, err := NegotiateOutputMediaTypeWatch(req, ns, DefaultEndpointRestrictions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mediaType.Accepted.MediaType != EventStreamMediaType {
+		t.Errorf("unexpected media type for a watch: %#v", mediaType.Accepted)
+	}
+
+	_, streamMediaTypes := MediaTypesForSerializer(ns)
+	if last := streamMediaTypes[len(streamMediaTypes)-1]; last != EventStreamMediaType {
+		t.Errorf("expected %s to be a stream media type, got %v", EventStreamMediaType, streamMediaTypes)
+	}
+
+	// without a JSON stream serializer there is nothing to send as events
+	ns = &fakeNegotiater{
+		serializer:       fakeCodec,
+		streamSerializer: fakeCodec,
+		types:            []string{"application/json", "application/vnd.kubernetes.protobuf"},
+		streamTypes:      []string{"application/vnd.kubernetes.protobuf"},
+	}
+	if _, err := NegotiateOutputMediaTypeStream(req, ns, DefaultEndpointRestrictions); err == nil {
+		t.Errorf("expected server-sent events to be refused without a JSON stream serializer")
+	}
+	if _, streamMediaTypes := MediaTypesForSerializer(ns); len(streamMediaTypes) != 1 {
+		t.Errorf("expected only the protobuf stream media type, got %v", streamMediaTypes)
+	}
+}
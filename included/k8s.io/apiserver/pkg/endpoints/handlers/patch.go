@@ -120,6 +120,12 @@ func PatchResource(r rest.Patcher, scope *RequestScope, admit admission.Interfac
 		}
 		options.TypeMeta.SetGroupVersionKind(metav1.SchemeGroupVersion.WithKind("PatchOptions"))
 
+		ifMatch, hasIfMatch, err := ifMatchResourceVersions(req.Header.Get("If-Match"))
+		if err != nil {
+			scope.err(err, w, req)
+			return
+		}
+
 		ae := request.AuditEventFrom(ctx)
 		admit = admission.WithAudit(admit, ae)
 
@@ -215,6 +221,9 @@ func PatchResource(r rest.Patcher, scope *RequestScope, admit admission.Interfac
 
 			trace: trace,
 		}
+		if hasIfMatch {
+			p.precondition = ifMatchPrecondition(scope.Resource.GroupResource(), name, ifMatch)
+		}
 
 		result, wasCreated, err := p.patchResource(ctx, scope)
 		if err != nil {
@@ -284,6 +293,9 @@ type patcher struct {
 
 	trace *utiltrace.Trace
 
+	// precondition, if set, is checked against the persisted object before the patch is applied
+	precondition rest.TransformFunc
+
 	// Set at invocation-time (by applyPatch) and immutable thereafter
 	namespace         string
 	updatedObjectInfo rest.UpdatedObjectInfo
@@ -555,7 +567,11 @@ func (p *patcher) patchResource(ctx context.Context, scope *RequestScope) (runti
 	}
 
 	wasCreated := false
-	p.updatedObjectInfo = rest.DefaultUpdatedObjectInfo(nil, p.applyPatch, p.applyAdmission)
+	transformers := []rest.TransformFunc{p.applyPatch, p.applyAdmission}
+	if p.precondition != nil {
+		transformers = append([]rest.TransformFunc{p.precondition}, transformers...)
+	}
+	p.updatedObjectInfo = rest.DefaultUpdatedObjectInfo(nil, transformers...)
 	result, err := finishRequest(p.timeout, func() (runtime.Object, error) {
 		// Pass in UpdateOptions to override UpdateStrategy.AllowUpdateOnCreate
 		options := patchToUpdateOptions(p.options)
@@ -105,17 +105,37 @@ func targetEncodingForTransform(scope *RequestScope, mediaType negotiation.Media
 // transformResponseObject takes an object loaded from storage and performs any necessary transformations.
 // Will write the complete response object.
 func transformResponseObject(ctx context.Context, scope *RequestScope, trace *utiltrace.Trace, req *http.Request, w http.ResponseWriter, statusCode int, mediaType negotiation.MediaTypeOptions, result runtime.Object) {
+	transformResponseObjectWithETag(ctx, scope, trace, req, w, statusCode, mediaType, result, "")
+}
+
+// transformResponseObjectWithETag is transformResponseObject that sets the ETag header,
+// if etag is not empty, when the transformed object is written.
+func transformResponseObjectWithETag(ctx context.Context, scope *RequestScope, trace *utiltrace.Trace, req *http.Request, w http.ResponseWriter, statusCode int, mediaType negotiation.MediaTypeOptions, result runtime.Object, etag string) {
 	options, err := optionsForTransform(mediaType, req)
 	if err != nil {
 		scope.err(err, w, req)
 		return
 	}
+	fields, err := fieldsetForTransform(mediaType, req)
+	if err != nil {
+		scope.err(err, w, req)
+		return
+	}
 	obj, err := transformObject(ctx, result, options, mediaType, scope, req)
 	if err != nil {
 		scope.err(err, w, req)
 		return
 	}
+	if fields != nil {
+		if obj, err = fields.prune(obj, scope); err != nil {
+			scope.err(err, w, req)
+			return
+		}
+	}
 	kind, serializer, _ := targetEncodingForTransform(scope, mediaType, req)
+	if len(etag) > 0 {
+		w.Header().Set("ETag", etag)
+	}
 	responsewriters.WriteObjectNegotiated(serializer, scope, kind.GroupVersion(), w, req, statusCode, obj)
 }
 
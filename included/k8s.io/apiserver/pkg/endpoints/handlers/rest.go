@@ -33,6 +33,7 @@ import (
 	metav1beta1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1beta1"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime/schema"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/util/sets"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/admission"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/authorization/authorizer"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/endpoints/handlers/fieldmanager"
@@ -72,12 +73,25 @@ type RequestScope struct {
 	HubGroupVersion schema.GroupVersion
 
 	MaxRequestBodyBytes int64
+
+	// SelectableFields are the JSON paths that field selectors may use in
+	// addition to the field labels supported by the Convertor.
+	SelectableFields sets.String
 }
 
 func (scope *RequestScope) err(err error, w http.ResponseWriter, req *http.Request) {
 	responsewriters.ErrorNegotiated(err, scope.Serializer, scope.Kind.GroupVersion(), w, req)
 }
 
+// convertFieldLabel converts a field selector label and value of the requested kind
+// to their internal form. Selectable fields are passed through unchanged.
+func (scope *RequestScope) convertFieldLabel(label, value string) (string, string, error) {
+	if scope.SelectableFields.Has(label) {
+		return label, value, nil
+	}
+	return scope.Convertor.ConvertFieldLabel(scope.Kind, label, value)
+}
+
 func (scope *RequestScope) AllowsConversion(gvk schema.GroupVersionKind, mimeType, mimeSubType string) bool {
 	// TODO: this is temporary, replace with an abstraction calculated at endpoint installation time
 	if gvk.GroupVersion() == metav1beta1.SchemeGroupVersion || gvk.GroupVersion() == metav1.SchemeGroupVersion {
@@ -0,0 +1,44 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handlers
+
+import (
+	"testing"
+
+	v1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/core/v1"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/fields"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/util/sets"
+	clientgoscheme "github.com/aaron-prindle/krmapiserver/included/k8s.io/client-go/kubernetes/scheme"
+)
+
+func TestConvertFieldLabelSelectableFields(t *testing.T) {
+	scope := &RequestScope{
+		Convertor:        clientgoscheme.Scheme,
+		Kind:             v1.SchemeGroupVersion.WithKind("Pod"),
+		SelectableFields: sets.NewString("status.phase", "spec.activeDeadlineSeconds"),
+	}
+	selector, err := fields.ParseAndTransformSelector("metadata.name=foo,status.phase in (Pending,Running),spec.activeDeadlineSeconds>30", scope.convertFieldLabel)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "metadata.name=foo,spec.activeDeadlineSeconds>30,status.phase in (Pending,Running)"; selector.String() != expected {
+		t.Errorf("expected %s, got %s", expected, selector.String())
+	}
+	if _, err := fields.ParseAndTransformSelector("spec.nodeName=foo", scope.convertFieldLabel); err == nil {
+		t.Errorf("expected a field that is neither supported nor selectable to be rejected")
+	}
+}
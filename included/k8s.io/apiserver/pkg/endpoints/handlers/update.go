@@ -121,8 +121,17 @@ func UpdateResource(r rest.Updater, scope *RequestScope, admit admission.Interfa
 			return
 		}
 
+		ifMatch, hasIfMatch, err := ifMatchResourceVersions(req.Header.Get("If-Match"))
+		if err != nil {
+			scope.err(err, w, req)
+			return
+		}
+
 		userInfo, _ := request.UserFrom(ctx)
 		transformers := []rest.TransformFunc{}
+		if hasIfMatch {
+			transformers = append(transformers, ifMatchPrecondition(scope.Resource.GroupResource(), name, ifMatch))
+		}
 		if scope.FieldManager != nil {
 			transformers = append(transformers, func(_ context.Context, newObj, liveObj runtime.Object) (runtime.Object, error) {
 				obj, err := scope.FieldManager.Update(liveObj, newObj, managerOrUserAgent(options.FieldManager, req.UserAgent()))
@@ -19,11 +19,13 @@ package handlers
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"net/http"
 	"reflect"
 	"time"
 
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/api/errors"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/api/meta"
 	metav1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1"
 	metav1beta1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1beta1"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
@@ -61,14 +63,20 @@ func (w *realTimeoutFactory) TimeoutCh() (<-chan time.Time, func() bool) {
 	return t.C, t.Stop
 }
 
-// serveWatch will serve a watch response.
+// serveWatch will serve a watch response. Server-sent event streams start with
+// initialObjects, listed at initialResourceVersion, if any.
 // TODO: the functionality in this method and in WatchServer.Serve is not cleanly decoupled.
-func serveWatch(watcher watch.Interface, scope *RequestScope, mediaTypeOptions negotiation.MediaTypeOptions, req *http.Request, w http.ResponseWriter, timeout time.Duration) {
+func serveWatch(watcher watch.Interface, scope *RequestScope, mediaTypeOptions negotiation.MediaTypeOptions, req *http.Request, w http.ResponseWriter, timeout time.Duration, initialObjects []runtime.Object, initialResourceVersion string) {
 	options, err := optionsForTransform(mediaTypeOptions, req)
 	if err != nil {
 		scope.err(err, w, req)
 		return
 	}
+	fields, err := fieldsetForTransform(mediaTypeOptions, req)
+	if err != nil {
+		scope.err(err, w, req)
+		return
+	}
 
 	// negotiate for the stream serializer from the scope's serializer
 	serializer, err := negotiation.NegotiateOutputMediaTypeStream(req, scope.Serializer, scope)
@@ -86,17 +94,22 @@ func serveWatch(watcher watch.Interface, scope *RequestScope, mediaTypeOptions n
 	}
 	// TODO: next step, get back mediaTypeOptions from negotiate and return the exact value here
 	mediaType := serializer.MediaType
-	if mediaType != runtime.ContentTypeJSON {
+	if mediaType != runtime.ContentTypeJSON && mediaType != negotiation.EventStreamMediaType {
 		mediaType += ";stream=watch"
 	}
+	// server-sent events carry objects encoded as JSON
+	embeddedMediaType := serializer.MediaType
+	if embeddedMediaType == negotiation.EventStreamMediaType {
+		embeddedMediaType = runtime.ContentTypeJSON
+	}
 
 	// locate the appropriate embedded encoder based on the transform
 	var embeddedEncoder runtime.Encoder
 	contentKind, contentSerializer, transform := targetEncodingForTransform(scope, mediaTypeOptions, req)
 	if transform {
-		info, ok := runtime.SerializerInfoForMediaType(contentSerializer.SupportedMediaTypes(), serializer.MediaType)
+		info, ok := runtime.SerializerInfoForMediaType(contentSerializer.SupportedMediaTypes(), embeddedMediaType)
 		if !ok {
-			scope.err(fmt.Errorf("no encoder for %q exists in the requested target %#v", serializer.MediaType, contentSerializer), w, req)
+			scope.err(fmt.Errorf("no encoder for %q exists in the requested target %#v", embeddedMediaType, contentSerializer), w, req)
 			return
 		}
 		embeddedEncoder = contentSerializer.EncoderForVersion(info.Serializer, contentKind.GroupVersion())
@@ -104,6 +117,17 @@ func serveWatch(watcher watch.Interface, scope *RequestScope, mediaTypeOptions n
 		embeddedEncoder = scope.Serializer.EncoderForVersion(serializer.Serializer, contentKind.GroupVersion())
 	}
 
+	// Without a transform, the encoding of an object only depends on the media
+	// type, the target version, the resource and the sparse fieldset, so it can
+	// be shared with the watchers of the same resource.
+	var embeddedEncoderIdentifier runtime.Identifier
+	if !transform {
+		embeddedEncoderIdentifier = runtime.Identifier(fmt.Sprintf("%s;%s;%s", embeddedMediaType, contentKind.GroupVersion(), scope.Resource))
+		if fields != nil {
+			embeddedEncoderIdentifier += runtime.Identifier(";" + fieldsQueryParam + "=" + fields.String())
+		}
+	}
+
 	ctx := req.Context()
 
 	server := &WatchServer{
@@ -116,12 +140,22 @@ func serveWatch(watcher watch.Interface, scope *RequestScope, mediaTypeOptions n
 		Encoder:         encoder,
 		EmbeddedEncoder: embeddedEncoder,
 
+		EmbeddedEncoderIdentifier: embeddedEncoderIdentifier,
+
 		Fixup: func(obj runtime.Object) runtime.Object {
 			result, err := transformObject(ctx, obj, options, mediaTypeOptions, scope, req)
 			if err != nil {
 				utilruntime.HandleError(fmt.Errorf("failed to transform object %v: %v", reflect.TypeOf(obj), err))
 				return obj
 			}
+			if fields != nil {
+				pruned, err := fields.prune(result, scope)
+				if err != nil {
+					utilruntime.HandleError(fmt.Errorf("failed to prune object %v: %v", reflect.TypeOf(obj), err))
+					return result
+				}
+				result = pruned
+			}
 			// When we are transformed to a table, use the table options as the state for whether we
 			// should print headers - on watch, we only want to print table headers on the first object
 			// and omit them on subsequent events.
@@ -132,6 +166,9 @@ func serveWatch(watcher watch.Interface, scope *RequestScope, mediaTypeOptions n
 		},
 
 		TimeoutFactory: &realTimeoutFactory{timeout},
+
+		InitialObjects:         initialObjects,
+		InitialResourceVersion: initialResourceVersion,
 	}
 
 	server.ServeHTTP(w, req)
@@ -152,10 +189,18 @@ type WatchServer struct {
 	Encoder runtime.Encoder
 	// used to encode the nested object in the watch stream
 	EmbeddedEncoder runtime.Encoder
+	// identifies the result of EmbeddedEncoder and Fixup, so that it can be
+	// cached by runtime.CacheableObject events; empty if it must not be cached
+	EmbeddedEncoderIdentifier runtime.Identifier
 	// used to correct the object before we send it to the serializer
 	Fixup func(runtime.Object) runtime.Object
 
 	TimeoutFactory TimeoutFactory
+
+	// objects sent as ADDED events before the watch events of a server-sent
+	// event stream, and the resourceVersion they were listed at
+	InitialObjects         []runtime.Object
+	InitialResourceVersion string
 }
 
 // ServeHTTP serves a series of encoded events via HTTP with Transfer-Encoding: chunked
@@ -167,6 +212,11 @@ func (s *WatchServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 
 	w = httplog.Unlogged(w)
 
+	if s.MediaType == negotiation.EventStreamMediaType {
+		s.serveEventStream(w, req)
+		return
+	}
+
 	if wsstream.IsWebSocketRequest(req) {
 		w.Header().Set("Content-Type", s.MediaType)
 		websocket.Handler(s.HandleWS).ServeHTTP(w, req)
@@ -226,10 +276,9 @@ func (s *WatchServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 				return
 			}
 
-			obj := s.Fixup(event.Object)
-			if err := s.EmbeddedEncoder.Encode(obj, buf); err != nil {
+			if err := s.encodeEmbedded(event.Object, buf); err != nil {
 				// unexpected error
-				utilruntime.HandleError(fmt.Errorf("unable to encode watch object %T: %v", obj, err))
+				utilruntime.HandleError(fmt.Errorf("unable to encode watch object %T: %v", event.Object, err))
 				return
 			}
 
@@ -264,6 +313,167 @@ func (s *WatchServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	}
 }
 
+// serveEventStream serves the watch as server-sent events. Each event carries the
+// watch event encoded as JSON, and its id is the resourceVersion of the object, so that
+// browsers resume the watch by sending it as Last-Event-ID when they reconnect. Added,
+// modified and deleted objects are sent as message events, while bookmarks and errors
+// are sent as BOOKMARK and ERROR events. Errors carry no id, and clients receiving one
+// should close the stream and relist rather than let the browser resume it.
+//
+// The initial objects are not ordered by resourceVersion, so they are sent without ids,
+// followed by an event carrying only the id of the resourceVersion they were listed at,
+// which browsers record without dispatching it.
+func (s *WatchServer) serveEventStream(w http.ResponseWriter, req *http.Request) {
+	cn, ok := w.(http.CloseNotifier)
+	if !ok {
+		err := fmt.Errorf("unable to start watch - can't get http.CloseNotifier: %#v", w)
+		utilruntime.HandleError(err)
+		s.Scope.err(errors.NewInternalError(err), w, req)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		err := fmt.Errorf("unable to start watch - can't get http.Flusher: %#v", w)
+		utilruntime.HandleError(err)
+		s.Scope.err(errors.NewInternalError(err), w, req)
+		return
+	}
+
+	// ensure the connection times out
+	timeoutCh, cleanup := s.TimeoutFactory.TimeoutCh()
+	defer cleanup()
+	defer s.Watching.Stop()
+
+	// begin the stream
+	w.Header().Set("Content-Type", s.MediaType)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	var unknown runtime.Unknown
+	internalEvent := &metav1.InternalEvent{}
+	outEvent := &metav1.WatchEvent{}
+	buf := &bytes.Buffer{}
+	eventBuf := &bytes.Buffer{}
+	writeEvent := func(event watch.Event, id string) bool {
+		defer buf.Reset()
+		defer eventBuf.Reset()
+		if err := s.encodeEmbedded(event.Object, buf); err != nil {
+			// unexpected error
+			utilruntime.HandleError(fmt.Errorf("unable to encode watch object %T: %v", event.Object, err))
+			return false
+		}
+		unknown.Raw = buf.Bytes()
+		event.Object = &unknown
+
+		*outEvent = metav1.WatchEvent{}
+		*internalEvent = metav1.InternalEvent(event)
+		if err := metav1.Convert_v1_InternalEvent_To_v1_WatchEvent(internalEvent, outEvent, nil); err != nil {
+			utilruntime.HandleError(fmt.Errorf("unable to convert watch object: %v", err))
+			// client disconnect.
+			return false
+		}
+		if err := s.Encoder.Encode(outEvent, eventBuf); err != nil {
+			utilruntime.HandleError(fmt.Errorf("unable to encode watch object %T: %v", outEvent, err))
+			// client disconnect.
+			return false
+		}
+		if _, err := w.Write(eventStreamEvent(event.Type, id, eventBuf.Bytes())); err != nil {
+			// client disconnect.
+			return false
+		}
+		return true
+	}
+
+	for _, obj := range s.InitialObjects {
+		if !writeEvent(watch.Event{Type: watch.Added, Object: obj}, "") {
+			return
+		}
+	}
+	if len(s.InitialResourceVersion) > 0 {
+		if _, err := fmt.Fprintf(w, "id: %s\n\n", s.InitialResourceVersion); err != nil {
+			// client disconnect.
+			return
+		}
+	}
+	flusher.Flush()
+
+	ch := s.Watching.ResultChan()
+	for {
+		select {
+		case <-cn.CloseNotify():
+			return
+		case <-timeoutCh:
+			return
+		case event, ok := <-ch:
+			if !ok {
+				// End of results.
+				return
+			}
+
+			var id string
+			if event.Type != watch.Error {
+				id = eventResourceVersion(event.Object)
+			}
+
+			if !writeEvent(event, id) {
+				return
+			}
+			if len(ch) == 0 {
+				flusher.Flush()
+			}
+		}
+	}
+}
+
+// eventResourceVersion returns the resourceVersion of the object of a watch event
+// without copying objects that are shared with other watchers.
+func eventResourceVersion(obj runtime.Object) string {
+	if o, ok := obj.(interface{ GetResourceVersion() string }); ok {
+		return o.GetResourceVersion()
+	}
+	if co, ok := obj.(runtime.CacheableObject); ok {
+		obj = co.GetObject()
+	}
+	resourceVersion, _ := meta.NewAccessor().ResourceVersion(obj)
+	return resourceVersion
+}
+
+// eventStreamEvent frames data as a server-sent event of the type matching a watch event
+// type. Lines of data are sent as separate data fields, which clients join again.
+func eventStreamEvent(eventType watch.EventType, id string, data []byte) []byte {
+	out := &bytes.Buffer{}
+	switch eventType {
+	case watch.Bookmark, watch.Error:
+		fmt.Fprintf(out, "event: %s\n", eventType)
+	}
+	if len(id) > 0 {
+		fmt.Fprintf(out, "id: %s\n", id)
+	}
+	for _, line := range bytes.Split(bytes.TrimRight(data, "\n"), []byte("\n")) {
+		out.WriteString("data: ")
+		out.Write(line)
+		out.WriteString("\n")
+	}
+	out.WriteString("\n")
+	return out.Bytes()
+}
+
+// encodeEmbedded encodes the object of a watch event to w. Objects that are
+// shared with other watchers are encoded once per EmbeddedEncoderIdentifier.
+func (s *WatchServer) encodeEmbedded(obj runtime.Object, w io.Writer) error {
+	if co, ok := obj.(runtime.CacheableObject); ok {
+		if len(s.EmbeddedEncoderIdentifier) == 0 {
+			obj = co.GetObject()
+		} else {
+			return co.CacheEncode(s.EmbeddedEncoderIdentifier, func(obj runtime.Object, w io.Writer) error {
+				return s.EmbeddedEncoder.Encode(s.Fixup(obj), w)
+			}, w)
+		}
+	}
+	return s.EmbeddedEncoder.Encode(s.Fixup(obj), w)
+}
+
 // HandleWS implements a websocket handler.
 func (s *WatchServer) HandleWS(ws *websocket.Conn) {
 	defer ws.Close()
@@ -293,10 +503,9 @@ func (s *WatchServer) HandleWS(ws *websocket.Conn) {
 				// End of results.
 				return
 			}
-			obj := s.Fixup(event.Object)
-			if err := s.EmbeddedEncoder.Encode(obj, buf); err != nil {
+			if err := s.encodeEmbedded(event.Object, buf); err != nil {
 				// unexpected error
-				utilruntime.HandleError(fmt.Errorf("unable to encode watch object %T: %v", obj, err))
+				utilruntime.HandleError(fmt.Errorf("unable to encode watch object %T: %v", event.Object, err))
 				return
 			}
 
@@ -0,0 +1,261 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/core/v1"
+	metainternalversion "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/util/wait"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/watch"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/endpoints/request"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/registry/rest"
+	clientgoscheme "github.com/aaron-prindle/krmapiserver/included/k8s.io/client-go/kubernetes/scheme"
+)
+
+type eventStreamTestWatcher struct {
+	watcher         *watch.FakeWatcher
+	resourceVersion chan string
+}
+
+func (w *eventStreamTestWatcher) Watch(ctx context.Context, options *metainternalversion.ListOptions) (watch.Interface, error) {
+	w.resourceVersion <- options.ResourceVersion
+	return w.watcher, nil
+}
+
+type sseEvent struct {
+	event string
+	id    string
+	data  string
+}
+
+// readEventStreamEvent reads the next server-sent event, joining its data lines.
+func readEventStreamEvent(r *bufio.Reader) (sseEvent, error) {
+	var event sseEvent
+	var data []string
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return event, err
+		}
+		line = strings.TrimSuffix(line, "\n")
+		if len(line) == 0 {
+			event.data = strings.Join(data, "\n")
+			return event, nil
+		}
+		field := strings.SplitN(line, ": ", 2)
+		switch field[0] {
+		case "event":
+			event.event = field[1]
+		case "id":
+			event.id = field[1]
+		case "data":
+			data = append(data, field[1])
+		}
+	}
+}
+
+type eventStreamTestLister struct {
+	list *v1.PodList
+}
+
+func (l *eventStreamTestLister) NewList() runtime.Object {
+	return &v1.PodList{}
+}
+
+func (l *eventStreamTestLister) List(ctx context.Context, options *metainternalversion.ListOptions) (runtime.Object, error) {
+	return l.list, nil
+}
+
+// watchEventStream starts a watch served as server-sent events and returns the
+// response along with the resourceVersion the storage was watched from.
+func watchEventStream(t *testing.T, lister rest.Lister, watcher *eventStreamTestWatcher, query, lastEventID string) (*http.Response, string, func()) {
+	scope := newETagTestScope()
+	scope.Convertor = clientgoscheme.Scheme
+	handler := ListResource(lister, watcher, scope, false, 0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		req = req.WithContext(request.WithRequestInfo(req.Context(), &request.RequestInfo{
+			IsResourceRequest: true,
+			Verb:              "watch",
+			APIGroup:          v1.GroupName,
+			APIVersion:        "v1",
+			Namespace:         "default",
+			Resource:          "pods",
+		}))
+		handler.ServeHTTP(w, req)
+	}))
+
+	req, err := http.NewRequest("GET", server.URL+"/api/v1/namespaces/default/pods?watch=true"+query, nil)
+	if err != nil {
+		server.Close()
+		t.Fatal(err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if len(lastEventID) > 0 {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		server.Close()
+		t.Fatal(err)
+	}
+	cleanup := func() {
+		resp.Body.Close()
+		server.Close()
+	}
+	if resp.StatusCode != http.StatusOK {
+		cleanup()
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	if contentType := resp.Header.Get("Content-Type"); contentType != "text/event-stream" {
+		t.Errorf("unexpected content type %s", contentType)
+	}
+	select {
+	case rv := <-watcher.resourceVersion:
+		return resp, rv, cleanup
+	case <-time.After(wait.ForeverTestTimeout):
+		cleanup()
+		t.Fatal("timed out waiting for the watch")
+	}
+	return nil, "", nil
+}
+
+type expectedSSEEvent struct {
+	event, id, eventType string
+}
+
+func expectEventStreamEvents(t *testing.T, reader *bufio.Reader, expected []expectedSSEEvent) {
+	for _, e := range expected {
+		done := make(chan struct{})
+		var event sseEvent
+		var err error
+		go func() {
+			defer close(done)
+			event, err = readEventStreamEvent(reader)
+		}()
+		select {
+		case <-done:
+		case <-time.After(wait.ForeverTestTimeout):
+			t.Fatal("timed out waiting for an event")
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if event.event != e.event || event.id != e.id {
+			t.Errorf("expected event %q with id %q, got %#v", e.event, e.id, event)
+		}
+		if len(e.eventType) == 0 {
+			if len(event.data) != 0 {
+				t.Errorf("expected an event without data, got %#v", event)
+			}
+			continue
+		}
+		watchEvent := metav1.WatchEvent{}
+		if err := json.Unmarshal([]byte(event.data), &watchEvent); err != nil {
+			t.Fatalf("unable to decode %q: %v", event.data, err)
+		}
+		if watchEvent.Type != e.eventType {
+			t.Errorf("expected a %s watch event, got %s", e.eventType, watchEvent.Type)
+		}
+	}
+}
+
+func TestWatchEventStream(t *testing.T) {
+	watcher := &eventStreamTestWatcher{watcher: watch.NewFake(), resourceVersion: make(chan string, 1)}
+	resp, rv, cleanup := watchEventStream(t, nil, watcher, "", "10")
+	defer cleanup()
+	if rv != "10" {
+		t.Errorf("expected the watch to resume from Last-Event-ID 10, got %q", rv)
+	}
+
+	go func() {
+		watcher.watcher.Add(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "foo", ResourceVersion: "11"}})
+		watcher.watcher.Action(watch.Bookmark, &v1.Pod{ObjectMeta: metav1.ObjectMeta{ResourceVersion: "12"}})
+		watcher.watcher.Error(&metav1.Status{Status: metav1.StatusFailure, Code: http.StatusGone, Reason: metav1.StatusReasonExpired})
+	}()
+
+	expectEventStreamEvents(t, bufio.NewReader(resp.Body), []expectedSSEEvent{
+		{id: "11", eventType: "ADDED"},
+		{event: "BOOKMARK", id: "12", eventType: "BOOKMARK"},
+		{event: "ERROR", eventType: "ERROR"},
+	})
+}
+
+func TestWatchEventStreamResourceVersion(t *testing.T) {
+	watcher := &eventStreamTestWatcher{watcher: watch.NewFake(), resourceVersion: make(chan string, 1)}
+	_, rv, cleanup := watchEventStream(t, nil, watcher, "&resourceVersion=5", "10")
+	defer cleanup()
+	if rv != "5" {
+		t.Errorf("expected the watch to start from the requested resourceVersion 5, got %q", rv)
+	}
+}
+
+func TestWatchEventStreamInitialObjects(t *testing.T) {
+	lister := &eventStreamTestLister{list: &v1.PodList{
+		ListMeta: metav1.ListMeta{ResourceVersion: "20"},
+		Items: []v1.Pod{
+			{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "bar", ResourceVersion: "15"}},
+			{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "foo", ResourceVersion: "8"}},
+		},
+	}}
+	watcher := &eventStreamTestWatcher{watcher: watch.NewFake(), resourceVersion: make(chan string, 1)}
+	resp, rv, cleanup := watchEventStream(t, lister, watcher, "", "")
+	defer cleanup()
+	if rv != "20" {
+		t.Errorf("expected the watch to start from the list resourceVersion 20, got %q", rv)
+	}
+
+	go watcher.watcher.Modify(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "foo", ResourceVersion: "21"}})
+
+	// the listed objects carry no id, so that a browser reconnecting in the middle
+	// of them lists again rather than resuming from an unordered resourceVersion
+	expectEventStreamEvents(t, bufio.NewReader(resp.Body), []expectedSSEEvent{
+		{eventType: "ADDED"},
+		{eventType: "ADDED"},
+		{id: "20"},
+		{id: "21", eventType: "MODIFIED"},
+	})
+}
+
+func TestEventStreamEvent(t *testing.T) {
+	tests := []struct {
+		eventType watch.EventType
+		id        string
+		data      string
+		expected  string
+	}{
+		{eventType: watch.Added, id: "1", data: "{}\n", expected: "id: 1\ndata: {}\n\n"},
+		{eventType: watch.Deleted, id: "2", data: "{\n}", expected: "id: 2\ndata: {\ndata: }\n\n"},
+		{eventType: watch.Bookmark, id: "3", data: "{}", expected: "event: BOOKMARK\nid: 3\ndata: {}\n\n"},
+		{eventType: watch.Error, data: "{}", expected: "event: ERROR\ndata: {}\n\n"},
+	}
+	for _, test := range tests {
+		if actual := string(eventStreamEvent(test.eventType, test.id, []byte(test.data))); actual != test.expected {
+			t.Errorf("%s: expected %q, got %q", test.eventType, test.expected, actual)
+		}
+	}
+}
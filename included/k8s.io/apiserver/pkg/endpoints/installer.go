@@ -32,6 +32,7 @@ import (
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime/schema"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/types"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/util/sets"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/admission"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/endpoints/discovery"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/endpoints/handlers"
@@ -551,6 +552,9 @@ func (a *APIInstaller) registerResourceHandlers(path string, storage rest.Storag
 	if a.group.MetaGroupVersion != nil {
 		reqScope.MetaGroupVersion = *a.group.MetaGroupVersion
 	}
+	if selectableFieldsProvider, ok := storage.(rest.SelectableFieldsProvider); ok {
+		reqScope.SelectableFields = sets.NewString(selectableFieldsProvider.SelectableFields()...)
+	}
 	if a.group.OpenAPIModels != nil && utilfeature.DefaultFeatureGate.Enabled(features.ServerSideApply) {
 		fm, err := fieldmanager.NewFieldManager(
 			a.group.OpenAPIModels,
@@ -930,6 +934,18 @@ func (a *APIInstaller) registerResourceHandlers(path string, storage rest.Storag
 	// Record the existence of the GVR and the corresponding GVK
 	a.group.EquivalentResourceRegistry.RegisterKindFor(reqScope.Resource, reqScope.Subresource, fqKindToRegister)
 
+	// Record the storage, so that other resources can act on its objects
+	if a.group.StorageRegistry != nil && len(subresource) == 0 {
+		a.group.StorageRegistry.Register(reqScope.Resource, &rest.RegisteredStorage{
+			Storage:          storage,
+			Kind:             fqKindToRegister,
+			NamespaceScoped:  namespaceScoped,
+			Serializer:       a.group.Serializer,
+			HubGroupVersion:  reqScope.HubGroupVersion,
+			ObjectInterfaces: &reqScope,
+		})
+	}
+
 	return &apiResource, nil
 }
 
@@ -39,6 +39,10 @@ const (
 
 	// audiencesKey is the context key for request audiences.
 	audiencesKey
+
+	// cacheableObjectsKey is the context key for whether the caller accepts
+	// watch events with cacheable objects.
+	cacheableObjectsKey
 )
 
 // NewContext instantiates a base context object for request flows.
@@ -94,3 +98,17 @@ func AuditEventFrom(ctx context.Context) *audit.Event {
 	ev, _ := ctx.Value(auditKey).(*audit.Event)
 	return ev
 }
+
+// WithCacheableObjects returns a copy of parent in which the caller declares
+// that it handles watch events whose objects implement runtime.CacheableObject,
+// which allows storage to share the serializations of an object among watches.
+func WithCacheableObjects(parent context.Context) context.Context {
+	return WithValue(parent, cacheableObjectsKey, true)
+}
+
+// CacheableObjectsFrom returns true if the caller accepts watch events whose
+// objects implement runtime.CacheableObject.
+func CacheableObjectsFrom(ctx context.Context) bool {
+	accepted, _ := ctx.Value(cacheableObjectsKey).(bool)
+	return accepted
+}
@@ -91,3 +91,15 @@ func TestUserContext(t *testing.T) {
 	}
 
 }
+
+// TestCacheableObjectsContext validates that accepting cacheable objects can be set on a context object
+func TestCacheableObjectsContext(t *testing.T) {
+	ctx := NewContext()
+	if CacheableObjectsFrom(ctx) {
+		t.Fatalf("Should not accept cacheable objects without setting it on the context")
+	}
+	ctx = WithCacheableObjects(ctx)
+	if !CacheableObjectsFrom(ctx) {
+		t.Fatalf("Expected cacheable objects to be accepted")
+	}
+}
@@ -123,6 +123,20 @@ const (
 	//
 	// Enables managing request concurrency with prioritization and fairness at each server
 	RequestManagement featuregate.Feature = "RequestManagement"
+
+	// owner: @aaron-prindle
+	// alpha: v1.16
+	//
+	// Allows the watch cache to serve lists without a resourceVersion once it
+	// has caught up with the current revision of etcd.
+	ConsistentListFromCache featuregate.Feature = "ConsistentListFromCache"
+
+	// owner: @aaron-prindle
+	// alpha: v1.16
+	//
+	// Allows the watch cache to serve paginated lists, with continue tokens
+	// that are compatible with the etcd3 storage.
+	PaginatedListFromCache featuregate.Feature = "PaginatedListFromCache"
 )
 
 func init() {
@@ -146,4 +160,6 @@ var defaultKubernetesFeatureGates = map[featuregate.Feature]featuregate.FeatureS
 	WinDSR:                  {Default: false, PreRelease: featuregate.Alpha},
 	WatchBookmark:           {Default: false, PreRelease: featuregate.Alpha},
 	RequestManagement:       {Default: false, PreRelease: featuregate.Alpha},
+	ConsistentListFromCache: {Default: false, PreRelease: featuregate.Alpha},
+	PaginatedListFromCache:  {Default: false, PreRelease: featuregate.Alpha},
 }
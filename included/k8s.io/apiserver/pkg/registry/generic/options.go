@@ -22,6 +22,7 @@ import (
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime/schema"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/storagebackend"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/client-go/tools/cache"
 )
 
 // RESTOptions is set of configuration options to generic registries.
@@ -33,6 +34,41 @@ type RESTOptions struct {
 	DeleteCollectionWorkers int
 	ResourcePrefix          string
 	CountMetricPollPeriod   time.Duration
+	History                 HistoryOptions
+	Trash                   TrashOptions
+	// SelectableFields are the JSON paths of the objects of the resource,
+	// e.g. spec.replicas, that field selectors may use in addition to the
+	// fields of its AttrFunc.
+	SelectableFields []string
+}
+
+// HistoryOptions configure the history of the objects of a resource, which
+// keeps the revisions of the objects replaced by their updates and deletions.
+// The history is not kept if both Limit and Window are 0.
+type HistoryOptions struct {
+	// Limit is the number of revisions kept per object, if not 0.
+	Limit int
+	// Window is how long revisions are kept after they are replaced, if not
+	// 0.
+	Window time.Duration
+}
+
+// Enabled returns whether the history is kept.
+func (o HistoryOptions) Enabled() bool {
+	return o.Limit > 0 || o.Window > 0
+}
+
+// TrashOptions configure the trash of a resource, which keeps its deleted
+// objects so that they can be restored. The trash is not kept if Retention is
+// 0.
+type TrashOptions struct {
+	// Retention is how long deleted objects are kept before they are purged.
+	Retention time.Duration
+}
+
+// Enabled returns whether the trash is kept.
+func (o TrashOptions) Enabled() bool {
+	return o.Retention > 0
 }
 
 // Implement RESTOptionsGetter so that RESTOptions can directly be used when available (i.e. tests)
@@ -47,6 +83,14 @@ type RESTOptionsGetter interface {
 // StoreOptions is set of configuration options used to complete generic registries.
 type StoreOptions struct {
 	RESTOptions RESTOptionsGetter
+	// TriggerFunc is used for the indexes of the watch cache if Indexers is
+	// not set.
+	//
+	// Deprecated: set Indexers instead.
 	TriggerFunc storage.TriggerPublisherFunc
 	AttrFunc    storage.AttrFunc
+	// Indexers are the indexes of the objects that the watch cache, if
+	// enabled, maintains to serve lists and watches; see storage.FieldIndex,
+	// storage.LabelIndex and storage.NamespaceIndex for their names.
+	Indexers *cache.Indexers
 }
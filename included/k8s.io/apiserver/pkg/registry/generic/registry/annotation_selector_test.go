@@ -0,0 +1,90 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/core/v1"
+	metainternalversion "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/labels"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/util/wait"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/watch"
+	genericapirequest "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/endpoints/request"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/registry/generic"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/registry/rest"
+)
+
+func TestStoreListAndWatchAnnotationSelector(t *testing.T) {
+	decorators := map[string]generic.StorageDecorator{
+		"etcd":   generic.UndecoratedStorage,
+		"cacher": StorageWithCacher(10),
+	}
+	for name, decorator := range decorators {
+		t.Run(name, func(t *testing.T) {
+			ctx := genericapirequest.WithNamespace(genericapirequest.NewContext(), "test")
+			registry := newTestPodStore(t, decorator)
+			defer registry.DestroyFunc()
+
+			var resourceVersion string
+			for _, pod := range []struct{ name, owner string }{{"foo", "team-a"}, {"bar", "team-b"}} {
+				obj, err := registry.Create(ctx, &v1.Pod{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: pod.name, Annotations: map[string]string{"owner": pod.owner}},
+					Spec:       v1.PodSpec{NodeName: "machine"},
+				}, rest.ValidateAllObjectFunc, &metav1.CreateOptions{})
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				resourceVersion = obj.(*v1.Pod).ResourceVersion
+			}
+
+			selector := labels.SelectorFromSet(labels.Set{"owner": "team-a"})
+			list, err := registry.List(ctx, &metainternalversion.ListOptions{AnnotationSelector: selector, ResourceVersion: resourceVersion})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if items := list.(*v1.PodList).Items; len(items) != 1 || items[0].Name != "foo" {
+				t.Errorf("Expected only foo to be listed, got %#v", items)
+			}
+
+			w, err := registry.Watch(ctx, &metainternalversion.ListOptions{AnnotationSelector: selector, ResourceVersion: resourceVersion})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			defer w.Stop()
+			obj, err := registry.Get(ctx, "bar", &metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			bar := obj.(*v1.Pod)
+			bar.Annotations["owner"] = "team-a"
+			if _, _, err := registry.Update(ctx, bar.Name, rest.DefaultUpdatedObjectInfo(bar), rest.ValidateAllObjectFunc, rest.ValidateAllObjectUpdateFunc, false, &metav1.UpdateOptions{}); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			select {
+			case event := <-w.ResultChan():
+				if event.Type != watch.Added || event.Object.(*v1.Pod).Name != "bar" {
+					t.Errorf("Expected bar to be added to the watch, got %v %#v", event.Type, event.Object)
+				}
+			case <-time.After(wait.ForeverTestTimeout):
+				t.Fatalf("timeout waiting for the watch event")
+			}
+		})
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"net/http"
 
 	metav1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/watch"
 )
 
@@ -56,6 +57,11 @@ func (d *decoratedWatcher) run(ctx context.Context) {
 			}
 			switch recv.Type {
 			case watch.Added, watch.Modified, watch.Deleted, watch.Bookmark:
+				// The decorator modifies the object, so objects shared with
+				// other watchers have to be copied first.
+				if co, ok := recv.Object.(runtime.CacheableObject); ok {
+					recv.Object = co.GetObject()
+				}
 				err := d.decorator(recv.Object)
 				if err != nil {
 					send = makeStatusErrorEvent(err)
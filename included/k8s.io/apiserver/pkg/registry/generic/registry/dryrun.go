@@ -18,6 +18,8 @@ package registry
 
 import (
 	"context"
+	"fmt"
+	"reflect"
 
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/watch"
@@ -104,6 +106,54 @@ func (s *DryRunnableStorage) Count(key string) (int64, error) {
 	return s.Storage.Count(key)
 }
 
+func (s *DryRunnableStorage) Transact(ctx context.Context, ops []storage.TxnOp, dryRun bool) error {
+	if dryRun {
+		for i, op := range ops {
+			if err := s.checkTxnOp(ctx, op); err != nil {
+				return storage.NewTxnOpError(i, err)
+			}
+		}
+		return nil
+	}
+	return s.Storage.Transact(ctx, ops)
+}
+
+// checkTxnOp checks op against the current object at its key without
+// changing it, and sets op.Out as if op had been committed.
+func (s *DryRunnableStorage) checkTxnOp(ctx context.Context, op storage.TxnOp) error {
+	opStorage := op.Storage
+	if opStorage == nil {
+		opStorage = s.Storage
+	}
+	if op.Type == storage.TxnCreate {
+		if err := opStorage.Get(ctx, op.Key, "", op.Out, false); err == nil {
+			return storage.NewKeyExistsError(op.Key, 0)
+		}
+		return setInto(op.Obj, op.Out)
+	}
+	if err := opStorage.Get(ctx, op.Key, "", op.Out, false); err != nil {
+		return err
+	}
+	if err := op.Preconditions.Check(op.Key, op.Out); err != nil {
+		return err
+	}
+	if op.Type == storage.TxnUpdate {
+		return setInto(op.Obj, op.Out)
+	}
+	return nil
+}
+
+// setInto sets out, which must be a pointer to an object of the type of in, to
+// a copy of in. Unlike copyInto it doesn't need a codec for the type.
+func setInto(in, out runtime.Object) error {
+	inValue, outValue := reflect.ValueOf(in.DeepCopyObject()), reflect.ValueOf(out)
+	if inValue.Type() != outValue.Type() || outValue.Kind() != reflect.Ptr {
+		return fmt.Errorf("cannot set %T into %T", in, out)
+	}
+	outValue.Elem().Set(inValue.Elem())
+	return nil
+}
+
 func (s *DryRunnableStorage) copyInto(in, out runtime.Object) error {
 	var data []byte
 
@@ -0,0 +1,347 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"path"
+	"sort"
+	"strconv"
+	"time"
+
+	historyv1alpha1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/history/v1alpha1"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/api/meta"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/types"
+	utilruntime "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/util/runtime"
+	genericapirequest "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/endpoints/request"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/registry/generic"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/registry/rest"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/storagebackend"
+)
+
+const (
+	// historyKeyPrefix is prepended to the keys of the objects to get the
+	// keys their revisions are stored under.
+	historyKeyPrefix = "/history"
+
+	// The metadata of a revision is kept in annotations of the stored
+	// revision, which are removed when it is read.
+	revisionResourceVersionAnnotation = "history.k8s.io/resource-version"
+	revisionOperationAnnotation       = "history.k8s.io/operation"
+	revisionUserAnnotation            = "history.k8s.io/user"
+	revisionTimestampAnnotation       = "history.k8s.io/timestamp"
+)
+
+// history keeps the revisions of the objects of a store as they are written,
+// with their authors, and retains them once they are replaced by updates and
+// deletions. Revisions are stored as objects of the resource, with the codec
+// and transformer of the resource, under the key of their object prefixed by
+// historyKeyPrefix and followed by their resourceVersion.
+type history struct {
+	storage     storage.Interface
+	options     generic.HistoryOptions
+	newFunc     func() runtime.Object
+	newListFunc func() runtime.Object
+}
+
+// newHistory returns the history of the objects stored with config under
+// resourcePrefix.
+func newHistory(config storagebackend.Config, resourcePrefix string, options generic.HistoryOptions, newFunc, newListFunc func() runtime.Object) (*history, func()) {
+	config.ResourcePrefix = historyKeyPrefix + resourcePrefix
+	// The revisions are not accounted as objects of the resource.
+	config.CountMetricPollPeriod = 0
+	config.NewFunc = newFunc
+	s, destroy := generic.NewRawStorage(&config)
+	return &history{
+		storage:     s,
+		options:     options,
+		newFunc:     newFunc,
+		newListFunc: newListFunc,
+	}, destroy
+}
+
+func historyKey(key string) string {
+	return historyKeyPrefix + key
+}
+
+func revisionKey(key string, resourceVersion uint64) string {
+	// The resourceVersion is padded for the revisions to be listed in order.
+	return fmt.Sprintf("%s/%020d", historyKey(key), resourceVersion)
+}
+
+// write stores obj, the object at key as written by the user of ctx, as its
+// current revision. The revision is retained once it is replaced.
+func (h *history) write(ctx context.Context, key string, obj runtime.Object) error {
+	revision := obj.DeepCopyObject()
+	resourceVersion, err := h.storage.Versioner().ObjectResourceVersion(revision)
+	if err != nil {
+		return err
+	}
+	accessor, err := meta.Accessor(revision)
+	if err != nil {
+		return err
+	}
+	annotations := make(map[string]string, len(accessor.GetAnnotations())+4)
+	for k, v := range accessor.GetAnnotations() {
+		annotations[k] = v
+	}
+	annotations[revisionResourceVersionAnnotation] = strconv.FormatUint(resourceVersion, 10)
+	if user, ok := genericapirequest.UserFrom(ctx); ok {
+		annotations[revisionUserAnnotation] = user.GetName()
+	}
+	accessor.SetAnnotations(annotations)
+	if err := h.storage.Versioner().PrepareObjectForStorage(revision); err != nil {
+		return err
+	}
+	err = h.storage.Create(ctx, revisionKey(key, resourceVersion), revision, h.newFunc(), 0)
+	if err != nil && !storage.IsNodeExist(err) {
+		return err
+	}
+	return nil
+}
+
+// replace records that obj, a revision of the object at key, was replaced by
+// op, and retains it for the window of the history. If obj was not written
+// to the history, e.g. because the history was enabled later, it is recorded
+// without its author.
+func (h *history) replace(ctx context.Context, key string, obj runtime.Object, op historyv1alpha1.RevisionOperation) error {
+	resourceVersion, err := h.storage.Versioner().ObjectResourceVersion(obj)
+	if err != nil {
+		return err
+	}
+	if err := h.markReplaced(ctx, key, resourceVersion, obj, op); err != nil {
+		return err
+	}
+	if h.options.Limit > 0 {
+		return h.trim(ctx, key, resourceVersion)
+	}
+	return nil
+}
+
+// markReplaced records that the revision of the object at key with the given
+// resourceVersion was replaced by op. If the revision is not stored, obj is
+// stored instead, unless nil.
+func (h *history) markReplaced(ctx context.Context, key string, resourceVersion uint64, obj runtime.Object, op historyv1alpha1.RevisionOperation) error {
+	ttl := uint64(math.Ceil(h.options.Window.Seconds()))
+	err := h.storage.GuaranteedUpdate(ctx, revisionKey(key, resourceVersion), h.newFunc(), obj != nil, nil,
+		func(existing runtime.Object, res storage.ResponseMeta) (runtime.Object, *uint64, error) {
+			revision := existing
+			if res.ResourceVersion == 0 {
+				revision = obj.DeepCopyObject()
+			}
+			accessor, err := meta.Accessor(revision)
+			if err != nil {
+				return nil, nil, err
+			}
+			annotations := make(map[string]string, len(accessor.GetAnnotations())+4)
+			for k, v := range accessor.GetAnnotations() {
+				annotations[k] = v
+			}
+			annotations[revisionResourceVersionAnnotation] = strconv.FormatUint(resourceVersion, 10)
+			annotations[revisionOperationAnnotation] = string(op)
+			annotations[revisionTimestampAnnotation] = time.Now().UTC().Format(time.RFC3339)
+			accessor.SetAnnotations(annotations)
+			return revision, &ttl, nil
+		})
+	if storage.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// trim deletes the oldest revisions of the object at key beyond the limit
+// among the ones replaced up to the revision with the given resourceVersion,
+// which are all its revisions but the current one.
+func (h *history) trim(ctx context.Context, key string, resourceVersion uint64) error {
+	resourceVersions, err := h.resourceVersions(ctx, key)
+	if err != nil {
+		return err
+	}
+	var replaced []uint64
+	for _, rv := range resourceVersions {
+		if rv <= resourceVersion {
+			replaced = append(replaced, rv)
+		}
+	}
+	for len(replaced) > h.options.Limit {
+		err := h.storage.Delete(ctx, revisionKey(key, replaced[0]), h.newFunc(), nil, rest.ValidateAllObjectFunc)
+		if err != nil && !storage.IsNotFound(err) {
+			return err
+		}
+		replaced = replaced[1:]
+	}
+	return nil
+}
+
+// resourceVersions returns the resourceVersions of the revisions of the object
+// at key, oldest first. If the storage is a storage.KeyLister, they are parsed
+// from the keys of the revisions without reading the revisions.
+func (h *history) resourceVersions(ctx context.Context, key string) ([]uint64, error) {
+	lister, ok := h.storage.(storage.KeyLister)
+	if !ok {
+		revisions, err := h.list(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		resourceVersions := make([]uint64, 0, len(revisions))
+		for _, r := range revisions {
+			resourceVersions = append(resourceVersions, r.resourceVersion)
+		}
+		return resourceVersions, nil
+	}
+	keys, err := lister.ListKeys(ctx, historyKey(key))
+	if err != nil {
+		return nil, err
+	}
+	// The keys are listed in order of their padded resourceVersions.
+	resourceVersions := make([]uint64, 0, len(keys))
+	for _, k := range keys {
+		resourceVersion, err := strconv.ParseUint(path.Base(k), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid revision key %s: %v", k, err)
+		}
+		resourceVersions = append(resourceVersions, resourceVersion)
+	}
+	return resourceVersions, nil
+}
+
+// revision is a stored revision, without the annotations of its metadata.
+type revision struct {
+	object          runtime.Object
+	uid             types.UID
+	resourceVersion uint64
+	// operation is the operation that replaced the revision, or empty for
+	// the current revision.
+	operation historyv1alpha1.RevisionOperation
+	// user is the user who wrote the revision.
+	user      string
+	timestamp time.Time
+}
+
+// list returns the revisions of the object at key, oldest first, including
+// the current one. The revisions of objects deleted and created again with
+// the same name are listed too.
+func (h *history) list(ctx context.Context, key string) ([]revision, error) {
+	list := h.newListFunc()
+	if err := h.storage.List(ctx, historyKey(key), "", storage.Everything, list); err != nil {
+		return nil, err
+	}
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return nil, err
+	}
+	revisions := make([]revision, 0, len(items))
+	for _, item := range items {
+		accessor, err := meta.Accessor(item)
+		if err != nil {
+			return nil, err
+		}
+		annotations := accessor.GetAnnotations()
+		resourceVersion, err := strconv.ParseUint(annotations[revisionResourceVersionAnnotation], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid revision of %s: %v", key, err)
+		}
+		r := revision{
+			object:          item,
+			uid:             accessor.GetUID(),
+			resourceVersion: resourceVersion,
+			operation:       historyv1alpha1.RevisionOperation(annotations[revisionOperationAnnotation]),
+			user:            annotations[revisionUserAnnotation],
+		}
+		r.timestamp, _ = time.Parse(time.RFC3339, annotations[revisionTimestampAnnotation])
+		delete(annotations, revisionResourceVersionAnnotation)
+		delete(annotations, revisionOperationAnnotation)
+		delete(annotations, revisionUserAnnotation)
+		delete(annotations, revisionTimestampAnnotation)
+		if len(annotations) == 0 {
+			annotations = nil
+		}
+		accessor.SetAnnotations(annotations)
+		if err := h.storage.Versioner().UpdateObject(item, resourceVersion); err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, r)
+	}
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].resourceVersion < revisions[j].resourceVersion
+	})
+	return revisions, nil
+}
+
+// replacedRevisions returns the revisions that were replaced.
+func replacedRevisions(revisions []revision) []revision {
+	var replaced []revision
+	for _, r := range revisions {
+		if len(r.operation) > 0 {
+			replaced = append(replaced, r)
+		}
+	}
+	return replaced
+}
+
+// recordWrite records out, the object at key written by the user of ctx, if
+// the store keeps the history of its objects. Failures are logged, as the
+// operation already succeeded.
+func (e *Store) recordWrite(ctx context.Context, key string, out runtime.Object) {
+	if e.history == nil || out == nil {
+		return
+	}
+	if err := e.history.write(ctx, key, out); err != nil {
+		utilruntime.HandleError(fmt.Errorf("failed to record the revision of %s: %v", key, err))
+	}
+}
+
+// recordRevision records that obj, the revision of the object at key, was
+// replaced by op, if the store keeps the history of its objects. Failures are
+// logged, as the operation already succeeded.
+func (e *Store) recordRevision(ctx context.Context, key string, obj runtime.Object, op historyv1alpha1.RevisionOperation) {
+	if e.history == nil || obj == nil {
+		return
+	}
+	if err := e.history.replace(ctx, key, obj, op); err != nil {
+		utilruntime.HandleError(fmt.Errorf("failed to record the revision of %s replaced by %s: %v", key, op, err))
+	}
+}
+
+// recordUpdate records that the revision of the object at key replaced was
+// replaced by the update to out, and out as written by the user of ctx, if
+// the update changed the object.
+func (e *Store) recordUpdate(ctx context.Context, key string, replaced, out runtime.Object) {
+	if e.history == nil || replaced == nil {
+		return
+	}
+	replacedVersion, err := e.Storage.Versioner().ObjectResourceVersion(replaced)
+	if err != nil {
+		return
+	}
+	if version, err := e.Storage.Versioner().ObjectResourceVersion(out); err == nil && version == replacedVersion {
+		return
+	}
+	e.recordWrite(ctx, key, out)
+	e.recordRevision(ctx, key, replaced, historyv1alpha1.RevisionOperationUpdate)
+}
+
+// recordDeletion records out, the object at key as of its deletion, as the
+// last revision of its history and moves it to the trash.
+func (e *Store) recordDeletion(ctx context.Context, key string, out runtime.Object) {
+	e.recordRevision(ctx, key, out, historyv1alpha1.RevisionOperationDelete)
+	e.moveToTrash(ctx, key, out)
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	jsonpatch "github.com/aaron-prindle/krmapiserver/included/github.com/evanphx/json-patch"
+	historyv1alpha1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/history/v1alpha1"
+	kubeerr "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/api/errors"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/api/meta"
+	metav1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime/schema"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/types"
+	genericapirequest "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/endpoints/request"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/registry/rest"
+	storeerr "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/errors"
+)
+
+var _ rest.HistoryProvider = &Store{}
+
+// HistoryStorage implements rest.HistoryProvider.
+func (e *Store) HistoryStorage(convertor runtime.ObjectConvertor, gv schema.GroupVersion) rest.Storage {
+	if e.history == nil {
+		return nil
+	}
+	return &HistoryREST{store: e, convertor: convertor, groupVersion: gv}
+}
+
+// HistoryREST implements the history subresource of a store, which returns
+// the retained revisions of an object in gv.
+type HistoryREST struct {
+	store        *Store
+	convertor    runtime.ObjectConvertor
+	groupVersion schema.GroupVersion
+}
+
+var _ rest.Getter = &HistoryREST{}
+var _ rest.GroupVersionKindProvider = &HistoryREST{}
+
+// New implements rest.Storage.
+func (r *HistoryREST) New() runtime.Object {
+	return &historyv1alpha1.ObjectHistory{}
+}
+
+// GroupVersionKind implements rest.GroupVersionKindProvider.
+func (r *HistoryREST) GroupVersionKind(containingGV schema.GroupVersion) schema.GroupVersionKind {
+	return historyv1alpha1.SchemeGroupVersion.WithKind("ObjectHistory")
+}
+
+// Get returns the history of the object with the given name, or of the last
+// object with the name if it was deleted. The revisions of earlier objects
+// with the name, which have other UIDs, are not returned. It fails with
+// NotFound if the object neither exists nor has retained revisions.
+func (r *HistoryREST) Get(ctx context.Context, name string, options *metav1.GetOptions) (runtime.Object, error) {
+	key, err := r.store.KeyFunc(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	qualifiedResource := r.store.qualifiedResourceFromContext(ctx)
+	all, err := r.store.history.list(ctx, key)
+	if err != nil {
+		return nil, storeerr.InterpretGetError(err, qualifiedResource, name)
+	}
+	current := r.store.NewFunc()
+	if err := r.store.Storage.Get(ctx, key, "", current, true); err != nil {
+		return nil, storeerr.InterpretGetError(err, qualifiedResource, name)
+	}
+	if version, err := r.store.Storage.Versioner().ObjectResourceVersion(current); err != nil || version == 0 {
+		current = nil
+	}
+	all = replacedRevisions(all)
+	var uid types.UID
+	if current != nil {
+		accessor, err := meta.Accessor(current)
+		if err != nil {
+			return nil, kubeerr.NewInternalError(err)
+		}
+		uid = accessor.GetUID()
+	} else if len(all) > 0 {
+		uid = all[len(all)-1].uid
+	}
+	var revisions []revision
+	for _, r := range all {
+		if r.uid == uid {
+			revisions = append(revisions, r)
+		}
+	}
+	if len(revisions) == 0 && current == nil {
+		return nil, kubeerr.NewNotFound(qualifiedResource, name)
+	}
+
+	// The patches are computed between the revisions encoded in the
+	// requested version.
+	encoded := make([][]byte, len(revisions)+1)
+	for i, revision := range revisions {
+		if encoded[i], err = r.encode(revision.object); err != nil {
+			return nil, kubeerr.NewInternalError(err)
+		}
+	}
+	if current != nil {
+		if encoded[len(revisions)], err = r.encode(current); err != nil {
+			return nil, kubeerr.NewInternalError(err)
+		}
+	}
+
+	result := &historyv1alpha1.ObjectHistory{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Revisions:  make([]historyv1alpha1.ObjectRevision, 0, len(revisions)),
+	}
+	result.Namespace, _ = genericapirequest.NamespaceFrom(ctx)
+	for i, revision := range revisions {
+		item := historyv1alpha1.ObjectRevision{
+			ResourceVersion: strconv.FormatUint(revision.resourceVersion, 10),
+			Object:          runtime.RawExtension{Raw: encoded[i]},
+			Operation:       revision.operation,
+			User:            revision.user,
+			Timestamp:       metav1.NewTime(revision.timestamp),
+		}
+		if revision.operation == historyv1alpha1.RevisionOperationUpdate && encoded[i+1] != nil {
+			patch, err := jsonpatch.CreateMergePatch(encoded[i], encoded[i+1])
+			if err != nil {
+				return nil, kubeerr.NewInternalError(err)
+			}
+			item.Patch = string(patch)
+		}
+		result.Revisions = append(result.Revisions, item)
+	}
+	return result, nil
+}
+
+// encode returns the JSON encoding of obj in the version of the history.
+func (r *HistoryREST) encode(obj runtime.Object) ([]byte, error) {
+	versioned, err := r.convertor.ConvertToVersion(obj, r.groupVersion)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(versioned)
+}
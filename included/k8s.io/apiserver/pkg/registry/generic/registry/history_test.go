@@ -0,0 +1,198 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/core/v1"
+	historyv1alpha1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/history/v1alpha1"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/api/errors"
+	metav1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/authentication/user"
+	genericapirequest "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/endpoints/request"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/registry/generic"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/registry/rest"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/storagebackend"
+	storagetesting "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/testing"
+	clientgoscheme "github.com/aaron-prindle/krmapiserver/included/k8s.io/client-go/kubernetes/scheme"
+)
+
+// newTestHistoryStoreRegistry returns a store keeping the history of its
+// objects in memory.
+func newTestHistoryStoreRegistry(t *testing.T, options generic.HistoryOptions) (func(), *Store) {
+	registry := newTestPodStore(t, generic.UndecoratedStorage)
+	config := storagebackend.Config{
+		Type:  storagebackend.StorageTypeMemory,
+		Codec: storagetesting.Codec,
+	}
+	var historyDestroy func()
+	registry.history, historyDestroy = newHistory(config, "/pods", options, registry.NewFunc, registry.NewListFunc)
+	return func() {
+		historyDestroy()
+		registry.DestroyFunc()
+	}, registry
+}
+
+func getHistory(t *testing.T, ctx context.Context, registry *Store, name string) *historyv1alpha1.ObjectHistory {
+	obj, err := registry.HistoryStorage(clientgoscheme.Scheme, v1.SchemeGroupVersion).(rest.Getter).Get(ctx, name, &metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return obj.(*historyv1alpha1.ObjectHistory)
+}
+
+func TestStoreHistory(t *testing.T) {
+	testContext := genericapirequest.WithUser(genericapirequest.WithNamespace(genericapirequest.NewContext(), "test"), &user.DefaultInfo{Name: "alice"})
+	destroyFunc, registry := newTestHistoryStoreRegistry(t, generic.HistoryOptions{Limit: 2})
+	defer destroyFunc()
+
+	if _, err := registry.HistoryStorage(clientgoscheme.Scheme, v1.SchemeGroupVersion).(rest.Getter).Get(testContext, "foo", &metav1.GetOptions{}); !errors.IsNotFound(err) {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "test"},
+		Spec:       v1.PodSpec{NodeName: "machine"},
+	}
+	obj, err := registry.Create(testContext, pod, rest.ValidateAllObjectFunc, &metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if history := getHistory(t, testContext, registry, "foo"); len(history.Revisions) != 0 {
+		t.Errorf("expected no revisions after creation, got %v", history.Revisions)
+	}
+
+	var resourceVersions []string
+	for _, nodeName := range []string{"machine2", "machine3", "machine4"} {
+		resourceVersions = append(resourceVersions, obj.(*v1.Pod).ResourceVersion)
+		pod := obj.(*v1.Pod).DeepCopy()
+		pod.Spec.NodeName = nodeName
+		if obj, _, err = registry.Update(testContext, pod.Name, rest.DefaultUpdatedObjectInfo(pod), rest.ValidateAllObjectFunc, rest.ValidateAllObjectUpdateFunc, false, &metav1.UpdateOptions{}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	// dry-run updates are not recorded
+	dryRunPod := obj.(*v1.Pod).DeepCopy()
+	dryRunPod.Spec.NodeName = "machine5"
+	if _, _, err = registry.Update(testContext, dryRunPod.Name, rest.DefaultUpdatedObjectInfo(dryRunPod), rest.ValidateAllObjectFunc, rest.ValidateAllObjectUpdateFunc, false, &metav1.UpdateOptions{DryRun: []string{metav1.DryRunAll}}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// only the last 2 revisions are kept
+	history := getHistory(t, testContext, registry, "foo")
+	if len(history.Revisions) != 2 {
+		t.Fatalf("expected 2 revisions, got %v", history.Revisions)
+	}
+	for i, revision := range history.Revisions {
+		if revision.ResourceVersion != resourceVersions[i+1] || revision.Operation != historyv1alpha1.RevisionOperationUpdate || revision.User != "alice" {
+			t.Errorf("unexpected revision %d: %#v", i, revision)
+		}
+	}
+	if patch := history.Revisions[1].Patch; patch != `{"metadata":{"resourceVersion":"`+obj.(*v1.Pod).ResourceVersion+`"},"spec":{"nodeName":"machine4"}}` {
+		t.Errorf("unexpected patch %s", patch)
+	}
+	revision, err := runtime.Decode(clientgoscheme.Codecs.UniversalDecoder(v1.SchemeGroupVersion), history.Revisions[1].Object.Raw)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if nodeName := revision.(*v1.Pod).Spec.NodeName; nodeName != "machine3" {
+		t.Errorf("expected the revision to be on machine3, got %s", nodeName)
+	}
+
+	if _, _, err := registry.Delete(testContext, "foo", rest.ValidateAllObjectFunc, nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	history = getHistory(t, testContext, registry, "foo")
+	if len(history.Revisions) != 2 {
+		t.Fatalf("expected 2 revisions, got %v", history.Revisions)
+	}
+	last := history.Revisions[1]
+	if last.ResourceVersion != obj.(*v1.Pod).ResourceVersion || last.Operation != historyv1alpha1.RevisionOperationDelete || len(last.Patch) != 0 {
+		t.Errorf("unexpected revision of the deletion: %#v", last)
+	}
+}
+
+func TestStoreHistoryAuthorsAndRecreation(t *testing.T) {
+	ctx := genericapirequest.WithNamespace(genericapirequest.NewContext(), "test")
+	as := func(name string) context.Context {
+		return genericapirequest.WithUser(ctx, &user.DefaultInfo{Name: name})
+	}
+	destroyFunc, registry := newTestHistoryStoreRegistry(t, generic.HistoryOptions{Limit: 10})
+	defer destroyFunc()
+
+	update := func(user string, obj runtime.Object, nodeName string) runtime.Object {
+		pod := obj.(*v1.Pod).DeepCopy()
+		pod.Spec.NodeName = nodeName
+		obj, _, err := registry.Update(as(user), pod.Name, rest.DefaultUpdatedObjectInfo(pod), rest.ValidateAllObjectFunc, rest.ValidateAllObjectUpdateFunc, false, &metav1.UpdateOptions{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return obj
+	}
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "test"},
+		Spec:       v1.PodSpec{NodeName: "machine"},
+	}
+	created, err := registry.Create(as("alice"), pod, rest.ValidateAllObjectFunc, &metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	updated := update("bob", created, "machine2")
+	if _, _, err := registry.Delete(as("carol"), "foo", rest.ValidateAllObjectFunc, nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// the revisions are attributed to the users who wrote them
+	history := getHistory(t, ctx, registry, "foo")
+	if len(history.Revisions) != 2 {
+		t.Fatalf("expected 2 revisions, got %v", history.Revisions)
+	}
+	if r := history.Revisions[0]; r.ResourceVersion != created.(*v1.Pod).ResourceVersion || r.User != "alice" || r.Operation != historyv1alpha1.RevisionOperationUpdate {
+		t.Errorf("unexpected revision of the creation: %#v", r)
+	}
+	if r := history.Revisions[1]; r.ResourceVersion != updated.(*v1.Pod).ResourceVersion || r.User != "bob" || r.Operation != historyv1alpha1.RevisionOperationDelete {
+		t.Errorf("unexpected revision of the update: %#v", r)
+	}
+
+	// an object created again with the same name does not inherit the history
+	pod = &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "test"},
+		Spec:       v1.PodSpec{NodeName: "other"},
+	}
+	recreated, err := registry.Create(as("dave"), pod, rest.ValidateAllObjectFunc, &metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if history := getHistory(t, ctx, registry, "foo"); len(history.Revisions) != 0 {
+		t.Errorf("expected no revisions of the new object, got %v", history.Revisions)
+	}
+	current := update("erin", recreated, "other2")
+	history = getHistory(t, ctx, registry, "foo")
+	if len(history.Revisions) != 1 {
+		t.Fatalf("expected 1 revision of the new object, got %v", history.Revisions)
+	}
+	r := history.Revisions[0]
+	if r.ResourceVersion != recreated.(*v1.Pod).ResourceVersion || r.User != "dave" || r.Operation != historyv1alpha1.RevisionOperationUpdate {
+		t.Errorf("unexpected revision of the new object: %#v", r)
+	}
+	if r.Patch != `{"metadata":{"resourceVersion":"`+current.(*v1.Pod).ResourceVersion+`"},"spec":{"nodeName":"other2"}}` {
+		t.Errorf("unexpected patch %s", r.Patch)
+	}
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	v1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/core/v1"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/api/meta"
+	metainternalversion "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/fields"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
+	genericapirequest "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/endpoints/request"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/registry/generic"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/registry/rest"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/names"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/storagebackend"
+	storagetesting "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/testing"
+	clientgoscheme "github.com/aaron-prindle/krmapiserver/included/k8s.io/client-go/kubernetes/scheme"
+)
+
+// newTestPodStore returns a store of pods in memory, decorated by decorator,
+// that exposes the given JSON paths of the pods as selectable fields.
+func newTestPodStore(t *testing.T, decorator generic.StorageDecorator, paths ...string) *Store {
+	info, ok := runtime.SerializerInfoForMediaType(clientgoscheme.Codecs.SupportedMediaTypes(), runtime.ContentTypeJSON)
+	if !ok {
+		t.Fatalf("no JSON serializer")
+	}
+	strategy := &testRESTStrategy{clientgoscheme.Scheme, names.SimpleNameGenerator, true, false, true}
+	store := &Store{
+		NewFunc:                  func() runtime.Object { return &v1.Pod{} },
+		NewListFunc:              func() runtime.Object { return &v1.PodList{} },
+		DefaultQualifiedResource: v1.Resource("pods"),
+		CreateStrategy:           strategy,
+		UpdateStrategy:           strategy,
+		DeleteStrategy:           strategy,
+	}
+	options := &generic.StoreOptions{
+		RESTOptions: generic.RESTOptions{
+			StorageConfig: &storagebackend.Config{
+				Type:        storagebackend.StorageTypeMemory,
+				Codec:       storagetesting.Codec,
+				JSONEncoder: clientgoscheme.Codecs.EncoderForVersion(info.Serializer, v1.SchemeGroupVersion),
+			},
+			Decorator:        decorator,
+			ResourcePrefix:   "pods",
+			SelectableFields: paths,
+		},
+	}
+	if err := store.CompleteWithOptions(options); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return store
+}
+
+func TestStoreSelectableFields(t *testing.T) {
+	decorators := map[string]generic.StorageDecorator{
+		"etcd":   generic.UndecoratedStorage,
+		"cacher": StorageWithCacher(10),
+	}
+	for name, decorator := range decorators {
+		t.Run(name, func(t *testing.T) {
+			registry := newTestPodStore(t, decorator, "spec.activeDeadlineSeconds", "status.phase")
+			defer registry.DestroyFunc()
+			if !reflect.DeepEqual(registry.SelectableFields(), []string{"spec.activeDeadlineSeconds", "status.phase"}) {
+				t.Errorf("unexpected selectable fields %v", registry.SelectableFields())
+			}
+
+			testContext := genericapirequest.WithNamespace(genericapirequest.NewContext(), "test")
+			var resourceVersion string
+			for _, pod := range []struct {
+				name     string
+				deadline int64
+				phase    v1.PodPhase
+			}{
+				{name: "foo", deadline: 10, phase: v1.PodRunning},
+				{name: "bar", deadline: 30, phase: v1.PodPending},
+				{name: "baz", deadline: 60, phase: v1.PodRunning},
+			} {
+				deadline := pod.deadline
+				obj, err := registry.Create(testContext, &v1.Pod{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: pod.name},
+					Spec:       v1.PodSpec{ActiveDeadlineSeconds: &deadline},
+					Status:     v1.PodStatus{Phase: pod.phase},
+				}, rest.ValidateAllObjectFunc, &metav1.CreateOptions{})
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				accessor, err := meta.Accessor(obj)
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				resourceVersion = accessor.GetResourceVersion()
+			}
+
+			for selector, expected := range map[string][]string{
+				"spec.activeDeadlineSeconds>20":                              {"bar", "baz"},
+				"spec.activeDeadlineSeconds<20":                              {"foo"},
+				"status.phase=Running":                                       {"baz", "foo"},
+				"status.phase in (Pending,Failed)":                           {"bar"},
+				"status.phase notin (Pending),spec.activeDeadlineSeconds>20": {"baz"},
+				"metadata.name=foo,status.phase!=Running":                    {},
+			} {
+				list, err := registry.List(testContext, &metainternalversion.ListOptions{
+					FieldSelector:   fields.ParseSelectorOrDie(selector),
+					ResourceVersion: resourceVersion,
+				})
+				if err != nil {
+					t.Fatalf("%s: unexpected error: %v", selector, err)
+				}
+				names := []string{}
+				for _, pod := range list.(*v1.PodList).Items {
+					names = append(names, pod.Name)
+				}
+				sort.Strings(names)
+				if !reflect.DeepEqual(expected, names) {
+					t.Errorf("%s: expected %v, got %v", selector, expected, names)
+				}
+			}
+		})
+	}
+}
+
+func TestStoreSelectableFieldsRequireJSONEncoder(t *testing.T) {
+	strategy := &testRESTStrategy{clientgoscheme.Scheme, names.SimpleNameGenerator, true, false, true}
+	store := &Store{
+		NewFunc:                  func() runtime.Object { return &v1.Pod{} },
+		NewListFunc:              func() runtime.Object { return &v1.PodList{} },
+		DefaultQualifiedResource: v1.Resource("pods"),
+		CreateStrategy:           strategy,
+		UpdateStrategy:           strategy,
+		DeleteStrategy:           strategy,
+	}
+	options := &generic.StoreOptions{
+		RESTOptions: generic.RESTOptions{
+			StorageConfig: &storagebackend.Config{
+				Type:  storagebackend.StorageTypeMemory,
+				Codec: storagetesting.Codec,
+			},
+			Decorator:        generic.UndecoratedStorage,
+			ResourcePrefix:   "pods",
+			SelectableFields: []string{"status.phase"},
+		},
+	}
+	if err := store.CompleteWithOptions(options); err == nil {
+		t.Errorf("expected an error completing a store with selectable fields and no JSON encoder")
+	}
+}
@@ -17,17 +17,26 @@ limitations under the License.
 package registry
 
 import (
+	"fmt"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
 	"sync"
 
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/klog"
 
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime/schema"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/registry/generic"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage"
 	cacherstorage "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/cacher"
 	etcdstorage "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/etcd"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/faultinjection"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/sharded"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/storagebackend"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/storagebackend/factory"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/client-go/tools/cache"
 )
 
 // Creates a cacher based given storageConfig.
@@ -39,46 +48,125 @@ func StorageWithCacher(capacity int) generic.StorageDecorator {
 		newFunc func() runtime.Object,
 		newListFunc func() runtime.Object,
 		getAttrsFunc storage.AttrFunc,
-		triggerFunc storage.TriggerPublisherFunc) (storage.Interface, factory.DestroyFunc) {
+		indexers *cache.Indexers) (storage.Interface, factory.DestroyFunc) {
 
-		s, d := generic.NewRawStorage(storageConfig)
 		if capacity <= 0 {
 			klog.V(5).Infof("Storage caching is disabled for %T", newFunc())
-			return s, d
+			rawConfig := *storageConfig
+			rawConfig.NewFunc = newFunc
+			return generic.NewRawStorage(&rawConfig)
 		}
 		if klog.V(5) {
 			klog.Infof("Storage caching is enabled for %T with capacity %v", newFunc(), capacity)
 		}
+		if len(storageConfig.Sharding.Shards) == 0 {
+			snapshotName := snapshotFileName(storageConfig.Prefix, resourcePrefix)
+			return newCacher(storageConfig, capacity, snapshotName, resourcePrefix, keyFunc, newFunc, newListFunc, getAttrsFunc, indexers)
+		}
 
-		// TODO: we would change this later to make storage always have cacher and hide low level KV layer inside.
-		// Currently it has two layers of same storage interface -- cacher and low level kv.
-		cacherConfig := cacherstorage.Config{
-			CacheCapacity:        capacity,
-			Storage:              s,
-			Versioner:            etcdstorage.APIObjectVersioner{},
-			ResourcePrefix:       resourcePrefix,
-			KeyFunc:              keyFunc,
-			NewFunc:              newFunc,
-			NewListFunc:          newListFunc,
-			GetAttrsFunc:         getAttrsFunc,
-			TriggerPublisherFunc: triggerFunc,
-			Codec:                storageConfig.Codec,
+		// The watch cache keeps the single resourceVersion sequence of a
+		// storage, so every shard of a sharded resource gets its own.
+		if storageConfig.Type != storagebackend.StorageTypeUnset && storageConfig.Type != storagebackend.StorageTypeETCD3 {
+			klog.Fatalf("Unable to create storage backend: only the %s storage backend can be sharded, not %s", storagebackend.StorageTypeETCD3, storageConfig.Type)
+		}
+		shards := make([]storage.Interface, 0, len(storageConfig.Sharding.Shards))
+		destroyFuncs := make([]factory.DestroyFunc, 0, len(storageConfig.Sharding.Shards))
+		for i, transport := range storageConfig.Sharding.Shards {
+			shardConfig := *storageConfig
+			shardConfig.Transport = transport
+			shardConfig.Sharding = storagebackend.ShardingConfig{}
+			snapshotName := snapshotFileName(storageConfig.Prefix, path.Join(resourcePrefix, fmt.Sprintf("shard-%d", i)))
+			s, d := newCacher(&shardConfig, capacity, snapshotName, resourcePrefix, keyFunc, newFunc, newListFunc, getAttrsFunc, indexers)
+			shards = append(shards, s)
+			destroyFuncs = append(destroyFuncs, d)
 		}
-		cacher := cacherstorage.NewCacherFromConfig(cacherConfig)
 		destroyFunc := func() {
-			cacher.Stop()
-			d()
+			for _, destroy := range destroyFuncs {
+				destroy()
+			}
 		}
+		return sharded.New(shards, storageConfig.ResourcePrefix, storageConfig.Paging), destroyFunc
+	}
+}
 
-		// TODO : Remove RegisterStorageCleanup below when PR
-		// https://github.com/kubernetes/kubernetes/pull/50690
-		// merges as that shuts down storage properly
-		RegisterStorageCleanup(destroyFunc)
+// newCacher creates the storage of storageConfig, which must not be sharded,
+// and caches it in a cacher that saves its state in snapshotName, if the
+// watch cache snapshots are enabled.
+func newCacher(
+	storageConfig *storagebackend.Config,
+	capacity int,
+	snapshotName string,
+	resourcePrefix string,
+	keyFunc func(obj runtime.Object) (string, error),
+	newFunc func() runtime.Object,
+	newListFunc func() runtime.Object,
+	getAttrsFunc storage.AttrFunc,
+	indexers *cache.Indexers) (storage.Interface, factory.DestroyFunc) {
+
+	rawConfig := *storageConfig
+	rawConfig.NewFunc = newFunc
+	s, d := generic.NewRawStorage(&rawConfig)
+
+	// TODO: we would change this later to make storage always have cacher and hide low level KV layer inside.
+	// Currently it has two layers of same storage interface -- cacher and low level kv.
+	cacherConfig := cacherstorage.Config{
+		CacheCapacity:  capacity,
+		Storage:        s,
+		Versioner:      etcdstorage.APIObjectVersioner{},
+		ResourcePrefix: resourcePrefix,
+		KeyFunc:        keyFunc,
+		NewFunc:        newFunc,
+		NewListFunc:    newListFunc,
+		GetAttrsFunc:   getAttrsFunc,
+		Indexers:       indexers,
+		Codec:          storageConfig.Codec,
+
+		ConsistencyCheckInterval: storageConfig.WatchCacheConsistencyCheckInterval,
+	}
+	if len(storageConfig.WatchCacheSnapshotDir) > 0 {
+		cacherConfig.SnapshotPath = filepath.Join(storageConfig.WatchCacheSnapshotDir, snapshotName)
+		cacherConfig.SnapshotInterval = storageConfig.WatchCacheSnapshotInterval
+		cacherConfig.SnapshotTransformer = storageConfig.Transformer
+	}
+	cacher := cacherstorage.NewCacherFromConfig(cacherConfig)
+	destroyFunc := func() {
+		cacher.Stop()
+		d()
+	}
+
+	// TODO : Remove RegisterStorageCleanup below when PR
+	// https://github.com/kubernetes/kubernetes/pull/50690
+	// merges as that shuts down storage properly
+	RegisterStorageCleanup(destroyFunc)
+
+	return cacher, destroyFunc
+}
 
-		return cacher, destroyFunc
+// StorageWithFaultInjection decorates the storages returned by decorator to
+// inject faults in the operations on resource, by the rules of the default
+// fault injector.
+func StorageWithFaultInjection(resource schema.GroupResource, decorator generic.StorageDecorator) generic.StorageDecorator {
+	return func(
+		storageConfig *storagebackend.Config,
+		resourcePrefix string,
+		keyFunc func(obj runtime.Object) (string, error),
+		newFunc func() runtime.Object,
+		newListFunc func() runtime.Object,
+		getAttrsFunc storage.AttrFunc,
+		indexers *cache.Indexers) (storage.Interface, factory.DestroyFunc) {
+
+		s, d := decorator(storageConfig, resourcePrefix, keyFunc, newFunc, newListFunc, getAttrsFunc, indexers)
+		return faultinjection.New(s, resource, faultinjection.DefaultInjector()), d
 	}
 }
 
+// snapshotFileName returns the name of the file the watch cache of the
+// resources under resourcePrefix saves its state in. Resources stored under
+// different prefixes get different files.
+func snapshotFileName(prefix, resourcePrefix string) string {
+	return url.PathEscape(strings.TrimPrefix(path.Join(prefix, resourcePrefix), "/")) + ".snapshot"
+}
+
 // TODO : Remove all the code below when PR
 // https://github.com/kubernetes/kubernetes/pull/50690
 // merges as that shuts down storage properly
@@ -45,6 +45,7 @@ import (
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage"
 	storeerr "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/errors"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/etcd/metrics"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/rewrite"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/util/dryrun"
 
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/klog"
@@ -190,6 +191,15 @@ type Store struct {
 	StorageVersioner runtime.GroupVersioner
 	// Called to cleanup clients used by the underlying Storage; optional.
 	DestroyFunc func()
+
+	// history keeps the revisions of the objects replaced by updates and
+	// deletions, if the RESTOptions enable it.
+	history *history
+	// trash keeps the deleted objects, if the RESTOptions enable it.
+	trash *trash
+	// selectableFields are the JSON paths of the objects that field selectors
+	// may use, if the RESTOptions expose any.
+	selectableFields []string
 }
 
 // Note: the rest.StandardStorage interface aggregates the common REST verbs
@@ -289,7 +299,7 @@ func (e *Store) GetExportStrategy() rest.RESTExportStrategy {
 }
 
 // List returns a list of items matching labels and field according to the
-// store's PredicateFunc.
+// store's PredicateFunc, and annotations according to the annotation selector.
 func (e *Store) List(ctx context.Context, options *metainternalversion.ListOptions) (runtime.Object, error) {
 	label := labels.Everything()
 	if options != nil && options.LabelSelector != nil {
@@ -299,7 +309,11 @@ func (e *Store) List(ctx context.Context, options *metainternalversion.ListOptio
 	if options != nil && options.FieldSelector != nil {
 		field = options.FieldSelector
 	}
-	out, err := e.ListPredicate(ctx, e.PredicateFunc(label, field), options)
+	predicate := e.PredicateFunc(label, field)
+	if options != nil && options.AnnotationSelector != nil {
+		predicate.Annotation = options.AnnotationSelector
+	}
+	out, err := e.ListPredicate(ctx, predicate, options)
 	if err != nil {
 		return nil, err
 	}
@@ -380,6 +394,9 @@ func (e *Store) Create(ctx context.Context, obj runtime.Object, createValidation
 		}
 		return nil, err
 	}
+	if !dryrun.IsDryRun(options.DryRun) {
+		e.recordWrite(ctx, key, out)
+	}
 	if e.AfterCreate != nil {
 		if err := e.AfterCreate(out); err != nil {
 			return nil, err
@@ -440,6 +457,9 @@ func (e *Store) deleteWithoutFinalizers(ctx context.Context, name, key string, o
 		}
 		return nil, false, storeerr.InterpretDeleteError(err, e.qualifiedResourceFromContext(ctx), name)
 	}
+	if !dryRun {
+		e.recordDeletion(ctx, key, out)
+	}
 	_, err := e.finalizeDelete(ctx, out, true)
 	// clients are expecting an updated object if a PUT succeeded, but
 	// finalizeDelete returns a metav1.Status, so return the object in
@@ -471,6 +491,8 @@ func (e *Store) Update(ctx context.Context, name string, objInfo rest.UpdatedObj
 	out := e.NewFunc()
 	// deleteObj is only used in case a deletion is carried out
 	var deleteObj runtime.Object
+	// replaced is the object replaced by the update, if its history is kept
+	var replaced runtime.Object
 	err = e.Storage.GuaranteedUpdate(ctx, key, out, true, storagePreconditions, func(existing runtime.Object, res storage.ResponseMeta) (runtime.Object, *uint64, error) {
 		// Given the existing object, get the new object
 		obj, err := objInfo.UpdatedObject(ctx, existing)
@@ -518,6 +540,9 @@ func (e *Store) Update(ctx context.Context, name string, objInfo rest.UpdatedObj
 
 		creating = false
 		creatingObj = nil
+		if e.history != nil {
+			replaced = existing.DeepCopyObject()
+		}
 		if doUnconditionalUpdate {
 			// Update the object's resource version to match the latest
 			// storage object's resource version.
@@ -581,12 +606,18 @@ func (e *Store) Update(ctx context.Context, name string, objInfo rest.UpdatedObj
 	}
 
 	if creating {
+		if !dryrun.IsDryRun(options.DryRun) {
+			e.recordWrite(ctx, key, out)
+		}
 		if e.AfterCreate != nil {
 			if err := e.AfterCreate(out); err != nil {
 				return nil, false, err
 			}
 		}
 	} else {
+		if !dryrun.IsDryRun(options.DryRun) {
+			e.recordUpdate(ctx, key, replaced, out)
+		}
 		if e.AfterUpdate != nil {
 			if err := e.AfterUpdate(out); err != nil {
 				return nil, false, err
@@ -804,6 +835,7 @@ func markAsDeleting(obj runtime.Object, now time.Time) (err error) {
 func (e *Store) updateForGracefulDeletionAndFinalizers(ctx context.Context, name, key string, options *metav1.DeleteOptions, preconditions storage.Preconditions, deleteValidation rest.ValidateObjectFunc, in runtime.Object) (err error, ignoreNotFound, deleteImmediately bool, out, lastExisting runtime.Object) {
 	lastGraceful := int64(0)
 	var pendingFinalizers bool
+	var replaced runtime.Object
 	out = e.NewFunc()
 	err = e.Storage.GuaranteedUpdate(
 		ctx,
@@ -815,6 +847,9 @@ func (e *Store) updateForGracefulDeletionAndFinalizers(ctx context.Context, name
 			if err := deleteValidation(existing); err != nil {
 				return nil, err
 			}
+			if e.history != nil {
+				replaced = existing.DeepCopyObject()
+			}
 			graceful, pendingGraceful, err := rest.BeforeDelete(e.DeleteStrategy, ctx, existing, options)
 			if err != nil {
 				return nil, err
@@ -857,6 +892,9 @@ func (e *Store) updateForGracefulDeletionAndFinalizers(ctx context.Context, name
 	)
 	switch err {
 	case nil:
+		if !dryrun.IsDryRun(options.DryRun) {
+			e.recordUpdate(ctx, key, replaced, out)
+		}
 		// If there are pending finalizers, we never delete the object immediately.
 		if pendingFinalizers {
 			return nil, false, false, out, lastExisting
@@ -963,6 +1001,9 @@ func (e *Store) Delete(ctx context.Context, name string, deleteValidation rest.V
 		}
 		return nil, false, storeerr.InterpretDeleteError(err, qualifiedResource, name)
 	}
+	if !dryrun.IsDryRun(options.DryRun) {
+		e.recordDeletion(ctx, key, out)
+	}
 	out, err = e.finalizeDelete(ctx, out, true)
 	return out, true, err
 }
@@ -1080,7 +1121,7 @@ func (e *Store) finalizeDelete(ctx context.Context, obj runtime.Object, runHooks
 	return status, nil
 }
 
-// Watch makes a matcher for the given label and field, and calls
+// Watch makes a matcher for the given label, field and annotation, and calls
 // WatchPredicate. If possible, you should customize PredicateFunc to produce
 // a matcher that matches by key. SelectionPredicate does this for you
 // automatically.
@@ -1094,6 +1135,9 @@ func (e *Store) Watch(ctx context.Context, options *metainternalversion.ListOpti
 		field = options.FieldSelector
 	}
 	predicate := e.PredicateFunc(label, field)
+	if options != nil && options.AnnotationSelector != nil {
+		predicate.Annotation = options.AnnotationSelector
+	}
 
 	resourceVersion := ""
 	if options != nil {
@@ -1221,6 +1265,11 @@ func (e *Store) CompleteWithOptions(options *generic.StoreOptions) error {
 		return fmt.Errorf("options for %s must have RESTOptions set", e.DefaultQualifiedResource.String())
 	}
 
+	opts, err := options.RESTOptions.GetRESTOptions(e.DefaultQualifiedResource)
+	if err != nil {
+		return err
+	}
+
 	attrFunc := options.AttrFunc
 	if attrFunc == nil {
 		if isNamespaced {
@@ -1229,19 +1278,40 @@ func (e *Store) CompleteWithOptions(options *generic.StoreOptions) error {
 			attrFunc = storage.DefaultClusterScopedAttr
 		}
 	}
+	// The selectable fields are added to the fields of the AttrFunc of the
+	// watch cache, which keeps the fields of every object for the selectors of
+	// later requests. The predicates of the store only evaluate them when
+	// their field selector refers to one.
+	var selectableFields storage.FieldMutationFunc
+	if len(opts.SelectableFields) > 0 {
+		_, isUnstructured := e.NewFunc().(runtime.Unstructured)
+		if !isUnstructured && opts.StorageConfig.JSONEncoder == nil {
+			return fmt.Errorf("store for %s has selectable fields but its storage config has no JSONEncoder", e.DefaultQualifiedResource.String())
+		}
+		selectableFields = storage.SelectableFieldsMutation(opts.SelectableFields, opts.StorageConfig.JSONEncoder)
+		e.selectableFields = opts.SelectableFields
+	}
 	if e.PredicateFunc == nil {
+		getAttrs := attrFunc
 		e.PredicateFunc = func(label labels.Selector, field fields.Selector) storage.SelectionPredicate {
 			return storage.SelectionPredicate{
 				Label:    label,
 				Field:    field,
-				GetAttrs: attrFunc,
+				GetAttrs: getAttrs,
 			}
 		}
 	}
-
-	opts, err := options.RESTOptions.GetRESTOptions(e.DefaultQualifiedResource)
-	if err != nil {
-		return err
+	if selectableFields != nil {
+		predicateFunc := e.PredicateFunc
+		selectable := e.selectableFields
+		e.PredicateFunc = func(label labels.Selector, field fields.Selector) storage.SelectionPredicate {
+			predicate := predicateFunc(label, field)
+			if storage.SelectsSelectableFields(field, selectable) {
+				predicate.GetAttrs = predicate.GetAttrs.WithFieldMutation(selectableFields)
+			}
+			return predicate
+		}
+		attrFunc = attrFunc.WithFieldMutation(selectableFields)
 	}
 
 	// ResourcePrefix must come from the underlying factory
@@ -1287,9 +1357,10 @@ func (e *Store) CompleteWithOptions(options *generic.StoreOptions) error {
 		return e.KeyFunc(genericapirequest.NewContext(), accessor.GetName())
 	}
 
-	triggerFunc := options.TriggerFunc
-	if triggerFunc == nil {
-		triggerFunc = storage.NoTriggerPublisher
+	indexers := options.Indexers
+	if indexers == nil && options.TriggerFunc != nil {
+		triggerIndexers := storage.TriggerIndexers(options.TriggerFunc, e.NewFunc())
+		indexers = &triggerIndexers
 	}
 
 	if e.DeleteCollectionWorkers == 0 {
@@ -1317,10 +1388,55 @@ func (e *Store) CompleteWithOptions(options *generic.StoreOptions) error {
 			e.NewFunc,
 			e.NewListFunc,
 			attrFunc,
-			triggerFunc,
+			indexers,
 		)
 		e.StorageVersioner = opts.StorageConfig.EncodeVersioner
 
+		rewriteResource := &rewrite.Resource{
+			GroupResource:  e.DefaultQualifiedResource,
+			Storage:        e.Storage.Storage,
+			ResourcePrefix: prefix,
+			KeyFunc:        keyFunc,
+			NewFunc:        e.NewFunc,
+			NewListFunc:    e.NewListFunc,
+		}
+		rewrite.Register(rewriteResource)
+		storageDestroy := e.DestroyFunc
+		e.DestroyFunc = func() {
+			rewrite.Unregister(rewriteResource)
+			if storageDestroy != nil {
+				storageDestroy()
+			}
+		}
+
+		if opts.History.Enabled() {
+			var historyDestroy func()
+			e.history, historyDestroy = newHistory(*opts.StorageConfig, prefix, opts.History, e.NewFunc, e.NewListFunc)
+			previousDestroy := e.DestroyFunc
+			e.DestroyFunc = func() {
+				historyDestroy()
+				if previousDestroy != nil {
+					previousDestroy()
+				}
+			}
+		}
+
+		if opts.Trash.Enabled() {
+			var trashDestroy func()
+			e.trash, trashDestroy = newTrash(*opts.StorageConfig, prefix, opts.Trash, keyFunc, e.NewFunc, e.NewListFunc)
+			registerTrash(e)
+			stopPurging := e.startPurgingTrash()
+			previousDestroy := e.DestroyFunc
+			e.DestroyFunc = func() {
+				stopPurging()
+				unregisterTrash(e)
+				trashDestroy()
+				if previousDestroy != nil {
+					previousDestroy()
+				}
+			}
+		}
+
 		if opts.CountMetricPollPeriod > 0 {
 			stopFunc := e.startObservingCount(opts.CountMetricPollPeriod)
 			previousDestroy := e.DestroyFunc
@@ -1336,6 +1452,11 @@ func (e *Store) CompleteWithOptions(options *generic.StoreOptions) error {
 	return nil
 }
 
+// SelectableFields implements rest.SelectableFieldsProvider.
+func (e *Store) SelectableFields() []string {
+	return e.selectableFields
+}
+
 // startObservingCount starts monitoring given prefix and periodically updating metrics. It returns a function to stop collection.
 func (e *Store) startObservingCount(period time.Duration) func() {
 	prefix := e.KeyRootFunc(genericapirequest.NewContext())
@@ -0,0 +1,279 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	kubeerr "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/api/errors"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/api/meta"
+	metav1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime/schema"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/util/validation/field"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/registry/rest"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage"
+	storeerr "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/errors"
+)
+
+// TxnOperation is the creation, update or deletion of an object of a Store
+// that is committed atomically with operations on other objects by Transact.
+type TxnOperation struct {
+	store             *Store
+	name              string
+	qualifiedResource schema.GroupResource
+	op                storage.TxnOp
+	// replaced is the object replaced by an update, recorded in the history
+	// of the store once the transaction is committed.
+	replaced runtime.Object
+}
+
+// PrepareCreate returns the operation creating obj in a transaction. The
+// strategy and createValidation are applied to obj as in Create.
+func (e *Store) PrepareCreate(ctx context.Context, obj runtime.Object, createValidation rest.ValidateObjectFunc) (*TxnOperation, error) {
+	if err := e.checkTransactable(); err != nil {
+		return nil, err
+	}
+	if err := rest.BeforeCreate(e.CreateStrategy, ctx, obj); err != nil {
+		return nil, err
+	}
+	if createValidation != nil {
+		if err := createValidation(obj.DeepCopyObject()); err != nil {
+			return nil, err
+		}
+	}
+	name, err := e.ObjectNameFunc(obj)
+	if err != nil {
+		return nil, err
+	}
+	key, err := e.KeyFunc(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return e.newTxnOperation(ctx, name, storage.TxnOp{Type: storage.TxnCreate, Key: key, Obj: obj}), nil
+}
+
+// PrepareUpdate returns the operation replacing the object called name with
+// obj in a transaction. As in Update, the resourceVersion of obj must be the
+// one of the current object unless the strategy allows unconditional updates,
+// and the strategy and updateValidation are applied to obj. Unlike Update, the
+// object is never created, and the transaction fails if the object is changed
+// before it is committed.
+func (e *Store) PrepareUpdate(ctx context.Context, name string, obj runtime.Object, updateValidation rest.ValidateObjectUpdateFunc) (*TxnOperation, error) {
+	if err := e.checkTransactable(); err != nil {
+		return nil, err
+	}
+	key, err := e.KeyFunc(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	qualifiedResource := e.qualifiedResourceFromContext(ctx)
+	existing := e.NewFunc()
+	if err := e.Storage.Get(ctx, key, "", existing, false); err != nil {
+		return nil, storeerr.InterpretGetError(err, qualifiedResource, name)
+	}
+
+	versioner := e.Storage.Versioner()
+	resourceVersion, err := versioner.ObjectResourceVersion(obj)
+	if err != nil {
+		return nil, err
+	}
+	version, err := versioner.ObjectResourceVersion(existing)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case resourceVersion == 0 && e.UpdateStrategy.AllowUnconditionalUpdate():
+		if err := versioner.UpdateObject(obj, version); err != nil {
+			return nil, err
+		}
+	case resourceVersion == 0:
+		qualifiedKind := schema.GroupKind{Group: qualifiedResource.Group, Kind: qualifiedResource.Resource}
+		fieldErrList := field.ErrorList{field.Invalid(field.NewPath("metadata").Child("resourceVersion"), resourceVersion, "must be specified for an update")}
+		return nil, kubeerr.NewInvalid(qualifiedKind, name, fieldErrList)
+	case resourceVersion != version:
+		return nil, kubeerr.NewConflict(qualifiedResource, name, fmt.Errorf(OptimisticLockErrorMsg))
+	}
+	if err := rest.BeforeUpdate(e.UpdateStrategy, ctx, obj, existing); err != nil {
+		return nil, err
+	}
+	if updateValidation != nil {
+		if err := updateValidation(obj.DeepCopyObject(), existing.DeepCopyObject()); err != nil {
+			return nil, err
+		}
+	}
+	// The object was validated against existing, so the transaction must
+	// fail if it changed in the meantime.
+	currentVersion := strconv.FormatUint(version, 10)
+	op := e.newTxnOperation(ctx, name, storage.TxnOp{
+		Type:          storage.TxnUpdate,
+		Key:           key,
+		Obj:           obj,
+		Preconditions: &storage.Preconditions{ResourceVersion: &currentVersion},
+	})
+	if e.history != nil {
+		op.replaced = existing
+	}
+	return op, nil
+}
+
+// PrepareDelete returns the operation deleting the object called name in a
+// transaction. The preconditions of options and deleteValidation are checked
+// as in Delete. Objects that would be deleted gracefully or that have
+// finalizers cannot be deleted in a transaction, as their deletion is not
+// immediate.
+func (e *Store) PrepareDelete(ctx context.Context, name string, deleteValidation rest.ValidateObjectFunc, options *metav1.DeleteOptions) (*TxnOperation, error) {
+	if err := e.checkTransactable(); err != nil {
+		return nil, err
+	}
+	key, err := e.KeyFunc(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	qualifiedResource := e.qualifiedResourceFromContext(ctx)
+	existing := e.NewFunc()
+	if err := e.Storage.Get(ctx, key, "", existing, false); err != nil {
+		return nil, storeerr.InterpretGetError(err, qualifiedResource, name)
+	}
+	if options == nil {
+		options = metav1.NewDeleteOptions(0)
+	}
+	// BeforeDelete sets the deletion timestamp of objects deleted
+	// gracefully, which must not be visible in existing.
+	graceful, pendingGraceful, err := rest.BeforeDelete(e.DeleteStrategy, ctx, existing.DeepCopyObject(), options)
+	if err != nil {
+		return nil, err
+	}
+	accessor, err := meta.Accessor(existing)
+	if err != nil {
+		return nil, err
+	}
+	if graceful || pendingGraceful || len(accessor.GetFinalizers()) > 0 {
+		return nil, kubeerr.NewBadRequest(fmt.Sprintf("%s %q cannot be deleted immediately and therefore not in a transaction", qualifiedResource.String(), name))
+	}
+	if deleteValidation != nil {
+		if err := deleteValidation(existing.DeepCopyObject()); err != nil {
+			return nil, err
+		}
+	}
+	currentVersion := accessor.GetResourceVersion()
+	preconditions := &storage.Preconditions{ResourceVersion: &currentVersion}
+	if options.Preconditions != nil {
+		preconditions.UID = options.Preconditions.UID
+	}
+	return e.newTxnOperation(ctx, name, storage.TxnOp{Type: storage.TxnDelete, Key: key, Preconditions: preconditions}), nil
+}
+
+func (e *Store) newTxnOperation(ctx context.Context, name string, op storage.TxnOp) *TxnOperation {
+	op.Storage = e.Storage.Storage
+	op.Out = e.NewFunc()
+	return &TxnOperation{
+		store:             e,
+		name:              name,
+		qualifiedResource: e.qualifiedResourceFromContext(ctx),
+		op:                op,
+	}
+}
+
+// checkTransactable returns an error if the objects of e cannot be changed in
+// transactions.
+func (e *Store) checkTransactable() error {
+	if e.TTLFunc != nil {
+		return kubeerr.NewBadRequest(fmt.Sprintf("%s expire and cannot be changed in a transaction", e.DefaultQualifiedResource.String()))
+	}
+	return nil
+}
+
+// interpretError converts an error of the storage operation into an API
+// error.
+func (o *TxnOperation) interpretError(err error) error {
+	switch o.op.Type {
+	case storage.TxnCreate:
+		err = storeerr.InterpretCreateError(err, o.qualifiedResource, o.name)
+		return rest.CheckGeneratedNameError(o.store.CreateStrategy, err, o.op.Obj)
+	case storage.TxnUpdate:
+		return storeerr.InterpretUpdateError(err, o.qualifiedResource, o.name)
+	default:
+		return storeerr.InterpretDeleteError(err, o.qualifiedResource, o.name)
+	}
+}
+
+// Transact atomically commits ops, which must all be stored by the same
+// storage backend, and returns the objects they stored, or the deleted
+// objects for deletions, in the order of ops. If an operation fails, none of
+// them is committed and a *storage.TxnOpError holding the API error of the
+// failed operation is returned.
+func Transact(ctx context.Context, ops []*TxnOperation, dryRun bool) ([]runtime.Object, error) {
+	if len(ops) == 0 {
+		return nil, nil
+	}
+	storageOps := make([]storage.TxnOp, len(ops))
+	for i, op := range ops {
+		storageOps[i] = op.op
+	}
+	if err := ops[0].store.Storage.Transact(ctx, storageOps, dryRun); err != nil {
+		if opErr, ok := err.(*storage.TxnOpError); ok && opErr.Index < len(ops) {
+			return nil, storage.NewTxnOpError(opErr.Index, ops[opErr.Index].interpretError(opErr.Err))
+		}
+		return nil, err
+	}
+
+	// The transaction is committed, so the history and the trash are
+	// recorded and the hooks run for all operations even if one of them
+	// fails.
+	var firstErr error
+	outs := make([]runtime.Object, len(ops))
+	for i, op := range ops {
+		out := op.op.Out
+		outs[i] = out
+		e := op.store
+		var hook ObjectFunc
+		switch op.op.Type {
+		case storage.TxnCreate:
+			if !dryRun {
+				e.recordWrite(ctx, op.op.Key, out)
+			}
+			hook = e.AfterCreate
+		case storage.TxnUpdate:
+			if !dryRun {
+				e.recordUpdate(ctx, op.op.Key, op.replaced, out)
+			}
+			hook = e.AfterUpdate
+		case storage.TxnDelete:
+			if !dryRun {
+				e.recordDeletion(ctx, op.op.Key, out)
+			}
+			hook = e.AfterDelete
+		}
+		if hook != nil {
+			if err := hook(out); err != nil {
+				if firstErr == nil {
+					firstErr = storage.NewTxnOpError(i, err)
+				}
+				continue
+			}
+		}
+		if e.Decorator != nil {
+			if err := e.Decorator(out); err != nil && firstErr == nil {
+				firstErr = storage.NewTxnOpError(i, err)
+			}
+		}
+	}
+	return outs, firstErr
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/core/v1"
+	historyv1alpha1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/history/v1alpha1"
+	metav1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/authentication/user"
+	genericapirequest "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/endpoints/request"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/registry/generic"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/registry/rest"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/storagebackend"
+	storagetesting "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/testing"
+)
+
+func TestTransactHistoryAndTrash(t *testing.T) {
+	testContext := genericapirequest.WithUser(genericapirequest.WithNamespace(genericapirequest.NewContext(), "test"), &user.DefaultInfo{Name: "alice"})
+	destroyFunc, registry := newTestTrashStoreRegistry(t, generic.TrashOptions{Retention: time.Hour})
+	defer destroyFunc()
+	config := storagebackend.Config{
+		Type:  storagebackend.StorageTypeMemory,
+		Codec: storagetesting.Codec,
+	}
+	var historyDestroy func()
+	registry.history, historyDestroy = newHistory(config, "/pods", generic.HistoryOptions{Limit: 10}, registry.NewFunc, registry.NewListFunc)
+	defer historyDestroy()
+
+	pods := map[string]*v1.Pod{}
+	for _, name := range []string{"foo", "bar"} {
+		pod := &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"},
+			Spec:       v1.PodSpec{NodeName: "machine"},
+		}
+		obj, err := registry.Create(testContext, pod, rest.ValidateAllObjectFunc, &metav1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		pods[name] = obj.(*v1.Pod)
+	}
+
+	transact := func(dryRun bool) {
+		updated := pods["foo"].DeepCopy()
+		updated.Spec.NodeName = "machine2"
+		update, err := registry.PrepareUpdate(testContext, "foo", updated, rest.ValidateAllObjectUpdateFunc)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		deletion, err := registry.PrepareDelete(testContext, "bar", rest.ValidateAllObjectFunc, nil)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, err := Transact(testContext, []*TxnOperation{update, deletion}, dryRun); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	// dry-run transactions are not recorded
+	transact(true)
+	if history := getHistory(t, testContext, registry, "foo"); len(history.Revisions) != 0 {
+		t.Errorf("expected no revisions of foo, got %v", history.Revisions)
+	}
+	if tombstones, err := Tombstones(testContext); err != nil || len(tombstones) != 0 {
+		t.Fatalf("expected no tombstones, got %v, %v", tombstones, err)
+	}
+
+	transact(false)
+	history := getHistory(t, testContext, registry, "foo")
+	if len(history.Revisions) != 1 {
+		t.Fatalf("expected 1 revision of foo, got %v", history.Revisions)
+	}
+	if revision := history.Revisions[0]; revision.ResourceVersion != pods["foo"].ResourceVersion || revision.Operation != historyv1alpha1.RevisionOperationUpdate {
+		t.Errorf("unexpected revision of the update: %#v", revision)
+	}
+	history = getHistory(t, testContext, registry, "bar")
+	if len(history.Revisions) != 1 {
+		t.Fatalf("expected 1 revision of bar, got %v", history.Revisions)
+	}
+	if revision := history.Revisions[0]; revision.ResourceVersion != pods["bar"].ResourceVersion || revision.Operation != historyv1alpha1.RevisionOperationDelete {
+		t.Errorf("unexpected revision of the deletion: %#v", revision)
+	}
+	tombstones, err := Tombstones(testContext)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(tombstones) != 1 {
+		t.Fatalf("expected 1 tombstone, got %v", tombstones)
+	}
+	if deleted := tombstones[0].Object.(*v1.Pod); deleted.Name != "bar" || deleted.UID != pods["bar"].UID {
+		t.Errorf("unexpected deleted object: %#v", deleted)
+	}
+}
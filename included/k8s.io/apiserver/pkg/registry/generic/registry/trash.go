@@ -0,0 +1,325 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/api/meta"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime/schema"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/types"
+	utilruntime "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/util/runtime"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/util/wait"
+	genericapirequest "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/endpoints/request"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/registry/generic"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/registry/rest"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/storagebackend"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/klog"
+)
+
+const (
+	// trashKeyPrefix is prepended to the keys of the objects to get the
+	// keys their tombstones are stored under.
+	trashKeyPrefix = "/trash"
+
+	// The metadata of a tombstone is kept in annotations of the stored
+	// object, which are removed when it is read.
+	tombstoneDeletedAtAnnotation = "trash.k8s.io/deleted-at"
+	tombstoneDeletedByAnnotation = "trash.k8s.io/deleted-by"
+
+	// trashPurgePeriod is how often the tombstones past the retention of the
+	// trash are purged.
+	trashPurgePeriod       = time.Minute
+	trashPurgePeriodJitter = 0.5
+)
+
+// trash keeps the objects deleted from a store as tombstones, until they are
+// restored or purged at the end of the retention. Tombstones are stored as
+// objects of the resource, with the codec and transformer of the resource,
+// under the key of their object prefixed by trashKeyPrefix and followed by
+// their UID, so that objects deleted and recreated with the same name are
+// kept apart.
+type trash struct {
+	storage     storage.Interface
+	options     generic.TrashOptions
+	keyFunc     func(runtime.Object) (string, error)
+	newFunc     func() runtime.Object
+	newListFunc func() runtime.Object
+}
+
+// newTrash returns the trash of the objects stored with config under
+// resourcePrefix, whose keys keyFunc returns.
+func newTrash(config storagebackend.Config, resourcePrefix string, options generic.TrashOptions, keyFunc func(runtime.Object) (string, error), newFunc, newListFunc func() runtime.Object) (*trash, func()) {
+	config.ResourcePrefix = trashKeyPrefix + resourcePrefix
+	// The tombstones are not accounted as objects of the resource.
+	config.CountMetricPollPeriod = 0
+	config.NewFunc = newFunc
+	s, destroy := generic.NewRawStorage(&config)
+	return &trash{
+		storage:     s,
+		options:     options,
+		keyFunc:     keyFunc,
+		newFunc:     newFunc,
+		newListFunc: newListFunc,
+	}, destroy
+}
+
+func trashKey(key string) string {
+	return trashKeyPrefix + key
+}
+
+func tombstoneKey(key string, uid types.UID) string {
+	return trashKey(key) + "/" + string(uid)
+}
+
+// record stores obj, the object at key as of its deletion, as a tombstone.
+func (t *trash) record(ctx context.Context, key string, obj runtime.Object) error {
+	tombstone := obj.DeepCopyObject()
+	accessor, err := meta.Accessor(tombstone)
+	if err != nil {
+		return err
+	}
+	annotations := make(map[string]string, len(accessor.GetAnnotations())+2)
+	for k, v := range accessor.GetAnnotations() {
+		annotations[k] = v
+	}
+	annotations[tombstoneDeletedAtAnnotation] = time.Now().UTC().Format(time.RFC3339)
+	if user, ok := genericapirequest.UserFrom(ctx); ok {
+		annotations[tombstoneDeletedByAnnotation] = user.GetName()
+	}
+	accessor.SetAnnotations(annotations)
+	if err := t.storage.Versioner().PrepareObjectForStorage(tombstone); err != nil {
+		return err
+	}
+
+	// An object restored with its UID and deleted again replaces its
+	// previous tombstone, if that was left behind.
+	return t.storage.GuaranteedUpdate(ctx, tombstoneKey(key, accessor.GetUID()), t.newFunc(), true, nil,
+		func(existing runtime.Object, res storage.ResponseMeta) (runtime.Object, *uint64, error) {
+			return tombstone.DeepCopyObject(), nil, nil
+		})
+}
+
+// tombstone is a stored tombstone, without the annotations of its metadata
+// and without resourceVersion, as the object would be restored.
+type tombstone struct {
+	object    runtime.Object
+	uid       types.UID
+	deletedAt time.Time
+	deletedBy string
+	// resourceVersion is the resourceVersion of the tombstone in the trash.
+	resourceVersion string
+}
+
+// purgeAt returns when tb is purged from the trash.
+func (t *trash) purgeAt(tb tombstone) time.Time {
+	return tb.deletedAt.Add(t.options.Retention)
+}
+
+// expired returns whether tb is past the retention of the trash, and can no
+// longer be restored even if it is not purged yet.
+func (t *trash) expired(tb tombstone) bool {
+	return !time.Now().Before(t.purgeAt(tb))
+}
+
+// list returns the tombstones stored under key, including the expired ones,
+// most recently deleted first.
+func (t *trash) list(ctx context.Context, key string) ([]tombstone, error) {
+	list := t.newListFunc()
+	if err := t.storage.List(ctx, trashKey(key), "", storage.Everything, list); err != nil {
+		return nil, err
+	}
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return nil, err
+	}
+	tombstones := make([]tombstone, 0, len(items))
+	for _, item := range items {
+		accessor, err := meta.Accessor(item)
+		if err != nil {
+			return nil, err
+		}
+		annotations := accessor.GetAnnotations()
+		deletedAt, err := time.Parse(time.RFC3339, annotations[tombstoneDeletedAtAnnotation])
+		if err != nil {
+			return nil, fmt.Errorf("invalid tombstone of %s under %s: %v", accessor.GetName(), key, err)
+		}
+		tb := tombstone{
+			object:          item,
+			uid:             accessor.GetUID(),
+			deletedAt:       deletedAt,
+			deletedBy:       annotations[tombstoneDeletedByAnnotation],
+			resourceVersion: accessor.GetResourceVersion(),
+		}
+		delete(annotations, tombstoneDeletedAtAnnotation)
+		delete(annotations, tombstoneDeletedByAnnotation)
+		if len(annotations) == 0 {
+			annotations = nil
+		}
+		accessor.SetAnnotations(annotations)
+		accessor.SetResourceVersion("")
+		tombstones = append(tombstones, tb)
+	}
+	sort.SliceStable(tombstones, func(i, j int) bool {
+		return tombstones[i].deletedAt.After(tombstones[j].deletedAt)
+	})
+	return tombstones, nil
+}
+
+// remove deletes tb, the tombstone of the object at key, unless it was
+// replaced since it was listed.
+func (t *trash) remove(ctx context.Context, key string, tb tombstone) error {
+	preconditions := &storage.Preconditions{ResourceVersion: &tb.resourceVersion}
+	err := t.storage.Delete(ctx, tombstoneKey(key, tb.uid), t.newFunc(), preconditions, rest.ValidateAllObjectFunc)
+	if err != nil && !storage.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// purge deletes the expired tombstones under root, the key of all objects of
+// the store.
+func (t *trash) purge(ctx context.Context, root string) error {
+	tombstones, err := t.list(ctx, root)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, tb := range tombstones {
+		if !t.expired(tb) {
+			continue
+		}
+		key, err := t.keyFunc(tb.object)
+		if err == nil {
+			err = t.remove(ctx, key, tb)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to purge %d of the expired tombstones: %v", len(errs), errs[0])
+	}
+	return nil
+}
+
+// moveToTrash records obj, the object at key as of its deletion, in the
+// trash if the store keeps its deleted objects. Failures are logged, as the
+// deletion already succeeded.
+func (e *Store) moveToTrash(ctx context.Context, key string, obj runtime.Object) {
+	if e.trash == nil || obj == nil {
+		return
+	}
+	if err := e.trash.record(ctx, key, obj); err != nil {
+		utilruntime.HandleError(fmt.Errorf("failed to move %s to the trash: %v", key, err))
+	}
+}
+
+// startPurgingTrash starts purging the expired tombstones of the trash
+// periodically. It returns a function to stop purging.
+func (e *Store) startPurgingTrash() func() {
+	root := e.KeyRootFunc(genericapirequest.NewContext())
+	resourceName := e.DefaultQualifiedResource.String()
+	klog.V(2).Infof("Purging the trash of %v past %v at <storage-prefix>%v", resourceName, e.trash.options.Retention, trashKey(root))
+	stopCh := make(chan struct{})
+	go wait.JitterUntil(func() {
+		if err := e.trash.purge(context.TODO(), root); err != nil {
+			utilruntime.HandleError(fmt.Errorf("failed to purge the trash of %v: %v", resourceName, err))
+		}
+	}, trashPurgePeriod, trashPurgePeriodJitter, true, stopCh)
+	return func() { close(stopCh) }
+}
+
+// Tombstone is an object deleted from a store that keeps it in its trash,
+// where it can be restored by the undelete subresource of its resource.
+type Tombstone struct {
+	// Resource is the resource of the object.
+	Resource schema.GroupResource
+	// Object is the object as of its deletion, without resourceVersion.
+	Object runtime.Object
+	// DeletedBy is the name of the user who deleted the object, if known.
+	DeletedBy string
+	// DeletedAt is when the object was deleted.
+	DeletedAt time.Time
+	// PurgeAt is when the object is purged from the trash.
+	PurgeAt time.Time
+}
+
+var (
+	trashesLock sync.RWMutex
+	// trashes are the stores keeping their deleted objects, by resource.
+	// Only the first store completed for a resource is used, later stores
+	// share the same trash.
+	trashes = map[schema.GroupResource]*Store{}
+)
+
+func registerTrash(e *Store) {
+	trashesLock.Lock()
+	defer trashesLock.Unlock()
+	if _, ok := trashes[e.DefaultQualifiedResource]; !ok {
+		trashes[e.DefaultQualifiedResource] = e
+	}
+}
+
+func unregisterTrash(e *Store) {
+	trashesLock.Lock()
+	defer trashesLock.Unlock()
+	if trashes[e.DefaultQualifiedResource] == e {
+		delete(trashes, e.DefaultQualifiedResource)
+	}
+}
+
+// Tombstones returns the objects kept in the trash of all resources that
+// are not expired, by resource and most recently deleted first.
+func Tombstones(ctx context.Context) ([]Tombstone, error) {
+	trashesLock.RLock()
+	stores := make([]*Store, 0, len(trashes))
+	for _, e := range trashes {
+		stores = append(stores, e)
+	}
+	trashesLock.RUnlock()
+	sort.Slice(stores, func(i, j int) bool {
+		return stores[i].DefaultQualifiedResource.String() < stores[j].DefaultQualifiedResource.String()
+	})
+
+	var result []Tombstone
+	for _, e := range stores {
+		tombstones, err := e.trash.list(ctx, e.KeyRootFunc(genericapirequest.NewContext()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the trash of %v: %v", e.DefaultQualifiedResource, err)
+		}
+		for _, tb := range tombstones {
+			if e.trash.expired(tb) {
+				continue
+			}
+			result = append(result, Tombstone{
+				Resource:  e.DefaultQualifiedResource,
+				Object:    tb.object,
+				DeletedBy: tb.deletedBy,
+				DeletedAt: tb.deletedAt,
+				PurgeAt:   e.trash.purgeAt(tb),
+			})
+		}
+	}
+	return result, nil
+}
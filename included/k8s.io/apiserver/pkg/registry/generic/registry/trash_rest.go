@@ -0,0 +1,155 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"context"
+	"fmt"
+
+	trashv1alpha1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/trash/v1alpha1"
+	kubeerr "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/api/errors"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/api/meta"
+	metav1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime/schema"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/types"
+	utilruntime "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/util/runtime"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/registry/rest"
+	storeerr "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/errors"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/util/dryrun"
+)
+
+var _ rest.TrashProvider = &Store{}
+
+// UndeleteStorage implements rest.TrashProvider.
+func (e *Store) UndeleteStorage() rest.Storage {
+	if e.trash == nil {
+		return nil
+	}
+	return &UndeleteREST{store: e}
+}
+
+// UndeleteREST implements the undelete subresource of a store, which
+// restores a deleted object from the trash.
+type UndeleteREST struct {
+	store *Store
+}
+
+var _ rest.NamedCreater = &UndeleteREST{}
+var _ rest.GroupVersionKindProvider = &UndeleteREST{}
+
+// New implements rest.Storage.
+func (r *UndeleteREST) New() runtime.Object {
+	return &trashv1alpha1.Undelete{}
+}
+
+// GroupVersionKind implements rest.GroupVersionKindProvider.
+func (r *UndeleteREST) GroupVersionKind(containingGV schema.GroupVersion) schema.GroupVersionKind {
+	return trashv1alpha1.SchemeGroupVersion.WithKind("Undelete")
+}
+
+// Create restores the deleted object with the given name from the trash, and
+// returns the status of the restored object, like a deletion does. The
+// restored object, not the Undelete, is admitted by createValidation.
+func (r *UndeleteREST) Create(ctx context.Context, name string, obj runtime.Object, createValidation rest.ValidateObjectFunc, options *metav1.CreateOptions) (runtime.Object, error) {
+	undelete, ok := obj.(*trashv1alpha1.Undelete)
+	if !ok {
+		return nil, kubeerr.NewBadRequest(fmt.Sprintf("not an Undelete: %T", obj))
+	}
+	if len(undelete.Name) > 0 && undelete.Name != name {
+		return nil, kubeerr.NewBadRequest("name in URL does not match name in Undelete object")
+	}
+	out, err := r.store.undelete(ctx, name, undelete.UID, createValidation, dryrun.IsDryRun(options.DryRun))
+	if err != nil {
+		return nil, err
+	}
+	accessor, err := meta.Accessor(out)
+	if err != nil {
+		return nil, err
+	}
+	qualifiedResource := r.store.qualifiedResourceFromContext(ctx)
+	details := &metav1.StatusDetails{
+		Name:  accessor.GetName(),
+		Group: qualifiedResource.Group,
+		Kind:  qualifiedResource.Resource, // Yes we set Kind field to resource.
+		UID:   accessor.GetUID(),
+	}
+	return &metav1.Status{Status: metav1.StatusSuccess, Details: details}, nil
+}
+
+// undelete recreates the object with the given name as of its deletion, with
+// its UID and metadata, from its tombstone in the trash, the one of uid if not
+// empty or else the most recent one. The object is prepared for creation and
+// validated by the create strategy of the store, so that e.g. its status is
+// reset, and admitted by createValidation as in Create.
+func (e *Store) undelete(ctx context.Context, name string, uid types.UID, createValidation rest.ValidateObjectFunc, dryRun bool) (runtime.Object, error) {
+	key, err := e.KeyFunc(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	qualifiedResource := e.qualifiedResourceFromContext(ctx)
+	tombstones, err := e.trash.list(ctx, key)
+	if err != nil {
+		return nil, storeerr.InterpretListError(err, qualifiedResource)
+	}
+	var restored *tombstone
+	for i := range tombstones {
+		if e.trash.expired(tombstones[i]) || (len(uid) > 0 && tombstones[i].uid != uid) {
+			continue
+		}
+		// The tombstones are listed most recently deleted first.
+		restored = &tombstones[i]
+		break
+	}
+	if restored == nil {
+		return nil, kubeerr.NewNotFound(qualifiedResource, name)
+	}
+
+	obj := restored.object
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, err
+	}
+	// BeforeCreate assigns a new UID and creation timestamp, but the object
+	// is restored with its own.
+	restoredUID, creationTimestamp := accessor.GetUID(), accessor.GetCreationTimestamp()
+	if err := rest.BeforeCreate(e.CreateStrategy, ctx, obj); err != nil {
+		return nil, err
+	}
+	accessor.SetUID(restoredUID)
+	accessor.SetCreationTimestamp(creationTimestamp)
+	if createValidation != nil {
+		if err := createValidation(obj.DeepCopyObject()); err != nil {
+			return nil, err
+		}
+	}
+	ttl, err := e.calculateTTL(obj, 0, false)
+	if err != nil {
+		return nil, err
+	}
+	out := e.NewFunc()
+	if err := e.Storage.Create(ctx, key, obj, out, ttl, dryRun); err != nil {
+		return nil, storeerr.InterpretCreateError(err, qualifiedResource, name)
+	}
+	if !dryRun {
+		e.recordWrite(ctx, key, out)
+		if err := e.trash.remove(ctx, key, *restored); err != nil {
+			utilruntime.HandleError(fmt.Errorf("failed to remove the restored %s from the trash: %v", key, err))
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,193 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/core/v1"
+	trashv1alpha1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/trash/v1alpha1"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/api/errors"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/api/meta"
+	metav1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/types"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/authentication/user"
+	genericapirequest "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/endpoints/request"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/registry/generic"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/registry/rest"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/storagebackend"
+	storagetesting "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/testing"
+)
+
+// newTestTrashStoreRegistry returns a store keeping its deleted objects in
+// memory.
+func newTestTrashStoreRegistry(t *testing.T, options generic.TrashOptions) (func(), *Store) {
+	registry := newTestPodStore(t, generic.UndecoratedStorage)
+	config := storagebackend.Config{
+		Type:  storagebackend.StorageTypeMemory,
+		Codec: storagetesting.Codec,
+	}
+	keyFunc := func(obj runtime.Object) (string, error) {
+		accessor, err := meta.Accessor(obj)
+		if err != nil {
+			return "", err
+		}
+		return registry.KeyFunc(genericapirequest.WithNamespace(genericapirequest.NewContext(), accessor.GetNamespace()), accessor.GetName())
+	}
+	var trashDestroy func()
+	registry.trash, trashDestroy = newTrash(config, "/pods", options, keyFunc, registry.NewFunc, registry.NewListFunc)
+	registerTrash(registry)
+	return func() {
+		unregisterTrash(registry)
+		trashDestroy()
+		registry.DestroyFunc()
+	}, registry
+}
+
+func undeleteObject(ctx context.Context, registry *Store, name string, uid string) error {
+	_, err := registry.UndeleteStorage().(rest.NamedCreater).Create(ctx, name, &trashv1alpha1.Undelete{UID: types.UID(uid)}, rest.ValidateAllObjectFunc, &metav1.CreateOptions{})
+	return err
+}
+
+func TestStoreTrash(t *testing.T) {
+	testContext := genericapirequest.WithUser(genericapirequest.WithNamespace(genericapirequest.NewContext(), "test"), &user.DefaultInfo{Name: "alice"})
+	destroyFunc, registry := newTestTrashStoreRegistry(t, generic.TrashOptions{Retention: time.Hour})
+	defer destroyFunc()
+
+	if err := undeleteObject(testContext, registry, "foo", ""); !errors.IsNotFound(err) {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "test", Labels: map[string]string{"app": "web"}},
+		Spec:       v1.PodSpec{NodeName: "machine"},
+	}
+	obj, err := registry.Create(testContext, pod, rest.ValidateAllObjectFunc, &metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	uid := obj.(*v1.Pod).UID
+	// dry-run deletions are not moved to the trash
+	if _, _, err := registry.Delete(testContext, "foo", rest.ValidateAllObjectFunc, &metav1.DeleteOptions{DryRun: []string{metav1.DryRunAll}}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if tombstones, err := Tombstones(testContext); err != nil || len(tombstones) != 0 {
+		t.Fatalf("expected no tombstones, got %v, %v", tombstones, err)
+	}
+	if _, _, err := registry.Delete(testContext, "foo", rest.ValidateAllObjectFunc, nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tombstones, err := Tombstones(testContext)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(tombstones) != 1 {
+		t.Fatalf("expected 1 tombstone, got %v", tombstones)
+	}
+	tombstone := tombstones[0]
+	if tombstone.Resource != registry.DefaultQualifiedResource || tombstone.DeletedBy != "alice" || tombstone.PurgeAt.Sub(tombstone.DeletedAt) != time.Hour {
+		t.Errorf("unexpected tombstone: %#v", tombstone)
+	}
+	if deleted := tombstone.Object.(*v1.Pod); deleted.UID != uid || len(deleted.Annotations) != 0 || len(deleted.ResourceVersion) != 0 {
+		t.Errorf("unexpected deleted object: %#v", deleted)
+	}
+
+	if err := undeleteObject(testContext, registry, "foo", "other"); !errors.IsNotFound(err) {
+		t.Errorf("expected no deleted object with another UID, got %v", err)
+	}
+	if err := undeleteObject(testContext, registry, "foo", ""); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	obj, err = registry.Get(testContext, "foo", &metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if restored := obj.(*v1.Pod); restored.UID != uid || restored.Labels["app"] != "web" || restored.Spec.NodeName != "machine" {
+		t.Errorf("unexpected restored object: %#v", restored)
+	}
+	if tombstones, err := Tombstones(testContext); err != nil || len(tombstones) != 0 {
+		t.Errorf("expected the restored object to be removed from the trash, got %v, %v", tombstones, err)
+	}
+	if err := undeleteObject(testContext, registry, "foo", ""); !errors.IsNotFound(err) {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	// expired objects are neither listed nor restored, and purged
+	if _, _, err := registry.Delete(testContext, "foo", rest.ValidateAllObjectFunc, nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	registry.trash.options.Retention = time.Nanosecond
+	if tombstones, err := Tombstones(testContext); err != nil || len(tombstones) != 0 {
+		t.Errorf("expected no tombstones past the retention, got %v, %v", tombstones, err)
+	}
+	if err := undeleteObject(testContext, registry, "foo", ""); !errors.IsNotFound(err) {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	root := registry.KeyRootFunc(genericapirequest.NewContext())
+	if err := registry.trash.purge(testContext, root); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if stored, err := registry.trash.list(testContext, root); err != nil || len(stored) != 0 {
+		t.Errorf("expected the trash to be purged, got %v, %v", stored, err)
+	}
+}
+
+func TestStoreUndeleteAdmission(t *testing.T) {
+	testContext := genericapirequest.WithUser(genericapirequest.WithNamespace(genericapirequest.NewContext(), "test"), &user.DefaultInfo{Name: "alice"})
+	destroyFunc, registry := newTestTrashStoreRegistry(t, generic.TrashOptions{Retention: time.Hour})
+	defer destroyFunc()
+
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "test", Labels: map[string]string{"app": "web"}},
+		Spec:       v1.PodSpec{NodeName: "machine"},
+	}
+	obj, err := registry.Create(testContext, pod, rest.ValidateAllObjectFunc, &metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	uid := obj.(*v1.Pod).UID
+	if _, _, err := registry.Delete(testContext, "foo", rest.ValidateAllObjectFunc, nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// admission sees the restored object and may reject it
+	var admitted runtime.Object
+	rejectWeb := func(obj runtime.Object) error {
+		admitted = obj
+		if obj.(*v1.Pod).Labels["app"] == "web" {
+			return errors.NewForbidden(registry.DefaultQualifiedResource, "foo", nil)
+		}
+		return nil
+	}
+	_, err = registry.UndeleteStorage().(rest.NamedCreater).Create(testContext, "foo", &trashv1alpha1.Undelete{}, rejectWeb, &metav1.CreateOptions{})
+	if !errors.IsForbidden(err) {
+		t.Fatalf("expected the undelete to be forbidden, got %v", err)
+	}
+	if restored, ok := admitted.(*v1.Pod); !ok || restored.UID != uid || restored.Spec.NodeName != "machine" {
+		t.Errorf("expected the restored object to be admitted, got %#v", admitted)
+	}
+	if _, err := registry.Get(testContext, "foo", &metav1.GetOptions{}); !errors.IsNotFound(err) {
+		t.Errorf("expected the rejected object not to be restored, got %v", err)
+	}
+	if tombstones, err := Tombstones(testContext); err != nil || len(tombstones) != 1 {
+		t.Errorf("expected the rejected object to stay in the trash, got %v, %v", tombstones, err)
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/storagebackend"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/storagebackend/factory"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/client-go/tools/cache"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/klog"
 )
 
@@ -33,7 +34,7 @@ type StorageDecorator func(
 	newFunc func() runtime.Object,
 	newListFunc func() runtime.Object,
 	getAttrsFunc storage.AttrFunc,
-	trigger storage.TriggerPublisherFunc) (storage.Interface, factory.DestroyFunc)
+	indexers *cache.Indexers) (storage.Interface, factory.DestroyFunc)
 
 // UndecoratedStorage returns the given a new storage from the given config
 // without any decoration.
@@ -44,8 +45,10 @@ func UndecoratedStorage(
 	newFunc func() runtime.Object,
 	newListFunc func() runtime.Object,
 	getAttrsFunc storage.AttrFunc,
-	trigger storage.TriggerPublisherFunc) (storage.Interface, factory.DestroyFunc) {
-	return NewRawStorage(config)
+	indexers *cache.Indexers) (storage.Interface, factory.DestroyFunc) {
+	rawConfig := *config
+	rawConfig.NewFunc = newFunc
+	return NewRawStorage(&rawConfig)
 }
 
 // NewRawStorage creates the low level kv storage. This is a work-around for current
@@ -343,3 +343,32 @@ type StorageVersionProvider interface {
 	// list of kinds the object might belong to.
 	StorageVersion() runtime.GroupVersioner
 }
+
+// HistoryProvider is an optional interface that a storage object can
+// implement if it keeps the history of its objects, which the server serves
+// as the history subresource of the resource.
+type HistoryProvider interface {
+	// HistoryStorage returns the storage of the history subresource, which
+	// converts the revisions of the objects to gv with convertor, or nil if
+	// the history is not kept.
+	HistoryStorage(convertor runtime.ObjectConvertor, gv schema.GroupVersion) Storage
+}
+
+// TrashProvider is an optional interface that a storage object can implement
+// if it keeps its deleted objects in a trash, which the server serves the
+// undelete subresource of the resource for.
+type TrashProvider interface {
+	// UndeleteStorage returns the storage of the undelete subresource, which
+	// restores the deleted objects, or nil if the deleted objects are not
+	// kept.
+	UndeleteStorage() Storage
+}
+
+// SelectableFieldsProvider is an optional interface that a storage object can
+// implement if field selectors may select its objects by JSON paths, in
+// addition to the fields that the field label conversion of their kind
+// supports.
+type SelectableFieldsProvider interface {
+	// SelectableFields returns the selectable JSON paths, e.g. spec.replicas.
+	SelectableFields() []string
+}
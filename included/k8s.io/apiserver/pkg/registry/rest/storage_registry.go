@@ -0,0 +1,95 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"sync"
+
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime/schema"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/admission"
+)
+
+// RegisteredStorage is the storage of a resource together with what is needed
+// to act on its objects outside of the handlers of the resource.
+type RegisteredStorage struct {
+	Storage Storage
+	// Kind is the kind of the objects of the resource in the version it
+	// was registered for.
+	Kind            schema.GroupVersionKind
+	NamespaceScoped bool
+	// Serializer encodes and decodes the objects of the resource.
+	Serializer runtime.NegotiatedSerializer
+	// HubGroupVersion is the version of the objects handled by Storage.
+	HubGroupVersion schema.GroupVersion
+	// ObjectInterfaces are passed to admission for the objects of the
+	// resource.
+	ObjectInterfaces admission.ObjectInterfaces
+}
+
+// StorageResolverFunc returns the storage of resource, or nil if it is not
+// known. It is used for resources that are served without being registered,
+// like custom resources.
+type StorageResolverFunc func(resource schema.GroupVersionResource) (*RegisteredStorage, error)
+
+// StorageRegistry holds the storages of the resources served by all servers
+// of a delegation chain, so that a resource can act on the objects of other
+// resources, e.g. to change them in a transaction.
+type StorageRegistry struct {
+	lock      sync.RWMutex
+	storages  map[schema.GroupVersionResource]*RegisteredStorage
+	resolvers []StorageResolverFunc
+}
+
+// NewStorageRegistry returns an empty StorageRegistry.
+func NewStorageRegistry() *StorageRegistry {
+	return &StorageRegistry{storages: map[schema.GroupVersionResource]*RegisteredStorage{}}
+}
+
+// Register registers storage as the storage of resource.
+func (r *StorageRegistry) Register(resource schema.GroupVersionResource, storage *RegisteredStorage) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.storages[resource] = storage
+}
+
+// AddResolver adds a resolver that is asked for the storages of resources
+// that are not registered.
+func (r *StorageRegistry) AddResolver(resolver StorageResolverFunc) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.resolvers = append(r.resolvers, resolver)
+}
+
+// Storage returns the storage of resource, or nil if neither a storage is
+// registered for it nor a resolver knows it.
+func (r *StorageRegistry) Storage(resource schema.GroupVersionResource) (*RegisteredStorage, error) {
+	r.lock.RLock()
+	storage, ok := r.storages[resource]
+	resolvers := r.resolvers
+	r.lock.RUnlock()
+	if ok {
+		return storage, nil
+	}
+	for _, resolver := range resolvers {
+		storage, err := resolver(resource)
+		if err != nil || storage != nil {
+			return storage, err
+		}
+	}
+	return nil, nil
+}
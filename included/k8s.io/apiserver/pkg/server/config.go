@@ -56,6 +56,7 @@ import (
 	apirequest "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/endpoints/request"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/features"
 	genericregistry "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/registry/generic"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/registry/rest"
 	genericfilters "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/server/filters"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/server/healthz"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/server/routes"
@@ -109,6 +110,9 @@ type Config struct {
 	// Requires generic profiling enabled
 	EnableContentionProfiling bool
 	EnableMetrics             bool
+	// EnableCacheCheck serves the results of the watch cache consistency
+	// checks at /debug/cachecheck.
+	EnableCacheCheck bool
 
 	DisabledPostStartHooks sets.String
 
@@ -193,6 +197,10 @@ type Config struct {
 	// EquivalentResourceRegistry provides information about resources equivalent to a given resource,
 	// and the kind associated with a given resource. As resources are installed, they are registered here.
 	EquivalentResourceRegistry runtime.EquivalentResourceRegistry
+
+	// StorageRegistry holds the storages of the installed resources. As resources are installed, they
+	// are registered here. It is shared by the configs of a delegation chain copied from this one.
+	StorageRegistry *rest.StorageRegistry
 }
 
 type RecommendedConfig struct {
@@ -292,6 +300,8 @@ func NewConfig(codecs serializer.CodecFactory) *Config {
 		// Default to treating watch as a long-running operation
 		// Generic API servers have no inherent long-running subresources
 		LongRunningFunc: genericfilters.BasicLongRunningRequestCheck(sets.NewString("watch"), sets.NewString()),
+
+		StorageRegistry: rest.NewStorageRegistry(),
 	}
 }
 
@@ -475,6 +485,7 @@ func (c completedConfig) New(name string, delegationTarget DelegationTarget) (*G
 		Authorizer:                 c.Authorization.Authorizer,
 		delegationTarget:           delegationTarget,
 		EquivalentResourceRegistry: c.EquivalentResourceRegistry,
+		StorageRegistry:            c.StorageRegistry,
 		HandlerChainWaitGroup:      c.HandlerChainWaitGroup,
 
 		minRequestTimeout: time.Duration(c.MinRequestTimeout) * time.Second,
@@ -591,7 +602,12 @@ func installAPI(s *GenericAPIServer, c *Config) {
 		}
 		// so far, only logging related endpoints are considered valid to add for these debug flags.
 		routes.DebugFlags{}.Install(s.Handler.NonGoRestfulMux, "v", routes.StringFlagPutHandler(logs.GlogSetter))
+		routes.StorageFaults{}.Install(s.Handler.NonGoRestfulMux)
+	}
+	if c.EnableCacheCheck {
+		routes.CacheCheck{}.Install(s.Handler.NonGoRestfulMux)
 	}
+	routes.ReencryptionStatus{}.Install(s.Handler.NonGoRestfulMux)
 	if c.EnableMetrics {
 		if c.EnableProfiling {
 			routes.MetricsWithReset{}.Install(s.Handler.NonGoRestfulMux)
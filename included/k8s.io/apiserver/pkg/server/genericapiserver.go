@@ -28,11 +28,14 @@ import (
 	"github.com/aaron-prindle/krmapiserver/included/github.com/go-openapi/spec"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/klog"
 
+	historyv1alpha1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/history/v1alpha1"
+	trashv1alpha1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/trash/v1alpha1"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/api/meta"
 	metav1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime/schema"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime/serializer"
+	utilruntime "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/util/runtime"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/util/sets"
 	utilwaitgroup "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/util/waitgroup"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/admission"
@@ -161,6 +164,10 @@ type GenericAPIServer struct {
 	// and the kind associated with a given resource. As resources are installed, they are registered here.
 	EquivalentResourceRegistry runtime.EquivalentResourceRegistry
 
+	// StorageRegistry holds the storages of the installed resources. As resources are installed,
+	// they are registered here.
+	StorageRegistry *rest.StorageRegistry
+
 	// enableAPIResponseCompression indicates whether API Responses should support compression
 	// if the client requests it via Accept-Encoding
 	enableAPIResponseCompression bool
@@ -451,6 +458,29 @@ func (s *GenericAPIServer) getAPIGroupVersion(apiGroupInfo *APIGroupInfo, groupV
 	for k, v := range apiGroupInfo.VersionedResourcesStorageMap[groupVersion.Version] {
 		storage[strings.ToLower(k)] = v
 	}
+	// Serve the history subresource of the resources whose history is kept.
+	for k, v := range apiGroupInfo.VersionedResourcesStorageMap[groupVersion.Version] {
+		provider, ok := v.(rest.HistoryProvider)
+		if !ok || strings.Contains(k, "/") {
+			continue
+		}
+		if history := provider.HistoryStorage(apiGroupInfo.Scheme, groupVersion); history != nil {
+			utilruntime.Must(historyv1alpha1.AddToScheme(apiGroupInfo.Scheme))
+			storage[strings.ToLower(k)+"/history"] = history
+		}
+	}
+	// Serve the undelete subresource of the resources whose deleted objects
+	// are kept in the trash.
+	for k, v := range apiGroupInfo.VersionedResourcesStorageMap[groupVersion.Version] {
+		provider, ok := v.(rest.TrashProvider)
+		if !ok || strings.Contains(k, "/") {
+			continue
+		}
+		if undelete := provider.UndeleteStorage(); undelete != nil {
+			utilruntime.Must(trashv1alpha1.AddToScheme(apiGroupInfo.Scheme))
+			storage[strings.ToLower(k)+"/undelete"] = undelete
+		}
+	}
 	version := s.newAPIGroupVersion(apiGroupInfo, groupVersion)
 	version.Root = apiPrefix
 	version.Storage = storage
@@ -472,6 +502,7 @@ func (s *GenericAPIServer) newAPIGroupVersion(apiGroupInfo *APIGroupInfo, groupV
 		Linker:          runtime.SelfLinker(meta.NewAccessor()),
 
 		EquivalentResourceRegistry: s.EquivalentResourceRegistry,
+		StorageRegistry:            s.StorageRegistry,
 
 		Admit:                        s.admissionControl,
 		MinRequestTimeout:            s.minRequestTimeout,
@@ -19,21 +19,27 @@ package encryptionconfig
 import (
 	"crypto/aes"
 	"crypto/cipher"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
 	"time"
 
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/klog"
+
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime/schema"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime/serializer"
 	apiserverconfig "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/apis/config"
 	apiserverconfigv1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/apis/config/v1"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/value"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/value/compression"
 	aestransformer "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/value/encrypt/aes"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/value/encrypt/envelope"
+	hkdftransformer "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/value/encrypt/hkdf"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/value/encrypt/identity"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/value/encrypt/secretbox"
 )
@@ -43,7 +49,15 @@ const (
 	aesGCMTransformerPrefixV1    = "k8s:enc:aesgcm:v1:"
 	secretboxTransformerPrefixV1 = "k8s:enc:secretbox:v1:"
 	kmsTransformerPrefixV1       = "k8s:enc:kms:v1:"
+	kmsTransformerPrefixV2       = "k8s:enc:kms:v2:"
+	hkdfTransformerPrefixV1      = "k8s:enc:hkdf:v1:"
 	kmsPluginConnectionTimeout   = 3 * time.Second
+
+	hkdfCipherAESGCM           = "aesgcm"
+	hkdfCipherChaCha20Poly1305 = "chacha20poly1305"
+
+	kmsAPIVersionV1 = "v1"
+	kmsAPIVersionV2 = "v2"
 )
 
 // GetTransformerOverrides returns the transformer overrides by reading and parsing the encryption provider configuration file
@@ -68,34 +82,136 @@ func ParseEncryptionConfiguration(f io.Reader) (map[schema.GroupResource]value.T
 		return nil, fmt.Errorf("could not read contents: %v", err)
 	}
 
+	parsed, err := parseEncryptionConfiguration(configFileContents, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[schema.GroupResource]value.Transformer{}
+	for gr, transformer := range parsed.transformers {
+		result[gr] = value.NewMutableTransformer(transformer)
+	}
+	return result, nil
+}
+
+// kmsCheck reports the health of a KMS provider used by a resource.
+type kmsCheck struct {
+	name    string
+	checker envelope.StatusChecker
+}
+
+// parsedConfiguration is the result of parsing configuration data.
+type parsedConfiguration struct {
+	// transformers is the prefix transformer of every configured resource
+	// whose providers were created.
+	transformers map[schema.GroupResource]value.Transformer
+	// hashes is a hash of the providers of every configured resource. The
+	// hash of a resource changes whenever its transformer would encrypt or
+	// decrypt differently.
+	hashes map[schema.GroupResource]string
+	// kmsChecks are the KMS providers of every resource that report their
+	// health.
+	kmsChecks map[schema.GroupResource][]kmsCheck
+	// closers are the connections to the KMS providers of every resource. A
+	// connection is shared by the resources configured together.
+	closers map[schema.GroupResource][]io.Closer
+}
+
+// parseEncryptionConfiguration parses configuration data and returns the
+// transformers of the configured resources. The providers of the resources
+// whose hash is the same in unchanged are not created, so that the KMS
+// providers are only connected to for the resources that need new ones.
+func parseEncryptionConfiguration(configFileContents []byte, unchanged map[schema.GroupResource]string) (*parsedConfiguration, error) {
 	config, err := loadConfig(configFileContents)
 	if err != nil {
 		return nil, fmt.Errorf("error while parsing file: %v", err)
 	}
 
+	resourceToProviders := map[schema.GroupResource][]apiserverconfig.ProviderConfiguration{}
+	resourceToCompression := map[schema.GroupResource]*apiserverconfig.CompressionConfiguration{}
+	for _, resourceConfig := range config.Resources {
+		for _, resource := range resourceConfig.Resources {
+			gr := schema.ParseGroupResource(resource)
+			resourceToProviders[gr] = append(resourceToProviders[gr], resourceConfig.Providers...)
+			if resourceToCompression[gr] == nil {
+				resourceToCompression[gr] = resourceConfig.Compression
+			}
+		}
+	}
+	hashes := map[schema.GroupResource]string{}
+	for gr, providers := range resourceToProviders {
+		data, err := json.Marshal(struct {
+			Providers   []apiserverconfig.ProviderConfiguration
+			Compression *apiserverconfig.CompressionConfiguration
+		}{providers, resourceToCompression[gr]})
+		if err != nil {
+			return nil, err
+		}
+		hashes[gr] = fmt.Sprintf("%x", sha256.Sum256(data))
+	}
+
 	resourceToPrefixTransformer := map[schema.GroupResource][]value.PrefixTransformer{}
+	kmsChecks := map[schema.GroupResource][]kmsCheck{}
+	closers := map[schema.GroupResource][]io.Closer{}
+	var allClosers []io.Closer
 
 	// For each entry in the configuration
 	for _, resourceConfig := range config.Resources {
-		transformers, err := GetPrefixTransformers(&resourceConfig)
+		var resources []schema.GroupResource
+		for _, resource := range resourceConfig.Resources {
+			gr := schema.ParseGroupResource(resource)
+			if hash, ok := unchanged[gr]; !ok || hash != hashes[gr] {
+				resources = append(resources, gr)
+			}
+		}
+		if len(resources) == 0 {
+			continue
+		}
+
+		transformers, entryClosers, err := getPrefixTransformers(&resourceConfig)
 		if err != nil {
+			closeAll(allClosers)
 			return nil, err
 		}
+		allClosers = append(allClosers, entryClosers...)
+		var checks []kmsCheck
+		for i, provider := range resourceConfig.Providers {
+			if checker, ok := transformers[i].Transformer.(envelope.StatusChecker); ok && provider.KMS != nil {
+				checks = append(checks, kmsCheck{name: provider.KMS.Name, checker: checker})
+			}
+		}
 
 		// For each resource, create a list of providers to use
-		for _, resource := range resourceConfig.Resources {
-			gr := schema.ParseGroupResource(resource)
+		for _, gr := range resources {
 			resourceToPrefixTransformer[gr] = append(
 				resourceToPrefixTransformer[gr], transformers...)
+			kmsChecks[gr] = append(kmsChecks[gr], checks...)
+			closers[gr] = append(closers[gr], entryClosers...)
 		}
 	}
 
-	result := map[schema.GroupResource]value.Transformer{}
+	transformers := map[schema.GroupResource]value.Transformer{}
 	for gr, transList := range resourceToPrefixTransformer {
-		result[gr] = value.NewMutableTransformer(value.NewPrefixTransformers(fmt.Errorf("no matching prefix found"), transList...))
+		// Values are compressed before, and decompressed after, the providers.
+		// Resources without compression still read values compressed earlier.
+		transformer, err := getCompressionTransformer(resourceToCompression[gr],
+			value.NewPrefixTransformers(fmt.Errorf("no matching prefix found"), transList...), gr)
+		if err != nil {
+			closeAll(allClosers)
+			return nil, err
+		}
+		transformers[gr] = transformer
 	}
-	return result, nil
+	return &parsedConfiguration{transformers: transformers, hashes: hashes, kmsChecks: kmsChecks, closers: closers}, nil
+}
 
+// closeAll closes the connections to KMS providers in closers.
+func closeAll(closers []io.Closer) {
+	for _, closer := range closers {
+		if err := closer.Close(); err != nil {
+			klog.Warningf("Failed to close the connection to a KMS provider: %v", err)
+		}
+	}
 }
 
 // loadConfig decodes data as a EncryptionConfiguration object.
@@ -116,12 +232,26 @@ func loadConfig(data []byte) (*apiserverconfig.EncryptionConfiguration, error) {
 	return config, nil
 }
 
-// The factory to create kms service. This is to make writing test easier.
-var envelopeServiceFactory = envelope.NewGRPCService
+// The factories to create kms services. This is to make writing test easier.
+var (
+	envelopeServiceFactory   = envelope.NewGRPCService
+	envelopeServiceV2Factory = envelope.NewGRPCServiceV2
+)
 
 // GetPrefixTransformers constructs and returns the appropriate prefix transformers for the passed resource using its configuration.
 func GetPrefixTransformers(config *apiserverconfig.ResourceConfiguration) ([]value.PrefixTransformer, error) {
-	var result []value.PrefixTransformer
+	result, _, err := getPrefixTransformers(config)
+	return result, err
+}
+
+// getPrefixTransformers is GetPrefixTransformers, but also returns the
+// connections to the KMS providers, which are closed if it fails.
+func getPrefixTransformers(config *apiserverconfig.ResourceConfiguration) (result []value.PrefixTransformer, closers []io.Closer, err error) {
+	defer func() {
+		if err != nil {
+			closeAll(closers)
+		}
+	}()
 	for _, provider := range config.Providers {
 		found := false
 
@@ -131,14 +261,14 @@ func GetPrefixTransformers(config *apiserverconfig.ResourceConfiguration) ([]val
 		if provider.AESGCM != nil {
 			transformer, err = GetAESPrefixTransformer(provider.AESGCM, aestransformer.NewGCMTransformer, aesGCMTransformerPrefixV1)
 			if err != nil {
-				return result, err
+				return result, closers, err
 			}
 			found = true
 		}
 
 		if provider.AESCBC != nil {
 			if found == true {
-				return result, fmt.Errorf("more than one provider specified in a single element, should split into different list elements")
+				return result, closers, fmt.Errorf("more than one provider specified in a single element, should split into different list elements")
 			}
 			transformer, err = GetAESPrefixTransformer(provider.AESCBC, aestransformer.NewCBCTransformer, aesCBCTransformerPrefixV1)
 			found = true
@@ -146,7 +276,7 @@ func GetPrefixTransformers(config *apiserverconfig.ResourceConfiguration) ([]val
 
 		if provider.Secretbox != nil {
 			if found == true {
-				return result, fmt.Errorf("more than one provider specified in a single element, should split into different list elements")
+				return result, closers, fmt.Errorf("more than one provider specified in a single element, should split into different list elements")
 			}
 			transformer, err = GetSecretboxPrefixTransformer(provider.Secretbox)
 			found = true
@@ -154,7 +284,7 @@ func GetPrefixTransformers(config *apiserverconfig.ResourceConfiguration) ([]val
 
 		if provider.Identity != nil {
 			if found == true {
-				return result, fmt.Errorf("more than one provider specified in a single element, should split into different list elements")
+				return result, closers, fmt.Errorf("more than one provider specified in a single element, should split into different list elements")
 			}
 			transformer = value.PrefixTransformer{
 				Transformer: identity.NewEncryptCheckTransformer(),
@@ -165,42 +295,72 @@ func GetPrefixTransformers(config *apiserverconfig.ResourceConfiguration) ([]val
 
 		if provider.KMS != nil {
 			if found == true {
-				return nil, fmt.Errorf("more than one provider specified in a single element, should split into different list elements")
+				return nil, closers, fmt.Errorf("more than one provider specified in a single element, should split into different list elements")
 			}
 
 			// Ensure the endpoint is provided.
 			if len(provider.KMS.Endpoint) == 0 {
-				return nil, fmt.Errorf("remote KMS provider can't use empty string as endpoint")
+				return nil, closers, fmt.Errorf("remote KMS provider can't use empty string as endpoint")
 			}
 
 			timeout := kmsPluginConnectionTimeout
 			if provider.KMS.Timeout != nil {
 				if provider.KMS.Timeout.Duration <= 0 {
-					return nil, fmt.Errorf("could not configure KMS plugin %q, timeout should be a positive value", provider.KMS.Name)
+					return nil, closers, fmt.Errorf("could not configure KMS plugin %q, timeout should be a positive value", provider.KMS.Name)
 				}
 				timeout = provider.KMS.Timeout.Duration
 			}
 
-			// Get gRPC client service with endpoint.
-			envelopeService, err := envelopeServiceFactory(provider.KMS.Endpoint, timeout)
-			if err != nil {
-				return nil, fmt.Errorf("could not configure KMS plugin %q, error: %v", provider.KMS.Name, err)
+			switch provider.KMS.APIVersion {
+			case "", kmsAPIVersionV1:
+				// Get gRPC client service with endpoint.
+				envelopeService, err := envelopeServiceFactory(provider.KMS.Endpoint, timeout)
+				if err != nil {
+					return nil, closers, fmt.Errorf("could not configure KMS plugin %q, error: %v", provider.KMS.Name, err)
+				}
+				if closer, ok := envelopeService.(io.Closer); ok {
+					closers = append(closers, closer)
+				}
+				transformer, err = getEnvelopePrefixTransformer(provider.KMS, envelopeService, kmsTransformerPrefixV1)
+				if err != nil {
+					return nil, closers, err
+				}
+			case kmsAPIVersionV2:
+				envelopeService, err := envelopeServiceV2Factory(provider.KMS.Endpoint, timeout)
+				if err != nil {
+					return nil, closers, fmt.Errorf("could not configure KMS plugin %q, error: %v", provider.KMS.Name, err)
+				}
+				if closer, ok := envelopeService.(io.Closer); ok {
+					closers = append(closers, closer)
+				}
+				transformer, err = getEnvelopeV2PrefixTransformer(provider.KMS, envelopeService, kmsTransformerPrefixV2)
+				if err != nil {
+					return nil, closers, err
+				}
+			default:
+				return nil, closers, fmt.Errorf("could not configure KMS plugin %q, unsupported apiVersion %q", provider.KMS.Name, provider.KMS.APIVersion)
 			}
+			found = true
+		}
 
-			transformer, err = getEnvelopePrefixTransformer(provider.KMS, envelopeService, kmsTransformerPrefixV1)
+		if provider.HKDF != nil {
+			if found == true {
+				return nil, closers, fmt.Errorf("more than one provider specified in a single element, should split into different list elements")
+			}
+			transformer, err = GetHKDFPrefixTransformer(provider.HKDF)
 			found = true
 		}
 
 		if err != nil {
-			return result, err
+			return result, closers, err
 		}
 		result = append(result, transformer)
 
 		if found == false {
-			return result, fmt.Errorf("invalid provider configuration: at least one provider must be specified")
+			return result, closers, fmt.Errorf("invalid provider configuration: at least one provider must be specified")
 		}
 	}
-	return result, nil
+	return result, closers, nil
 }
 
 // BlockTransformerFunc takes an AES cipher block and returns a value transformer.
@@ -306,6 +466,69 @@ func GetSecretboxPrefixTransformer(config *apiserverconfig.SecretboxConfiguratio
 	return result, nil
 }
 
+// GetHKDFPrefixTransformer returns a prefix transformer from the provided configuration.
+func GetHKDFPrefixTransformer(config *apiserverconfig.HKDFConfiguration) (value.PrefixTransformer, error) {
+	var result value.PrefixTransformer
+
+	cipherName := config.Cipher
+	if len(cipherName) == 0 {
+		cipherName = hkdfCipherAESGCM
+	}
+	var newTransformer func(masterKey []byte) (value.Transformer, error)
+	switch cipherName {
+	case hkdfCipherAESGCM:
+		newTransformer = hkdftransformer.NewAESGCMTransformer
+	case hkdfCipherChaCha20Poly1305:
+		newTransformer = hkdftransformer.NewChaCha20Poly1305Transformer
+	default:
+		return result, fmt.Errorf("hkdf provider has unsupported cipher %q, must be %q or %q", config.Cipher, hkdfCipherAESGCM, hkdfCipherChaCha20Poly1305)
+	}
+
+	if len(config.Keys) == 0 {
+		return result, fmt.Errorf("hkdf provider has no valid keys")
+	}
+	for _, key := range config.Keys {
+		if key.Name == "" {
+			return result, fmt.Errorf("key with invalid name provided")
+		}
+		if key.Secret == "" {
+			return result, fmt.Errorf("key %v has no provided secret", key.Name)
+		}
+	}
+
+	keyTransformers := []value.PrefixTransformer{}
+
+	for _, keyData := range config.Keys {
+		key, err := base64.StdEncoding.DecodeString(keyData.Secret)
+		if err != nil {
+			return result, fmt.Errorf("could not obtain secret for named key %s: %s", keyData.Name, err)
+		}
+		transformer, err := newTransformer(key)
+		if err != nil {
+			return result, fmt.Errorf("error while creating transformer for named key %s: %s", keyData.Name, err)
+		}
+
+		// Create a new PrefixTransformer for this key
+		keyTransformers = append(keyTransformers,
+			value.PrefixTransformer{
+				Transformer: transformer,
+				Prefix:      []byte(keyData.Name + ":"),
+			})
+	}
+
+	// Create a prefixTransformer which can choose between these keys
+	keyTransformer := value.NewPrefixTransformers(
+		fmt.Errorf("no matching key was found for the provided HKDF transformer"), keyTransformers...)
+
+	// Create a PrefixTransformer which shall later be put in a list with other providers. The cipher is part
+	// of the prefix, so that values written with another cipher are still read after the cipher is changed.
+	result = value.PrefixTransformer{
+		Transformer: keyTransformer,
+		Prefix:      []byte(hkdfTransformerPrefixV1 + cipherName + ":"),
+	}
+	return result, nil
+}
+
 // getEnvelopePrefixTransformer returns a prefix transformer from the provided config.
 // envelopeService is used as the root of trust.
 func getEnvelopePrefixTransformer(config *apiserverconfig.KMSConfiguration, envelopeService envelope.Service, prefix string) (value.PrefixTransformer, error) {
@@ -318,3 +541,40 @@ func getEnvelopePrefixTransformer(config *apiserverconfig.KMSConfiguration, enve
 		Prefix:      []byte(prefix + config.Name + ":"),
 	}, nil
 }
+
+// getEnvelopeV2PrefixTransformer returns a prefix transformer from the provided config
+// using the v2 KMS protocol. envelopeService is used as the root of trust.
+func getEnvelopeV2PrefixTransformer(config *apiserverconfig.KMSConfiguration, envelopeService envelope.ServiceV2, prefix string) (value.PrefixTransformer, error) {
+	envelopeTransformer, err := envelope.NewEnvelopeTransformerV2(envelopeService, int(config.CacheSize), aestransformer.NewGCMTransformer)
+	if err != nil {
+		return value.PrefixTransformer{}, err
+	}
+	return value.PrefixTransformer{
+		Transformer: envelopeTransformer,
+		Prefix:      []byte(prefix + config.Name + ":"),
+	}, nil
+}
+
+// getCompressionTransformer returns a transformer compressing values of gr
+// before they are passed to delegate, as configured by config. Values are not
+// compressed if config is nil, but values compressed earlier are read.
+func getCompressionTransformer(config *apiserverconfig.CompressionConfiguration, delegate value.Transformer, gr schema.GroupResource) (value.Transformer, error) {
+	algorithm, minSize, maxSize := "", compression.DefaultMinSize, compression.DefaultMaxSize
+	if config != nil {
+		algorithm = config.Algorithm
+		if len(algorithm) == 0 {
+			algorithm = compression.AlgorithmGzip
+		}
+		if config.MinSize != 0 {
+			minSize = int(config.MinSize)
+		}
+		if config.MaxSize != 0 {
+			maxSize = int(config.MaxSize)
+		}
+	}
+	transformer, err := compression.NewCompressionTransformer(delegate, algorithm, minSize, maxSize, gr.String())
+	if err != nil {
+		return nil, fmt.Errorf("could not configure compression of %s: %v", gr, err)
+	}
+	return transformer, nil
+}
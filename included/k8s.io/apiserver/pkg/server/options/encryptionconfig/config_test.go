@@ -19,13 +19,18 @@ package encryptionconfig
 import (
 	"bytes"
 	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime/schema"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/util/diff"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/util/wait"
 	apiserverconfig "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/apis/config"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/value"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/value/encrypt/envelope"
@@ -218,6 +223,73 @@ resources:
           secret: dGhpcyBpcyBwYXNzd29yZA==
 `
 
+	correctConfigWithHKDFFirst = `
+kind: EncryptionConfiguration
+apiVersion: apiserver.config.k8s.io/v1
+resources:
+  - resources:
+    - secrets
+    providers:
+    - hkdf:
+        keys:
+        - name: key1
+          secret: YWJjZGVmZ2hpamtsbW5vcHFyc3R1dnd4eXoxMjM0NTY=
+    - hkdf:
+        cipher: chacha20poly1305
+        keys:
+        - name: key1
+          secret: YWJjZGVmZ2hpamtsbW5vcHFyc3R1dnd4eXoxMjM0NTY=
+    - identity: {}
+`
+
+	correctConfigWithHKDFChaCha20Poly1305First = `
+kind: EncryptionConfiguration
+apiVersion: apiserver.config.k8s.io/v1
+resources:
+  - resources:
+    - secrets
+    providers:
+    - hkdf:
+        cipher: chacha20poly1305
+        keys:
+        - name: key2
+          secret: dGhpcyBpcyBwYXNzd29yZCwgaXNuJ3QgaXQ/IG9rYXk=
+        - name: key1
+          secret: YWJjZGVmZ2hpamtsbW5vcHFyc3R1dnd4eXoxMjM0NTY=
+    - hkdf:
+        keys:
+        - name: key1
+          secret: YWJjZGVmZ2hpamtsbW5vcHFyc3R1dnd4eXoxMjM0NTY=
+    - identity: {}
+`
+
+	incorrectConfigHKDFCipher = `
+kind: EncryptionConfiguration
+apiVersion: apiserver.config.k8s.io/v1
+resources:
+  - resources:
+    - secrets
+    providers:
+    - hkdf:
+        cipher: aescbc
+        keys:
+        - name: key1
+          secret: YWJjZGVmZ2hpamtsbW5vcHFyc3R1dnd4eXoxMjM0NTY=
+`
+
+	incorrectConfigHKDFKeySize = `
+kind: EncryptionConfiguration
+apiVersion: apiserver.config.k8s.io/v1
+resources:
+  - resources:
+    - secrets
+    providers:
+    - hkdf:
+        keys:
+        - name: key1
+          secret: c2VjcmV0IGlzIHNlY3VyZQ==
+`
+
 	incorrectConfigNoSecretForKey = `
 kind: EncryptionConfiguration
 apiVersion: apiserver.config.k8s.io/v1
@@ -258,6 +330,63 @@ resources:
         name: testprovider
         cachesize: 10
 `
+
+	correctConfigKMSV2 = `
+kind: EncryptionConfiguration
+apiVersion: apiserver.config.k8s.io/v1
+resources:
+  - resources:
+    - secrets
+    providers:
+    - kms:
+        apiVersion: v2
+        name: testproviderv2
+        endpoint: unix:///tmp/testproviderv2.sock
+    - kms:
+        name: testprovider
+        endpoint: unix:///tmp/testprovider.sock
+`
+
+	correctConfigCompression = `
+kind: EncryptionConfiguration
+apiVersion: apiserver.config.k8s.io/v1
+resources:
+  - resources:
+    - configmaps
+    compression:
+      algorithm: flate
+      minSize: 64
+    providers:
+    - aescbc:
+        keys:
+        - name: key1
+          secret: c2VjcmV0IGlzIHNlY3VyZSwgaXMgaXQ/IGtpbmQgb2Y=
+`
+
+	incorrectConfigCompressionAlgorithm = `
+kind: EncryptionConfiguration
+apiVersion: apiserver.config.k8s.io/v1
+resources:
+  - resources:
+    - configmaps
+    compression:
+      algorithm: zstd
+    providers:
+    - identity: {}
+`
+
+	incorrectConfigKMSAPIVersion = `
+kind: EncryptionConfiguration
+apiVersion: apiserver.config.k8s.io/v1
+resources:
+  - resources:
+    - secrets
+    providers:
+    - kms:
+        apiVersion: v3
+        name: testprovider
+        endpoint: unix:///tmp/testprovider.sock
+`
 )
 
 // testEnvelopeService is a mock envelope service which can be used to simulate remote Envelope services
@@ -278,6 +407,32 @@ func newMockEnvelopeService(endpoint string, timeout time.Duration) (envelope.Se
 	return &testEnvelopeService{}, nil
 }
 
+// testEnvelopeServiceV2 is a mock v2 envelope service with a configurable health.
+type testEnvelopeServiceV2 struct {
+	lock    sync.Mutex
+	healthz string
+}
+
+func (t *testEnvelopeServiceV2) Decrypt(uid string, keyID string, data []byte) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(string(data))
+}
+
+func (t *testEnvelopeServiceV2) Encrypt(uid string, data []byte) ([]byte, string, error) {
+	return []byte(base64.StdEncoding.EncodeToString(data)), "1", nil
+}
+
+func (t *testEnvelopeServiceV2) Status() (*envelope.StatusResponse, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return &envelope.StatusResponse{Version: "v2alpha1", Healthz: t.healthz, KeyID: "1"}, nil
+}
+
+func (t *testEnvelopeServiceV2) setHealthz(healthz string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.healthz = healthz
+}
+
 func TestLegacyConfig(t *testing.T) {
 	legacyConfigObject, err := loadConfig([]byte(legacyV1Config))
 	if err != nil {
@@ -399,6 +554,67 @@ func TestEncryptionProviderConfigCorrect(t *testing.T) {
 
 }
 
+func TestEncryptionProviderConfigHKDF(t *testing.T) {
+	hkdfFirstTransformerOverrides, err := ParseEncryptionConfiguration(strings.NewReader(correctConfigWithHKDFFirst))
+	if err != nil {
+		t.Fatalf("error while parsing configuration file: %s.\nThe file was:\n%s", err, correctConfigWithHKDFFirst)
+	}
+	chachaFirstTransformerOverrides, err := ParseEncryptionConfiguration(strings.NewReader(correctConfigWithHKDFChaCha20Poly1305First))
+	if err != nil {
+		t.Fatalf("error while parsing configuration file: %s.\nThe file was:\n%s", err, correctConfigWithHKDFChaCha20Poly1305First)
+	}
+	hkdfFirstTransformer := hkdfFirstTransformerOverrides[schema.ParseGroupResource("secrets")]
+	chachaFirstTransformer := chachaFirstTransformerOverrides[schema.ParseGroupResource("secrets")]
+
+	context := value.DefaultContext([]byte(sampleContextText))
+	originalText := []byte(sampleText)
+
+	// Data written with the AES-GCM provider is read by the configuration that rotated to a new key and cipher,
+	// and reported as stale so that it is rewritten.
+	transformedData, err := hkdfFirstTransformer.TransformToStorage(originalText, context)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(transformedData, []byte(hkdfTransformerPrefixV1+"aesgcm:key1:")) {
+		t.Fatalf("unexpected prefix: %q", transformedData)
+	}
+	untransformedData, stale, err := chachaFirstTransformer.TransformFromStorage(transformedData, context)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stale || !bytes.Equal(untransformedData, originalText) {
+		t.Fatalf("expected stale %q, got stale=%t %q", originalText, stale, untransformedData)
+	}
+
+	transformedData, err = chachaFirstTransformer.TransformToStorage(originalText, context)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(transformedData, []byte(hkdfTransformerPrefixV1+"chacha20poly1305:key2:")) {
+		t.Fatalf("unexpected prefix: %q", transformedData)
+	}
+	untransformedData, stale, err = chachaFirstTransformer.TransformFromStorage(transformedData, context)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stale || !bytes.Equal(untransformedData, originalText) {
+		t.Fatalf("expected %q, got stale=%t %q", originalText, stale, untransformedData)
+	}
+	// key2 is unknown to the old configuration.
+	if _, _, err := hkdfFirstTransformer.TransformFromStorage(transformedData, context); err == nil {
+		t.Fatal("expected an error reading data written with an unknown key")
+	}
+}
+
+// Throw error if hkdf has an unsupported cipher or a key of the wrong size
+func TestEncryptionProviderConfigInvalidHKDF(t *testing.T) {
+	for _, config := range []string{incorrectConfigHKDFCipher, incorrectConfigHKDFKeySize} {
+		if _, err := ParseEncryptionConfiguration(strings.NewReader(config)); err == nil {
+			t.Errorf("invalid configuration file got parsed:\n%s", config)
+		}
+	}
+}
+
 // Throw error if key has no secret
 func TestEncryptionProviderConfigNoSecretForKey(t *testing.T) {
 	if _, err := ParseEncryptionConfiguration(strings.NewReader(incorrectConfigNoSecretForKey)); err == nil {
@@ -413,6 +629,116 @@ func TestEncryptionProviderConfigInvalidKey(t *testing.T) {
 	}
 }
 
+func TestEncryptionProviderConfigKMSV2(t *testing.T) {
+	service := &testEnvelopeServiceV2{healthz: "ok"}
+	factory, factoryV2 := envelopeServiceFactory, envelopeServiceV2Factory
+	envelopeServiceFactory = newMockEnvelopeService
+	envelopeServiceV2Factory = func(endpoint string, timeout time.Duration) (envelope.ServiceV2, error) {
+		return service, nil
+	}
+	defer func() {
+		envelopeServiceFactory, envelopeServiceV2Factory = factory, factoryV2
+	}()
+
+	dir, err := ioutil.TempDir("", "encryptionconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	configFile := filepath.Join(dir, "config.yaml")
+	if err := ioutil.WriteFile(configFile, []byte(correctConfigKMSV2), 0600); err != nil {
+		t.Fatal(err)
+	}
+	d, err := NewDynamicTransformers(configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secrets := d.TransformerForResource(schema.GroupResource{Resource: "secrets"})
+	context := value.DefaultContext([]byte(sampleContextText))
+	stored, err := secrets.TransformToStorage([]byte(sampleText), context)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(stored, []byte(kmsTransformerPrefixV2+"testproviderv2:")) {
+		t.Errorf("expected the value to be written with the v2 provider, got %q", stored)
+	}
+	out, stale, err := secrets.TransformFromStorage(stored, context)
+	if err != nil || stale || !bytes.Equal(out, []byte(sampleText)) {
+		t.Errorf("expected %q, got stale=%t err=%v %q", sampleText, stale, err, out)
+	}
+
+	// Only the v2 provider reports its health, once it was polled.
+	checker := d.HealthzChecker()
+	if checker.Name() != "kms-providers" {
+		t.Errorf("unexpected healthz check name %q", checker.Name())
+	}
+	if err := checker.Check(nil); err == nil {
+		t.Errorf("expected an error before the provider was polled")
+	}
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go d.RunKMSStatusPolls(10*time.Millisecond, stopCh)
+	if err := wait.Poll(10*time.Millisecond, wait.ForeverTestTimeout, func() (bool, error) {
+		return checker.Check(nil) == nil, nil
+	}); err != nil {
+		t.Errorf("expected the healthy provider to pass the check: %v", checker.Check(nil))
+	}
+	service.setHealthz("unavailable")
+	if err := wait.Poll(10*time.Millisecond, wait.ForeverTestTimeout, func() (bool, error) {
+		err := checker.Check(nil)
+		return err != nil && strings.Contains(err.Error(), "testproviderv2"), nil
+	}); err != nil {
+		t.Errorf("expected an error for the unhealthy provider, got %v", checker.Check(nil))
+	}
+}
+
+func TestEncryptionProviderConfigCompression(t *testing.T) {
+	transformers, err := ParseEncryptionConfiguration(strings.NewReader(correctConfigCompression))
+	if err != nil {
+		t.Fatal(err)
+	}
+	configmaps := transformers[schema.GroupResource{Resource: "configmaps"}]
+	context := value.DefaultContext([]byte(sampleContextText))
+	large := []byte(strings.Repeat(sampleText, 10))
+
+	stored, err := configmaps.TransformToStorage(large, context)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(stored, []byte(aesCBCTransformerPrefixV1)) {
+		t.Errorf("expected the compressed value to be encrypted, got %q", stored)
+	}
+	// The value is compressed before it is encrypted, so it is smaller than
+	// the same value encrypted without compression.
+	uncompressed, err := ParseEncryptionConfiguration(strings.NewReader(strings.Replace(correctConfigCompression, "minSize: 64", "minSize: 100000", 1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, err := uncompressed[schema.GroupResource{Resource: "configmaps"}].TransformToStorage(large, context)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored) >= len(plain) {
+		t.Errorf("expected the compressed value to be smaller, got %d bytes, %d without compression", len(stored), len(plain))
+	}
+	out, stale, err := configmaps.TransformFromStorage(stored, context)
+	if err != nil || stale || !bytes.Equal(out, large) {
+		t.Errorf("expected %q, got stale=%t err=%v %q", large, stale, err, out)
+	}
+
+	if _, err := ParseEncryptionConfiguration(strings.NewReader(incorrectConfigCompressionAlgorithm)); err == nil {
+		t.Errorf("invalid configuration file (unsupported compression algorithm) got parsed:\n%s", incorrectConfigCompressionAlgorithm)
+	}
+}
+
+// Throw error if kms has an unsupported apiVersion
+func TestEncryptionProviderConfigInvalidKMSAPIVersion(t *testing.T) {
+	if _, err := ParseEncryptionConfiguration(strings.NewReader(incorrectConfigKMSAPIVersion)); err == nil {
+		t.Fatalf("invalid configuration file (kms has an unsupported apiVersion) got parsed:\n%s", incorrectConfigKMSAPIVersion)
+	}
+}
+
 // Throw error if kms has no endpoint
 func TestEncryptionProviderConfigNoEndpointForKMS(t *testing.T) {
 	if _, err := ParseEncryptionConfiguration(strings.NewReader(incorrectConfigNoEndpointForKMS)); err == nil {
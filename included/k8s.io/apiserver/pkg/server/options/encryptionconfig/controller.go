@@ -0,0 +1,343 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package encryptionconfig
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/klog"
+
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime/schema"
+	utilruntime "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/util/runtime"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/util/wait"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/server/healthz"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/value"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/value/encrypt/envelope"
+)
+
+// DefaultReloadInterval is how often the encryption provider configuration
+// file is read to check it for changes by default.
+const DefaultReloadInterval = time.Minute
+
+// DefaultKMSStatusPollInterval is how often the status of every KMS provider
+// is polled by default.
+const DefaultKMSStatusPollInterval = 10 * time.Second
+
+// ReloadListener is called after the configuration was reloaded with the
+// resources whose providers changed.
+type ReloadListener func(resources []schema.GroupResource)
+
+// DynamicTransformers holds the transformers of the resources configured in
+// an encryption provider configuration file, and replaces them when the file
+// changes.
+//
+// The storage of a resource looks its transformer up on every call, so
+// resources that are added to the file are encrypted from the next reload on,
+// and resources that are removed from the file are stored unencrypted again,
+// as they would be after a restart.
+type DynamicTransformers struct {
+	filepath string
+
+	// transformers holds a map[schema.GroupResource]value.Transformer that is
+	// replaced, never modified, on reload.
+	transformers atomic.Value
+
+	// reloadLock serializes reloads, so that the hashes the providers are
+	// created against are still current when they replace the transformers.
+	reloadLock sync.Mutex
+
+	lock        sync.Mutex
+	contentHash string
+	hashes      map[schema.GroupResource]string
+	kmsChecks   map[schema.GroupResource][]kmsCheck
+	closers     map[schema.GroupResource][]io.Closer
+	listeners   []ReloadListener
+
+	// statusPollInterval is how often the KMS providers are polled, zero
+	// until RunKMSStatusPolls is called.
+	statusPollInterval time.Duration
+	// statusPolls holds a channel that stops the poll of every distinct KMS
+	// provider in kmsChecks.
+	statusPolls map[envelope.StatusChecker]chan struct{}
+}
+
+// NewDynamicTransformers reads and parses the encryption provider
+// configuration file at filepath.
+func NewDynamicTransformers(filepath string) (*DynamicTransformers, error) {
+	data, err := ioutil.ReadFile(filepath)
+	if err != nil {
+		return nil, fmt.Errorf("error opening encryption provider configuration file %q: %v", filepath, err)
+	}
+	parsed, err := parseEncryptionConfiguration(data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error while parsing encryption provider configuration file %q: %v", filepath, err)
+	}
+	d := &DynamicTransformers{
+		filepath:    filepath,
+		contentHash: fmt.Sprintf("%x", sha256.Sum256(data)),
+		hashes:      parsed.hashes,
+		kmsChecks:   parsed.kmsChecks,
+		closers:     parsed.closers,
+	}
+	d.transformers.Store(parsed.transformers)
+	recordConfigHash(d.contentHash)
+	return d, nil
+}
+
+// TransformerForResource returns the transformer of a resource. It uses the
+// providers configured for the resource by the current configuration, and
+// stores the resource unencrypted if the configuration does not contain it.
+func (d *DynamicTransformers) TransformerForResource(groupResource schema.GroupResource) value.Transformer {
+	return &resourceTransformer{transformers: d, groupResource: groupResource}
+}
+
+func (d *DynamicTransformers) transformer(groupResource schema.GroupResource) value.Transformer {
+	if transformer, ok := d.transformers.Load().(map[schema.GroupResource]value.Transformer)[groupResource]; ok {
+		return transformer
+	}
+	return value.IdentityTransformer
+}
+
+// resourceTransformer transforms the values of a resource with its current
+// transformer.
+type resourceTransformer struct {
+	transformers  *DynamicTransformers
+	groupResource schema.GroupResource
+}
+
+func (t *resourceTransformer) TransformFromStorage(data []byte, context value.Context) ([]byte, bool, error) {
+	return t.transformers.transformer(t.groupResource).TransformFromStorage(data, context)
+}
+
+func (t *resourceTransformer) TransformToStorage(data []byte, context value.Context) ([]byte, error) {
+	return t.transformers.transformer(t.groupResource).TransformToStorage(data, context)
+}
+
+// AddListener registers a listener that is called after every reload that
+// changed the providers of some resource.
+func (d *DynamicTransformers) AddListener(listener ReloadListener) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.listeners = append(d.listeners, listener)
+}
+
+// Run checks the configuration file for changes every interval until stopCh
+// is closed. The file is polled, not watched, so a change takes effect up to
+// interval after it was written. Polling also follows files that are replaced
+// rather than written, e.g. the files of mounted secrets or config maps.
+func (d *DynamicTransformers) Run(interval time.Duration, stopCh <-chan struct{}) {
+	defer utilruntime.HandleCrash()
+	klog.Infof("Watching encryption provider configuration file %q for changes", d.filepath)
+	wait.Until(func() {
+		if err := d.Reload(); err != nil {
+			klog.Errorf("Failed to reload encryption provider configuration: %v", err)
+		}
+	}, interval, stopCh)
+}
+
+// Reload reads the configuration file and, if its contents changed, replaces
+// the transformers of the resources whose providers changed. Only these get
+// new providers, and the connections to the KMS providers no resource uses
+// anymore are closed. A file that cannot be read or parsed leaves the current
+// providers in place.
+func (d *DynamicTransformers) Reload() error {
+	d.reloadLock.Lock()
+	defer d.reloadLock.Unlock()
+
+	data, err := ioutil.ReadFile(d.filepath)
+	if err != nil {
+		err = fmt.Errorf("error opening encryption provider configuration file %q: %v", d.filepath, err)
+		recordReload("", err)
+		return err
+	}
+	contentHash := fmt.Sprintf("%x", sha256.Sum256(data))
+
+	d.lock.Lock()
+	if contentHash == d.contentHash {
+		d.lock.Unlock()
+		return nil
+	}
+	hashes := d.hashes
+	d.lock.Unlock()
+
+	parsed, err := parseEncryptionConfiguration(data, hashes)
+	if err != nil {
+		err = fmt.Errorf("error while parsing encryption provider configuration file %q: %v", d.filepath, err)
+		recordReload("", err)
+		return err
+	}
+
+	d.lock.Lock()
+	current := d.transformers.Load().(map[schema.GroupResource]value.Transformer)
+	transformers := make(map[schema.GroupResource]value.Transformer, len(parsed.hashes))
+	kmsChecks := make(map[schema.GroupResource][]kmsCheck, len(parsed.hashes))
+	closers := make(map[schema.GroupResource][]io.Closer, len(parsed.hashes))
+	var changed []schema.GroupResource
+	for gr, hash := range parsed.hashes {
+		if previous, ok := d.hashes[gr]; ok && previous == hash {
+			// Unchanged resources keep their transformers, and the KMS
+			// providers these were created with.
+			transformers[gr] = current[gr]
+			kmsChecks[gr] = d.kmsChecks[gr]
+			closers[gr] = d.closers[gr]
+			continue
+		}
+		if _, ok := current[gr]; !ok {
+			klog.Infof("Resource %s was added to the encryption provider configuration, its objects are encrypted from now on", gr)
+		}
+		transformers[gr] = parsed.transformers[gr]
+		kmsChecks[gr] = parsed.kmsChecks[gr]
+		closers[gr] = parsed.closers[gr]
+		changed = append(changed, gr)
+	}
+	for gr := range current {
+		if _, ok := parsed.hashes[gr]; !ok {
+			klog.Warningf("Resource %s was removed from the encryption provider configuration, its objects are no longer encrypted", gr)
+			changed = append(changed, gr)
+		}
+	}
+	sort.Slice(changed, func(i, j int) bool { return changed[i].String() < changed[j].String() })
+	discarded := unusedClosers(d.closers, closers)
+	d.transformers.Store(transformers)
+	d.kmsChecks = kmsChecks
+	d.closers = closers
+	d.contentHash = contentHash
+	d.hashes = parsed.hashes
+	d.syncStatusPollsLocked()
+	listeners := d.listeners
+	d.lock.Unlock()
+
+	closeAll(discarded)
+
+	klog.Infof("Reloaded encryption provider configuration file %q, providers of %v changed", d.filepath, changed)
+	recordReload(contentHash, nil)
+	if len(changed) > 0 {
+		for _, listener := range listeners {
+			listener(changed)
+		}
+	}
+	return nil
+}
+
+// unusedClosers returns the connections in previous that are not in current.
+func unusedClosers(previous, current map[schema.GroupResource][]io.Closer) []io.Closer {
+	used := map[io.Closer]bool{}
+	for _, closers := range current {
+		for _, closer := range closers {
+			used[closer] = true
+		}
+	}
+	var unused []io.Closer
+	for _, closers := range previous {
+		for _, closer := range closers {
+			if !used[closer] {
+				used[closer] = true
+				unused = append(unused, closer)
+			}
+		}
+	}
+	return unused
+}
+
+// RunKMSStatusPolls polls the status of every distinct KMS provider every
+// interval, each in its own goroutine, until stopCh is closed. The polls
+// refresh the key IDs the providers encrypt with and the health that the
+// healthz check reports. They follow the providers across reloads.
+func (d *DynamicTransformers) RunKMSStatusPolls(interval time.Duration, stopCh <-chan struct{}) {
+	defer utilruntime.HandleCrash()
+	d.lock.Lock()
+	d.statusPollInterval = interval
+	d.statusPolls = map[envelope.StatusChecker]chan struct{}{}
+	d.syncStatusPollsLocked()
+	d.lock.Unlock()
+
+	<-stopCh
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	for _, stop := range d.statusPolls {
+		close(stop)
+	}
+	d.statusPolls = nil
+}
+
+// syncStatusPollsLocked starts polling the KMS providers in kmsChecks that
+// are not polled yet, and stops polling the others. It is a no-op unless
+// RunKMSStatusPolls is running.
+func (d *DynamicTransformers) syncStatusPollsLocked() {
+	if d.statusPolls == nil {
+		return
+	}
+	current := map[envelope.StatusChecker]bool{}
+	for _, checks := range d.kmsChecks {
+		for _, check := range checks {
+			current[check.checker] = true
+		}
+	}
+	for checker, stop := range d.statusPolls {
+		if !current[checker] {
+			close(stop)
+			delete(d.statusPolls, checker)
+		}
+	}
+	for checker := range current {
+		if _, ok := d.statusPolls[checker]; ok {
+			continue
+		}
+		checker, stop := checker, make(chan struct{})
+		d.statusPolls[checker] = stop
+		go wait.Until(func() { checker.PollStatus() }, d.statusPollInterval, stop)
+	}
+}
+
+// HealthzChecker returns the kms-providers healthz check, which fails unless
+// the KMS providers of all resources that report their health were healthy
+// when they were last polled. The check follows the providers across reloads.
+func (d *DynamicTransformers) HealthzChecker() healthz.HealthzChecker {
+	return healthz.NamedCheck("kms-providers", d.checkKMSProviders)
+}
+
+func (d *DynamicTransformers) checkKMSProviders(_ *http.Request) error {
+	d.lock.Lock()
+	var resources []schema.GroupResource
+	checks := map[schema.GroupResource][]kmsCheck{}
+	for gr, grChecks := range d.kmsChecks {
+		if len(grChecks) > 0 {
+			resources = append(resources, gr)
+			checks[gr] = grChecks
+		}
+	}
+	d.lock.Unlock()
+	sort.Slice(resources, func(i, j int) bool { return resources[i].String() < resources[j].String() })
+
+	for _, gr := range resources {
+		for _, check := range checks[gr] {
+			if err := check.checker.CheckStatus(); err != nil {
+				return fmt.Errorf("KMS provider %q of %s: %v", check.name, gr, err)
+			}
+		}
+	}
+	return nil
+}
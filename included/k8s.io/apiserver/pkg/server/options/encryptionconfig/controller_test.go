@@ -0,0 +1,284 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package encryptionconfig
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime/schema"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/value"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/value/encrypt/envelope"
+)
+
+const (
+	reloadConfigKey1 = `
+kind: EncryptionConfiguration
+apiVersion: apiserver.config.k8s.io/v1
+resources:
+  - resources:
+    - secrets
+    providers:
+    - aescbc:
+        keys:
+        - name: key1
+          secret: c2VjcmV0IGlzIHNlY3VyZSwgaXMgaXQ/IGtpbmQgb2Y=
+  - resources:
+    - configmaps
+    providers:
+    - identity: {}
+`
+
+	reloadConfigKey2First = `
+kind: EncryptionConfiguration
+apiVersion: apiserver.config.k8s.io/v1
+resources:
+  - resources:
+    - secrets
+    providers:
+    - aescbc:
+        keys:
+        - name: key2
+          secret: dGhpcyBpcyBwYXNzd29yZCwgaXNuJ3QgaXQ/IG9rYXk=
+        - name: key1
+          secret: c2VjcmV0IGlzIHNlY3VyZSwgaXMgaXQ/IGtpbmQgb2Y=
+  - resources:
+    - configmaps
+    providers:
+    - identity: {}
+`
+
+	reloadConfigKMS = `
+kind: EncryptionConfiguration
+apiVersion: apiserver.config.k8s.io/v1
+resources:
+  - resources:
+    - secrets
+    providers:
+    - kms:
+        apiVersion: v2
+        name: secrets-provider
+        endpoint: unix:///tmp/secrets.sock
+  - resources:
+    - configmaps
+    providers:
+    - kms:
+        apiVersion: v2
+        name: configmaps-provider
+        endpoint: unix:///tmp/configmaps.sock
+`
+
+	reloadConfigPodsAdded = `
+kind: EncryptionConfiguration
+apiVersion: apiserver.config.k8s.io/v1
+resources:
+  - resources:
+    - secrets
+    - pods
+    providers:
+    - aescbc:
+        keys:
+        - name: key2
+          secret: dGhpcyBpcyBwYXNzd29yZCwgaXNuJ3QgaXQ/IG9rYXk=
+    - identity: {}
+`
+)
+
+func TestDynamicTransformersReload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "encryptionconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	configFile := filepath.Join(dir, "config.yaml")
+	if err := ioutil.WriteFile(configFile, []byte(reloadConfigKey1), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := NewDynamicTransformers(configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var changed [][]schema.GroupResource
+	d.AddListener(func(resources []schema.GroupResource) {
+		changed = append(changed, resources)
+	})
+
+	secrets := d.TransformerForResource(schema.GroupResource{Resource: "secrets"})
+	context := value.DefaultContext([]byte("key"))
+	stored, err := secrets.TransformToStorage([]byte("value"), context)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// An unchanged file is not reloaded.
+	if err := d.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if len(changed) != 0 {
+		t.Fatalf("unexpected reload: %v", changed)
+	}
+
+	// A broken file keeps the current providers.
+	if err := ioutil.WriteFile(configFile, []byte("kind: EncryptionConfiguration\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Reload(); err == nil {
+		t.Fatal("expected an error for a broken configuration")
+	}
+
+	if err := ioutil.WriteFile(configFile, []byte(reloadConfigKey2First), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	expected := [][]schema.GroupResource{{{Resource: "secrets"}}}
+	if !reflect.DeepEqual(changed, expected) {
+		t.Fatalf("expected listeners to be called with %v, got %v", expected, changed)
+	}
+
+	// Data written with the old primary key is still readable, but stale.
+	out, stale, err := secrets.TransformFromStorage(stored, context)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stale || !bytes.Equal(out, []byte("value")) {
+		t.Errorf("expected stale %q, got stale=%t %q", "value", stale, out)
+	}
+	restored, err := secrets.TransformToStorage([]byte("value"), context)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, stale, err := secrets.TransformFromStorage(restored, context); err != nil || stale {
+		t.Errorf("expected data written after the reload to be current, got stale=%t err=%v", stale, err)
+	}
+
+	// Resources added to the file are encrypted, resources removed from it
+	// are stored unencrypted.
+	pods := d.TransformerForResource(schema.GroupResource{Resource: "pods"})
+	configmaps := d.TransformerForResource(schema.GroupResource{Resource: "configmaps"})
+	if stored, err := pods.TransformToStorage([]byte("value"), context); err != nil || !bytes.Equal(stored, []byte("value")) {
+		t.Fatalf("expected pods to be stored unencrypted before they are configured, got %q, %v", stored, err)
+	}
+	if err := ioutil.WriteFile(configFile, []byte(reloadConfigPodsAdded), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	expected = append(expected, []schema.GroupResource{{Resource: "configmaps"}, {Resource: "pods"}, {Resource: "secrets"}})
+	if !reflect.DeepEqual(changed, expected) {
+		t.Fatalf("expected listeners to be called with %v, got %v", expected, changed)
+	}
+	stored, err = pods.TransformToStorage([]byte("value"), context)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(stored, []byte("k8s:enc:aescbc:v1:key2:")) {
+		t.Errorf("expected pods to be encrypted after they were added, got %q", stored)
+	}
+	if out, _, err := pods.TransformFromStorage(stored, context); err != nil || !bytes.Equal(out, []byte("value")) {
+		t.Errorf("expected %q, got %q, %v", "value", out, err)
+	}
+	if stored, err := configmaps.TransformToStorage([]byte("value"), context); err != nil || !bytes.Equal(stored, []byte("value")) {
+		t.Errorf("expected configmaps to be stored unencrypted after they were removed, got %q, %v", stored, err)
+	}
+}
+
+// closableEnvelopeServiceV2 is a v2 envelope service that records whether
+// its connection was closed.
+type closableEnvelopeServiceV2 struct {
+	testEnvelopeServiceV2
+	closed bool
+}
+
+func (s *closableEnvelopeServiceV2) Close() error {
+	s.closed = true
+	return nil
+}
+
+func TestDynamicTransformersReloadKMSConnections(t *testing.T) {
+	services := map[string][]*closableEnvelopeServiceV2{}
+	factoryV2 := envelopeServiceV2Factory
+	envelopeServiceV2Factory = func(endpoint string, timeout time.Duration) (envelope.ServiceV2, error) {
+		service := &closableEnvelopeServiceV2{testEnvelopeServiceV2: testEnvelopeServiceV2{healthz: "ok"}}
+		services[endpoint] = append(services[endpoint], service)
+		return service, nil
+	}
+	defer func() {
+		envelopeServiceV2Factory = factoryV2
+	}()
+
+	dir, err := ioutil.TempDir("", "encryptionconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	configFile := filepath.Join(dir, "config.yaml")
+	if err := ioutil.WriteFile(configFile, []byte(reloadConfigKMS), 0600); err != nil {
+		t.Fatal(err)
+	}
+	d, err := NewDynamicTransformers(configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const secretsEndpoint, configmapsEndpoint = "unix:///tmp/secrets.sock", "unix:///tmp/configmaps.sock"
+	if len(services[secretsEndpoint]) != 1 || len(services[configmapsEndpoint]) != 1 {
+		t.Fatalf("expected one connection per provider, got %v", services)
+	}
+
+	// Only the resource whose provider changed connects to a KMS provider
+	// again, and its previous connection is closed.
+	changedConfig := strings.Replace(reloadConfigKMS, "configmaps.sock", "configmaps-2.sock", 1)
+	if err := ioutil.WriteFile(configFile, []byte(changedConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if len(services[secretsEndpoint]) != 1 || services[secretsEndpoint][0].closed {
+		t.Errorf("expected the connection of secrets to be kept, got %v", services[secretsEndpoint])
+	}
+	if !services[configmapsEndpoint][0].closed {
+		t.Errorf("expected the replaced connection of configmaps to be closed")
+	}
+	if replaced := services["unix:///tmp/configmaps-2.sock"]; len(replaced) != 1 || replaced[0].closed {
+		t.Errorf("expected a new connection for configmaps, got %v", replaced)
+	}
+
+	// The connections of removed resources are closed.
+	removedConfig := changedConfig[:strings.Index(changedConfig, "  - resources:\n    - configmaps")]
+	if err := ioutil.WriteFile(configFile, []byte(removedConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if replaced := services["unix:///tmp/configmaps-2.sock"]; !replaced[0].closed {
+		t.Errorf("expected the connection of the removed configmaps to be closed")
+	}
+	if len(services[secretsEndpoint]) != 1 || services[secretsEndpoint][0].closed {
+		t.Errorf("expected the connection of secrets to be kept, got %v", services[secretsEndpoint])
+	}
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package encryptionconfig
+
+import (
+	"sync"
+	"time"
+
+	"github.com/aaron-prindle/krmapiserver/included/github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	namespace = "apiserver"
+	subsystem = "encryption_config_controller"
+)
+
+var (
+	reloadsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "automatic_reloads_total",
+			Help:      "Total number of reloads of the encryption provider configuration, split by status.",
+		},
+		[]string{"status"},
+	)
+	lastReloadTimestamp = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "automatic_reload_last_timestamp_seconds",
+			Help:      "Timestamp of the last reload of the encryption provider configuration, split by status.",
+		},
+		[]string{"status"},
+	)
+	configInfo = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "config_info",
+			Help:      "Information about the encryption provider configuration in use. The value is always 1.",
+		},
+		[]string{"hash"},
+	)
+)
+
+var registerMetrics sync.Once
+
+func registerReloadMetrics() {
+	registerMetrics.Do(func() {
+		prometheus.MustRegister(reloadsTotal)
+		prometheus.MustRegister(lastReloadTimestamp)
+		prometheus.MustRegister(configInfo)
+	})
+}
+
+// recordReload records a reload of the configuration with the given hash
+// of its contents.
+func recordReload(hash string, err error) {
+	registerReloadMetrics()
+	status := "success"
+	if err != nil {
+		status = "failure"
+	}
+	reloadsTotal.WithLabelValues(status).Inc()
+	lastReloadTimestamp.WithLabelValues(status).Set(float64(time.Now().Unix()))
+	if err == nil {
+		recordConfigHash(hash)
+	}
+}
+
+// recordConfigHash records the hash of the contents of the configuration in
+// use.
+func recordConfigHash(hash string) {
+	registerReloadMetrics()
+	configInfo.Reset()
+	configInfo.WithLabelValues(hash).Set(1)
+}
@@ -31,7 +31,10 @@ import (
 	genericregistry "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/registry/generic/registry"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/server"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/server/healthz"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/server/options/encryptionconfig"
 	serverstorage "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/server/storage"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/faultinjection"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/storagebackend"
 	storagefactory "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/storagebackend/factory"
 )
@@ -41,8 +44,19 @@ type EtcdOptions struct {
 	// calculated feature gate value.
 	StorageConfig                    storagebackend.Config
 	EncryptionProviderConfigFilepath string
+	// EncryptionProviderConfigAutomaticReload, if true, makes the server
+	// reload the encryption provider configuration file when it changes and
+	// re-encrypt the objects of the resources whose providers changed.
+	EncryptionProviderConfigAutomaticReload bool
+	// EncryptionProviderConfigReloadInterval is how often the encryption
+	// provider configuration file is polled for changes.
+	EncryptionProviderConfigReloadInterval time.Duration
 
 	EtcdServersOverrides []string
+	// EtcdShardsOverrides shard resources across several etcd clusters. Every
+	// override adds a shard, so the overrides of a resource list its shards
+	// in order.
+	EtcdShardsOverrides []string
 
 	// To enable protobuf as storage format, it is enough
 	// to set it to "application/vnd.kubernetes.protobuf".
@@ -56,10 +70,30 @@ type EtcdOptions struct {
 	DefaultWatchCacheSize int
 	// WatchCacheSizes represents override to a given resource
 	WatchCacheSizes []string
+	// ObjectHistory keeps the history of the objects of some resources, as
+	// resource[.group]#retention, where retention is either a number of
+	// revisions or a duration.
+	ObjectHistory []string
+	// SoftDelete keeps the deleted objects of some resources in their trash,
+	// as resource[.group]#retention, where retention is a duration.
+	SoftDelete []string
+	// SelectableFields exposes JSON paths of the objects of some resources as
+	// selectable fields, as resource[.group]#path, e.g.
+	// deployments.apps#spec.replicas.
+	SelectableFields []string
+
+	// EnableStorageFaultInjection decorates the storages of all resources to
+	// inject faults in their operations, by the rules of the file at
+	// StorageFaultInjectionConfigFilepath and of the /debug/storage/faults
+	// API.
+	EnableStorageFaultInjection         bool
+	StorageFaultInjectionConfigFilepath string
 }
 
 var storageTypes = sets.NewString(
 	storagebackend.StorageTypeETCD3,
+	storagebackend.StorageTypeEmbedded,
+	storagebackend.StorageTypeMemory,
 )
 
 func NewEtcdOptions(backendConfig *storagebackend.Config) *EtcdOptions {
@@ -70,6 +104,8 @@ func NewEtcdOptions(backendConfig *storagebackend.Config) *EtcdOptions {
 		EnableGarbageCollection: true,
 		EnableWatchCache:        true,
 		DefaultWatchCacheSize:   100,
+
+		EncryptionProviderConfigReloadInterval: encryptionconfig.DefaultReloadInterval,
 	}
 	options.StorageConfig.CountMetricPollPeriod = time.Minute
 	return options
@@ -81,14 +117,36 @@ func (s *EtcdOptions) Validate() []error {
 	}
 
 	allErrors := []error{}
-	if len(s.StorageConfig.Transport.ServerList) == 0 {
-		allErrors = append(allErrors, fmt.Errorf("--etcd-servers must be specified"))
+	switch s.StorageConfig.Type {
+	case storagebackend.StorageTypeEmbedded:
+		if len(s.StorageConfig.DataDir) == 0 {
+			allErrors = append(allErrors, fmt.Errorf("--storage-data-dir must be specified for the %s storage backend", s.StorageConfig.Type))
+		}
+	case storagebackend.StorageTypeMemory:
+		// the memory storage backend needs no configuration
+	default:
+		if len(s.StorageConfig.Transport.ServerList) == 0 {
+			allErrors = append(allErrors, fmt.Errorf("--etcd-servers must be specified"))
+		}
 	}
 
 	if s.StorageConfig.Type != storagebackend.StorageTypeUnset && !storageTypes.Has(s.StorageConfig.Type) {
 		allErrors = append(allErrors, fmt.Errorf("--storage-backend invalid, allowed values: %s. If not specified, it will default to 'etcd3'", strings.Join(storageTypes.List(), ", ")))
 	}
 
+	if len(s.StorageConfig.WatchCacheSnapshotDir) > 0 && s.StorageConfig.WatchCacheSnapshotInterval <= 0 {
+		allErrors = append(allErrors, fmt.Errorf("--watch-cache-snapshot-interval must be greater than 0 if --watch-cache-snapshot-dir is set"))
+	}
+
+	if s.EncryptionProviderConfigAutomaticReload {
+		if len(s.EncryptionProviderConfigFilepath) == 0 {
+			allErrors = append(allErrors, fmt.Errorf("--encryption-provider-config-automatic-reload must be set with --encryption-provider-config"))
+		}
+		if s.EncryptionProviderConfigReloadInterval <= 0 {
+			allErrors = append(allErrors, fmt.Errorf("--encryption-provider-config-reload-interval must be greater than 0 if --encryption-provider-config-automatic-reload is set"))
+		}
+	}
+
 	for _, override := range s.EtcdServersOverrides {
 		tokens := strings.Split(override, "#")
 		if len(tokens) != 2 {
@@ -104,6 +162,44 @@ func (s *EtcdOptions) Validate() []error {
 
 	}
 
+	if len(s.EtcdShardsOverrides) > 0 && s.StorageConfig.Type != storagebackend.StorageTypeUnset && s.StorageConfig.Type != storagebackend.StorageTypeETCD3 {
+		allErrors = append(allErrors, fmt.Errorf("--etcd-shards-overrides is only supported by the %s storage backend", storagebackend.StorageTypeETCD3))
+	}
+	serversOverridden := sets.NewString()
+	for _, override := range s.EtcdServersOverrides {
+		serversOverridden.Insert(strings.Split(override, "#")[0])
+	}
+	for _, override := range s.EtcdShardsOverrides {
+		tokens := strings.Split(override, "#")
+		if len(tokens) != 2 || len(strings.Split(tokens[0], "/")) != 2 || len(tokens[1]) == 0 {
+			allErrors = append(allErrors, fmt.Errorf("--etcd-shards-overrides invalid, must be of format: group/resource#servers, where servers are URLs, semicolon separated"))
+			continue
+		}
+		if serversOverridden.Has(tokens[0]) {
+			allErrors = append(allErrors, fmt.Errorf("--etcd-shards-overrides invalid, %s also has --etcd-servers-overrides", tokens[0]))
+		}
+	}
+
+	if _, err := ParseObjectHistory(s.ObjectHistory); err != nil {
+		allErrors = append(allErrors, fmt.Errorf("--object-history invalid: %v", err))
+	}
+
+	if _, err := ParseSoftDelete(s.SoftDelete); err != nil {
+		allErrors = append(allErrors, fmt.Errorf("--soft-delete invalid: %v", err))
+	}
+
+	if _, err := ParseSelectableFields(s.SelectableFields); err != nil {
+		allErrors = append(allErrors, fmt.Errorf("--selectable-fields invalid: %v", err))
+	}
+
+	if len(s.StorageFaultInjectionConfigFilepath) > 0 {
+		if !s.EnableStorageFaultInjection {
+			allErrors = append(allErrors, fmt.Errorf("--storage-fault-injection-config must be set with --enable-storage-fault-injection"))
+		} else if _, err := faultinjection.LoadConfig(s.StorageFaultInjectionConfigFilepath); err != nil {
+			allErrors = append(allErrors, fmt.Errorf("--storage-fault-injection-config invalid: %v", err))
+		}
+	}
+
 	return allErrors
 }
 
@@ -117,6 +213,14 @@ func (s *EtcdOptions) AddFlags(fs *pflag.FlagSet) {
 		"Per-resource etcd servers overrides, comma separated. The individual override "+
 		"format: group/resource#servers, where servers are URLs, semicolon separated.")
 
+	fs.StringSliceVar(&s.EtcdShardsOverrides, "etcd-shards-overrides", s.EtcdShardsOverrides, ""+
+		"Per-resource etcd shards, comma separated. The individual override format: "+
+		"group/resource#servers, where servers are URLs, semicolon separated. Every override "+
+		"adds a shard of the resource, in order. The objects of a sharded resource are spread "+
+		"across its shards by the hash of their namespace, or of their name if the resource "+
+		"is cluster-scoped, so the shards of a resource must not change once it is stored. "+
+		"Every shard of a resource has its own watch cache.")
+
 	fs.StringVar(&s.DefaultStorageMediaType, "storage-media-type", s.DefaultStorageMediaType, ""+
 		"The media type to use to store objects in storage. "+
 		"Some resources or storage backends may only support a specific media type and will ignore this setting.")
@@ -141,8 +245,44 @@ func (s *EtcdOptions) AddFlags(fs *pflag.FlagSet) {
 		"Some resources (replicationcontrollers, endpoints, nodes, pods, services, apiservices.apiregistration.k8s.io) "+
 		"have system defaults set by heuristics, others default to default-watch-cache-size")
 
-	fs.StringVar(&s.StorageConfig.Type, "storage-backend", s.StorageConfig.Type,
-		"The storage backend for persistence. Options: 'etcd3' (default).")
+	fs.StringSliceVar(&s.ObjectHistory, "object-history", s.ObjectHistory, ""+
+		"Resources whose objects keep the history of their revisions replaced by updates and deletions, "+
+		"comma separated. The individual setting format: resource[.group]#retention, where retention is "+
+		"either the number of revisions kept per object, e.g. 10, or how long revisions are kept, e.g. 24h. "+
+		"The history is served by the read-only history subresource of the resources, e.g. deployments/history, "+
+		"which RBAC must grant access to.")
+
+	fs.StringSliceVar(&s.SoftDelete, "soft-delete", s.SoftDelete, ""+
+		"Resources whose deleted objects are kept in their trash, comma separated. The individual setting "+
+		"format: resource[.group]#retention, where retention is how long deleted objects are kept before they "+
+		"are purged, e.g. 72h. Deleted objects are listed by deletedobjects.trash.k8s.io and restored with their "+
+		"UID by the undelete subresource of the resources, e.g. namespaces/undelete, which RBAC must grant access to.")
+
+	fs.StringSliceVar(&s.SelectableFields, "selectable-fields", s.SelectableFields, ""+
+		"JSON paths of the objects of resources that field selectors may use, comma separated. The individual "+
+		"setting format: resource[.group]#path, e.g. deployments.apps#spec.replicas, and a resource may be listed "+
+		"once per path. Paths refer to the storage version of the objects and select strings, numbers and booleans, "+
+		"which field selectors may compare with =, ==, !=, in, notin and, for numbers, > and <.")
+
+	fs.StringVar(&s.StorageConfig.WatchCacheSnapshotDir, "watch-cache-snapshot-dir", s.StorageConfig.WatchCacheSnapshotDir, ""+
+		"If set, the directory the watch caches periodically save their state in. On restart, the watch caches "+
+		"restore their state from it and resume watching the storage, instead of relisting it. "+
+		"It takes effect when watch-cache is enabled.")
+
+	fs.DurationVar(&s.StorageConfig.WatchCacheSnapshotInterval, "watch-cache-snapshot-interval", s.StorageConfig.WatchCacheSnapshotInterval,
+		"How often the watch caches save their state in --watch-cache-snapshot-dir.")
+
+	fs.DurationVar(&s.StorageConfig.WatchCacheConsistencyCheckInterval, "watch-cache-consistency-check-interval", s.StorageConfig.WatchCacheConsistencyCheckInterval, ""+
+		"How often the contents of the watch caches are compared with the storage. The results are reported by metrics "+
+		"and at /debug/cachecheck. If 0, the watch caches are not checked.")
+
+	fs.StringVar(&s.StorageConfig.Type, "storage-backend", s.StorageConfig.Type, ""+
+		"The storage backend for persistence. Options: 'etcd3' (default), 'embedded', 'memory'. "+
+		"The 'memory' backend loses all data when the server exits.")
+
+	fs.StringVar(&s.StorageConfig.DataDir, "storage-data-dir", s.StorageConfig.DataDir, ""+
+		"The directory the embedded storage backend keeps its write-ahead log and snapshots in. "+
+		"Only used if --storage-backend is 'embedded'.")
 
 	dummyCacheSize := 0
 	fs.IntVar(&dummyCacheSize, "deserialization-cache-size", 0, "Number of deserialized json objects to cache in memory.")
@@ -170,11 +310,28 @@ func (s *EtcdOptions) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&s.EncryptionProviderConfigFilepath, "encryption-provider-config", s.EncryptionProviderConfigFilepath,
 		"The file containing configuration for encryption providers to be used for storing secrets in etcd")
 
+	fs.BoolVar(&s.EncryptionProviderConfigAutomaticReload, "encryption-provider-config-automatic-reload", s.EncryptionProviderConfigAutomaticReload, ""+
+		"Reload the file set by --encryption-provider-config when it changes, and rewrite all objects of the resources "+
+		"whose providers changed with the new primary provider. The progress is reported by metrics and at /encryption-status.")
+
+	fs.DurationVar(&s.EncryptionProviderConfigReloadInterval, "encryption-provider-config-reload-interval", s.EncryptionProviderConfigReloadInterval, ""+
+		"How often the file set by --encryption-provider-config is read to check it for changes if --encryption-provider-config-automatic-reload is set. "+
+		"The file is polled rather than watched, so changes take effect up to this interval after they are written.")
+
 	fs.DurationVar(&s.StorageConfig.CompactionInterval, "etcd-compaction-interval", s.StorageConfig.CompactionInterval,
 		"The interval of compaction requests. If 0, the compaction request from apiserver is disabled.")
 
 	fs.DurationVar(&s.StorageConfig.CountMetricPollPeriod, "etcd-count-metric-poll-period", s.StorageConfig.CountMetricPollPeriod, ""+
-		"Frequency of polling etcd for number of resources per type. 0 disables the metric collection.")
+		"Frequency of polling etcd for number of resources per type. 0 disables the metric collection "+
+		"and the accounting of the objects and bytes of every resource per namespace.")
+
+	fs.BoolVar(&s.EnableStorageFaultInjection, "enable-storage-fault-injection", s.EnableStorageFaultInjection, ""+
+		"Inject faults in the storage operations of the resources for resilience testing: latency, errors, dropped "+
+		"watch events and watch closures. The faults are configured by --storage-fault-injection-config and, if "+
+		"profiling is enabled, at /debug/storage/faults, and recorded in audit annotations. Never enable it in production.")
+
+	fs.StringVar(&s.StorageFaultInjectionConfigFilepath, "storage-fault-injection-config", s.StorageFaultInjectionConfigFilepath,
+		"The file containing the initial storage fault injection rules, if --enable-storage-fault-injection is set.")
 }
 
 func (s *EtcdOptions) ApplyTo(c *server.Config) error {
@@ -184,7 +341,11 @@ func (s *EtcdOptions) ApplyTo(c *server.Config) error {
 	if err := s.addEtcdHealthEndpoint(c); err != nil {
 		return err
 	}
+	if err := s.applyStorageFaultInjection(); err != nil {
+		return err
+	}
 	c.RESTOptionsGetter = &SimpleRestOptionsFactory{Options: *s}
+	c.EnableCacheCheck = s.EnableWatchCache && s.StorageConfig.WatchCacheConsistencyCheckInterval > 0
 	return nil
 }
 
@@ -192,7 +353,11 @@ func (s *EtcdOptions) ApplyWithStorageFactoryTo(factory serverstorage.StorageFac
 	if err := s.addEtcdHealthEndpoint(c); err != nil {
 		return err
 	}
+	if err := s.applyStorageFaultInjection(); err != nil {
+		return err
+	}
 	c.RESTOptionsGetter = &StorageFactoryRestOptionsFactory{Options: *s, StorageFactory: factory}
+	c.EnableCacheCheck = s.EnableWatchCache && s.StorageConfig.WatchCacheConsistencyCheckInterval > 0
 	return nil
 }
 
@@ -207,6 +372,26 @@ func (s *EtcdOptions) addEtcdHealthEndpoint(c *server.Config) error {
 	return nil
 }
 
+// applyStorageFaultInjection configures the fault injector the storages are
+// decorated with if fault injection is enabled.
+func (s *EtcdOptions) applyStorageFaultInjection() error {
+	if !s.EnableStorageFaultInjection {
+		return nil
+	}
+	injector := faultinjection.DefaultInjector()
+	if len(s.StorageFaultInjectionConfigFilepath) > 0 {
+		config, err := faultinjection.LoadConfig(s.StorageFaultInjectionConfigFilepath)
+		if err != nil {
+			return err
+		}
+		if err := injector.SetConfig(config); err != nil {
+			return err
+		}
+	}
+	injector.SetEnabled(true)
+	return nil
+}
+
 type SimpleRestOptionsFactory struct {
 	Options EtcdOptions
 }
@@ -220,6 +405,21 @@ func (f *SimpleRestOptionsFactory) GetRESTOptions(resource schema.GroupResource)
 		ResourcePrefix:          resource.Group + "/" + resource.Resource,
 		CountMetricPollPeriod:   f.Options.StorageConfig.CountMetricPollPeriod,
 	}
+	history, err := ParseObjectHistory(f.Options.ObjectHistory)
+	if err != nil {
+		return generic.RESTOptions{}, err
+	}
+	ret.History = history[resource]
+	trash, err := ParseSoftDelete(f.Options.SoftDelete)
+	if err != nil {
+		return generic.RESTOptions{}, err
+	}
+	ret.Trash = trash[resource]
+	selectableFields, err := ParseSelectableFields(f.Options.SelectableFields)
+	if err != nil {
+		return generic.RESTOptions{}, err
+	}
+	ret.SelectableFields = selectableFields[resource]
 	if f.Options.EnableWatchCache {
 		sizes, err := ParseWatchCacheSizes(f.Options.WatchCacheSizes)
 		if err != nil {
@@ -232,6 +432,9 @@ func (f *SimpleRestOptionsFactory) GetRESTOptions(resource schema.GroupResource)
 		// depending on cache size this might return an undecorated storage
 		ret.Decorator = genericregistry.StorageWithCacher(cacheSize)
 	}
+	if f.Options.EnableStorageFaultInjection {
+		ret.Decorator = genericregistry.StorageWithFaultInjection(resource, ret.Decorator)
+	}
 	return ret, nil
 }
 
@@ -254,6 +457,21 @@ func (f *StorageFactoryRestOptionsFactory) GetRESTOptions(resource schema.GroupR
 		ResourcePrefix:          f.StorageFactory.ResourcePrefix(resource),
 		CountMetricPollPeriod:   f.Options.StorageConfig.CountMetricPollPeriod,
 	}
+	history, err := ParseObjectHistory(f.Options.ObjectHistory)
+	if err != nil {
+		return generic.RESTOptions{}, err
+	}
+	ret.History = history[resource]
+	trash, err := ParseSoftDelete(f.Options.SoftDelete)
+	if err != nil {
+		return generic.RESTOptions{}, err
+	}
+	ret.Trash = trash[resource]
+	selectableFields, err := ParseSelectableFields(f.Options.SelectableFields)
+	if err != nil {
+		return generic.RESTOptions{}, err
+	}
+	ret.SelectableFields = selectableFields[resource]
 	if f.Options.EnableWatchCache {
 		sizes, err := ParseWatchCacheSizes(f.Options.WatchCacheSizes)
 		if err != nil {
@@ -266,6 +484,9 @@ func (f *StorageFactoryRestOptionsFactory) GetRESTOptions(resource schema.GroupR
 		// depending on cache size this might return an undecorated storage
 		ret.Decorator = genericregistry.StorageWithCacher(cacheSize)
 	}
+	if f.Options.EnableStorageFaultInjection {
+		ret.Decorator = genericregistry.StorageWithFaultInjection(resource, ret.Decorator)
+	}
 
 	return ret, nil
 }
@@ -304,3 +525,82 @@ func WriteWatchCacheSizes(watchCacheSizes map[schema.GroupResource]int) ([]strin
 	}
 	return cacheSizes, nil
 }
+
+// ParseObjectHistory turns a list of object history settings into a map of
+// group resources to the options of their history.
+func ParseObjectHistory(settings []string) (map[schema.GroupResource]generic.HistoryOptions, error) {
+	history := make(map[schema.GroupResource]generic.HistoryOptions)
+	for _, setting := range settings {
+		tokens := strings.Split(setting, "#")
+		if len(tokens) != 2 || len(tokens[0]) == 0 {
+			return nil, fmt.Errorf("invalid value of object history: %s", setting)
+		}
+		resource := schema.ParseGroupResource(tokens[0])
+		if _, ok := history[resource]; ok {
+			return nil, fmt.Errorf("object history set more than once for resource %s", resource)
+		}
+		var options generic.HistoryOptions
+		if limit, err := strconv.Atoi(tokens[1]); err == nil {
+			options.Limit = limit
+		} else if window, err := time.ParseDuration(tokens[1]); err == nil {
+			options.Window = window
+		} else {
+			return nil, fmt.Errorf("invalid retention of object history, must be a number of revisions or a duration: %s", setting)
+		}
+		if !options.Enabled() {
+			return nil, fmt.Errorf("retention of object history must be positive: %s", setting)
+		}
+		history[resource] = options
+	}
+	return history, nil
+}
+
+// ParseSoftDelete turns a list of soft delete settings into a map of the
+// trash options of the resources.
+func ParseSoftDelete(settings []string) (map[schema.GroupResource]generic.TrashOptions, error) {
+	trash := make(map[schema.GroupResource]generic.TrashOptions)
+	for _, setting := range settings {
+		tokens := strings.Split(setting, "#")
+		if len(tokens) != 2 || len(tokens[0]) == 0 {
+			return nil, fmt.Errorf("invalid value of soft delete: %s", setting)
+		}
+		resource := schema.ParseGroupResource(tokens[0])
+		if _, ok := trash[resource]; ok {
+			return nil, fmt.Errorf("soft delete set more than once for resource %s", resource)
+		}
+		retention, err := time.ParseDuration(tokens[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid retention of soft delete, must be a duration: %s", setting)
+		}
+		options := generic.TrashOptions{Retention: retention}
+		if !options.Enabled() {
+			return nil, fmt.Errorf("retention of soft delete must be positive: %s", setting)
+		}
+		trash[resource] = options
+	}
+	return trash, nil
+}
+
+// ParseSelectableFields turns a list of selectable field settings into a map
+// of the selectable JSON paths of the resources.
+func ParseSelectableFields(settings []string) (map[schema.GroupResource][]string, error) {
+	selectableFields := make(map[schema.GroupResource][]string)
+	seen := sets.NewString()
+	for _, setting := range settings {
+		tokens := strings.Split(setting, "#")
+		if len(tokens) != 2 || len(tokens[0]) == 0 {
+			return nil, fmt.Errorf("invalid value of selectable field: %s", setting)
+		}
+		resource := schema.ParseGroupResource(tokens[0])
+		path := strings.TrimPrefix(tokens[1], ".")
+		if err := storage.ValidateSelectableField(path); err != nil {
+			return nil, err
+		}
+		if seen.Has(resource.String() + "#" + path) {
+			return nil, fmt.Errorf("selectable field set more than once: %s", setting)
+		}
+		seen.Insert(resource.String() + "#" + path)
+		selectableFields[resource] = append(selectableFields[resource], path)
+	}
+	return selectableFields, nil
+}
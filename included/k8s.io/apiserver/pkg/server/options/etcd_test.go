@@ -17,12 +17,15 @@ limitations under the License.
 package options
 
 import (
+	"reflect"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime/schema"
 	utilerrors "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/util/errors"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/registry/generic"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/server"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/storagebackend"
 )
 
@@ -78,7 +81,7 @@ func TestEtcdOptionsValidate(t *testing.T) {
 				DefaultWatchCacheSize:   100,
 				EtcdServersOverrides:    []string{"/events#http://127.0.0.1:4002"},
 			},
-			expectErr: "--storage-backend invalid, allowed values: etcd3. If not specified, it will default to 'etcd3'",
+			expectErr: "--storage-backend invalid, allowed values: embedded, etcd3, memory. If not specified, it will default to 'etcd3'",
 		},
 		{
 			name: "test when etcd-servers-overrides is invalid",
@@ -104,6 +107,136 @@ func TestEtcdOptionsValidate(t *testing.T) {
 			},
 			expectErr: "--etcd-servers-overrides invalid, must be of format: group/resource#servers, where servers are URLs, semicolon separated",
 		},
+		{
+			name: "test when etcd-shards-overrides is invalid",
+			testOptions: &EtcdOptions{
+				StorageConfig: storagebackend.Config{
+					Type:   "etcd3",
+					Prefix: "/registry",
+					Transport: storagebackend.TransportConfig{
+						ServerList: []string{"http://127.0.0.1"},
+					},
+					CompactionInterval:    storagebackend.DefaultCompactInterval,
+					CountMetricPollPeriod: time.Minute,
+				},
+				DefaultStorageMediaType: "application/vnd.kubernetes.protobuf",
+				DeleteCollectionWorkers: 1,
+				EnableGarbageCollection: true,
+				EnableWatchCache:        true,
+				DefaultWatchCacheSize:   100,
+				EtcdShardsOverrides:     []string{"/events/http://127.0.0.1:4002"},
+			},
+			expectErr: "--etcd-shards-overrides invalid, must be of format: group/resource#servers, where servers are URLs, semicolon separated",
+		},
+		{
+			name: "test when a resource has etcd-shards-overrides and etcd-servers-overrides",
+			testOptions: &EtcdOptions{
+				StorageConfig: storagebackend.Config{
+					Type:   "etcd3",
+					Prefix: "/registry",
+					Transport: storagebackend.TransportConfig{
+						ServerList: []string{"http://127.0.0.1"},
+					},
+					CompactionInterval:    storagebackend.DefaultCompactInterval,
+					CountMetricPollPeriod: time.Minute,
+				},
+				DefaultStorageMediaType: "application/vnd.kubernetes.protobuf",
+				DeleteCollectionWorkers: 1,
+				EnableGarbageCollection: true,
+				EnableWatchCache:        true,
+				DefaultWatchCacheSize:   100,
+				EtcdServersOverrides:    []string{"/events#http://127.0.0.1:4002"},
+				EtcdShardsOverrides:     []string{"/events#http://127.0.0.1:4003", "/events#http://127.0.0.1:4004"},
+			},
+			expectErr: "--etcd-shards-overrides invalid, /events also has --etcd-servers-overrides",
+		},
+		{
+			name: "test when etcd-shards-overrides is set for the memory storage",
+			testOptions: &EtcdOptions{
+				StorageConfig: storagebackend.Config{
+					Type:                  "memory",
+					Prefix:                "/registry",
+					CompactionInterval:    storagebackend.DefaultCompactInterval,
+					CountMetricPollPeriod: time.Minute,
+				},
+				DefaultStorageMediaType: "application/vnd.kubernetes.protobuf",
+				DeleteCollectionWorkers: 1,
+				EnableGarbageCollection: true,
+				EnableWatchCache:        true,
+				DefaultWatchCacheSize:   100,
+				EtcdShardsOverrides:     []string{"/pods#http://127.0.0.1:4003", "/pods#http://127.0.0.1:4004"},
+			},
+			expectErr: "--etcd-shards-overrides is only supported by the etcd3 storage backend",
+		},
+		{
+			name: "test when embedded storage has no data dir",
+			testOptions: &EtcdOptions{
+				StorageConfig: storagebackend.Config{
+					Type:                  "embedded",
+					Prefix:                "/registry",
+					CompactionInterval:    storagebackend.DefaultCompactInterval,
+					CountMetricPollPeriod: time.Minute,
+				},
+				DefaultStorageMediaType: "application/vnd.kubernetes.protobuf",
+				DeleteCollectionWorkers: 1,
+				EnableGarbageCollection: true,
+				EnableWatchCache:        true,
+				DefaultWatchCacheSize:   100,
+			},
+			expectErr: "--storage-data-dir must be specified for the embedded storage backend",
+		},
+		{
+			name: "test when embedded storage is valid without etcd servers",
+			testOptions: &EtcdOptions{
+				StorageConfig: storagebackend.Config{
+					Type:                  "embedded",
+					Prefix:                "/registry",
+					DataDir:               "/var/lib/krmapiserver",
+					CompactionInterval:    storagebackend.DefaultCompactInterval,
+					CountMetricPollPeriod: time.Minute,
+				},
+				DefaultStorageMediaType: "application/vnd.kubernetes.protobuf",
+				DeleteCollectionWorkers: 1,
+				EnableGarbageCollection: true,
+				EnableWatchCache:        true,
+				DefaultWatchCacheSize:   100,
+			},
+		},
+		{
+			name: "test when memory storage is valid without etcd servers",
+			testOptions: &EtcdOptions{
+				StorageConfig: storagebackend.Config{
+					Type:                  "memory",
+					Prefix:                "/registry",
+					CompactionInterval:    storagebackend.DefaultCompactInterval,
+					CountMetricPollPeriod: time.Minute,
+				},
+				DefaultStorageMediaType: "application/vnd.kubernetes.protobuf",
+				DeleteCollectionWorkers: 1,
+				EnableGarbageCollection: true,
+				EnableWatchCache:        true,
+				DefaultWatchCacheSize:   100,
+			},
+		},
+		{
+			name: "test when watch cache snapshot interval is not positive",
+			testOptions: &EtcdOptions{
+				StorageConfig: storagebackend.Config{
+					Type:                       "memory",
+					Prefix:                     "/registry",
+					CompactionInterval:         storagebackend.DefaultCompactInterval,
+					CountMetricPollPeriod:      time.Minute,
+					WatchCacheSnapshotDir:      "/var/lib/kube-apiserver/watch-cache",
+					WatchCacheSnapshotInterval: 0,
+				},
+				DefaultStorageMediaType: "application/vnd.kubernetes.protobuf",
+				DeleteCollectionWorkers: 1,
+				EnableGarbageCollection: true,
+				EnableWatchCache:        true,
+				DefaultWatchCacheSize:   100,
+			},
+			expectErr: "--watch-cache-snapshot-interval must be greater than 0 if --watch-cache-snapshot-dir is set",
+		},
 		{
 			name: "test when EtcdOptions is valid",
 			testOptions: &EtcdOptions{
@@ -125,6 +258,7 @@ func TestEtcdOptionsValidate(t *testing.T) {
 				EnableWatchCache:        true,
 				DefaultWatchCacheSize:   100,
 				EtcdServersOverrides:    []string{"/events#http://127.0.0.1:4002"},
+				EtcdShardsOverrides:     []string{"/pods#http://127.0.0.1:4003", "/pods#http://127.0.0.1:4004"},
 			},
 		},
 	}
@@ -194,3 +328,194 @@ func TestParseWatchCacheSizes(t *testing.T) {
 		})
 	}
 }
+
+func TestParseObjectHistory(t *testing.T) {
+	testCases := []struct {
+		name          string
+		settings      []string
+		expectHistory map[schema.GroupResource]generic.HistoryOptions
+		expectErr     string
+	}{
+		{
+			name:      "test when invalid value of object history",
+			settings:  []string{"deployments.apps#10", "configmaps"},
+			expectErr: "invalid value of object history",
+		},
+		{
+			name:      "test when invalid retention of object history",
+			settings:  []string{"deployments.apps#10", "configmaps#1d"},
+			expectErr: "invalid retention of object history",
+		},
+		{
+			name:      "test when retention of object history is not positive",
+			settings:  []string{"deployments.apps#0"},
+			expectErr: "retention of object history must be positive",
+		},
+		{
+			name:      "test when object history is set twice",
+			settings:  []string{"deployments.apps#10", "deployments.apps#1h"},
+			expectErr: "object history set more than once",
+		},
+		{
+			name:     "test when parse object history success",
+			settings: []string{"deployments.apps#10", "configmaps#24h"},
+			expectHistory: map[schema.GroupResource]generic.HistoryOptions{
+				{Group: "apps", Resource: "deployments"}: {Limit: 10},
+				{Resource: "configmaps"}:                 {Window: 24 * time.Hour},
+			},
+		},
+	}
+
+	for _, testcase := range testCases {
+		t.Run(testcase.name, func(t *testing.T) {
+			result, err := ParseObjectHistory(testcase.settings)
+			if len(testcase.expectErr) != 0 {
+				if err == nil || !strings.Contains(err.Error(), testcase.expectErr) {
+					t.Errorf("got err: %v, expected err: %s", err, testcase.expectErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("got err: %v, expected err nil", err)
+			}
+			if !reflect.DeepEqual(result, testcase.expectHistory) {
+				t.Errorf("got object history: %v, expected object history %v", result, testcase.expectHistory)
+			}
+		})
+	}
+}
+
+func TestParseSoftDelete(t *testing.T) {
+	testCases := []struct {
+		name        string
+		settings    []string
+		expectTrash map[schema.GroupResource]generic.TrashOptions
+		expectErr   string
+	}{
+		{
+			name:      "test when invalid value of soft delete",
+			settings:  []string{"namespaces#72h", "configmaps"},
+			expectErr: "invalid value of soft delete",
+		},
+		{
+			name:      "test when invalid retention of soft delete",
+			settings:  []string{"namespaces#72h", "configmaps#10"},
+			expectErr: "invalid retention of soft delete",
+		},
+		{
+			name:      "test when retention of soft delete is not positive",
+			settings:  []string{"namespaces#0s"},
+			expectErr: "retention of soft delete must be positive",
+		},
+		{
+			name:      "test when soft delete is set twice",
+			settings:  []string{"namespaces#72h", "namespaces#1h"},
+			expectErr: "soft delete set more than once",
+		},
+		{
+			name:     "test when parse soft delete success",
+			settings: []string{"namespaces#72h", "widgets.example.com#30m"},
+			expectTrash: map[schema.GroupResource]generic.TrashOptions{
+				{Resource: "namespaces"}:                    {Retention: 72 * time.Hour},
+				{Group: "example.com", Resource: "widgets"}: {Retention: 30 * time.Minute},
+			},
+		},
+	}
+
+	for _, testcase := range testCases {
+		t.Run(testcase.name, func(t *testing.T) {
+			result, err := ParseSoftDelete(testcase.settings)
+			if len(testcase.expectErr) != 0 {
+				if err == nil || !strings.Contains(err.Error(), testcase.expectErr) {
+					t.Errorf("got err: %v, expected err: %s", err, testcase.expectErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("got err: %v, expected err nil", err)
+			}
+			if !reflect.DeepEqual(result, testcase.expectTrash) {
+				t.Errorf("got trash: %v, expected trash %v", result, testcase.expectTrash)
+			}
+		})
+	}
+}
+
+func TestParseSelectableFields(t *testing.T) {
+	testCases := []struct {
+		name             string
+		settings         []string
+		expectSelectable map[schema.GroupResource][]string
+		expectErr        string
+	}{
+		{
+			name:      "test when invalid value of selectable field",
+			settings:  []string{"deployments.apps#spec.replicas", "pods"},
+			expectErr: "invalid value of selectable field",
+		},
+		{
+			name:      "test when invalid path of selectable field",
+			settings:  []string{"deployments.apps#spec..replicas"},
+			expectErr: "invalid selectable field",
+		},
+		{
+			name:      "test when selectable field is set twice",
+			settings:  []string{"deployments.apps#spec.replicas", "deployments.apps#.spec.replicas"},
+			expectErr: "selectable field set more than once",
+		},
+		{
+			name:     "test when parse selectable fields success",
+			settings: []string{"deployments.apps#spec.replicas", "deployments.apps#.status.readyReplicas", "pods#spec.priority"},
+			expectSelectable: map[schema.GroupResource][]string{
+				{Group: "apps", Resource: "deployments"}: {"spec.replicas", "status.readyReplicas"},
+				{Resource: "pods"}:                       {"spec.priority"},
+			},
+		},
+	}
+
+	for _, testcase := range testCases {
+		t.Run(testcase.name, func(t *testing.T) {
+			result, err := ParseSelectableFields(testcase.settings)
+			if len(testcase.expectErr) != 0 {
+				if err == nil || !strings.Contains(err.Error(), testcase.expectErr) {
+					t.Errorf("got err: %v, expected err: %s", err, testcase.expectErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("got err: %v, expected err nil", err)
+			}
+			if !reflect.DeepEqual(result, testcase.expectSelectable) {
+				t.Errorf("got selectable fields: %v, expected selectable fields %v", result, testcase.expectSelectable)
+			}
+		})
+	}
+}
+
+func TestApplyEnablesCacheCheck(t *testing.T) {
+	tests := []struct {
+		name             string
+		enableWatchCache bool
+		interval         time.Duration
+		expected         bool
+	}{
+		{name: "checked watch caches", enableWatchCache: true, interval: time.Minute, expected: true},
+		{name: "unchecked watch caches", enableWatchCache: true},
+		{name: "no watch caches", interval: time.Minute},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			options := NewEtcdOptions(storagebackend.NewDefaultConfig("/registry", nil))
+			options.StorageConfig.Type = storagebackend.StorageTypeMemory
+			options.EnableWatchCache = tc.enableWatchCache
+			options.StorageConfig.WatchCacheConsistencyCheckInterval = tc.interval
+			c := &server.Config{}
+			if err := options.ApplyTo(c); err != nil {
+				t.Fatal(err)
+			}
+			if c.EnableCacheCheck != tc.expected {
+				t.Errorf("expected EnableCacheCheck %t, got %t", tc.expected, c.EnableCacheCheck)
+			}
+		})
+	}
+}
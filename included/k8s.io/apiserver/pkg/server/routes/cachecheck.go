@@ -0,0 +1,52 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package routes
+
+import (
+	"net/http"
+
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/endpoints/handlers/responsewriters"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/server/mux"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/cacher"
+)
+
+// CacheCheck adds a handler for the results of the watch cache consistency
+// checks under /debug/cachecheck.
+type CacheCheck struct{}
+
+// cacheCheckStatus is the response of /debug/cachecheck.
+type cacheCheckStatus struct {
+	// Diverged are the resource prefixes whose watch cache diverged from
+	// storage at the last check.
+	Diverged []string                        `json:"diverged"`
+	Results  []cacher.ConsistencyCheckResult `json:"results"`
+}
+
+// Install registers the APIServer's `/debug/cachecheck` handler.
+func (CacheCheck) Install(c *mux.PathRecorderMux) {
+	c.UnlistedHandleFunc("/debug/cachecheck", handleCacheCheck)
+}
+
+func handleCacheCheck(w http.ResponseWriter, req *http.Request) {
+	status := cacheCheckStatus{Diverged: []string{}, Results: cacher.ConsistencyCheckResults()}
+	for _, result := range status.Results {
+		if result.Diverged {
+			status.Diverged = append(status.Diverged, result.ResourcePrefix)
+		}
+	}
+	responsewriters.WriteRawJSON(http.StatusOK, status, w)
+}
@@ -0,0 +1,43 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package routes
+
+import (
+	"net/http"
+
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/endpoints/handlers/responsewriters"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/server/mux"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/reencryption"
+)
+
+// ReencryptionStatus adds a handler for the progress of the re-encryption of
+// stored objects under /encryption-status.
+type ReencryptionStatus struct{}
+
+// reencryptionStatus is the response of /encryption-status.
+type reencryptionStatus struct {
+	Resources []reencryption.ResourceStatus `json:"resources"`
+}
+
+// Install registers the APIServer's `/encryption-status` handler.
+func (ReencryptionStatus) Install(c *mux.PathRecorderMux) {
+	c.UnlistedHandleFunc("/encryption-status", handleReencryptionStatus)
+}
+
+func handleReencryptionStatus(w http.ResponseWriter, req *http.Request) {
+	responsewriters.WriteRawJSON(http.StatusOK, reencryptionStatus{Resources: reencryption.Statuses()}, w)
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package routes
+
+import (
+	"io/ioutil"
+	"net/http"
+
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/endpoints/handlers/responsewriters"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/server/mux"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/faultinjection"
+)
+
+// StorageFaults adds a handler for the storage fault injection rules under
+// /debug/storage/faults. GET returns the rules, and PUT replaces them with
+// the YAML or JSON configuration in the request body.
+type StorageFaults struct{}
+
+// Install registers the APIServer's `/debug/storage/faults` handler.
+func (StorageFaults) Install(c *mux.PathRecorderMux) {
+	c.UnlistedHandleFunc("/debug/storage/faults", handleStorageFaults)
+}
+
+func handleStorageFaults(w http.ResponseWriter, req *http.Request) {
+	injector := faultinjection.DefaultInjector()
+	if !injector.Enabled() {
+		writePlainText(http.StatusNotFound, "storage fault injection is not enabled, see --enable-storage-fault-injection", w)
+		return
+	}
+	switch req.Method {
+	case "GET":
+	case "PUT":
+		body, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			writePlainText(http.StatusBadRequest, "error reading request body: "+err.Error(), w)
+			return
+		}
+		defer req.Body.Close()
+		config, err := faultinjection.ParseConfig(body)
+		if err != nil {
+			writePlainText(http.StatusBadRequest, err.Error(), w)
+			return
+		}
+		if err := injector.SetConfig(config); err != nil {
+			writePlainText(http.StatusBadRequest, err.Error(), w)
+			return
+		}
+	default:
+		writePlainText(http.StatusNotAcceptable, "unsupported http method", w)
+		return
+	}
+	responsewriters.WriteRawJSON(http.StatusOK, injector.Config(), w)
+}
@@ -85,14 +85,27 @@ type DefaultStorageFactory struct {
 	// is left to the caller.
 	APIResourceConfigSource APIResourceConfigSource
 
+	// resourceTransformers, if set, provides the transformers of all resources
+	// that have no transformer override.
+	resourceTransformers ResourceTransformers
+
 	// newStorageCodecFn exists to be overwritten for unit testing.
 	newStorageCodecFn func(opts StorageCodecConfig) (codec runtime.Codec, encodeVersioner runtime.GroupVersioner, err error)
 }
 
+// ResourceTransformers provides the transformers that encrypt resources at
+// rest.
+type ResourceTransformers interface {
+	// TransformerForResource returns the transformer of groupResource.
+	TransformerForResource(groupResource schema.GroupResource) value.Transformer
+}
+
 type groupResourceOverrides struct {
 	// etcdLocation contains the list of "special" locations that are used for particular GroupResources
 	// These are merged on top of the StorageConfig when requesting the storage.Interface for a given GroupResource
 	etcdLocation []string
+	// etcdShards contains the servers of the shards the GroupResource is spread across, in order.
+	etcdShards [][]string
 	// etcdPrefix is the base location for a GroupResource.
 	etcdPrefix string
 	// etcdResourcePrefix is the location to use to store a particular type under the `etcdPrefix` location
@@ -123,6 +136,14 @@ func (o groupResourceOverrides) Apply(config *storagebackend.Config, options *St
 	if len(o.etcdLocation) > 0 {
 		config.Transport.ServerList = o.etcdLocation
 	}
+	if len(o.etcdShards) > 0 {
+		config.Sharding.Shards = make([]storagebackend.TransportConfig, len(o.etcdShards))
+		for i, servers := range o.etcdShards {
+			shard := config.Transport
+			shard.ServerList = servers
+			config.Sharding.Shards[i] = shard
+		}
+	}
 	if len(o.etcdPrefix) > 0 {
 		config.Prefix = o.etcdPrefix
 	}
@@ -182,6 +203,14 @@ func (s *DefaultStorageFactory) SetEtcdLocation(groupResource schema.GroupResour
 	s.Overrides[groupResource] = overrides
 }
 
+// SetEtcdShards spreads a resource across several etcd clusters, which are
+// given by their servers, in order.
+func (s *DefaultStorageFactory) SetEtcdShards(groupResource schema.GroupResource, shards [][]string) {
+	overrides := s.Overrides[groupResource]
+	overrides.etcdShards = shards
+	s.Overrides[groupResource] = overrides
+}
+
 func (s *DefaultStorageFactory) SetEtcdPrefix(groupResource schema.GroupResource, prefix string) {
 	overrides := s.Overrides[groupResource]
 	overrides.etcdPrefix = prefix
@@ -216,6 +245,12 @@ func (s *DefaultStorageFactory) SetTransformer(groupResource schema.GroupResourc
 	s.Overrides[groupResource] = overrides
 }
 
+// SetResourceTransformers sets the transformers of the resources that have
+// no transformer set with SetTransformer.
+func (s *DefaultStorageFactory) SetResourceTransformers(transformers ResourceTransformers) {
+	s.resourceTransformers = transformers
+}
+
 // AddCohabitatingResources links resources together the order of the slice matters!  its the priority order of lookup for finding a storage location
 func (s *DefaultStorageFactory) AddCohabitatingResources(groupResources ...schema.GroupResource) {
 	for _, groupResource := range groupResources {
@@ -259,6 +294,9 @@ func (s *DefaultStorageFactory) NewConfig(groupResource schema.GroupResource) (*
 		StorageMediaType:  s.DefaultMediaType,
 		StorageSerializer: s.DefaultSerializer,
 	}
+	if s.resourceTransformers != nil {
+		storageConfig.Transformer = s.resourceTransformers.TransformerForResource(chosenStorageResource)
+	}
 
 	if override, ok := s.Overrides[getAllResourcesAlias(chosenStorageResource)]; ok {
 		override.Apply(&storageConfig, &codecConfig)
@@ -267,6 +305,8 @@ func (s *DefaultStorageFactory) NewConfig(groupResource schema.GroupResource) (*
 		override.Apply(&storageConfig, &codecConfig)
 	}
 
+	storageConfig.ResourcePrefix = s.ResourcePrefix(groupResource)
+
 	var err error
 	codecConfig.StorageVersion, err = s.ResourceEncodingConfig.StorageEncodingFor(chosenStorageResource)
 	if err != nil {
@@ -282,6 +322,11 @@ func (s *DefaultStorageFactory) NewConfig(groupResource schema.GroupResource) (*
 	if err != nil {
 		return nil, err
 	}
+	if codecConfig.StorageSerializer != nil {
+		if info, ok := runtime.SerializerInfoForMediaType(codecConfig.StorageSerializer.SupportedMediaTypes(), runtime.ContentTypeJSON); ok {
+			storageConfig.JSONEncoder = codecConfig.StorageSerializer.EncoderForVersion(info.Serializer, storageConfig.EncodeVersioner)
+		}
+	}
 	klog.V(3).Infof("storing %v in %v, reading as %v from %#v", groupResource, codecConfig.StorageVersion, codecConfig.MemoryVersion, codecConfig.Config)
 
 	return &storageConfig, nil
@@ -294,6 +339,9 @@ func (s *DefaultStorageFactory) Backends() []Backend {
 
 	for _, overrides := range s.Overrides {
 		servers.Insert(overrides.etcdLocation...)
+		for _, shard := range overrides.etcdShards {
+			servers.Insert(shard...)
+		}
 	}
 
 	tlsConfig := &tls.Config{
@@ -0,0 +1,64 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime/schema"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/storagebackend"
+	clientgoscheme "github.com/aaron-prindle/krmapiserver/included/k8s.io/client-go/kubernetes/scheme"
+)
+
+func TestEtcdShardsOverrides(t *testing.T) {
+	defaultConfig := storagebackend.Config{
+		Prefix: "/registry",
+		Transport: storagebackend.TransportConfig{
+			ServerList: []string{"http://127.0.0.1"},
+			CAFile:     "/var/run/kubernetes/etcdca.crt",
+		},
+	}
+	resource := schema.GroupResource{Resource: "pods"}
+	storageFactory := NewDefaultStorageFactory(defaultConfig, "", clientgoscheme.Codecs, NewDefaultResourceEncodingConfig(clientgoscheme.Scheme), NewResourceConfig(), nil)
+	storageFactory.SetEtcdShards(resource, [][]string{{"http://127.0.0.1:10000"}, {"http://127.0.0.1:20000", "http://127.0.0.1:20001"}})
+
+	config, err := storageFactory.NewConfig(resource)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := storagebackend.ShardingConfig{
+		Shards: []storagebackend.TransportConfig{
+			{ServerList: []string{"http://127.0.0.1:10000"}, CAFile: "/var/run/kubernetes/etcdca.crt"},
+			{ServerList: []string{"http://127.0.0.1:20000", "http://127.0.0.1:20001"}, CAFile: "/var/run/kubernetes/etcdca.crt"},
+		},
+	}
+	if !reflect.DeepEqual(config.Sharding, expected) {
+		t.Errorf("expected %#v, got %#v", expected, config.Sharding)
+	}
+	if config.ResourcePrefix != "pods" {
+		t.Errorf("expected resource prefix %q, got %q", "pods", config.ResourcePrefix)
+	}
+
+	config, err = storageFactory.NewConfig(schema.GroupResource{Resource: "services"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(config.Sharding.Shards) != 0 {
+		t.Errorf("expected no shards, got %#v", config.Sharding)
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"net/http"
 	"reflect"
+	"strings"
 	"sync"
 	"time"
 
@@ -37,8 +38,11 @@ import (
 	utilruntime "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/util/runtime"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/util/wait"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/watch"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/endpoints/request"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/features"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/usage"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/value"
 	utilfeature "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/util/feature"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/client-go/tools/cache"
 	utiltrace "github.com/aaron-prindle/krmapiserver/included/k8s.io/utils/trace"
@@ -54,6 +58,38 @@ var (
 		},
 		[]string{"resource"},
 	)
+	consistentListCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "apiserver_watch_cache_consistent_list_total",
+			Help: "Counter of lists without a resourceVersion handled by the watch cache broken by resource type and the path that served them: " +
+				"'cache', 'storage' if the watch cache was not initialized, or 'fallback' if it did not catch up with storage in time",
+		},
+		[]string{"resource", "path"},
+	)
+	paginatedListCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "apiserver_watch_cache_paginated_list_total",
+			Help: "Counter of paginated lists handled by the watch cache broken by resource type and the path that served them: " +
+				"'cache', or 'storage' if the watch cache has no snapshot at the requested resource version",
+		},
+		[]string{"resource", "path"},
+	)
+	indexRequestsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "apiserver_watch_cache_index_requests_total",
+			Help: "Counter of lists and watches served by the watch cache broken by resource type, operation and the index " +
+				"used to select the objects or events, 'none' if no index was used",
+		},
+		[]string{"resource", "operation", "index"},
+	)
+	snapshotCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "apiserver_watch_cache_snapshots_total",
+			Help: "Counter of on-disk snapshots of the watch cache broken by resource type, operation ('save' or 'restore') " +
+				"and result ('success' or 'error')",
+		},
+		[]string{"resource", "operation", "result"},
+	)
 	emptyFunc = func() {}
 )
 
@@ -65,6 +101,10 @@ const (
 
 func init() {
 	prometheus.MustRegister(initCounter)
+	prometheus.MustRegister(consistentListCounter)
+	prometheus.MustRegister(paginatedListCounter)
+	prometheus.MustRegister(indexRequestsCounter)
+	prometheus.MustRegister(snapshotCounter)
 }
 
 // Config contains the configuration for a given Cache.
@@ -88,9 +128,13 @@ type Config struct {
 	// GetAttrsFunc is used to get object labels, fields
 	GetAttrsFunc func(runtime.Object) (label labels.Set, field fields.Set, err error)
 
-	// TriggerPublisherFunc is used for optimizing amount of watchers that
-	// needs to process an incoming event.
-	TriggerPublisherFunc storage.TriggerPublisherFunc
+	// Indexers are the indexes of the cached objects. Lists and watches whose
+	// predicate matches only objects with a given value of one of the indexes
+	// (see storage.SelectionPredicate.MatcherIndex) only process those objects
+	// and the events of those objects. The names of the indexes are the ones
+	// returned by storage.FieldIndex and storage.LabelIndex. The namespace of
+	// the request is used with storage.NamespaceIndex.
+	Indexers *cache.Indexers
 
 	// NewFunc is a function that creates new empty object storing a object of type Type.
 	NewFunc func() runtime.Object
@@ -100,6 +144,24 @@ type Config struct {
 	NewListFunc func() runtime.Object
 
 	Codec runtime.Codec
+
+	// SnapshotPath, if not empty, is the file the state of the cache is
+	// periodically saved to. On start, the cache is restored from it and
+	// resumes watching the underlying storage from the saved resourceVersion,
+	// or relists the underlying storage if that resourceVersion has been
+	// compacted.
+	SnapshotPath string
+	// SnapshotInterval is how often the state of the cache is saved.
+	SnapshotInterval time.Duration
+	// SnapshotTransformer transforms the saved state of the cache, as the
+	// underlying storage transforms the objects it persists. Defaults to
+	// value.IdentityTransformer.
+	SnapshotTransformer value.Transformer
+
+	// ConsistencyCheckInterval, if positive, is how often the contents of
+	// the cache are compared with the contents of the underlying storage at
+	// the same resourceVersion. See ConsistencyCheckResults.
+	ConsistencyCheckInterval time.Duration
 }
 
 type watchersMap map[int]*cacheWatcher
@@ -123,26 +185,36 @@ func (wm watchersMap) terminateAll(done func(*cacheWatcher)) {
 }
 
 type indexedWatchers struct {
-	allWatchers   watchersMap
-	valueWatchers map[string]watchersMap
+	allWatchers watchersMap
+	// valueWatchers maps the name of an index to the watchers interested in
+	// the given values of that index.
+	valueWatchers map[string]map[string]watchersMap
 }
 
-func (i *indexedWatchers) addWatcher(w *cacheWatcher, number int, value string, supported bool) {
+func (i *indexedWatchers) addWatcher(w *cacheWatcher, number int, trigger storage.MatchValue, supported bool) {
 	if supported {
-		if _, ok := i.valueWatchers[value]; !ok {
-			i.valueWatchers[value] = watchersMap{}
+		if _, ok := i.valueWatchers[trigger.IndexName]; !ok {
+			i.valueWatchers[trigger.IndexName] = map[string]watchersMap{}
 		}
-		i.valueWatchers[value].addWatcher(w, number)
+		indexWatchers := i.valueWatchers[trigger.IndexName]
+		if _, ok := indexWatchers[trigger.Value]; !ok {
+			indexWatchers[trigger.Value] = watchersMap{}
+		}
+		indexWatchers[trigger.Value].addWatcher(w, number)
 	} else {
 		i.allWatchers.addWatcher(w, number)
 	}
 }
 
-func (i *indexedWatchers) deleteWatcher(number int, value string, supported bool, done func(*cacheWatcher)) {
+func (i *indexedWatchers) deleteWatcher(number int, trigger storage.MatchValue, supported bool, done func(*cacheWatcher)) {
 	if supported {
-		i.valueWatchers[value].deleteWatcher(number, done)
-		if len(i.valueWatchers[value]) == 0 {
-			delete(i.valueWatchers, value)
+		indexWatchers := i.valueWatchers[trigger.IndexName]
+		indexWatchers[trigger.Value].deleteWatcher(number, done)
+		if len(indexWatchers[trigger.Value]) == 0 {
+			delete(indexWatchers, trigger.Value)
+		}
+		if len(indexWatchers) == 0 {
+			delete(i.valueWatchers, trigger.IndexName)
 		}
 	} else {
 		i.allWatchers.deleteWatcher(number, done)
@@ -154,9 +226,12 @@ func (i *indexedWatchers) terminateAll(objectType reflect.Type, done func(*cache
 		klog.Warningf("Terminating all watchers from cacher %v", objectType)
 	}
 	i.allWatchers.terminateAll(done)
-	for index, watchers := range i.valueWatchers {
-		watchers.terminateAll(done)
-		delete(i.valueWatchers, index)
+	for indexName, indexWatchers := range i.valueWatchers {
+		for value, watchers := range indexWatchers {
+			watchers.terminateAll(done)
+			delete(indexWatchers, value)
+		}
+		delete(i.valueWatchers, indexName)
 	}
 }
 
@@ -207,7 +282,7 @@ func (t *watcherBookmarkTimeBuckets) popExpiredWatchers() [][]*cacheWatcher {
 	return expiredWatchers
 }
 
-type filterWithAttrsFunc func(key string, l labels.Set, f fields.Set) bool
+type filterWithAttrsFunc func(key string, l labels.Set, f fields.Set, a labels.Set) bool
 
 // Cacher is responsible for serving WATCH and LIST requests for a given
 // resource from its internal cache and updating its cache in the background
@@ -238,21 +313,37 @@ type Cacher struct {
 	// Expected type of objects in the underlying cache.
 	objectType reflect.Type
 
+	// resourcePrefix is the directory the objects are stored under in the
+	// underlying storage.
+	resourcePrefix string
+	// newListFunc is a function that creates new empty list for storing objects of type Type.
+	newListFunc func() runtime.Object
+
 	// "sliding window" of recent changes of objects and the current state.
 	watchCache *watchCache
 	reflector  *cache.Reflector
 
+	// snapshotFile, if not nil, is the file the state of watchCache is
+	// periodically saved to, and snapshotResourceVersion the resourceVersion
+	// it was last saved at.
+	snapshotFile            *snapshotFile
+	snapshotResourceVersion uint64
+
+	// consistencyChecked is true if the cache is periodically compared with
+	// the underlying storage.
+	consistencyChecked bool
+
 	// Versioner is used to handle resource versions.
 	versioner storage.Versioner
 
 	// newFunc is a function that creates new empty object storing a object of type Type.
 	newFunc func() runtime.Object
 
-	// triggerFunc is used for optimizing amount of watchers that needs to process
+	// indexers are used for optimizing amount of watchers that needs to process
 	// an incoming event.
-	triggerFunc storage.TriggerPublisherFunc
-	// watchers is mapping from the value of trigger function that a
-	// watcher is interested into the watchers
+	indexers cache.Indexers
+	// watchers is mapping from the index values that a watcher is
+	// interested into the watchers
 	watcherIdx int
 	watchers   indexedWatchers
 
@@ -300,16 +391,17 @@ func NewCacherFromConfig(config Config) *Cacher {
 
 	clock := clock.RealClock{}
 	cacher := &Cacher{
-		ready:       newReady(),
-		storage:     config.Storage,
-		objectType:  reflect.TypeOf(obj),
-		versioner:   config.Versioner,
-		newFunc:     config.NewFunc,
-		triggerFunc: config.TriggerPublisherFunc,
-		watcherIdx:  0,
+		ready:          newReady(),
+		storage:        config.Storage,
+		objectType:     reflect.TypeOf(obj),
+		resourcePrefix: config.ResourcePrefix,
+		newListFunc:    config.NewListFunc,
+		versioner:      config.Versioner,
+		newFunc:        config.NewFunc,
+		watcherIdx:     0,
 		watchers: indexedWatchers{
 			allWatchers:   make(map[int]*cacheWatcher),
-			valueWatchers: make(map[string]watchersMap),
+			valueWatchers: make(map[string]map[string]watchersMap),
 		},
 		// TODO: Figure out the correct value for the buffer size.
 		incoming:              make(chan watchCacheEvent, 100),
@@ -333,9 +425,27 @@ func NewCacherFromConfig(config Config) *Cacher {
 		<-cacher.timer.C
 	}
 
+	if config.Indexers != nil {
+		cacher.indexers = *config.Indexers
+	}
+
 	watchCache := newWatchCache(
-		config.CacheCapacity, config.KeyFunc, cacher.processEvent, config.GetAttrsFunc, config.Versioner)
+		config.CacheCapacity, config.KeyFunc, cacher.processEvent, config.GetAttrsFunc, config.Versioner, config.Indexers)
 	listerWatcher := NewCacherListerWatcher(config.Storage, config.ResourcePrefix, config.NewListFunc)
+	if len(config.SnapshotPath) > 0 {
+		transformer := config.SnapshotTransformer
+		if transformer == nil {
+			transformer = value.IdentityTransformer
+		}
+		cacher.snapshotFile = &snapshotFile{
+			path:        config.SnapshotPath,
+			codec:       config.Codec,
+			transformer: transformer,
+			versioner:   config.Versioner,
+			newListFunc: config.NewListFunc,
+		}
+		listerWatcher = newRestoringListerWatcher(listerWatcher, cacher.snapshotFile, cacher.objectType.String())
+	}
 	reflectorName := "storage/cacher.go:" + config.ResourcePrefix
 
 	reflector := cache.NewNamedReflector(reflectorName, listerWatcher, obj, watchCache, 0)
@@ -361,6 +471,28 @@ func NewCacherFromConfig(config Config) *Cacher {
 		)
 	}()
 
+	if cacher.snapshotFile != nil {
+		interval := config.SnapshotInterval
+		if interval <= 0 {
+			interval = defaultSnapshotInterval
+		}
+		cacher.stopWg.Add(1)
+		go func() {
+			defer cacher.stopWg.Done()
+			wait.Until(cacher.saveSnapshot, interval, stopCh)
+		}()
+	}
+
+	if config.ConsistencyCheckInterval > 0 {
+		cacher.consistencyChecked = true
+		cacher.stopWg.Add(1)
+		go func() {
+			defer cacher.stopWg.Done()
+			// Jitter spreads the lists of the checks of all cachers.
+			wait.JitterUntil(cacher.checkConsistency, config.ConsistencyCheckInterval, 1.0, true, stopCh)
+		}()
+	}
+
 	return cacher
 }
 
@@ -416,24 +548,26 @@ func (c *Cacher) Watch(ctx context.Context, key string, resourceVersion string,
 
 	c.ready.wait()
 
-	triggerValue, triggerSupported := "", false
-	// TODO: Currently we assume that in a given Cacher object, any <predicate> that is
-	// passed here is aware of exactly the same trigger (at most one).
-	// Thus, either 0 or 1 values will be returned.
-	if matchValues := pred.MatcherIndex(); len(matchValues) > 0 {
-		triggerValue, triggerSupported = matchValues[0].Value, true
+	// The watcher is only sent the events of objects with the value of the
+	// first index it can be narrowed to.
+	trigger, triggerSupported := storage.MatchValue{}, false
+	if matchValues := c.indexedMatchValues(ctx, pred); len(matchValues) > 0 {
+		trigger, triggerSupported = matchValues[0], true
+		indexRequestsCounter.WithLabelValues(c.objectType.String(), "watch", trigger.IndexName).Inc()
+	} else {
+		indexRequestsCounter.WithLabelValues(c.objectType.String(), "watch", "none").Inc()
 	}
 
-	// If there is triggerFunc defined, but triggerSupported is false,
+	// If there are indexers defined, but triggerSupported is false,
 	// we can't narrow the amount of events significantly at this point.
 	//
-	// That said, currently triggerFunc is defined only for Pods and Nodes,
+	// That said, currently indexers are defined only for few resources,
 	// and there is only constant number of watchers for which triggerSupported
 	// is false (excluding those issues explicitly by users).
 	// Thus, to reduce the risk of those watchers blocking all watchers of a
 	// given resource in the system, we increase the sizes of buffers for them.
 	chanSize := 10
-	if c.triggerFunc != nil && !triggerSupported {
+	if len(c.indexers) > 0 && !triggerSupported {
 		// TODO: We should tune this value and ideally make it dependent on the
 		// number of objects of a given type and/or their churn.
 		chanSize = 1000
@@ -446,6 +580,7 @@ func (c *Cacher) Watch(ctx context.Context, key string, resourceVersion string,
 	// Also note that emptyFunc is a placeholder, until we will be able
 	// to compute watcher.forget function (which has to happen under lock).
 	watcher := newCacheWatcher(chanSize, filterWithAttrsFunction(key, pred), emptyFunc, c.versioner, deadline, pred.AllowWatchBookmarks, c.objectType)
+	watcher.cacheableObjects = request.CacheableObjectsFrom(ctx)
 
 	// We explicitly use thread unsafe version and do locking ourself to ensure that
 	// no new events will be processed in the meantime. The watchCache will be unlocked
@@ -473,8 +608,8 @@ func (c *Cacher) Watch(ctx context.Context, key string, resourceVersion string,
 		c.Lock()
 		defer c.Unlock()
 		// Update watcher.forget function once we can compute it.
-		watcher.forget = forgetWatcher(c, c.watcherIdx, triggerValue, triggerSupported)
-		c.watchers.addWatcher(watcher, c.watcherIdx, triggerValue, triggerSupported)
+		watcher.forget = forgetWatcher(c, c.watcherIdx, trigger, triggerSupported)
+		c.watchers.addWatcher(watcher, c.watcherIdx, trigger, triggerSupported)
 
 		// Add it to the queue only when server and client support watch bookmarks.
 		if c.watchBookmarkEnabled && watcher.allowWatchBookmarks {
@@ -548,15 +683,22 @@ func (c *Cacher) GetToList(ctx context.Context, key string, resourceVersion stri
 	pagingEnabled := utilfeature.DefaultFeatureGate.Enabled(features.APIListChunking)
 	hasContinuation := pagingEnabled && len(pred.Continue) > 0
 	hasLimit := pagingEnabled && pred.Limit > 0 && resourceVersion != "0"
-	if resourceVersion == "" || hasContinuation || hasLimit {
-		// If resourceVersion is not specified, serve it from underlying
-		// storage (for backward compatibility). If a continuation is
-		// requested, serve it from the underlying storage as well.
+	if hasContinuation || hasLimit {
+		// If a continuation is requested, serve it from the underlying storage.
 		// Limits are only sent to storage when resourceVersion is non-zero
 		// since the watch cache isn't able to perform continuations, and
 		// limits are ignored when resource version is zero
 		return c.storage.GetToList(ctx, key, resourceVersion, pred, listObj)
 	}
+	if resourceVersion == "" {
+		// If resourceVersion is not specified, the result has to be at least
+		// as fresh as a quorum read from the underlying storage.
+		return c.consistentList(ctx, func(listRV uint64) error {
+			return c.getToListFromCache(key, listRV, pred, listObj)
+		}, func() error {
+			return c.storage.GetToList(ctx, key, resourceVersion, pred, listObj)
+		})
+	}
 
 	// If resourceVersion is specified, serve it from cache.
 	// It's guaranteed that the returned value is at least that
@@ -571,7 +713,12 @@ func (c *Cacher) GetToList(ctx context.Context, key string, resourceVersion stri
 		// minimal resource version, simply forward the request to storage.
 		return c.storage.GetToList(ctx, key, resourceVersion, pred, listObj)
 	}
+	return c.getToListFromCache(key, listRV, pred, listObj)
+}
 
+// getToListFromCache serves GetToList from the watch cache once it is at
+// least as fresh as listRV.
+func (c *Cacher) getToListFromCache(key string, listRV uint64, pred storage.SelectionPredicate, listObj runtime.Object) error {
 	trace := utiltrace.New(fmt.Sprintf("cacher %v: List", c.objectType.String()))
 	defer trace.LogIfLong(500 * time.Millisecond)
 
@@ -600,7 +747,7 @@ func (c *Cacher) GetToList(ctx context.Context, key string, resourceVersion stri
 		if !ok {
 			return fmt.Errorf("non *storeElement returned from storage: %v", obj)
 		}
-		if filter(elem.Key, elem.Labels, elem.Fields) {
+		if filter(elem.Key, elem.Labels, elem.Fields, elem.Annotations) {
 			listVal.Set(reflect.Append(listVal, reflect.ValueOf(elem.Object).Elem()))
 		}
 	}
@@ -617,15 +764,25 @@ func (c *Cacher) List(ctx context.Context, key string, resourceVersion string, p
 	pagingEnabled := utilfeature.DefaultFeatureGate.Enabled(features.APIListChunking)
 	hasContinuation := pagingEnabled && len(pred.Continue) > 0
 	hasLimit := pagingEnabled && pred.Limit > 0 && resourceVersion != "0"
-	if resourceVersion == "" || hasContinuation || hasLimit {
-		// If resourceVersion is not specified, serve it from underlying
-		// storage (for backward compatibility). If a continuation is
-		// requested, serve it from the underlying storage as well.
+	if hasContinuation || hasLimit {
+		if utilfeature.DefaultFeatureGate.Enabled(features.PaginatedListFromCache) && c.ready.check() {
+			return c.paginatedList(ctx, key, resourceVersion, pred, listObj)
+		}
+		// If a continuation is requested, serve it from the underlying storage.
 		// Limits are only sent to storage when resourceVersion is non-zero
 		// since the watch cache isn't able to perform continuations, and
 		// limits are ignored when resource version is zero.
 		return c.storage.List(ctx, key, resourceVersion, pred, listObj)
 	}
+	if resourceVersion == "" {
+		// If resourceVersion is not specified, the result has to be at least
+		// as fresh as a quorum read from the underlying storage.
+		return c.consistentList(ctx, func(listRV uint64) error {
+			return c.listFromCache(key, listRV, pred, c.indexedMatchValues(ctx, pred), listObj)
+		}, func() error {
+			return c.storage.List(ctx, key, resourceVersion, pred, listObj)
+		})
+	}
 
 	// If resourceVersion is specified, serve it from cache.
 	// It's guaranteed that the returned value is at least that
@@ -640,7 +797,13 @@ func (c *Cacher) List(ctx context.Context, key string, resourceVersion string, p
 		// minimal resource version, simply forward the request to storage.
 		return c.storage.List(ctx, key, resourceVersion, pred, listObj)
 	}
+	return c.listFromCache(key, listRV, pred, c.indexedMatchValues(ctx, pred), listObj)
+}
 
+// listFromCache serves List from the watch cache once it is at least as
+// fresh as listRV. Only the objects with the first of matchValues that the
+// watch cache has an index of are considered.
+func (c *Cacher) listFromCache(key string, listRV uint64, pred storage.SelectionPredicate, matchValues []storage.MatchValue, listObj runtime.Object) error {
 	trace := utiltrace.New(fmt.Sprintf("cacher %v: List", c.objectType.String()))
 	defer trace.LogIfLong(500 * time.Millisecond)
 
@@ -658,12 +821,16 @@ func (c *Cacher) List(ctx context.Context, key string, resourceVersion string, p
 	}
 	filter := filterWithAttrsFunction(key, pred)
 
-	objs, readResourceVersion, err := c.watchCache.WaitUntilFreshAndList(listRV, trace)
+	objs, readResourceVersion, indexUsed, err := c.watchCache.WaitUntilFreshAndList(listRV, matchValues, trace)
 	if err != nil {
 		return err
 	}
+	if len(indexUsed) == 0 {
+		indexUsed = "none"
+	}
+	indexRequestsCounter.WithLabelValues(c.objectType.String(), "list", indexUsed).Inc()
 	trace.Step(fmt.Sprintf("Listed %d items from cache", len(objs)))
-	if len(objs) > listVal.Cap() && pred.Label.Empty() && pred.Field.Empty() {
+	if len(objs) > listVal.Cap() && pred.Empty() {
 		// Resize the slice appropriately, since we already know that none
 		// of the elements will be filtered out.
 		listVal.Set(reflect.MakeSlice(reflect.SliceOf(c.objectType.Elem()), 0, len(objs)))
@@ -674,7 +841,7 @@ func (c *Cacher) List(ctx context.Context, key string, resourceVersion string, p
 		if !ok {
 			return fmt.Errorf("non *storeElement returned from storage: %v", obj)
 		}
-		if filter(elem.Key, elem.Labels, elem.Fields) {
+		if filter(elem.Key, elem.Labels, elem.Fields, elem.Annotations) {
 			listVal.Set(reflect.Append(listVal, reflect.ValueOf(elem.Object).Elem()))
 		}
 	}
@@ -687,6 +854,207 @@ func (c *Cacher) List(ctx context.Context, key string, resourceVersion string, p
 	return nil
 }
 
+// paginatedList serves a list with a limit or a continuation from a snapshot
+// of the watch cache. Like for the etcd3 storage, all pages are served from
+// the resourceVersion of the first one, and the continue tokens are the ones
+// the etcd3 storage would return, so that the pages can be served by either
+// of them. If the watch cache has no snapshot at the requested
+// resourceVersion, the list is served from the underlying storage.
+func (c *Cacher) paginatedList(ctx context.Context, key string, resourceVersion string, pred storage.SelectionPredicate, listObj runtime.Object) error {
+	// We need to make sure the key ended with "/", like the etcd3 storage,
+	// so that we only get children "directories".
+	keyPrefix := key
+	if !strings.HasSuffix(keyPrefix, "/") {
+		keyPrefix += "/"
+	}
+	fromStorage := func() error {
+		paginatedListCounter.WithLabelValues(c.objectType.String(), "storage").Inc()
+		return c.storage.List(ctx, key, resourceVersion, pred, listObj)
+	}
+
+	if len(pred.Continue) > 0 {
+		fromKey, continueRV, err := storage.DecodeContinue(pred.Continue, keyPrefix)
+		if err != nil {
+			return errors.NewBadRequest(fmt.Sprintf("invalid continue token: %v", err))
+		}
+		if len(resourceVersion) > 0 && resourceVersion != "0" {
+			return errors.NewBadRequest("specifying resource version is not allowed when using continue")
+		}
+		if continueRV < 0 {
+			// The continuation is for the latest resource version.
+			return fromStorage()
+		}
+		snapshot, ok := c.watchCache.Snapshot(uint64(continueRV))
+		if !ok {
+			return fromStorage()
+		}
+		return c.listFromSnapshot(snapshot, key, keyPrefix, fromKey, pred, listObj)
+	}
+
+	if resourceVersion == "" {
+		// The first page has to be at least as fresh as a quorum read from
+		// the underlying storage.
+		return c.consistentList(ctx, func(listRV uint64) error {
+			snapshot, err := c.watchCache.WaitUntilFreshAndSnapshot(listRV, nil)
+			if err != nil {
+				return err
+			}
+			return c.listFromSnapshot(snapshot, key, keyPrefix, keyPrefix, pred, listObj)
+		}, func() error {
+			return c.storage.List(ctx, key, resourceVersion, pred, listObj)
+		})
+	}
+
+	// The first page of a list with a resourceVersion is served from exactly
+	// that resourceVersion.
+	listRV, err := c.versioner.ParseResourceVersion(resourceVersion)
+	if err != nil {
+		return errors.NewBadRequest(fmt.Sprintf("invalid resource version: %v", err))
+	}
+	snapshot, ok := c.watchCache.Snapshot(listRV)
+	if !ok {
+		return fromStorage()
+	}
+	return c.listFromSnapshot(snapshot, key, keyPrefix, keyPrefix, pred, listObj)
+}
+
+// listFromSnapshot serves a page of at most pred.Limit objects with keys
+// starting at fromKey from snapshot.
+func (c *Cacher) listFromSnapshot(snapshot *listSnapshot, key, keyPrefix, fromKey string, pred storage.SelectionPredicate, listObj runtime.Object) error {
+	trace := utiltrace.New(fmt.Sprintf("cacher %v: paginated List", c.objectType.String()))
+	defer trace.LogIfLong(500 * time.Millisecond)
+
+	listPtr, err := meta.GetItemsPtr(listObj)
+	if err != nil {
+		return err
+	}
+	listVal, err := conversion.EnforcePtr(listPtr)
+	if err != nil || listVal.Kind() != reflect.Slice {
+		return fmt.Errorf("need a pointer to slice, got %v", listVal.Kind())
+	}
+	filter := filterWithAttrsFunction(key, pred)
+
+	start, end := snapshot.keyRange(keyPrefix, fromKey)
+	var lastKey string
+	hasMore := false
+	i := start
+	for ; i < end; i++ {
+		if pred.Limit > 0 && int64(listVal.Len()) >= pred.Limit {
+			hasMore = true
+			break
+		}
+		elem := snapshot.elems[i]
+		lastKey = elem.Key
+		if filter(elem.Key, elem.Labels, elem.Fields, elem.Annotations) {
+			listVal.Set(reflect.Append(listVal, reflect.ValueOf(elem.Object).Elem()))
+		}
+	}
+	trace.Step(fmt.Sprintf("Filtered %d items", listVal.Len()))
+	paginatedListCounter.WithLabelValues(c.objectType.String(), "cache").Inc()
+
+	if !hasMore {
+		return c.versioner.UpdateList(listObj, snapshot.resourceVersion, "", nil)
+	}
+	// Begin the next page immediately after the last key we examined.
+	next, err := storage.EncodeContinue(lastKey+"\x00", keyPrefix, int64(snapshot.resourceVersion))
+	if err != nil {
+		return err
+	}
+	var remainingItemCount *int64
+	// Like the etcd3 storage, only count the remaining items if the
+	// predicate is empty, since we don't know how many of them match it.
+	if pred.Empty() {
+		count := int64(end - i)
+		remainingItemCount = &count
+	}
+	return c.versioner.UpdateList(listObj, snapshot.resourceVersion, next, remainingItemCount)
+}
+
+// consistentList serves a list without a resourceVersion. If the
+// ConsistentListFromCache feature is enabled, the current revision of the
+// underlying storage is read, which is cheap, and fromCache is called to
+// serve the list from the watch cache once the cache has caught up with that
+// revision. If the underlying storage is a storage.ChangeDetector and none of
+// the cached objects changed since the revision of the cache, the cache is
+// fresh already and the list is served without waiting. If the cache does not
+// catch up in time, or if the feature is disabled, the list is served from
+// the underlying storage by fromStorage.
+func (c *Cacher) consistentList(ctx context.Context, fromCache func(listRV uint64) error, fromStorage func() error) error {
+	if !utilfeature.DefaultFeatureGate.Enabled(features.ConsistentListFromCache) {
+		return fromStorage()
+	}
+	if !c.ready.check() {
+		consistentListCounter.WithLabelValues(c.objectType.String(), "storage").Inc()
+		return fromStorage()
+	}
+
+	listRV, err := c.getCurrentResourceVersion(ctx)
+	if err != nil {
+		return err
+	}
+	err = fromCache(listRV)
+	if errors.IsTimeout(err) {
+		klog.V(4).Infof("cacher %v: watch cache did not reach resource version %d in time, listing from storage", c.objectType.String(), listRV)
+		consistentListCounter.WithLabelValues(c.objectType.String(), "fallback").Inc()
+		return fromStorage()
+	}
+	if err == nil {
+		consistentListCounter.WithLabelValues(c.objectType.String(), "cache").Inc()
+	}
+	return err
+}
+
+// getCurrentResourceVersion returns the current revision of the underlying
+// storage. If the storage can tell that no object of the resource changed
+// since the revision of the watch cache, the watch cache is advanced to the
+// current revision, so that an idle resource, for which the watch cache
+// receives neither events nor bookmarks, does not have to wait for them.
+func (c *Cacher) getCurrentResourceVersion(ctx context.Context) (uint64, error) {
+	detector, ok := c.storage.(storage.ChangeDetector)
+	if !ok {
+		return c.getCurrentResourceVersionFromStorage(ctx)
+	}
+	cacheRV := c.watchCache.getResourceVersion()
+	currentRV, changed, err := detector.ChangedSince(ctx, c.resourcePrefix, cacheRV)
+	if err != nil {
+		return 0, err
+	}
+	if currentRV == 0 {
+		return 0, fmt.Errorf("the current resource version of %v must be greater than 0", c.objectType.String())
+	}
+	if !changed {
+		c.watchCache.advanceResourceVersion(currentRV)
+	}
+	return currentRV, nil
+}
+
+// getCurrentResourceVersionFromStorage returns the current revision of the
+// underlying storage. It lists at most a single object, so that the cost of
+// the call does not depend on the number of objects.
+func (c *Cacher) getCurrentResourceVersionFromStorage(ctx context.Context) (uint64, error) {
+	emptyList := c.newListFunc()
+	pred := storage.SelectionPredicate{
+		Label: labels.Everything(),
+		Field: fields.Everything(),
+		Limit: 1,
+	}
+	if err := c.storage.List(ctx, c.resourcePrefix, "", pred, emptyList); err != nil {
+		return 0, err
+	}
+	listAccessor, err := meta.ListAccessor(emptyList)
+	if err != nil {
+		return 0, err
+	}
+	currentRV, err := c.versioner.ParseResourceVersion(listAccessor.GetResourceVersion())
+	if err != nil {
+		return 0, err
+	}
+	if currentRV == 0 {
+		return 0, fmt.Errorf("the current resource version of %v must be greater than 0", c.objectType.String())
+	}
+	return currentRV, nil
+}
+
 // GuaranteedUpdate implements storage.Interface.
 func (c *Cacher) GuaranteedUpdate(
 	ctx context.Context, key string, ptrToType runtime.Object, ignoreNotFound bool,
@@ -708,30 +1076,98 @@ func (c *Cacher) Count(pathPrefix string) (int64, error) {
 	return c.storage.Count(pathPrefix)
 }
 
-func (c *Cacher) triggerValues(event *watchCacheEvent) ([]string, bool) {
-	// TODO: Currently we assume that in a given Cacher object, its <c.triggerFunc>
-	// is aware of exactly the same trigger (at most one). Thus calling:
-	//   c.triggerFunc(<some object>)
-	// can return only 0 or 1 values.
-	// That means, that triggerValues itself may return up to 2 different values.
-	if c.triggerFunc == nil {
-		return nil, false
+// NamespaceUsage implements usage.Reporter.
+func (c *Cacher) NamespaceUsage() (map[string]usage.Usage, bool) {
+	if reporter, ok := c.storage.(usage.Reporter); ok {
+		return reporter.NamespaceUsage()
 	}
-	result := make([]string, 0, 2)
-	matchValues := c.triggerFunc(event.Object)
-	if len(matchValues) > 0 {
-		result = append(result, matchValues[0].Value)
+	return nil, false
+}
+
+// StoredSize implements usage.Sizer.
+func (c *Cacher) StoredSize(obj runtime.Object) (int64, error) {
+	if sizer, ok := c.storage.(usage.Sizer); ok {
+		return sizer.StoredSize(obj)
 	}
-	if event.PrevObject == nil {
-		return result, len(result) > 0
+	return 0, fmt.Errorf("the storage of %v does not account the usage", c.objectType.String())
+}
+
+// Transact implements storage.Interface. The transaction is committed by the
+// underlying storage, which unwraps the storages of the other keys.
+func (c *Cacher) Transact(ctx context.Context, ops []storage.TxnOp) error {
+	return c.storage.Transact(ctx, ops)
+}
+
+// Unwrap implements storage.Unwrapper.
+func (c *Cacher) Unwrap() storage.Interface {
+	return c.storage
+}
+
+// indexedMatchValues returns the values of the indexes of the cacher that
+// the objects matching pred in the request ctx have, more specific indexes
+// first.
+func (c *Cacher) indexedMatchValues(ctx context.Context, pred storage.SelectionPredicate) []storage.MatchValue {
+	if len(c.indexers) == 0 {
+		return nil
+	}
+	var result []storage.MatchValue
+	for _, matchValue := range pred.MatcherIndex() {
+		if _, ok := c.indexers[matchValue.IndexName]; ok {
+			result = append(result, matchValue)
+		}
+	}
+	if _, ok := c.indexers[storage.NamespaceIndex]; ok {
+		if namespace, ok := request.NamespaceFrom(ctx); ok && len(namespace) > 0 {
+			result = append(result, storage.MatchValue{IndexName: storage.NamespaceIndex, Value: namespace})
+		}
+	}
+	return result
+}
+
+// triggerValues returns, for every index of the cacher, the values of the
+// index for the object and the previous object of event. If the values of an
+// index cannot be computed, they are nil, so that all watchers of that index
+// are considered.
+func (c *Cacher) triggerValues(event *watchCacheEvent) map[string][]string {
+	if len(c.indexers) == 0 {
+		return nil
 	}
-	prevMatchValues := c.triggerFunc(event.PrevObject)
-	if len(prevMatchValues) > 0 {
-		if len(result) == 0 || result[0] != prevMatchValues[0].Value {
-			result = append(result, prevMatchValues[0].Value)
+	result := make(map[string][]string, len(c.indexers))
+	for indexName, indexFunc := range c.indexers {
+		values, err := indexFunc(event.Object)
+		if err != nil {
+			result[indexName] = nil
+			continue
+		}
+		if event.PrevObject != nil {
+			prevValues, err := indexFunc(event.PrevObject)
+			if err != nil {
+				result[indexName] = nil
+				continue
+			}
+			for _, prevValue := range prevValues {
+				if !containsString(values, prevValue) {
+					values = append(values, prevValue)
+				}
+			}
 		}
+		// A non-nil empty slice means that no watchers of the index are
+		// interested in the event.
+		if values == nil {
+			values = []string{}
+		}
+		result[indexName] = values
 	}
-	return result, len(result) > 0
+	return result
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
 }
 
 func (c *Cacher) processEvent(event *watchCacheEvent) {
@@ -758,7 +1194,12 @@ func (c *Cacher) dispatchEvents() {
 			if !ok {
 				return
 			}
-			c.dispatchEvent(&event)
+			// Bookmarks of the storage only advance the resourceVersion of
+			// the bookmarks sent to watchers below. They may be frequent,
+			// so they aren't dispatched themselves.
+			if event.Type != watch.Bookmark {
+				c.dispatchEvent(&event)
+			}
 			lastProcessedResourceVersion = event.ResourceVersion
 		case <-bookmarkTimer.C():
 			bookmarkTimer.Reset(wait.Jitter(time.Second, 0.25))
@@ -820,7 +1261,10 @@ func (c *Cacher) startDispatchingBookmarkEvents() {
 // startDispatching chooses watchers potentially interested in a given event
 // a marks dispatching as true.
 func (c *Cacher) startDispatching(event *watchCacheEvent) {
-	triggerValues, supported := c.triggerValues(event)
+	var triggerValues map[string][]string
+	if event.Type != watch.Bookmark {
+		triggerValues = c.triggerValues(event)
+	}
 
 	c.Lock()
 	defer c.Unlock()
@@ -843,22 +1287,22 @@ func (c *Cacher) startDispatching(event *watchCacheEvent) {
 	for _, watcher := range c.watchers.allWatchers {
 		c.watchersBuffer = append(c.watchersBuffer, watcher)
 	}
-	if supported {
-		// Iterate over watchers interested in the given values of the trigger.
-		for _, triggerValue := range triggerValues {
-			for _, watcher := range c.watchers.valueWatchers[triggerValue] {
-				c.watchersBuffer = append(c.watchersBuffer, watcher)
+	for indexName, indexWatchers := range c.watchers.valueWatchers {
+		values, supported := triggerValues[indexName]
+		if supported && values != nil {
+			// Iterate over watchers interested in the given values of the index.
+			for _, value := range values {
+				for _, watcher := range indexWatchers[value] {
+					c.watchersBuffer = append(c.watchersBuffer, watcher)
+				}
 			}
+			continue
 		}
-	} else {
-		// supported equal to false generally means that trigger function
-		// is not defined (or not aware of any indexes). In this case,
-		// watchers filters should generally also don't generate any
-		// trigger values, but can cause problems in case of some
-		// misconfiguration. Thus we paranoidly leave this branch.
-
-		// Iterate over watchers interested in exact values for all values.
-		for _, watchers := range c.watchers.valueWatchers {
+		// Not being able to compute the values of the index generally means
+		// that the index is misconfigured, as watchers are only added for the
+		// indexes of the cacher. In this case, we paranoidly iterate over
+		// watchers interested in exact values for all values.
+		for _, watchers := range indexWatchers {
 			for _, watcher := range watchers {
 				c.watchersBuffer = append(c.watchersBuffer, watcher)
 			}
@@ -912,9 +1356,12 @@ func (c *Cacher) Stop() {
 	c.stopLock.Unlock()
 	close(c.stopCh)
 	c.stopWg.Wait()
+	if c.consistencyChecked {
+		deleteConsistencyCheckResult(c.resourcePrefix)
+	}
 }
 
-func forgetWatcher(c *Cacher, index int, triggerValue string, triggerSupported bool) func() {
+func forgetWatcher(c *Cacher, index int, trigger storage.MatchValue, triggerSupported bool) func() {
 	return func() {
 		c.Lock()
 		defer c.Unlock()
@@ -922,16 +1369,16 @@ func forgetWatcher(c *Cacher, index int, triggerValue string, triggerSupported b
 		// It's possible that the watcher is already not in the structure (e.g. in case of
 		// simultaneous Stop() and terminateAllWatchers(), but it is safe to call stop()
 		// on a watcher multiple times.
-		c.watchers.deleteWatcher(index, triggerValue, triggerSupported, c.stopWatcherThreadUnsafe)
+		c.watchers.deleteWatcher(index, trigger, triggerSupported, c.stopWatcherThreadUnsafe)
 	}
 }
 
 func filterWithAttrsFunction(key string, p storage.SelectionPredicate) filterWithAttrsFunc {
-	filterFunc := func(objKey string, label labels.Set, field fields.Set) bool {
+	filterFunc := func(objKey string, label labels.Set, field fields.Set, annotation labels.Set) bool {
 		if !hasPathPrefix(objKey, key) {
 			return false
 		}
-		return p.MatchesObjectAttributes(label, field)
+		return p.MatchesObjectAttributes(label, field, annotation)
 	}
 	return filterFunc
 }
@@ -978,7 +1425,14 @@ func (lw *cacherListerWatcher) List(options metav1.ListOptions) (runtime.Object,
 
 // Implements cache.ListerWatcher interface.
 func (lw *cacherListerWatcher) Watch(options metav1.ListOptions) (watch.Interface, error) {
-	return lw.storage.WatchList(context.TODO(), lw.resourcePrefix, options.ResourceVersion, storage.Everything)
+	pred := storage.SelectionPredicate{
+		Label: labels.Everything(),
+		Field: fields.Everything(),
+		// Bookmarks of the storage advance the resourceVersion of the
+		// cache when objects are rarely changed.
+		AllowWatchBookmarks: true,
+	}
+	return lw.storage.WatchList(context.TODO(), lw.resourcePrefix, options.ResourceVersion, pred)
 }
 
 // errWatcher implements watch.Interface to return a single error
@@ -1037,6 +1491,9 @@ type cacheWatcher struct {
 	allowWatchBookmarks bool
 	// Object type of the cache watcher interests
 	objectType reflect.Type
+	// Whether the watcher accepts runtime.CacheableObject wrappers instead
+	// of copies of the objects.
+	cacheableObjects bool
 }
 
 func newCacheWatcher(chanSize int, filter filterWithAttrsFunc, forget func(), versioner storage.Versioner, deadline time.Time, allowWatchBookmarks bool, objectType reflect.Type) *cacheWatcher {
@@ -1133,10 +1590,10 @@ func (c *cacheWatcher) convertToWatchEvent(event *watchCacheEvent) *watch.Event
 		return &watch.Event{Type: watch.Bookmark, Object: event.Object.DeepCopyObject()}
 	}
 
-	curObjPasses := event.Type != watch.Deleted && c.filter(event.Key, event.ObjLabels, event.ObjFields)
+	curObjPasses := event.Type != watch.Deleted && c.filter(event.Key, event.ObjLabels, event.ObjFields, event.ObjAnnotations)
 	oldObjPasses := false
 	if event.PrevObject != nil {
-		oldObjPasses = c.filter(event.Key, event.PrevObjLabels, event.PrevObjFields)
+		oldObjPasses = c.filter(event.Key, event.PrevObjLabels, event.PrevObjFields, event.PrevObjAnnotations)
 	}
 	if !curObjPasses && !oldObjPasses {
 		// Watcher is not interested in that object.
@@ -1145,21 +1602,36 @@ func (c *cacheWatcher) convertToWatchEvent(event *watchCacheEvent) *watch.Event
 
 	switch {
 	case curObjPasses && !oldObjPasses:
-		return &watch.Event{Type: watch.Added, Object: event.Object.DeepCopyObject()}
+		return &watch.Event{Type: watch.Added, Object: c.getObject(event)}
 	case curObjPasses && oldObjPasses:
-		return &watch.Event{Type: watch.Modified, Object: event.Object.DeepCopyObject()}
+		return &watch.Event{Type: watch.Modified, Object: c.getObject(event)}
 	case !curObjPasses && oldObjPasses:
 		// return a delete event with the previous object content, but with the event's resource version
-		oldObj := event.PrevObject.DeepCopyObject()
-		if err := c.versioner.UpdateObject(oldObj, event.ResourceVersion); err != nil {
-			utilruntime.HandleError(fmt.Errorf("failure to version api object (%d) %#v: %v", event.ResourceVersion, oldObj, err))
-		}
-		return &watch.Event{Type: watch.Deleted, Object: oldObj}
+		return &watch.Event{Type: watch.Deleted, Object: c.getPrevObject(event)}
 	}
 
 	return nil
 }
 
+// getObject returns the object of event to be sent to the watcher. Watchers
+// accepting cacheable objects share a single cachingObject per event, so that
+// its serializations are computed once for all of them.
+func (c *cacheWatcher) getObject(event *watchCacheEvent) runtime.Object {
+	if c.cacheableObjects && event.cachingObjects != nil {
+		return event.cachingObjects.getObject(event)
+	}
+	return event.Object.DeepCopyObject()
+}
+
+// getPrevObject returns the previous object of event, with the resource
+// version of event, to be sent to the watcher.
+func (c *cacheWatcher) getPrevObject(event *watchCacheEvent) runtime.Object {
+	if c.cacheableObjects && event.cachingObjects != nil {
+		return event.cachingObjects.getPrevObject(event, c.versioner)
+	}
+	return prevObjectAtEventVersion(event, c.versioner)
+}
+
 // NOTE: sendWatchCacheEvent is assumed to not modify <event> !!!
 func (c *cacheWatcher) sendWatchCacheEvent(event *watchCacheEvent) {
 	watchEvent := c.convertToWatchEvent(event)
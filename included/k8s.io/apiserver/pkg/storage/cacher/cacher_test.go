@@ -0,0 +1,503 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cacher
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	v1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/core/v1"
+	metav1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/fields"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/labels"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/util/wait"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/watch"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/endpoints/request"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/features"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/embedded"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/etcd"
+	storagetesting "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/testing"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/value"
+	utilfeature "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/util/feature"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/client-go/tools/cache"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/component-base/featuregate"
+)
+
+// enableFeature enables feature and returns a func restoring its previous
+// state.
+func enableFeature(t *testing.T, feature featuregate.Feature) func() {
+	enabled := utilfeature.DefaultFeatureGate.Enabled(feature)
+	if err := utilfeature.DefaultMutableFeatureGate.Set(string(feature) + "=true"); err != nil {
+		t.Fatal(err)
+	}
+	return func() {
+		utilfeature.DefaultMutableFeatureGate.Set(string(feature) + "=" + strconv.FormatBool(enabled))
+	}
+}
+
+// testStorage is an in-memory storage of core/v1 objects. Errors can be
+// injected in its lists, and the watches it serves can be paused to make the
+// watch cache fall behind.
+type testStorage struct {
+	storage.Interface
+
+	lock sync.Mutex
+	err  error
+	// watchLock is held while the watches are paused.
+	watchLock sync.Mutex
+}
+
+func newTestStorage() (*testStorage, func()) {
+	b := embedded.NewMemory()
+	s := embedded.New(b, storagetesting.Codec, "", value.IdentityTransformer, true)
+	return &testStorage{Interface: s}, func() { b.Close() }
+}
+
+// injectError makes List and GetToList fail with err, or succeed again if err
+// is nil.
+func (s *testStorage) injectError(err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.err = err
+}
+
+func (s *testStorage) injectedError() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.err
+}
+
+func (s *testStorage) pauseWatches() {
+	s.watchLock.Lock()
+}
+
+func (s *testStorage) resumeWatches() {
+	s.watchLock.Unlock()
+}
+
+func (s *testStorage) List(ctx context.Context, key string, resourceVersion string, p storage.SelectionPredicate, listObj runtime.Object) error {
+	if err := s.injectedError(); err != nil {
+		return err
+	}
+	return s.Interface.List(ctx, key, resourceVersion, p, listObj)
+}
+
+func (s *testStorage) GetToList(ctx context.Context, key string, resourceVersion string, p storage.SelectionPredicate, listObj runtime.Object) error {
+	if err := s.injectedError(); err != nil {
+		return err
+	}
+	return s.Interface.GetToList(ctx, key, resourceVersion, p, listObj)
+}
+
+func (s *testStorage) WatchList(ctx context.Context, key string, resourceVersion string, p storage.SelectionPredicate) (watch.Interface, error) {
+	w, err := s.Interface.WatchList(ctx, key, resourceVersion, p)
+	if err != nil {
+		return nil, err
+	}
+	return watch.Filter(w, func(event watch.Event) (watch.Event, bool) {
+		s.watchLock.Lock()
+		defer s.watchLock.Unlock()
+		return event, true
+	}), nil
+}
+
+// createTestPod creates a pod in s and returns it as stored.
+func createTestPod(t *testing.T, s storage.Interface, pod *v1.Pod) *v1.Pod {
+	t.Helper()
+	out := &v1.Pod{}
+	key := "pods/" + pod.Namespace + "/" + pod.Name
+	if err := s.Create(context.TODO(), key, pod, out, 0); err != nil {
+		t.Fatalf("Failed to create %s: %v", key, err)
+	}
+	return out
+}
+
+// newTestPodCacherConfig returns the configuration of a cacher of the pods
+// in s.
+func newTestPodCacherConfig(s storage.Interface, capacity int) Config {
+	prefix := "pods"
+	return Config{
+		CacheCapacity:  capacity,
+		Storage:        s,
+		Versioner:      s.Versioner(),
+		ResourcePrefix: prefix,
+		KeyFunc:        func(obj runtime.Object) (string, error) { return storage.NamespaceKeyFunc(prefix, obj) },
+		GetAttrsFunc:   func(obj runtime.Object) (labels.Set, fields.Set, error) { return nil, nil, nil },
+		NewFunc:        func() runtime.Object { return &v1.Pod{} },
+		NewListFunc:    func() runtime.Object { return &v1.PodList{} },
+		Codec:          storagetesting.Codec,
+	}
+}
+
+// waitForWatchCache waits until the watch cache of c reached resourceVersion.
+func waitForWatchCache(t *testing.T, c *Cacher, resourceVersion string) {
+	t.Helper()
+	rv, err := strconv.ParseUint(resourceVersion, 10, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.watchCache.waitUntilFreshAndBlock(rv, nil)
+	c.watchCache.RUnlock()
+	if err != nil {
+		t.Fatalf("The watch cache did not reach %s: %v", resourceVersion, err)
+	}
+}
+
+func TestPaginatedListFromCache(t *testing.T) {
+	defer enableFeature(t, features.PaginatedListFromCache)()
+	s, cleanup := newTestStorage()
+	defer cleanup()
+	cacher := NewCacherFromConfig(newTestPodCacherConfig(s, 10))
+	defer cacher.Stop()
+	cacher.ready.wait()
+
+	var pods []*v1.Pod
+	for i := 0; i < 5; i++ {
+		pods = append(pods, createTestPod(t, s, &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: fmt.Sprintf("pod-%d", i)}}))
+	}
+	listRV := pods[4].ResourceVersion
+	waitForWatchCache(t, cacher, listRV)
+
+	// Inject an error in the storage to check that pages are served from cache.
+	errList := fmt.Errorf("list failed")
+	s.injectError(errList)
+
+	pred := storage.SelectionPredicate{Label: labels.Everything(), Field: fields.Everything(), Limit: 2}
+	result := &v1.PodList{}
+	if err := cacher.List(context.TODO(), "pods/ns", listRV, pred, result); err != nil {
+		t.Fatalf("List with Limit should be served from cache: %v", err)
+	}
+	if len(result.Items) != 2 || result.Items[0].Name != "pod-0" || result.Items[1].Name != "pod-1" {
+		t.Errorf("unexpected first page: %#v", result.Items)
+	}
+	if result.RemainingItemCount == nil || *result.RemainingItemCount != 3 {
+		t.Errorf("expected 3 remaining items, got %v", result.RemainingItemCount)
+	}
+	// The continue token is the one the etcd3 storage would return.
+	fromKey, rv, err := storage.DecodeContinue(result.Continue, "/registry/pods/ns/")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fromKey != "/registry/pods/ns/pod-1\x00" || strconv.FormatInt(rv, 10) != listRV {
+		t.Errorf("unexpected continue token: %q at %d", fromKey, rv)
+	}
+
+	// Changes after the first page are not visible in the following ones.
+	s.injectError(nil)
+	if err := s.Delete(context.TODO(), "pods/ns/pod-2", &v1.Pod{}, nil, storage.ValidateAllObjectFunc); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	current := &v1.PodList{}
+	if err := s.List(context.TODO(), "pods", "", storage.Everything, current); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	waitForWatchCache(t, cacher, current.ResourceVersion)
+	s.injectError(errList)
+	var names []string
+	for len(result.Continue) > 0 {
+		pred.Continue = result.Continue
+		result = &v1.PodList{}
+		if err := cacher.List(context.TODO(), "pods/ns", "", pred, result); err != nil {
+			t.Fatalf("List with continue should be served from cache: %v", err)
+		}
+		if result.ResourceVersion != listRV {
+			t.Errorf("expected resource version %s, got %s", listRV, result.ResourceVersion)
+		}
+		for _, pod := range result.Items {
+			names = append(names, pod.Name)
+		}
+	}
+	if !reflect.DeepEqual(names, []string{"pod-2", "pod-3", "pod-4"}) {
+		t.Errorf("unexpected following pages: %v", names)
+	}
+
+	// Resource versions the watch cache has no snapshot at are served from
+	// the underlying storage.
+	pred.Continue = ""
+	if err := cacher.List(context.TODO(), "pods/ns", pods[1].ResourceVersion, pred, result); err != errList {
+		t.Errorf("List with Limit at an old resource version should be served from storage: %v", err)
+	}
+}
+
+func TestCacherIndexes(t *testing.T) {
+	s, cleanup := newTestStorage()
+	defer cleanup()
+	nodeNameIndex := storage.FieldIndex("spec.nodeName")
+	nodeName := func(obj interface{}) string { return obj.(*v1.Pod).Spec.NodeName }
+	config := newTestPodCacherConfig(s, 10)
+	config.GetAttrsFunc = func(obj runtime.Object) (labels.Set, fields.Set, error) {
+		return nil, fields.Set{"spec.nodeName": nodeName(obj)}, nil
+	}
+	config.Indexers = &cache.Indexers{
+		nodeNameIndex: func(obj interface{}) ([]string, error) {
+			return []string{nodeName(obj)}, nil
+		},
+		storage.NamespaceIndex: storage.NamespaceIndexFunc,
+	}
+	makePod := func(namespace, name, node string) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Spec:       v1.PodSpec{NodeName: node},
+		}
+	}
+	startRV := createTestPod(t, s, makePod("ns3", "pod0", "node3")).ResourceVersion
+	cacher := NewCacherFromConfig(config)
+	defer cacher.Stop()
+	cacher.ready.wait()
+	nodePred := func(node string) storage.SelectionPredicate {
+		return storage.SelectionPredicate{
+			Label:       labels.Everything(),
+			Field:       fields.OneTermEqualSelector("spec.nodeName", node),
+			IndexFields: []string{"spec.nodeName"},
+		}
+	}
+
+	nodeWatcher, err := cacher.Watch(context.TODO(), "pods", startRV, nodePred("node1"))
+	if err != nil {
+		t.Fatalf("Failed to create watch: %v", err)
+	}
+	defer nodeWatcher.Stop()
+	nsWatcher, err := cacher.Watch(request.WithNamespace(context.TODO(), "ns2"), "pods/ns2", startRV, storage.Everything)
+	if err != nil {
+		t.Fatalf("Failed to create watch: %v", err)
+	}
+	defer nsWatcher.Stop()
+
+	func() {
+		cacher.Lock()
+		defer cacher.Unlock()
+		if len(cacher.watchers.allWatchers) != 0 {
+			t.Errorf("expected all watchers to be indexed, got %d unindexed", len(cacher.watchers.allWatchers))
+		}
+		if len(cacher.watchers.valueWatchers[nodeNameIndex]["node1"]) != 1 {
+			t.Errorf("expected a watcher of node1")
+		}
+		if len(cacher.watchers.valueWatchers[storage.NamespaceIndex]["ns2"]) != 1 {
+			t.Errorf("expected a watcher of ns2")
+		}
+	}()
+
+	for _, pod := range []*v1.Pod{
+		makePod("ns1", "pod1", "node1"),
+		makePod("ns1", "pod2", "node2"),
+		makePod("ns2", "pod3", "node2"),
+	} {
+		createTestPod(t, s, pod)
+	}
+	// Moving pod2 to node1 is sent to the watcher of node1.
+	moved := &v1.Pod{}
+	err = s.GuaranteedUpdate(context.TODO(), "pods/ns1/pod2", moved, false, nil, storage.SimpleUpdate(func(obj runtime.Object) (runtime.Object, error) {
+		pod := obj.(*v1.Pod)
+		pod.Spec.NodeName = "node1"
+		return pod, nil
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expectEvents := func(w watch.Interface, expected ...string) {
+		t.Helper()
+		for _, name := range expected {
+			select {
+			case event := <-w.ResultChan():
+				pod, ok := event.Object.(*v1.Pod)
+				if !ok {
+					t.Fatalf("expected an event of %s, got %#v", name, event)
+				}
+				if pod.Name != name {
+					t.Errorf("expected an event of %s, got %s", name, pod.Name)
+				}
+			case <-time.After(wait.ForeverTestTimeout):
+				t.Fatalf("timed out waiting for an event of %s", name)
+			}
+		}
+		select {
+		case event := <-w.ResultChan():
+			t.Errorf("unexpected event: %#v", event)
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+	expectEvents(nodeWatcher, "pod1", "pod2")
+	expectEvents(nsWatcher, "pod3")
+
+	result := &v1.PodList{}
+	if err := cacher.List(context.TODO(), "pods", moved.ResourceVersion, nodePred("node2"), result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Items) != 1 || result.Items[0].Name != "pod3" {
+		t.Errorf("unexpected list of node2: %#v", result.Items)
+	}
+	result = &v1.PodList{}
+	if err := cacher.List(request.WithNamespace(context.TODO(), "ns1"), "pods/ns1", moved.ResourceVersion, storage.Everything, result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Items) != 2 {
+		t.Errorf("unexpected list of ns1: %#v", result.Items)
+	}
+}
+
+func TestCacherWarmRestart(t *testing.T) {
+	dir, err := ioutil.TempDir("", "watch-cache-snapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	s, cleanup := newTestStorage()
+	defer cleanup()
+
+	newCacher := func() *Cacher {
+		config := newTestPodCacherConfig(s, 10)
+		config.SnapshotPath = filepath.Join(dir, "pods.snapshot")
+		// Snapshots are saved explicitly by the test.
+		config.SnapshotInterval = time.Hour
+		cacher := NewCacherFromConfig(config)
+		cacher.ready.wait()
+		return cacher
+	}
+
+	cacher := newCacher()
+	pod := createTestPod(t, s, &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "foo"}})
+	waitForWatchCache(t, cacher, pod.ResourceVersion)
+	cacher.saveSnapshot()
+	cacher.Stop()
+
+	// Other resources change while the cacher is stopped, so the restarted
+	// cacher is restored at the saved resourceVersion, not the current one of
+	// the storage.
+	if err := s.Create(context.TODO(), "services/ns/foo", &v1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "foo"}}, &v1.Service{}, 0); err != nil {
+		t.Fatal(err)
+	}
+	cacher = newCacher()
+	defer cacher.Stop()
+	objs, resourceVersion := cacher.watchCache.listWithResourceVersion()
+	if strconv.FormatUint(resourceVersion, 10) != pod.ResourceVersion {
+		t.Errorf("expected the cache to be restored at %s, got %d", pod.ResourceVersion, resourceVersion)
+	}
+	if len(objs) != 1 || objs[0].(*storeElement).Key != "pods/ns/foo" {
+		t.Errorf("unexpected restored objects: %#v", objs)
+	}
+}
+
+func TestCacherConsistencyCheck(t *testing.T) {
+	s, cleanup := newTestStorage()
+	defer cleanup()
+	foo := createTestPod(t, s, &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "foo"}})
+	cacher := NewCacherFromConfig(newTestPodCacherConfig(s, 10))
+	defer cacher.Stop()
+	cacher.ready.wait()
+
+	lastResult := func() ConsistencyCheckResult {
+		for _, result := range ConsistencyCheckResults() {
+			if result.ResourcePrefix == "pods" {
+				return result
+			}
+		}
+		t.Fatalf("no consistency check result of pods")
+		return ConsistencyCheckResult{}
+	}
+
+	cacher.checkConsistency()
+	if result := lastResult(); result.Diverged || result.Error != "" || strconv.FormatUint(result.ResourceVersion, 10) != foo.ResourceVersion {
+		t.Errorf("expected a consistent cache at %s, got %#v", foo.ResourceVersion, result)
+	}
+
+	// The watch cache misses bar and has baz, which the storage doesn't have.
+	s.pauseWatches()
+	defer s.resumeWatches()
+	bar := createTestPod(t, s, &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "bar"}})
+	baz := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "baz", ResourceVersion: bar.ResourceVersion}}
+	if err := cacher.watchCache.Add(baz); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cacher.checkConsistency()
+	result := lastResult()
+	if !result.Diverged || result.DivergedObjects != 2 || strconv.FormatUint(result.ResourceVersion, 10) != bar.ResourceVersion {
+		t.Errorf("expected a cache diverged by 2 objects at %s, got %#v", bar.ResourceVersion, result)
+	}
+	if result.CacheDigest == result.StorageDigest {
+		t.Errorf("expected different digests, got %s", result.CacheDigest)
+	}
+
+	s.injectError(fmt.Errorf("storage unavailable"))
+	cacher.checkConsistency()
+	if result := lastResult(); result.Error != "storage unavailable" {
+		t.Errorf("expected the storage error, got %#v", result)
+	}
+}
+
+func TestCacheWatcherFiltersAnnotations(t *testing.T) {
+	pred := storage.SelectionPredicate{
+		Label:      labels.Everything(),
+		Field:      fields.Everything(),
+		Annotation: labels.SelectorFromSet(labels.Set{"owner": "team-a"}),
+	}
+	filter := filterWithAttrsFunction("pods/ns", pred)
+	makePod := func(owner string) *v1.Pod {
+		return &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "foo", ResourceVersion: "1", Annotations: map[string]string{"owner": owner}}}
+	}
+	testCases := []struct {
+		name                string
+		owner, prevOwner    string
+		expectedType        watch.EventType
+		expectedFilteredOut bool
+		eventType           watch.EventType
+	}{
+		{name: "added matching", eventType: watch.Added, owner: "team-a", expectedType: watch.Added},
+		{name: "added not matching", eventType: watch.Added, owner: "team-b", expectedFilteredOut: true},
+		{name: "modified to match", eventType: watch.Modified, owner: "team-a", prevOwner: "team-b", expectedType: watch.Added},
+		{name: "modified to not match", eventType: watch.Modified, owner: "team-b", prevOwner: "team-a", expectedType: watch.Deleted},
+		{name: "modified matching", eventType: watch.Modified, owner: "team-a", prevOwner: "team-a", expectedType: watch.Modified},
+	}
+	for _, tc := range testCases {
+		event := &watchCacheEvent{
+			Type:            tc.eventType,
+			Object:          makePod(tc.owner),
+			ObjAnnotations:  labels.Set{"owner": tc.owner},
+			Key:             "pods/ns/foo",
+			ResourceVersion: 1,
+		}
+		if tc.prevOwner != "" {
+			event.PrevObject = makePod(tc.prevOwner)
+			event.PrevObjAnnotations = labels.Set{"owner": tc.prevOwner}
+		}
+		w := newCacheWatcher(0, filter, emptyFunc, etcd.APIObjectVersioner{}, time.Now(), false, reflect.TypeOf(&v1.Pod{}))
+		watchEvent := w.convertToWatchEvent(event)
+		if tc.expectedFilteredOut {
+			if watchEvent != nil {
+				t.Errorf("%s: expected the event to be filtered out, got %v", tc.name, watchEvent)
+			}
+			continue
+		}
+		if watchEvent == nil {
+			t.Errorf("%s: expected a %s event, got none", tc.name, tc.expectedType)
+			continue
+		}
+		if watchEvent.Type != tc.expectedType {
+			t.Errorf("%s: expected a %s event, got %s", tc.name, tc.expectedType, watchEvent.Type)
+		}
+	}
+}
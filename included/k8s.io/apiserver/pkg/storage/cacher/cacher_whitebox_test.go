@@ -17,8 +17,10 @@ limitations under the License.
 package cacher
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"reflect"
 	"strconv"
 	"sync"
@@ -41,6 +43,7 @@ import (
 	examplev1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/apis/example/v1"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/features"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage"
+	storagetesting "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/testing"
 	utilfeature "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/util/feature"
 	featuregatetesting "github.com/aaron-prindle/krmapiserver/included/k8s.io/component-base/featuregate/testing"
 )
@@ -55,7 +58,7 @@ func TestCacheWatcherCleanupNotBlockedByResult(t *testing.T) {
 	var lock sync.RWMutex
 	var w *cacheWatcher
 	count := 0
-	filter := func(string, labels.Set, fields.Set) bool { return true }
+	filter := func(string, labels.Set, fields.Set, labels.Set) bool { return true }
 	forget := func() {
 		lock.Lock()
 		defer lock.Unlock()
@@ -325,6 +328,9 @@ func (d *dummyStorage) GuaranteedUpdate(_ context.Context, _ string, _ runtime.O
 func (d *dummyStorage) Count(_ string) (int64, error) {
 	return 0, fmt.Errorf("unimplemented")
 }
+func (d *dummyStorage) Transact(_ context.Context, _ []storage.TxnOp) error {
+	return fmt.Errorf("unimplemented")
+}
 
 func TestListWithLimitAndRV0(t *testing.T) {
 	backingStorage := &dummyStorage{}
@@ -460,7 +466,7 @@ func TestWatcherNotGoingBackInTime(t *testing.T) {
 func TestCacheWatcherStoppedInAnotherGoroutine(t *testing.T) {
 	var w *cacheWatcher
 	done := make(chan struct{})
-	filter := func(string, labels.Set, fields.Set) bool { return true }
+	filter := func(string, labels.Set, fields.Set, labels.Set) bool { return true }
 	forget := func() {
 		w.stop()
 		done <- struct{}{}
@@ -532,6 +538,62 @@ func TestCacheWatcherStoppedOnDestroy(t *testing.T) {
 
 }
 
+func BenchmarkWatchEventEncoding(b *testing.B) {
+	for _, watchers := range []int{1, 10, 100} {
+		for _, cacheable := range []bool{false, true} {
+			b.Run(fmt.Sprintf("watchers=%d/cacheable=%v", watchers, cacheable), func(b *testing.B) {
+				benchmarkWatchEventEncoding(b, watchers, cacheable)
+			})
+		}
+	}
+}
+
+func benchmarkWatchEventEncoding(b *testing.B, watchers int, cacheable bool) {
+	filter := func(string, labels.Set, fields.Set, labels.Set) bool { return true }
+	codec := storagetesting.Codec
+	encode := func(obj runtime.Object, w io.Writer) error {
+		return codec.Encode(obj, w)
+	}
+	ws := make([]*cacheWatcher, watchers)
+	for i := range ws {
+		ws[i] = newCacheWatcher(0, filter, emptyFunc, testVersioner{}, time.Now(), false, objectType)
+		ws[i].cacheableObjects = cacheable
+	}
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "foo",
+			Namespace:       "ns",
+			ResourceVersion: "1",
+			Labels:          map[string]string{"app": "foo", "tier": "backend"},
+		},
+		Spec: v1.PodSpec{NodeName: "node", Hostname: "foo"},
+	}
+	buf := &bytes.Buffer{}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		event := &watchCacheEvent{
+			Type:            watch.Modified,
+			Object:          pod,
+			PrevObject:      pod,
+			ResourceVersion: 1,
+			cachingObjects:  &eventObjects{},
+		}
+		for _, w := range ws {
+			watchEvent := w.convertToWatchEvent(event)
+			if co, ok := watchEvent.Object.(runtime.CacheableObject); ok {
+				if err := co.CacheEncode("id", encode, buf); err != nil {
+					b.Fatal(err)
+				}
+			} else if err := codec.Encode(watchEvent.Object, buf); err != nil {
+				b.Fatal(err)
+			}
+			buf.Reset()
+		}
+	}
+}
+
 func TestTimeBucketWatchersBasic(t *testing.T) {
 	filter := func(_ string, _ labels.Set, _ fields.Set) bool {
 		return true
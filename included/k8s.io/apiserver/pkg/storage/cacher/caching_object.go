@@ -0,0 +1,181 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cacher
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/api/meta"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime/schema"
+	utilruntime "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/util/runtime"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage"
+)
+
+var _ runtime.CacheableObject = &cachingObject{}
+
+// serializationResult holds the result of encoding an object once.
+type serializationResult struct {
+	once sync.Once
+
+	raw []byte
+	err error
+}
+
+// cachingObject wraps an object that is sent to many watchers and caches its
+// serializations, so that each of them is computed once no matter how many
+// watchers receive the object.
+//
+// The wrapped object is shared by all watchers and never modified. Consumers
+// that need to modify it have to use GetObject, which returns a copy.
+type cachingObject struct {
+	lock sync.RWMutex
+
+	object         runtime.Object
+	serializations map[runtime.Identifier]*serializationResult
+}
+
+func newCachingObject(object runtime.Object) *cachingObject {
+	return &cachingObject{
+		object:         object,
+		serializations: make(map[runtime.Identifier]*serializationResult),
+	}
+}
+
+func (o *cachingObject) getSerializationResult(id runtime.Identifier) *serializationResult {
+	o.lock.RLock()
+	result, exists := o.serializations[id]
+	o.lock.RUnlock()
+	if exists {
+		return result
+	}
+
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	if result, exists := o.serializations[id]; exists {
+		return result
+	}
+	result = &serializationResult{}
+	o.serializations[id] = result
+	return result
+}
+
+// CacheEncode implements runtime.CacheableObject.
+func (o *cachingObject) CacheEncode(id runtime.Identifier, encode func(runtime.Object, io.Writer) error, w io.Writer) error {
+	result := o.getSerializationResult(id)
+	result.once.Do(func() {
+		buffer := bytes.NewBuffer(nil)
+		result.err = encode(o.GetObject(), buffer)
+		result.raw = buffer.Bytes()
+	})
+	if result.err != nil {
+		return result.err
+	}
+	_, err := w.Write(result.raw)
+	return err
+}
+
+// GetObject implements runtime.CacheableObject.
+func (o *cachingObject) GetObject() runtime.Object {
+	o.lock.RLock()
+	defer o.lock.RUnlock()
+	return o.object.DeepCopyObject()
+}
+
+// GetResourceVersion returns the resourceVersion of the wrapped object without
+// copying it, for consumers that only need to know which version is sent.
+func (o *cachingObject) GetResourceVersion() string {
+	o.lock.RLock()
+	defer o.lock.RUnlock()
+	resourceVersion, _ := meta.NewAccessor().ResourceVersion(o.object)
+	return resourceVersion
+}
+
+// GetObjectKind implements runtime.Object.
+func (o *cachingObject) GetObjectKind() schema.ObjectKind {
+	return o
+}
+
+// DeepCopyObject implements runtime.Object. The wrapped object is never
+// modified, so the copy shares it, but not the cached serializations.
+func (o *cachingObject) DeepCopyObject() runtime.Object {
+	o.lock.RLock()
+	defer o.lock.RUnlock()
+	return newCachingObject(o.object)
+}
+
+// GroupVersionKind implements schema.ObjectKind.
+func (o *cachingObject) GroupVersionKind() schema.GroupVersionKind {
+	o.lock.RLock()
+	defer o.lock.RUnlock()
+	return o.object.GetObjectKind().GroupVersionKind()
+}
+
+// SetGroupVersionKind implements schema.ObjectKind. The wrapped object is
+// shared, so it is replaced by a modified copy and the serializations of the
+// previous object are dropped.
+func (o *cachingObject) SetGroupVersionKind(gvk schema.GroupVersionKind) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	if o.object.GetObjectKind().GroupVersionKind() == gvk {
+		return
+	}
+	o.object = o.object.DeepCopyObject()
+	o.object.GetObjectKind().SetGroupVersionKind(gvk)
+	o.serializations = make(map[runtime.Identifier]*serializationResult)
+}
+
+// eventObjects holds the objects of a watchCacheEvent that are sent to
+// watchers accepting cacheable objects. They are created lazily and shared
+// by all copies of the event.
+type eventObjects struct {
+	objectOnce sync.Once
+	object     *cachingObject
+
+	prevObjectOnce sync.Once
+	prevObject     *cachingObject
+}
+
+// getObject returns the object of event.
+func (e *eventObjects) getObject(event *watchCacheEvent) runtime.Object {
+	e.objectOnce.Do(func() {
+		e.object = newCachingObject(event.Object)
+	})
+	return e.object
+}
+
+// getPrevObject returns the previous object of event with the resource
+// version of event, as sent in deletion events.
+func (e *eventObjects) getPrevObject(event *watchCacheEvent, versioner storage.Versioner) runtime.Object {
+	e.prevObjectOnce.Do(func() {
+		e.prevObject = newCachingObject(prevObjectAtEventVersion(event, versioner))
+	})
+	return e.prevObject
+}
+
+// prevObjectAtEventVersion returns a copy of the previous object of event
+// with the resource version of event.
+func prevObjectAtEventVersion(event *watchCacheEvent, versioner storage.Versioner) runtime.Object {
+	oldObj := event.PrevObject.DeepCopyObject()
+	if err := versioner.UpdateObject(oldObj, event.ResourceVersion); err != nil {
+		utilruntime.HandleError(fmt.Errorf("failure to version api object (%d) %#v: %v", event.ResourceVersion, oldObj, err))
+	}
+	return oldObj
+}
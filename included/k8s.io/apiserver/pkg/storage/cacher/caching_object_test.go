@@ -0,0 +1,160 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cacher
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	v1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/core/v1"
+	metav1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/fields"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/labels"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime/schema"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/watch"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/etcd"
+	storagetesting "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/testing"
+)
+
+func nameEncoder(calls *int32) func(runtime.Object, io.Writer) error {
+	return func(obj runtime.Object, w io.Writer) error {
+		atomic.AddInt32(calls, 1)
+		pod := obj.(*v1.Pod)
+		_, err := fmt.Fprintf(w, "%s/%s", pod.Kind, pod.Name)
+		return err
+	}
+}
+
+func TestCachingObjectCacheEncode(t *testing.T) {
+	object := newCachingObject(&v1.Pod{TypeMeta: metav1.TypeMeta{Kind: "Pod"}, ObjectMeta: metav1.ObjectMeta{Name: "foo"}})
+
+	var calls int32
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			buf := &bytes.Buffer{}
+			if err := object.CacheEncode("a", nameEncoder(&calls), buf); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if buf.String() != "Pod/foo" {
+				t.Errorf("unexpected serialization: %q", buf.String())
+			}
+		}()
+	}
+	wg.Wait()
+	if calls != 1 {
+		t.Errorf("expected one encoding, got %d", calls)
+	}
+
+	if err := object.CacheEncode("b", nameEncoder(&calls), &bytes.Buffer{}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("expected a separate encoding per identifier, got %d", calls)
+	}
+}
+
+func TestCachingObjectIsNotModified(t *testing.T) {
+	pod := &v1.Pod{TypeMeta: metav1.TypeMeta{Kind: "Pod"}, ObjectMeta: metav1.ObjectMeta{Name: "foo"}}
+	object := newCachingObject(pod)
+
+	object.GetObject().(*v1.Pod).Name = "bar"
+	if pod.Name != "foo" {
+		t.Errorf("GetObject modified the wrapped object")
+	}
+
+	var calls int32
+	if err := object.CacheEncode("a", nameEncoder(&calls), &bytes.Buffer{}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	object.SetGroupVersionKind(schema.GroupVersionKind{Version: "v1", Kind: "Other"})
+	if pod.Kind != "Pod" {
+		t.Errorf("SetGroupVersionKind modified the wrapped object")
+	}
+	buf := &bytes.Buffer{}
+	if err := object.CacheEncode("a", nameEncoder(&calls), buf); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if buf.String() != "Other/foo" {
+		t.Errorf("expected serialization of the new kind, got %q", buf.String())
+	}
+}
+
+func TestCachingObjectResourceVersion(t *testing.T) {
+	object := newCachingObject(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo", ResourceVersion: "10"}})
+	if rv := object.GetResourceVersion(); rv != "10" {
+		t.Errorf("expected resourceVersion 10, got %q", rv)
+	}
+}
+
+func TestCacheWatcherSharesSerializations(t *testing.T) {
+	filter := func(string, labels.Set, fields.Set, labels.Set) bool { return true }
+	podType := reflect.TypeOf(&v1.Pod{})
+	event := &watchCacheEvent{
+		Type:            watch.Added,
+		Object:          &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo", ResourceVersion: "1"}},
+		ResourceVersion: 1,
+		cachingObjects:  &eventObjects{},
+	}
+
+	encodes := 0
+	encode := func(obj runtime.Object, w io.Writer) error {
+		encodes++
+		return storagetesting.Codec.Encode(obj, w)
+	}
+	var expected []byte
+	for i := 0; i < 3; i++ {
+		w := newCacheWatcher(0, filter, emptyFunc, etcd.APIObjectVersioner{}, time.Now(), false, podType)
+		w.cacheableObjects = true
+		watchEvent := w.convertToWatchEvent(event)
+		co, ok := watchEvent.Object.(runtime.CacheableObject)
+		if !ok {
+			t.Fatalf("expected a cacheable object, got %T", watchEvent.Object)
+		}
+		buf := &bytes.Buffer{}
+		if err := co.CacheEncode("id", encode, buf); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if expected == nil {
+			expected = buf.Bytes()
+		} else if !bytes.Equal(buf.Bytes(), expected) {
+			t.Errorf("expected %q, got %q", expected, buf.Bytes())
+		}
+	}
+	if encodes != 1 {
+		t.Errorf("expected the object to be encoded once, got %d", encodes)
+	}
+
+	// Watchers that don't accept cacheable objects get their own copy.
+	w := newCacheWatcher(0, filter, emptyFunc, etcd.APIObjectVersioner{}, time.Now(), false, podType)
+	watchEvent := w.convertToWatchEvent(event)
+	if watchEvent.Object == event.Object {
+		t.Errorf("expected a copy of the object")
+	}
+	if _, ok := watchEvent.Object.(*v1.Pod); !ok {
+		t.Errorf("expected a pod, got %T", watchEvent.Object)
+	}
+}
@@ -0,0 +1,249 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cacher
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/klog"
+
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/api/meta"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/fields"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/labels"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage"
+
+	"github.com/aaron-prindle/krmapiserver/included/github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	consistencyCheckCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "apiserver_watch_cache_consistency_checks_total",
+			Help: "Counter of checks of the watch cache against storage broken by resource type and result: " +
+				"'consistent', 'diverged', or 'error' if storage could not be listed",
+		},
+		[]string{"resource", "result"},
+	)
+	consistencyCheckDivergedGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "apiserver_watch_cache_consistency_check_diverged",
+			Help: "1 if the last check of the watch cache found it diverged from storage, 0 otherwise, broken by resource type",
+		},
+		[]string{"resource"},
+	)
+	consistencyCheckDivergedObjectsGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "apiserver_watch_cache_consistency_check_diverged_objects",
+			Help: "Number of objects that are missing, extra or stale in the watch cache at the last check against storage, " +
+				"broken by resource type",
+		},
+		[]string{"resource"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(consistencyCheckCounter)
+	prometheus.MustRegister(consistencyCheckDivergedGauge)
+	prometheus.MustRegister(consistencyCheckDivergedObjectsGauge)
+}
+
+// ConsistencyCheckResult is the result of the last check of a watch cache
+// against its underlying storage.
+type ConsistencyCheckResult struct {
+	// Resource is the type of the cached objects.
+	Resource string `json:"resource"`
+	// ResourcePrefix is the directory the objects are stored under.
+	ResourcePrefix string `json:"resourcePrefix"`
+	// ResourceVersion is the resourceVersion the cache and the storage
+	// were compared at.
+	ResourceVersion uint64    `json:"resourceVersion"`
+	Time            time.Time `json:"time"`
+
+	// Diverged is true if the contents of the cache and of the storage
+	// differ, in which case DivergedObjects is the number of objects that
+	// are missing, extra or at a different resourceVersion in the cache.
+	Diverged        bool   `json:"diverged"`
+	DivergedObjects int    `json:"divergedObjects,omitempty"`
+	CacheDigest     string `json:"cacheDigest,omitempty"`
+	StorageDigest   string `json:"storageDigest,omitempty"`
+
+	// Error is set if the check could not be completed.
+	Error string `json:"error,omitempty"`
+}
+
+// consistencyCheckResults holds the result of the last check of every
+// checked Cacher, by resource prefix.
+var consistencyCheckResults = struct {
+	sync.RWMutex
+	results map[string]ConsistencyCheckResult
+}{results: map[string]ConsistencyCheckResult{}}
+
+// ConsistencyCheckResults returns the results of the last checks of the
+// watch caches against their storage, sorted by resource prefix.
+func ConsistencyCheckResults() []ConsistencyCheckResult {
+	consistencyCheckResults.RLock()
+	defer consistencyCheckResults.RUnlock()
+	results := make([]ConsistencyCheckResult, 0, len(consistencyCheckResults.results))
+	for _, result := range consistencyCheckResults.results {
+		results = append(results, result)
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].ResourcePrefix < results[j].ResourcePrefix })
+	return results
+}
+
+func setConsistencyCheckResult(result ConsistencyCheckResult) {
+	consistencyCheckResults.Lock()
+	defer consistencyCheckResults.Unlock()
+	consistencyCheckResults.results[result.ResourcePrefix] = result
+}
+
+func deleteConsistencyCheckResult(resourcePrefix string) {
+	consistencyCheckResults.Lock()
+	defer consistencyCheckResults.Unlock()
+	delete(consistencyCheckResults.results, resourcePrefix)
+}
+
+// objectVersions maps the keys of objects to their resourceVersion.
+type objectVersions map[string]uint64
+
+// digest returns a digest of the keys and resourceVersions of the objects,
+// which is equal for two caches if and only if they hold the same objects
+// at the same resourceVersions.
+func (v objectVersions) digest() string {
+	keys := make([]string, 0, len(v))
+	for key := range v {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	hash := sha256.New()
+	buf := make([]byte, 8)
+	for _, key := range keys {
+		hash.Write([]byte(key))
+		// Keys never contain a NUL byte, so it separates them from
+		// their resourceVersion unambiguously.
+		hash.Write([]byte{0})
+		binary.BigEndian.PutUint64(buf, v[key])
+		hash.Write(buf)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// diff returns the number of objects that are only in one of v and other,
+// or at different resourceVersions.
+func (v objectVersions) diff(other objectVersions) int {
+	count := 0
+	for key, version := range v {
+		if otherVersion, ok := other[key]; !ok || otherVersion != version {
+			count++
+		}
+	}
+	for key := range other {
+		if _, ok := v[key]; !ok {
+			count++
+		}
+	}
+	return count
+}
+
+// checkConsistency compares the objects of the watch cache with the ones
+// listed from storage at the resourceVersion of the watch cache, and
+// records the result.
+func (c *Cacher) checkConsistency() {
+	if !c.ready.check() {
+		return
+	}
+	elems, resourceVersion := c.watchCache.listWithResourceVersion()
+	if resourceVersion == 0 {
+		return
+	}
+	resource := c.objectType.String()
+	result := ConsistencyCheckResult{
+		Resource:        resource,
+		ResourcePrefix:  c.resourcePrefix,
+		ResourceVersion: resourceVersion,
+		Time:            c.clock.Now(),
+	}
+
+	cacheVersions, storageVersions, err := c.listVersions(elems, resourceVersion)
+	if err != nil {
+		klog.Warningf("Failed to check watch cache of %s at resourceVersion %d: %v", resource, resourceVersion, err)
+		consistencyCheckCounter.WithLabelValues(resource, "error").Inc()
+		result.Error = err.Error()
+		setConsistencyCheckResult(result)
+		return
+	}
+
+	result.CacheDigest = cacheVersions.digest()
+	result.StorageDigest = storageVersions.digest()
+	if result.CacheDigest != result.StorageDigest {
+		result.Diverged = true
+		result.DivergedObjects = cacheVersions.diff(storageVersions)
+		klog.Errorf("Watch cache of %s diverged from storage at resourceVersion %d: %d objects differ", resource, resourceVersion, result.DivergedObjects)
+		consistencyCheckCounter.WithLabelValues(resource, "diverged").Inc()
+		consistencyCheckDivergedGauge.WithLabelValues(resource).Set(1)
+	} else {
+		consistencyCheckCounter.WithLabelValues(resource, "consistent").Inc()
+		consistencyCheckDivergedGauge.WithLabelValues(resource).Set(0)
+	}
+	consistencyCheckDivergedObjectsGauge.WithLabelValues(resource).Set(float64(result.DivergedObjects))
+	setConsistencyCheckResult(result)
+}
+
+// listVersions returns the versions of the objects of the watch cache and of
+// the objects listed from storage at resourceVersion.
+func (c *Cacher) listVersions(elems []interface{}, resourceVersion uint64) (objectVersions, objectVersions, error) {
+	cacheVersions := make(objectVersions, len(elems))
+	for _, elem := range elems {
+		elem := elem.(*storeElement)
+		version, err := c.versioner.ObjectResourceVersion(elem.Object)
+		if err != nil {
+			return nil, nil, err
+		}
+		cacheVersions[elem.Key] = version
+	}
+
+	list := c.newListFunc()
+	pred := storage.SelectionPredicate{Label: labels.Everything(), Field: fields.Everything()}
+	if err := c.storage.List(context.TODO(), c.resourcePrefix, strconv.FormatUint(resourceVersion, 10), pred, list); err != nil {
+		return nil, nil, err
+	}
+	storageVersions := objectVersions{}
+	err := meta.EachListItem(list, func(obj runtime.Object) error {
+		key, err := c.watchCache.keyFunc(obj)
+		if err != nil {
+			return err
+		}
+		version, err := c.versioner.ObjectResourceVersion(obj)
+		if err != nil {
+			return err
+		}
+		storageVersions[key] = version
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return cacheVersions, storageVersions, nil
+}
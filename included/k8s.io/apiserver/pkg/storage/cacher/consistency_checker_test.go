@@ -0,0 +1,61 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cacher
+
+import (
+	"testing"
+)
+
+func TestObjectVersionsDigestAndDiff(t *testing.T) {
+	base := objectVersions{"pods/ns/a": 1, "pods/ns/b": 2}
+	testCases := []struct {
+		name     string
+		other    objectVersions
+		expected int
+	}{
+		{
+			name:     "equal",
+			other:    objectVersions{"pods/ns/b": 2, "pods/ns/a": 1},
+			expected: 0,
+		},
+		{
+			name:     "stale object",
+			other:    objectVersions{"pods/ns/a": 1, "pods/ns/b": 3},
+			expected: 1,
+		},
+		{
+			name:     "missing and extra objects",
+			other:    objectVersions{"pods/ns/a": 1, "pods/ns/c": 2},
+			expected: 2,
+		},
+		{
+			name:     "empty",
+			other:    objectVersions{},
+			expected: 2,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := base.diff(tc.other); diff != tc.expected {
+				t.Errorf("expected %d diverged objects, got %d", tc.expected, diff)
+			}
+			if equal := base.digest() == tc.other.digest(); equal != (tc.expected == 0) {
+				t.Errorf("expected equal digests to be %v, got %v", tc.expected == 0, equal)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cacher
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	v1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/core/v1"
+	metav1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/util/clock"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/util/wait"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/features"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/embedded"
+	storagetesting "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/testing"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/value"
+)
+
+func TestConsistentListFromCache(t *testing.T) {
+	defer enableFeature(t, features.ConsistentListFromCache)()
+	s, cleanup := newTestStorage()
+	defer cleanup()
+	foo := createTestPod(t, s, &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "foo"}})
+
+	cacher := NewCacherFromConfig(newTestPodCacherConfig(s, 10))
+	defer cacher.Stop()
+	cacher.ready.wait()
+	fc := clock.NewFakeClock(time.Now())
+	cacher.watchCache.clock = fc
+
+	// The watch cache is as fresh as the storage.
+	result := &v1.PodList{}
+	if err := cacher.List(context.TODO(), "pods/ns", "", storage.Everything, result); err != nil {
+		t.Fatalf("List without RV failed: %v", err)
+	}
+	if result.ResourceVersion != foo.ResourceVersion || len(result.Items) != 1 {
+		t.Errorf("expected foo at resource version %s, got %#v", foo.ResourceVersion, result)
+	}
+
+	// The watch cache does not catch up with the storage, so the list falls
+	// back to the storage once waiting for it times out.
+	s.pauseWatches()
+	bar := createTestPod(t, s, &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "bar"}})
+	go func() {
+		for !fc.HasWaiters() {
+			time.Sleep(time.Millisecond)
+		}
+		fc.Step(blockTimeout)
+	}()
+	result = &v1.PodList{}
+	err := cacher.List(context.TODO(), "pods/ns", "", storage.Everything, result)
+	s.resumeWatches()
+	if err != nil {
+		t.Fatalf("List without RV failed: %v", err)
+	}
+	if result.ResourceVersion != bar.ResourceVersion || len(result.Items) != 2 {
+		t.Errorf("expected the list to be served from storage at resource version %s, got %#v", bar.ResourceVersion, result)
+	}
+
+	// Errors getting the current resource version are returned.
+	errList := fmt.Errorf("list failed")
+	s.injectError(errList)
+	if err := cacher.List(context.TODO(), "pods/ns", "", storage.Everything, result); err != errList {
+		t.Errorf("expected the storage error, got: %v", err)
+	}
+}
+
+func TestConsistentListOfIdleResource(t *testing.T) {
+	defer enableFeature(t, features.ConsistentListFromCache)()
+
+	b := embedded.NewMemory()
+	defer b.Close()
+	s := embedded.New(b, storagetesting.Codec, "", value.IdentityTransformer, true)
+	ctx := context.Background()
+	if err := s.Create(ctx, "pods/ns/foo", &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "foo"}}, &v1.Pod{}, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	cacher := NewCacherFromConfig(newTestPodCacherConfig(s, 10))
+	defer cacher.Stop()
+	cacher.ready.wait()
+	// The fake clock never moves, so waiting for the watch cache to reach the
+	// current revision would never time out and fall back to the storage.
+	cacher.watchCache.clock = clock.NewFakeClock(time.Now())
+
+	// Other resources change, the pods do not.
+	var currentRV string
+	for i := 0; i < 3; i++ {
+		out := &v1.Service{}
+		name := strconv.Itoa(i)
+		if err := s.Create(ctx, "services/ns/"+name, &v1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: name}}, out, 0); err != nil {
+			t.Fatal(err)
+		}
+		currentRV = out.ResourceVersion
+	}
+
+	result := &v1.PodList{}
+	done := make(chan error)
+	go func() {
+		done <- cacher.List(ctx, "pods/ns", "", storage.Everything, result)
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("List without RV failed: %v", err)
+		}
+	case <-time.After(wait.ForeverTestTimeout):
+		t.Fatalf("List without RV of an idle resource waited for the watch cache")
+	}
+	if result.ResourceVersion != currentRV {
+		t.Errorf("expected the list at the current resource version %s, got %s", currentRV, result.ResourceVersion)
+	}
+	if len(result.Items) != 1 || result.Items[0].Name != "foo" {
+		t.Errorf("unexpected list: %#v", result.Items)
+	}
+}
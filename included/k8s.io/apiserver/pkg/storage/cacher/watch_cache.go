@@ -47,27 +47,36 @@ const (
 // the previous value of the object to enable proper filtering in the
 // upper layers.
 type watchCacheEvent struct {
-	Type            watch.EventType
-	Object          runtime.Object
-	ObjLabels       labels.Set
-	ObjFields       fields.Set
-	PrevObject      runtime.Object
-	PrevObjLabels   labels.Set
-	PrevObjFields   fields.Set
-	Key             string
-	ResourceVersion uint64
+	Type               watch.EventType
+	Object             runtime.Object
+	ObjLabels          labels.Set
+	ObjFields          fields.Set
+	ObjAnnotations     labels.Set
+	PrevObject         runtime.Object
+	PrevObjLabels      labels.Set
+	PrevObjFields      fields.Set
+	PrevObjAnnotations labels.Set
+	Key                string
+	ResourceVersion    uint64
+
+	// cachingObjects holds the objects of the event wrapped for watchers that
+	// accept cacheable objects. It is shared by all copies of the event, so
+	// that their serializations are computed once. It is nil for events that
+	// are sent to a single watcher.
+	cachingObjects *eventObjects
 }
 
 // Computing a key of an object is generally non-trivial (it performs
 // e.g. validation underneath). Similarly computing object fields and
 // labels. To avoid computing them multiple times (to serve the event
 // in different List/Watch requests), in the underlying store we are
-// keeping structs (key, object, labels, fields).
+// keeping structs (key, object, labels, fields, annotations).
 type storeElement struct {
-	Key    string
-	Object runtime.Object
-	Labels labels.Set
-	Fields fields.Set
+	Key         string
+	Object      runtime.Object
+	Labels      labels.Set
+	Fields      fields.Set
+	Annotations labels.Set
 }
 
 func storeElementKey(obj interface{}) (string, error) {
@@ -78,6 +87,37 @@ func storeElementKey(obj interface{}) (string, error) {
 	return elem.Key, nil
 }
 
+func storeElementObject(obj interface{}) (runtime.Object, error) {
+	elem, ok := obj.(*storeElement)
+	if !ok {
+		return nil, fmt.Errorf("not a storeElement: %v", obj)
+	}
+	return elem.Object, nil
+}
+
+func storeElementIndexFunc(objIndexFunc cache.IndexFunc) cache.IndexFunc {
+	return func(obj interface{}) ([]string, error) {
+		seo, err := storeElementObject(obj)
+		if err != nil {
+			return nil, err
+		}
+		return objIndexFunc(seo)
+	}
+}
+
+// storeElementIndexers returns indexers of storeElements that apply the
+// given indexers to their objects.
+func storeElementIndexers(indexers *cache.Indexers) cache.Indexers {
+	if indexers == nil {
+		return cache.Indexers{}
+	}
+	ret := cache.Indexers{}
+	for indexName, indexFunc := range *indexers {
+		ret[indexName] = storeElementIndexFunc(indexFunc)
+	}
+	return ret
+}
+
 // watchCache implements a Store interface.
 // However, it depends on the elements implementing runtime.Object interface.
 //
@@ -112,7 +152,7 @@ type watchCache struct {
 	// history" i.e. from the moment just after the newest cached watched event.
 	// It is necessary to effectively allow clients to start watching at now.
 	// NOTE: We assume that <store> is thread-safe.
-	store cache.Store
+	store cache.Indexer
 
 	// ResourceVersion up to which the watchCache is propagated.
 	resourceVersion uint64
@@ -120,6 +160,10 @@ type watchCache struct {
 	// ResourceVersion of the last list result (populated via Replace() method).
 	listResourceVersion uint64
 
+	// snapshots of the store at recent resourceVersions, used to serve
+	// paginated lists.
+	snapshots *listSnapshots
+
 	// This handler is run at the end of every successful Replace() method.
 	onReplace func()
 
@@ -139,7 +183,8 @@ func newWatchCache(
 	keyFunc func(runtime.Object) (string, error),
 	eventHandler func(*watchCacheEvent),
 	getAttrsFunc func(runtime.Object) (labels.Set, fields.Set, error),
-	versioner storage.Versioner) *watchCache {
+	versioner storage.Versioner,
+	indexers *cache.Indexers) *watchCache {
 	wc := &watchCache{
 		capacity:            capacity,
 		keyFunc:             keyFunc,
@@ -147,9 +192,10 @@ func newWatchCache(
 		cache:               make([]*watchCacheEvent, capacity),
 		startIndex:          0,
 		endIndex:            0,
-		store:               cache.NewStore(storeElementKey),
+		store:               cache.NewIndexer(storeElementKey, storeElementIndexers(indexers)),
 		resourceVersion:     0,
 		listResourceVersion: 0,
+		snapshots:           newListSnapshots(defaultSnapshotsCapacity),
 		eventHandler:        eventHandler,
 		clock:               clock.RealClock{},
 		versioner:           versioner,
@@ -218,14 +264,20 @@ func (w *watchCache) processEvent(event watch.Event, resourceVersion uint64, upd
 	if err != nil {
 		return err
 	}
+	elem.Annotations, err = storage.AnnotationAttrs(event.Object)
+	if err != nil {
+		return err
+	}
 
 	watchCacheEvent := &watchCacheEvent{
 		Type:            event.Type,
 		Object:          elem.Object,
 		ObjLabels:       elem.Labels,
 		ObjFields:       elem.Fields,
+		ObjAnnotations:  elem.Annotations,
 		Key:             key,
 		ResourceVersion: resourceVersion,
+		cachingObjects:  &eventObjects{},
 	}
 
 	if err := func() error {
@@ -245,6 +297,7 @@ func (w *watchCache) processEvent(event watch.Event, resourceVersion uint64, upd
 			watchCacheEvent.PrevObject = previousElem.Object
 			watchCacheEvent.PrevObjLabels = previousElem.Labels
 			watchCacheEvent.PrevObjFields = previousElem.Fields
+			watchCacheEvent.PrevObjAnnotations = previousElem.Annotations
 		}
 
 		w.updateCache(watchCacheEvent)
@@ -265,6 +318,57 @@ func (w *watchCache) processEvent(event watch.Event, resourceVersion uint64, upd
 	return nil
 }
 
+// UpdateResourceVersion implements cache.ResourceVersionUpdater. It advances
+// the resourceVersion of the cache on bookmarks of the underlying storage,
+// e.g. etcd progress notifications, so that the cache doesn't fall behind
+// the storage when objects are rarely changed.
+func (w *watchCache) UpdateResourceVersion(resourceVersion string) {
+	version, err := w.versioner.ParseResourceVersion(resourceVersion)
+	if err != nil {
+		klog.Errorf("Couldn't parse resourceVersion: %v", err)
+		return
+	}
+
+	w.Lock()
+	if version <= w.resourceVersion {
+		// The resourceVersion is the one of an event that was processed.
+		w.Unlock()
+		return
+	}
+	w.resourceVersion = version
+	w.cond.Broadcast()
+	w.Unlock()
+
+	// Avoid calling event handler under lock.
+	// This is safe as long as there is at most one call to processEvent in flight
+	// at any point in time.
+	if w.eventHandler != nil {
+		w.eventHandler(&watchCacheEvent{Type: watch.Bookmark, ResourceVersion: version})
+	}
+}
+
+// getResourceVersion returns the resourceVersion the cache is fresh at.
+func (w *watchCache) getResourceVersion() uint64 {
+	w.RLock()
+	defer w.RUnlock()
+	return w.resourceVersion
+}
+
+// advanceResourceVersion marks the cache as fresh at <resourceVersion>,
+// waking up requests waiting for it. It must only be called if no object of
+// the cache was changed between the resourceVersion of the cache and
+// <resourceVersion>. Unlike UpdateResourceVersion, it does not send a bookmark
+// to the watchers, so it is safe to call from outside the reflector.
+func (w *watchCache) advanceResourceVersion(resourceVersion uint64) {
+	w.Lock()
+	defer w.Unlock()
+	if resourceVersion <= w.resourceVersion {
+		return
+	}
+	w.resourceVersion = resourceVersion
+	w.cond.Broadcast()
+}
+
 // Assumes that lock is already held for write.
 func (w *watchCache) updateCache(event *watchCacheEvent) {
 	if w.endIndex == w.startIndex+w.capacity {
@@ -315,13 +419,27 @@ func (w *watchCache) waitUntilFreshAndBlock(resourceVersion uint64, trace *utilt
 }
 
 // WaitUntilFreshAndList returns list of pointers to <storeElement> objects.
-func (w *watchCache) WaitUntilFreshAndList(resourceVersion uint64, trace *utiltrace.Trace) ([]interface{}, uint64, error) {
+// If the store has an index of one of <matchValues>, only the objects with
+// the value of the first such index are returned, together with its name.
+func (w *watchCache) WaitUntilFreshAndList(resourceVersion uint64, matchValues []storage.MatchValue, trace *utiltrace.Trace) ([]interface{}, uint64, string, error) {
 	err := w.waitUntilFreshAndBlock(resourceVersion, trace)
 	defer w.RUnlock()
 	if err != nil {
-		return nil, 0, err
+		return nil, 0, "", err
 	}
-	return w.store.List(), w.resourceVersion, nil
+	result, indexName, err := w.listByIndex(matchValues)
+	return result, w.resourceVersion, indexName, err
+}
+
+func (w *watchCache) listByIndex(matchValues []storage.MatchValue) ([]interface{}, string, error) {
+	indexers := w.store.GetIndexers()
+	for _, matchValue := range matchValues {
+		if _, ok := indexers[matchValue.IndexName]; ok {
+			result, err := w.store.ByIndex(matchValue.IndexName, matchValue.Value)
+			return result, matchValue.IndexName, err
+		}
+	}
+	return w.store.List(), "", nil
 }
 
 // WaitUntilFreshAndGet returns a pointers to <storeElement> object.
@@ -335,6 +453,58 @@ func (w *watchCache) WaitUntilFreshAndGet(resourceVersion uint64, key string, tr
 	return value, exists, w.resourceVersion, err
 }
 
+// WaitUntilFreshAndSnapshot returns a snapshot of the store that is at least
+// as fresh as given <resourceVersion>.
+func (w *watchCache) WaitUntilFreshAndSnapshot(resourceVersion uint64, trace *utiltrace.Trace) (*listSnapshot, error) {
+	err := w.waitUntilFreshAndBlock(resourceVersion, trace)
+	defer w.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+	return w.snapshotLocked(), nil
+}
+
+// Snapshot returns the snapshot of the store at exactly <resourceVersion>.
+// It exists if <resourceVersion> is the current one, or if a snapshot at
+// <resourceVersion> was taken recently.
+func (w *watchCache) Snapshot(resourceVersion uint64) (*listSnapshot, bool) {
+	w.RLock()
+	defer w.RUnlock()
+	if resourceVersion == w.resourceVersion {
+		return w.snapshotLocked(), true
+	}
+	return w.snapshots.get(resourceVersion, w.clock.Now())
+}
+
+// snapshotLocked returns the snapshot of the store at the resourceVersion of
+// the cache. It is built from the most recent snapshot and the events after
+// it if they are all still cached, and from the whole store otherwise.
+// Assumes that lock is already held for read.
+func (w *watchCache) snapshotLocked() *listSnapshot {
+	now := w.clock.Now()
+	if snapshot, ok := w.snapshots.get(w.resourceVersion, now); ok {
+		return snapshot
+	}
+	var snapshot *listSnapshot
+	if latest, ok := w.snapshots.latest(now); ok && latest.resourceVersion > 0 && latest.resourceVersion < w.resourceVersion {
+		if events, err := w.GetAllEventsSinceThreadUnsafe(latest.resourceVersion); err == nil {
+			snapshot = latest.apply(w.resourceVersion, now, events)
+		}
+	}
+	if snapshot == nil {
+		snapshot = newListSnapshot(w.resourceVersion, now, w.store.List())
+	}
+	return w.snapshots.add(snapshot)
+}
+
+// listWithResourceVersion returns list of pointers to <storeElement> objects
+// together with the resourceVersion of the store.
+func (w *watchCache) listWithResourceVersion() ([]interface{}, uint64) {
+	w.RLock()
+	defer w.RUnlock()
+	return w.store.List(), w.resourceVersion
+}
+
 func (w *watchCache) ListKeys() []string {
 	return w.store.ListKeys()
 }
@@ -380,11 +550,16 @@ func (w *watchCache) Replace(objs []interface{}, resourceVersion string) error {
 		if err != nil {
 			return err
 		}
+		objAnnotations, err := storage.AnnotationAttrs(object)
+		if err != nil {
+			return err
+		}
 		toReplace = append(toReplace, &storeElement{
-			Key:    key,
-			Object: object,
-			Labels: objLabels,
-			Fields: objFields,
+			Key:         key,
+			Object:      object,
+			Labels:      objLabels,
+			Fields:      objFields,
+			Annotations: objAnnotations,
 		})
 	}
 
@@ -452,11 +627,16 @@ func (w *watchCache) GetAllEventsSinceThreadUnsafe(resourceVersion uint64) ([]*w
 			if err != nil {
 				return nil, err
 			}
+			objAnnotations, err := storage.AnnotationAttrs(elem.Object)
+			if err != nil {
+				return nil, err
+			}
 			result[i] = &watchCacheEvent{
 				Type:            watch.Added,
 				Object:          elem.Object,
 				ObjLabels:       objLabels,
 				ObjFields:       objFields,
+				ObjAnnotations:  objAnnotations,
 				Key:             elem.Key,
 				ResourceVersion: w.resourceVersion,
 			}
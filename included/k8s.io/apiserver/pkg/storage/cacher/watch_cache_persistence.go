@@ -0,0 +1,179 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cacher
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/klog"
+
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/api/meta"
+	metav1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/value"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/client-go/tools/cache"
+)
+
+// defaultSnapshotInterval is how often the state of the watch cache is saved
+// if Config.SnapshotInterval is not set.
+const defaultSnapshotInterval = time.Minute
+
+// snapshotFile stores the state of a watch cache in a file, so that a
+// restarted watch cache can resume watching the underlying storage from the
+// resourceVersion of the saved state instead of relisting it.
+type snapshotFile struct {
+	path        string
+	codec       runtime.Codec
+	transformer value.Transformer
+	versioner   storage.Versioner
+	newListFunc func() runtime.Object
+}
+
+// save atomically replaces the content of the file with objs at
+// resourceVersion.
+func (f *snapshotFile) save(objs []runtime.Object, resourceVersion uint64) error {
+	list := f.newListFunc()
+	if err := meta.SetList(list, objs); err != nil {
+		return err
+	}
+	if err := f.versioner.UpdateList(list, resourceVersion, "", nil); err != nil {
+		return err
+	}
+	data, err := runtime.Encode(f.codec, list)
+	if err != nil {
+		return err
+	}
+	// The file may contain sensitive objects, so it is transformed as
+	// the underlying storage transforms them, e.g. encrypted.
+	data, err = f.transformer.TransformToStorage(data, value.DefaultContext([]byte(f.path)))
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(f.path), filepath.Base(f.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path)
+}
+
+// load returns the list saved in the file. The error satisfies os.IsNotExist
+// if nothing was saved.
+func (f *snapshotFile) load() (runtime.Object, error) {
+	data, err := ioutil.ReadFile(f.path)
+	if err != nil {
+		return nil, err
+	}
+	data, _, err = f.transformer.TransformFromStorage(data, value.DefaultContext([]byte(f.path)))
+	if err != nil {
+		return nil, err
+	}
+	list := f.newListFunc()
+	if err := runtime.DecodeInto(f.codec, data, list); err != nil {
+		return nil, err
+	}
+	listAccessor, err := meta.ListAccessor(list)
+	if err != nil {
+		return nil, err
+	}
+	if len(listAccessor.GetResourceVersion()) == 0 {
+		return nil, fmt.Errorf("no resourceVersion in %s", f.path)
+	}
+	return list, nil
+}
+
+// restoringListerWatcher serves its first list from a snapshotFile, if it
+// exists, and delegates everything else to the wrapped ListerWatcher.
+//
+// The reflector of the watch cache then resumes watching from the
+// resourceVersion of the snapshot. If that resourceVersion has been
+// compacted in the meantime, the watch fails and the reflector relists,
+// this time from the underlying storage.
+type restoringListerWatcher struct {
+	cache.ListerWatcher
+
+	file     *snapshotFile
+	resource string
+	// restored is only accessed by the reflector, which never lists
+	// concurrently.
+	restored bool
+}
+
+func newRestoringListerWatcher(lw cache.ListerWatcher, file *snapshotFile, resource string) *restoringListerWatcher {
+	return &restoringListerWatcher{ListerWatcher: lw, file: file, resource: resource}
+}
+
+// List implements cache.ListerWatcher.
+func (lw *restoringListerWatcher) List(options metav1.ListOptions) (runtime.Object, error) {
+	if !lw.restored {
+		lw.restored = true
+		list, err := lw.file.load()
+		switch {
+		case err == nil:
+			klog.Infof("Restored watch cache of %s from %s", lw.resource, lw.file.path)
+			snapshotCounter.WithLabelValues(lw.resource, "restore", "success").Inc()
+			return list, nil
+		case os.IsNotExist(err):
+			klog.V(2).Infof("No watch cache snapshot of %s at %s", lw.resource, lw.file.path)
+		default:
+			klog.Warningf("Failed to restore watch cache of %s from %s, listing from storage: %v", lw.resource, lw.file.path, err)
+			snapshotCounter.WithLabelValues(lw.resource, "restore", "error").Inc()
+		}
+	}
+	return lw.ListerWatcher.List(options)
+}
+
+// saveSnapshot saves the current state of the watch cache to its snapshotFile,
+// if it changed since it was last saved.
+func (c *Cacher) saveSnapshot() {
+	if !c.ready.check() {
+		return
+	}
+	elems, resourceVersion := c.watchCache.listWithResourceVersion()
+	if resourceVersion == 0 || resourceVersion == c.snapshotResourceVersion {
+		return
+	}
+	objs := make([]runtime.Object, 0, len(elems))
+	for _, elem := range elems {
+		objs = append(objs, elem.(*storeElement).Object)
+	}
+	if err := c.snapshotFile.save(objs, resourceVersion); err != nil {
+		klog.Errorf("Failed to save watch cache of %v to %s: %v", c.objectType, c.snapshotFile.path, err)
+		snapshotCounter.WithLabelValues(c.objectType.String(), "save", "error").Inc()
+		return
+	}
+	klog.V(4).Infof("Saved watch cache of %v at resourceVersion %d to %s", c.objectType, resourceVersion, c.snapshotFile.path)
+	snapshotCounter.WithLabelValues(c.objectType.String(), "save", "success").Inc()
+	c.snapshotResourceVersion = resourceVersion
+}
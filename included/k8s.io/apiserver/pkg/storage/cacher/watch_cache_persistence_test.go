@@ -0,0 +1,183 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cacher
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	v1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/core/v1"
+	metav1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime/serializer"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/watch"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/etcd"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/value"
+)
+
+// reverseTransformer reverses the transformed data, which is enough to
+// verify that the data is transformed.
+type reverseTransformer struct{}
+
+func reverse(data []byte) []byte {
+	out := make([]byte, len(data))
+	for i, b := range data {
+		out[len(data)-1-i] = b
+	}
+	return out
+}
+
+func (reverseTransformer) TransformFromStorage(data []byte, context value.Context) ([]byte, bool, error) {
+	return reverse(data), false, nil
+}
+
+func (reverseTransformer) TransformToStorage(data []byte, context value.Context) ([]byte, error) {
+	return reverse(data), nil
+}
+
+// fakeListerWatcher counts the lists it serves.
+type fakeListerWatcher struct {
+	lists int
+}
+
+func (lw *fakeListerWatcher) List(options metav1.ListOptions) (runtime.Object, error) {
+	lw.lists++
+	return &v1.PodList{ListMeta: metav1.ListMeta{ResourceVersion: "200"}}, nil
+}
+
+func (lw *fakeListerWatcher) Watch(options metav1.ListOptions) (watch.Interface, error) {
+	return watch.NewFake(), nil
+}
+
+func newTestSnapshotFile(t *testing.T) (*snapshotFile, func()) {
+	dir, err := ioutil.TempDir("", "watch-cache-snapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	scheme := runtime.NewScheme()
+	if err := v1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	file := &snapshotFile{
+		path:        filepath.Join(dir, "pods.snapshot"),
+		codec:       serializer.NewCodecFactory(scheme).LegacyCodec(v1.SchemeGroupVersion),
+		transformer: reverseTransformer{},
+		versioner:   etcd.APIObjectVersioner{},
+		newListFunc: func() runtime.Object { return &v1.PodList{} },
+	}
+	return file, func() { os.RemoveAll(dir) }
+}
+
+func TestSnapshotFileSaveAndLoad(t *testing.T) {
+	file, cleanup := newTestSnapshotFile(t)
+	defer cleanup()
+
+	if _, err := file.load(); !os.IsNotExist(err) {
+		t.Fatalf("expected a not exist error, got %v", err)
+	}
+
+	objs := []runtime.Object{
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "foo", ResourceVersion: "101"}},
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "bar", ResourceVersion: "105"}},
+	}
+	if err := file.save(objs, 110); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := ioutil.ReadFile(file.path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if bytes.Contains(data, []byte("foo")) {
+		t.Errorf("expected the saved data to be transformed")
+	}
+
+	loaded, err := file.load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	list := loaded.(*v1.PodList)
+	if list.ResourceVersion != "110" {
+		t.Errorf("expected resourceVersion 110, got %q", list.ResourceVersion)
+	}
+	if len(list.Items) != 2 || list.Items[0].Name != "foo" || list.Items[1].ResourceVersion != "105" {
+		t.Errorf("unexpected items: %#v", list.Items)
+	}
+
+	// Saving again replaces the previous state.
+	if err := file.save(objs[:1], 120); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	loaded, err = file.load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if list := loaded.(*v1.PodList); list.ResourceVersion != "120" || len(list.Items) != 1 {
+		t.Errorf("unexpected list: %#v", list)
+	}
+}
+
+func TestRestoringListerWatcher(t *testing.T) {
+	file, cleanup := newTestSnapshotFile(t)
+	defer cleanup()
+	objs := []runtime.Object{&v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "foo", ResourceVersion: "101"}}}
+	if err := file.save(objs, 110); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	storageLW := &fakeListerWatcher{}
+	lw := newRestoringListerWatcher(storageLW, file, "pods")
+
+	// The first list is restored from the snapshot.
+	list, err := lw.List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rv := list.(*v1.PodList).ResourceVersion; rv != "110" || storageLW.lists != 0 {
+		t.Errorf("expected a list restored at 110, got %q with %d lists from storage", rv, storageLW.lists)
+	}
+
+	// Relists, e.g. after the resourceVersion of the snapshot was
+	// compacted, are served by the storage.
+	list, err = lw.List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rv := list.(*v1.PodList).ResourceVersion; rv != "200" || storageLW.lists != 1 {
+		t.Errorf("expected a list from storage at 200, got %q with %d lists from storage", rv, storageLW.lists)
+	}
+}
+
+func TestRestoringListerWatcherInvalidSnapshot(t *testing.T) {
+	file, cleanup := newTestSnapshotFile(t)
+	defer cleanup()
+	if err := ioutil.WriteFile(file.path, []byte("garbage"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	storageLW := &fakeListerWatcher{}
+	lw := newRestoringListerWatcher(storageLW, file, "pods")
+	list, err := lw.List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rv := list.(*v1.PodList).ResourceVersion; rv != "200" || storageLW.lists != 1 {
+		t.Errorf("expected a list from storage at 200, got %q with %d lists from storage", rv, storageLW.lists)
+	}
+}
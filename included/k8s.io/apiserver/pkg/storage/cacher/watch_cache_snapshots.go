@@ -0,0 +1,201 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cacher
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/watch"
+)
+
+const (
+	// Maximum number of snapshots kept by a watchCache.
+	defaultSnapshotsCapacity = 16
+	// Snapshots expire after snapshotTTL, like continue tokens of the etcd3
+	// storage expire once their revision is compacted.
+	snapshotTTL = 5 * time.Minute
+)
+
+// listSnapshot is the immutable state of the watch cache at a given
+// resourceVersion, sorted by key. It allows to serve all pages of a
+// paginated list from the same resourceVersion, while the watch cache is
+// further updated.
+type listSnapshot struct {
+	resourceVersion uint64
+	created         time.Time
+	// elems are sorted by Key.
+	elems []*storeElement
+}
+
+func newListSnapshot(resourceVersion uint64, created time.Time, objs []interface{}) *listSnapshot {
+	elems := make([]*storeElement, 0, len(objs))
+	for _, obj := range objs {
+		elems = append(elems, obj.(*storeElement))
+	}
+	sort.Slice(elems, func(i, j int) bool { return elems[i].Key < elems[j].Key })
+	return &listSnapshot{resourceVersion: resourceVersion, created: created, elems: elems}
+}
+
+// apply returns the snapshot at resourceVersion obtained by applying to s
+// the events that happened after it, in order. Unlike newListSnapshot, it
+// only sorts the keys of the events, and shares the elements of s.
+func (s *listSnapshot) apply(resourceVersion uint64, created time.Time, events []*watchCacheEvent) *listSnapshot {
+	if len(events) == 0 {
+		// Snapshots are immutable, so the elements can be shared.
+		return &listSnapshot{resourceVersion: resourceVersion, created: created, elems: s.elems}
+	}
+	// Only the last event of a key matters. A nil element is a deletion.
+	changed := make(map[string]*storeElement, len(events))
+	for _, event := range events {
+		switch event.Type {
+		case watch.Added, watch.Modified:
+			changed[event.Key] = &storeElement{
+				Key:         event.Key,
+				Object:      event.Object,
+				Labels:      event.ObjLabels,
+				Fields:      event.ObjFields,
+				Annotations: event.ObjAnnotations,
+			}
+		case watch.Deleted:
+			changed[event.Key] = nil
+		}
+	}
+	keys := make([]string, 0, len(changed))
+	for key := range changed {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	elems := make([]*storeElement, 0, len(s.elems)+len(keys))
+	i := 0
+	for _, key := range keys {
+		// The elements between two changed keys are copied at once.
+		j := i + sort.Search(len(s.elems)-i, func(k int) bool {
+			return s.elems[i+k].Key >= key
+		})
+		elems = append(elems, s.elems[i:j]...)
+		i = j
+		if i < len(s.elems) && s.elems[i].Key == key {
+			i++
+		}
+		if elem := changed[key]; elem != nil {
+			elems = append(elems, elem)
+		}
+	}
+	elems = append(elems, s.elems[i:]...)
+	return &listSnapshot{resourceVersion: resourceVersion, created: created, elems: elems}
+}
+
+// keyRange returns the indexes of the elements whose key has keyPrefix and
+// is greater than or equal to fromKey.
+func (s *listSnapshot) keyRange(keyPrefix, fromKey string) (int, int) {
+	if fromKey < keyPrefix {
+		fromKey = keyPrefix
+	}
+	start := sort.Search(len(s.elems), func(i int) bool {
+		return s.elems[i].Key >= fromKey
+	})
+	// Keys with keyPrefix are contiguous, so the keys after them are
+	// the first ones greater than keyPrefix that don't have it.
+	end := sort.Search(len(s.elems), func(i int) bool {
+		key := s.elems[i].Key
+		return key > keyPrefix && !strings.HasPrefix(key, keyPrefix)
+	})
+	if start > end {
+		start = end
+	}
+	return start, end
+}
+
+// listSnapshots holds the most recent listSnapshots of a watchCache.
+type listSnapshots struct {
+	lock sync.Mutex
+
+	capacity int
+	// snapshots are sorted by resourceVersion.
+	snapshots []*listSnapshot
+}
+
+func newListSnapshots(capacity int) *listSnapshots {
+	return &listSnapshots{capacity: capacity}
+}
+
+// get returns the snapshot at resourceVersion, if it exists and has not
+// expired at now.
+func (s *listSnapshots) get(resourceVersion uint64, now time.Time) (*listSnapshot, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.expireLocked(now)
+	for _, snapshot := range s.snapshots {
+		if snapshot.resourceVersion == resourceVersion {
+			return snapshot, true
+		}
+	}
+	return nil, false
+}
+
+// latest returns the most recent snapshot that has not expired at now.
+func (s *listSnapshots) latest(now time.Time) (*listSnapshot, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.expireLocked(now)
+	if len(s.snapshots) == 0 {
+		return nil, false
+	}
+	return s.snapshots[len(s.snapshots)-1], true
+}
+
+// add adds snapshot, dropping the oldest snapshot if capacity is exceeded.
+// If a snapshot at the same resourceVersion exists, it is returned instead,
+// so that concurrent lists creating the same snapshot share it.
+func (s *listSnapshots) add(snapshot *listSnapshot) *listSnapshot {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, existing := range s.snapshots {
+		if existing.resourceVersion == snapshot.resourceVersion {
+			return existing
+		}
+	}
+	i := sort.Search(len(s.snapshots), func(i int) bool {
+		return s.snapshots[i].resourceVersion > snapshot.resourceVersion
+	})
+	s.snapshots = append(s.snapshots, nil)
+	copy(s.snapshots[i+1:], s.snapshots[i:])
+	s.snapshots[i] = snapshot
+	if len(s.snapshots) > s.capacity {
+		// Drop the oldest snapshot.
+		s.snapshots = s.snapshots[1:]
+	}
+	return snapshot
+}
+
+func (s *listSnapshots) expireLocked(now time.Time) {
+	i := 0
+	for _, snapshot := range s.snapshots {
+		if now.Sub(snapshot.created) < snapshotTTL {
+			s.snapshots[i] = snapshot
+			i++
+		}
+	}
+	for j := i; j < len(s.snapshots); j++ {
+		s.snapshots[j] = nil
+	}
+	s.snapshots = s.snapshots[:i]
+}
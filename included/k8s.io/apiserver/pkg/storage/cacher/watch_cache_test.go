@@ -18,6 +18,7 @@ package cacher
 
 import (
 	"fmt"
+	"reflect"
 	"strconv"
 	"strings"
 	"testing"
@@ -56,10 +57,11 @@ func makeTestPod(name string, resourceVersion uint64) *v1.Pod {
 
 func makeTestStoreElement(pod *v1.Pod) *storeElement {
 	return &storeElement{
-		Key:    "prefix/ns/" + pod.Name,
-		Object: pod,
-		Labels: labels.Set(pod.Labels),
-		Fields: fields.Set{"spec.nodeName": pod.Spec.NodeName},
+		Key:         "prefix/ns/" + pod.Name,
+		Object:      pod,
+		Labels:      labels.Set(pod.Labels),
+		Fields:      fields.Set{"spec.nodeName": pod.Spec.NodeName},
+		Annotations: labels.Set(pod.Annotations),
 	}
 }
 
@@ -77,7 +79,7 @@ func newTestWatchCache(capacity int) *watchCache {
 	}
 	versioner := etcd.APIObjectVersioner{}
 	mockHandler := func(*watchCacheEvent) {}
-	wc := newWatchCache(capacity, keyFunc, mockHandler, getAttrsFunc, versioner)
+	wc := newWatchCache(capacity, keyFunc, mockHandler, getAttrsFunc, versioner, nil)
 	wc.clock = clock.NewFakeClock(time.Now())
 	return wc
 }
@@ -323,7 +325,7 @@ func TestWaitUntilFreshAndList(t *testing.T) {
 		store.Add(makeTestPod("bar", 5))
 	}()
 
-	list, resourceVersion, err := store.WaitUntilFreshAndList(5, nil)
+	list, resourceVersion, _, err := store.WaitUntilFreshAndList(5, nil, nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -378,7 +380,7 @@ func TestWaitUntilFreshAndListTimeout(t *testing.T) {
 		store.Add(makeTestPod("bar", 5))
 	}()
 
-	_, _, err := store.WaitUntilFreshAndList(5, nil)
+	_, _, _, err := store.WaitUntilFreshAndList(5, nil, nil)
 	if err == nil {
 		t.Fatalf("unexpected lack of timeout error")
 	}
@@ -400,7 +402,7 @@ func TestReflectorForWatchCache(t *testing.T) {
 	store := newTestWatchCache(5)
 
 	{
-		_, version, err := store.WaitUntilFreshAndList(0, nil)
+		_, version, _, err := store.WaitUntilFreshAndList(0, nil, nil)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -423,7 +425,7 @@ func TestReflectorForWatchCache(t *testing.T) {
 	r.ListAndWatch(wait.NeverStop)
 
 	{
-		_, version, err := store.WaitUntilFreshAndList(10, nil)
+		_, version, _, err := store.WaitUntilFreshAndList(10, nil, nil)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -432,3 +434,232 @@ func TestReflectorForWatchCache(t *testing.T) {
 		}
 	}
 }
+
+func TestReflectorBookmarksForWatchCache(t *testing.T) {
+	store := newTestWatchCache(5)
+	var events []*watchCacheEvent
+	store.eventHandler = func(event *watchCacheEvent) {
+		events = append(events, event)
+	}
+
+	watched := false
+	lw := &testLW{
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			// Stop the reflector once the events were processed.
+			if watched {
+				return nil, fmt.Errorf("watched once")
+			}
+			watched = true
+			fw := watch.NewFakeWithChanSize(2, false)
+			fw.Add(makeTestPod("pod", 15))
+			// A bookmark of the storage, e.g. an etcd progress notification.
+			fw.Action(watch.Bookmark, &v1.Pod{ObjectMeta: metav1.ObjectMeta{ResourceVersion: "20"}})
+			fw.Stop()
+			return fw, nil
+		},
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			return &v1.PodList{ListMeta: metav1.ListMeta{ResourceVersion: "10"}}, nil
+		},
+	}
+	r := cache.NewReflector(lw, &v1.Pod{}, store, 0)
+	r.ListAndWatch(wait.NeverStop)
+
+	_, version, _, err := store.WaitUntilFreshAndList(20, nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if version != 20 {
+		t.Errorf("unexpected resource version: %d", version)
+	}
+	if len(events) != 2 || events[1].Type != watch.Bookmark || events[1].ResourceVersion != 20 {
+		t.Errorf("expected an event and a bookmark at 20, got %#v", events)
+	}
+
+	// Bookmarks are not stored in the history of events.
+	cachedEvents, err := store.GetAllEventsSince(10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cachedEvents) != 1 || cachedEvents[0].ResourceVersion != 15 {
+		t.Errorf("expected only the event at 15, got %#v", cachedEvents)
+	}
+}
+
+func TestWatchCacheSnapshots(t *testing.T) {
+	store := newTestWatchCache(10)
+	for i := 0; i < 3; i++ {
+		if err := store.Add(makeTestPod(fmt.Sprintf("pod-%d", i), uint64(i+1))); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	keys := func(snapshot *listSnapshot, keyPrefix, fromKey string) []string {
+		var result []string
+		start, end := snapshot.keyRange(keyPrefix, fromKey)
+		for _, elem := range snapshot.elems[start:end] {
+			result = append(result, elem.Key)
+		}
+		return result
+	}
+
+	snapshot, ok := store.Snapshot(3)
+	if !ok {
+		t.Fatalf("expected a snapshot at the current resource version")
+	}
+	if _, ok := store.Snapshot(2); ok {
+		t.Errorf("unexpected snapshot at resource version 2")
+	}
+
+	// The snapshot is not affected by later changes.
+	if err := store.Add(makeTestPod("pod-3", 4)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := store.Delete(makeTestPod("pod-0", 5)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	snapshot, ok = store.Snapshot(3)
+	if !ok {
+		t.Fatalf("expected the snapshot at resource version 3 to be kept")
+	}
+	expected := []string{"prefix/ns/pod-0", "prefix/ns/pod-1", "prefix/ns/pod-2"}
+	if got := keys(snapshot, "prefix/ns/", ""); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+	expected = []string{"prefix/ns/pod-2"}
+	if got := keys(snapshot, "prefix/ns/", "prefix/ns/pod-1\x00"); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+	if got := keys(snapshot, "prefix/other/", ""); len(got) != 0 {
+		t.Errorf("expected no keys, got %v", got)
+	}
+
+	snapshot, err := store.WaitUntilFreshAndSnapshot(4, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if snapshot.resourceVersion != 5 {
+		t.Errorf("expected a snapshot at resource version 5, got %d", snapshot.resourceVersion)
+	}
+	expected = []string{"prefix/ns/pod-1", "prefix/ns/pod-2", "prefix/ns/pod-3"}
+	if got := keys(snapshot, "prefix/ns/", ""); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+
+	// Snapshots expire.
+	store.clock.(*clock.FakeClock).Step(snapshotTTL)
+	if _, ok := store.Snapshot(3); ok {
+		t.Errorf("expected the snapshot at resource version 3 to expire")
+	}
+}
+
+func TestListSnapshotApply(t *testing.T) {
+	elem := func(name string, resourceVersion uint64) *storeElement {
+		return makeTestStoreElement(makeTestPod(name, resourceVersion))
+	}
+	event := func(eventType watch.EventType, name string, resourceVersion uint64) *watchCacheEvent {
+		e := elem(name, resourceVersion)
+		return &watchCacheEvent{Type: eventType, Object: e.Object, ObjLabels: e.Labels, ObjFields: e.Fields, Key: e.Key, ResourceVersion: resourceVersion}
+	}
+	snapshot := newListSnapshot(4, time.Now(), []interface{}{elem("c", 2), elem("a", 1), elem("e", 3), elem("g", 4)})
+
+	next := snapshot.apply(12, time.Now(), []*watchCacheEvent{
+		event(watch.Added, "b", 5),
+		event(watch.Modified, "c", 6),
+		event(watch.Deleted, "e", 7),
+		event(watch.Added, "h", 8),
+		event(watch.Added, "0", 9),
+		event(watch.Deleted, "0", 10),
+		event(watch.Modified, "g", 11),
+		event(watch.Deleted, "g", 12),
+	})
+	expected := map[string]string{"prefix/ns/a": "1", "prefix/ns/b": "5", "prefix/ns/c": "6", "prefix/ns/h": "8"}
+	var keys []string
+	for _, elem := range next.elems {
+		keys = append(keys, elem.Key)
+		if rv := elem.Object.(*v1.Pod).ResourceVersion; rv != expected[elem.Key] {
+			t.Errorf("expected %s at resource version %s, got %s", elem.Key, expected[elem.Key], rv)
+		}
+	}
+	if expectedKeys := []string{"prefix/ns/a", "prefix/ns/b", "prefix/ns/c", "prefix/ns/h"}; !reflect.DeepEqual(keys, expectedKeys) {
+		t.Errorf("expected %v, got %v", expectedKeys, keys)
+	}
+	if next.resourceVersion != 12 {
+		t.Errorf("expected a snapshot at resource version 12, got %d", next.resourceVersion)
+	}
+
+	// The snapshot the events are applied to is not modified.
+	keys = nil
+	for _, elem := range snapshot.elems {
+		keys = append(keys, elem.Key)
+	}
+	if expectedKeys := []string{"prefix/ns/a", "prefix/ns/c", "prefix/ns/e", "prefix/ns/g"}; !reflect.DeepEqual(keys, expectedKeys) {
+		t.Errorf("expected %v, got %v", expectedKeys, keys)
+	}
+}
+
+func TestWatchCacheIncrementalSnapshots(t *testing.T) {
+	store := newTestWatchCache(3)
+	store.Replace(nil, "1")
+	for i := 0; i < 3; i++ {
+		if err := store.Add(makeTestPod(fmt.Sprintf("pod-%d", i), uint64(i+2))); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	// checkSnapshot verifies that snapshot has the objects of the store.
+	checkSnapshot := func(snapshot *listSnapshot) {
+		t.Helper()
+		expected := newListSnapshot(store.resourceVersion, time.Now(), store.List())
+		if len(snapshot.elems) != len(expected.elems) {
+			t.Fatalf("expected %d elements, got %d", len(expected.elems), len(snapshot.elems))
+		}
+		for i := range expected.elems {
+			if !reflect.DeepEqual(snapshot.elems[i], expected.elems[i]) {
+				t.Errorf("expected %#v, got %#v", expected.elems[i], snapshot.elems[i])
+			}
+		}
+	}
+
+	first, ok := store.Snapshot(4)
+	if !ok {
+		t.Fatalf("expected a snapshot at the current resource version")
+	}
+	checkSnapshot(first)
+
+	// Without events, the elements of the previous snapshot are shared.
+	store.UpdateResourceVersion("5")
+	snapshot, ok := store.Snapshot(5)
+	if !ok {
+		t.Fatalf("expected a snapshot at the current resource version")
+	}
+	if &snapshot.elems[0] != &first.elems[0] {
+		t.Errorf("expected the snapshot at resource version 5 to share the elements of the one at 4")
+	}
+
+	if err := store.Update(makeTestPod("pod-1", 6)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := store.Delete(makeTestPod("pod-0", 7)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	snapshot, ok = store.Snapshot(7)
+	if !ok {
+		t.Fatalf("expected a snapshot at the current resource version")
+	}
+	checkSnapshot(snapshot)
+
+	// Once the events after the latest snapshot are no longer cached, the
+	// snapshot is built from the whole store.
+	for i := 3; i < 7; i++ {
+		if err := store.Add(makeTestPod(fmt.Sprintf("pod-%d", i), uint64(i+5))); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if _, err := store.GetAllEventsSince(7); err == nil {
+		t.Fatalf("expected the events after resource version 7 to be dropped")
+	}
+	snapshot, ok = store.Snapshot(11)
+	if !ok {
+		t.Fatalf("expected a snapshot at the current resource version")
+	}
+	checkSnapshot(snapshot)
+}
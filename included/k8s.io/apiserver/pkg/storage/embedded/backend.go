@@ -0,0 +1,634 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package embedded
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/util/wait"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/klog"
+)
+
+const (
+	// expiryInterval is how often keys with a TTL are checked for expiration.
+	expiryInterval = time.Second
+
+	// rangeToEnd is the range end that selects every key after the range start,
+	// following the etcd convention.
+	rangeToEnd = "\x00"
+
+	// defaultSnapshotCount is the number of write-ahead log records after which
+	// the keyspace is snapshotted and the log truncated.
+	defaultSnapshotCount = 10000
+)
+
+var (
+	// errCompacted is returned when the requested revision has been compacted.
+	errCompacted = errors.New("embedded: required revision has been compacted")
+	// errFutureRev is returned when the requested revision is newer than the current one.
+	errFutureRev = errors.New("embedded: required revision is a future revision")
+	// errClosed is returned for operations on a closed Backend.
+	errClosed = errors.New("embedded: backend is closed")
+)
+
+// isCompacted reports whether err is caused by a compacted revision.
+func isCompacted(err error) bool {
+	return err == errCompacted
+}
+
+// keyValue is a single version of a key. keyValues are never mutated once they
+// have been committed, so they can be shared between the keyspace, its history
+// and watchers without copying.
+type keyValue struct {
+	Key            string `json:"key"`
+	Value          []byte `json:"value,omitempty"`
+	CreateRevision int64  `json:"createRevision"`
+	ModRevision    int64  `json:"modRevision"`
+	// ExpiresAt is the unix time in nanoseconds after which the key is deleted,
+	// or zero if the key does not expire.
+	ExpiresAt int64 `json:"expiresAt,omitempty"`
+}
+
+// kvEvent is a single committed change to the keyspace.
+type kvEvent struct {
+	// kv is the new version of the key. For deletions only Key and
+	// ModRevision (the revision of the deletion) are set.
+	kv        *keyValue
+	prevKV    *keyValue
+	isDeleted bool
+}
+
+func (e *kvEvent) rev() int64 {
+	return e.kv.ModRevision
+}
+
+// txnOp is the mutation applied by Backend.txn.
+type txnOp struct {
+	delete    bool
+	value     []byte
+	expiresAt int64
+}
+
+// rangeResult is the result of a range read.
+type rangeResult struct {
+	kvs []*keyValue
+	// more is true if the limit cut the result short.
+	more bool
+	// count is the total number of keys in the range, ignoring the limit.
+	count int64
+	// rev is the revision the range was read at.
+	rev int64
+}
+
+// Backend is a revisioned key-value store shared by all stores created for the
+// same data directory, in the same way resources stored in one etcd cluster
+// share its revision sequence. Unless the Backend is memory-only, every
+// committed change is appended to the write-ahead log before it becomes
+// visible.
+type Backend struct {
+	mu sync.RWMutex
+
+	// rev is the revision of the last committed change.
+	rev int64
+	// compactRev is the revision up to which history has been discarded.
+	compactRev int64
+	kvs        map[string]*keyValue
+	// keys holds the keys of kvs in sorted order to serve range reads.
+	keys []string
+	// leased holds the keys that have a TTL.
+	leased map[string]struct{}
+	// history holds the events after compactRev in revision order.
+	history  []*kvEvent
+	watchers map[*kvWatcher]struct{}
+
+	wal           *wal
+	snapshotCount int
+	// err is set when persisting a change failed. The in-memory state can no
+	// longer be trusted to match the disk after that, so all writes fail.
+	err    error
+	closed bool
+	stopCh chan struct{}
+}
+
+// Open opens the Backend persisted in dir, creating the directory if it does
+// not exist yet. The most recent snapshot is loaded and the write-ahead log is
+// replayed on top of it. History before the snapshot is not retained, so
+// watches and reads from older revisions fail as if they had been compacted.
+func Open(dir string) (*Backend, error) {
+	b := newBackend()
+	w, err := openWAL(dir, b.restore)
+	if err != nil {
+		return nil, err
+	}
+	b.wal = w
+	b.start()
+	return b, nil
+}
+
+// NewMemory returns a Backend that keeps its keyspace in memory only. Its
+// semantics are the same as those of a Backend opened from a data directory,
+// but nothing survives Close.
+func NewMemory() *Backend {
+	b := newBackend()
+	b.start()
+	return b
+}
+
+func newBackend() *Backend {
+	return &Backend{
+		kvs:           map[string]*keyValue{},
+		leased:        map[string]struct{}{},
+		watchers:      map[*kvWatcher]struct{}{},
+		snapshotCount: defaultSnapshotCount,
+		stopCh:        make(chan struct{}),
+	}
+}
+
+func (b *Backend) start() {
+	go wait.Until(b.expireKeys, expiryInterval, b.stopCh)
+}
+
+// Close snapshots the keyspace, closes the write-ahead log and terminates all
+// watchers. It is safe to call Close more than once.
+func (b *Backend) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return nil
+	}
+	b.closed = true
+	close(b.stopCh)
+	for w := range b.watchers {
+		w.close()
+	}
+	b.watchers = nil
+
+	if b.wal == nil {
+		return nil
+	}
+	var errs []string
+	if b.err == nil {
+		if err := b.wal.snapshot(b.snapshotState()); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if err := b.wal.close(); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to close embedded storage: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// Healthy returns an error if the Backend cannot serve writes.
+func (b *Backend) Healthy() error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return errClosed
+	}
+	if b.err != nil {
+		return fmt.Errorf("embedded storage failed to persist a change: %v", b.err)
+	}
+	return nil
+}
+
+// Revision returns the revision of the last committed change.
+func (b *Backend) Revision() int64 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.rev
+}
+
+// get returns the version of key at rev, or nil if the key did not exist then.
+// A rev of zero reads the current version.
+func (b *Backend) get(key string, rev int64) (*keyValue, int64, error) {
+	res, err := b.rangeKeys(key, "", rev, 0)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(res.kvs) == 0 {
+		return nil, res.rev, nil
+	}
+	return res.kvs[0], res.rev, nil
+}
+
+// rangeKeys returns the keys in [key, end) as they were at rev, or only key if
+// end is empty. A rev of zero reads the current state. A limit of zero returns
+// all keys in the range.
+func (b *Backend) rangeKeys(key, end string, rev, limit int64) (*rangeResult, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return nil, errClosed
+	}
+	if rev == 0 {
+		rev = b.rev
+	}
+	if rev < b.compactRev {
+		return nil, errCompacted
+	}
+	if rev > b.rev {
+		return nil, errFutureRev
+	}
+	inRange := func(k string) bool {
+		switch end {
+		case "":
+			return k == key
+		case rangeToEnd:
+			return k >= key
+		default:
+			return k >= key && k < end
+		}
+	}
+
+	// Undo the changes made after rev. Walking the history backwards, the
+	// last change seen for a key is the first one after rev, and its previous
+	// value is the one the key had at rev.
+	var past map[string]*keyValue
+	for i := len(b.history) - 1; i >= 0 && b.history[i].rev() > rev; i-- {
+		e := b.history[i]
+		if !inRange(e.kv.Key) {
+			continue
+		}
+		if past == nil {
+			past = map[string]*keyValue{}
+		}
+		past[e.kv.Key] = e.prevKV
+	}
+
+	var keys []string
+	if len(end) == 0 {
+		keys = []string{key}
+	} else {
+		keys = b.keys[b.search(key):b.search(end)]
+		if len(past) > 0 {
+			merged := make([]string, len(keys), len(keys)+len(past))
+			copy(merged, keys)
+			for k := range past {
+				if _, ok := b.kvs[k]; !ok {
+					merged = append(merged, k)
+				}
+			}
+			sort.Strings(merged)
+			keys = merged
+		}
+	}
+
+	res := &rangeResult{rev: rev}
+	for _, k := range keys {
+		kv, changed := past[k]
+		if !changed {
+			kv = b.kvs[k]
+		}
+		if kv == nil {
+			continue
+		}
+		res.count++
+		if limit > 0 && int64(len(res.kvs)) >= limit {
+			res.more = true
+			continue
+		}
+		res.kvs = append(res.kvs, kv)
+	}
+	return res, nil
+}
+
+// count returns the number of keys in [key, end).
+func (b *Backend) count(key, end string) (int64, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return 0, errClosed
+	}
+	return int64(b.search(end) - b.search(key)), nil
+}
+
+// changedSince returns the current revision and whether a key in [key, end)
+// changed after rev. Changes before the compacted revision are not known, so
+// they count as changes.
+func (b *Backend) changedSince(key, end string, rev int64) (int64, bool, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return 0, false, errClosed
+	}
+	if rev < b.compactRev {
+		return b.rev, true, nil
+	}
+	for i := len(b.history) - 1; i >= 0 && b.history[i].rev() > rev; i-- {
+		if k := b.history[i].kv.Key; k >= key && k < end {
+			return b.rev, true, nil
+		}
+	}
+	return b.rev, false, nil
+}
+
+// search returns the index in b.keys at which key would be inserted. b.mu must
+// be held.
+func (b *Backend) search(key string) int {
+	if key == rangeToEnd {
+		return len(b.keys)
+	}
+	return sort.SearchStrings(b.keys, key)
+}
+
+// keyTxn is the comparison and the mutation of a single key in a
+// transaction.
+type keyTxn struct {
+	key string
+	// expectedModRev is the modRevision the key must have, where zero means
+	// that the key must not exist.
+	expectedModRev int64
+	op             txnOp
+}
+
+// txn applies op to key if the current modRevision of the key equals
+// expectedModRev, where zero means that the key must not exist. When the
+// comparison fails, the current version of the key is returned and nothing
+// is changed.
+func (b *Backend) txn(key string, expectedModRev int64, op txnOp) (succeeded bool, rev int64, current *keyValue, err error) {
+	succeeded, rev, currents, err := b.multiTxn([]keyTxn{{key: key, expectedModRev: expectedModRev, op: op}})
+	if err != nil {
+		return false, 0, nil, err
+	}
+	return succeeded, rev, currents[0], nil
+}
+
+// multiTxn applies the ops of all txns in a single revision if the current
+// modRevision of every key equals its expectedModRev. Otherwise nothing is
+// changed. The versions of the keys before the transaction are returned in
+// either case. The keys of txns must be distinct.
+func (b *Backend) multiTxn(txns []keyTxn) (succeeded bool, rev int64, current []*keyValue, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return false, 0, nil, errClosed
+	}
+	if b.err != nil {
+		return false, 0, nil, b.err
+	}
+	succeeded = true
+	current = make([]*keyValue, len(txns))
+	for i, t := range txns {
+		current[i] = b.kvs[t.key]
+		var currentRev int64
+		if current[i] != nil {
+			currentRev = current[i].ModRevision
+		}
+		if currentRev != t.expectedModRev {
+			succeeded = false
+		}
+	}
+	if !succeeded {
+		return false, b.rev, current, nil
+	}
+
+	rev = b.rev + 1
+	var changes []walChange
+	for i, t := range txns {
+		if t.op.delete && current[i] == nil {
+			continue
+		}
+		change := walChange{Delete: t.op.delete}
+		if t.op.delete {
+			change.KV = &keyValue{Key: t.key, ModRevision: rev}
+		} else {
+			createRev := rev
+			if current[i] != nil {
+				createRev = current[i].CreateRevision
+			}
+			change.KV = &keyValue{
+				Key:            t.key,
+				Value:          t.op.value,
+				CreateRevision: createRev,
+				ModRevision:    rev,
+				ExpiresAt:      t.op.expiresAt,
+			}
+		}
+		changes = append(changes, change)
+	}
+	if len(changes) == 0 {
+		return true, b.rev, current, nil
+	}
+	rec := &walRecord{Revision: rev, walChange: changes[0], Txn: changes[1:]}
+	if err := b.commit(rec); err != nil {
+		return false, 0, nil, err
+	}
+	return true, b.rev, current, nil
+}
+
+// commit persists rec and applies it to the keyspace. b.mu must be held.
+func (b *Backend) commit(rec *walRecord) error {
+	if b.wal != nil {
+		if err := b.wal.append(rec); err != nil {
+			klog.Errorf("embedded storage: failed to persist revision %d: %v", rec.Revision, err)
+			b.err = err
+			return err
+		}
+	}
+	events := b.apply(rec)
+	for w := range b.watchers {
+		for _, e := range events {
+			if !w.deliver(e) {
+				// The watcher is closed, its consumer stops watching.
+				delete(b.watchers, w)
+				break
+			}
+		}
+	}
+	if b.wal != nil && b.wal.records >= b.snapshotCount {
+		if err := b.wal.snapshot(b.snapshotState()); err != nil {
+			// The change is safely in the log, so a failed snapshot only
+			// means the log keeps growing until the next attempt.
+			klog.Errorf("embedded storage: failed to snapshot at revision %d: %v", b.rev, err)
+		}
+	}
+	return nil
+}
+
+// apply applies rec to the keyspace and records it in the history. b.mu must
+// be held.
+func (b *Backend) apply(rec *walRecord) []*kvEvent {
+	var events []*kvEvent
+	for _, change := range rec.changes() {
+		key := change.KV.Key
+		e := &kvEvent{kv: change.KV, prevKV: b.kvs[key], isDeleted: change.Delete}
+		if change.Delete {
+			delete(b.kvs, key)
+			delete(b.leased, key)
+			if i := sort.SearchStrings(b.keys, key); i < len(b.keys) && b.keys[i] == key {
+				b.keys = append(b.keys[:i], b.keys[i+1:]...)
+			}
+		} else {
+			if e.prevKV == nil {
+				i := sort.SearchStrings(b.keys, key)
+				b.keys = append(b.keys, "")
+				copy(b.keys[i+1:], b.keys[i:])
+				b.keys[i] = key
+			}
+			b.kvs[key] = change.KV
+			if change.KV.ExpiresAt != 0 {
+				b.leased[key] = struct{}{}
+			} else {
+				delete(b.leased, key)
+			}
+		}
+		b.history = append(b.history, e)
+		events = append(events, e)
+	}
+	b.rev = rec.Revision
+	return events
+}
+
+// restore is called by the write-ahead log while the Backend is opened, first
+// with the snapshot (if any) and then with every record logged after it.
+func (b *Backend) restore(snap *snapshot, rec *walRecord) {
+	if snap != nil {
+		b.rev = snap.Revision
+		b.compactRev = snap.Revision
+		for _, kv := range snap.KVs {
+			b.kvs[kv.Key] = kv
+			b.keys = append(b.keys, kv.Key)
+			if kv.ExpiresAt != 0 {
+				b.leased[kv.Key] = struct{}{}
+			}
+		}
+		sort.Strings(b.keys)
+		return
+	}
+	if rec.Revision <= b.rev {
+		// the record is already reflected in the snapshot
+		return
+	}
+	b.apply(rec)
+}
+
+// snapshotState returns the current state of the keyspace. b.mu must be held.
+func (b *Backend) snapshotState() *snapshot {
+	snap := &snapshot{Revision: b.rev, KVs: make([]*keyValue, 0, len(b.keys))}
+	for _, k := range b.keys {
+		snap.KVs = append(snap.KVs, b.kvs[k])
+	}
+	return snap
+}
+
+// compact discards the history up to and including rev. Reads and watches
+// from revisions before rev fail afterwards.
+func (b *Backend) compact(rev int64) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return errClosed
+	}
+	if rev <= b.compactRev {
+		return errCompacted
+	}
+	if rev > b.rev {
+		return errFutureRev
+	}
+	i := sort.Search(len(b.history), func(i int) bool {
+		return b.history[i].rev() > rev
+	})
+	remaining := make([]*kvEvent, len(b.history)-i)
+	copy(remaining, b.history[i:])
+	b.history = remaining
+	b.compactRev = rev
+	return nil
+}
+
+// expireKeys deletes all keys whose TTL has passed.
+func (b *Backend) expireKeys() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed || b.err != nil || len(b.leased) == 0 {
+		return
+	}
+	now := time.Now().UnixNano()
+	var expired []string
+	for key := range b.leased {
+		if b.kvs[key].ExpiresAt <= now {
+			expired = append(expired, key)
+		}
+	}
+	sort.Strings(expired)
+	for _, key := range expired {
+		rec := &walRecord{Revision: b.rev + 1, walChange: walChange{Delete: true, KV: &keyValue{Key: key, ModRevision: b.rev + 1}}}
+		if err := b.commit(rec); err != nil {
+			return
+		}
+	}
+}
+
+// watch registers a watcher for key, or for every key under it if recursive
+// is set. If rev is zero, the current versions of the watched keys are queued
+// as creation events first and changes are delivered from the next revision on.
+// Otherwise changes are delivered from rev+1 on, replaying the history if rev
+// is in the past.
+func (b *Backend) watch(key string, recursive bool, rev int64) (*kvWatcher, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return nil, errClosed
+	}
+	w := newKVWatcher(key, recursive)
+	if rev == 0 {
+		w.startRev = b.rev + 1
+		for _, k := range b.keys {
+			if w.matches(k) {
+				w.add(&kvEvent{kv: b.kvs[k]})
+			}
+		}
+	} else {
+		w.startRev = rev + 1
+		if w.startRev <= b.compactRev {
+			return nil, errCompacted
+		}
+		for _, e := range b.history {
+			w.replay(e)
+		}
+	}
+	w.registered()
+	b.watchers[w] = struct{}{}
+	return w, nil
+}
+
+// stopWatch unregisters w.
+func (b *Backend) stopWatch(w *kvWatcher) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.watchers, w)
+}
+
+// prefixEnd returns the end of the range of keys starting with prefix.
+func prefixEnd(prefix string) string {
+	end := []byte(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return string(end[:i+1])
+		}
+	}
+	// the prefix consists of 0xff bytes only, range to the end of the keyspace
+	return rangeToEnd
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package embedded
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+
+	corev1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/core/v1"
+	metav1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage"
+	storagetesting "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/testing"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/value"
+)
+
+func testCreate(ctx context.Context, t *testing.T, s *store, key string, obj *corev1.Pod) *corev1.Pod {
+	out := &corev1.Pod{}
+	if err := s.Create(ctx, key, obj, out, 0); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	return out
+}
+
+func TestRestart(t *testing.T) {
+	dir, err := ioutil.TempDir("", "embedded-storage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ctx := context.Background()
+
+	b, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.snapshotCount = 2
+	s := newStore(b, true, storagetesting.Codec, "", value.IdentityTransformer)
+	var stored []*corev1.Pod
+	for _, name := range []string{"a", "b", "c"} {
+		stored = append(stored, testCreate(ctx, t, s, "/pods/"+name, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name}}))
+	}
+	rev := b.Revision()
+	// simulate a crash: the last record is only in the write-ahead log
+	b.wal.close()
+
+	b, err = Open(dir)
+	if err != nil {
+		t.Fatalf("failed to reopen: %v", err)
+	}
+	defer b.Close()
+	if b.Revision() != rev {
+		t.Errorf("revision want=%d, get=%d", rev, b.Revision())
+	}
+	s = newStore(b, true, storagetesting.Codec, "", value.IdentityTransformer)
+	out := &corev1.PodList{}
+	if err := s.List(ctx, "/pods", "", storage.Everything, out); err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if !reflect.DeepEqual(out.Items, []corev1.Pod{*stored[0], *stored[1], *stored[2]}) {
+		t.Errorf("unexpected list after restart: %#v", out.Items)
+	}
+	next := testCreate(ctx, t, s, "/pods/d", &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "d"}})
+	if next.ResourceVersion != strconv.FormatInt(rev+1, 10) {
+		t.Errorf("resource version after restart want=%d, get=%s", rev+1, next.ResourceVersion)
+	}
+}
+
+func TestRestartWithTornRecord(t *testing.T) {
+	dir, err := ioutil.TempDir("", "embedded-storage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ctx := context.Background()
+
+	b, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := newStore(b, true, storagetesting.Codec, "", value.IdentityTransformer)
+	stored := testCreate(ctx, t, s, "/pods/a", &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "a"}})
+	b.wal.close()
+
+	// simulate a crash in the middle of appending a record
+	f, err := os.OpenFile(filepath.Join(dir, walFileName), os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte{0, 0, 1, 0, 42}); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	b, err = Open(dir)
+	if err != nil {
+		t.Fatalf("failed to reopen: %v", err)
+	}
+	defer b.Close()
+	s = newStore(b, true, storagetesting.Codec, "", value.IdentityTransformer)
+	out := &corev1.Pod{}
+	if err := s.Get(ctx, "/pods/a", "", out, false); err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if !reflect.DeepEqual(stored, out) {
+		t.Errorf("pod want=%#v, get=%#v", stored, out)
+	}
+	testCreate(ctx, t, s, "/pods/b", &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "b"}})
+}
+
+func TestRestartAfterTransaction(t *testing.T) {
+	dir, err := ioutil.TempDir("", "embedded-storage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ctx := context.Background()
+
+	b, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := newStore(b, true, storagetesting.Codec, "", value.IdentityTransformer)
+	testCreate(ctx, t, s, "/pods/a", &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "a"}})
+	ops := []storage.TxnOp{
+		{Type: storage.TxnCreate, Key: "/pods/b", Obj: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "b"}}, Out: &corev1.Pod{}},
+		{Type: storage.TxnDelete, Key: "/pods/a", Out: &corev1.Pod{}},
+	}
+	if err := s.Transact(ctx, ops); err != nil {
+		t.Fatalf("Transact failed: %v", err)
+	}
+	rev := b.Revision()
+	// simulate a crash: the transaction is only in the write-ahead log
+	b.wal.close()
+
+	b, err = Open(dir)
+	if err != nil {
+		t.Fatalf("failed to reopen: %v", err)
+	}
+	defer b.Close()
+	if b.Revision() != rev {
+		t.Errorf("revision want=%d, get=%d", rev, b.Revision())
+	}
+	s = newStore(b, true, storagetesting.Codec, "", value.IdentityTransformer)
+	out := &corev1.PodList{}
+	if err := s.List(ctx, "/pods", "", storage.Everything, out); err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if !reflect.DeepEqual(out.Items, []corev1.Pod{*ops[0].Out.(*corev1.Pod)}) {
+		t.Errorf("unexpected list after restart: %#v", out.Items)
+	}
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package embedded
+
+import (
+	"context"
+	"time"
+
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/klog"
+)
+
+// StartCompactor starts a compactor in the background that discards the history
+// of b that is older than interval, mirroring what the etcd3 compactor does for
+// etcd. Watches and paginated lists from compacted revisions fail with
+// "too old resource version" errors. If interval is 0, no compaction is done.
+func StartCompactor(ctx context.Context, b *Backend, interval time.Duration) {
+	if interval != 0 {
+		go compactor(ctx, b, interval)
+	}
+}
+
+// compactor compacts, at every interval, the history up to the revision that
+// was current one interval earlier. The first compaction happens after two
+// intervals.
+func compactor(ctx context.Context, b *Backend, interval time.Duration) {
+	var rev int64
+	for {
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return
+		}
+
+		curRev := b.Revision()
+		if rev != 0 {
+			if err := b.compact(rev); err != nil && err != errCompacted {
+				klog.Errorf("embedded storage: compact to revision %d failed: %v", rev, err)
+			} else if err == nil {
+				klog.V(4).Infof("embedded storage: compacted rev (%d)", rev)
+			}
+		}
+		rev = curRev
+	}
+}
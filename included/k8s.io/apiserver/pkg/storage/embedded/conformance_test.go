@@ -0,0 +1,67 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package embedded
+
+import (
+	"io/ioutil"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage"
+	storagetesting "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/testing"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/value"
+)
+
+func compactFunc(t *testing.T, b *Backend) func(string) {
+	return func(resourceVersion string) {
+		rev, err := strconv.ParseInt(resourceVersion, 10, 64)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := b.compact(rev); err != nil {
+			t.Fatalf("Unable to compact, %v", err)
+		}
+	}
+}
+
+func TestConformance(t *testing.T) {
+	storagetesting.RunConformanceTests(t, func(t *testing.T) (storage.Interface, func(string), func()) {
+		dir, err := ioutil.TempDir("", "embedded-storage")
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := Open(dir)
+		if err != nil {
+			os.RemoveAll(dir)
+			t.Fatal(err)
+		}
+		s := newStore(b, true, storagetesting.Codec, "", value.IdentityTransformer)
+		return s, compactFunc(t, b), func() {
+			b.Close()
+			os.RemoveAll(dir)
+		}
+	})
+}
+
+func TestMemoryConformance(t *testing.T) {
+	storagetesting.RunConformanceTests(t, func(t *testing.T) (storage.Interface, func(string), func()) {
+		b := NewMemory()
+		s := newStore(b, true, storagetesting.Codec, "", value.IdentityTransformer)
+		return s, compactFunc(t, b), func() { b.Close() }
+	})
+}
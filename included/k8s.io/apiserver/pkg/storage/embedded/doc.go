@@ -0,0 +1,22 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package embedded implements storage.Interface on top of an in-process,
+// revisioned keyspace that is persisted to a local write-ahead log and
+// periodic snapshot files, so that a server can run without an external
+// etcd cluster. The same keyspace can also be kept in memory only, for
+// ephemeral servers and hermetic tests.
+package embedded // import "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/embedded"
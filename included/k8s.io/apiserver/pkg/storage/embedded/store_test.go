@@ -0,0 +1,391 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package embedded
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	corev1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/core/v1"
+	apierrors "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/api/errors"
+	metav1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/fields"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/labels"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/util/wait"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/watch"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage"
+	storagetesting "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/testing"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/value"
+)
+
+func testSetup(t *testing.T) (context.Context, *store, *Backend, func()) {
+	dir, err := ioutil.TempDir("", "embedded-storage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Open(dir)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	s := newStore(b, true, storagetesting.Codec, "", value.IdentityTransformer)
+	return context.Background(), s, b, func() {
+		b.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func testCheckResult(t *testing.T, w watch.Interface, expectType watch.EventType, expectObj runtime.Object) {
+	select {
+	case res := <-w.ResultChan():
+		if res.Type != expectType {
+			t.Fatalf("event type want=%v, get=%v (%#v)", expectType, res.Type, res.Object)
+		}
+		if expectObj != nil && !reflect.DeepEqual(expectObj, res.Object) {
+			t.Errorf("obj want=\n%#v\nget=\n%#v", expectObj, res.Object)
+		}
+	case <-time.After(wait.ForeverTestTimeout):
+		t.Fatalf("time out after waiting %v on ResultChan", wait.ForeverTestTimeout)
+	}
+}
+
+func testCheckExpired(t *testing.T, w watch.Interface) {
+	select {
+	case res := <-w.ResultChan():
+		status, ok := res.Object.(*metav1.Status)
+		if res.Type != watch.Error || !ok || status.Code != 410 {
+			t.Errorf("expected a 410 error event, got %#v", res)
+		}
+	case <-time.After(wait.ForeverTestTimeout):
+		t.Fatalf("time out after waiting %v on ResultChan", wait.ForeverTestTimeout)
+	}
+}
+
+func TestCreate(t *testing.T) {
+	ctx, s, _, cleanup := testSetup(t)
+	defer cleanup()
+
+	obj := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo", SelfLink: "testlink"}}
+	out := testCreate(ctx, t, s, "/testkey", obj)
+	if out.Name != "foo" {
+		t.Errorf("pod name want=foo, get=%s", out.Name)
+	}
+	if out.ResourceVersion == "" {
+		t.Errorf("output should have non-empty resource version")
+	}
+	if out.SelfLink != "" {
+		t.Errorf("output should have empty self link")
+	}
+
+	err := s.Create(ctx, "/testkey", &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo"}}, nil, 0)
+	if !storage.IsNodeExist(err) {
+		t.Errorf("expecting key exists error, but get: %v", err)
+	}
+
+	got := &corev1.Pod{}
+	if err := s.Get(ctx, "/testkey", "", got, false); err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if !reflect.DeepEqual(out, got) {
+		t.Errorf("pod want=%#v, get=%#v", out, got)
+	}
+	if err := s.Get(ctx, "/non-existing", "", got, false); !storage.IsNotFound(err) {
+		t.Errorf("expecting not found error, but get: %v", err)
+	}
+}
+
+func TestCreateWithTTL(t *testing.T) {
+	ctx, s, _, cleanup := testSetup(t)
+	defer cleanup()
+
+	out := &corev1.Pod{}
+	if err := s.Create(ctx, "/somekey", &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo"}}, out, 1); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	w, err := s.Watch(ctx, "/somekey", out.ResourceVersion, storage.Everything)
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+	defer w.Stop()
+	testCheckResult(t, w, watch.Deleted, nil)
+}
+
+func TestConditionalDelete(t *testing.T) {
+	ctx, s, _, cleanup := testSetup(t)
+	defer cleanup()
+	stored := testCreate(ctx, t, s, "/testkey", &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo", UID: "A"}})
+
+	out := &corev1.Pod{}
+	err := s.Delete(ctx, "/testkey", out, storage.NewUIDPreconditions("B"), storage.ValidateAllObjectFunc)
+	if !storage.IsInvalidObj(err) {
+		t.Fatalf("expecting invalid UID error, but get: %v", err)
+	}
+	if err := s.Delete(ctx, "/testkey", out, storage.NewUIDPreconditions("A"), storage.ValidateAllObjectFunc); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if !reflect.DeepEqual(stored, out) {
+		t.Errorf("pod want=%#v, get=%#v", stored, out)
+	}
+	if err := s.Delete(ctx, "/testkey", out, nil, storage.ValidateAllObjectFunc); !storage.IsNotFound(err) {
+		t.Errorf("expecting not found error, but get: %v", err)
+	}
+}
+
+func TestGuaranteedUpdate(t *testing.T) {
+	ctx, s, _, cleanup := testSetup(t)
+	defer cleanup()
+	stored := testCreate(ctx, t, s, "/testkey", &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo", UID: "A"}})
+
+	update := func(node string) storage.UpdateFunc {
+		return storage.SimpleUpdate(func(obj runtime.Object) (runtime.Object, error) {
+			pod := obj.(*corev1.Pod)
+			pod.Spec.NodeName = node
+			return pod, nil
+		})
+	}
+
+	out := &corev1.Pod{}
+	if err := s.GuaranteedUpdate(ctx, "/testkey", out, false, storage.NewUIDPreconditions("B"), update("node1")); !storage.IsInvalidObj(err) {
+		t.Fatalf("expecting invalid UID error, but get: %v", err)
+	}
+	if err := s.GuaranteedUpdate(ctx, "/testkey", out, false, storage.NewUIDPreconditions("A"), update("node1")); err != nil {
+		t.Fatalf("GuaranteedUpdate failed: %v", err)
+	}
+	if out.Spec.NodeName != "node1" || out.ResourceVersion == stored.ResourceVersion {
+		t.Errorf("unexpected update result: %#v", out)
+	}
+
+	// an update that does not change the object must not create a new revision
+	unchanged := &corev1.Pod{}
+	if err := s.GuaranteedUpdate(ctx, "/testkey", unchanged, false, nil, update("node1")); err != nil {
+		t.Fatalf("GuaranteedUpdate failed: %v", err)
+	}
+	if unchanged.ResourceVersion != out.ResourceVersion {
+		t.Errorf("no-op update changed the resource version from %s to %s", out.ResourceVersion, unchanged.ResourceVersion)
+	}
+
+	// a stale suggestion must be refreshed instead of overwriting the newer object
+	if err := s.GuaranteedUpdate(ctx, "/testkey", out, false, nil, update("node2"), stored); err != nil {
+		t.Fatalf("GuaranteedUpdate failed: %v", err)
+	}
+	if out.Spec.NodeName != "node2" {
+		t.Errorf("unexpected update result: %#v", out)
+	}
+
+	if err := s.GuaranteedUpdate(ctx, "/non-existing", out, false, nil, update("node1")); !storage.IsNotFound(err) {
+		t.Errorf("expecting not found error, but get: %v", err)
+	}
+}
+
+func TestList(t *testing.T) {
+	ctx, s, _, cleanup := testSetup(t)
+	defer cleanup()
+
+	var preset []*corev1.Pod
+	for _, name := range []string{"a", "b", "c"} {
+		preset = append(preset, testCreate(ctx, t, s, "/pods/"+name, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name}}))
+	}
+	testCreate(ctx, t, s, "/podsx/d", &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "d"}})
+	listRV := preset[2].ResourceVersion
+
+	// changes after the first page must not be visible in later pages
+	if err := s.Delete(ctx, "/pods/b", &corev1.Pod{}, nil, storage.ValidateAllObjectFunc); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+
+	pred := func(limit int64, continueValue string) storage.SelectionPredicate {
+		return storage.SelectionPredicate{
+			Label:    labels.Everything(),
+			Field:    fields.Everything(),
+			Limit:    limit,
+			Continue: continueValue,
+		}
+	}
+
+	out := &corev1.PodList{}
+	if err := s.List(ctx, "/pods", listRV, pred(1, ""), out); err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(out.Items) != 1 || !reflect.DeepEqual(&out.Items[0], preset[0]) {
+		t.Fatalf("unexpected first page: %#v", out.Items)
+	}
+	if out.RemainingItemCount == nil || *out.RemainingItemCount != 2 {
+		t.Errorf("remaining item count want=2, get=%v", out.RemainingItemCount)
+	}
+	if len(out.Continue) == 0 {
+		t.Fatalf("no continuation token set")
+	}
+
+	out2 := &corev1.PodList{}
+	if err := s.List(ctx, "/pods", "", pred(0, out.Continue), out2); err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if !reflect.DeepEqual(out2.Items, []corev1.Pod{*preset[1], *preset[2]}) {
+		t.Errorf("unexpected second page: %#v", out2.Items)
+	}
+	if out2.ResourceVersion != listRV {
+		t.Errorf("list resource version want=%s, get=%s", listRV, out2.ResourceVersion)
+	}
+
+	current := &corev1.PodList{}
+	if err := s.List(ctx, "/pods", "", storage.Everything, current); err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if !reflect.DeepEqual(current.Items, []corev1.Pod{*preset[0], *preset[2]}) {
+		t.Errorf("unexpected current list: %#v", current.Items)
+	}
+	// like etcd3, Count counts every key that has the given prefix
+	if count, err := s.Count("/pods"); err != nil || count != 3 {
+		t.Errorf("count want=3, get=%d (%v)", count, err)
+	}
+}
+
+func TestListKeys(t *testing.T) {
+	b := NewMemory()
+	defer b.Close()
+	ctx, s := context.Background(), newStore(b, true, storagetesting.Codec, "/registry", value.IdentityTransformer)
+
+	for _, key := range []string{"/pods/b", "/pods/a", "/podsx/c"} {
+		testCreate(ctx, t, s, key, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: key}})
+	}
+	// the keys are relative to the prefix of the store, in order, and only
+	// the ones under the key are listed
+	keys, err := s.ListKeys(ctx, "/pods")
+	if err != nil {
+		t.Fatalf("ListKeys failed: %v", err)
+	}
+	if expected := []string{"/pods/a", "/pods/b"}; !reflect.DeepEqual(keys, expected) {
+		t.Errorf("keys want=%v, get=%v", expected, keys)
+	}
+}
+
+func TestCompaction(t *testing.T) {
+	ctx, s, b, cleanup := testSetup(t)
+	defer cleanup()
+
+	first := testCreate(ctx, t, s, "/pods/a", &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "a"}})
+	testCreate(ctx, t, s, "/pods/b", &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "b"}})
+	second := testCreate(ctx, t, s, "/pods/c", &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "c"}})
+
+	rv, _ := strconv.ParseInt(second.ResourceVersion, 10, 64)
+	if err := b.compact(rv); err != nil {
+		t.Fatalf("compact failed: %v", err)
+	}
+	if err := b.compact(rv); err != errCompacted {
+		t.Errorf("expected %v compacting twice, got %v", errCompacted, err)
+	}
+
+	w, err := s.WatchList(ctx, "/pods", first.ResourceVersion, storage.Everything)
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+	defer w.Stop()
+	testCheckExpired(t, w)
+
+	err = s.List(ctx, "/pods", first.ResourceVersion, storage.SelectionPredicate{Label: labels.Everything(), Field: fields.Everything(), Limit: 1}, &corev1.PodList{})
+	if !apierrors.IsResourceExpired(err) {
+		t.Errorf("expected resource expired error, got %v", err)
+	}
+
+	// the compacted revision itself can still be watched
+	w2, err := s.WatchList(ctx, "/pods", second.ResourceVersion, storage.Everything)
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+	defer w2.Stop()
+	third := testCreate(ctx, t, s, "/pods/d", &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "d"}})
+	testCheckResult(t, w2, watch.Added, third)
+}
+
+func TestWatchFromOldRevision(t *testing.T) {
+	ctx, s, _, cleanup := testSetup(t)
+	defer cleanup()
+
+	created := testCreate(ctx, t, s, "/pods/a", &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "a"}})
+	updated := &corev1.Pod{}
+	err := s.GuaranteedUpdate(ctx, "/pods/a", updated, false, nil, storage.SimpleUpdate(func(obj runtime.Object) (runtime.Object, error) {
+		pod := obj.(*corev1.Pod)
+		pod.Labels = map[string]string{"updated": "true"}
+		return pod, nil
+	}))
+	if err != nil {
+		t.Fatalf("GuaranteedUpdate failed: %v", err)
+	}
+
+	w, err := s.WatchList(ctx, "/pods", created.ResourceVersion, storage.Everything)
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+	defer w.Stop()
+	testCheckResult(t, w, watch.Modified, updated)
+
+	if err := s.Delete(ctx, "/pods/a", &corev1.Pod{}, nil, storage.ValidateAllObjectFunc); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	testCheckResult(t, w, watch.Deleted, nil)
+}
+
+func TestWatchAfterRestart(t *testing.T) {
+	dir, err := ioutil.TempDir("", "embedded-storage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ctx := context.Background()
+
+	b, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.snapshotCount = 2
+	s := newStore(b, true, storagetesting.Codec, "", value.IdentityTransformer)
+	var stored []*corev1.Pod
+	for _, name := range []string{"a", "b", "c"} {
+		stored = append(stored, testCreate(ctx, t, s, "/pods/"+name, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name}}))
+	}
+	// simulate a crash: the last record is only in the write-ahead log
+	b.wal.close()
+
+	b, err = Open(dir)
+	if err != nil {
+		t.Fatalf("failed to reopen: %v", err)
+	}
+	defer b.Close()
+	s = newStore(b, true, storagetesting.Codec, "", value.IdentityTransformer)
+
+	// the history before the snapshot is lost, the records logged after it
+	// are replayed
+	w, err := s.WatchList(ctx, "/pods", stored[0].ResourceVersion, storage.Everything)
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+	defer w.Stop()
+	testCheckExpired(t, w)
+
+	w2, err := s.WatchList(ctx, "/pods", stored[1].ResourceVersion, storage.Everything)
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+	defer w2.Stop()
+	testCheckResult(t, w2, watch.Added, stored[2])
+}
@@ -0,0 +1,212 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package embedded
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/storeutil"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/klog"
+	utiltrace "github.com/aaron-prindle/krmapiserver/included/k8s.io/utils/trace"
+)
+
+// txnOpState is an operation of a transaction resolved against the store the
+// key belongs to.
+type txnOpState struct {
+	storage.TxnOp
+	store *store
+	// key is the key including the path prefix of store.
+	key string
+	// data is the encoded object of creations and updates before it is
+	// transformed.
+	data []byte
+}
+
+// Transact implements storage.Interface.Transact.
+func (s *store) Transact(ctx context.Context, ops []storage.TxnOp) error {
+	if len(ops) == 0 {
+		return nil
+	}
+	trace := utiltrace.New(fmt.Sprintf("Transact embedded: %d operations", len(ops)))
+	defer trace.LogIfLong(500 * time.Millisecond)
+
+	states := make([]*txnOpState, len(ops))
+	seen := make(map[string]bool, len(ops))
+	for i, op := range ops {
+		st, err := s.resolveTxnOp(op)
+		if err != nil {
+			return storage.NewTxnOpError(i, err)
+		}
+		if seen[st.key] {
+			return storage.NewTxnOpError(i, fmt.Errorf("duplicate key %s in transaction", st.key))
+		}
+		seen[st.key] = true
+		states[i] = st
+	}
+
+	current := make([]*keyValue, len(states))
+	for i, st := range states {
+		kv, _, err := s.backend.get(st.key, 0)
+		if err != nil {
+			return err
+		}
+		current[i] = kv
+	}
+	trace.Step("Operations resolved")
+
+	for attempt := 1; ; attempt++ {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		txns := make([]keyTxn, len(states))
+		for i, st := range states {
+			txn, err := st.store.prepareTxnOp(st, current[i])
+			if err != nil {
+				return storage.NewTxnOpError(i, err)
+			}
+			txns[i] = txn
+		}
+		trace.Step("Transaction prepared")
+
+		succeeded, rev, kvs, err := s.backend.multiTxn(txns)
+		if err != nil {
+			return err
+		}
+		trace.Step("Transaction committed")
+		if !succeeded {
+			if attempt == storage.MaxTxnAttempts {
+				return conflictError(states, current, kvs)
+			}
+			current = kvs
+			klog.V(4).Infof("transaction of %d operations failed because of a conflict, going to retry", len(states))
+			continue
+		}
+
+		for _, st := range states {
+			if st.Type == storage.TxnDelete {
+				// Out already holds the deleted object.
+				continue
+			}
+			if err := storeutil.Decode(st.store.codec, st.store.versioner, st.data, st.Out, rev); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// conflictError returns the resource version conflict of the first operation
+// whose key changed between the reads before and after a failed commit.
+func conflictError(states []*txnOpState, before, after []*keyValue) error {
+	for i, st := range states {
+		var modRevisionBefore, modRevisionAfter int64
+		if before[i] != nil {
+			modRevisionBefore = before[i].ModRevision
+		}
+		if after[i] != nil {
+			modRevisionAfter = after[i].ModRevision
+		}
+		if modRevisionBefore != modRevisionAfter {
+			return storage.NewTxnOpError(i, storage.NewResourceVersionConflictsError(st.key, modRevisionAfter))
+		}
+	}
+	return storage.NewResourceVersionConflictsError(states[0].key, 0)
+}
+
+// resolveTxnOp resolves op against the store its key belongs to and encodes
+// the object of creations and updates.
+func (s *store) resolveTxnOp(op storage.TxnOp) (*txnOpState, error) {
+	st := &txnOpState{TxnOp: op, store: s}
+	if op.Storage != nil {
+		other, ok := storage.Unwrap(op.Storage).(*store)
+		if !ok || other.backend != s.backend {
+			return nil, fmt.Errorf("the storage of %s is not served by the same embedded backend", op.Key)
+		}
+		st.store = other
+	}
+	if op.Out == nil {
+		return nil, fmt.Errorf("no output object for %s", op.Key)
+	}
+	st.key = path.Join(st.store.pathPrefix, op.Key)
+
+	switch op.Type {
+	case storage.TxnCreate:
+		if version, err := st.store.versioner.ObjectResourceVersion(op.Obj); err == nil && version != 0 {
+			return nil, fmt.Errorf("resourceVersion should not be set on objects to be created")
+		}
+		fallthrough
+	case storage.TxnUpdate:
+		if err := st.store.versioner.PrepareObjectForStorage(op.Obj); err != nil {
+			return nil, fmt.Errorf("PrepareObjectForStorage failed: %v", err)
+		}
+		data, err := runtime.Encode(st.store.codec, op.Obj)
+		if err != nil {
+			return nil, err
+		}
+		st.data = data
+	case storage.TxnDelete:
+	default:
+		return nil, fmt.Errorf("unknown operation type %q", op.Type)
+	}
+	return st, nil
+}
+
+// prepareTxnOp checks st against kv, the current version of its key or nil
+// if the key doesn't exist, and returns the guarded mutation of the key.
+func (s *store) prepareTxnOp(st *txnOpState, kv *keyValue) (keyTxn, error) {
+	if st.Type == storage.TxnCreate {
+		if kv != nil {
+			return keyTxn{}, storage.NewKeyExistsError(st.key, 0)
+		}
+		newData, err := s.transformer.TransformToStorage(st.data, storeutil.AuthenticatedDataString(st.key))
+		if err != nil {
+			return keyTxn{}, storage.NewInternalError(err.Error())
+		}
+		return keyTxn{key: st.key, op: txnOp{value: newData}}, nil
+	}
+
+	if kv == nil {
+		return keyTxn{}, storage.NewKeyNotFoundError(st.key, 0)
+	}
+	// The current object is decoded into Out to check the preconditions,
+	// which leaves the deleted object in Out for deletions.
+	data, _, err := s.transformer.TransformFromStorage(kv.Value, storeutil.AuthenticatedDataString(st.key))
+	if err != nil {
+		return keyTxn{}, storage.NewInternalError(err.Error())
+	}
+	if err := storeutil.Decode(s.codec, s.versioner, data, st.Out, kv.ModRevision); err != nil {
+		return keyTxn{}, err
+	}
+	if err := st.Preconditions.Check(st.key, st.Out); err != nil {
+		return keyTxn{}, err
+	}
+	if st.Type == storage.TxnDelete {
+		return keyTxn{key: st.key, expectedModRev: kv.ModRevision, op: txnOp{delete: true}}, nil
+	}
+	newData, err := s.transformer.TransformToStorage(st.data, storeutil.AuthenticatedDataString(st.key))
+	if err != nil {
+		return keyTxn{}, storage.NewInternalError(err.Error())
+	}
+	return keyTxn{key: st.key, expectedModRev: kv.ModRevision, op: txnOp{value: newData}}, nil
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package embedded
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	corev1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/core/v1"
+	metav1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage"
+	storagetesting "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/testing"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/value"
+)
+
+// conflictingTransformer updates the pod at key through other whenever a value
+// is transformed to storage, so that every commit of a transaction conflicts.
+type conflictingTransformer struct {
+	value.Transformer
+	other      storage.Interface
+	key        string
+	transforms int
+	// onTransform is called after each update.
+	onTransform func(transforms int)
+}
+
+func (c *conflictingTransformer) TransformToStorage(data []byte, dataCtx value.Context) ([]byte, error) {
+	c.transforms++
+	err := c.other.GuaranteedUpdate(context.Background(), c.key, &corev1.Pod{}, false, nil, storage.SimpleUpdate(func(obj runtime.Object) (runtime.Object, error) {
+		pod := obj.(*corev1.Pod)
+		pod.Labels = map[string]string{"update": strconv.Itoa(c.transforms)}
+		return pod, nil
+	}))
+	if err != nil {
+		return nil, err
+	}
+	if c.onTransform != nil {
+		c.onTransform(c.transforms)
+	}
+	return c.Transformer.TransformToStorage(data, dataCtx)
+}
+
+func TestTransactConflicts(t *testing.T) {
+	b := NewMemory()
+	defer b.Close()
+	other := newStore(b, true, storagetesting.Codec, "", value.IdentityTransformer)
+	testCreate(context.Background(), t, other, "/pods/a", &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "a"}})
+
+	transformer := &conflictingTransformer{Transformer: value.IdentityTransformer, other: other, key: "/pods/a"}
+	s := newStore(b, true, storagetesting.Codec, "", transformer)
+	update := func(ctx context.Context) error {
+		current := &corev1.Pod{}
+		if err := s.Get(ctx, "/pods/a", "", current, false); err != nil {
+			t.Fatal(err)
+		}
+		current.ResourceVersion = ""
+		current.Spec.NodeName = "node1"
+		return s.Transact(ctx, []storage.TxnOp{{Type: storage.TxnUpdate, Key: "/pods/a", Obj: current, Out: &corev1.Pod{}}})
+	}
+
+	// a transaction that keeps conflicting gives up
+	err := update(context.Background())
+	if opErr, ok := err.(*storage.TxnOpError); !ok || opErr.Index != 0 || !storage.IsConflict(opErr.Err) {
+		t.Errorf("expected a conflict of operation 0, got %v", err)
+	}
+	if transformer.transforms != storage.MaxTxnAttempts {
+		t.Errorf("expected %d attempts, got %d", storage.MaxTxnAttempts, transformer.transforms)
+	}
+
+	// and stops retrying once its context is done
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	transformer.transforms = 0
+	transformer.onTransform = func(transforms int) {
+		if transforms == 2 {
+			cancel()
+		}
+	}
+	if err := update(ctx); err != context.Canceled {
+		t.Errorf("expected the context error, got %v", err)
+	}
+	if transformer.transforms != 2 {
+		t.Errorf("expected 2 attempts, got %d", transformer.transforms)
+	}
+}
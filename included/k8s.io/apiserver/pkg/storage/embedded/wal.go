@@ -0,0 +1,277 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package embedded
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/klog"
+)
+
+const (
+	walFileName      = "wal"
+	snapshotFileName = "snapshot"
+
+	// frameHeaderSize is the size of the length and checksum preceding every
+	// record in the log.
+	frameHeaderSize = 8
+	// maxRecordSize bounds the size of a single record to detect corrupted
+	// length fields before allocating for them.
+	maxRecordSize = 256 * 1024 * 1024
+)
+
+var (
+	crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+	errChecksumMismatch = errors.New("record checksum mismatch")
+)
+
+// walRecord is a single committed revision as stored in the write-ahead log.
+// Most revisions change a single key, the changes to further keys committed in
+// the same revision by a transaction are held in Txn.
+type walRecord struct {
+	Revision int64 `json:"rev"`
+	walChange
+	Txn []walChange `json:"txn,omitempty"`
+}
+
+// walChange is the change of a single key in a walRecord.
+type walChange struct {
+	Delete bool      `json:"delete,omitempty"`
+	KV     *keyValue `json:"kv"`
+}
+
+// changes returns all changes of the record in the order they were made.
+func (r *walRecord) changes() []walChange {
+	return append([]walChange{r.walChange}, r.Txn...)
+}
+
+// snapshot is the full state of the keyspace at a revision.
+type snapshot struct {
+	Revision int64       `json:"revision"`
+	KVs      []*keyValue `json:"kvs"`
+}
+
+// wal is an append-only log of the changes committed since the last snapshot.
+// Every record is framed by its length and a CRC32-C checksum so that a
+// record torn by a crash can be detected and discarded on replay.
+type wal struct {
+	dir string
+	f   *os.File
+	// records is the number of records in the log.
+	records int
+}
+
+// openWAL loads the snapshot and the write-ahead log in dir, calling restore
+// with the snapshot first and with every logged record after it, and returns
+// the log ready to be appended to.
+func openWAL(dir string, restore func(*snapshot, *walRecord)) (*wal, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create embedded storage directory: %v", err)
+	}
+	snap, err := readSnapshot(filepath.Join(dir, snapshotFileName))
+	if err != nil {
+		return nil, err
+	}
+	if snap != nil {
+		restore(snap, nil)
+	}
+
+	f, err := os.OpenFile(filepath.Join(dir, walFileName), os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open write-ahead log: %v", err)
+	}
+	w := &wal{dir: dir, f: f}
+	if err := w.replay(restore); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// replay reads all records from the log. A record at the end of the log that
+// was only partially written is truncated away, any other corruption is
+// reported as an error.
+func (w *wal) replay(restore func(*snapshot, *walRecord)) error {
+	info, err := w.f.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat write-ahead log: %v", err)
+	}
+	r := bufio.NewReader(w.f)
+	var offset int64
+	for {
+		rec, n, err := readRecord(r)
+		if err == io.EOF {
+			break
+		}
+		if err == io.ErrUnexpectedEOF || (err == errChecksumMismatch && offset+n == info.Size()) {
+			klog.Warningf("embedded storage: discarding incomplete record at offset %d of %s", offset, w.f.Name())
+			if err := w.f.Truncate(offset); err != nil {
+				return fmt.Errorf("failed to truncate write-ahead log: %v", err)
+			}
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read write-ahead log at offset %d: %v", offset, err)
+		}
+		restore(nil, rec)
+		w.records++
+		offset += n
+	}
+	if _, err := w.f.Seek(offset, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek write-ahead log: %v", err)
+	}
+	return nil
+}
+
+// readRecord reads a single framed record and returns it with its size. The
+// size is also returned for records failing the checksum.
+func readRecord(r io.Reader) (*walRecord, int64, error) {
+	var header [frameHeaderSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, 0, err
+	}
+	size := binary.BigEndian.Uint32(header[:4])
+	if size > maxRecordSize {
+		return nil, 0, fmt.Errorf("record size %d exceeds the maximum of %d", size, maxRecordSize)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, 0, err
+	}
+	if crc32.Checksum(data, crcTable) != binary.BigEndian.Uint32(header[4:]) {
+		return nil, int64(frameHeaderSize + len(data)), errChecksumMismatch
+	}
+	rec := &walRecord{}
+	if err := json.Unmarshal(data, rec); err != nil {
+		return nil, 0, err
+	}
+	for _, change := range rec.changes() {
+		if change.KV == nil {
+			return nil, 0, fmt.Errorf("record for revision %d has no key", rec.Revision)
+		}
+	}
+	return rec, int64(frameHeaderSize + len(data)), nil
+}
+
+// append durably writes rec to the end of the log.
+func (w *wal) append(rec *walRecord) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	frame := make([]byte, frameHeaderSize+len(data))
+	binary.BigEndian.PutUint32(frame[:4], uint32(len(data)))
+	binary.BigEndian.PutUint32(frame[4:8], crc32.Checksum(data, crcTable))
+	copy(frame[frameHeaderSize:], data)
+	if _, err := w.f.Write(frame); err != nil {
+		return err
+	}
+	if err := w.f.Sync(); err != nil {
+		return err
+	}
+	w.records++
+	return nil
+}
+
+// snapshot atomically replaces the snapshot file with snap and truncates the
+// log. A crash between the two steps is harmless because records that are
+// already part of the snapshot are skipped on replay.
+func (w *wal) snapshot(snap *snapshot) error {
+	tmp, err := ioutil.TempFile(w.dir, snapshotFileName+".tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+	bw := bufio.NewWriter(tmp)
+	if err := json.NewEncoder(bw).Encode(snap); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write snapshot: %v", err)
+	}
+	if err := bw.Flush(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write snapshot: %v", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync snapshot: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close snapshot: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(w.dir, snapshotFileName)); err != nil {
+		return fmt.Errorf("failed to install snapshot: %v", err)
+	}
+	if err := syncDir(w.dir); err != nil {
+		return err
+	}
+
+	if err := w.f.Truncate(0); err != nil {
+		return fmt.Errorf("failed to truncate write-ahead log: %v", err)
+	}
+	if _, err := w.f.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek write-ahead log: %v", err)
+	}
+	w.records = 0
+	return w.f.Sync()
+}
+
+func (w *wal) close() error {
+	return w.f.Close()
+}
+
+// readSnapshot reads the snapshot at path, returning nil if there is none.
+func readSnapshot(path string) (*snapshot, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open snapshot: %v", err)
+	}
+	defer f.Close()
+	snap := &snapshot{}
+	if err := json.NewDecoder(bufio.NewReader(f)).Decode(snap); err != nil {
+		return nil, fmt.Errorf("failed to read snapshot %s: %v", path, err)
+	}
+	return snap, nil
+}
+
+// syncDir makes the creation and renaming of files in dir durable.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	if err := d.Sync(); err != nil {
+		return fmt.Errorf("failed to sync %s: %v", dir, err)
+	}
+	return nil
+}
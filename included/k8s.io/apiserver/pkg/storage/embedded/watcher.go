@@ -0,0 +1,384 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package embedded
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	apierrs "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/api/errors"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/watch"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/storeutil"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/value"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/klog"
+)
+
+const (
+	// incomingBufSize is how many committed events a watcher may have queued
+	// before it is terminated as too slow. Unlike the etcd3 watcher, which
+	// blocks and leaves the buffering to etcd, writers never wait for
+	// watchers, so the buffer is larger.
+	incomingBufSize = 1000
+	// We have set a buffer in order to reduce times of context switches.
+	outgoingBufSize = 100
+)
+
+// kvWatcher queues the events of the keys it watches. Events are queued
+// without blocking the writer that committed them, so a slow consumer never
+// holds up writes; a consumer that falls more than incomingBufSize events
+// behind is terminated instead.
+type kvWatcher struct {
+	key       string
+	recursive bool
+	// startRev is the first revision delivered to the watcher.
+	startRev int64
+
+	lock  sync.Mutex
+	queue []*kvEvent
+	// limit is how long queue may grow with committed events. The events
+	// queued when the watcher is registered don't count.
+	limit  int
+	closed bool
+	// overflowed is set if the watcher was closed because it was too slow.
+	overflowed bool
+	// ready is signalled whenever events are queued or the watcher is closed.
+	ready chan struct{}
+}
+
+func newKVWatcher(key string, recursive bool) *kvWatcher {
+	return &kvWatcher{
+		key:       key,
+		recursive: recursive,
+		limit:     incomingBufSize,
+		ready:     make(chan struct{}, 1),
+	}
+}
+
+func (w *kvWatcher) matches(key string) bool {
+	if w.recursive {
+		return strings.HasPrefix(key, w.key)
+	}
+	return key == w.key
+}
+
+// replay queues e if it is a change to a watched key at or after startRev,
+// regardless of the limit. It is used while the watcher is registered.
+func (w *kvWatcher) replay(e *kvEvent) {
+	if e.rev() < w.startRev || !w.matches(e.kv.Key) {
+		return
+	}
+	w.add(e)
+}
+
+// deliver queues the committed event e if it is a change to a watched key at
+// or after startRev. If the queue is full, the watcher is closed instead and
+// deliver returns false.
+func (w *kvWatcher) deliver(e *kvEvent) bool {
+	if e.rev() < w.startRev || !w.matches(e.kv.Key) {
+		return true
+	}
+	w.lock.Lock()
+	if w.closed {
+		w.lock.Unlock()
+		return false
+	}
+	if len(w.queue) >= w.limit {
+		w.closed = true
+		w.overflowed = true
+		w.lock.Unlock()
+		w.signal()
+		return false
+	}
+	w.queue = append(w.queue, e)
+	w.lock.Unlock()
+	w.signal()
+	return true
+}
+
+func (w *kvWatcher) add(e *kvEvent) {
+	w.lock.Lock()
+	w.queue = append(w.queue, e)
+	w.lock.Unlock()
+	w.signal()
+}
+
+// registered allows incomingBufSize committed events on top of the events
+// queued while the watcher was registered.
+func (w *kvWatcher) registered() {
+	w.lock.Lock()
+	w.limit = len(w.queue) + incomingBufSize
+	w.lock.Unlock()
+}
+
+func (w *kvWatcher) close() {
+	w.lock.Lock()
+	w.closed = true
+	w.lock.Unlock()
+	w.signal()
+}
+
+func (w *kvWatcher) signal() {
+	select {
+	case w.ready <- struct{}{}:
+	default:
+	}
+}
+
+// next blocks until events are queued and returns all of them. It returns
+// false if the watcher was closed or stopCh was closed first. The events
+// queued before the watcher was closed are returned first.
+func (w *kvWatcher) next(stopCh <-chan struct{}) ([]*kvEvent, bool) {
+	for {
+		w.lock.Lock()
+		if len(w.queue) > 0 {
+			events := w.queue
+			w.queue = nil
+			w.limit = incomingBufSize
+			w.lock.Unlock()
+			return events, true
+		}
+		closed := w.closed
+		w.lock.Unlock()
+		if closed {
+			return nil, false
+		}
+		select {
+		case <-w.ready:
+		case <-stopCh:
+			return nil, false
+		}
+	}
+}
+
+func (w *kvWatcher) isOverflowed() bool {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.overflowed
+}
+
+type watcher struct {
+	backend     *Backend
+	codec       runtime.Codec
+	versioner   storage.Versioner
+	transformer value.Transformer
+}
+
+// watchChan implements watch.Interface.
+type watchChan struct {
+	watcher      *watcher
+	key          string
+	initialRev   int64
+	recursive    bool
+	internalPred storage.SelectionPredicate
+	ctx          context.Context
+	cancel       context.CancelFunc
+	resultChan   chan watch.Event
+}
+
+func newWatcher(backend *Backend, codec runtime.Codec, versioner storage.Versioner, transformer value.Transformer) *watcher {
+	return &watcher{
+		backend:     backend,
+		codec:       codec,
+		versioner:   versioner,
+		transformer: transformer,
+	}
+}
+
+// Watch watches on a key and returns a watch.Interface that transfers relevant notifications.
+// If rev is zero, it will return the existing object(s) and then start watching from
+// the current revision+1.
+// If rev is non-zero, it will watch events happened after given revision.
+// If recursive is false, it watches on given key.
+// If recursive is true, it watches any children and directories under the key, excluding the root key itself.
+// pred must be non-nil. Only if pred matches the change, it will be returned.
+func (w *watcher) Watch(ctx context.Context, key string, rev int64, recursive bool, pred storage.SelectionPredicate) (watch.Interface, error) {
+	if recursive && !strings.HasSuffix(key, "/") {
+		key += "/"
+	}
+	wc := &watchChan{
+		watcher:      w,
+		key:          key,
+		initialRev:   rev,
+		recursive:    recursive,
+		internalPred: pred,
+		resultChan:   make(chan watch.Event, outgoingBufSize),
+	}
+	if pred.Empty() {
+		// The filter doesn't filter out any object.
+		wc.internalPred = storage.Everything
+	}
+	wc.ctx, wc.cancel = context.WithCancel(ctx)
+	go wc.run()
+	return wc, nil
+}
+
+func (wc *watchChan) run() {
+	defer close(wc.resultChan)
+	defer wc.cancel()
+
+	kw, err := wc.watcher.backend.watch(wc.key, wc.recursive, wc.initialRev)
+	if err != nil {
+		wc.sendError(err)
+		return
+	}
+	defer wc.watcher.backend.stopWatch(kw)
+
+	for {
+		events, ok := kw.next(wc.ctx.Done())
+		if !ok {
+			if kw.isOverflowed() {
+				klog.Warningf("Terminating the watch of %s, it fell more than %d events behind", wc.key, incomingBufSize)
+			}
+			return
+		}
+		for _, e := range events {
+			res, err := wc.transform(e)
+			if err != nil {
+				klog.Errorf("failed to prepare current and previous objects: %v", err)
+				wc.sendError(err)
+				return
+			}
+			if res == nil {
+				continue
+			}
+			if len(wc.resultChan) == outgoingBufSize {
+				klog.V(3).Infof("Fast watcher, slow processing. Number of buffered events: %d."+
+					"Probably caused by slow dispatching events to watchers", outgoingBufSize)
+			}
+			select {
+			case wc.resultChan <- *res:
+			case <-wc.ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+func (wc *watchChan) Stop() {
+	wc.cancel()
+}
+
+func (wc *watchChan) ResultChan() <-chan watch.Event {
+	return wc.resultChan
+}
+
+func (wc *watchChan) filter(obj runtime.Object) bool {
+	if wc.internalPred.Empty() {
+		return true
+	}
+	matched, err := wc.internalPred.Matches(obj)
+	return err == nil && matched
+}
+
+func (wc *watchChan) acceptAll() bool {
+	return wc.internalPred.Empty()
+}
+
+// transform transforms an event into a result for user if not filtered.
+func (wc *watchChan) transform(e *kvEvent) (*watch.Event, error) {
+	curObj, oldObj, err := wc.prepareObjs(e)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case e.isDeleted:
+		if !wc.filter(oldObj) {
+			return nil, nil
+		}
+		return &watch.Event{Type: watch.Deleted, Object: oldObj}, nil
+	case e.prevKV == nil:
+		if !wc.filter(curObj) {
+			return nil, nil
+		}
+		return &watch.Event{Type: watch.Added, Object: curObj}, nil
+	case wc.acceptAll():
+		return &watch.Event{Type: watch.Modified, Object: curObj}, nil
+	}
+	curObjPasses := wc.filter(curObj)
+	oldObjPasses := wc.filter(oldObj)
+	switch {
+	case curObjPasses && oldObjPasses:
+		return &watch.Event{Type: watch.Modified, Object: curObj}, nil
+	case curObjPasses && !oldObjPasses:
+		return &watch.Event{Type: watch.Added, Object: curObj}, nil
+	case !curObjPasses && oldObjPasses:
+		return &watch.Event{Type: watch.Deleted, Object: oldObj}, nil
+	}
+	return nil, nil
+}
+
+func (wc *watchChan) sendError(err error) {
+	err = storeutil.InterpretWatchError(err, isCompacted)
+	if _, ok := err.(apierrs.APIStatus); !ok {
+		err = apierrs.NewInternalError(err)
+	}
+	status := err.(apierrs.APIStatus).Status()
+	// error result is guaranteed to be received by user before closing ResultChan.
+	select {
+	case wc.resultChan <- watch.Event{Type: watch.Error, Object: &status}:
+	case <-wc.ctx.Done(): // user has given up all results
+	}
+}
+
+func (wc *watchChan) prepareObjs(e *kvEvent) (curObj runtime.Object, oldObj runtime.Object, err error) {
+	key := e.kv.Key
+	if !e.isDeleted {
+		data, _, err := wc.watcher.transformer.TransformFromStorage(e.kv.Value, storeutil.AuthenticatedDataString(key))
+		if err != nil {
+			return nil, nil, err
+		}
+		curObj, err = decodeObj(wc.watcher.codec, wc.watcher.versioner, data, e.rev())
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	// We need to decode the previous value, only if this is deletion event or
+	// the underlying filter doesn't accept all objects (otherwise we
+	// know that the filter for previous object will return true and
+	// we need the object only to compute whether it was filtered out
+	// before).
+	if e.prevKV != nil && (e.isDeleted || !wc.acceptAll()) {
+		data, _, err := wc.watcher.transformer.TransformFromStorage(e.prevKV.Value, storeutil.AuthenticatedDataString(key))
+		if err != nil {
+			return nil, nil, err
+		}
+		// Note that this sends the *old* object with the revision for the time at
+		// which it gets deleted.
+		oldObj, err = decodeObj(wc.watcher.codec, wc.watcher.versioner, data, e.rev())
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return curObj, oldObj, nil
+}
+
+func decodeObj(codec runtime.Codec, versioner storage.Versioner, data []byte, rev int64) (runtime.Object, error) {
+	obj, err := runtime.Decode(codec, data)
+	if err != nil {
+		return nil, err
+	}
+	// ensure resource version is set on the object we load from storage
+	if err := versioner.UpdateObject(obj, uint64(rev)); err != nil {
+		return nil, fmt.Errorf("failure to version api object (%d) %#v: %v", rev, obj, err)
+	}
+	return obj, nil
+}
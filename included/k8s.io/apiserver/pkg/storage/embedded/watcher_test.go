@@ -0,0 +1,143 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package embedded
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	v1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/core/v1"
+	metav1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/util/wait"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/watch"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage"
+	storagetesting "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/testing"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/value"
+)
+
+// TestWatchWithoutBookmarks checks that watches that allow bookmarks get the
+// events of the watched objects only, because the embedded storage does not
+// support bookmarks.
+func TestWatchWithoutBookmarks(t *testing.T) {
+	b := NewMemory()
+	defer b.Close()
+	s := newStore(b, true, storagetesting.Codec, "", value.IdentityTransformer)
+	ctx := context.Background()
+
+	out := &v1.Service{}
+	if err := s.Create(ctx, "/services/baz", &v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "baz"}}, out, 0); err != nil {
+		t.Fatal(err)
+	}
+	pred := storage.Everything
+	pred.AllowWatchBookmarks = true
+	w, err := s.WatchList(ctx, "/pods", out.ResourceVersion, pred)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	for _, name := range []string{"foo", "bar"} {
+		if err := s.Create(ctx, "/pods/"+name, &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name}}, nil, 0); err != nil {
+			t.Fatal(err)
+		}
+		// Other keys advance the revision, without events for the watch.
+		if err := s.Create(ctx, "/services/"+name, &v1.Service{ObjectMeta: metav1.ObjectMeta{Name: name}}, nil, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, name := range []string{"foo", "bar"} {
+		select {
+		case event := <-w.ResultChan():
+			if event.Type != watch.Added || event.Object.(*v1.Pod).Name != name {
+				t.Fatalf("expected the creation of %s, got %s %#v", name, event.Type, event.Object)
+			}
+		case <-time.After(wait.ForeverTestTimeout):
+			t.Fatalf("timed out waiting for the creation of %s", name)
+		}
+	}
+	select {
+	case event := <-w.ResultChan():
+		t.Errorf("unexpected event %s %#v", event.Type, event.Object)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+// TestSlowWatcherIsTerminated checks that a watcher that falls too far behind
+// the writes is terminated, after the events it got so far, instead of
+// queueing events without bound.
+func TestSlowWatcherIsTerminated(t *testing.T) {
+	b := NewMemory()
+	defer b.Close()
+	s := newStore(b, true, storagetesting.Codec, "", value.IdentityTransformer)
+	ctx := context.Background()
+
+	out := &v1.Service{}
+	if err := s.Create(ctx, "/services/foo", &v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "foo"}}, out, 0); err != nil {
+		t.Fatal(err)
+	}
+	w, err := s.WatchList(ctx, "/pods", out.ResourceVersion, storage.Everything)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+	// The watcher is registered asynchronously, the events written before
+	// are replayed without limit.
+	if err := wait.PollImmediate(10*time.Millisecond, wait.ForeverTestTimeout, func() (bool, error) {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		return len(b.watchers) == 1, nil
+	}); err != nil {
+		t.Fatalf("the watcher was not registered: %v", err)
+	}
+
+	count := 2 * (incomingBufSize + outgoingBufSize)
+	for i := 0; i < count; i++ {
+		name := strconv.Itoa(i)
+		if err := s.Create(ctx, "/pods/"+name, &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name}}, nil, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	b.mu.Lock()
+	watchers := len(b.watchers)
+	b.mu.Unlock()
+	if watchers != 0 {
+		t.Errorf("expected the slow watcher to be unregistered, got %d watchers", watchers)
+	}
+
+	received := 0
+	timeout := time.After(wait.ForeverTestTimeout)
+	for {
+		select {
+		case event, ok := <-w.ResultChan():
+			if !ok {
+				if received == 0 || received >= count {
+					t.Errorf("expected the watch to end after some of the %d events, got %d", count, received)
+				}
+				return
+			}
+			if name := strconv.Itoa(received); event.Type != watch.Added || event.Object.(*v1.Pod).Name != name {
+				t.Fatalf("expected the creation of %s, got %s %#v", name, event.Type, event.Object)
+			}
+			received++
+		case <-timeout:
+			t.Fatalf("timed out waiting for the watch to end, got %d events", received)
+		}
+	}
+}
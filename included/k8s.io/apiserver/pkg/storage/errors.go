@@ -168,3 +168,21 @@ func NewInternalError(reason string) InternalError {
 func NewInternalErrorf(format string, a ...interface{}) InternalError {
 	return InternalError{fmt.Sprintf(format, a...)}
 }
+
+// TxnOpError is returned by Interface.Transact when an operation of the
+// transaction failed, in which case none of its operations was applied. Err
+// is the error the operation failed with, e.g. a StorageError if the key of a
+// created object exists.
+type TxnOpError struct {
+	// Index is the index of the failed operation in the transaction.
+	Index int
+	Err   error
+}
+
+func (e *TxnOpError) Error() string {
+	return fmt.Sprintf("operation %d of the transaction failed: %v", e.Index, e.Err)
+}
+
+func NewTxnOpError(index int, err error) *TxnOpError {
+	return &TxnOpError{Index: index, Err: err}
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcd3
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/aaron-prindle/krmapiserver/included/github.com/coreos/etcd/clientv3"
+	etcdrpc "github.com/aaron-prindle/krmapiserver/included/github.com/coreos/etcd/etcdserver/api/v3rpc/rpctypes"
+	pb "github.com/aaron-prindle/krmapiserver/included/github.com/coreos/etcd/etcdserver/etcdserverpb"
+	"github.com/aaron-prindle/krmapiserver/included/github.com/coreos/etcd/mvcc/mvccpb"
+	storagetesting "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/testing"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/value"
+)
+
+// historyClient serves the ranges of the keyspace built from the events of
+// history, at the revisions they ask for, and records how they were read.
+type historyClient struct {
+	clientv3.KV
+	current int64
+	history []*clientv3.Event
+	// compacted is the compacted revision.
+	compacted int64
+
+	gets int
+	// valuesRead is true if a Get read the values, or more than one key, of
+	// a range.
+	valuesRead bool
+}
+
+func (c *historyClient) Get(ctx context.Context, key string, opts ...clientv3.OpOption) (*clientv3.GetResponse, error) {
+	op := clientv3.OpGet(key, opts...)
+	c.gets++
+	rev := op.Rev()
+	if rev == 0 {
+		rev = c.current
+	}
+	if rev < c.compacted {
+		return nil, etcdrpc.ErrCompacted
+	}
+	keyspace := map[string]*mvccpb.KeyValue{}
+	for _, e := range c.history {
+		if e.Kv.ModRevision > rev || string(e.Kv.Key) < key || string(e.Kv.Key) >= string(op.RangeBytes()) {
+			continue
+		}
+		if e.Type == clientv3.EventTypeDelete {
+			delete(keyspace, string(e.Kv.Key))
+		} else {
+			keyspace[string(e.Kv.Key)] = e.Kv
+		}
+	}
+	resp := &clientv3.GetResponse{Header: &pb.ResponseHeader{Revision: c.current}, Count: int64(len(keyspace))}
+	if op.IsCountOnly() {
+		return resp, nil
+	}
+	if !op.IsKeysOnly() {
+		c.valuesRead = true
+	}
+	for _, kv := range keyspace {
+		if kv.ModRevision >= op.MinModRev() {
+			resp.Kvs = append(resp.Kvs, &mvccpb.KeyValue{Key: kv.Key, ModRevision: kv.ModRevision})
+		}
+	}
+	if len(resp.Kvs) > 1 {
+		// A limit of 1 is expected.
+		resp.Kvs, resp.More = resp.Kvs[:1], true
+	}
+	return resp, nil
+}
+
+func historyEvent(typ mvccpb.Event_EventType, key string, rev int64) *clientv3.Event {
+	return &clientv3.Event{Type: typ, Kv: &mvccpb.KeyValue{Key: []byte(key), ModRevision: rev}}
+}
+
+func TestChangedSinceReadsNoValues(t *testing.T) {
+	var history []*clientv3.Event
+	for i := int64(1); i <= 100; i++ {
+		history = append(history, historyEvent(clientv3.EventTypePut, fmt.Sprintf("/registry/pods/pod%d", i), i))
+	}
+	history = append(history,
+		historyEvent(clientv3.EventTypePut, "/registry/services/a", 101),
+		// a transaction writing a service and a pod
+		historyEvent(clientv3.EventTypePut, "/registry/services/b", 102),
+		historyEvent(clientv3.EventTypePut, "/registry/pods/pod1", 102),
+		historyEvent(clientv3.EventTypePut, "/registry/podsx/a", 103),
+		historyEvent(clientv3.EventTypeDelete, "/registry/pods/pod2", 104),
+		// a pod created and deleted again
+		historyEvent(clientv3.EventTypePut, "/registry/pods/pod101", 105),
+		historyEvent(clientv3.EventTypeDelete, "/registry/pods/pod101", 106),
+		historyEvent(clientv3.EventTypeDelete, "/registry/services/a", 107),
+	)
+
+	testCases := []struct {
+		name            string
+		current         int64
+		compacted       int64
+		resourceVersion uint64
+		expectChanged   bool
+		expectGets      int
+	}{
+		{name: "unknown resource version", current: 103, resourceVersion: 0, expectChanged: true, expectGets: 1},
+		{name: "current resource version", current: 103, resourceVersion: 103, expectGets: 1},
+		{name: "changes of other resources", current: 101, resourceVersion: 100, expectGets: 3},
+		{name: "changes of a resource with a common prefix", current: 103, resourceVersion: 102, expectGets: 3},
+		{name: "change in a transaction", current: 103, resourceVersion: 101, expectChanged: true, expectGets: 2},
+		{name: "change since", current: 103, resourceVersion: 50, expectChanged: true, expectGets: 2},
+		{name: "deletion since", current: 104, resourceVersion: 103, expectChanged: true, expectGets: 3},
+		{name: "object created and deleted since", current: 107, resourceVersion: 104, expectGets: 3},
+		{name: "compacted", current: 103, compacted: 103, resourceVersion: 102, expectChanged: true, expectGets: 3},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := &historyClient{current: tc.current, history: history, compacted: tc.compacted}
+			s := newStore(&clientv3.Client{KV: client}, true, storagetesting.Codec, "/registry", value.IdentityTransformer)
+			current, changed, err := s.ChangedSince(context.TODO(), "/pods", tc.resourceVersion)
+			if err != nil {
+				t.Fatalf("ChangedSince failed: %v", err)
+			}
+			if current != uint64(tc.current) || changed != tc.expectChanged {
+				t.Errorf("expected changed=%v at %d, got changed=%v at %d", tc.expectChanged, tc.current, changed, current)
+			}
+			if client.gets != tc.expectGets || client.valuesRead {
+				t.Errorf("expected %d Gets reading no values, got %d (values read: %v)", tc.expectGets, client.gets, client.valuesRead)
+			}
+		})
+	}
+}
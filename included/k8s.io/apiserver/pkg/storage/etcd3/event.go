@@ -29,6 +29,9 @@ type event struct {
 	rev       int64
 	isDeleted bool
 	isCreated bool
+	// isProgressNotify is true for progress notifications of etcd, which
+	// only carry the revision etcd has sent all events up to.
+	isProgressNotify bool
 }
 
 // parseKV converts a KeyValue retrieved from an initial sync() listing to a synthetic isCreated event.
@@ -43,6 +46,15 @@ func parseKV(kv *mvccpb.KeyValue) *event {
 	}
 }
 
+// progressNotifyEvent returns a synthetic event of a progress notification of
+// etcd at rev.
+func progressNotifyEvent(rev int64) *event {
+	return &event{
+		rev:              rev,
+		isProgressNotify: true,
+	}
+}
+
 func parseEvent(e *clientv3.Event) (*event, error) {
 	if !e.IsCreate() && e.PrevKv == nil {
 		// If the previous value is nil, error. One example of how this is possible is if the previous value has been compacted already.
@@ -0,0 +1,63 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcd3
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/core/v1"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/watch"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/etcd"
+	storagetesting "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/testing"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/value"
+)
+
+func TestWatchProgressNotify(t *testing.T) {
+	w := newWatcher(nil, storagetesting.Codec, etcd.APIObjectVersioner{}, value.IdentityTransformer)
+	pred := storage.Everything
+	pred.AllowWatchBookmarks = true
+	if wc := w.createWatchChan(context.TODO(), "/", 0, true, pred); wc.progressNotify {
+		t.Errorf("expected no progress notifications of a store without WithBookmarks")
+	}
+
+	s := &store{watcher: w}
+	WithBookmarks(func() runtime.Object { return &v1.Pod{} })(s)
+	pred.AllowWatchBookmarks = false
+	if wc := w.createWatchChan(context.TODO(), "/", 0, true, pred); wc.progressNotify {
+		t.Errorf("expected no progress notifications without bookmarks")
+	}
+
+	pred.AllowWatchBookmarks = true
+	wc := w.createWatchChan(context.TODO(), "/", 0, true, pred)
+	if !wc.progressNotify {
+		t.Fatalf("expected progress notifications with bookmarks")
+	}
+	res := wc.transform(progressNotifyEvent(10))
+	if res == nil || res.Type != watch.Bookmark {
+		t.Fatalf("expected a bookmark, got %#v", res)
+	}
+	pod, ok := res.Object.(*v1.Pod)
+	if !ok {
+		t.Fatalf("expected a bookmark of a pod, got %#v", res.Object)
+	}
+	if pod.ResourceVersion != "10" {
+		t.Errorf("expected the bookmark at the revision of the progress notification, got %q", pod.ResourceVersion)
+	}
+}
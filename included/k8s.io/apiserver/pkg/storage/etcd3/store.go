@@ -19,8 +19,6 @@ package etcd3
 import (
 	"bytes"
 	"context"
-	"encoding/base64"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"path"
@@ -29,6 +27,7 @@ import (
 	"time"
 
 	"github.com/aaron-prindle/krmapiserver/included/github.com/coreos/etcd/clientv3"
+	etcdrpc "github.com/aaron-prindle/krmapiserver/included/github.com/coreos/etcd/etcdserver/api/v3rpc/rpctypes"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/klog"
 
 	apierrors "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/api/errors"
@@ -39,26 +38,12 @@ import (
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/etcd"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/etcd/metrics"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/storeutil"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/usage"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/value"
 	utiltrace "github.com/aaron-prindle/krmapiserver/included/k8s.io/utils/trace"
 )
 
-// authenticatedDataString satisfies the value.Context interface. It uses the key to
-// authenticate the stored data. This does not defend against reuse of previously
-// encrypted values under the same key, but will prevent an attacker from using an
-// encrypted value from a different key. A stronger authenticated data segment would
-// include the etcd3 Version field (which is incremented on each write to a key and
-// reset when the key is deleted), but an attacker with write access to etcd can
-// force deletion and recreation of keys to weaken that angle.
-type authenticatedDataString string
-
-// AuthenticatedData implements the value.Context interface.
-func (d authenticatedDataString) AuthenticatedData() []byte {
-	return []byte(string(d))
-}
-
-var _ value.Context = authenticatedDataString("")
-
 type store struct {
 	client *clientv3.Client
 	// getOpts contains additional options that should be passed
@@ -71,6 +56,9 @@ type store struct {
 	watcher       *watcher
 	pagingEnabled bool
 	leaseManager  *leaseManager
+	// usage, if not nil, accounts the objects stored under the resource
+	// prefix of the store.
+	usage *usage.Tracker
 }
 
 type objState struct {
@@ -81,9 +69,37 @@ type objState struct {
 	stale bool
 }
 
+// Option configures a store created by New or NewWithUsage.
+type Option func(*store)
+
+// WithBookmarks makes watches that allow bookmarks request progress
+// notifications from etcd, and send them as bookmarks carrying an empty
+// object created by newFunc. Without it, watches don't send bookmarks.
+func WithBookmarks(newFunc func() runtime.Object) Option {
+	return func(s *store) {
+		s.watcher.newFunc = newFunc
+	}
+}
+
 // New returns an etcd3 implementation of storage.Interface.
-func New(c *clientv3.Client, codec runtime.Codec, prefix string, transformer value.Transformer, pagingEnabled bool) storage.Interface {
-	return newStore(c, pagingEnabled, codec, prefix, transformer)
+func New(c *clientv3.Client, codec runtime.Codec, prefix string, transformer value.Transformer, pagingEnabled bool, opts ...Option) storage.Interface {
+	s := newStore(c, pagingEnabled, codec, prefix, transformer)
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// NewWithUsage returns an etcd3 implementation of storage.Interface that also
+// accounts the number and size of the objects stored under resourcePrefix per
+// namespace, as a usage.Reporter, until the returned func is called.
+func NewWithUsage(c *clientv3.Client, codec runtime.Codec, prefix, resourcePrefix string, transformer value.Transformer, pagingEnabled bool, opts ...Option) (storage.Interface, func()) {
+	s := newStore(c, pagingEnabled, codec, prefix, transformer)
+	for _, opt := range opts {
+		opt(s)
+	}
+	release := s.trackUsage(resourcePrefix)
+	return s, release
 }
 
 func newStore(c *clientv3.Client, pagingEnabled bool, codec runtime.Codec, prefix string, transformer value.Transformer) *store {
@@ -127,12 +143,12 @@ func (s *store) Get(ctx context.Context, key string, resourceVersion string, out
 	}
 	kv := getResp.Kvs[0]
 
-	data, _, err := s.transformer.TransformFromStorage(kv.Value, authenticatedDataString(key))
+	data, _, err := s.transformer.TransformFromStorage(kv.Value, storeutil.AuthenticatedDataString(key))
 	if err != nil {
 		return storage.NewInternalError(err.Error())
 	}
 
-	return decode(s.codec, s.versioner, data, out, kv.ModRevision)
+	return storeutil.Decode(s.codec, s.versioner, data, out, kv.ModRevision)
 }
 
 // Create implements storage.Interface.Create.
@@ -154,7 +170,7 @@ func (s *store) Create(ctx context.Context, key string, obj, out runtime.Object,
 		return err
 	}
 
-	newData, err := s.transformer.TransformToStorage(data, authenticatedDataString(key))
+	newData, err := s.transformer.TransformToStorage(data, storeutil.AuthenticatedDataString(key))
 	if err != nil {
 		return storage.NewInternalError(err.Error())
 	}
@@ -175,7 +191,7 @@ func (s *store) Create(ctx context.Context, key string, obj, out runtime.Object,
 
 	if out != nil {
 		putResp := txnResp.Responses[0].GetResponsePut()
-		return decode(s.codec, s.versioner, data, out, putResp.Header.Revision)
+		return storeutil.Decode(s.codec, s.versioner, data, out, putResp.Header.Revision)
 	}
 	return nil
 }
@@ -227,7 +243,7 @@ func (s *store) conditionalDelete(ctx context.Context, key string, out runtime.O
 			klog.V(4).Infof("deletion of %s failed because of a conflict, going to retry", key)
 			continue
 		}
-		return decode(s.codec, s.versioner, origState.data, out, origState.rev)
+		return storeutil.Decode(s.codec, s.versioner, origState.data, out, origState.rev)
 	}
 }
 
@@ -270,7 +286,7 @@ func (s *store) GuaranteedUpdate(
 	}
 	trace.Step("initial value restored")
 
-	transformContext := authenticatedDataString(key)
+	transformContext := storeutil.AuthenticatedDataString(key)
 	for {
 		if err := preconditions.Check(key, origState.obj); err != nil {
 			return err
@@ -315,7 +331,7 @@ func (s *store) GuaranteedUpdate(
 			}
 			// recheck that the data from etcd is not stale before short-circuiting a write
 			if !origState.stale {
-				return decode(s.codec, s.versioner, origState.data, out, origState.rev)
+				return storeutil.Decode(s.codec, s.versioner, origState.data, out, origState.rev)
 			}
 		}
 
@@ -356,7 +372,7 @@ func (s *store) GuaranteedUpdate(
 		}
 		putResp := txnResp.Responses[0].GetResponsePut()
 
-		return decode(s.codec, s.versioner, data, out, putResp.Header.Revision)
+		return storeutil.Decode(s.codec, s.versioner, data, out, putResp.Header.Revision)
 	}
 }
 
@@ -382,11 +398,11 @@ func (s *store) GetToList(ctx context.Context, key string, resourceVersion strin
 	}
 
 	if len(getResp.Kvs) > 0 {
-		data, _, err := s.transformer.TransformFromStorage(getResp.Kvs[0].Value, authenticatedDataString(key))
+		data, _, err := s.transformer.TransformFromStorage(getResp.Kvs[0].Value, storeutil.AuthenticatedDataString(key))
 		if err != nil {
 			return storage.NewInternalError(err.Error())
 		}
-		if err := appendListItem(v, data, uint64(getResp.Kvs[0].ModRevision), pred, s.codec, s.versioner); err != nil {
+		if err := storeutil.AppendListItem(v, data, uint64(getResp.Kvs[0].ModRevision), pred, s.codec, s.versioner); err != nil {
 			return err
 		}
 	}
@@ -405,64 +421,64 @@ func (s *store) Count(key string) (int64, error) {
 	return getResp.Count, nil
 }
 
-// continueToken is a simple structured object for encoding the state of a continue token.
-// TODO: if we change the version of the encoded from, we can't start encoding the new version
-// until all other servers are upgraded (i.e. we need to support rolling schema)
-// This is a public API struct and cannot change.
-type continueToken struct {
-	APIVersion      string `json:"v"`
-	ResourceVersion int64  `json:"rv"`
-	StartKey        string `json:"start"`
-}
+// ChangedSince implements storage.ChangeDetector. Only the current revision
+// and the number of keys under key are read, along with at most one key that
+// was written after resourceVersion: if no key under key was written since
+// resourceVersion, every current key was already there at resourceVersion, so
+// a key can only have been deleted since if there were more keys then.
+func (s *store) ChangedSince(ctx context.Context, key string, resourceVersion uint64) (uint64, bool, error) {
+	key = path.Join(s.pathPrefix, key)
+	if !strings.HasSuffix(key, "/") {
+		key += "/"
+	}
+	startTime := time.Now()
+	defer metrics.RecordEtcdRequestLatency("changedSince", key, startTime)
+	currentResp, err := s.client.KV.Get(ctx, key, clientv3.WithPrefix(), clientv3.WithCountOnly())
+	if err != nil {
+		return 0, false, err
+	}
+	current := currentResp.Header.Revision
+	if resourceVersion == 0 || current <= int64(resourceVersion) {
+		return uint64(current), resourceVersion == 0, nil
+	}
 
-// parseFrom transforms an encoded predicate from into a versioned struct.
-// TODO: return a typed error that instructs clients that they must relist
-func decodeContinue(continueValue, keyPrefix string) (fromKey string, rv int64, err error) {
-	data, err := base64.RawURLEncoding.DecodeString(continueValue)
+	// etcd ignores the limit of ranges filtered by revision until the keys are
+	// filtered, so at most one key, without its value, is returned.
+	writtenResp, err := s.client.KV.Get(ctx, key, clientv3.WithPrefix(), clientv3.WithRev(current),
+		clientv3.WithMinModRev(int64(resourceVersion)+1), clientv3.WithKeysOnly(), clientv3.WithLimit(1))
 	if err != nil {
-		return "", 0, fmt.Errorf("continue key is not valid: %v", err)
+		return 0, false, err
 	}
-	var c continueToken
-	if err := json.Unmarshal(data, &c); err != nil {
-		return "", 0, fmt.Errorf("continue key is not valid: %v", err)
+	if len(writtenResp.Kvs) > 0 {
+		return uint64(current), true, nil
 	}
-	switch c.APIVersion {
-	case "meta.k8s.io/v1":
-		if c.ResourceVersion == 0 {
-			return "", 0, fmt.Errorf("continue key is not valid: incorrect encoded start resourceVersion (version meta.k8s.io/v1)")
-		}
-		if len(c.StartKey) == 0 {
-			return "", 0, fmt.Errorf("continue key is not valid: encoded start key empty (version meta.k8s.io/v1)")
-		}
-		// defend against path traversal attacks by clients - path.Clean will ensure that startKey cannot
-		// be at a higher level of the hierarchy, and so when we append the key prefix we will end up with
-		// continue start key that is fully qualified and cannot range over anything less specific than
-		// keyPrefix.
-		key := c.StartKey
-		if !strings.HasPrefix(key, "/") {
-			key = "/" + key
-		}
-		cleaned := path.Clean(key)
-		if cleaned != key {
-			return "", 0, fmt.Errorf("continue key is not valid: %s", c.StartKey)
-		}
-		return keyPrefix + cleaned[1:], c.ResourceVersion, nil
-	default:
-		return "", 0, fmt.Errorf("continue key is not valid: server does not recognize this encoded version %q", c.APIVersion)
+	sinceResp, err := s.client.KV.Get(ctx, key, clientv3.WithPrefix(), clientv3.WithRev(int64(resourceVersion)), clientv3.WithCountOnly())
+	if err == etcdrpc.ErrCompacted {
+		return uint64(current), true, nil
+	} else if err != nil {
+		return 0, false, err
 	}
+	return uint64(current), sinceResp.Count != currentResp.Count, nil
 }
 
-// encodeContinue returns a string representing the encoded continuation of the current query.
-func encodeContinue(key, keyPrefix string, resourceVersion int64) (string, error) {
-	nextKey := strings.TrimPrefix(key, keyPrefix)
-	if nextKey == key {
-		return "", fmt.Errorf("unable to encode next field: the key and key prefix do not match")
+// ListKeys implements storage.KeyLister. Only the keys are read from etcd.
+func (s *store) ListKeys(ctx context.Context, key string) ([]string, error) {
+	key = path.Join(s.pathPrefix, key)
+	if !strings.HasSuffix(key, "/") {
+		key += "/"
 	}
-	out, err := json.Marshal(&continueToken{APIVersion: "meta.k8s.io/v1", ResourceVersion: resourceVersion, StartKey: nextKey})
+	startTime := time.Now()
+	getResp, err := s.client.KV.Get(ctx, key, clientv3.WithPrefix(), clientv3.WithKeysOnly())
+	metrics.RecordEtcdRequestLatency("listKeys", key, startTime)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	prefix := strings.TrimSuffix(s.pathPrefix, "/")
+	keys := make([]string, 0, len(getResp.Kvs))
+	for _, kv := range getResp.Kvs {
+		keys = append(keys, strings.TrimPrefix(string(kv.Key), prefix))
 	}
-	return base64.RawURLEncoding.EncodeToString(out), nil
+	return keys, nil
 }
 
 // List implements storage.Interface.List.
@@ -501,7 +517,7 @@ func (s *store) List(ctx context.Context, key, resourceVersion string, pred stor
 	var continueKey string
 	switch {
 	case s.pagingEnabled && len(pred.Continue) > 0:
-		continueKey, continueRV, err = decodeContinue(pred.Continue, keyPrefix)
+		continueKey, continueRV, err = storage.DecodeContinue(pred.Continue, keyPrefix)
 		if err != nil {
 			return apierrors.NewBadRequest(fmt.Sprintf("invalid continue token: %v", err))
 		}
@@ -560,7 +576,7 @@ func (s *store) List(ctx context.Context, key, resourceVersion string, pred stor
 		getResp, err = s.client.KV.Get(ctx, key, options...)
 		metrics.RecordEtcdRequestLatency("list", getTypeName(listPtr), startTime)
 		if err != nil {
-			return interpretListError(err, len(pred.Continue) > 0, continueKey, keyPrefix)
+			return storeutil.InterpretListError(err, isCompacted, len(pred.Continue) > 0, continueKey, keyPrefix)
 		}
 		hasMore = getResp.More
 
@@ -571,9 +587,9 @@ func (s *store) List(ctx context.Context, key, resourceVersion string, pred stor
 		// avoid small allocations for the result slice, since this can be called in many
 		// different contexts and we don't know how significantly the result will be filtered
 		if pred.Empty() {
-			growSlice(v, len(getResp.Kvs))
+			storeutil.GrowSlice(v, len(getResp.Kvs))
 		} else {
-			growSlice(v, 2048, len(getResp.Kvs))
+			storeutil.GrowSlice(v, 2048, len(getResp.Kvs))
 		}
 
 		// take items from the response until the bucket is full, filtering as we go
@@ -584,12 +600,12 @@ func (s *store) List(ctx context.Context, key, resourceVersion string, pred stor
 			}
 			lastKey = kv.Key
 
-			data, _, err := s.transformer.TransformFromStorage(kv.Value, authenticatedDataString(kv.Key))
+			data, _, err := s.transformer.TransformFromStorage(kv.Value, storeutil.AuthenticatedDataString(kv.Key))
 			if err != nil {
 				return storage.NewInternalErrorf("unable to transform key %q: %v", kv.Key, err)
 			}
 
-			if err := appendListItem(v, data, uint64(kv.ModRevision), pred, s.codec, s.versioner); err != nil {
+			if err := storeutil.AppendListItem(v, data, uint64(kv.ModRevision), pred, s.codec, s.versioner); err != nil {
 				return err
 			}
 		}
@@ -614,7 +630,7 @@ func (s *store) List(ctx context.Context, key, resourceVersion string, pred stor
 	// we never return a key that the client wouldn't be allowed to see
 	if hasMore {
 		// we want to start immediately after the last key
-		next, err := encodeContinue(string(lastKey)+"\x00", keyPrefix, returnedRV)
+		next, err := storage.EncodeContinue(string(lastKey)+"\x00", keyPrefix, returnedRV)
 		if err != nil {
 			return err
 		}
@@ -633,37 +649,6 @@ func (s *store) List(ctx context.Context, key, resourceVersion string, pred stor
 	return s.versioner.UpdateList(listObj, uint64(returnedRV), "", nil)
 }
 
-// growSlice takes a slice value and grows its capacity up
-// to the maximum of the passed sizes or maxCapacity, whichever
-// is smaller. Above maxCapacity decisions about allocation are left
-// to the Go runtime on append. This allows a caller to make an
-// educated guess about the potential size of the total list while
-// still avoiding overly aggressive initial allocation. If sizes
-// is empty maxCapacity will be used as the size to grow.
-func growSlice(v reflect.Value, maxCapacity int, sizes ...int) {
-	cap := v.Cap()
-	max := cap
-	for _, size := range sizes {
-		if size > max {
-			max = size
-		}
-	}
-	if len(sizes) == 0 || max > maxCapacity {
-		max = maxCapacity
-	}
-	if max <= cap {
-		return
-	}
-	if v.Len() > 0 {
-		extra := reflect.MakeSlice(v.Type(), 0, max)
-		reflect.Copy(extra, v)
-		v.Set(extra)
-	} else {
-		extra := reflect.MakeSlice(v.Type(), 0, max)
-		v.Set(extra)
-	}
-}
-
 // Watch implements storage.Interface.Watch.
 func (s *store) Watch(ctx context.Context, key string, resourceVersion string, pred storage.SelectionPredicate) (watch.Interface, error) {
 	return s.watch(ctx, key, resourceVersion, pred, false)
@@ -696,7 +681,7 @@ func (s *store) getState(getResp *clientv3.GetResponse, key string, v reflect.Va
 			return nil, err
 		}
 	} else {
-		data, stale, err := s.transformer.TransformFromStorage(getResp.Kvs[0].Value, authenticatedDataString(key))
+		data, stale, err := s.transformer.TransformFromStorage(getResp.Kvs[0].Value, storeutil.AuthenticatedDataString(key))
 		if err != nil {
 			return nil, storage.NewInternalError(err.Error())
 		}
@@ -704,7 +689,7 @@ func (s *store) getState(getResp *clientv3.GetResponse, key string, v reflect.Va
 		state.meta.ResourceVersion = uint64(state.rev)
 		state.data = data
 		state.stale = stale
-		if err := decode(s.codec, s.versioner, state.data, state.obj, state.rev); err != nil {
+		if err := storeutil.Decode(s.codec, s.versioner, state.data, state.obj, state.rev); err != nil {
 			return nil, err
 		}
 	}
@@ -766,35 +751,6 @@ func (s *store) ttlOpts(ctx context.Context, ttl int64) ([]clientv3.OpOption, er
 	return []clientv3.OpOption{clientv3.WithLease(id)}, nil
 }
 
-// decode decodes value of bytes into object. It will also set the object resource version to rev.
-// On success, objPtr would be set to the object.
-func decode(codec runtime.Codec, versioner storage.Versioner, value []byte, objPtr runtime.Object, rev int64) error {
-	if _, err := conversion.EnforcePtr(objPtr); err != nil {
-		panic("unable to convert output object to pointer")
-	}
-	_, _, err := codec.Decode(value, nil, objPtr)
-	if err != nil {
-		return err
-	}
-	// being unable to set the version does not prevent the object from being extracted
-	versioner.UpdateObject(objPtr, uint64(rev))
-	return nil
-}
-
-// appendListItem decodes and appends the object (if it passes filter) to v, which must be a slice.
-func appendListItem(v reflect.Value, data []byte, rev uint64, pred storage.SelectionPredicate, codec runtime.Codec, versioner storage.Versioner) error {
-	obj, _, err := codec.Decode(data, nil, reflect.New(v.Type().Elem()).Interface().(runtime.Object))
-	if err != nil {
-		return err
-	}
-	// being unable to set the version does not prevent the object from being extracted
-	versioner.UpdateObject(obj, rev)
-	if matched, err := pred.Matches(obj); err == nil && matched {
-		v.Set(reflect.Append(v, reflect.ValueOf(obj).Elem()))
-	}
-	return nil
-}
-
 func notFound(key string) clientv3.Cmp {
 	return clientv3.Compare(clientv3.ModRevision(key), "=", 0)
 }
@@ -45,6 +45,8 @@ import (
 	examplev1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/apis/example/v1"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/etcd"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/storeutil"
+	storagetesting "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/testing"
 	storagetests "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/tests"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/value"
 	utilpointer "github.com/aaron-prindle/krmapiserver/included/k8s.io/utils/pointer"
@@ -814,7 +816,7 @@ func TestList(t *testing.T) {
 	list := &example.PodList{}
 	store.List(ctx, "/two-level", "0", storage.Everything, list)
 	continueRV, _ := strconv.Atoi(list.ResourceVersion)
-	secondContinuation, err := encodeContinue("/two-level/2", "/two-level/", int64(continueRV))
+	secondContinuation, err := storage.EncodeContinue("/two-level/2", "/two-level/", int64(continueRV))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1156,7 +1158,7 @@ func TestListContinuation(t *testing.T) {
 		t.Fatalf("Unexpected continuation token set")
 	}
 	if !reflect.DeepEqual(out.Items, []example.Pod{*preset[1].storedObj, *preset[2].storedObj}) {
-		key, rv, err := decodeContinue(continueFromSecondItem, "/")
+		key, rv, err := storage.DecodeContinue(continueFromSecondItem, "/")
 		t.Logf("continue token was %d %s %v", rv, key, err)
 		t.Fatalf("Unexpected second page: %#v", out.Items)
 	}
@@ -1291,7 +1293,7 @@ func TestListInconsistentContinuation(t *testing.T) {
 	if err == nil {
 		t.Fatalf("unexpected no error")
 	}
-	if !strings.Contains(err.Error(), inconsistentContinue) {
+	if !strings.Contains(err.Error(), storeutil.InconsistentContinue) {
 		t.Fatalf("unexpected error message %v", err)
 	}
 	status, ok := err.(apierrors.APIStatus)
@@ -1360,6 +1362,24 @@ func testPropogateStore(ctx context.Context, t *testing.T, store *store, obj *ex
 	return key, setOutput
 }
 
+func TestConformance(t *testing.T) {
+	storagetesting.RunConformanceTests(t, func(t *testing.T) (storage.Interface, func(string), func()) {
+		cluster := integration.NewClusterV3(t, &integration.ClusterConfig{Size: 1})
+		store := newStore(cluster.RandClient(), true, storagetesting.Codec, "", prefixTransformer{prefix: []byte(defaultTestPrefix)})
+		store.leaseManager.setLeaseReuseDurationSeconds(1)
+		compact := func(resourceVersion string) {
+			rv, err := store.versioner.ParseResourceVersion(resourceVersion)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := cluster.Client(0).KV.Compact(context.Background(), int64(rv), clientv3.WithCompactPhysical()); err != nil {
+				t.Fatalf("Unable to compact, %v", err)
+			}
+		}
+		return store, compact, func() { cluster.Terminate(t) }
+	})
+}
+
 func TestPrefix(t *testing.T) {
 	codec := apitesting.TestCodec(codecs, examplev1.SchemeGroupVersion)
 	cluster := integration.NewClusterV3(t, &integration.ClusterConfig{Size: 1})
@@ -1379,114 +1399,13 @@ func TestPrefix(t *testing.T) {
 }
 
 func encodeContinueOrDie(apiVersion string, resourceVersion int64, nextKey string) string {
-	out, err := json.Marshal(&continueToken{APIVersion: apiVersion, ResourceVersion: resourceVersion, StartKey: nextKey})
+	out, err := json.Marshal(&struct {
+		APIVersion      string `json:"v"`
+		ResourceVersion int64  `json:"rv"`
+		StartKey        string `json:"start"`
+	}{APIVersion: apiVersion, ResourceVersion: resourceVersion, StartKey: nextKey})
 	if err != nil {
 		panic(err)
 	}
 	return base64.RawURLEncoding.EncodeToString(out)
 }
-
-func Test_decodeContinue(t *testing.T) {
-	type args struct {
-		continueValue string
-		keyPrefix     string
-	}
-	tests := []struct {
-		name        string
-		args        args
-		wantFromKey string
-		wantRv      int64
-		wantErr     bool
-	}{
-		{name: "valid", args: args{continueValue: encodeContinueOrDie("meta.k8s.io/v1", 1, "key"), keyPrefix: "/test/"}, wantRv: 1, wantFromKey: "/test/key"},
-		{name: "root path", args: args{continueValue: encodeContinueOrDie("meta.k8s.io/v1", 1, "/"), keyPrefix: "/test/"}, wantRv: 1, wantFromKey: "/test/"},
-
-		{name: "empty version", args: args{continueValue: encodeContinueOrDie("", 1, "key"), keyPrefix: "/test/"}, wantErr: true},
-		{name: "invalid version", args: args{continueValue: encodeContinueOrDie("v1", 1, "key"), keyPrefix: "/test/"}, wantErr: true},
-
-		{name: "path traversal - parent", args: args{continueValue: encodeContinueOrDie("meta.k8s.io/v1", 1, "../key"), keyPrefix: "/test/"}, wantErr: true},
-		{name: "path traversal - local", args: args{continueValue: encodeContinueOrDie("meta.k8s.io/v1", 1, "./key"), keyPrefix: "/test/"}, wantErr: true},
-		{name: "path traversal - double parent", args: args{continueValue: encodeContinueOrDie("meta.k8s.io/v1", 1, "./../key"), keyPrefix: "/test/"}, wantErr: true},
-		{name: "path traversal - after parent", args: args{continueValue: encodeContinueOrDie("meta.k8s.io/v1", 1, "key/../.."), keyPrefix: "/test/"}, wantErr: true},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			gotFromKey, gotRv, err := decodeContinue(tt.args.continueValue, tt.args.keyPrefix)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("decodeContinue() error = %v, wantErr %v", err, tt.wantErr)
-				return
-			}
-			if gotFromKey != tt.wantFromKey {
-				t.Errorf("decodeContinue() gotFromKey = %v, want %v", gotFromKey, tt.wantFromKey)
-			}
-			if gotRv != tt.wantRv {
-				t.Errorf("decodeContinue() gotRv = %v, want %v", gotRv, tt.wantRv)
-			}
-		})
-	}
-}
-
-func Test_growSlice(t *testing.T) {
-	type args struct {
-		t               reflect.Type
-		initialCapacity int
-		v               reflect.Value
-		maxCapacity     int
-		sizes           []int
-	}
-	tests := []struct {
-		name string
-		args args
-		cap  int
-	}{
-		{
-			name: "empty",
-			args: args{v: reflect.ValueOf([]example.Pod{})},
-			cap:  0,
-		},
-		{
-			name: "no sizes",
-			args: args{v: reflect.ValueOf([]example.Pod{}), maxCapacity: 10},
-			cap:  10,
-		},
-		{
-			name: "above maxCapacity",
-			args: args{v: reflect.ValueOf([]example.Pod{}), maxCapacity: 10, sizes: []int{1, 12}},
-			cap:  10,
-		},
-		{
-			name: "takes max",
-			args: args{v: reflect.ValueOf([]example.Pod{}), maxCapacity: 10, sizes: []int{8, 4}},
-			cap:  8,
-		},
-		{
-			name: "with existing capacity above max",
-			args: args{initialCapacity: 12, maxCapacity: 10, sizes: []int{8, 4}},
-			cap:  12,
-		},
-		{
-			name: "with existing capacity below max",
-			args: args{initialCapacity: 5, maxCapacity: 10, sizes: []int{8, 4}},
-			cap:  8,
-		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if tt.args.initialCapacity > 0 {
-				tt.args.v = reflect.ValueOf(make([]example.Pod, 0, tt.args.initialCapacity))
-			}
-			// reflection requires that the value be addressible in order to call set,
-			// so we must ensure the value we created is available on the heap (not a problem
-			// for normal usage)
-			if !tt.args.v.CanAddr() {
-				x := reflect.New(tt.args.v.Type())
-				x.Elem().Set(tt.args.v)
-				tt.args.v = x.Elem()
-			}
-			growSlice(tt.args.v, tt.args.maxCapacity, tt.args.sizes...)
-			if tt.cap != tt.args.v.Cap() {
-				t.Errorf("Unexpected capacity: got=%d want=%d", tt.args.v.Cap(), tt.cap)
-			}
-		})
-	}
-}
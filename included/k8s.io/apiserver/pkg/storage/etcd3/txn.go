@@ -0,0 +1,247 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcd3
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/aaron-prindle/krmapiserver/included/github.com/coreos/etcd/clientv3"
+	"github.com/aaron-prindle/krmapiserver/included/github.com/coreos/etcd/mvcc/mvccpb"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/klog"
+
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/etcd/metrics"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/storeutil"
+	utiltrace "github.com/aaron-prindle/krmapiserver/included/k8s.io/utils/trace"
+)
+
+// txnOpState is an operation of a transaction resolved against the store the
+// key belongs to.
+type txnOpState struct {
+	storage.TxnOp
+	store *store
+	// key is the key including the path prefix of store.
+	key string
+	// data is the encoded object of creations and updates before it is
+	// transformed.
+	data []byte
+}
+
+// Transact implements storage.Interface.Transact.
+func (s *store) Transact(ctx context.Context, ops []storage.TxnOp) error {
+	if len(ops) == 0 {
+		return nil
+	}
+	trace := utiltrace.New(fmt.Sprintf("Transact etcd3: %d operations", len(ops)))
+	defer trace.LogIfLong(500 * time.Millisecond)
+
+	states := make([]*txnOpState, len(ops))
+	seen := make(map[string]bool, len(ops))
+	for i, op := range ops {
+		st, err := s.resolveTxnOp(op)
+		if err != nil {
+			return storage.NewTxnOpError(i, err)
+		}
+		if seen[st.key] {
+			return storage.NewTxnOpError(i, fmt.Errorf("duplicate key %s in transaction", st.key))
+		}
+		seen[st.key] = true
+		states[i] = st
+	}
+	trace.Step("Operations resolved")
+
+	getOps := make([]clientv3.Op, len(states))
+	for i, st := range states {
+		getOps[i] = clientv3.OpGet(st.key)
+	}
+	startTime := time.Now()
+	getResp, err := s.client.KV.Txn(ctx).Then(getOps...).Commit()
+	metrics.RecordEtcdRequestLatency("get", "transaction", startTime)
+	if err != nil {
+		return err
+	}
+	for attempt := 1; ; attempt++ {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		cmps := make([]clientv3.Cmp, 0, len(states))
+		thenOps := make([]clientv3.Op, 0, len(states))
+		for i, st := range states {
+			resp := getResp.Responses[i].GetResponseRange()
+			cmp, op, err := st.store.prepareTxnOp(st, resp.Kvs)
+			if err != nil {
+				return storage.NewTxnOpError(i, err)
+			}
+			cmps = append(cmps, cmp)
+			thenOps = append(thenOps, op)
+		}
+		trace.Step("Transaction prepared")
+
+		startTime := time.Now()
+		txnResp, err := s.client.KV.Txn(ctx).If(cmps...).Then(thenOps...).Else(getOps...).Commit()
+		metrics.RecordEtcdRequestLatency("transaction", getTypeName(ops[0].Out), startTime)
+		if err != nil {
+			return err
+		}
+		trace.Step("Transaction committed")
+		if !txnResp.Succeeded {
+			if attempt == storage.MaxTxnAttempts {
+				return conflictError(states, getResp, txnResp)
+			}
+			getResp = txnResp
+			klog.V(4).Infof("transaction of %d operations failed because of a conflict, going to retry", len(states))
+			continue
+		}
+
+		for _, st := range states {
+			if st.Type == storage.TxnDelete {
+				// Out already holds the deleted object.
+				continue
+			}
+			if err := storeutil.Decode(st.store.codec, st.store.versioner, st.data, st.Out, txnResp.Header.Revision); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// conflictError returns the resource version conflict of the first operation
+// whose key changed between the reads before and after a failed commit.
+func conflictError(states []*txnOpState, before, after *clientv3.TxnResponse) error {
+	for i, st := range states {
+		var modRevisionBefore, modRevisionAfter int64
+		if kvs := before.Responses[i].GetResponseRange().Kvs; len(kvs) > 0 {
+			modRevisionBefore = kvs[0].ModRevision
+		}
+		if kvs := after.Responses[i].GetResponseRange().Kvs; len(kvs) > 0 {
+			modRevisionAfter = kvs[0].ModRevision
+		}
+		if modRevisionBefore != modRevisionAfter {
+			return storage.NewTxnOpError(i, storage.NewResourceVersionConflictsError(st.key, modRevisionAfter))
+		}
+	}
+	return storage.NewResourceVersionConflictsError(states[0].key, 0)
+}
+
+// resolveTxnOp resolves op against the store its key belongs to and encodes
+// the object of creations and updates.
+func (s *store) resolveTxnOp(op storage.TxnOp) (*txnOpState, error) {
+	st := &txnOpState{TxnOp: op, store: s}
+	if op.Storage != nil {
+		other, ok := storage.Unwrap(op.Storage).(*store)
+		if !ok || !sameCluster(s.client, other.client) {
+			return nil, fmt.Errorf("the storage of %s is not served by the same etcd cluster", op.Key)
+		}
+		st.store = other
+	}
+	if op.Out == nil {
+		return nil, fmt.Errorf("no output object for %s", op.Key)
+	}
+	st.key = path.Join(st.store.pathPrefix, op.Key)
+
+	switch op.Type {
+	case storage.TxnCreate:
+		if version, err := st.store.versioner.ObjectResourceVersion(op.Obj); err == nil && version != 0 {
+			return nil, fmt.Errorf("resourceVersion should not be set on objects to be created")
+		}
+		fallthrough
+	case storage.TxnUpdate:
+		if err := st.store.versioner.PrepareObjectForStorage(op.Obj); err != nil {
+			return nil, fmt.Errorf("PrepareObjectForStorage failed: %v", err)
+		}
+		data, err := runtime.Encode(st.store.codec, op.Obj)
+		if err != nil {
+			return nil, err
+		}
+		st.data = data
+	case storage.TxnDelete:
+	default:
+		return nil, fmt.Errorf("unknown operation type %q", op.Type)
+	}
+	return st, nil
+}
+
+// prepareTxnOp checks st against the current value of its key, which is the
+// only element of kvs unless the key doesn't exist, and returns the
+// comparison guarding the operation and the operation itself.
+func (s *store) prepareTxnOp(st *txnOpState, kvs []*mvccpb.KeyValue) (clientv3.Cmp, clientv3.Op, error) {
+	if st.Type == storage.TxnCreate {
+		if len(kvs) > 0 {
+			return clientv3.Cmp{}, clientv3.Op{}, storage.NewKeyExistsError(st.key, 0)
+		}
+		newData, err := s.transformer.TransformToStorage(st.data, storeutil.AuthenticatedDataString(st.key))
+		if err != nil {
+			return clientv3.Cmp{}, clientv3.Op{}, storage.NewInternalError(err.Error())
+		}
+		return notFound(st.key), clientv3.OpPut(st.key, string(newData)), nil
+	}
+
+	if len(kvs) == 0 {
+		return clientv3.Cmp{}, clientv3.Op{}, storage.NewKeyNotFoundError(st.key, 0)
+	}
+	kv := kvs[0]
+	// The current object is decoded into Out to check the preconditions,
+	// which leaves the deleted object in Out for deletions.
+	data, _, err := s.transformer.TransformFromStorage(kv.Value, storeutil.AuthenticatedDataString(st.key))
+	if err != nil {
+		return clientv3.Cmp{}, clientv3.Op{}, storage.NewInternalError(err.Error())
+	}
+	if err := storeutil.Decode(s.codec, s.versioner, data, st.Out, kv.ModRevision); err != nil {
+		return clientv3.Cmp{}, clientv3.Op{}, err
+	}
+	if err := st.Preconditions.Check(st.key, st.Out); err != nil {
+		return clientv3.Cmp{}, clientv3.Op{}, err
+	}
+	cmp := clientv3.Compare(clientv3.ModRevision(st.key), "=", kv.ModRevision)
+	if st.Type == storage.TxnDelete {
+		return cmp, clientv3.OpDelete(st.key), nil
+	}
+	newData, err := s.transformer.TransformToStorage(st.data, storeutil.AuthenticatedDataString(st.key))
+	if err != nil {
+		return clientv3.Cmp{}, clientv3.Op{}, storage.NewInternalError(err.Error())
+	}
+	return cmp, clientv3.OpPut(st.key, string(newData)), nil
+}
+
+// sameCluster returns true if a and b are connected to the same etcd
+// endpoints, and can therefore take part in the same transaction.
+func sameCluster(a, b *clientv3.Client) bool {
+	if a == b {
+		return true
+	}
+	endpointsA := append([]string(nil), a.Endpoints()...)
+	endpointsB := append([]string(nil), b.Endpoints()...)
+	if len(endpointsA) != len(endpointsB) {
+		return false
+	}
+	sort.Strings(endpointsA)
+	sort.Strings(endpointsB)
+	for i := range endpointsA {
+		if endpointsA[i] != endpointsB[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcd3
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aaron-prindle/krmapiserver/included/github.com/coreos/etcd/clientv3"
+
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/etcd/metrics"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/storeutil"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/usage"
+)
+
+// usageLoadPageSize is the number of keys fetched at once when loading the usage.
+const usageLoadPageSize = 1000
+
+var _ usage.Reporter = &store{}
+var _ usage.Sizer = &store{}
+
+// trackUsage makes the store account the objects stored under resourcePrefix
+// and returns a func that stops the accounting. The usage is loaded once and
+// then kept current from a watch of the keys, which also sees the writes of
+// other servers and the deletion of objects with an expired lease.
+func (s *store) trackUsage(resourcePrefix string) func() {
+	endpoints := append([]string(nil), s.client.Endpoints()...)
+	sort.Strings(endpoints)
+	keyPrefix := path.Join(s.pathPrefix, resourcePrefix)
+	tracker, release := usage.Acquire(strings.Join(endpoints, ","), keyPrefix, strings.Trim(resourcePrefix, "/"), s.loadUsage, s.watchUsage)
+	s.usage = tracker
+	return release
+}
+
+// NamespaceUsage implements usage.Reporter.
+func (s *store) NamespaceUsage() (map[string]usage.Usage, bool) {
+	if s.usage == nil {
+		return nil, false
+	}
+	return s.usage.NamespaceUsage()
+}
+
+// StoredSize implements usage.Sizer. The length of a transformed value does
+// not depend on the key it is authenticated with, so obj is transformed
+// without one.
+func (s *store) StoredSize(obj runtime.Object) (int64, error) {
+	obj = obj.DeepCopyObject()
+	if err := s.versioner.PrepareObjectForStorage(obj); err != nil {
+		return 0, err
+	}
+	data, err := runtime.Encode(s.codec, obj)
+	if err != nil {
+		return 0, err
+	}
+	value, err := s.transformer.TransformToStorage(data, storeutil.AuthenticatedDataString(""))
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(value)), nil
+}
+
+// loadUsage returns the size of the values stored under keyPrefix, read in
+// pages at a single revision.
+func (s *store) loadUsage(keyPrefix string) (map[string]int64, int64, error) {
+	sizes := map[string]int64{}
+	key := keyPrefix
+	rangeEnd := clientv3.GetPrefixRangeEnd(keyPrefix)
+	var rev int64
+	for {
+		options := []clientv3.OpOption{clientv3.WithRange(rangeEnd), clientv3.WithLimit(usageLoadPageSize)}
+		if rev != 0 {
+			options = append(options, clientv3.WithRev(rev))
+		}
+		startTime := time.Now()
+		getResp, err := s.client.KV.Get(context.Background(), key, options...)
+		metrics.RecordEtcdRequestLatency("listWithSizes", keyPrefix, startTime)
+		if err != nil {
+			return nil, 0, err
+		}
+		if rev == 0 {
+			rev = getResp.Header.Revision
+		}
+		for _, kv := range getResp.Kvs {
+			sizes[string(kv.Key)] = int64(len(kv.Value))
+		}
+		if !getResp.More || len(getResp.Kvs) == 0 {
+			return sizes, rev, nil
+		}
+		// The next page starts right after the last key of this one.
+		key = string(getResp.Kvs[len(getResp.Kvs)-1].Key) + "\x00"
+	}
+}
+
+// watchUsage records the changes made to the values stored under keyPrefix
+// after revision rev, until stopCh is closed. The events carry the previous
+// values of the keys, which give the size updates and deletions remove.
+func (s *store) watchUsage(keyPrefix string, rev int64, record usage.RecordFunc, stopCh <-chan struct{}) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-stopCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	wch := s.client.Watch(ctx, keyPrefix, clientv3.WithPrefix(), clientv3.WithRev(rev+1), clientv3.WithPrevKV())
+	for wres := range wch {
+		if err := wres.Err(); err != nil {
+			return err
+		}
+		for _, e := range wres.Events {
+			key := string(e.Kv.Key)
+			switch {
+			case e.IsCreate():
+				record(key, 1, int64(len(e.Kv.Value)))
+			case e.PrevKv == nil:
+				// etcd leaves the previous value out once its revision has
+				// been compacted, so the usage has to be reloaded.
+				return fmt.Errorf("the previous value of %s at revision %d is not known", key, e.Kv.ModRevision)
+			case e.Type == clientv3.EventTypeDelete:
+				record(key, -1, -int64(len(e.PrevKv.Value)))
+			default:
+				record(key, 0, int64(len(e.Kv.Value)-len(e.PrevKv.Value)))
+			}
+		}
+	}
+	select {
+	case <-stopCh:
+		return nil
+	default:
+		return fmt.Errorf("the watch of %s was closed", keyPrefix)
+	}
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcd3
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aaron-prindle/krmapiserver/included/github.com/coreos/etcd/clientv3"
+	v1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/core/v1"
+	metav1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/util/wait"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/usage"
+)
+
+func TestUsage(t *testing.T) {
+	ctx, store, cluster := testSetup(t)
+	defer cluster.Terminate(t)
+
+	// An object created before the accounting starts is loaded.
+	if err := store.Create(ctx, "/pods/ns1/a", &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "a"}}, nil, 0); err != nil {
+		t.Fatal(err)
+	}
+	release := store.trackUsage("pods")
+	defer release()
+	if err := wait.PollImmediate(10*time.Millisecond, wait.ForeverTestTimeout, func() (bool, error) {
+		_, ok := store.NamespaceUsage()
+		return ok, nil
+	}); err != nil {
+		t.Fatalf("the usage was not loaded: %v", err)
+	}
+
+	if err := store.Create(ctx, "/pods/ns1/b", &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "b"}}, nil, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Create(ctx, "/pods/ns2/a", &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns2", Name: "a"}}, nil, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.GuaranteedUpdate(ctx, "/pods/ns1/a", &v1.Pod{}, false, nil,
+		storage.SimpleUpdate(func(obj runtime.Object) (runtime.Object, error) {
+			pod := obj.(*v1.Pod)
+			pod.Spec.NodeName = "a-node-with-a-long-name"
+			return pod, nil
+		})); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Delete(ctx, "/pods/ns2/a", &v1.Pod{}, nil, storage.ValidateAllObjectFunc); err != nil {
+		t.Fatal(err)
+	}
+	err := store.Transact(ctx, []storage.TxnOp{
+		{Type: storage.TxnCreate, Key: "/pods/ns3/a", Obj: &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns3", Name: "a"}}, Out: &v1.Pod{}},
+		{Type: storage.TxnDelete, Key: "/pods/ns1/b", Out: &v1.Pod{}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The writes of other servers, which don't account the usage, are
+	// accounted as well, including the deletion of an expired object.
+	other := newStore(cluster.RandClient(), true, store.codec, "", store.transformer)
+	other.leaseManager.setLeaseReuseDurationSeconds(1)
+	if err := other.Create(ctx, "/pods/ns3/b", &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns3", Name: "b"}}, nil, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := other.Create(ctx, "/pods/ns4/a", &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns4", Name: "a"}}, nil, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	// The accounted usage matches the stored values once the object with a
+	// TTL has expired.
+	var expected map[string]usage.Usage
+	if err := wait.PollImmediate(100*time.Millisecond, wait.ForeverTestTimeout, func() (bool, error) {
+		getResp, err := store.client.KV.Get(ctx, "/pods/", clientv3.WithPrefix())
+		if err != nil {
+			return false, err
+		}
+		expected = map[string]usage.Usage{}
+		for _, kv := range getResp.Kvs {
+			namespace := string(kv.Key)[len("/pods/"):len("/pods/ns1")]
+			u := expected[namespace]
+			u.Objects++
+			u.Bytes += int64(len(kv.Value))
+			expected[namespace] = u
+		}
+		if _, ok := expected["ns4"]; ok {
+			return false, nil
+		}
+		got, _ := store.NamespaceUsage()
+		return reflect.DeepEqual(got, expected), nil
+	}); err != nil {
+		got, _ := store.NamespaceUsage()
+		t.Fatalf("expected %v, got %v: %v", expected, got, err)
+	}
+	if len(expected) != 2 || expected["ns1"].Objects != 1 || expected["ns3"].Objects != 2 {
+		t.Fatalf("unexpected objects stored: %v", expected)
+	}
+
+	// Reloading the usage agrees.
+	if _, err := store.usage.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := store.NamespaceUsage(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v after a reload, got %v", expected, got)
+	}
+}
@@ -0,0 +1,182 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package faultinjection
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	metav1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime/schema"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/util/sets"
+	"github.com/aaron-prindle/krmapiserver/included/sigs.k8s.io/yaml"
+)
+
+// Operation is a storage operation faults can be injected in.
+type Operation string
+
+const (
+	OperationGet      Operation = "get"
+	OperationList     Operation = "list"
+	OperationCreate   Operation = "create"
+	OperationUpdate   Operation = "update"
+	OperationDelete   Operation = "delete"
+	OperationWatch    Operation = "watch"
+	OperationCount    Operation = "count"
+	OperationTransact Operation = "transact"
+)
+
+var operations = sets.NewString(
+	string(OperationGet),
+	string(OperationList),
+	string(OperationCreate),
+	string(OperationUpdate),
+	string(OperationDelete),
+	string(OperationWatch),
+	string(OperationCount),
+	string(OperationTransact),
+)
+
+// ErrorType is the type of an injected error.
+type ErrorType string
+
+const (
+	// ErrorConflict fails the operation with a resource version conflict,
+	// which is returned to clients as a 409.
+	ErrorConflict ErrorType = "Conflict"
+	// ErrorTimeout fails the operation as if the storage could not be
+	// reached in time, which is returned to clients as a 504.
+	ErrorTimeout ErrorType = "Timeout"
+	// ErrorUnavailable fails the operation as if the storage was
+	// unavailable, which is returned to clients as a 503.
+	ErrorUnavailable ErrorType = "Unavailable"
+)
+
+// AllResources matches the resources of all groups in Rule.Resource.
+const AllResources = "*"
+
+// Config is the fault injection configuration.
+type Config struct {
+	// Rules are the fault injection rules. The faults of all the rules
+	// matching an operation are injected in it.
+	Rules []Rule `json:"rules"`
+}
+
+// Rule injects faults in the operations of a resource. Probabilities are
+// between 0 and 1.
+type Rule struct {
+	// Resource is the group resource the faults are injected in, such as
+	// "pods" or "deployments.apps", or "*" for all resources.
+	Resource string `json:"resource"`
+	// Operations are the operations the faults are injected in. All the
+	// operations if empty.
+	Operations []Operation `json:"operations,omitempty"`
+
+	// LatencyProbability is the probability of delaying an operation by
+	// Latency.
+	LatencyProbability float64         `json:"latencyProbability,omitempty"`
+	Latency            metav1.Duration `json:"latency,omitempty"`
+
+	// ErrorProbability is the probability of failing an operation with
+	// Error, without performing it.
+	ErrorProbability float64   `json:"errorProbability,omitempty"`
+	Error            ErrorType `json:"error,omitempty"`
+
+	// DropWatchEventProbability is the probability of dropping each event
+	// of a watch.
+	DropWatchEventProbability float64 `json:"dropWatchEventProbability,omitempty"`
+	// CloseWatchProbability is the probability of closing a watch after
+	// each of its events, as if the watch of the storage was lost.
+	CloseWatchProbability float64 `json:"closeWatchProbability,omitempty"`
+}
+
+// matches returns whether the rule applies to op of resource.
+func (r *Rule) matches(resource schema.GroupResource, op Operation) bool {
+	if r.Resource != AllResources && schema.ParseGroupResource(r.Resource) != resource {
+		return false
+	}
+	if len(r.Operations) == 0 {
+		return true
+	}
+	for _, o := range r.Operations {
+		if o == op {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate returns an error if the configuration is invalid.
+func (c *Config) Validate() error {
+	for i, r := range c.Rules {
+		if len(r.Resource) == 0 {
+			return fmt.Errorf("rules[%d].resource is required", i)
+		}
+		for _, op := range r.Operations {
+			if !operations.Has(string(op)) {
+				return fmt.Errorf("rules[%d].operations: unsupported operation %q, must be one of %v", i, op, operations.List())
+			}
+		}
+		for name, p := range map[string]float64{
+			"latencyProbability":        r.LatencyProbability,
+			"errorProbability":          r.ErrorProbability,
+			"dropWatchEventProbability": r.DropWatchEventProbability,
+			"closeWatchProbability":     r.CloseWatchProbability,
+		} {
+			if p < 0 || p > 1 {
+				return fmt.Errorf("rules[%d].%s must be between 0 and 1, got %v", i, name, p)
+			}
+		}
+		if r.Latency.Duration < 0 {
+			return fmt.Errorf("rules[%d].latency must not be negative", i)
+		}
+		if r.ErrorProbability > 0 {
+			switch r.Error {
+			case ErrorConflict, ErrorTimeout, ErrorUnavailable:
+			default:
+				return fmt.Errorf("rules[%d].error: unsupported error %q, must be one of %q, %q or %q", i, r.Error, ErrorConflict, ErrorTimeout, ErrorUnavailable)
+			}
+		}
+	}
+	return nil
+}
+
+// ParseConfig parses a YAML or JSON fault injection configuration and
+// validates it.
+func ParseConfig(data []byte) (*Config, error) {
+	config := &Config{}
+	if err := yaml.UnmarshalStrict(data, config); err != nil {
+		return nil, err
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// LoadConfig reads and parses the fault injection configuration file at path.
+func LoadConfig(path string) (*Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fault injection configuration %q: %v", path, err)
+	}
+	config, err := ParseConfig(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid fault injection configuration %q: %v", path, err)
+	}
+	return config, nil
+}
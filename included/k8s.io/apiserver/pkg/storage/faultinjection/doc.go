@@ -0,0 +1,22 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package faultinjection decorates storages to inject faults in their
+// operations for resilience testing: latency, errors, dropped watch events and
+// forced watch closures, with configurable probabilities. The faults are
+// driven by rules that can be loaded from a file and replaced at runtime, and
+// every injected fault is recorded in the audit annotations of the request.
+package faultinjection // import "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/faultinjection"
@@ -0,0 +1,131 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package faultinjection
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Injector decides the faults to inject in the operations of the storages
+// decorated with it, by the rules of its configuration.
+type Injector struct {
+	lock    sync.RWMutex
+	enabled bool
+	config  Config
+	// random returns a number in [0, 1); faults are injected when it is
+	// lower than their probability.
+	random func() float64
+}
+
+var defaultInjector = NewInjector()
+
+// DefaultInjector returns the injector shared by the storages of the server,
+// which the fault injection configuration file and debug API configure.
+func DefaultInjector() *Injector {
+	return defaultInjector
+}
+
+// NewInjector returns an injector without rules.
+func NewInjector() *Injector {
+	return &Injector{random: rand.Float64}
+}
+
+// Enabled returns whether the storages are decorated with the injector.
+func (i *Injector) Enabled() bool {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	return i.enabled
+}
+
+// SetEnabled records whether the storages are decorated with the injector.
+func (i *Injector) SetEnabled(enabled bool) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	i.enabled = enabled
+}
+
+// Config returns the configuration of the injector.
+func (i *Injector) Config() *Config {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	return &Config{Rules: append([]Rule{}, i.config.Rules...)}
+}
+
+// SetConfig replaces the configuration of the injector. The new rules apply
+// to the operations started after it, and to the open watches.
+func (i *Injector) SetConfig(config *Config) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	i.config = Config{Rules: append([]Rule{}, config.Rules...)}
+	return nil
+}
+
+// happens returns whether a fault with probability p is injected.
+func (i *Injector) happens(p float64) bool {
+	return p > 0 && i.random() < p
+}
+
+// faults are the faults to inject in an operation.
+type faults struct {
+	latency time.Duration
+	err     ErrorType
+}
+
+// operationFaults returns the faults to inject in op of resource. The
+// latencies of all the matching rules add up, and the error is the one of the
+// first matching rule that injects one.
+func (i *Injector) operationFaults(resource schema.GroupResource, op Operation) faults {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	var f faults
+	for j := range i.config.Rules {
+		r := &i.config.Rules[j]
+		if !r.matches(resource, op) {
+			continue
+		}
+		if i.happens(r.LatencyProbability) {
+			f.latency += r.Latency.Duration
+		}
+		if len(f.err) == 0 && i.happens(r.ErrorProbability) {
+			f.err = r.Error
+		}
+	}
+	return f
+}
+
+// watchEventFaults returns whether to drop an event of a watch of resource,
+// and whether to close the watch after it.
+func (i *Injector) watchEventFaults(resource schema.GroupResource) (drop, close bool) {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	for j := range i.config.Rules {
+		r := &i.config.Rules[j]
+		if !r.matches(resource, OperationWatch) {
+			continue
+		}
+		drop = drop || i.happens(r.DropWatchEventProbability)
+		close = close || i.happens(r.CloseWatchProbability)
+	}
+	return drop, close
+}
@@ -0,0 +1,41 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package faultinjection
+
+import (
+	"sync"
+
+	"github.com/aaron-prindle/krmapiserver/included/github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	injectedFaults = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "apiserver_storage_injected_faults_total",
+			Help: "Counter of the faults injected in storage operations split by resource, operation and fault.",
+		},
+		[]string{"resource", "operation", "fault"},
+	)
+)
+
+var registerMetricsOnce sync.Once
+
+func registerMetrics() {
+	registerMetricsOnce.Do(func() {
+		prometheus.MustRegister(injectedFaults)
+	})
+}
@@ -0,0 +1,201 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package faultinjection
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	apierrors "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/api/errors"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime/schema"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/watch"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/audit"
+	genericapirequest "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/endpoints/request"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/usage"
+)
+
+// annotationPrefix is the prefix of the audit annotations the injected faults
+// are recorded in, as <prefix><operation>-<fault>.
+const annotationPrefix = "faultinjection.storage.k8s.io/"
+
+// annotationLock serializes the audit annotations of the injected faults, as
+// the operations of a request, such as the deletions of a collection, may run
+// concurrently.
+var annotationLock sync.Mutex
+
+// store injects faults in the operations of the storage it decorates.
+type store struct {
+	storage.Interface
+	resource schema.GroupResource
+	injector *Injector
+}
+
+var _ storage.Interface = &store{}
+var _ storage.Unwrapper = &store{}
+
+// New returns a storage that injects faults in the operations of s, the
+// storage of resource, by the rules of injector.
+func New(s storage.Interface, resource schema.GroupResource, injector *Injector) storage.Interface {
+	registerMetrics()
+	return &store{Interface: s, resource: resource, injector: injector}
+}
+
+// inject injects the faults of op. It returns the injected error, if any.
+// If ctx is done while the injected latency is awaited, inject returns the
+// error of ctx, which is not an injected fault and is not recorded as one.
+func (s *store) inject(ctx context.Context, op Operation, key string) error {
+	f := s.injector.operationFaults(s.resource, op)
+	if f.latency > 0 {
+		s.record(ctx, op, "latency", f.latency.String())
+		t := time.NewTimer(f.latency)
+		defer t.Stop()
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	switch f.err {
+	case ErrorConflict:
+		s.record(ctx, op, "error", string(f.err))
+		return storage.NewResourceVersionConflictsError(key, 0)
+	case ErrorTimeout:
+		s.record(ctx, op, "error", string(f.err))
+		return storage.NewUnreachableError(key, 0)
+	case ErrorUnavailable:
+		s.record(ctx, op, "error", string(f.err))
+		return apierrors.NewServiceUnavailable(fmt.Sprintf("injected fault: storage of %s unavailable", s.resource))
+	}
+	return nil
+}
+
+// record records a fault injected in op in the metrics and in the audit
+// annotations of the request.
+func (s *store) record(ctx context.Context, op Operation, fault, value string) {
+	injectedFaults.WithLabelValues(s.resource.String(), string(op), fault).Inc()
+	annotationLock.Lock()
+	defer annotationLock.Unlock()
+	audit.LogAnnotation(genericapirequest.AuditEventFrom(ctx), annotationPrefix+string(op)+"-"+fault, value)
+}
+
+func (s *store) Create(ctx context.Context, key string, obj, out runtime.Object, ttl uint64) error {
+	if err := s.inject(ctx, OperationCreate, key); err != nil {
+		return err
+	}
+	return s.Interface.Create(ctx, key, obj, out, ttl)
+}
+
+func (s *store) Delete(ctx context.Context, key string, out runtime.Object, preconditions *storage.Preconditions, validateDeletion storage.ValidateObjectFunc) error {
+	if err := s.inject(ctx, OperationDelete, key); err != nil {
+		return err
+	}
+	return s.Interface.Delete(ctx, key, out, preconditions, validateDeletion)
+}
+
+func (s *store) Watch(ctx context.Context, key string, resourceVersion string, p storage.SelectionPredicate) (watch.Interface, error) {
+	if err := s.inject(ctx, OperationWatch, key); err != nil {
+		return nil, err
+	}
+	w, err := s.Interface.Watch(ctx, key, resourceVersion, p)
+	if err != nil {
+		return nil, err
+	}
+	return newWatcher(ctx, w, s), nil
+}
+
+func (s *store) WatchList(ctx context.Context, key string, resourceVersion string, p storage.SelectionPredicate) (watch.Interface, error) {
+	if err := s.inject(ctx, OperationWatch, key); err != nil {
+		return nil, err
+	}
+	w, err := s.Interface.WatchList(ctx, key, resourceVersion, p)
+	if err != nil {
+		return nil, err
+	}
+	return newWatcher(ctx, w, s), nil
+}
+
+func (s *store) Get(ctx context.Context, key string, resourceVersion string, objPtr runtime.Object, ignoreNotFound bool) error {
+	if err := s.inject(ctx, OperationGet, key); err != nil {
+		return err
+	}
+	return s.Interface.Get(ctx, key, resourceVersion, objPtr, ignoreNotFound)
+}
+
+func (s *store) GetToList(ctx context.Context, key string, resourceVersion string, p storage.SelectionPredicate, listObj runtime.Object) error {
+	if err := s.inject(ctx, OperationGet, key); err != nil {
+		return err
+	}
+	return s.Interface.GetToList(ctx, key, resourceVersion, p, listObj)
+}
+
+func (s *store) List(ctx context.Context, key string, resourceVersion string, p storage.SelectionPredicate, listObj runtime.Object) error {
+	if err := s.inject(ctx, OperationList, key); err != nil {
+		return err
+	}
+	return s.Interface.List(ctx, key, resourceVersion, p, listObj)
+}
+
+func (s *store) GuaranteedUpdate(
+	ctx context.Context, key string, ptrToType runtime.Object, ignoreNotFound bool,
+	preconditions *storage.Preconditions, tryUpdate storage.UpdateFunc, suggestion ...runtime.Object) error {
+	if err := s.inject(ctx, OperationUpdate, key); err != nil {
+		return err
+	}
+	return s.Interface.GuaranteedUpdate(ctx, key, ptrToType, ignoreNotFound, preconditions, tryUpdate, suggestion...)
+}
+
+func (s *store) Count(key string) (int64, error) {
+	if err := s.inject(context.TODO(), OperationCount, key); err != nil {
+		return 0, err
+	}
+	return s.Interface.Count(key)
+}
+
+func (s *store) Transact(ctx context.Context, ops []storage.TxnOp) error {
+	if err := s.inject(ctx, OperationTransact, ""); err != nil {
+		return err
+	}
+	// The transaction is committed by the decorated storage, which unwraps
+	// the storages of the other keys.
+	return s.Interface.Transact(ctx, ops)
+}
+
+// Unwrap implements storage.Unwrapper.
+func (s *store) Unwrap() storage.Interface {
+	return s.Interface
+}
+
+// NamespaceUsage implements usage.Reporter by the usage of the decorated
+// storage.
+func (s *store) NamespaceUsage() (map[string]usage.Usage, bool) {
+	if r, ok := s.Interface.(usage.Reporter); ok {
+		return r.NamespaceUsage()
+	}
+	return nil, false
+}
+
+// StoredSize implements usage.Sizer by the size in the decorated storage.
+func (s *store) StoredSize(obj runtime.Object) (int64, error) {
+	if sizer, ok := s.Interface.(usage.Sizer); ok {
+		return sizer.StoredSize(obj)
+	}
+	return 0, fmt.Errorf("the decorated storage does not account the usage")
+}
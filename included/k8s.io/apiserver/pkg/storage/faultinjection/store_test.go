@@ -0,0 +1,259 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package faultinjection
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/core/v1"
+	apierrors "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/api/errors"
+	metav1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime/schema"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/watch"
+	auditinternal "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/apis/audit"
+	genericapirequest "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/endpoints/request"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/embedded"
+	storagetesting "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/testing"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/value"
+)
+
+var pods = schema.GroupResource{Resource: "pods"}
+
+func newTestStore(t *testing.T, rules ...Rule) (storage.Interface, func()) {
+	injector := NewInjector()
+	// Inject every fault with a non-zero probability.
+	injector.random = func() float64 { return 0 }
+	if err := injector.SetConfig(&Config{Rules: rules}); err != nil {
+		t.Fatal(err)
+	}
+	backend := embedded.NewMemory()
+	s := embedded.New(backend, storagetesting.Codec, "", value.IdentityTransformer, true)
+	return New(s, pods, injector), func() { backend.Close() }
+}
+
+func newAuditContext() (context.Context, *auditinternal.Event) {
+	ae := &auditinternal.Event{Level: auditinternal.LevelMetadata}
+	return genericapirequest.WithAuditEvent(context.Background(), ae), ae
+}
+
+func newPod(name string) *corev1.Pod {
+	return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: name}}
+}
+
+func TestParseConfig(t *testing.T) {
+	config, err := ParseConfig([]byte(`
+rules:
+- resource: deployments.apps
+  operations: [update, create]
+  latencyProbability: 0.5
+  latency: 200ms
+  errorProbability: 0.1
+  error: Conflict
+- resource: "*"
+  operations: [watch]
+  dropWatchEventProbability: 0.01
+  closeWatchProbability: 0.001
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(config.Rules) != 2 || config.Rules[0].Latency.Duration != 200*time.Millisecond || config.Rules[1].CloseWatchProbability != 0.001 {
+		t.Errorf("unexpected config %+v", config)
+	}
+	if !config.Rules[0].matches(schema.GroupResource{Group: "apps", Resource: "deployments"}, OperationCreate) {
+		t.Errorf("expected rule to match creations of deployments.apps")
+	}
+	if config.Rules[0].matches(schema.GroupResource{Group: "apps", Resource: "deployments"}, OperationGet) {
+		t.Errorf("expected rule not to match gets")
+	}
+	if !config.Rules[1].matches(pods, OperationWatch) {
+		t.Errorf("expected rule to match watches of all resources")
+	}
+
+	for _, invalid := range []string{
+		`rules: [{operations: [get]}]`,
+		`rules: [{resource: pods, operations: [patch]}]`,
+		`rules: [{resource: pods, errorProbability: 1.5, error: Conflict}]`,
+		`rules: [{resource: pods, errorProbability: 1, error: NotFound}]`,
+		`rules: [{resource: pods, latency: -1s}]`,
+		`rules: [{resource: pods, unknown: true}]`,
+	} {
+		if _, err := ParseConfig([]byte(invalid)); err == nil {
+			t.Errorf("expected %q to be invalid", invalid)
+		}
+	}
+}
+
+func TestInjectedErrors(t *testing.T) {
+	for _, tc := range []struct {
+		err   ErrorType
+		check func(error) bool
+	}{
+		{ErrorConflict, storage.IsConflict},
+		{ErrorTimeout, storage.IsUnreachable},
+		{ErrorUnavailable, apierrors.IsServiceUnavailable},
+	} {
+		t.Run(string(tc.err), func(t *testing.T) {
+			s, destroy := newTestStore(t, Rule{
+				Resource:         "pods",
+				Operations:       []Operation{OperationCreate},
+				ErrorProbability: 1,
+				Error:            tc.err,
+			})
+			defer destroy()
+
+			ctx, ae := newAuditContext()
+			err := s.Create(ctx, "/pods/ns/a", newPod("a"), &corev1.Pod{}, 0)
+			if !tc.check(err) {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if v := ae.Annotations[annotationPrefix+"create-error"]; v != string(tc.err) {
+				t.Errorf("expected the error to be recorded in the audit annotations, got %v", ae.Annotations)
+			}
+			if err := s.Get(ctx, "/pods/ns/a", "", &corev1.Pod{}, true); err != nil {
+				t.Errorf("expected gets not to fail, got %v", err)
+			}
+		})
+	}
+}
+
+func TestInjectedLatency(t *testing.T) {
+	latency := 100 * time.Millisecond
+	s, destroy := newTestStore(t, Rule{
+		Resource:           "pods",
+		LatencyProbability: 1,
+		Latency:            metav1.Duration{Duration: latency},
+	})
+	defer destroy()
+
+	ctx, ae := newAuditContext()
+	start := time.Now()
+	if err := s.Get(ctx, "/pods/ns/a", "", &corev1.Pod{}, true); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < latency {
+		t.Errorf("expected a latency of at least %v, got %v", latency, elapsed)
+	}
+	if v := ae.Annotations[annotationPrefix+"get-latency"]; v != latency.String() {
+		t.Errorf("expected the latency to be recorded in the audit annotations, got %v", ae.Annotations)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := s.Get(ctx, "/pods/ns/a", "", &corev1.Pod{}, true); err != context.Canceled {
+		t.Errorf("expected canceled operations to fail with the error of their context, got %v", err)
+	}
+}
+
+func TestDroppedWatchEvents(t *testing.T) {
+	s, destroy := newTestStore(t, Rule{
+		Resource:                  "pods",
+		Operations:                []Operation{OperationWatch},
+		DropWatchEventProbability: 1,
+	})
+	defer destroy()
+
+	ctx, ae := newAuditContext()
+	w, err := s.WatchList(ctx, "/pods", "0", storage.Everything)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a", "b"} {
+		if err := s.Create(context.Background(), "/pods/ns/"+name, newPod(name), &corev1.Pod{}, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	select {
+	case event := <-w.ResultChan():
+		t.Fatalf("unexpected event %v", event)
+	case <-time.After(200 * time.Millisecond):
+	}
+	w.Stop()
+	if v := ae.Annotations[annotationPrefix+"watch-dropped-events"]; v != "2" {
+		t.Errorf("expected the dropped events to be recorded in the audit annotations, got %v", ae.Annotations)
+	}
+}
+
+func TestClosedWatch(t *testing.T) {
+	s, destroy := newTestStore(t, Rule{
+		Resource:              "pods",
+		Operations:            []Operation{OperationWatch},
+		CloseWatchProbability: 1,
+	})
+	defer destroy()
+
+	ctx, ae := newAuditContext()
+	w, err := s.WatchList(ctx, "/pods", "0", storage.Everything)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a", "b"} {
+		if err := s.Create(context.Background(), "/pods/ns/"+name, newPod(name), &corev1.Pod{}, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var events []watch.Event
+	timeout := time.After(5 * time.Second)
+	for done := false; !done; {
+		select {
+		case event, ok := <-w.ResultChan():
+			if !ok {
+				done = true
+				break
+			}
+			events = append(events, event)
+		case <-timeout:
+			t.Fatalf("timed out waiting for the watch to be closed")
+		}
+	}
+	if len(events) != 1 || events[0].Object.(*corev1.Pod).Name != "a" {
+		t.Errorf("expected the watch to be closed after its first event, got %v", events)
+	}
+	w.Stop()
+	if v := ae.Annotations[annotationPrefix+"watch-closed"]; v != "true" {
+		t.Errorf("expected the closure to be recorded in the audit annotations, got %v", ae.Annotations)
+	}
+}
+
+func TestTransactWithDecoratedStorages(t *testing.T) {
+	backend := embedded.NewMemory()
+	defer backend.Close()
+	undecorated := embedded.New(backend, storagetesting.Codec, "", value.IdentityTransformer, true)
+	decorated := New(embedded.New(backend, storagetesting.Codec, "", value.IdentityTransformer, true), pods, NewInjector())
+
+	// Both the decorated storage committing a transaction and the one
+	// committing it for a decorated storage unwrap the storages of the keys.
+	for name, committer := range map[string]storage.Interface{"a": decorated, "b": undecorated} {
+		ops := []storage.TxnOp{
+			{Type: storage.TxnCreate, Storage: decorated, Key: "/pods/ns/" + name, Obj: newPod(name), Out: &corev1.Pod{}},
+			{Type: storage.TxnCreate, Storage: undecorated, Key: "/configmaps/ns/" + name, Obj: newPod(name), Out: &corev1.Pod{}},
+		}
+		if err := committer.Transact(context.Background(), ops); err != nil {
+			t.Fatalf("Transact failed: %v", err)
+		}
+	}
+	count, err := undecorated.Count("/pods")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Errorf("expected 2 pods, got %d", count)
+	}
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package faultinjection
+
+import (
+	"context"
+	"strconv"
+	"sync"
+
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/watch"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/audit"
+	genericapirequest "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/endpoints/request"
+)
+
+// watcher drops the events of the watch it decorates and closes it by the
+// watch rules of its store. The faults are recorded in the audit annotations
+// of the request when the watcher is stopped, which the watch handler does
+// before the request completes.
+type watcher struct {
+	ctx      context.Context
+	w        watch.Interface
+	store    *store
+	result   chan watch.Event
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+	stopOnce sync.Once
+
+	// dropped and closed are only written by run, and read once doneCh is
+	// closed.
+	dropped int
+	closed  bool
+}
+
+func newWatcher(ctx context.Context, w watch.Interface, s *store) *watcher {
+	wc := &watcher{
+		ctx:    ctx,
+		w:      w,
+		store:  s,
+		result: make(chan watch.Event),
+		stopCh: make(chan struct{}),
+		doneCh: make(chan struct{}),
+	}
+	go wc.run()
+	return wc
+}
+
+func (wc *watcher) run() {
+	defer close(wc.doneCh)
+	defer close(wc.result)
+	defer wc.w.Stop()
+	for {
+		var event watch.Event
+		var ok bool
+		select {
+		case event, ok = <-wc.w.ResultChan():
+			if !ok {
+				return
+			}
+		case <-wc.stopCh:
+			return
+		}
+		drop, close := false, false
+		switch event.Type {
+		case watch.Added, watch.Modified, watch.Deleted:
+			drop, close = wc.store.injector.watchEventFaults(wc.store.resource)
+		}
+		if drop {
+			wc.dropped++
+			injectedFaults.WithLabelValues(wc.store.resource.String(), string(OperationWatch), "dropped-event").Inc()
+		} else {
+			select {
+			case wc.result <- event:
+			case <-wc.stopCh:
+				return
+			}
+		}
+		if close {
+			wc.closed = true
+			injectedFaults.WithLabelValues(wc.store.resource.String(), string(OperationWatch), "closed").Inc()
+			return
+		}
+	}
+}
+
+func (wc *watcher) ResultChan() <-chan watch.Event {
+	return wc.result
+}
+
+func (wc *watcher) Stop() {
+	wc.stopOnce.Do(func() {
+		close(wc.stopCh)
+		<-wc.doneCh
+		annotationLock.Lock()
+		defer annotationLock.Unlock()
+		ae := genericapirequest.AuditEventFrom(wc.ctx)
+		if wc.dropped > 0 {
+			audit.LogAnnotation(ae, annotationPrefix+string(OperationWatch)+"-dropped-events", strconv.Itoa(wc.dropped))
+		}
+		if wc.closed {
+			audit.LogAnnotation(ae, annotationPrefix+string(OperationWatch)+"-closed", "true")
+		}
+	})
+}
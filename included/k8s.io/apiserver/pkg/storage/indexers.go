@@ -0,0 +1,91 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"fmt"
+
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/api/meta"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/client-go/tools/cache"
+)
+
+// NamespaceIndex is the name of the index of objects by namespace.
+var NamespaceIndex = FieldIndex("metadata.namespace")
+
+// FieldIndex returns the name of the index of objects by the value of the
+// given field, as used in MatchValues and in the indexers of the watch cache.
+func FieldIndex(field string) string {
+	return "f:" + field
+}
+
+// LabelIndex returns the name of the index of objects by the value of the
+// given label, as used in MatchValues and in the indexers of the watch cache.
+func LabelIndex(label string) string {
+	return "l:" + label
+}
+
+// NamespaceIndexFunc is the index function of NamespaceIndex.
+func NamespaceIndexFunc(obj interface{}) ([]string, error) {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, err
+	}
+	return []string{accessor.GetNamespace()}, nil
+}
+
+// LabelIndexFunc returns the index function of LabelIndex(label). Objects
+// without the label are not indexed.
+func LabelIndexFunc(label string) cache.IndexFunc {
+	return func(obj interface{}) ([]string, error) {
+		accessor, err := meta.Accessor(obj)
+		if err != nil {
+			return nil, err
+		}
+		if value, ok := accessor.GetLabels()[label]; ok {
+			return []string{value}, nil
+		}
+		return nil, nil
+	}
+}
+
+// TriggerIndexers returns the indexers that index objects like trigger. The
+// index names of a trigger are the fields it indexes objects by, e.g.
+// "spec.nodeName", and it returns the same ones for every object, so they are
+// taken from what trigger returns for sample. They become FieldIndex names.
+//
+// Deprecated: TriggerIndexers only adapts TriggerPublisherFuncs, new code
+// should define cache.Indexers.
+func TriggerIndexers(trigger TriggerPublisherFunc, sample runtime.Object) cache.Indexers {
+	indexers := cache.Indexers{}
+	for _, matchValue := range trigger(sample) {
+		indexName := matchValue.IndexName
+		indexers[FieldIndex(indexName)] = func(obj interface{}) ([]string, error) {
+			object, ok := obj.(runtime.Object)
+			if !ok {
+				return nil, fmt.Errorf("expected a runtime.Object, got %T", obj)
+			}
+			for _, matchValue := range trigger(object) {
+				if matchValue.IndexName == indexName {
+					return []string{matchValue.Value}, nil
+				}
+			}
+			return nil, nil
+		}
+	}
+	return indexers
+}
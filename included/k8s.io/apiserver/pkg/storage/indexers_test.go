@@ -0,0 +1,52 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestTriggerIndexers(t *testing.T) {
+	trigger := func(obj runtime.Object) []MatchValue {
+		return []MatchValue{{IndexName: "id", Value: obj.(*Ignored).ID}}
+	}
+	indexers := TriggerIndexers(trigger, &Ignored{})
+	if len(indexers) != 1 {
+		t.Fatalf("expected a single indexer, got %v", indexers)
+	}
+	indexFunc, ok := indexers[FieldIndex("id")]
+	if !ok {
+		t.Fatalf("expected an indexer named %q, got %v", FieldIndex("id"), indexers)
+	}
+	values, err := indexFunc(&Ignored{ID: "foo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"foo"}; !reflect.DeepEqual(values, expected) {
+		t.Errorf("expected %v, got %v", expected, values)
+	}
+	if _, err := indexFunc("foo"); err == nil {
+		t.Errorf("expected an error for an object that is not a runtime.Object")
+	}
+
+	if indexers := TriggerIndexers(NoTriggerPublisher, &Ignored{}); len(indexers) != 0 {
+		t.Errorf("expected no indexers, got %v", indexers)
+	}
+}
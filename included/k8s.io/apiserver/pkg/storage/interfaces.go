@@ -82,6 +82,9 @@ type MatchValue struct {
 // TriggerPublisherFunc is a function that takes an object, and returns a list of pairs
 // (<index name>, <index value for the given object>) for all indexes known
 // to that function.
+//
+// Deprecated: index the cached objects with cache.Indexers instead; see
+// TriggerIndexers.
 type TriggerPublisherFunc func(obj runtime.Object) []MatchValue
 
 // Everything accepts all objects.
@@ -150,6 +153,45 @@ func (p *Preconditions) Check(key string, obj runtime.Object) error {
 	return nil
 }
 
+// TxnOpType is the type of an operation of a transaction.
+type TxnOpType string
+
+const (
+	// TxnCreate creates the object at a key that must not exist.
+	TxnCreate TxnOpType = "Create"
+	// TxnUpdate replaces the object at a key that must exist.
+	TxnUpdate TxnOpType = "Update"
+	// TxnDelete deletes the object at a key that must exist.
+	TxnDelete TxnOpType = "Delete"
+)
+
+// MaxTxnAttempts is how often Interface.Transact tries to commit a transaction
+// whose keys keep being changed by other writers, before it gives up with a
+// resource version conflict of the first changed key.
+const MaxTxnAttempts = 10
+
+// TxnOp is a single operation of a transaction committed by
+// Interface.Transact.
+type TxnOp struct {
+	Type TxnOpType
+	// Storage is the storage the key belongs to, or nil for the storage
+	// committing the transaction. The storages of all operations of a
+	// transaction must be served by the same backend, e.g. the same etcd
+	// cluster, once they are unwrapped by Unwrap.
+	Storage Interface
+	Key     string
+	// Obj is the object to create, or the new state of the object to
+	// update. It is not set for deletions.
+	Obj runtime.Object
+	// Out is set to the object as stored by the transaction, or to the
+	// deleted object for deletions. It must be a pointer to an object of
+	// the type stored at the key.
+	Out runtime.Object
+	// Preconditions are checked against the current object at the key of
+	// updates and deletions when the transaction is committed.
+	Preconditions *Preconditions
+}
+
 // Interface offers a common interface for object marshaling/unmarshaling operations and
 // hides all the storage-related operations behind it.
 type Interface interface {
@@ -239,4 +281,50 @@ type Interface interface {
 
 	// Count returns number of different entries under the key (generally being path prefix).
 	Count(key string) (int64, error)
+
+	// Transact atomically applies ops: either all of them are committed in a
+	// single resourceVersion, or none of them is. Creations fail if their key
+	// exists, updates and deletions if their key doesn't exist or their
+	// preconditions are not met, in which case a TxnOpError is returned. As in
+	// GuaranteedUpdate, the transaction is retried if other writers change one
+	// of its keys while it is being committed, up to MaxTxnAttempts times and
+	// until ctx is done.
+	Transact(ctx context.Context, ops []TxnOp) error
+}
+
+// Unwrapper is implemented by storages that decorate another storage, e.g. to
+// cache it.
+type Unwrapper interface {
+	// Unwrap returns the decorated storage.
+	Unwrap() Interface
+}
+
+// Unwrap returns the storage decorated by s and all decorators of it, or s if it
+// doesn't decorate another storage.
+func Unwrap(s Interface) Interface {
+	for {
+		u, ok := s.(Unwrapper)
+		if !ok {
+			return s
+		}
+		s = u.Unwrap()
+	}
+}
+
+// ChangeDetector is implemented by storages that can tell whether objects under a
+// key were changed after a resourceVersion without listing them.
+type ChangeDetector interface {
+	// ChangedSince returns the current resourceVersion of the storage and whether an
+	// object under key was created, updated or deleted after resourceVersion. If
+	// that cannot be determined, e.g. because resourceVersion was compacted,
+	// changed is true.
+	ChangedSince(ctx context.Context, key string, resourceVersion uint64) (current uint64, changed bool, err error)
+}
+
+// KeyLister is implemented by storages that can list the keys of the objects
+// under a key without reading the objects.
+type KeyLister interface {
+	// ListKeys returns the keys of the objects under key, in order. They are
+	// relative to the prefix of the storage, like the keys it is passed.
+	ListKeys(ctx context.Context, key string) ([]string, error)
 }
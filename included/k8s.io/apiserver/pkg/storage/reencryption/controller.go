@@ -0,0 +1,264 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package reencryption rewrites the objects of resources in storage, so that
+// they are encrypted with the current primary provider of their transformer.
+package reencryption
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/klog"
+
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/api/meta"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/fields"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/labels"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime/schema"
+	utilruntime "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/util/runtime"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/util/wait"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/rewrite"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/client-go/util/workqueue"
+)
+
+const (
+	// defaultPageSize is the number of objects listed from storage at once.
+	defaultPageSize = 500
+	// maxRetries is how often the rewrite of a resource is retried before
+	// it is given up until the next configuration change.
+	maxRetries = 5
+)
+
+// State is the state of the rewrite of the objects of a resource.
+type State string
+
+const (
+	StatePending   State = "Pending"
+	StateRunning   State = "Running"
+	StateCompleted State = "Completed"
+	StateFailed    State = "Failed"
+)
+
+// ResourceStatus is the progress of the rewrite of the objects of a resource.
+type ResourceStatus struct {
+	Resource string `json:"resource"`
+	State    State  `json:"state"`
+	// Rewritten is the number of objects written under the current primary
+	// provider by the last run.
+	Rewritten int64 `json:"rewritten"`
+	// Unchanged is the number of objects that already were encrypted with
+	// the current primary provider, or were deleted in the meantime.
+	Unchanged int64 `json:"unchanged"`
+	// Failed is the number of objects that could not be rewritten.
+	Failed         int64      `json:"failed"`
+	StartTime      *time.Time `json:"startTime,omitempty"`
+	CompletionTime *time.Time `json:"completionTime,omitempty"`
+	LastError      string     `json:"lastError,omitempty"`
+}
+
+var (
+	statusesLock sync.RWMutex
+	statuses     = map[schema.GroupResource]*ResourceStatus{}
+)
+
+// Statuses returns the progress of the rewrites of all resources the
+// controller was asked to rewrite, sorted by resource.
+func Statuses() []ResourceStatus {
+	statusesLock.RLock()
+	defer statusesLock.RUnlock()
+	result := make([]ResourceStatus, 0, len(statuses))
+	for _, status := range statuses {
+		result = append(result, *status)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Resource < result[j].Resource })
+	return result
+}
+
+func updateStatus(gr schema.GroupResource, update func(status *ResourceStatus)) {
+	statusesLock.Lock()
+	defer statusesLock.Unlock()
+	status, ok := statuses[gr]
+	if !ok {
+		status = &ResourceStatus{Resource: gr.String()}
+		statuses[gr] = status
+	}
+	update(status)
+}
+
+// Controller rewrites all objects of the resources it is asked to, so that
+// they are stored encrypted with the current primary provider of their
+// transformer. Objects are rewritten with a no-op update, which the storage
+// only writes if the object was read with a provider other than the primary
+// one.
+type Controller struct {
+	queue    workqueue.RateLimitingInterface
+	pageSize int64
+}
+
+// NewController returns a controller that rewrites the objects of registered
+// resources.
+func NewController() *Controller {
+	registerMetrics()
+	return &Controller{
+		queue:    workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "reencryption"),
+		pageSize: defaultPageSize,
+	}
+}
+
+// Enqueue requests the rewrite of all objects of resources. It can be used
+// as a listener for changes of the encryption provider configuration.
+func (c *Controller) Enqueue(resources []schema.GroupResource) {
+	for _, gr := range resources {
+		updateStatus(gr, func(status *ResourceStatus) {
+			if status.State != StateRunning {
+				status.State = StatePending
+			}
+		})
+		c.queue.Add(gr)
+	}
+}
+
+// Run rewrites the enqueued resources one at a time until stopCh is closed.
+func (c *Controller) Run(stopCh <-chan struct{}) {
+	defer utilruntime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	klog.Infof("Starting re-encryption controller")
+	defer klog.Infof("Shutting down re-encryption controller")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-stopCh
+		cancel()
+	}()
+
+	go wait.Until(func() {
+		for c.processNextItem(ctx) {
+		}
+	}, time.Second, stopCh)
+
+	<-stopCh
+}
+
+func (c *Controller) processNextItem(ctx context.Context) bool {
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(key)
+
+	gr := key.(schema.GroupResource)
+	err := c.reencrypt(ctx, gr)
+	if err == nil {
+		c.queue.Forget(key)
+		return true
+	}
+	if c.queue.NumRequeues(key) < maxRetries {
+		klog.Warningf("Failed to re-encrypt %s, retrying: %v", gr, err)
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	klog.Errorf("Failed to re-encrypt %s, giving up: %v", gr, err)
+	c.queue.Forget(key)
+	return true
+}
+
+// reencrypt rewrites all objects of gr and records the progress.
+func (c *Controller) reencrypt(ctx context.Context, gr schema.GroupResource) (err error) {
+	start := time.Now()
+	updateStatus(gr, func(status *ResourceStatus) {
+		*status = ResourceStatus{Resource: gr.String(), State: StateRunning, StartTime: &start}
+	})
+	setInProgress(gr, true)
+	defer func() {
+		setInProgress(gr, false)
+		recordRun(gr, err)
+		now := time.Now()
+		updateStatus(gr, func(status *ResourceStatus) {
+			status.CompletionTime = &now
+			status.State = StateCompleted
+			status.LastError = ""
+			if err != nil {
+				status.State = StateFailed
+				status.LastError = err.Error()
+			}
+		})
+	}()
+
+	resource := rewrite.Lookup(gr)
+	if resource == nil {
+		return fmt.Errorf("resource %s is not served", gr)
+	}
+
+	var failed int64
+	continueToken := ""
+	for {
+		listObj := resource.NewListFunc()
+		pred := storage.SelectionPredicate{
+			Label:    labels.Everything(),
+			Field:    fields.Everything(),
+			Limit:    c.pageSize,
+			Continue: continueToken,
+		}
+		if err := resource.Storage.List(ctx, resource.ResourcePrefix, "", pred, listObj); err != nil {
+			return err
+		}
+		if err := meta.EachListItem(listObj, func(obj runtime.Object) error {
+			result := resultRewritten
+			rewritten, err := rewrite.Object(ctx, resource, obj)
+			switch {
+			case err != nil:
+				klog.V(2).Infof("Failed to re-encrypt an object of %s: %v", gr, err)
+				result = resultFailed
+				failed++
+			case !rewritten:
+				result = resultUnchanged
+			}
+			recordObject(gr, result)
+			updateStatus(gr, func(status *ResourceStatus) {
+				switch result {
+				case resultRewritten:
+					status.Rewritten++
+				case resultUnchanged:
+					status.Unchanged++
+				default:
+					status.Failed++
+				}
+			})
+			return ctx.Err()
+		}); err != nil {
+			return err
+		}
+
+		listMeta, err := meta.ListAccessor(listObj)
+		if err != nil {
+			return err
+		}
+		continueToken = listMeta.GetContinue()
+		if len(continueToken) == 0 {
+			break
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to re-encrypt %d objects", failed)
+	}
+	return nil
+}
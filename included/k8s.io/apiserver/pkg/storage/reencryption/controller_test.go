@@ -0,0 +1,126 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reencryption
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"testing"
+
+	corev1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/core/v1"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/api/meta"
+	metav1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime/schema"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/embedded"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/rewrite"
+	storagetesting "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/testing"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/value"
+)
+
+func prefixTransformer(prefixes ...string) value.Transformer {
+	var transformers []value.PrefixTransformer
+	for _, prefix := range prefixes {
+		transformers = append(transformers, value.PrefixTransformer{Prefix: []byte(prefix), Transformer: value.IdentityTransformer})
+	}
+	return value.NewPrefixTransformers(nil, transformers...)
+}
+
+func TestReencrypt(t *testing.T) {
+	b := embedded.NewMemory()
+	defer b.Close()
+	transformer := value.NewMutableTransformer(prefixTransformer("k1:"))
+	s := embedded.New(b, storagetesting.Codec, "", transformer, true)
+
+	gr := schema.GroupResource{Resource: "pods"}
+	resource := &rewrite.Resource{
+		GroupResource:  gr,
+		Storage:        s,
+		ResourcePrefix: "/pods",
+		KeyFunc: func(obj runtime.Object) (string, error) {
+			accessor, err := meta.Accessor(obj)
+			if err != nil {
+				return "", err
+			}
+			return path.Join("/pods", accessor.GetName()), nil
+		},
+		NewFunc:     func() runtime.Object { return &corev1.Pod{} },
+		NewListFunc: func() runtime.Object { return &corev1.PodList{} },
+	}
+	rewrite.Register(resource)
+	defer rewrite.Unregister(resource)
+
+	ctx := context.Background()
+	const count = 5
+	for i := 0; i < count; i++ {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("pod-%d", i), UID: "uid"}}
+		if err := s.Create(ctx, path.Join("/pods", pod.Name), pod, nil, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c := NewController()
+	c.pageSize = 2
+	status := func() ResourceStatus {
+		for _, status := range Statuses() {
+			if status.Resource == gr.String() {
+				return status
+			}
+		}
+		t.Fatalf("no status for %s", gr)
+		return ResourceStatus{}
+	}
+
+	// Nothing is written while the primary provider is unchanged.
+	if err := c.reencrypt(ctx, gr); err != nil {
+		t.Fatal(err)
+	}
+	if got := status(); got.State != StateCompleted || got.Rewritten != 0 || got.Unchanged != count {
+		t.Errorf("unexpected status before rotation: %#v", got)
+	}
+
+	// After a rotation every object is rewritten with the new primary
+	// provider, so that the old one can be removed.
+	transformer.Set(prefixTransformer("k2:", "k1:"))
+	if err := c.reencrypt(ctx, gr); err != nil {
+		t.Fatal(err)
+	}
+	if got := status(); got.State != StateCompleted || got.Rewritten != count || got.Unchanged != 0 {
+		t.Errorf("unexpected status after rotation: %#v", got)
+	}
+
+	transformer.Set(prefixTransformer("k2:"))
+	for i := 0; i < count; i++ {
+		if err := s.Get(ctx, path.Join("/pods", fmt.Sprintf("pod-%d", i)), "", &corev1.Pod{}, false); err != nil {
+			t.Errorf("object was not re-encrypted: %v", err)
+		}
+	}
+}
+
+func TestReencryptUnknownResource(t *testing.T) {
+	c := NewController()
+	gr := schema.GroupResource{Group: "example.com", Resource: "unknown"}
+	if err := c.reencrypt(context.Background(), gr); err == nil {
+		t.Fatal("expected an error for a resource that is not served")
+	}
+	for _, status := range Statuses() {
+		if status.Resource == gr.String() && status.State != StateFailed {
+			t.Errorf("unexpected state %q", status.State)
+		}
+	}
+}
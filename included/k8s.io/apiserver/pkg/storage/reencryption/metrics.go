@@ -0,0 +1,103 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reencryption
+
+import (
+	"sync"
+	"time"
+
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/aaron-prindle/krmapiserver/included/github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	resultRewritten = "rewritten"
+	resultUnchanged = "unchanged"
+	resultFailed    = "failed"
+)
+
+var (
+	objectsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "apiserver",
+			Subsystem: "storage_reencryption",
+			Name:      "objects_total",
+			Help:      "Number of objects processed by the re-encryption controller, split by resource and result.",
+		},
+		[]string{"resource", "result"},
+	)
+	runsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "apiserver",
+			Subsystem: "storage_reencryption",
+			Name:      "runs_total",
+			Help:      "Number of re-encryptions of all objects of a resource, split by resource and status.",
+		},
+		[]string{"resource", "status"},
+	)
+	lastCompletionTimestamp = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "apiserver",
+			Subsystem: "storage_reencryption",
+			Name:      "last_completion_timestamp_seconds",
+			Help:      "Timestamp of the last successful re-encryption of all objects of a resource.",
+		},
+		[]string{"resource"},
+	)
+	inProgress = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "apiserver",
+			Subsystem: "storage_reencryption",
+			Name:      "in_progress",
+			Help:      "1 if the objects of a resource are being re-encrypted, 0 otherwise.",
+		},
+		[]string{"resource"},
+	)
+)
+
+var registerOnce sync.Once
+
+func registerMetrics() {
+	registerOnce.Do(func() {
+		prometheus.MustRegister(objectsTotal)
+		prometheus.MustRegister(runsTotal)
+		prometheus.MustRegister(lastCompletionTimestamp)
+		prometheus.MustRegister(inProgress)
+	})
+}
+
+func recordObject(gr schema.GroupResource, result string) {
+	objectsTotal.WithLabelValues(gr.String(), result).Inc()
+}
+
+func recordRun(gr schema.GroupResource, err error) {
+	if err != nil {
+		runsTotal.WithLabelValues(gr.String(), "failure").Inc()
+		return
+	}
+	runsTotal.WithLabelValues(gr.String(), "success").Inc()
+	lastCompletionTimestamp.WithLabelValues(gr.String()).Set(float64(time.Now().Unix()))
+}
+
+func setInProgress(gr schema.GroupResource, running bool) {
+	value := 0.0
+	if running {
+		value = 1
+	}
+	inProgress.WithLabelValues(gr.String()).Set(value)
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package rewrite keeps track of the storage of the served resources, so
+// that their objects can be rewritten in storage, e.g. to encrypt them with a
+// new key or to encode them in a new storage version.
+package rewrite
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime/schema"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage"
+)
+
+// Resource is the storage of a resource whose objects can be rewritten.
+type Resource struct {
+	GroupResource schema.GroupResource
+	// Storage is the storage the objects of the resource are kept in.
+	Storage storage.Interface
+	// ResourcePrefix is the key all objects of the resource are stored under.
+	ResourcePrefix string
+	// KeyFunc returns the key of an object of the resource.
+	KeyFunc     func(obj runtime.Object) (string, error)
+	NewFunc     func() runtime.Object
+	NewListFunc func() runtime.Object
+}
+
+var (
+	resourcesLock sync.RWMutex
+	resources     = map[schema.GroupResource]*Resource{}
+)
+
+// Register makes the objects of resource available for rewriting. Only the
+// first storage registered for a group resource is used, later registrations
+// share the same objects.
+func Register(resource *Resource) {
+	resourcesLock.Lock()
+	defer resourcesLock.Unlock()
+	if _, ok := resources[resource.GroupResource]; !ok {
+		resources[resource.GroupResource] = resource
+	}
+}
+
+// Unregister removes resource again, e.g. when its storage is destroyed.
+func Unregister(resource *Resource) {
+	resourcesLock.Lock()
+	defer resourcesLock.Unlock()
+	if resources[resource.GroupResource] == resource {
+		delete(resources, resource.GroupResource)
+	}
+}
+
+// Lookup returns the registered storage of gr, or nil if gr is not served.
+func Lookup(gr schema.GroupResource) *Resource {
+	resourcesLock.RLock()
+	defer resourcesLock.RUnlock()
+	return resources[gr]
+}
+
+// Resources returns the group resources with a registered storage, sorted.
+func Resources() []schema.GroupResource {
+	resourcesLock.RLock()
+	defer resourcesLock.RUnlock()
+	result := make([]schema.GroupResource, 0, len(resources))
+	for gr := range resources {
+		result = append(result, gr)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].String() < result[j].String() })
+	return result
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rewrite
+
+import (
+	"context"
+
+	apierrors "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/api/errors"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/api/meta"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage"
+)
+
+// Object writes obj back to storage unchanged, which the storage turns into
+// an actual write only if the stored object is not encoded or encrypted the
+// way it would be written now. It returns whether obj was written. Objects
+// that were deleted or replaced in the meantime are not written.
+func Object(ctx context.Context, resource *Resource, obj runtime.Object) (bool, error) {
+	key, err := resource.KeyFunc(obj)
+	if err != nil {
+		return false, err
+	}
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return false, err
+	}
+	uid := accessor.GetUID()
+	out := resource.NewFunc()
+	err = resource.Storage.GuaranteedUpdate(ctx, key, out, false, &storage.Preconditions{UID: &uid},
+		func(existing runtime.Object, res storage.ResponseMeta) (runtime.Object, *uint64, error) {
+			if res.TTL <= 0 {
+				return existing, nil, nil
+			}
+			ttl := uint64(res.TTL)
+			return existing, &ttl, nil
+		})
+	switch {
+	case storage.IsNotFound(err), storage.IsInvalidObj(err), apierrors.IsNotFound(err):
+		// The object was deleted or replaced by a new one, which is written
+		// the current way anyway.
+		return false, nil
+	case err != nil:
+		return false, err
+	}
+	outAccessor, err := meta.Accessor(out)
+	if err != nil {
+		return false, err
+	}
+	return outAccessor.GetResourceVersion() != accessor.GetResourceVersion(), nil
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/fields"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
+)
+
+// ValidateSelectableField returns an error if path, e.g. spec.replicas, cannot be
+// exposed as a selectable field. Paths are dot separated field names, which must not
+// contain the characters of the field selector syntax.
+func ValidateSelectableField(path string) error {
+	for _, name := range strings.Split(path, ".") {
+		if len(name) == 0 {
+			return fmt.Errorf("invalid selectable field %q: field names must not be empty", path)
+		}
+		if strings.ContainsAny(name, "=!<>(), \\") {
+			return fmt.Errorf("invalid selectable field %q: field names must not contain any of =!<>(), or spaces", path)
+		}
+	}
+	return nil
+}
+
+// SelectsSelectableFields returns true if selector has a requirement on any of the
+// selectable fields paths, so that objects must be matched against their values.
+func SelectsSelectableFields(selector fields.Selector, paths []string) bool {
+	if selector == nil {
+		return false
+	}
+	for _, r := range selector.Requirements() {
+		for _, path := range paths {
+			if r.Field == path {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SelectableFieldsMutation returns a FieldMutationFunc that adds the values of the
+// JSON paths of objects to their fields, so that field selectors can select objects by
+// them, e.g. by spec.replicas>3. Unstructured objects are read as they are, while other
+// objects are encoded as JSON with encoder, which converts them to the version the
+// paths refer to. Only paths that reach a string, a number or a boolean add a field,
+// and fields already set by the AttrFunc are kept; objects are not encoded when the
+// AttrFunc set all of them.
+func SelectableFieldsMutation(paths []string, encoder runtime.Encoder) FieldMutationFunc {
+	split := make([][]string, 0, len(paths))
+	for _, path := range paths {
+		split = append(split, strings.Split(path, "."))
+	}
+	return func(obj runtime.Object, fieldSet fields.Set) error {
+		missing := false
+		for _, path := range paths {
+			if _, ok := fieldSet[path]; !ok {
+				missing = true
+				break
+			}
+		}
+		if !missing {
+			return nil
+		}
+		var content map[string]interface{}
+		if u, ok := obj.(runtime.Unstructured); ok {
+			content = u.UnstructuredContent()
+		} else {
+			if encoder == nil {
+				return fmt.Errorf("no encoder to evaluate the selectable fields of %T", obj)
+			}
+			data, err := runtime.Encode(encoder, obj)
+			if err != nil {
+				return err
+			}
+			decoder := json.NewDecoder(bytes.NewReader(data))
+			decoder.UseNumber()
+			if err := decoder.Decode(&content); err != nil {
+				return err
+			}
+		}
+		for i, path := range split {
+			if _, ok := fieldSet[paths[i]]; ok {
+				continue
+			}
+			if value, ok := selectableFieldValue(content, path); ok {
+				fieldSet[paths[i]] = value
+			}
+		}
+		return nil
+	}
+}
+
+// selectableFieldValue returns the value that path reaches in content as a field value.
+func selectableFieldValue(content map[string]interface{}, path []string) (string, bool) {
+	var value interface{} = content
+	for _, name := range path {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+		if value, ok = m[name]; !ok {
+			return "", false
+		}
+	}
+	switch value := value.(type) {
+	case string:
+		return value, true
+	case bool:
+		return strconv.FormatBool(value), true
+	case json.Number:
+		return value.String(), true
+	case int64:
+		return strconv.FormatInt(value, 10), true
+	case float64:
+		return strconv.FormatFloat(value, 'g', -1, 64), true
+	}
+	return "", false
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/fields"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+type selectableObject struct {
+	Spec selectableSpec `json:"spec"`
+}
+
+type selectableSpec struct {
+	Replicas int               `json:"replicas"`
+	Ratio    float64           `json:"ratio"`
+	Paused   bool              `json:"paused"`
+	Phase    string            `json:"phase"`
+	Selector map[string]string `json:"selector"`
+}
+
+func (obj *selectableObject) GetObjectKind() schema.ObjectKind { return schema.EmptyObjectKind }
+func (obj *selectableObject) DeepCopyObject() runtime.Object {
+	panic("selectableObject does not support DeepCopy")
+}
+
+type jsonEncoder struct{}
+
+func (jsonEncoder) Encode(obj runtime.Object, w io.Writer) error {
+	return json.NewEncoder(w).Encode(obj)
+}
+
+func TestValidateSelectableField(t *testing.T) {
+	for _, path := range []string{"spec.replicas", "status.phase", "spec.template.spec.nodeName"} {
+		if err := ValidateSelectableField(path); err != nil {
+			t.Errorf("%s: unexpected error: %v", path, err)
+		}
+	}
+	for _, path := range []string{"", "spec..replicas", ".spec", "spec.replicas>3", "spec.a b", "spec.a,b"} {
+		if err := ValidateSelectableField(path); err == nil {
+			t.Errorf("%q: expected an error", path)
+		}
+	}
+}
+
+func TestSelectableFieldsMutation(t *testing.T) {
+	paths := []string{"spec.replicas", "spec.ratio", "spec.paused", "spec.phase", "spec.selector", "spec.missing", "metadata.name"}
+	expected := fields.Set{
+		"metadata.name": "foo",
+		"spec.replicas": "5",
+		"spec.ratio":    "0.5",
+		"spec.paused":   "true",
+		"spec.phase":    "Running",
+	}
+	objects := map[string]runtime.Object{
+		"typed": &selectableObject{Spec: selectableSpec{
+			Replicas: 5,
+			Ratio:    0.5,
+			Paused:   true,
+			Phase:    "Running",
+			Selector: map[string]string{"app": "foo"},
+		}},
+		"unstructured": &unstructured.Unstructured{Object: map[string]interface{}{
+			"metadata": map[string]interface{}{"name": "bar"},
+			"spec": map[string]interface{}{
+				"replicas": int64(5),
+				"ratio":    0.5,
+				"paused":   true,
+				"phase":    "Running",
+				"selector": map[string]interface{}{"app": "foo"},
+			},
+		}},
+	}
+	mutation := SelectableFieldsMutation(paths, jsonEncoder{})
+	for name, obj := range objects {
+		// the fields already set by the AttrFunc take precedence
+		fieldSet := fields.Set{"metadata.name": "foo"}
+		if err := mutation(obj, fieldSet); err != nil {
+			t.Errorf("%s: unexpected error: %v", name, err)
+			continue
+		}
+		if !reflect.DeepEqual(expected, fieldSet) {
+			t.Errorf("%s: expected fields %v, got %v", name, expected, fieldSet)
+		}
+		for selector, matches := range map[string]bool{
+			"spec.replicas>3":                    true,
+			"spec.replicas<5":                    false,
+			"spec.ratio<1,spec.paused=true":      true,
+			"spec.phase in (Pending,Running)":    true,
+			"spec.phase notin (Pending,Running)": false,
+			"spec.missing in (a)":                false,
+		} {
+			if actual := fields.ParseSelectorOrDie(selector).Matches(fieldSet); actual != matches {
+				t.Errorf("%s: expected %s to match %t, got %t", name, selector, matches, actual)
+			}
+		}
+	}
+
+	if err := SelectableFieldsMutation(paths, nil)(objects["typed"], fields.Set{}); err == nil {
+		t.Errorf("expected an error evaluating a typed object without an encoder")
+	}
+	// objects are not encoded when the AttrFunc set every path
+	if err := SelectableFieldsMutation([]string{"metadata.name"}, nil)(objects["typed"], fields.Set{"metadata.name": "foo"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestSelectsSelectableFields(t *testing.T) {
+	paths := []string{"spec.replicas", "spec.phase"}
+	for selector, expected := range map[string]bool{
+		"metadata.name=foo":               false,
+		"spec.replicas>3":                 true,
+		"metadata.name=foo,spec.phase!=a": true,
+		"spec.replicasx=3":                false,
+	} {
+		if actual := SelectsSelectableFields(fields.ParseSelectorOrDie(selector), paths); actual != expected {
+			t.Errorf("%s: expected %t, got %t", selector, expected, actual)
+		}
+	}
+	if SelectsSelectableFields(fields.Everything(), paths) || SelectsSelectableFields(nil, paths) {
+		t.Errorf("expected the empty selector not to select selectable fields")
+	}
+}
@@ -56,6 +56,17 @@ func DefaultNamespaceScopedAttr(obj runtime.Object) (labels.Set, fields.Set, err
 	return labels.Set(metadata.GetLabels()), fieldSet, nil
 }
 
+// AnnotationAttrs returns the annotations of obj as a set for annotation
+// selectors to match. Annotations are part of the metadata of every object,
+// so unlike labels and fields they are not customized per resource by an AttrFunc.
+func AnnotationAttrs(obj runtime.Object) (labels.Set, error) {
+	metadata, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, err
+	}
+	return labels.Set(metadata.GetAnnotations()), nil
+}
+
 func (f AttrFunc) WithFieldMutation(fieldMutator FieldMutationFunc) AttrFunc {
 	return func(obj runtime.Object) (labels.Set, fields.Set, error) {
 		labelSet, fieldSet, err := f(obj)
@@ -73,16 +84,18 @@ func (f AttrFunc) WithFieldMutation(fieldMutator FieldMutationFunc) AttrFunc {
 type SelectionPredicate struct {
 	Label               labels.Selector
 	Field               fields.Selector
+	Annotation          labels.Selector
 	GetAttrs            AttrFunc
 	IndexFields         []string
+	IndexLabels         []string
 	Limit               int64
 	Continue            string
 	AllowWatchBookmarks bool
 }
 
 // Matches returns true if the given object's labels and fields (as
-// returned by s.GetAttrs) match s.Label and s.Field. An error is
-// returned if s.GetAttrs fails.
+// returned by s.GetAttrs) and annotations match s.Label, s.Field and
+// s.Annotation. An error is returned if s.GetAttrs fails.
 func (s *SelectionPredicate) Matches(obj runtime.Object) (bool, error) {
 	if s.Empty() {
 		return true, nil
@@ -95,19 +108,29 @@ func (s *SelectionPredicate) Matches(obj runtime.Object) (bool, error) {
 	if matched && s.Field != nil {
 		matched = matched && s.Field.Matches(fields)
 	}
+	if matched && s.Annotation != nil && !s.Annotation.Empty() {
+		annotations, err := AnnotationAttrs(obj)
+		if err != nil {
+			return false, err
+		}
+		matched = s.Annotation.Matches(annotations)
+	}
 	return matched, nil
 }
 
-// MatchesObjectAttributes returns true if the given labels and fields
-// match s.Label and s.Field.
-func (s *SelectionPredicate) MatchesObjectAttributes(l labels.Set, f fields.Set) bool {
-	if s.Label.Empty() && s.Field.Empty() {
+// MatchesObjectAttributes returns true if the given labels, fields and
+// annotations match s.Label, s.Field and s.Annotation.
+func (s *SelectionPredicate) MatchesObjectAttributes(l labels.Set, f fields.Set, a labels.Set) bool {
+	if s.Empty() {
 		return true
 	}
 	matched := s.Label.Matches(l)
 	if matched && s.Field != nil {
 		matched = (matched && s.Field.Matches(f))
 	}
+	if matched && s.Annotation != nil {
+		matched = s.Annotation.Matches(a)
+	}
 	return matched
 }
 
@@ -124,15 +147,22 @@ func (s *SelectionPredicate) MatchesSingle() (string, bool) {
 	return "", false
 }
 
-// For any index defined by IndexFields, if a matcher can match only (a subset)
-// of objects that return <value> for a given index, a pair (<index name>, <value>)
-// wil be returned.
-// TODO: Consider supporting also labels.
+// For any index defined by IndexFields or IndexLabels, if a matcher can match
+// only (a subset) of objects that return <value> for a given index, a pair
+// (<index name>, <value>) wil be returned. Index names are the ones returned
+// by FieldIndex and LabelIndex.
 func (s *SelectionPredicate) MatcherIndex() []MatchValue {
 	var result []MatchValue
 	for _, field := range s.IndexFields {
 		if value, ok := s.Field.RequiresExactMatch(field); ok {
-			result = append(result, MatchValue{IndexName: field, Value: value})
+			result = append(result, MatchValue{IndexName: FieldIndex(field), Value: value})
+		}
+	}
+	if s.Label != nil {
+		for _, label := range s.IndexLabels {
+			if value, ok := labels.RequiresExactMatch(s.Label, label); ok {
+				result = append(result, MatchValue{IndexName: LabelIndex(label), Value: value})
+			}
 		}
 	}
 	return result
@@ -140,5 +170,5 @@ func (s *SelectionPredicate) MatcherIndex() []MatchValue {
 
 // Empty returns true if the predicate performs no filtering.
 func (s *SelectionPredicate) Empty() bool {
-	return s.Label.Empty() && s.Field.Empty()
+	return s.Label.Empty() && s.Field.Empty() && (s.Annotation == nil || s.Annotation.Empty())
 }
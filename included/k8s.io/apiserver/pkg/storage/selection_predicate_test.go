@@ -18,8 +18,10 @@ package storage
 
 import (
 	"errors"
+	"reflect"
 	"testing"
 
+	metav1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/fields"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/labels"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
@@ -123,3 +125,128 @@ func TestSelectionPredicate(t *testing.T) {
 		}
 	}
 }
+
+func TestSelectionPredicateAnnotation(t *testing.T) {
+	obj := &metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{
+		Name:        "foo",
+		Labels:      map[string]string{"name": "foo"},
+		Annotations: map[string]string{"example.com/owner": "team-a", "example.com/tier": "gold"},
+	}}
+	getAttrs := func(runtime.Object) (labels.Set, fields.Set, error) {
+		return labels.Set(obj.Labels), fields.Set{"metadata.name": obj.Name}, nil
+	}
+	testCases := map[string]struct {
+		labelSelector, annotationSelector string
+		shouldMatch                       bool
+	}{
+		"annotation matches":         {annotationSelector: "example.com/owner=team-a", shouldMatch: true},
+		"annotation does not match":  {annotationSelector: "example.com/owner=team-b", shouldMatch: false},
+		"set based":                  {annotationSelector: "example.com/owner in (team-a,team-b),example.com/tier", shouldMatch: true},
+		"missing annotation":         {annotationSelector: "!example.com/tier", shouldMatch: false},
+		"label and annotation match": {labelSelector: "name=foo", annotationSelector: "example.com/tier=gold", shouldMatch: true},
+		"label does not match":       {labelSelector: "name=bar", annotationSelector: "example.com/tier=gold", shouldMatch: false},
+	}
+	for name, testCase := range testCases {
+		parsedLabel, err := labels.Parse(testCase.labelSelector)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsedAnnotation, err := labels.Parse(testCase.annotationSelector)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sp := &SelectionPredicate{
+			Label:      parsedLabel,
+			Field:      fields.Everything(),
+			Annotation: parsedAnnotation,
+			GetAttrs:   getAttrs,
+		}
+		if sp.Empty() {
+			t.Errorf("%s: expected a predicate with an annotation selector not to be empty", name)
+		}
+		got, err := sp.Matches(obj)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", name, err)
+			continue
+		}
+		if got != testCase.shouldMatch {
+			t.Errorf("%s: expected %v, got %v", name, testCase.shouldMatch, got)
+		}
+		l, f, _ := getAttrs(obj)
+		if got := sp.MatchesObjectAttributes(l, f, labels.Set(obj.Annotations)); got != testCase.shouldMatch {
+			t.Errorf("%s: expected object attributes to match %v, got %v", name, testCase.shouldMatch, got)
+		}
+	}
+
+	// predicates without an annotation selector match any annotations
+	sp := &SelectionPredicate{Label: labels.Everything(), Field: fields.Everything(), GetAttrs: getAttrs}
+	if !sp.Empty() {
+		t.Errorf("expected a predicate without selectors to be empty")
+	}
+	if !sp.MatchesObjectAttributes(labels.Set{}, fields.Set{}, nil) {
+		t.Errorf("expected a predicate without selectors to match")
+	}
+}
+
+func TestSelectionPredicateMatcherIndex(t *testing.T) {
+	testCases := map[string]struct {
+		labelSelector, fieldSelector string
+		indexLabels, indexFields     []string
+		expected                     []MatchValue
+	}{
+		"no indexes": {
+			labelSelector: "name=foo",
+			fieldSelector: "uid=12345",
+			expected:      nil,
+		},
+		"field index": {
+			labelSelector: "name=foo",
+			fieldSelector: "uid=12345",
+			indexFields:   []string{"uid"},
+			expected:      []MatchValue{{IndexName: FieldIndex("uid"), Value: "12345"}},
+		},
+		"label index": {
+			labelSelector: "name=foo",
+			fieldSelector: "uid=12345",
+			indexLabels:   []string{"name"},
+			expected:      []MatchValue{{IndexName: LabelIndex("name"), Value: "foo"}},
+		},
+		"field and label indexes": {
+			labelSelector: "name=foo",
+			fieldSelector: "uid=12345",
+			indexLabels:   []string{"name"},
+			indexFields:   []string{"uid"},
+			expected: []MatchValue{
+				{IndexName: FieldIndex("uid"), Value: "12345"},
+				{IndexName: LabelIndex("name"), Value: "foo"},
+			},
+		},
+		"no exact match": {
+			labelSelector: "name!=foo",
+			fieldSelector: "uid!=12345",
+			indexLabels:   []string{"name"},
+			indexFields:   []string{"uid"},
+			expected:      nil,
+		},
+	}
+
+	for name, testCase := range testCases {
+		parsedLabel, err := labels.Parse(testCase.labelSelector)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsedField, err := fields.ParseSelector(testCase.fieldSelector)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sp := &SelectionPredicate{
+			Label:       parsedLabel,
+			Field:       parsedField,
+			IndexLabels: testCase.indexLabels,
+			IndexFields: testCase.indexFields,
+		}
+		if got := sp.MatcherIndex(); !reflect.DeepEqual(got, testCase.expected) {
+			t.Errorf("%s: expected %v, got %v", name, testCase.expected, got)
+		}
+	}
+}
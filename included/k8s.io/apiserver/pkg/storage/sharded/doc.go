@@ -0,0 +1,24 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package sharded implements a storage.Interface that spreads the objects of
+// a resource across several storages, e.g. etcd clusters, by the hash of
+// their namespace, or of their name for cluster-scoped resources.
+//
+// Each object keeps the resourceVersion of the storage it is stored in.
+// Lists and watches across all shards return composite resourceVersions,
+// which hold the resourceVersion of every shard, so that they can be resumed.
+package sharded // import "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/sharded"
@@ -0,0 +1,139 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sharded
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/api/meta"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage"
+)
+
+// resourceVersionSeparator separates the resourceVersions of the shards in a
+// composite resourceVersion, e.g. "1042.977.1210" for three shards. The
+// composite resourceVersion of a single shard is its own resourceVersion.
+const resourceVersionSeparator = "."
+
+// resourceVersions are the resourceVersions of all shards, in shard order.
+type resourceVersions []uint64
+
+func (v resourceVersions) String() string {
+	parts := make([]string, len(v))
+	for i, rv := range v {
+		parts[i] = strconv.FormatUint(rv, 10)
+	}
+	return strings.Join(parts, resourceVersionSeparator)
+}
+
+// shardResourceVersion returns the resourceVersion of shard i to pass to its
+// storage. A zero resourceVersion is passed as "0".
+func (v resourceVersions) shardResourceVersion(i int) string {
+	return strconv.FormatUint(v[i], 10)
+}
+
+// isComposite returns true if resourceVersion holds the resourceVersions of
+// shards shards.
+func isComposite(resourceVersion string, shards int) bool {
+	if len(resourceVersion) == 0 {
+		return false
+	}
+	return shards == 1 || strings.Contains(resourceVersion, resourceVersionSeparator)
+}
+
+// parseResourceVersions parses the composite resourceVersion of shards shards.
+func parseResourceVersions(resourceVersion string, shards int) (resourceVersions, error) {
+	parts := strings.Split(resourceVersion, resourceVersionSeparator)
+	if len(parts) != shards {
+		return nil, fmt.Errorf("resource version %q holds %d resource versions, not one for each of the %d shards", resourceVersion, len(parts), shards)
+	}
+	v := make(resourceVersions, shards)
+	for i, part := range parts {
+		rv, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid resource version %q: %v", resourceVersion, err)
+		}
+		v[i] = rv
+	}
+	return v, nil
+}
+
+// continueToken is the state of a list across all shards. The shards are
+// listed one after the other at the resourceVersions of the first page.
+type continueToken struct {
+	// Shard is the shard the next page starts in.
+	Shard int `json:"shard"`
+	// Continue is the continue token of Shard, or empty to list Shard from
+	// its start.
+	Continue string `json:"continue,omitempty"`
+	// ResourceVersions are the resourceVersions all shards are listed at.
+	ResourceVersions resourceVersions `json:"rvs"`
+}
+
+func encodeContinue(c *continueToken) (string, error) {
+	out, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(out), nil
+}
+
+func decodeContinue(continueValue string, shards int) (*continueToken, error) {
+	data, err := base64.RawURLEncoding.DecodeString(continueValue)
+	if err != nil {
+		return nil, fmt.Errorf("continue key is not valid: %v", err)
+	}
+	c := &continueToken{}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("continue key is not valid: %v", err)
+	}
+	if c.Shard < 0 || c.Shard >= shards || len(c.ResourceVersions) != shards {
+		return nil, fmt.Errorf("continue key is not valid: it is not a continue key of %d shards", shards)
+	}
+	return c, nil
+}
+
+// versioner is the storage.Versioner of the shards. It also accepts the
+// composite resourceVersions that objects returned by watches across all
+// shards carry, taking the resourceVersion of the shard of the object.
+type versioner struct {
+	storage.Versioner
+	store *store
+}
+
+var _ storage.Versioner = &versioner{}
+
+// ObjectResourceVersion implements storage.Versioner.
+func (v *versioner) ObjectResourceVersion(obj runtime.Object) (uint64, error) {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return 0, err
+	}
+	resourceVersion := accessor.GetResourceVersion()
+	if !isComposite(resourceVersion, len(v.store.shards)) {
+		return v.Versioner.ObjectResourceVersion(obj)
+	}
+	rvs, err := parseResourceVersions(resourceVersion, len(v.store.shards))
+	if err != nil {
+		return 0, storage.NewInternalError(err.Error())
+	}
+	return rvs[v.store.objectShard(accessor)], nil
+}
@@ -0,0 +1,541 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sharded
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"reflect"
+	"sort"
+	"strings"
+
+	apierrors "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/api/errors"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/api/meta"
+	metav1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/watch"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/usage"
+)
+
+// store routes every key of a resource to one of its shards by the hash of
+// the first segment of the key following the resource prefix, which is the
+// namespace of namespaced objects and the name of cluster-scoped ones. Keys
+// without that segment span all shards.
+type store struct {
+	shards         []storage.Interface
+	resourcePrefix string
+	pagingEnabled  bool
+	versioner      *versioner
+}
+
+var _ storage.Interface = &store{}
+
+// New returns a storage.Interface that stores the objects with keys under
+// resourcePrefix in shards. The shards must be storages of the same resource
+// and codec, with different backends. Objects must not be moved between
+// shards, so the number and order of the shards of a resource must not change
+// once it holds objects.
+func New(shards []storage.Interface, resourcePrefix string, pagingEnabled bool) storage.Interface {
+	s := &store{
+		shards:         shards,
+		resourcePrefix: "/" + strings.Trim(resourcePrefix, "/") + "/",
+		pagingEnabled:  pagingEnabled,
+	}
+	s.versioner = &versioner{Versioner: shards[0].Versioner(), store: s}
+	return s
+}
+
+// shard returns the shard of the objects with the given key segment.
+func (s *store) shard(segment string) int {
+	h := fnv.New32a()
+	h.Write([]byte(segment))
+	return int(h.Sum32() % uint32(len(s.shards)))
+}
+
+// keyShard returns the shard of key, or false if the key spans all shards.
+func (s *store) keyShard(key string) (int, bool) {
+	if !strings.HasPrefix(key, s.resourcePrefix) {
+		return 0, false
+	}
+	segment := strings.SplitN(strings.TrimPrefix(key, s.resourcePrefix), "/", 2)[0]
+	if len(segment) == 0 {
+		return 0, false
+	}
+	return s.shard(segment), true
+}
+
+// objectKeyShard returns the shard of the object at key.
+func (s *store) objectKeyShard(key string) (int, error) {
+	i, ok := s.keyShard(key)
+	if !ok {
+		return 0, storage.NewInternalErrorf("%q is not the key of an object under %q", key, s.resourcePrefix)
+	}
+	return i, nil
+}
+
+// objectShard returns the shard obj is stored in.
+func (s *store) objectShard(obj metav1.Object) int {
+	if namespace := obj.GetNamespace(); len(namespace) > 0 {
+		return s.shard(namespace)
+	}
+	return s.shard(obj.GetName())
+}
+
+// shardResourceVersion returns the resourceVersion to pass to shard i for
+// resourceVersion, which may be a composite resourceVersion.
+func (s *store) shardResourceVersion(i int, resourceVersion string) (string, error) {
+	if !isComposite(resourceVersion, len(s.shards)) {
+		return resourceVersion, nil
+	}
+	rvs, err := parseResourceVersions(resourceVersion, len(s.shards))
+	if err != nil {
+		return "", apierrors.NewBadRequest(fmt.Sprintf("invalid resource version: %v", err))
+	}
+	return rvs.shardResourceVersion(i), nil
+}
+
+// shardPreconditions returns preconditions with the resourceVersion of shard
+// i, if they have a composite one.
+func (s *store) shardPreconditions(i int, preconditions *storage.Preconditions) (*storage.Preconditions, error) {
+	if preconditions == nil || preconditions.ResourceVersion == nil {
+		return preconditions, nil
+	}
+	resourceVersion, err := s.shardResourceVersion(i, *preconditions.ResourceVersion)
+	if err != nil {
+		return nil, err
+	}
+	return &storage.Preconditions{UID: preconditions.UID, ResourceVersion: &resourceVersion}, nil
+}
+
+// notAllShardsError is returned for requests across all shards with the
+// resourceVersion of a single shard, which clients might have kept from
+// before the resource was sharded. They have to list the resource again.
+func (s *store) notAllShardsError(resourceVersion string) error {
+	return apierrors.NewResourceExpired(fmt.Sprintf("resource version %s is not a resource version of all %d shards", resourceVersion, len(s.shards)))
+}
+
+// Versioner implements storage.Interface.Versioner.
+func (s *store) Versioner() storage.Versioner {
+	return s.versioner
+}
+
+// Create implements storage.Interface.Create.
+func (s *store) Create(ctx context.Context, key string, obj, out runtime.Object, ttl uint64) error {
+	i, err := s.objectKeyShard(key)
+	if err != nil {
+		return err
+	}
+	return s.shards[i].Create(ctx, key, obj, out, ttl)
+}
+
+// Delete implements storage.Interface.Delete.
+func (s *store) Delete(ctx context.Context, key string, out runtime.Object, preconditions *storage.Preconditions, validateDeletion storage.ValidateObjectFunc) error {
+	i, err := s.objectKeyShard(key)
+	if err != nil {
+		return err
+	}
+	preconditions, err = s.shardPreconditions(i, preconditions)
+	if err != nil {
+		return err
+	}
+	return s.shards[i].Delete(ctx, key, out, preconditions, validateDeletion)
+}
+
+// Watch implements storage.Interface.Watch.
+func (s *store) Watch(ctx context.Context, key string, resourceVersion string, pred storage.SelectionPredicate) (watch.Interface, error) {
+	return s.watch(ctx, key, resourceVersion, pred, storage.Interface.Watch)
+}
+
+// WatchList implements storage.Interface.WatchList.
+func (s *store) WatchList(ctx context.Context, key string, resourceVersion string, pred storage.SelectionPredicate) (watch.Interface, error) {
+	return s.watch(ctx, key, resourceVersion, pred, storage.Interface.WatchList)
+}
+
+type watchFunc func(s storage.Interface, ctx context.Context, key string, resourceVersion string, pred storage.SelectionPredicate) (watch.Interface, error)
+
+func (s *store) watch(ctx context.Context, key string, resourceVersion string, pred storage.SelectionPredicate, watchShard watchFunc) (watch.Interface, error) {
+	if i, ok := s.keyShard(key); ok {
+		shardResourceVersion, err := s.shardResourceVersion(i, resourceVersion)
+		if err != nil {
+			return nil, err
+		}
+		return watchShard(s.shards[i], ctx, key, shardResourceVersion, pred)
+	}
+
+	// A shard watched from resourceVersion 0 sends its current objects first,
+	// so resuming from a composite resourceVersion with a 0 never loses events.
+	// Watches without a composite resourceVersion start with the current
+	// resourceVersions of the shards, read before watching them, so that the
+	// composite resourceVersions of their events hold the resourceVersion of
+	// every shard from the first one.
+	var rvs resourceVersions
+	var err error
+	composite := isComposite(resourceVersion, len(s.shards))
+	switch {
+	case composite:
+		rvs, err = parseResourceVersions(resourceVersion, len(s.shards))
+		if err != nil {
+			return nil, apierrors.NewBadRequest(fmt.Sprintf("invalid resource version: %v", err))
+		}
+	case len(resourceVersion) > 0 && resourceVersion != "0":
+		return nil, s.notAllShardsError(resourceVersion)
+	default:
+		rvs, err = s.currentResourceVersions(ctx, key, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	watchers := make([]watch.Interface, len(s.shards))
+	for i, shard := range s.shards {
+		shardResourceVersion := resourceVersion
+		if composite {
+			shardResourceVersion = rvs.shardResourceVersion(i)
+		}
+		w, err := watchShard(shard, ctx, key, shardResourceVersion, pred)
+		if err != nil {
+			for _, started := range watchers[:i] {
+				started.Stop()
+			}
+			return nil, err
+		}
+		watchers[i] = w
+	}
+	return newMergedWatcher(watchers, s.versioner.Versioner, rvs), nil
+}
+
+// Get implements storage.Interface.Get.
+func (s *store) Get(ctx context.Context, key string, resourceVersion string, objPtr runtime.Object, ignoreNotFound bool) error {
+	i, err := s.objectKeyShard(key)
+	if err != nil {
+		return err
+	}
+	resourceVersion, err = s.shardResourceVersion(i, resourceVersion)
+	if err != nil {
+		return err
+	}
+	return s.shards[i].Get(ctx, key, resourceVersion, objPtr, ignoreNotFound)
+}
+
+// GetToList implements storage.Interface.GetToList.
+func (s *store) GetToList(ctx context.Context, key string, resourceVersion string, pred storage.SelectionPredicate, listObj runtime.Object) error {
+	i, err := s.objectKeyShard(key)
+	if err != nil {
+		return err
+	}
+	resourceVersion, err = s.shardResourceVersion(i, resourceVersion)
+	if err != nil {
+		return err
+	}
+	return s.shards[i].GetToList(ctx, key, resourceVersion, pred, listObj)
+}
+
+// List implements storage.Interface.List. Lists of a single shard return
+// its own resourceVersion and continue tokens. Lists across all shards list
+// the shards one after the other, at the resourceVersions of all shards that
+// the first page returns.
+func (s *store) List(ctx context.Context, key string, resourceVersion string, pred storage.SelectionPredicate, listObj runtime.Object) error {
+	if i, ok := s.keyShard(key); ok {
+		shardResourceVersion, err := s.shardResourceVersion(i, resourceVersion)
+		if err != nil {
+			return err
+		}
+		return s.shards[i].List(ctx, key, shardResourceVersion, pred, listObj)
+	}
+
+	paging := s.pagingEnabled && pred.Limit > 0
+	token := &continueToken{}
+	switch {
+	case s.pagingEnabled && len(pred.Continue) > 0:
+		var err error
+		token, err = decodeContinue(pred.Continue, len(s.shards))
+		if err != nil {
+			return apierrors.NewBadRequest(fmt.Sprintf("invalid continue token: %v", err))
+		}
+		if len(resourceVersion) > 0 && resourceVersion != "0" {
+			return apierrors.NewBadRequest("specifying resource version is not allowed when using continue")
+		}
+	case isComposite(resourceVersion, len(s.shards)):
+		rvs, err := parseResourceVersions(resourceVersion, len(s.shards))
+		if err != nil {
+			return apierrors.NewBadRequest(fmt.Sprintf("invalid resource version: %v", err))
+		}
+		token.ResourceVersions = rvs
+	case len(resourceVersion) > 0 && resourceVersion != "0":
+		return s.notAllShardsError(resourceVersion)
+	case paging:
+		rvs, err := s.currentResourceVersions(ctx, key, listObj)
+		if err != nil {
+			return err
+		}
+		token.ResourceVersions = rvs
+	}
+
+	rvs := make(resourceVersions, len(s.shards))
+	copy(rvs, token.ResourceVersions)
+	var items []runtime.Object
+	for i := token.Shard; i < len(s.shards); i++ {
+		shardPred := pred
+		shardPred.Continue = ""
+		shardResourceVersion := resourceVersion
+		if token.ResourceVersions != nil {
+			shardResourceVersion = rvs.shardResourceVersion(i)
+		}
+		if i == token.Shard && len(token.Continue) > 0 {
+			shardPred.Continue = token.Continue
+			shardResourceVersion = ""
+		}
+		if paging {
+			shardPred.Limit = pred.Limit - int64(len(items))
+		}
+
+		part := newList(listObj)
+		if err := s.shards[i].List(ctx, key, shardResourceVersion, shardPred, part); err != nil {
+			return interpretListError(err, i, rvs)
+		}
+		partItems, err := meta.ExtractList(part)
+		if err != nil {
+			return err
+		}
+		items = append(items, partItems...)
+		partMeta, err := meta.ListAccessor(part)
+		if err != nil {
+			return err
+		}
+		if token.ResourceVersions == nil {
+			if rvs[i], err = s.versioner.ParseResourceVersion(partMeta.GetResourceVersion()); err != nil {
+				return err
+			}
+		}
+
+		if !paging {
+			continue
+		}
+		next := &continueToken{Shard: i, Continue: partMeta.GetContinue(), ResourceVersions: rvs}
+		if len(next.Continue) == 0 {
+			next.Shard++
+		}
+		if next.Shard == len(s.shards) {
+			break
+		}
+		if len(next.Continue) > 0 || int64(len(items)) >= pred.Limit {
+			return setList(listObj, items, rvs, next)
+		}
+	}
+	return setList(listObj, items, rvs, nil)
+}
+
+// currentResourceVersions returns the current resourceVersions of all shards.
+// Shards that are a storage.ChangeDetector report it, the others are listed
+// into listObj, so listObj is only optional if all shards are one.
+func (s *store) currentResourceVersions(ctx context.Context, key string, listObj runtime.Object) (resourceVersions, error) {
+	pred := storage.Everything
+	pred.Limit = 1
+	rvs := make(resourceVersions, len(s.shards))
+	for i, shard := range s.shards {
+		if detector, ok := shard.(storage.ChangeDetector); ok {
+			current, _, err := detector.ChangedSince(ctx, key, 0)
+			if err != nil {
+				return nil, err
+			}
+			rvs[i] = current
+			continue
+		}
+		if listObj == nil {
+			return nil, storage.NewInternalErrorf("shard %d of %s does not report its current resource version", i, s.resourcePrefix)
+		}
+		part := newList(listObj)
+		if err := shard.List(ctx, key, "", pred, part); err != nil {
+			return nil, err
+		}
+		partMeta, err := meta.ListAccessor(part)
+		if err != nil {
+			return nil, err
+		}
+		if rvs[i], err = s.versioner.ParseResourceVersion(partMeta.GetResourceVersion()); err != nil {
+			return nil, err
+		}
+	}
+	return rvs, nil
+}
+
+// interpretListError replaces the continue token of shard i that an expired
+// continue token error carries with the continue token of all shards.
+func interpretListError(err error, i int, rvs resourceVersions) error {
+	statusErr, ok := err.(*apierrors.StatusError)
+	if !ok || len(statusErr.ErrStatus.ListMeta.Continue) == 0 {
+		return err
+	}
+	next, encodeErr := encodeContinue(&continueToken{Shard: i, Continue: statusErr.ErrStatus.ListMeta.Continue, ResourceVersions: rvs})
+	if encodeErr != nil {
+		return err
+	}
+	statusErr.ErrStatus.ListMeta.Continue = next
+	return statusErr
+}
+
+// newList returns an empty list of the type of listObj.
+func newList(listObj runtime.Object) runtime.Object {
+	return reflect.New(reflect.TypeOf(listObj).Elem()).Interface().(runtime.Object)
+}
+
+// setList sets items, the composite resourceVersion rvs and the continue
+// token of the next page, if any, into listObj.
+func setList(listObj runtime.Object, items []runtime.Object, rvs resourceVersions, next *continueToken) error {
+	if err := meta.SetList(listObj, items); err != nil {
+		return err
+	}
+	listMeta, err := meta.ListAccessor(listObj)
+	if err != nil {
+		return err
+	}
+	var continueValue string
+	if next != nil {
+		if continueValue, err = encodeContinue(next); err != nil {
+			return err
+		}
+	}
+	listMeta.SetResourceVersion(rvs.String())
+	listMeta.SetContinue(continueValue)
+	listMeta.SetRemainingItemCount(nil)
+	return nil
+}
+
+// GuaranteedUpdate implements storage.Interface.GuaranteedUpdate.
+func (s *store) GuaranteedUpdate(
+	ctx context.Context, key string, ptrToType runtime.Object, ignoreNotFound bool,
+	preconditions *storage.Preconditions, tryUpdate storage.UpdateFunc, suggestion ...runtime.Object) error {
+	i, err := s.objectKeyShard(key)
+	if err != nil {
+		return err
+	}
+	preconditions, err = s.shardPreconditions(i, preconditions)
+	if err != nil {
+		return err
+	}
+	return s.shards[i].GuaranteedUpdate(ctx, key, ptrToType, ignoreNotFound, preconditions, tryUpdate, suggestion...)
+}
+
+// Count implements storage.Interface.Count.
+func (s *store) Count(key string) (int64, error) {
+	if i, ok := s.keyShard(key); ok {
+		return s.shards[i].Count(key)
+	}
+	var count int64
+	for _, shard := range s.shards {
+		c, err := shard.Count(key)
+		if err != nil {
+			return 0, err
+		}
+		count += c
+	}
+	return count, nil
+}
+
+// ListKeys implements storage.KeyLister if all shards do.
+func (s *store) ListKeys(ctx context.Context, key string) ([]string, error) {
+	shards := s.shards
+	if i, ok := s.keyShard(key); ok {
+		shards = s.shards[i : i+1]
+	}
+	var keys []string
+	for _, shard := range shards {
+		lister, ok := shard.(storage.KeyLister)
+		if !ok {
+			return nil, storage.NewInternalErrorf("a shard of %s does not list keys", s.resourcePrefix)
+		}
+		shardKeys, err := lister.ListKeys(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, shardKeys...)
+	}
+	if len(shards) > 1 {
+		sort.Strings(keys)
+	}
+	return keys, nil
+}
+
+// NamespaceUsage implements usage.Reporter. Every namespace is held by a
+// single shard, but the cluster-scoped objects are spread across all of them.
+func (s *store) NamespaceUsage() (map[string]usage.Usage, bool) {
+	namespaces := map[string]usage.Usage{}
+	for _, shard := range s.shards {
+		reporter, ok := shard.(usage.Reporter)
+		if !ok {
+			return nil, false
+		}
+		shardNamespaces, ok := reporter.NamespaceUsage()
+		if !ok {
+			return nil, false
+		}
+		for namespace, u := range shardNamespaces {
+			total := namespaces[namespace]
+			total.Objects += u.Objects
+			total.Bytes += u.Bytes
+			namespaces[namespace] = total
+		}
+	}
+	return namespaces, true
+}
+
+// StoredSize implements usage.Sizer by the size in the shard of obj.
+func (s *store) StoredSize(obj runtime.Object) (int64, error) {
+	objMeta, err := meta.Accessor(obj)
+	if err != nil {
+		return 0, err
+	}
+	sizer, ok := s.shards[s.objectShard(objMeta)].(usage.Sizer)
+	if !ok {
+		return 0, fmt.Errorf("the shards do not account the usage")
+	}
+	return sizer.StoredSize(obj)
+}
+
+// Transact implements storage.Interface.Transact. The transaction is
+// committed by the shard of the first key stored by s, so all keys must be
+// stored in shards and storages served by the same backend as that shard.
+func (s *store) Transact(ctx context.Context, ops []storage.TxnOp) error {
+	var committer storage.Interface
+	routed := make([]storage.TxnOp, len(ops))
+	for i, op := range ops {
+		owner := s
+		if op.Storage != nil {
+			owner, _ = storage.Unwrap(op.Storage).(*store)
+		}
+		if owner != nil {
+			shard, err := owner.objectKeyShard(op.Key)
+			if err != nil {
+				return err
+			}
+			if op.Preconditions, err = owner.shardPreconditions(shard, op.Preconditions); err != nil {
+				return err
+			}
+			if committer == nil && owner == s {
+				committer = owner.shards[shard]
+			}
+			op.Storage = owner.shards[shard]
+		}
+		routed[i] = op
+	}
+	if committer == nil {
+		return fmt.Errorf("none of the keys of the transaction is stored in %q", s.resourcePrefix)
+	}
+	return committer.Transact(ctx, routed)
+}
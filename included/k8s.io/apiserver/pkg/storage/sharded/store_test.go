@@ -0,0 +1,341 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sharded
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	corev1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/core/v1"
+	apierrors "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/api/errors"
+	metav1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/util/wait"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/watch"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/embedded"
+	storagetesting "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/testing"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/value"
+)
+
+const numShards = 3
+
+func newTestStore(t *testing.T) (*store, func()) {
+	shards := make([]storage.Interface, numShards)
+	backends := make([]*embedded.Backend, numShards)
+	for i := range shards {
+		backends[i] = embedded.NewMemory()
+		shards[i] = embedded.New(backends[i], storagetesting.Codec, "", value.IdentityTransformer, true)
+	}
+	return New(shards, "pods", true).(*store), func() {
+		for _, b := range backends {
+			b.Close()
+		}
+	}
+}
+
+// namespaces returns a namespace of every shard.
+func namespaces(s *store) []string {
+	result := make([]string, numShards)
+	found := 0
+	for i := 0; found < numShards; i++ {
+		namespace := fmt.Sprintf("ns-%d", i)
+		if shard := s.shard(namespace); len(result[shard]) == 0 {
+			result[shard] = namespace
+			found++
+		}
+	}
+	return result
+}
+
+func newPod(namespace, name string) *corev1.Pod {
+	return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+}
+
+func create(ctx context.Context, t *testing.T, s storage.Interface, obj *corev1.Pod) *corev1.Pod {
+	out := &corev1.Pod{}
+	if err := s.Create(ctx, "/pods/"+obj.Namespace+"/"+obj.Name, obj, out, 0); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	return out
+}
+
+func names(pods []corev1.Pod) []string {
+	var result []string
+	for _, pod := range pods {
+		result = append(result, pod.Namespace+"/"+pod.Name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func TestRouting(t *testing.T) {
+	ctx := context.Background()
+	s, destroy := newTestStore(t)
+	defer destroy()
+
+	for _, namespace := range namespaces(s) {
+		create(ctx, t, s, newPod(namespace, "a"))
+		create(ctx, t, s, newPod(namespace, "b"))
+	}
+	for i, shard := range s.shards {
+		count, err := shard.Count("/pods")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != 2 {
+			t.Errorf("shard %d holds %d pods, expected 2", i, count)
+		}
+	}
+	count, err := s.Count("/pods")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2*numShards {
+		t.Errorf("count want=%d, get=%d", 2*numShards, count)
+	}
+
+	namespace := namespaces(s)[1]
+	out := &corev1.PodList{}
+	if err := s.List(ctx, "/pods/"+namespace, "", storage.Everything, out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(names(out.Items), []string{namespace + "/a", namespace + "/b"}) {
+		t.Errorf("unexpected namespace list: %v", names(out.Items))
+	}
+	if isComposite(out.ResourceVersion, numShards) {
+		t.Errorf("the list of a single shard has the composite resource version %s", out.ResourceVersion)
+	}
+}
+
+func TestListAcrossShards(t *testing.T) {
+	ctx := context.Background()
+	s, destroy := newTestStore(t)
+	defer destroy()
+
+	var expected []string
+	for _, namespace := range namespaces(s) {
+		for _, name := range []string{"a", "b", "c"} {
+			create(ctx, t, s, newPod(namespace, name))
+			expected = append(expected, namespace+"/"+name)
+		}
+	}
+	sort.Strings(expected)
+
+	// pages span shards and are all listed at the resource version of the first
+	pred := storage.Everything
+	pred.Limit = 2
+	var items []corev1.Pod
+	var listRV string
+	for {
+		out := &corev1.PodList{}
+		if err := s.List(ctx, "/pods", "", pred, out); err != nil {
+			t.Fatal(err)
+		}
+		if len(out.Items) > 2 {
+			t.Fatalf("page of %d items exceeds the limit", len(out.Items))
+		}
+		if len(listRV) == 0 {
+			listRV = out.ResourceVersion
+			if err := s.Delete(ctx, "/pods/"+namespaces(s)[2]+"/c", &corev1.Pod{}, nil, storage.ValidateAllObjectFunc); err != nil {
+				t.Fatal(err)
+			}
+		} else if out.ResourceVersion != listRV {
+			t.Errorf("page resource version want=%s, get=%s", listRV, out.ResourceVersion)
+		}
+		items = append(items, out.Items...)
+		if len(out.Continue) == 0 {
+			break
+		}
+		pred.Continue = out.Continue
+	}
+	if !reflect.DeepEqual(names(items), expected) {
+		t.Errorf("unexpected paginated list: %v", names(items))
+	}
+
+	out := &corev1.PodList{}
+	if err := s.List(ctx, "/pods", listRV, storage.Everything, out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(names(out.Items), expected) {
+		t.Errorf("unexpected list at resource version %s: %v", listRV, names(out.Items))
+	}
+	out = &corev1.PodList{}
+	if err := s.List(ctx, "/pods", "", storage.Everything, out); err != nil {
+		t.Fatal(err)
+	}
+	if len(out.Items) != len(expected)-1 {
+		t.Errorf("expected %d pods in the current list, got %v", len(expected)-1, names(out.Items))
+	}
+
+	if err := s.List(ctx, "/pods", "5", storage.Everything, &corev1.PodList{}); !apierrors.IsResourceExpired(err) {
+		t.Errorf("expected a resource expired error for the resource version of a single shard, got %v", err)
+	}
+	if err := s.List(ctx, "/pods", "1.2", storage.Everything, &corev1.PodList{}); !apierrors.IsBadRequest(err) {
+		t.Errorf("expected a bad request error for a composite resource version of 2 shards, got %v", err)
+	}
+}
+
+func expectEvent(t *testing.T, w watch.Interface, expectType watch.EventType, name string) *corev1.Pod {
+	select {
+	case event, ok := <-w.ResultChan():
+		if !ok {
+			t.Fatalf("watch closed, expected a %v event", expectType)
+		}
+		pod, ok := event.Object.(*corev1.Pod)
+		if event.Type != expectType || !ok || pod.Name != name {
+			t.Fatalf("expected a %v event of %s, got %#v", expectType, name, event)
+		}
+		return pod
+	case <-time.After(wait.ForeverTestTimeout):
+		t.Fatalf("time out after waiting %v on ResultChan", wait.ForeverTestTimeout)
+	}
+	return nil
+}
+
+func TestWatchAcrossShards(t *testing.T) {
+	ctx := context.Background()
+	s, destroy := newTestStore(t)
+	defer destroy()
+
+	ns := namespaces(s)
+	create(ctx, t, s, newPod(ns[0], "a"))
+	list := &corev1.PodList{}
+	if err := s.List(ctx, "/pods", "", storage.Everything, list); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := s.WatchList(ctx, "/pods", list.ResourceVersion, storage.Everything)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := create(ctx, t, s, newPod(ns[1], "b"))
+	got := expectEvent(t, w, watch.Added, "b")
+	if !isComposite(got.ResourceVersion, numShards) {
+		t.Errorf("expected a composite resource version, got %s", got.ResourceVersion)
+	}
+	// the composite resource version of the object resolves to its own
+	rv, err := s.Versioner().ObjectResourceVersion(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if native, _ := s.versioner.Versioner.ObjectResourceVersion(b); rv != native {
+		t.Errorf("resource version of the watched object want=%d, get=%d", native, rv)
+	}
+	resourceVersion := got.ResourceVersion
+	if err := s.GuaranteedUpdate(ctx, "/pods/"+ns[1]+"/b", &corev1.Pod{}, false, &storage.Preconditions{ResourceVersion: &resourceVersion}, storage.SimpleUpdate(func(obj runtime.Object) (runtime.Object, error) {
+		obj.(*corev1.Pod).Spec.NodeName = "node1"
+		return obj, nil
+	})); err != nil {
+		t.Fatalf("GuaranteedUpdate with a composite resource version precondition failed: %v", err)
+	}
+	expectEvent(t, w, watch.Modified, "b")
+	w.Stop()
+
+	// resuming from the first event replays only the later ones
+	w, err = s.WatchList(ctx, "/pods", resourceVersion, storage.Everything)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+	expectEvent(t, w, watch.Modified, "b")
+	create(ctx, t, s, newPod(ns[2], "c"))
+	expectEvent(t, w, watch.Added, "c")
+
+	if _, err := s.WatchList(ctx, "/pods", b.ResourceVersion, storage.Everything); !apierrors.IsResourceExpired(err) {
+		t.Errorf("expected a resource expired error for the resource version of a single shard, got %v", err)
+	}
+}
+
+func TestWatchWithoutCompositeResourceVersion(t *testing.T) {
+	ctx := context.Background()
+	s, destroy := newTestStore(t)
+	defer destroy()
+
+	ns := namespaces(s)
+	current := make(resourceVersions, numShards)
+	for i := range ns {
+		pod := create(ctx, t, s, newPod(ns[i], "a"))
+		rv, err := s.versioner.Versioner.ObjectResourceVersion(pod)
+		if err != nil {
+			t.Fatal(err)
+		}
+		current[i] = rv
+	}
+
+	for _, resourceVersion := range []string{"", "0"} {
+		w, err := s.WatchList(ctx, "/pods", resourceVersion, storage.Everything)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the composite resource versions of the current objects hold the
+		// current resource versions of all shards
+		for i := 0; i < numShards; i++ {
+			got := expectEvent(t, w, watch.Added, "a")
+			rvs, err := parseResourceVersions(got.ResourceVersion, numShards)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(rvs, current) {
+				t.Errorf("watch from %q: composite resource version want=%s, get=%s", resourceVersion, current, rvs)
+			}
+		}
+		w.Stop()
+	}
+
+	// resuming from an event does not replay the objects of the other shards
+	w, err := s.WatchList(ctx, "/pods", "", storage.Everything)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := expectEvent(t, w, watch.Added, "a")
+	w.Stop()
+	w, err = s.WatchList(ctx, "/pods", got.ResourceVersion, storage.Everything)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+	create(ctx, t, s, newPod(ns[1], "b"))
+	expectEvent(t, w, watch.Added, "b")
+}
+
+func TestTransact(t *testing.T) {
+	ctx := context.Background()
+	s, destroy := newTestStore(t)
+	defer destroy()
+
+	ns := namespaces(s)
+	ops := []storage.TxnOp{
+		{Type: storage.TxnCreate, Key: "/pods/" + ns[0] + "/a", Obj: newPod(ns[0], "a"), Out: &corev1.Pod{}},
+		{Type: storage.TxnCreate, Key: "/pods/" + ns[0] + "/b", Obj: newPod(ns[0], "b"), Out: &corev1.Pod{}},
+	}
+	if err := s.Transact(ctx, ops); err != nil {
+		t.Fatalf("Transact in a single shard failed: %v", err)
+	}
+
+	ops = []storage.TxnOp{
+		{Type: storage.TxnCreate, Key: "/pods/" + ns[0] + "/c", Obj: newPod(ns[0], "c"), Out: &corev1.Pod{}},
+		{Type: storage.TxnCreate, Key: "/pods/" + ns[1] + "/c", Obj: newPod(ns[1], "c"), Out: &corev1.Pod{}},
+	}
+	if err := s.Transact(ctx, ops); err == nil {
+		t.Errorf("expected an error for a transaction across shards")
+	}
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sharded
+
+import (
+	"sync"
+
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/api/meta"
+	utilruntime "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/util/runtime"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/watch"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage"
+)
+
+// mergedWatcher merges the watches of all shards. The objects of its events
+// carry the composite resourceVersion of the last events of all shards, so
+// that clients can resume the watch from any event.
+type mergedWatcher struct {
+	watchers  []watch.Interface
+	versioner storage.Versioner
+	result    chan watch.Event
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+
+	// lock serializes the events of the shards, so that the composite
+	// resourceVersions of the sent events never go back.
+	lock sync.Mutex
+	rvs  resourceVersions
+}
+
+// newMergedWatcher merges watchers, which were started at rvs, or from the
+// current objects after the shards were at rvs. versioner is the versioner of
+// the shards.
+func newMergedWatcher(watchers []watch.Interface, versioner storage.Versioner, rvs resourceVersions) *mergedWatcher {
+	w := &mergedWatcher{
+		watchers:  watchers,
+		versioner: versioner,
+		result:    make(chan watch.Event),
+		stopCh:    make(chan struct{}),
+		rvs:       rvs,
+	}
+	var wg sync.WaitGroup
+	wg.Add(len(watchers))
+	for i := range watchers {
+		go func(i int) {
+			defer wg.Done()
+			w.forward(i)
+		}(i)
+	}
+	go func() {
+		wg.Wait()
+		close(w.result)
+	}()
+	return w
+}
+
+// forward sends the events of shard i until its watch ends, which ends the
+// watches of all shards.
+func (w *mergedWatcher) forward(i int) {
+	defer w.Stop()
+	for {
+		select {
+		case <-w.stopCh:
+			return
+		case event, ok := <-w.watchers[i].ResultChan():
+			if !ok {
+				return
+			}
+			if !w.send(i, event) {
+				return
+			}
+		}
+	}
+}
+
+// send sends event of shard i and returns false if the watch was stopped.
+func (w *mergedWatcher) send(i int, event watch.Event) bool {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if event.Type != watch.Error {
+		if err := w.setResourceVersion(i, event); err != nil {
+			utilruntime.HandleError(err)
+		}
+	}
+	select {
+	case w.result <- event:
+		return true
+	case <-w.stopCh:
+		return false
+	}
+}
+
+// setResourceVersion records the resourceVersion of the object of event of
+// shard i and replaces it with the composite resourceVersion.
+func (w *mergedWatcher) setResourceVersion(i int, event watch.Event) error {
+	accessor, err := meta.Accessor(event.Object)
+	if err != nil {
+		return err
+	}
+	rv, err := w.versioner.ObjectResourceVersion(event.Object)
+	if err != nil {
+		return err
+	}
+	if rv > w.rvs[i] {
+		w.rvs[i] = rv
+	}
+	accessor.SetResourceVersion(w.rvs.String())
+	return nil
+}
+
+// Stop implements watch.Interface.
+func (w *mergedWatcher) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stopCh)
+		for _, watcher := range w.watchers {
+			watcher.Stop()
+		}
+	})
+}
+
+// ResultChan implements watch.Interface.
+func (w *mergedWatcher) ResultChan() <-chan watch.Event {
+	return w.result
+}
@@ -24,10 +24,14 @@ import (
 )
 
 const (
-	StorageTypeUnset = ""
-	StorageTypeETCD3 = "etcd3"
+	StorageTypeUnset    = ""
+	StorageTypeETCD3    = "etcd3"
+	StorageTypeEmbedded = "embedded"
+	StorageTypeMemory   = "memory"
 
 	DefaultCompactInterval = 5 * time.Minute
+
+	DefaultWatchCacheSnapshotInterval = time.Minute
 )
 
 // TransportConfig holds all connection related info,  i.e. equal TransportConfig means equal servers we talk to.
@@ -40,14 +44,35 @@ type TransportConfig struct {
 	CAFile   string
 }
 
+// ShardingConfig configures the sharding of a resource across several storage
+// backends of the same type.
+type ShardingConfig struct {
+	// Shards holds the connection info of the shards, in order. Objects are
+	// assigned to a shard by the hash of their namespace, or of their name if
+	// they are cluster-scoped, so the shards must not change once the resource
+	// holds objects.
+	Shards []TransportConfig
+}
+
 // Config is configuration for creating a storage backend.
 type Config struct {
 	// Type defines the type of storage backend. Default ("") is "etcd3".
 	Type string
 	// Prefix is the prefix to all keys passed to storage.Interface methods.
 	Prefix string
+	// ResourcePrefix is the prefix of the keys of the resource, which
+	// precedes the namespace or name of the objects in their keys. It is
+	// required by sharding and, unless CountMetricPollPeriod is 0, enables
+	// the accounting of the objects of the resource per namespace.
+	ResourcePrefix string
 	// Transport holds all connection related info, i.e. equal TransportConfig means equal servers we talk to.
 	Transport TransportConfig
+	// Sharding, if it has shards, spreads the resource across several backends
+	// instead of storing it in the backend of Transport.
+	Sharding ShardingConfig
+	// DataDir is the directory the embedded storage backend keeps its write-ahead log
+	// and snapshots in. It is ignored by the other backends.
+	DataDir string
 	// Paging indicates whether the server implementation should allow paging (if it is
 	// supported). This is generally configured by feature gating, or by a specific
 	// resource type not wishing to allow paging, and is not intended for end users to
@@ -60,14 +85,32 @@ type Config struct {
 	// to, the EncodeVersioner outputs the gvk the object will be
 	// converted to before persisted in etcd.
 	EncodeVersioner runtime.GroupVersioner
+	// JSONEncoder, if set, encodes objects as JSON in the version chosen by
+	// EncodeVersioner. It is used to evaluate the JSON paths of objects that
+	// are exposed as selectable fields.
+	JSONEncoder runtime.Encoder
 	// Transformer allows the value to be transformed prior to persisting into etcd.
 	Transformer value.Transformer
+	// NewFunc, if set, creates empty objects of the stored type. The etcd3
+	// storage needs it to send watch bookmarks; the embedded and memory
+	// storages never send them.
+	NewFunc func() runtime.Object
 
 	// CompactionInterval is an interval of requesting compaction from apiserver.
 	// If the value is 0, no compaction will be issued.
 	CompactionInterval time.Duration
 	// CountMetricPollPeriod specifies how often should count metric be updated
 	CountMetricPollPeriod time.Duration
+
+	// WatchCacheSnapshotDir, if not empty, is the directory the watch caches
+	// periodically save their state in, to restore it on restart instead of
+	// relisting the storage.
+	WatchCacheSnapshotDir string
+	// WatchCacheSnapshotInterval is how often the watch caches save their state.
+	WatchCacheSnapshotInterval time.Duration
+	// WatchCacheConsistencyCheckInterval, if positive, is how often the watch
+	// caches are compared with the storage. If the value is 0, they are not.
+	WatchCacheConsistencyCheckInterval time.Duration
 }
 
 func NewDefaultConfig(prefix string, codec runtime.Codec) *Config {
@@ -76,5 +119,7 @@ func NewDefaultConfig(prefix string, codec runtime.Codec) *Config {
 		Prefix:             prefix,
 		Codec:              codec,
 		CompactionInterval: DefaultCompactInterval,
+
+		WatchCacheSnapshotInterval: DefaultWatchCacheSnapshotInterval,
 	}
 }
@@ -0,0 +1,136 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package factory
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+
+	utilruntime "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/util/runtime"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/embedded"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/storagebackend"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/value"
+)
+
+type openBackend struct {
+	backend *embedded.Backend
+	cancel  context.CancelFunc
+	refs    int
+}
+
+var (
+	backendsLock sync.Mutex
+	backends     = map[string]*openBackend{}
+)
+
+// openBackendOnce opens one backend per key, so that all resources stored in
+// it share a single keyspace and revision sequence. A release func is
+// returned. If all release funcs for the key are called, the backend is closed.
+func openBackendOnce(key string, c storagebackend.Config, open func() (*embedded.Backend, error)) (*embedded.Backend, func(), error) {
+	backendsLock.Lock()
+	defer backendsLock.Unlock()
+
+	b, found := backends[key]
+	if !found {
+		backend, err := open()
+		if err != nil {
+			return nil, nil, err
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		embedded.StartCompactor(ctx, backend, c.CompactionInterval)
+		b = &openBackend{backend: backend, cancel: cancel}
+		backends[key] = b
+	}
+	b.refs++
+
+	return b.backend, func() {
+		backendsLock.Lock()
+		defer backendsLock.Unlock()
+
+		b.refs--
+		if b.refs == 0 {
+			b.cancel()
+			if err := b.backend.Close(); err != nil {
+				utilruntime.HandleError(err)
+			}
+			delete(backends, key)
+		}
+	}, nil
+}
+
+// newBackendStorage returns a storage.Interface for the backend opened under key.
+func newBackendStorage(key string, c storagebackend.Config, open func() (*embedded.Backend, error)) (storage.Interface, DestroyFunc, error) {
+	backend, release, err := openBackendOnce(key, c, open)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var once sync.Once
+	destroyFunc := func() {
+		// storage destroy funcs are called multiple times (due to reuse in subresources).
+		// Hence, we only release once.
+		once.Do(release)
+	}
+	transformer := c.Transformer
+	if transformer == nil {
+		transformer = value.IdentityTransformer
+	}
+	return embedded.New(backend, c.Codec, c.Prefix, transformer, c.Paging), destroyFunc, nil
+}
+
+// backendHealthCheck returns a health check for the backend opened under key.
+func backendHealthCheck(key string) func() error {
+	return func() error {
+		backendsLock.Lock()
+		b, found := backends[key]
+		backendsLock.Unlock()
+		if !found {
+			return fmt.Errorf("storage backend %q is not open", key)
+		}
+		return b.backend.Healthy()
+	}
+}
+
+// dataDir returns the absolute data directory of the embedded backend, which
+// is the key it is opened under.
+func dataDir(c storagebackend.Config) (string, error) {
+	if len(c.DataDir) == 0 {
+		return "", fmt.Errorf("a data directory is required for the %s storage backend", c.Type)
+	}
+	return filepath.Abs(c.DataDir)
+}
+
+func newEmbeddedStorage(c storagebackend.Config) (storage.Interface, DestroyFunc, error) {
+	dir, err := dataDir(c)
+	if err != nil {
+		return nil, nil, err
+	}
+	return newBackendStorage(dir, c, func() (*embedded.Backend, error) {
+		return embedded.Open(dir)
+	})
+}
+
+func newEmbeddedHealthCheck(c storagebackend.Config) (func() error, error) {
+	dir, err := dataDir(c)
+	if err != nil {
+		return nil, err
+	}
+	return backendHealthCheck(dir), nil
+}
@@ -185,19 +185,33 @@ func newETCD3Storage(c storagebackend.Config) (storage.Interface, DestroyFunc, e
 		return nil, nil, err
 	}
 
+	transformer := c.Transformer
+	if transformer == nil {
+		transformer = value.IdentityTransformer
+	}
+	var opts []etcd3.Option
+	if c.NewFunc != nil {
+		opts = append(opts, etcd3.WithBookmarks(c.NewFunc))
+	}
+	var store storage.Interface
+	stopUsage := func() {}
+	// The usage is not accounted when the object count is not polled either.
+	if len(c.ResourcePrefix) > 0 && c.CountMetricPollPeriod > 0 {
+		store, stopUsage = etcd3.NewWithUsage(client, c.Codec, c.Prefix, c.ResourcePrefix, transformer, c.Paging, opts...)
+	} else {
+		store = etcd3.New(client, c.Codec, c.Prefix, transformer, c.Paging, opts...)
+	}
+
 	var once sync.Once
 	destroyFunc := func() {
 		// we know that storage destroy funcs are called multiple times (due to reuse in subresources).
 		// Hence, we only destroy once.
 		// TODO: fix duplicated storage destroy calls higher level
 		once.Do(func() {
+			stopUsage()
 			stopCompactor()
 			client.Close()
 		})
 	}
-	transformer := c.Transformer
-	if transformer == nil {
-		transformer = value.IdentityTransformer
-	}
-	return etcd3.New(client, c.Codec, c.Prefix, transformer, c.Paging), destroyFunc, nil
+	return store, destroyFunc, nil
 }
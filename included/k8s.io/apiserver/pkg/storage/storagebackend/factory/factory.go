@@ -20,6 +20,7 @@ import (
 	"fmt"
 
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/sharded"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/storagebackend"
 )
 
@@ -28,16 +29,51 @@ type DestroyFunc func()
 
 // Create creates a storage backend based on given config.
 func Create(c storagebackend.Config) (storage.Interface, DestroyFunc, error) {
+	if len(c.Sharding.Shards) > 0 {
+		return newShardedStorage(c)
+	}
 	switch c.Type {
 	case "etcd2":
 		return nil, nil, fmt.Errorf("%v is no longer a supported storage backend", c.Type)
 	case storagebackend.StorageTypeUnset, storagebackend.StorageTypeETCD3:
 		return newETCD3Storage(c)
+	case storagebackend.StorageTypeEmbedded:
+		return newEmbeddedStorage(c)
+	case storagebackend.StorageTypeMemory:
+		return newMemoryStorage(c)
 	default:
 		return nil, nil, fmt.Errorf("unknown storage type: %s", c.Type)
 	}
 }
 
+// newShardedStorage creates the storage of every shard of c and routes the
+// keys of the resource to them.
+func newShardedStorage(c storagebackend.Config) (storage.Interface, DestroyFunc, error) {
+	if c.Type != storagebackend.StorageTypeUnset && c.Type != storagebackend.StorageTypeETCD3 {
+		return nil, nil, fmt.Errorf("only the %s storage backend can be sharded", storagebackend.StorageTypeETCD3)
+	}
+	shards := make([]storage.Interface, 0, len(c.Sharding.Shards))
+	destroyFuncs := make([]DestroyFunc, 0, len(c.Sharding.Shards))
+	destroyFunc := func() {
+		for _, destroy := range destroyFuncs {
+			destroy()
+		}
+	}
+	for _, transport := range c.Sharding.Shards {
+		shardConfig := c
+		shardConfig.Transport = transport
+		shardConfig.Sharding = storagebackend.ShardingConfig{}
+		s, d, err := Create(shardConfig)
+		if err != nil {
+			destroyFunc()
+			return nil, nil, err
+		}
+		shards = append(shards, s)
+		destroyFuncs = append(destroyFuncs, d)
+	}
+	return sharded.New(shards, c.ResourcePrefix, c.Paging), destroyFunc, nil
+}
+
 // CreateHealthCheck creates a healthcheck function based on given config.
 func CreateHealthCheck(c storagebackend.Config) (func() error, error) {
 	switch c.Type {
@@ -45,6 +81,10 @@ func CreateHealthCheck(c storagebackend.Config) (func() error, error) {
 		return nil, fmt.Errorf("%v is no longer a supported storage backend", c.Type)
 	case storagebackend.StorageTypeUnset, storagebackend.StorageTypeETCD3:
 		return newETCD3HealthCheck(c)
+	case storagebackend.StorageTypeEmbedded:
+		return newEmbeddedHealthCheck(c)
+	case storagebackend.StorageTypeMemory:
+		return newMemoryHealthCheck(c)
 	default:
 		return nil, fmt.Errorf("unknown storage type: %s", c.Type)
 	}
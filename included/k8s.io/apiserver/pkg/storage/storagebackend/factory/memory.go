@@ -0,0 +1,40 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package factory
+
+import (
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/embedded"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/storagebackend"
+)
+
+// memoryBackendKey is the key the memory backend is opened under. Data
+// directories are absolute paths, so it cannot collide with them.
+const memoryBackendKey = "memory"
+
+// newMemoryStorage returns a storage.Interface backed by the process-wide
+// memory backend. Like the resources stored in one etcd cluster, all resources
+// share its keyspace until the last of them is destroyed, which discards it.
+func newMemoryStorage(c storagebackend.Config) (storage.Interface, DestroyFunc, error) {
+	return newBackendStorage(memoryBackendKey, c, func() (*embedded.Backend, error) {
+		return embedded.NewMemory(), nil
+	})
+}
+
+func newMemoryHealthCheck(c storagebackend.Config) (func() error, error) {
+	return backendHealthCheck(memoryBackendKey), nil
+}
@@ -0,0 +1,683 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	corev1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/core/v1"
+	apierrors "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/api/errors"
+	metav1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/fields"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/labels"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime/serializer"
+	utilruntime "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/util/runtime"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/util/wait"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/watch"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage"
+)
+
+var scheme = runtime.NewScheme()
+var codecs = serializer.NewCodecFactory(scheme)
+
+// Codec is the codec the stores under test must use. The suite stores core/v1
+// Pods and PodLists.
+var Codec = codecs.CodecForVersions(codecs.LegacyCodec(corev1.SchemeGroupVersion), codecs.UniversalDeserializer(), corev1.SchemeGroupVersion, corev1.SchemeGroupVersion)
+
+func init() {
+	metav1.AddToGroupVersion(scheme, metav1.SchemeGroupVersion)
+	utilruntime.Must(corev1.AddToScheme(scheme))
+}
+
+// StoreFactory returns an empty store that uses Codec, together with a
+// function that compacts the history of the store up to and including the
+// given resource version, and a function that releases the store.
+type StoreFactory func(t *testing.T) (s storage.Interface, compact func(resourceVersion string), destroy func())
+
+// RunConformanceTests runs every conformance case against a fresh store
+// returned by newStore.
+func RunConformanceTests(t *testing.T, newStore StoreFactory) {
+	cases := []struct {
+		name string
+		test func(context.Context, *testing.T, storage.Interface, func(string))
+	}{
+		{"Create", testCreate},
+		{"CreateWithTTL", testCreateWithTTL},
+		{"Get", testGet},
+		{"ConditionalDelete", testConditionalDelete},
+		{"GuaranteedUpdate", testGuaranteedUpdate},
+		{"GuaranteedUpdateWithConflict", testGuaranteedUpdateWithConflict},
+		{"GetToList", testGetToList},
+		{"List", testList},
+		{"ListContinuation", testListContinuation},
+		{"ListInconsistentContinuation", testListInconsistentContinuation},
+		{"Watch", testWatch},
+		{"WatchFromCompactedRevision", testWatchFromCompactedRevision},
+		{"Count", testCount},
+		{"Transact", testTransact},
+		{"ChangedSince", testChangedSince},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s, compact, destroy := newStore(t)
+			defer destroy()
+			c.test(context.Background(), t, s, compact)
+		})
+	}
+}
+
+func newPod(name string) *corev1.Pod {
+	return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name}}
+}
+
+func create(ctx context.Context, t *testing.T, s storage.Interface, key string, obj *corev1.Pod) *corev1.Pod {
+	out := &corev1.Pod{}
+	if err := s.Create(ctx, key, obj, out, 0); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	return out
+}
+
+func setNodeName(node string) storage.UpdateFunc {
+	return storage.SimpleUpdate(func(obj runtime.Object) (runtime.Object, error) {
+		pod := obj.(*corev1.Pod)
+		pod.Spec.NodeName = node
+		return pod, nil
+	})
+}
+
+func everything(limit int64, continueValue string) storage.SelectionPredicate {
+	return storage.SelectionPredicate{
+		Label:    labels.Everything(),
+		Field:    fields.Everything(),
+		Limit:    limit,
+		Continue: continueValue,
+	}
+}
+
+func expectEvent(t *testing.T, w watch.Interface, expectType watch.EventType, expectObj runtime.Object) {
+	select {
+	case res, ok := <-w.ResultChan():
+		if !ok {
+			t.Fatalf("watch closed, expected a %v event", expectType)
+		}
+		if res.Type != expectType {
+			t.Fatalf("event type want=%v, get=%v (%#v)", expectType, res.Type, res.Object)
+		}
+		if expectObj != nil && !reflect.DeepEqual(expectObj, res.Object) {
+			t.Errorf("obj want=\n%#v\nget=\n%#v", expectObj, res.Object)
+		}
+	case <-time.After(wait.ForeverTestTimeout):
+		t.Fatalf("time out after waiting %v on ResultChan", wait.ForeverTestTimeout)
+	}
+}
+
+func expectNoEvent(t *testing.T, w watch.Interface) {
+	select {
+	case res := <-w.ResultChan():
+		t.Errorf("unexpected event: %#v", res)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func testCreate(ctx context.Context, t *testing.T, s storage.Interface, _ func(string)) {
+	obj := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo", SelfLink: "testlink"}}
+	out := create(ctx, t, s, "/testkey", obj)
+	if out.Name != "foo" {
+		t.Errorf("pod name want=foo, get=%s", out.Name)
+	}
+	if out.ResourceVersion == "" {
+		t.Errorf("output should have non-empty resource version")
+	}
+	if out.SelfLink != "" {
+		t.Errorf("output should have empty self link")
+	}
+	if obj.ResourceVersion != "" {
+		t.Errorf("input object must not be mutated")
+	}
+
+	if err := s.Create(ctx, "/testkey", newPod("foo"), nil, 0); !storage.IsNodeExist(err) {
+		t.Errorf("expecting key exists error, but get: %v", err)
+	}
+	if err := s.Create(ctx, "/otherkey", &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo", ResourceVersion: "1"}}, nil, 0); err == nil {
+		t.Errorf("expecting an error for an object with a resource version")
+	}
+}
+
+func testCreateWithTTL(ctx context.Context, t *testing.T, s storage.Interface, _ func(string)) {
+	out := &corev1.Pod{}
+	if err := s.Create(ctx, "/somekey", newPod("foo"), out, 1); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	w, err := s.Watch(ctx, "/somekey", out.ResourceVersion, storage.Everything)
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+	defer w.Stop()
+	expectEvent(t, w, watch.Deleted, nil)
+}
+
+func testGet(ctx context.Context, t *testing.T, s storage.Interface, _ func(string)) {
+	stored := create(ctx, t, s, "/testkey", newPod("foo"))
+
+	out := &corev1.Pod{}
+	if err := s.Get(ctx, "/testkey", "", out, false); err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if !reflect.DeepEqual(stored, out) {
+		t.Errorf("pod want=%#v, get=%#v", stored, out)
+	}
+
+	if err := s.Get(ctx, "/non-existing", "", out, false); !storage.IsNotFound(err) {
+		t.Errorf("expecting not found error, but get: %v", err)
+	}
+	empty := &corev1.Pod{}
+	if err := s.Get(ctx, "/non-existing", "", empty, true); err != nil {
+		t.Errorf("Get with ignoreNotFound failed: %v", err)
+	}
+	if !reflect.DeepEqual(empty, &corev1.Pod{}) {
+		t.Errorf("expecting an empty object, but get: %#v", empty)
+	}
+}
+
+func testConditionalDelete(ctx context.Context, t *testing.T, s storage.Interface, _ func(string)) {
+	stored := create(ctx, t, s, "/testkey", &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo", UID: "A"}})
+
+	out := &corev1.Pod{}
+	if err := s.Delete(ctx, "/testkey", out, storage.NewUIDPreconditions("B"), storage.ValidateAllObjectFunc); !storage.IsInvalidObj(err) {
+		t.Fatalf("expecting invalid UID error, but get: %v", err)
+	}
+	if err := s.Delete(ctx, "/testkey", out, storage.NewUIDPreconditions("A"), storage.ValidateAllObjectFunc); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if !reflect.DeepEqual(stored, out) {
+		t.Errorf("pod want=%#v, get=%#v", stored, out)
+	}
+	if err := s.Delete(ctx, "/testkey", out, nil, storage.ValidateAllObjectFunc); !storage.IsNotFound(err) {
+		t.Errorf("expecting not found error, but get: %v", err)
+	}
+}
+
+func testGuaranteedUpdate(ctx context.Context, t *testing.T, s storage.Interface, _ func(string)) {
+	stored := create(ctx, t, s, "/testkey", &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo", UID: "A"}})
+
+	out := &corev1.Pod{}
+	if err := s.GuaranteedUpdate(ctx, "/testkey", out, false, storage.NewUIDPreconditions("B"), setNodeName("node1")); !storage.IsInvalidObj(err) {
+		t.Fatalf("expecting invalid UID error, but get: %v", err)
+	}
+	if err := s.GuaranteedUpdate(ctx, "/testkey", out, false, storage.NewUIDPreconditions("A"), setNodeName("node1")); err != nil {
+		t.Fatalf("GuaranteedUpdate failed: %v", err)
+	}
+	if out.Spec.NodeName != "node1" || out.ResourceVersion == stored.ResourceVersion {
+		t.Errorf("unexpected update result: %#v", out)
+	}
+
+	// an update that does not change the object must not create a new revision
+	unchanged := &corev1.Pod{}
+	if err := s.GuaranteedUpdate(ctx, "/testkey", unchanged, false, nil, setNodeName("node1")); err != nil {
+		t.Fatalf("GuaranteedUpdate failed: %v", err)
+	}
+	if unchanged.ResourceVersion != out.ResourceVersion {
+		t.Errorf("no-op update changed the resource version from %s to %s", out.ResourceVersion, unchanged.ResourceVersion)
+	}
+
+	// a stale suggestion must be refreshed instead of overwriting the newer object
+	if err := s.GuaranteedUpdate(ctx, "/testkey", out, false, nil, setNodeName("node2"), stored); err != nil {
+		t.Fatalf("GuaranteedUpdate failed: %v", err)
+	}
+	if out.Spec.NodeName != "node2" {
+		t.Errorf("unexpected update result: %#v", out)
+	}
+
+	if err := s.GuaranteedUpdate(ctx, "/non-existing", out, false, nil, setNodeName("node1")); !storage.IsNotFound(err) {
+		t.Errorf("expecting not found error, but get: %v", err)
+	}
+	created := &corev1.Pod{}
+	if err := s.GuaranteedUpdate(ctx, "/non-existing", created, true, nil, setNodeName("node1")); err != nil {
+		t.Fatalf("GuaranteedUpdate with ignoreNotFound failed: %v", err)
+	}
+	if created.Spec.NodeName != "node1" || created.ResourceVersion == "" {
+		t.Errorf("unexpected create result: %#v", created)
+	}
+}
+
+func testGuaranteedUpdateWithConflict(ctx context.Context, t *testing.T, s storage.Interface, _ func(string)) {
+	create(ctx, t, s, "/testkey", newPod("foo"))
+
+	const updates = 5
+	var wg sync.WaitGroup
+	errs := make(chan error, updates)
+	for i := 0; i < updates; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- s.GuaranteedUpdate(ctx, "/testkey", &corev1.Pod{}, false, nil, storage.SimpleUpdate(func(obj runtime.Object) (runtime.Object, error) {
+				pod := obj.(*corev1.Pod)
+				count, _ := strconv.Atoi(pod.Annotations["count"])
+				pod.Annotations = map[string]string{"count": strconv.Itoa(count + 1)}
+				return pod, nil
+			}))
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("GuaranteedUpdate failed: %v", err)
+		}
+	}
+
+	out := &corev1.Pod{}
+	if err := s.Get(ctx, "/testkey", "", out, false); err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if out.Annotations["count"] != strconv.Itoa(updates) {
+		t.Errorf("expected every conflicting update to be retried, got count %q", out.Annotations["count"])
+	}
+}
+
+func testGetToList(ctx context.Context, t *testing.T, s storage.Interface, _ func(string)) {
+	stored := create(ctx, t, s, "/testkey", newPod("foo"))
+
+	out := &corev1.PodList{}
+	if err := s.GetToList(ctx, "/testkey", "", storage.Everything, out); err != nil {
+		t.Fatalf("GetToList failed: %v", err)
+	}
+	if !reflect.DeepEqual(out.Items, []corev1.Pod{*stored}) {
+		t.Errorf("unexpected list: %#v", out.Items)
+	}
+
+	pred := storage.SelectionPredicate{
+		Label: labels.Everything(),
+		Field: fields.ParseSelectorOrDie("metadata.name!=foo"),
+		GetAttrs: func(obj runtime.Object) (labels.Set, fields.Set, error) {
+			pod := obj.(*corev1.Pod)
+			return nil, fields.Set{"metadata.name": pod.Name}, nil
+		},
+	}
+	out = &corev1.PodList{}
+	if err := s.GetToList(ctx, "/testkey", "", pred, out); err != nil {
+		t.Fatalf("GetToList failed: %v", err)
+	}
+	if len(out.Items) != 0 {
+		t.Errorf("expected the predicate to filter out the object, got: %#v", out.Items)
+	}
+
+	out = &corev1.PodList{}
+	if err := s.GetToList(ctx, "/non-existing", "", storage.Everything, out); err != nil {
+		t.Fatalf("GetToList failed: %v", err)
+	}
+	if len(out.Items) != 0 || out.ResourceVersion == "" {
+		t.Errorf("expected an empty list with a resource version, got: %#v", out)
+	}
+}
+
+func testList(ctx context.Context, t *testing.T, s storage.Interface, _ func(string)) {
+	a := create(ctx, t, s, "/pods/a", &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "a", Labels: map[string]string{"app": "web"}}})
+	b := create(ctx, t, s, "/pods/b", newPod("b"))
+	create(ctx, t, s, "/podsx/c", newPod("c"))
+
+	out := &corev1.PodList{}
+	if err := s.List(ctx, "/pods", "", storage.Everything, out); err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if !reflect.DeepEqual(out.Items, []corev1.Pod{*a, *b}) {
+		t.Errorf("unexpected list: %#v", out.Items)
+	}
+	if out.ResourceVersion == "" {
+		t.Errorf("list should have non-empty resource version")
+	}
+
+	pred := storage.SelectionPredicate{
+		Label: labels.SelectorFromSet(labels.Set{"app": "web"}),
+		Field: fields.Everything(),
+		GetAttrs: func(obj runtime.Object) (labels.Set, fields.Set, error) {
+			return labels.Set(obj.(*corev1.Pod).Labels), nil, nil
+		},
+	}
+	out = &corev1.PodList{}
+	if err := s.List(ctx, "/pods", "", pred, out); err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if !reflect.DeepEqual(out.Items, []corev1.Pod{*a}) {
+		t.Errorf("unexpected filtered list: %#v", out.Items)
+	}
+
+	// the list at an older resource version must not include later changes
+	if err := s.Delete(ctx, "/pods/a", &corev1.Pod{}, nil, storage.ValidateAllObjectFunc); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	out = &corev1.PodList{}
+	if err := s.List(ctx, "/pods", b.ResourceVersion, everything(10, ""), out); err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if !reflect.DeepEqual(out.Items, []corev1.Pod{*a, *b}) {
+		t.Errorf("unexpected list at resource version %s: %#v", b.ResourceVersion, out.Items)
+	}
+}
+
+func testListContinuation(ctx context.Context, t *testing.T, s storage.Interface, _ func(string)) {
+	var preset []*corev1.Pod
+	for _, name := range []string{"a", "b", "c"} {
+		preset = append(preset, create(ctx, t, s, "/pods/"+name, newPod(name)))
+	}
+	listRV := preset[2].ResourceVersion
+
+	out := &corev1.PodList{}
+	if err := s.List(ctx, "/pods", "", everything(1, ""), out); err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if !reflect.DeepEqual(out.Items, []corev1.Pod{*preset[0]}) {
+		t.Fatalf("unexpected first page: %#v", out.Items)
+	}
+	if out.RemainingItemCount == nil || *out.RemainingItemCount != 2 {
+		t.Errorf("remaining item count want=2, get=%v", out.RemainingItemCount)
+	}
+	if len(out.Continue) == 0 {
+		t.Fatalf("no continuation token set")
+	}
+
+	// changes after the first page must not be visible in later pages
+	if err := s.Delete(ctx, "/pods/b", &corev1.Pod{}, nil, storage.ValidateAllObjectFunc); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+
+	out2 := &corev1.PodList{}
+	if err := s.List(ctx, "/pods", "", everything(0, out.Continue), out2); err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if !reflect.DeepEqual(out2.Items, []corev1.Pod{*preset[1], *preset[2]}) {
+		t.Errorf("unexpected second page: %#v", out2.Items)
+	}
+	if len(out2.Continue) != 0 {
+		t.Errorf("unexpected continuation token on the last page: %s", out2.Continue)
+	}
+	if out2.ResourceVersion != listRV {
+		t.Errorf("list resource version want=%s, get=%s", listRV, out2.ResourceVersion)
+	}
+
+	if err := s.List(ctx, "/pods", "", everything(0, "not-a-token"), &corev1.PodList{}); err == nil {
+		t.Errorf("expecting an error for an invalid continue token")
+	}
+}
+
+func testListInconsistentContinuation(ctx context.Context, t *testing.T, s storage.Interface, compact func(string)) {
+	var preset []*corev1.Pod
+	for _, name := range []string{"a", "b", "c"} {
+		preset = append(preset, create(ctx, t, s, "/pods/"+name, newPod(name)))
+	}
+
+	out := &corev1.PodList{}
+	if err := s.List(ctx, "/pods", "", everything(1, ""), out); err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(out.Continue) == 0 {
+		t.Fatalf("no continuation token set")
+	}
+
+	updated := &corev1.Pod{}
+	if err := s.GuaranteedUpdate(ctx, "/pods/c", updated, false, nil, setNodeName("node1")); err != nil {
+		t.Fatalf("GuaranteedUpdate failed: %v", err)
+	}
+	compact(updated.ResourceVersion)
+
+	err := s.List(ctx, "/pods", "", everything(0, out.Continue), &corev1.PodList{})
+	if !apierrors.IsResourceExpired(err) {
+		t.Fatalf("expected resource expired error, got %v", err)
+	}
+	status, ok := err.(apierrors.APIStatus)
+	if !ok || len(status.Status().ListMeta.Continue) == 0 {
+		t.Fatalf("expected a continue token in the error, got %v", err)
+	}
+
+	// the inconsistent continue token lists the rest at the latest revision
+	out2 := &corev1.PodList{}
+	if err := s.List(ctx, "/pods", "", everything(0, status.Status().ListMeta.Continue), out2); err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if !reflect.DeepEqual(out2.Items, []corev1.Pod{*preset[1], *updated}) {
+		t.Errorf("unexpected inconsistent page: %#v", out2.Items)
+	}
+	if out2.ResourceVersion != updated.ResourceVersion {
+		t.Errorf("list resource version want=%s, get=%s", updated.ResourceVersion, out2.ResourceVersion)
+	}
+
+	err = s.List(ctx, "/pods", preset[0].ResourceVersion, everything(1, ""), &corev1.PodList{})
+	if !apierrors.IsResourceExpired(err) {
+		t.Errorf("expected resource expired error for a compacted resource version, got %v", err)
+	}
+}
+
+func testWatch(ctx context.Context, t *testing.T, s storage.Interface, _ func(string)) {
+	created := create(ctx, t, s, "/pods/a", newPod("a"))
+	updated := &corev1.Pod{}
+	if err := s.GuaranteedUpdate(ctx, "/pods/a", updated, false, nil, setNodeName("node1")); err != nil {
+		t.Fatalf("GuaranteedUpdate failed: %v", err)
+	}
+
+	// a watch from a past resource version replays the changes after it
+	w, err := s.WatchList(ctx, "/pods", created.ResourceVersion, storage.Everything)
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+	defer w.Stop()
+	expectEvent(t, w, watch.Modified, updated)
+
+	// a watch on a single key ignores other keys
+	single, err := s.Watch(ctx, "/pods/b", updated.ResourceVersion, storage.Everything)
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+	defer single.Stop()
+
+	if err := s.Delete(ctx, "/pods/a", &corev1.Pod{}, nil, storage.ValidateAllObjectFunc); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	expectEvent(t, w, watch.Deleted, nil)
+	b := create(ctx, t, s, "/pods/b", newPod("b"))
+	expectEvent(t, w, watch.Added, b)
+	expectEvent(t, single, watch.Added, b)
+	expectNoEvent(t, single)
+
+	// a watch from "0" starts with the current state
+	w0, err := s.WatchList(ctx, "/pods", "0", storage.Everything)
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+	defer w0.Stop()
+	expectEvent(t, w0, watch.Added, b)
+
+	// objects that stop matching the predicate are reported as deleted
+	pred := storage.SelectionPredicate{
+		Label: labels.Everything(),
+		Field: fields.ParseSelectorOrDie("spec.nodeName="),
+		GetAttrs: func(obj runtime.Object) (labels.Set, fields.Set, error) {
+			return nil, fields.Set{"spec.nodeName": obj.(*corev1.Pod).Spec.NodeName}, nil
+		},
+	}
+	filtered, err := s.WatchList(ctx, "/pods", b.ResourceVersion, pred)
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+	defer filtered.Stop()
+	if err := s.GuaranteedUpdate(ctx, "/pods/b", &corev1.Pod{}, false, nil, setNodeName("node1")); err != nil {
+		t.Fatalf("GuaranteedUpdate failed: %v", err)
+	}
+	expectEvent(t, filtered, watch.Deleted, nil)
+}
+
+func testWatchFromCompactedRevision(ctx context.Context, t *testing.T, s storage.Interface, compact func(string)) {
+	first := create(ctx, t, s, "/pods/a", newPod("a"))
+	create(ctx, t, s, "/pods/b", newPod("b"))
+	last := create(ctx, t, s, "/pods/c", newPod("c"))
+	compact(last.ResourceVersion)
+
+	w, err := s.WatchList(ctx, "/pods", first.ResourceVersion, storage.Everything)
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+	defer w.Stop()
+	select {
+	case res := <-w.ResultChan():
+		status, ok := res.Object.(*metav1.Status)
+		if res.Type != watch.Error || !ok || status.Code != 410 {
+			t.Errorf("expected a 410 error event, got %#v", res)
+		}
+	case <-time.After(wait.ForeverTestTimeout):
+		t.Fatalf("time out after waiting %v on ResultChan", wait.ForeverTestTimeout)
+	}
+}
+
+func testCount(ctx context.Context, t *testing.T, s storage.Interface, _ func(string)) {
+	for _, name := range []string{"a", "b", "c"} {
+		create(ctx, t, s, "/pods/"+name, newPod(name))
+	}
+	create(ctx, t, s, "/services/a", newPod("a"))
+	if err := s.Delete(ctx, "/pods/b", &corev1.Pod{}, nil, storage.ValidateAllObjectFunc); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+
+	count, err := s.Count("/pods")
+	if err != nil {
+		t.Fatalf("Count failed: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("count want=2, get=%d", count)
+	}
+}
+
+func testTransact(ctx context.Context, t *testing.T, s storage.Interface, _ func(string)) {
+	a := create(ctx, t, s, "/pods/a", newPod("a"))
+	b := create(ctx, t, s, "/pods/b", &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "b", UID: "B"}})
+	w, err := s.WatchList(ctx, "/pods", b.ResourceVersion, storage.Everything)
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+	defer w.Stop()
+
+	updatedA := a.DeepCopy()
+	updatedA.Spec.NodeName = "node1"
+	ops := []storage.TxnOp{
+		{Type: storage.TxnCreate, Key: "/pods/c", Obj: newPod("c"), Out: &corev1.Pod{}},
+		{Type: storage.TxnUpdate, Key: "/pods/a", Obj: updatedA, Out: &corev1.Pod{}, Preconditions: &storage.Preconditions{ResourceVersion: &a.ResourceVersion}},
+		{Type: storage.TxnDelete, Key: "/pods/b", Out: &corev1.Pod{}, Preconditions: storage.NewUIDPreconditions("B")},
+	}
+	if err := s.Transact(ctx, ops); err != nil {
+		t.Fatalf("Transact failed: %v", err)
+	}
+	created, updated, deleted := ops[0].Out.(*corev1.Pod), ops[1].Out.(*corev1.Pod), ops[2].Out.(*corev1.Pod)
+	if created.ResourceVersion == "" || created.ResourceVersion != updated.ResourceVersion {
+		t.Errorf("operations committed at different resource versions %q and %q", created.ResourceVersion, updated.ResourceVersion)
+	}
+	if updated.Spec.NodeName != "node1" {
+		t.Errorf("unexpected update result: %#v", updated)
+	}
+	if !reflect.DeepEqual(b, deleted) {
+		t.Errorf("deleted pod want=%#v, get=%#v", b, deleted)
+	}
+	expectEvent(t, w, watch.Added, created)
+	expectEvent(t, w, watch.Modified, updated)
+	expectEvent(t, w, watch.Deleted, nil)
+
+	// a failing operation aborts the whole transaction
+	ops = []storage.TxnOp{
+		{Type: storage.TxnCreate, Key: "/pods/d", Obj: newPod("d"), Out: &corev1.Pod{}},
+		{Type: storage.TxnUpdate, Key: "/pods/a", Obj: updatedA, Out: &corev1.Pod{}, Preconditions: &storage.Preconditions{ResourceVersion: &a.ResourceVersion}},
+	}
+	err = s.Transact(ctx, ops)
+	if opErr, ok := err.(*storage.TxnOpError); !ok || opErr.Index != 1 || !storage.IsInvalidObj(opErr.Err) {
+		t.Fatalf("expecting a failed precondition of operation 1, but get: %v", err)
+	}
+	if err := s.Get(ctx, "/pods/d", "", &corev1.Pod{}, false); !storage.IsNotFound(err) {
+		t.Errorf("expecting not found error, but get: %v", err)
+	}
+	expectNoEvent(t, w)
+
+	ops = []storage.TxnOp{
+		{Type: storage.TxnDelete, Key: "/pods/b", Out: &corev1.Pod{}},
+	}
+	if err := s.Transact(ctx, ops); !isTxnOpError(err, 0, storage.IsNotFound) {
+		t.Errorf("expecting not found error of operation 0, but get: %v", err)
+	}
+	ops = []storage.TxnOp{
+		{Type: storage.TxnCreate, Key: "/pods/c", Obj: newPod("c"), Out: &corev1.Pod{}},
+	}
+	if err := s.Transact(ctx, ops); !isTxnOpError(err, 0, storage.IsNodeExist) {
+		t.Errorf("expecting key exists error of operation 0, but get: %v", err)
+	}
+}
+
+func testChangedSince(ctx context.Context, t *testing.T, s storage.Interface, compact func(string)) {
+	detector, ok := s.(storage.ChangeDetector)
+	if !ok {
+		t.Skip("storage does not implement storage.ChangeDetector")
+	}
+	changedSince := func(resourceVersion string) (uint64, bool) {
+		rv, err := strconv.ParseUint(resourceVersion, 10, 64)
+		if err != nil {
+			t.Fatal(err)
+		}
+		current, changed, err := detector.ChangedSince(ctx, "/pods", rv)
+		if err != nil {
+			t.Fatalf("ChangedSince failed: %v", err)
+		}
+		return current, changed
+	}
+
+	a := create(ctx, t, s, "/pods/a", newPod("a"))
+	b := create(ctx, t, s, "/pods/b", newPod("b"))
+	// Changes of other resources do not count.
+	other := create(ctx, t, s, "/podsx/a", newPod("a"))
+	if current, changed := changedSince(b.ResourceVersion); changed || strconv.FormatUint(current, 10) != other.ResourceVersion {
+		t.Errorf("want unchanged at %s, get changed=%v at %d", other.ResourceVersion, changed, current)
+	}
+	if _, changed := changedSince(a.ResourceVersion); !changed {
+		t.Errorf("creation after %s not detected", a.ResourceVersion)
+	}
+
+	updated := &corev1.Pod{}
+	if err := s.GuaranteedUpdate(ctx, "/pods/a", updated, false, nil, setNodeName("node1")); err != nil {
+		t.Fatalf("GuaranteedUpdate failed: %v", err)
+	}
+	if _, changed := changedSince(b.ResourceVersion); !changed {
+		t.Errorf("update after %s not detected", b.ResourceVersion)
+	}
+	if err := s.Delete(ctx, "/pods/b", &corev1.Pod{}, nil, storage.ValidateAllObjectFunc); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, changed := changedSince(updated.ResourceVersion); !changed {
+		t.Errorf("deletion after %s not detected", updated.ResourceVersion)
+	}
+
+	// Changes before a compacted revision are unknown.
+	last := create(ctx, t, s, "/services/a", newPod("a"))
+	compact(last.ResourceVersion)
+	if _, changed := changedSince(a.ResourceVersion); !changed {
+		t.Errorf("changes before the compacted revision %s not reported", last.ResourceVersion)
+	}
+}
+
+func isTxnOpError(err error, index int, is func(error) bool) bool {
+	opErr, ok := err.(*storage.TxnOpError)
+	return ok && opErr.Index == index && is(opErr.Err)
+}
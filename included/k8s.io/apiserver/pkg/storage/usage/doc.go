@@ -0,0 +1,22 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package usage keeps track of the number of objects of the stored resources
+// and of the bytes they take in storage, per namespace. The usage is loaded
+// once and then kept current by watching the changes to the stored values, so
+// that it accounts the writes of every server and the deletion of expired
+// objects.
+package usage // import "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/usage"
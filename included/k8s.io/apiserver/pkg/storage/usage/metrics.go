@@ -0,0 +1,59 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package usage
+
+import (
+	"sync"
+
+	"github.com/aaron-prindle/krmapiserver/included/github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	namespaceObjects = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "apiserver_storage_namespace_objects",
+			Help: "Number of stored objects split by resource and namespace.",
+		},
+		[]string{"resource", "namespace"},
+	)
+	namespaceBytes = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "apiserver_storage_namespace_bytes",
+			Help: "Total size in bytes of the stored objects split by resource and namespace.",
+		},
+		[]string{"resource", "namespace"},
+	)
+)
+
+var registerMetricsOnce sync.Once
+
+func registerMetrics() {
+	registerMetricsOnce.Do(func() {
+		prometheus.MustRegister(namespaceObjects)
+		prometheus.MustRegister(namespaceBytes)
+	})
+}
+
+func updateNamespaceUsage(resource, namespace string, u Usage) {
+	namespaceObjects.WithLabelValues(resource, namespace).Set(float64(u.Objects))
+	namespaceBytes.WithLabelValues(resource, namespace).Set(float64(u.Bytes))
+}
+
+func deleteNamespaceUsage(resource, namespace string) {
+	namespaceObjects.DeleteLabelValues(resource, namespace)
+	namespaceBytes.DeleteLabelValues(resource, namespace)
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package usage
+
+import (
+	"sync"
+)
+
+type trackerKey struct {
+	backend   string
+	keyPrefix string
+}
+
+type sharedTracker struct {
+	tracker *Tracker
+	refs    int
+	stopCh  chan struct{}
+}
+
+var (
+	trackersLock sync.Mutex
+	trackers     = map[trackerKey]*sharedTracker{}
+)
+
+// Acquire returns the tracker of the objects stored under keyPrefix in
+// backend, which identifies the storage backend, creating it if needed. The
+// stores of the resources that share a key prefix, such as the same resource
+// served by several API groups, share its tracker so that the keys are only
+// loaded and watched once. A new tracker is loaded by calling load right away and kept
+// current by calling watch until the returned func has been called by all of
+// the stores that acquired it. The usage is reported in the metrics under the
+// given resource name.
+func Acquire(backend, keyPrefix, resource string, load LoadFunc, watch WatchFunc) (*Tracker, func()) {
+	registerMetrics()
+
+	key := trackerKey{backend: backend, keyPrefix: keyPrefix}
+	trackersLock.Lock()
+	defer trackersLock.Unlock()
+	shared, ok := trackers[key]
+	if !ok {
+		shared = &sharedTracker{
+			tracker: NewTracker(keyPrefix, resource, load, watch),
+			stopCh:  make(chan struct{}),
+		}
+		trackers[key] = shared
+		go shared.tracker.run(shared.stopCh)
+	}
+	shared.refs++
+
+	var once sync.Once
+	release := func() {
+		once.Do(func() {
+			trackersLock.Lock()
+			defer trackersLock.Unlock()
+			shared.refs--
+			if shared.refs == 0 {
+				delete(trackers, key)
+				close(shared.stopCh)
+			}
+		})
+	}
+	return shared.tracker, release
+}
@@ -0,0 +1,213 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package usage
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/util/wait"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/klog"
+)
+
+const (
+	// reloadPeriod is how long a tracker waits to reload the usage after its
+	// watch ended or failed to start.
+	reloadPeriod = time.Second
+	// reloadPeriodJitter is the jitter applied to reloadPeriod.
+	reloadPeriodJitter = 0.5
+)
+
+// Usage is the storage taken by the objects of a resource in a namespace.
+type Usage struct {
+	// Objects is the number of objects.
+	Objects int64
+	// Bytes is the total size of the objects, as they are stored, that is
+	// after they are encoded and transformed (e.g. encrypted).
+	Bytes int64
+}
+
+// Reporter is implemented by the stores that account the usage of the
+// resource they hold.
+type Reporter interface {
+	// NamespaceUsage returns the usage of the resource per namespace. The
+	// usage of cluster-scoped resources is reported under the "" namespace.
+	// It returns false if the usage is not accounted, or not known yet.
+	NamespaceUsage() (map[string]Usage, bool)
+}
+
+// Sizer is implemented by the stores that account the usage of the resource
+// they hold, to tell the usage of an object before it is stored.
+type Sizer interface {
+	// StoredSize returns the number of bytes obj takes in storage once it is
+	// encoded and transformed, as counted in Usage.Bytes.
+	StoredSize(obj runtime.Object) (int64, error)
+}
+
+// LoadFunc returns the size of every value stored under the given key
+// prefix, by key, as of the returned revision.
+type LoadFunc func(keyPrefix string) (sizes map[string]int64, rev int64, err error)
+
+// RecordFunc records that a change to the value stored under key changed the
+// number of objects by objects and their size by bytes.
+type RecordFunc func(key string, objects, bytes int64)
+
+// WatchFunc calls record, in order, for every change made to the values stored
+// under keyPrefix after revision rev until stopCh is closed. It returns nil
+// once stopCh is closed, or the error that ended the watch, such as the
+// compaction of rev.
+type WatchFunc func(keyPrefix string, rev int64, record RecordFunc, stopCh <-chan struct{}) error
+
+// Tracker keeps the usage of the objects stored under a key prefix, per
+// namespace. It loads the usage once and then keeps it current from a watch
+// of the key prefix, which sees the writes of every server as well as the
+// deletion of objects with an expired lease. The usage is only reloaded when
+// the watch ends, e.g. because the revision it was at has been compacted.
+type Tracker struct {
+	keyPrefix string
+	resource  string
+	load      LoadFunc
+	watch     WatchFunc
+
+	lock sync.Mutex
+	// namespaces is nil until the tracker is first loaded.
+	namespaces map[string]Usage
+}
+
+// NewTracker returns a tracker of the usage of the objects stored under
+// keyPrefix, which is loaded by calling load and then kept current by calling
+// watch. The usage is reported in the metrics under the given resource name.
+func NewTracker(keyPrefix, resource string, load LoadFunc, watch WatchFunc) *Tracker {
+	return &Tracker{
+		keyPrefix: "/" + strings.Trim(keyPrefix, "/") + "/",
+		resource:  resource,
+		load:      load,
+		watch:     watch,
+	}
+}
+
+// Record records that a change to the value stored under key changed the
+// number of objects by objects and their size by bytes. Changes outside of
+// the key prefix of the tracker, or made before it is loaded, are ignored.
+func (t *Tracker) Record(key string, objects, bytes int64) {
+	namespace, ok := t.namespace(key)
+	if !ok || (objects == 0 && bytes == 0) {
+		return
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.namespaces == nil {
+		return
+	}
+	add(t.namespaces, namespace, objects, bytes)
+	if u, ok := t.namespaces[namespace]; ok {
+		updateNamespaceUsage(t.resource, namespace, u)
+	} else {
+		deleteNamespaceUsage(t.resource, namespace)
+	}
+}
+
+// Load loads the usage from the storage, replacing the one recorded so far,
+// and returns the revision it was loaded at. The changes made after that
+// revision must be recorded for the usage to stay current.
+func (t *Tracker) Load() (int64, error) {
+	sizes, rev, err := t.load(t.keyPrefix)
+	if err != nil {
+		return 0, err
+	}
+	namespaces := map[string]Usage{}
+	for key, size := range sizes {
+		if namespace, ok := t.namespace(key); ok {
+			add(namespaces, namespace, 1, size)
+		}
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	for namespace := range t.namespaces {
+		if _, ok := namespaces[namespace]; !ok {
+			deleteNamespaceUsage(t.resource, namespace)
+		}
+	}
+	for namespace, u := range namespaces {
+		updateNamespaceUsage(t.resource, namespace, u)
+	}
+	t.namespaces = namespaces
+	return rev, nil
+}
+
+// NamespaceUsage implements Reporter.
+func (t *Tracker) NamespaceUsage() (map[string]Usage, bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.namespaces == nil {
+		return nil, false
+	}
+	namespaces := make(map[string]Usage, len(t.namespaces))
+	for namespace, u := range t.namespaces {
+		namespaces[namespace] = u
+	}
+	return namespaces, true
+}
+
+// add adds to the usage of namespace, which is dropped once it has no
+// objects left.
+func add(namespaces map[string]Usage, namespace string, objects, bytes int64) {
+	u := namespaces[namespace]
+	u.Objects += objects
+	u.Bytes += bytes
+	if u.Objects <= 0 {
+		delete(namespaces, namespace)
+		return
+	}
+	namespaces[namespace] = u
+}
+
+// namespace returns the namespace of the object stored under key, which is ""
+// for cluster-scoped objects, or false if the key is not under the key prefix.
+func (t *Tracker) namespace(key string) (string, bool) {
+	if !strings.HasPrefix(key, t.keyPrefix) {
+		return "", false
+	}
+	rest := key[len(t.keyPrefix):]
+	if i := strings.Index(rest, "/"); i >= 0 {
+		return rest[:i], true
+	}
+	return "", true
+}
+
+// sync loads the usage and records the changes made after it was loaded
+// until stopCh is closed or the watch of the changes ends.
+func (t *Tracker) sync(stopCh <-chan struct{}) error {
+	rev, err := t.Load()
+	if err != nil {
+		return err
+	}
+	return t.watch(t.keyPrefix, rev, t.Record, stopCh)
+}
+
+// run syncs the tracker until stopCh is closed, reloading the usage whenever
+// the watch of the changes ends.
+func (t *Tracker) run(stopCh <-chan struct{}) {
+	wait.JitterUntil(func() {
+		if err := t.sync(stopCh); err != nil {
+			klog.V(5).Infof("Failed to track the usage of %s, reloading it: %v", t.resource, err)
+		}
+	}, reloadPeriod, reloadPeriodJitter, true, stopCh)
+}
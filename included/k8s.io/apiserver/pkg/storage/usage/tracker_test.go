@@ -0,0 +1,112 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package usage
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestTrackerRecord(t *testing.T) {
+	tracker := NewTracker("/registry/pods", "pods", func(string) (map[string]int64, int64, error) {
+		return map[string]int64{
+			"/registry/pods/ns1/a":        10,
+			"/registry/pods/ns1/b":        20,
+			"/registry/pods/ns2/a":        30,
+			"/registry/podsecurity/ns1/a": 40,
+		}, 5, nil
+	}, nil)
+	if _, ok := tracker.NamespaceUsage(); ok {
+		t.Fatalf("expected no usage before the tracker is loaded")
+	}
+	tracker.Record("/registry/pods/ns1/c", 1, 5)
+	if _, err := tracker.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	tracker.Record("/registry/pods/ns1/c", 1, 5)
+	tracker.Record("/registry/pods/ns1/a", 0, 2)
+	tracker.Record("/registry/pods/ns2/a", -1, -30)
+	tracker.Record("/registry/pods/ns3/a", 1, 1)
+	tracker.Record("/registry/other/ns3/a", 1, 1)
+	expected := map[string]Usage{
+		"ns1": {Objects: 3, Bytes: 37},
+		"ns3": {Objects: 1, Bytes: 1},
+	}
+	if got, ok := tracker.NamespaceUsage(); !ok || !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v (%v)", expected, got, ok)
+	}
+}
+
+func TestTrackerSync(t *testing.T) {
+	loads := []struct {
+		sizes map[string]int64
+		rev   int64
+		err   error
+	}{
+		{sizes: map[string]int64{"/nodes/a": 10}, rev: 5},
+		{err: fmt.Errorf("unavailable")},
+		{sizes: map[string]int64{"/nodes/a": 10, "/nodes/b": 20, "/nodes/c": 5}, rev: 9},
+	}
+	var watched []int64
+	tracker := NewTracker("/nodes", "nodes", func(string) (map[string]int64, int64, error) {
+		load := loads[0]
+		loads = loads[1:]
+		return load.sizes, load.rev, load.err
+	}, func(keyPrefix string, rev int64, record RecordFunc, stopCh <-chan struct{}) error {
+		watched = append(watched, rev)
+		if keyPrefix != "/nodes/" {
+			t.Errorf("expected the watch of /nodes/, got %s", keyPrefix)
+		}
+		// The changes made by other servers, and the expiration of objects,
+		// are recorded from the watch.
+		record("/nodes/b", 1, 20)
+		record("/nodes/a", -1, -10)
+		return fmt.Errorf("compacted")
+	})
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	if err := tracker.sync(stopCh); err == nil {
+		t.Fatalf("expected the watch to end with an error")
+	}
+	expected := map[string]Usage{"": {Objects: 1, Bytes: 20}}
+	if got, ok := tracker.NamespaceUsage(); !ok || !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v (%v)", expected, got, ok)
+	}
+
+	// A failed load keeps the usage.
+	if err := tracker.sync(stopCh); err == nil {
+		t.Fatalf("expected the load to fail")
+	}
+	if got, ok := tracker.NamespaceUsage(); !ok || !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v (%v)", expected, got, ok)
+	}
+
+	// The usage is reloaded and watched from the revision it was loaded at.
+	if err := tracker.sync(stopCh); err == nil {
+		t.Fatalf("expected the watch to end with an error")
+	}
+	expected = map[string]Usage{"": {Objects: 3, Bytes: 45}}
+	if got, ok := tracker.NamespaceUsage(); !ok || !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v (%v)", expected, got, ok)
+	}
+	if !reflect.DeepEqual(watched, []int64{5, 9}) {
+		t.Errorf("expected the watches from revisions 5 and 9, got %v", watched)
+	}
+}
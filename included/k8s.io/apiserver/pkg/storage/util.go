@@ -39,10 +39,9 @@ func EverythingFunc(runtime.Object) bool {
 	return true
 }
 
-func NoTriggerFunc() []MatchValue {
-	return nil
-}
-
+// NoTriggerPublisher indexes no objects.
+//
+// Deprecated: leave the indexers of the watch cache unset instead.
 func NoTriggerPublisher(runtime.Object) []MatchValue {
 	return nil
 }
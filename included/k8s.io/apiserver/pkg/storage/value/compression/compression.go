@@ -0,0 +1,209 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package compression transforms values for storage by compressing large
+// values before they are encrypted.
+package compression
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/value"
+)
+
+const (
+	// AlgorithmGzip compresses values with gzip.
+	AlgorithmGzip = "gzip"
+	// AlgorithmFlate compresses values with raw DEFLATE, which saves the gzip
+	// header and checksum.
+	AlgorithmFlate = "flate"
+
+	// DefaultMinSize is the size in bytes from which values are compressed by default.
+	DefaultMinSize = 1024
+	// DefaultMaxSize is the size in bytes up to which values are compressed by default,
+	// and the largest size values are allowed to decompress to.
+	DefaultMaxSize = 64 << 20
+
+	// prefix is prepended to compressed values, followed by the algorithm.
+	// Values without it are returned as they are, so that values written
+	// before compression was enabled stay readable.
+	prefix = "k8s:compress:v1:"
+)
+
+// uncompressedPrefix is prepended to values that compression would not make
+// smaller, so that they are not reported stale and rewritten on every read.
+var uncompressedPrefix = []byte(prefix + "none:")
+
+// algorithm compresses and decompresses values.
+type algorithm struct {
+	prefix    []byte
+	newWriter func(w io.Writer) (io.WriteCloser, error)
+	newReader func(r io.Reader) (io.ReadCloser, error)
+}
+
+var algorithms = map[string]*algorithm{
+	AlgorithmGzip: {
+		prefix: []byte(prefix + AlgorithmGzip + ":"),
+		newWriter: func(w io.Writer) (io.WriteCloser, error) {
+			return gzip.NewWriter(w), nil
+		},
+		newReader: func(r io.Reader) (io.ReadCloser, error) {
+			return gzip.NewReader(r)
+		},
+	},
+	AlgorithmFlate: {
+		prefix: []byte(prefix + AlgorithmFlate + ":"),
+		newWriter: func(w io.Writer) (io.WriteCloser, error) {
+			return flate.NewWriter(w, flate.DefaultCompression)
+		},
+		newReader: func(r io.Reader) (io.ReadCloser, error) {
+			return flate.NewReader(r), nil
+		},
+	},
+}
+
+type compressionTransformer struct {
+	delegate value.Transformer
+	// algorithm compresses values written, nil if values are not compressed.
+	algorithm *algorithm
+	name      string
+	minSize   int
+	maxSize   int
+	resource  string
+}
+
+// NewCompressionTransformer returns a transformer that compresses values of
+// minSize to maxSize bytes with algorithm before they are passed to delegate,
+// and decompresses values read from delegate. An empty algorithm disables
+// compression of written values, values compressed earlier are still read.
+// Values are only stored compressed if that makes them smaller, and reading a
+// value that decompresses to more than maxSize bytes fails. resource labels
+// the metrics of the transformer.
+func NewCompressionTransformer(delegate value.Transformer, algorithmName string, minSize, maxSize int, resource string) (value.Transformer, error) {
+	t := &compressionTransformer{
+		delegate: delegate,
+		name:     algorithmName,
+		minSize:  minSize,
+		maxSize:  maxSize,
+		resource: resource,
+	}
+	if len(algorithmName) > 0 {
+		a, ok := algorithms[algorithmName]
+		if !ok {
+			return nil, fmt.Errorf("unsupported compression algorithm %q, supported are %q and %q", algorithmName, AlgorithmGzip, AlgorithmFlate)
+		}
+		t.algorithm = a
+	}
+	if minSize < 0 {
+		return nil, fmt.Errorf("compression minimum size must not be negative, got %d", minSize)
+	}
+	if maxSize < minSize {
+		return nil, fmt.Errorf("compression maximum size %d must not be below the minimum size %d", maxSize, minSize)
+	}
+	registerMetrics()
+	return t, nil
+}
+
+// TransformFromStorage decompresses data read from the delegate if it was
+// compressed. The data is stale if it would be stored differently now, which
+// for data that compression did not make smaller means that compression was
+// disabled or the data is now below the minimum size.
+func (t *compressionTransformer) TransformFromStorage(data []byte, context value.Context) ([]byte, bool, error) {
+	data, stale, err := t.delegate.TransformFromStorage(data, context)
+	if err != nil {
+		return nil, false, err
+	}
+	for name, a := range algorithms {
+		if !bytes.HasPrefix(data, a.prefix) {
+			continue
+		}
+		out, err := decompress(a, data[len(a.prefix):], t.maxSize)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to decompress %s data: %v", name, err)
+		}
+		return out, stale || a != t.algorithm || len(out) < t.minSize, nil
+	}
+	if bytes.HasPrefix(data, uncompressedPrefix) {
+		out := data[len(uncompressedPrefix):]
+		return out, stale || !t.shouldCompress(out), nil
+	}
+	return data, stale || t.shouldCompress(data), nil
+}
+
+// TransformToStorage compresses data if it is large enough, and passes it
+// to the delegate. Data that does not get smaller is marked as such, so that
+// reading it back does not report it stale.
+func (t *compressionTransformer) TransformToStorage(data []byte, context value.Context) ([]byte, error) {
+	if t.shouldCompress(data) {
+		compressed, err := compress(t.algorithm, data)
+		if err != nil {
+			return nil, err
+		}
+		if len(compressed) < len(data) {
+			recordCompression(t.resource, len(data), len(compressed))
+			data = compressed
+		} else {
+			recordSkipped(t.resource)
+			data = append(append(make([]byte, 0, len(uncompressedPrefix)+len(data)), uncompressedPrefix...), data...)
+		}
+	}
+	return t.delegate.TransformToStorage(data, context)
+}
+
+func (t *compressionTransformer) shouldCompress(data []byte) bool {
+	return t.algorithm != nil && len(data) >= t.minSize && len(data) <= t.maxSize
+}
+
+// compress returns data compressed with a, preceded by the prefix of a.
+func compress(a *algorithm, data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.Grow(len(a.prefix) + len(data)/2)
+	buf.Write(a.prefix)
+	w, err := a.newWriter(&buf)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decompress returns data decompressed with a. It fails without reading
+// further once the decompressed data exceeds maxSize bytes.
+func decompress(a *algorithm, data []byte, maxSize int) ([]byte, error) {
+	r, err := a.newReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	out, err := ioutil.ReadAll(io.LimitReader(r, int64(maxSize)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(out) > maxSize {
+		return nil, fmt.Errorf("decompressed data exceeds the maximum size of %d bytes", maxSize)
+	}
+	return out, nil
+}
@@ -0,0 +1,242 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package compression
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"strings"
+	"testing"
+
+	corev1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/core/v1"
+	metav1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime/serializer"
+	utilruntime "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/util/runtime"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/embedded"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/value"
+)
+
+func TestCompressionRoundTrip(t *testing.T) {
+	context := value.DefaultContext([]byte("key"))
+	large := []byte(strings.Repeat("compressible ", 200))
+	small := []byte("small")
+
+	for _, algorithm := range []string{AlgorithmGzip, AlgorithmFlate} {
+		t.Run(algorithm, func(t *testing.T) {
+			transformer, err := NewCompressionTransformer(value.IdentityTransformer, algorithm, DefaultMinSize, DefaultMaxSize, "configmaps")
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, data := range [][]byte{large, small} {
+				stored, err := transformer.TransformToStorage(data, context)
+				if err != nil {
+					t.Fatal(err)
+				}
+				compressed := bytes.HasPrefix(stored, []byte(prefix+algorithm+":"))
+				if compressed != (len(data) >= DefaultMinSize) {
+					t.Errorf("expected value of %d bytes to be compressed=%t, got %q", len(data), !compressed, stored)
+				}
+				if compressed && len(stored) >= len(data) {
+					t.Errorf("expected the compressed value to be smaller, got %d bytes from %d", len(stored), len(data))
+				}
+				out, stale, err := transformer.TransformFromStorage(stored, context)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if stale || !bytes.Equal(out, data) {
+					t.Errorf("expected %d bytes, got stale=%t %d bytes", len(data), stale, len(out))
+				}
+			}
+		})
+	}
+}
+
+func TestCompressionBackwardCompatible(t *testing.T) {
+	context := value.DefaultContext([]byte("key"))
+	large := []byte(strings.Repeat("compressible ", 200))
+
+	gzipTransformer, err := NewCompressionTransformer(value.IdentityTransformer, AlgorithmGzip, DefaultMinSize, DefaultMaxSize, "configmaps")
+	if err != nil {
+		t.Fatal(err)
+	}
+	disabled, err := NewCompressionTransformer(value.IdentityTransformer, "", DefaultMinSize, DefaultMaxSize, "configmaps")
+	if err != nil {
+		t.Fatal(err)
+	}
+	flateTransformer, err := NewCompressionTransformer(value.IdentityTransformer, AlgorithmFlate, DefaultMinSize, DefaultMaxSize, "configmaps")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Uncompressed values written before compression was enabled are read,
+	// but stale, so that they are compressed when they are rewritten.
+	out, stale, err := gzipTransformer.TransformFromStorage(large, context)
+	if err != nil || !stale || !bytes.Equal(out, large) {
+		t.Errorf("expected an uncompressed value to be read as stale, got stale=%t err=%v", stale, err)
+	}
+
+	// Compressed values are read after compression was disabled or the
+	// algorithm was changed, but stale.
+	stored, err := gzipTransformer.TransformToStorage(large, context)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, transformer := range map[string]value.Transformer{"disabled": disabled, "flate": flateTransformer} {
+		out, stale, err := transformer.TransformFromStorage(stored, context)
+		if err != nil || !stale || !bytes.Equal(out, large) {
+			t.Errorf("%s: expected a gzip value to be read as stale, got stale=%t err=%v", name, stale, err)
+		}
+	}
+	if stored, err := disabled.TransformToStorage(large, context); err != nil || !bytes.Equal(stored, large) {
+		t.Errorf("expected disabled compression to write values unchanged, got %q, %v", stored, err)
+	}
+}
+
+func TestCompressionIncompressible(t *testing.T) {
+	context := value.DefaultContext([]byte("key"))
+	random := make([]byte, 2*DefaultMinSize)
+	if _, err := rand.Read(random); err != nil {
+		t.Fatal(err)
+	}
+	transformer, err := NewCompressionTransformer(value.IdentityTransformer, AlgorithmGzip, DefaultMinSize, DefaultMaxSize, "secrets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored, err := transformer.TransformToStorage(random, context)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(stored, append(append([]byte{}, uncompressedPrefix...), random...)) {
+		t.Errorf("expected a value that does not get smaller to be stored uncompressed and marked")
+	}
+	out, stale, err := transformer.TransformFromStorage(stored, context)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stale || !bytes.Equal(out, random) {
+		t.Errorf("expected the marked value to be read back unchanged and not stale, got stale=%t", stale)
+	}
+
+	// values that were not marked yet, or are marked but would not be compressed
+	// any more, are rewritten
+	if _, stale, err := transformer.TransformFromStorage(random, context); err != nil || !stale {
+		t.Errorf("expected an unmarked value to be stale, got stale=%t, %v", stale, err)
+	}
+	disabled, err := NewCompressionTransformer(value.IdentityTransformer, "", DefaultMinSize, DefaultMaxSize, "secrets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out, stale, err := disabled.TransformFromStorage(stored, context); err != nil || !stale || !bytes.Equal(out, random) {
+		t.Errorf("expected a marked value to be stale once compression is disabled, got stale=%t, %v", stale, err)
+	}
+}
+
+func TestCompressionIncompressibleNoOpUpdate(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(corev1.AddToScheme(scheme))
+	codecs := serializer.NewCodecFactory(scheme)
+	info, ok := runtime.SerializerInfoForMediaType(codecs.SupportedMediaTypes(), runtime.ContentTypeProtobuf)
+	if !ok {
+		t.Fatal("no protobuf serializer")
+	}
+	codec := codecs.CodecForVersions(info.Serializer, info.Serializer, corev1.SchemeGroupVersion, corev1.SchemeGroupVersion)
+	transformer, err := NewCompressionTransformer(value.IdentityTransformer, AlgorithmGzip, DefaultMinSize, DefaultMaxSize, "secrets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := embedded.NewMemory()
+	defer b.Close()
+	s := embedded.New(b, codec, "", transformer, true)
+
+	random := make([]byte, 4*DefaultMinSize)
+	if _, err := rand.Read(random); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.TODO()
+	created := &corev1.Secret{}
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "foo"}, Data: map[string][]byte{"random": random}}
+	if err := s.Create(ctx, "/secrets/foo", secret, created, 0); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		out := &corev1.Secret{}
+		noop := func(input runtime.Object, res storage.ResponseMeta) (runtime.Object, *uint64, error) {
+			return input, nil, nil
+		}
+		if err := s.GuaranteedUpdate(ctx, "/secrets/foo", out, false, nil, noop); err != nil {
+			t.Fatal(err)
+		}
+		if out.ResourceVersion != created.ResourceVersion {
+			t.Fatalf("expected a no-op update of an incompressible value not to write, resource version changed from %s to %s", created.ResourceVersion, out.ResourceVersion)
+		}
+	}
+}
+
+func TestCompressionInvalid(t *testing.T) {
+	if _, err := NewCompressionTransformer(value.IdentityTransformer, "zstd", DefaultMinSize, DefaultMaxSize, "secrets"); err == nil {
+		t.Error("expected an error for an unsupported algorithm")
+	}
+	transformer, err := NewCompressionTransformer(value.IdentityTransformer, AlgorithmGzip, DefaultMinSize, DefaultMaxSize, "secrets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	corrupt := append([]byte(prefix+AlgorithmGzip+":"), "not gzip"...)
+	if _, _, err := transformer.TransformFromStorage(corrupt, value.DefaultContext(nil)); err == nil {
+		t.Error("expected an error for corrupt compressed data")
+	}
+}
+
+func TestCompressionMaxSize(t *testing.T) {
+	context := value.DefaultContext([]byte("key"))
+	if _, err := NewCompressionTransformer(value.IdentityTransformer, AlgorithmGzip, DefaultMinSize, DefaultMinSize-1, "secrets"); err == nil {
+		t.Error("expected an error for a maximum size below the minimum size")
+	}
+	unlimited, err := NewCompressionTransformer(value.IdentityTransformer, AlgorithmGzip, DefaultMinSize, DefaultMaxSize, "secrets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	limited, err := NewCompressionTransformer(value.IdentityTransformer, AlgorithmGzip, DefaultMinSize, 4*DefaultMinSize, "secrets")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// values decompressing to more than the maximum size are not read
+	large := bytes.Repeat([]byte{0}, 100*DefaultMinSize)
+	stored, err := unlimited.TransformToStorage(large, context)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := limited.TransformFromStorage(stored, context); err == nil || !strings.Contains(err.Error(), "exceeds the maximum size") {
+		t.Errorf("expected an error for data exceeding the maximum size, got %v", err)
+	}
+
+	// and are stored uncompressed, so that they are read back
+	stored, err = limited.TransformToStorage(large, context)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(stored, large) {
+		t.Errorf("expected a value larger than the maximum size to be stored as it is")
+	}
+	out, stale, err := limited.TransformFromStorage(stored, context)
+	if err != nil || stale || !bytes.Equal(out, large) {
+		t.Errorf("expected the value to be read back, got stale=%t err=%v", stale, err)
+	}
+}
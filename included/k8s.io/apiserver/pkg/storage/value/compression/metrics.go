@@ -0,0 +1,74 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package compression
+
+import (
+	"sync"
+
+	"github.com/aaron-prindle/krmapiserver/included/github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	compressionRatio = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "apiserver",
+			Subsystem: "storage",
+			Name:      "compression_ratio",
+			Help:      "Ratio of the uncompressed to the compressed size of values written compressed.",
+			Buckets:   []float64{1.25, 1.5, 2, 3, 4, 6, 8, 12, 16, 32},
+		},
+		[]string{"resource"},
+	)
+	compressionBytesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "apiserver",
+			Subsystem: "storage",
+			Name:      "compression_bytes_total",
+			Help:      "Total size of values written compressed, before (uncompressed) and after (compressed) compression.",
+		},
+		[]string{"resource", "size"},
+	)
+	compressionSkippedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "apiserver",
+			Subsystem: "storage",
+			Name:      "compression_skipped_total",
+			Help:      "Number of values large enough to be compressed that were written uncompressed because compression did not make them smaller.",
+		},
+		[]string{"resource"},
+	)
+)
+
+var registerOnce sync.Once
+
+func registerMetrics() {
+	registerOnce.Do(func() {
+		prometheus.MustRegister(compressionRatio)
+		prometheus.MustRegister(compressionBytesTotal)
+		prometheus.MustRegister(compressionSkippedTotal)
+	})
+}
+
+func recordCompression(resource string, uncompressed, compressed int) {
+	compressionRatio.WithLabelValues(resource).Observe(float64(uncompressed) / float64(compressed))
+	compressionBytesTotal.WithLabelValues(resource, "uncompressed").Add(float64(uncompressed))
+	compressionBytesTotal.WithLabelValues(resource, "compressed").Add(float64(compressed))
+}
+
+func recordSkipped(resource string) {
+	compressionSkippedTotal.WithLabelValues(resource).Inc()
+}
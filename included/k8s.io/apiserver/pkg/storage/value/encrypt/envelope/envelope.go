@@ -139,11 +139,10 @@ var _ value.Transformer = &envelopeTransformer{}
 
 // addTransformer inserts a new transformer to the Envelope cache of DEKs for future reads.
 func (t *envelopeTransformer) addTransformer(encKey []byte, key []byte) (value.Transformer, error) {
-	block, err := aes.NewCipher(key)
+	transformer, err := newDEKTransformer(key, t.baseTransformerFunc)
 	if err != nil {
 		return nil, err
 	}
-	transformer := t.baseTransformerFunc(block)
 	// Use base64 of encKey as the key into the cache because hashicorp/golang-lru
 	// cannot hash []uint8.
 	t.transformers.Add(base64.StdEncoding.EncodeToString(encKey), transformer)
@@ -159,6 +158,15 @@ func (t *envelopeTransformer) getTransformer(encKey []byte) value.Transformer {
 	return nil
 }
 
+// newDEKTransformer returns the transformer encrypting data with the DEK key.
+func newDEKTransformer(key []byte, baseTransformerFunc func(cipher.Block) value.Transformer) (value.Transformer, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return baseTransformerFunc(block), nil
+}
+
 // generateKey generates a random key using system randomness.
 func generateKey(length int) (key []byte, err error) {
 	defer func(start time.Time) {
@@ -0,0 +1,342 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envelope
+
+import (
+	"crypto/cipher"
+	"encoding/base64"
+	"fmt"
+	"sync"
+
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/klog"
+
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/value"
+
+	lru "github.com/aaron-prindle/krmapiserver/included/github.com/hashicorp/golang-lru"
+	"golang.org/x/crypto/cryptobyte"
+)
+
+const (
+	// maxDEKUses is the number of values encrypted with the same DEK before a
+	// new one is generated. It keeps the probability of a nonce collision of
+	// the randomly generated AES-GCM nonces negligible.
+	maxDEKUses = 1 << 20
+
+	// maxKeyIDSize is the maximum length of a key ID returned by a KMS provider.
+	// The length is stored in a single byte in front of the encrypted data.
+	maxKeyIDSize = 255
+
+	// statusHealthy is the healthz of a KMS provider that can encrypt and decrypt.
+	statusHealthy = "ok"
+)
+
+// StatusResponse is the health of a KMS provider and the ID of the key it
+// currently encrypts with.
+type StatusResponse struct {
+	Version string
+	Healthz string
+	KeyID   string
+}
+
+// ServiceV2 allows encrypting and decrypting data using an external Key
+// Management Service that identifies the key each value is encrypted with.
+type ServiceV2 interface {
+	// Decrypt a given bytearray, encrypted with the key keyID, to obtain the original data as bytes.
+	Decrypt(uid string, keyID string, data []byte) ([]byte, error)
+	// Encrypt bytes to a ciphertext, returning the ID of the key used.
+	Encrypt(uid string, data []byte) ([]byte, string, error)
+	// Status returns the health of the KMS provider and the ID of its current key.
+	Status() (*StatusResponse, error)
+}
+
+// StatusChecker is implemented by transformers that can report the health of
+// their KMS provider. The status is polled in the background, so that checks
+// of the health do not call the KMS provider.
+type StatusChecker interface {
+	// PollStatus calls the Status RPC of the KMS provider and returns an
+	// error unless it is healthy. The key ID reported by the provider becomes
+	// the current one.
+	PollStatus() error
+	// CheckStatus returns the result of the last PollStatus, or an error if
+	// the status was not polled yet.
+	CheckStatus() error
+}
+
+// writeDEK is the DEK values are currently encrypted with.
+type writeDEK struct {
+	encKey      []byte
+	keyID       string
+	transformer value.Transformer
+	uses        int
+}
+
+type envelopeTransformerV2 struct {
+	envelopeService ServiceV2
+
+	// transformers is a thread-safe LRU cache which caches decrypted DEKs indexed by their encrypted form.
+	transformers *lru.Cache
+
+	// baseTransformerFunc creates a new transformer for encrypting the data with the DEK.
+	baseTransformerFunc func(cipher.Block) value.Transformer
+
+	lock sync.Mutex
+	// currentKeyID is the ID of the key the KMS provider last reported to
+	// encrypt with. It is empty until the provider was called for the first time.
+	currentKeyID string
+	// dek is reused for writes until the key ID of the provider changes or it
+	// was used maxDEKUses times.
+	dek *writeDEK
+	// statusErr is the result of the last poll of the status of the provider.
+	statusErr error
+}
+
+// NewEnvelopeTransformerV2 returns a transformer which implements a KEK-DEK based envelope encryption scheme
+// with the v2 KMS protocol. In addition to the encrypted DEK, the ID of the key encrypting the DEK is
+// prepended to the data items, and both are authenticated as additional data of the AEAD the data is
+// encrypted with. Values read with a key ID other than the current one of the KMS provider
+// are reported as stale. A DEK is reused for many writes rather than generated for each one, and a
+// cache (of size cacheSize) is maintained to store the most recently used decrypted DEKs in memory.
+// The returned transformer implements StatusChecker.
+func NewEnvelopeTransformerV2(envelopeService ServiceV2, cacheSize int, baseTransformerFunc func(cipher.Block) value.Transformer) (value.Transformer, error) {
+	if cacheSize == 0 {
+		cacheSize = defaultCacheSize
+	}
+	cache, err := lru.New(cacheSize)
+	if err != nil {
+		return nil, err
+	}
+	return &envelopeTransformerV2{
+		envelopeService:     envelopeService,
+		transformers:        cache,
+		baseTransformerFunc: baseTransformerFunc,
+		statusErr:           fmt.Errorf("the status of the KMS provider was not polled yet"),
+	}, nil
+}
+
+// TransformFromStorage decrypts data encrypted by this transformer using envelope encryption.
+func (t *envelopeTransformerV2) TransformFromStorage(data []byte, context value.Context) ([]byte, bool, error) {
+	var encKey, keyID cryptobyte.String
+	s := cryptobyte.String(data)
+	if ok := s.ReadUint16LengthPrefixed(&encKey); !ok {
+		return nil, false, fmt.Errorf("invalid data encountered by envelope transformer: failed to read uint16 length prefixed data")
+	}
+	if ok := s.ReadUint8LengthPrefixed(&keyID); !ok {
+		return nil, false, fmt.Errorf("invalid data encountered by envelope transformer: failed to read key ID")
+	}
+	header := data[:len(data)-len(s)]
+	encData := []byte(s)
+
+	// Look up the decrypted DEK from cache or Envelope.
+	transformer := t.getTransformer(encKey)
+	if transformer == nil {
+		value.RecordCacheMiss()
+		key, err := t.envelopeService.Decrypt(newUID(), string(keyID), encKey)
+		if err != nil {
+			return nil, false, fmt.Errorf("error while decrypting key: %q", err)
+		}
+		transformer, err = t.addTransformer(encKey, key)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+	out, stale, err := transformer.TransformFromStorage(encData, headerContext{header: header, context: context})
+	if err != nil {
+		return nil, false, err
+	}
+
+	t.lock.Lock()
+	currentKeyID := t.currentKeyID
+	t.lock.Unlock()
+	if len(currentKeyID) > 0 && currentKeyID != string(keyID) {
+		value.RecordStaleKeyID()
+		stale = true
+	}
+	return out, stale, nil
+}
+
+// TransformToStorage encrypts data to be written to disk using envelope encryption.
+func (t *envelopeTransformerV2) TransformToStorage(data []byte, context value.Context) ([]byte, error) {
+	dek, err := t.useDEK()
+	if err != nil {
+		return nil, err
+	}
+
+	// Append the length of the encrypted DEK as the first 2 bytes, followed
+	// by the length of the key ID in 1 byte.
+	b := cryptobyte.NewBuilder(nil)
+	b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+		b.AddBytes(dek.encKey)
+	})
+	b.AddUint8LengthPrefixed(func(b *cryptobyte.Builder) {
+		b.AddBytes([]byte(dek.keyID))
+	})
+	header, err := b.Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := dek.transformer.TransformToStorage(data, headerContext{header: header, context: context})
+	if err != nil {
+		return nil, err
+	}
+	return append(header, result...), nil
+}
+
+// headerContext authenticates the header of an envelope, the encrypted DEK and
+// the key ID, along with the authenticated data of the value, so that neither
+// can be replaced without the value failing to decrypt. The header is length
+// prefixed, so the concatenation is unambiguous.
+type headerContext struct {
+	header  []byte
+	context value.Context
+}
+
+// AuthenticatedData returns the header followed by the authenticated data of the value.
+func (c headerContext) AuthenticatedData() []byte {
+	data := c.context.AuthenticatedData()
+	out := make([]byte, 0, len(c.header)+len(data))
+	return append(append(out, c.header...), data...)
+}
+
+// PollStatus implements StatusChecker.
+func (t *envelopeTransformerV2) PollStatus() error {
+	err := t.pollStatus()
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if err != nil && t.statusErr == nil {
+		klog.Warningf("KMS provider became unhealthy: %v", err)
+	} else if err == nil && t.statusErr != nil {
+		klog.Infof("KMS provider is healthy")
+	}
+	t.statusErr = err
+	return err
+}
+
+func (t *envelopeTransformerV2) pollStatus() error {
+	response, err := t.envelopeService.Status()
+	if err != nil {
+		return fmt.Errorf("failed to get status of KMS provider: %v", err)
+	}
+	if response.Healthz != statusHealthy {
+		return fmt.Errorf("KMS provider is not healthy: %q", response.Healthz)
+	}
+	if err := validateKeyID(response.KeyID); err != nil {
+		return err
+	}
+	t.setCurrentKeyID(response.KeyID)
+	return nil
+}
+
+// CheckStatus implements StatusChecker.
+func (t *envelopeTransformerV2) CheckStatus() error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.statusErr
+}
+
+var _ value.Transformer = &envelopeTransformerV2{}
+var _ StatusChecker = &envelopeTransformerV2{}
+
+// useDEK returns the DEK to encrypt a value with. A new DEK is generated if
+// there is none yet, if the key of the KMS provider changed since the
+// current one was encrypted, or if it was used too often. The KMS provider is
+// called without holding the lock, so that reads and writes with the current
+// DEK are not blocked by it.
+func (t *envelopeTransformerV2) useDEK() (*writeDEK, error) {
+	if dek := t.reuseDEK(); dek != nil {
+		return dek, nil
+	}
+
+	newKey, err := generateKey(32)
+	if err != nil {
+		return nil, err
+	}
+	encKey, keyID, err := t.envelopeService.Encrypt(newUID(), newKey)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateKeyID(keyID); err != nil {
+		return nil, err
+	}
+	transformer, err := t.addTransformer(encKey, newKey)
+	if err != nil {
+		return nil, err
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if len(t.currentKeyID) > 0 && t.currentKeyID != keyID {
+		klog.Infof("KMS provider encrypts with key ID %q, previously %q", keyID, t.currentKeyID)
+	}
+	t.currentKeyID = keyID
+	// Concurrent writes that found no DEK to reuse each generated one, the last
+	// one replaces the others.
+	t.dek = &writeDEK{encKey: encKey, keyID: keyID, transformer: transformer, uses: 1}
+	return t.dek, nil
+}
+
+// reuseDEK returns the current DEK, or nil if a new one has to be generated.
+func (t *envelopeTransformerV2) reuseDEK() *writeDEK {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.dek != nil && t.dek.keyID == t.currentKeyID && t.dek.uses < maxDEKUses {
+		t.dek.uses++
+		return t.dek
+	}
+	return nil
+}
+
+func (t *envelopeTransformerV2) setCurrentKeyID(keyID string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if len(t.currentKeyID) > 0 && t.currentKeyID != keyID {
+		klog.Infof("KMS provider reports key ID %q, previously %q", keyID, t.currentKeyID)
+	}
+	t.currentKeyID = keyID
+}
+
+// addTransformer inserts a new transformer to the Envelope cache of DEKs for future reads.
+func (t *envelopeTransformerV2) addTransformer(encKey []byte, key []byte) (value.Transformer, error) {
+	transformer, err := newDEKTransformer(key, t.baseTransformerFunc)
+	if err != nil {
+		return nil, err
+	}
+	// Use base64 of encKey as the key into the cache because hashicorp/golang-lru
+	// cannot hash []uint8.
+	t.transformers.Add(base64.StdEncoding.EncodeToString(encKey), transformer)
+	return transformer, nil
+}
+
+// getTransformer fetches the transformer corresponding to encKey from cache, if it exists.
+func (t *envelopeTransformerV2) getTransformer(encKey []byte) value.Transformer {
+	_transformer, found := t.transformers.Get(base64.StdEncoding.EncodeToString(encKey))
+	if found {
+		return _transformer.(value.Transformer)
+	}
+	return nil
+}
+
+// validateKeyID returns an error if keyID can't be stored with a value.
+func validateKeyID(keyID string) error {
+	if len(keyID) == 0 {
+		return fmt.Errorf("KMS provider returned an empty key ID")
+	}
+	if len(keyID) > maxKeyIDSize {
+		return fmt.Errorf("KMS provider returned a key ID of %d bytes, at most %d are supported", len(keyID), maxKeyIDSize)
+	}
+	return nil
+}
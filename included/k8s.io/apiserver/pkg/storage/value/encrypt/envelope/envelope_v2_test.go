@@ -0,0 +1,309 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envelope
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/value"
+	aestransformer "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/value/encrypt/aes"
+)
+
+// testEnvelopeServiceV2 is a mock v2 Envelope service which counts the calls
+// to the remote KMS provider.
+type testEnvelopeServiceV2 struct {
+	lock     sync.Mutex
+	keyID    string
+	healthz  string
+	encrypts int
+	decrypts int
+}
+
+func newTestEnvelopeServiceV2() *testEnvelopeServiceV2 {
+	return &testEnvelopeServiceV2{keyID: "1", healthz: "ok"}
+}
+
+func (t *testEnvelopeServiceV2) Decrypt(uid string, keyID string, data []byte) ([]byte, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.decrypts++
+	if !strings.HasPrefix(string(data), keyID+":") {
+		return nil, fmt.Errorf("data %q was not encrypted with key %q", data, keyID)
+	}
+	return base64.StdEncoding.DecodeString(strings.TrimPrefix(string(data), keyID+":"))
+}
+
+func (t *testEnvelopeServiceV2) Encrypt(uid string, data []byte) ([]byte, string, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.encrypts++
+	return []byte(t.keyID + ":" + base64.StdEncoding.EncodeToString(data)), t.keyID, nil
+}
+
+func (t *testEnvelopeServiceV2) Status() (*StatusResponse, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return &StatusResponse{Version: "v2alpha1", Healthz: t.healthz, KeyID: t.keyID}, nil
+}
+
+func (t *testEnvelopeServiceV2) set(keyID, healthz string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.keyID = keyID
+	t.healthz = healthz
+}
+
+func TestEnvelopeV2DEKReuse(t *testing.T) {
+	service := newTestEnvelopeServiceV2()
+	transformer, err := NewEnvelopeTransformerV2(service, testEnvelopeCacheSize, aestransformer.NewGCMTransformer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	context := value.DefaultContext([]byte(testContextText))
+
+	for i := 0; i < 10; i++ {
+		stored, err := transformer.TransformToStorage([]byte(testText), context)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out, stale, err := transformer.TransformFromStorage(stored, context)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stale || !bytes.Equal(out, []byte(testText)) {
+			t.Fatalf("expected %q, got stale=%t %q", testText, stale, out)
+		}
+	}
+	if service.encrypts != 1 || service.decrypts != 0 {
+		t.Errorf("expected the DEK to be generated once and cached, got %d encrypts and %d decrypts", service.encrypts, service.decrypts)
+	}
+}
+
+func TestEnvelopeV2StaleKeyID(t *testing.T) {
+	service := newTestEnvelopeServiceV2()
+	transformer, err := NewEnvelopeTransformerV2(service, testEnvelopeCacheSize, aestransformer.NewGCMTransformer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	context := value.DefaultContext([]byte(testContextText))
+
+	stored, err := transformer.TransformToStorage([]byte(testText), context)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The rotation of the key becomes visible with the next status poll.
+	service.set("2", "ok")
+	if _, stale, err := transformer.TransformFromStorage(stored, context); err != nil || stale {
+		t.Fatalf("expected a current value before the status poll, got stale=%t err=%v", stale, err)
+	}
+	if err := transformer.(StatusChecker).PollStatus(); err != nil {
+		t.Fatal(err)
+	}
+	out, stale, err := transformer.TransformFromStorage(stored, context)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stale || !bytes.Equal(out, []byte(testText)) {
+		t.Fatalf("expected stale %q, got stale=%t %q", testText, stale, out)
+	}
+
+	// Writes use a new DEK encrypted with the new key.
+	restored, err := transformer.TransformToStorage([]byte(testText), context)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if service.encrypts != 2 {
+		t.Errorf("expected a new DEK after the rotation, got %d encrypts", service.encrypts)
+	}
+	if _, stale, err := transformer.TransformFromStorage(restored, context); err != nil || stale {
+		t.Errorf("expected a current value after the rotation, got stale=%t err=%v", stale, err)
+	}
+}
+
+// blockingEnvelopeServiceV2 blocks Encrypt calls until unblock is closed.
+type blockingEnvelopeServiceV2 struct {
+	*testEnvelopeServiceV2
+	encrypting chan struct{}
+	unblock    chan struct{}
+}
+
+func (t *blockingEnvelopeServiceV2) Encrypt(uid string, data []byte) ([]byte, string, error) {
+	t.encrypting <- struct{}{}
+	<-t.unblock
+	return t.testEnvelopeServiceV2.Encrypt(uid, data)
+}
+
+func TestEnvelopeV2EncryptDoesNotBlock(t *testing.T) {
+	service := &blockingEnvelopeServiceV2{
+		testEnvelopeServiceV2: newTestEnvelopeServiceV2(),
+		encrypting:            make(chan struct{}, 1),
+		unblock:               make(chan struct{}),
+	}
+	transformer, err := NewEnvelopeTransformerV2(service, testEnvelopeCacheSize, aestransformer.NewGCMTransformer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	context := value.DefaultContext([]byte(testContextText))
+	close(service.unblock)
+	stored, err := transformer.TransformToStorage([]byte(testText), context)
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-service.encrypting
+
+	// The rotation of the key makes the next write generate a new DEK, which
+	// waits for the KMS provider.
+	service.unblock = make(chan struct{})
+	service.set("2", "ok")
+	if err := transformer.(StatusChecker).PollStatus(); err != nil {
+		t.Fatal(err)
+	}
+	written := make(chan error)
+	go func() {
+		_, err := transformer.TransformToStorage([]byte(testText), context)
+		written <- err
+	}()
+	<-service.encrypting
+
+	done := make(chan error)
+	go func() {
+		if _, _, err := transformer.TransformFromStorage(stored, context); err != nil {
+			done <- err
+			return
+		}
+		done <- transformer.(StatusChecker).PollStatus()
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("reads and status polls are blocked while a DEK is encrypted")
+	}
+
+	close(service.unblock)
+	if err := <-written; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEnvelopeV2CacheMiss(t *testing.T) {
+	service := newTestEnvelopeServiceV2()
+	context := value.DefaultContext([]byte(testContextText))
+	writer, err := NewEnvelopeTransformerV2(service, testEnvelopeCacheSize, aestransformer.NewGCMTransformer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored, err := writer.TransformToStorage([]byte(testText), context)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A transformer which has not seen the DEK yet decrypts it with the key
+	// ID stored with the value, once.
+	reader, err := NewEnvelopeTransformerV2(service, testEnvelopeCacheSize, aestransformer.NewGCMTransformer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		out, _, err := reader.TransformFromStorage(stored, context)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(out, []byte(testText)) {
+			t.Fatalf("expected %q, got %q", testText, out)
+		}
+	}
+	if service.decrypts != 1 {
+		t.Errorf("expected 1 decrypt, got %d", service.decrypts)
+	}
+
+	if _, _, err := reader.TransformFromStorage(stored[:1], context); err == nil {
+		t.Error("expected an error for truncated data")
+	}
+}
+
+func TestEnvelopeV2AuthenticatesHeader(t *testing.T) {
+	service := newTestEnvelopeServiceV2()
+	context := value.DefaultContext([]byte(testContextText))
+	transformer, err := NewEnvelopeTransformerV2(service, testEnvelopeCacheSize, aestransformer.NewGCMTransformer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored, err := transformer.TransformToStorage([]byte(testText), context)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := transformer.TransformFromStorage(stored, context); err != nil {
+		t.Fatal(err)
+	}
+
+	// The DEK is cached, so only the authenticated data detects that the key
+	// ID stored with the value was replaced.
+	encKeySize := int(stored[0])<<8 | int(stored[1])
+	keyIDOffset := 2 + encKeySize + 1
+	if keyID := string(stored[keyIDOffset : keyIDOffset+1]); keyID != "1" {
+		t.Fatalf("expected key ID 1 at offset %d, got %q", keyIDOffset, keyID)
+	}
+	tampered := append([]byte{}, stored...)
+	tampered[keyIDOffset] = '2'
+	if _, _, err := transformer.TransformFromStorage(tampered, context); err == nil {
+		t.Error("expected an error for a value with a replaced key ID")
+	}
+}
+
+func TestEnvelopeV2CheckStatus(t *testing.T) {
+	service := newTestEnvelopeServiceV2()
+	transformer, err := NewEnvelopeTransformerV2(service, testEnvelopeCacheSize, aestransformer.NewGCMTransformer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checker := transformer.(StatusChecker)
+	if err := checker.CheckStatus(); err == nil {
+		t.Error("expected an error before the status was polled")
+	}
+	if err := checker.PollStatus(); err != nil {
+		t.Errorf("unexpected error for a healthy provider: %v", err)
+	}
+	if err := checker.CheckStatus(); err != nil {
+		t.Errorf("unexpected error for a healthy provider: %v", err)
+	}
+
+	// Checks report the last poll without calling the provider.
+	service.set("1", "unavailable")
+	if err := checker.CheckStatus(); err != nil {
+		t.Errorf("unexpected error before the status was polled again: %v", err)
+	}
+	if err := checker.PollStatus(); err == nil {
+		t.Error("expected an error for an unhealthy provider")
+	}
+	if err := checker.CheckStatus(); err == nil {
+		t.Error("expected an error for an unhealthy provider")
+	}
+	service.set("", "ok")
+	if err := checker.PollStatus(); err == nil {
+		t.Error("expected an error for an empty key ID")
+	}
+}
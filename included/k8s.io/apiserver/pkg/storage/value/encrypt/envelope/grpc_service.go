@@ -56,6 +56,27 @@ type gRPCService struct {
 func NewGRPCService(endpoint string, callTimeout time.Duration) (Service, error) {
 	klog.V(4).Infof("Configure KMS provider with endpoint: %s", endpoint)
 
+	connection, err := dialUnix(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	kmsClient := kmsapi.NewKeyManagementServiceClient(connection)
+	return &gRPCService{
+		kmsClient:   kmsClient,
+		connection:  connection,
+		callTimeout: callTimeout,
+	}, nil
+}
+
+// Close closes the connection to the remote KMS provider.
+func (g *gRPCService) Close() error {
+	return g.connection.Close()
+}
+
+// dialUnix returns a non-blocking gRPC connection to the unix socket of the
+// remote KMS provider at endpoint.
+func dialUnix(endpoint string) (*grpc.ClientConn, error) {
 	addr, err := parseEndpoint(endpoint)
 	if err != nil {
 		return nil, err
@@ -76,13 +97,7 @@ func NewGRPCService(endpoint string, callTimeout time.Duration) (Service, error)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create connection to %s, error: %v", endpoint, err)
 	}
-
-	kmsClient := kmsapi.NewKeyManagementServiceClient(connection)
-	return &gRPCService{
-		kmsClient:   kmsClient,
-		connection:  connection,
-		callTimeout: callTimeout,
-	}, nil
+	return connection, nil
 }
 
 // Parse the endpoint to extract schema, host or path.
@@ -0,0 +1,99 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envelope
+
+import (
+	"context"
+	"time"
+
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/util/uuid"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/klog"
+
+	"github.com/aaron-prindle/krmapiserver/included/google.golang.org/grpc"
+
+	kmsapi "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/value/encrypt/envelope/v2alpha1"
+)
+
+// The gRPC implementation for envelope.ServiceV2.
+type gRPCServiceV2 struct {
+	kmsClient   kmsapi.KeyManagementServiceClient
+	connection  *grpc.ClientConn
+	callTimeout time.Duration
+}
+
+// NewGRPCServiceV2 returns an envelope.ServiceV2 which uses gRPC to communicate with the remote KMS provider.
+func NewGRPCServiceV2(endpoint string, callTimeout time.Duration) (ServiceV2, error) {
+	klog.V(4).Infof("Configure KMS v2 provider with endpoint: %s", endpoint)
+
+	connection, err := dialUnix(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return &gRPCServiceV2{
+		kmsClient:   kmsapi.NewKeyManagementServiceClient(connection),
+		connection:  connection,
+		callTimeout: callTimeout,
+	}, nil
+}
+
+// Close closes the connection to the remote KMS provider.
+func (g *gRPCServiceV2) Close() error {
+	return g.connection.Close()
+}
+
+// Status returns the health of the remote KMS provider and the ID of its current key.
+func (g *gRPCServiceV2) Status() (*StatusResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), g.callTimeout)
+	defer cancel()
+
+	response, err := g.kmsClient.Status(ctx, &kmsapi.StatusRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return &StatusResponse{Version: response.Version, Healthz: response.Healthz, KeyID: response.KeyId}, nil
+}
+
+// Decrypt a given data string to obtain the original byte data.
+func (g *gRPCServiceV2) Decrypt(uid string, keyID string, cipher []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), g.callTimeout)
+	defer cancel()
+
+	request := &kmsapi.DecryptRequest{Ciphertext: cipher, Uid: uid, KeyId: keyID}
+	response, err := g.kmsClient.Decrypt(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return response.Plaintext, nil
+}
+
+// Encrypt bytes to a ciphertext, returning the ID of the key used.
+func (g *gRPCServiceV2) Encrypt(uid string, plain []byte) ([]byte, string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), g.callTimeout)
+	defer cancel()
+
+	request := &kmsapi.EncryptRequest{Plaintext: plain, Uid: uid}
+	response, err := g.kmsClient.Encrypt(ctx, request)
+	if err != nil {
+		return nil, "", err
+	}
+	return response.Ciphertext, response.KeyId, nil
+}
+
+// newUID returns an identifier that correlates a KMS call with the logs of the provider.
+func newUID() string {
+	return string(uuid.NewUUID())
+}
@@ -0,0 +1,381 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by protoc-gen-gogo. DO NOT EDIT.
+// source: service.proto
+
+/*
+Package v2alpha1 is a generated protocol buffer package.
+
+It is generated from these files:
+
+	service.proto
+
+It has these top-level messages:
+
+	StatusRequest
+	StatusResponse
+	DecryptRequest
+	DecryptResponse
+	EncryptRequest
+	EncryptResponse
+*/
+package v2alpha1
+
+import proto "github.com/aaron-prindle/krmapiserver/included/github.com/gogo/protobuf/proto"
+import fmt "fmt"
+import math "math"
+
+import (
+	grpc "github.com/aaron-prindle/krmapiserver/included/google.golang.org/grpc"
+	context "golang.org/x/net/context"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.GoGoProtoPackageIsVersion2 // please upgrade the proto package
+
+type StatusRequest struct {
+}
+
+func (m *StatusRequest) Reset()                    { *m = StatusRequest{} }
+func (m *StatusRequest) String() string            { return proto.CompactTextString(m) }
+func (*StatusRequest) ProtoMessage()               {}
+func (*StatusRequest) Descriptor() ([]byte, []int) { return fileDescriptorService, []int{0} }
+
+type StatusResponse struct {
+	// Version of the KMS plugin API.
+	Version string `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	// Healthz is "ok" if the KMS plugin is able to encrypt and decrypt.
+	Healthz string `protobuf:"bytes,2,opt,name=healthz,proto3" json:"healthz,omitempty"`
+	// KeyId is the ID of the key the KMS plugin currently encrypts with.
+	KeyId string `protobuf:"bytes,3,opt,name=key_id,json=keyId,proto3" json:"key_id,omitempty"`
+}
+
+func (m *StatusResponse) Reset()                    { *m = StatusResponse{} }
+func (m *StatusResponse) String() string            { return proto.CompactTextString(m) }
+func (*StatusResponse) ProtoMessage()               {}
+func (*StatusResponse) Descriptor() ([]byte, []int) { return fileDescriptorService, []int{1} }
+
+func (m *StatusResponse) GetVersion() string {
+	if m != nil {
+		return m.Version
+	}
+	return ""
+}
+
+func (m *StatusResponse) GetHealthz() string {
+	if m != nil {
+		return m.Healthz
+	}
+	return ""
+}
+
+func (m *StatusResponse) GetKeyId() string {
+	if m != nil {
+		return m.KeyId
+	}
+	return ""
+}
+
+type DecryptRequest struct {
+	// The data to be decrypted.
+	Ciphertext []byte `protobuf:"bytes,1,opt,name=ciphertext,proto3" json:"ciphertext,omitempty"`
+	// UID identifies the request for correlating logs of the KMS plugin.
+	Uid string `protobuf:"bytes,2,opt,name=uid,proto3" json:"uid,omitempty"`
+	// KeyId is the ID of the key the data was encrypted with.
+	KeyId string `protobuf:"bytes,3,opt,name=key_id,json=keyId,proto3" json:"key_id,omitempty"`
+}
+
+func (m *DecryptRequest) Reset()                    { *m = DecryptRequest{} }
+func (m *DecryptRequest) String() string            { return proto.CompactTextString(m) }
+func (*DecryptRequest) ProtoMessage()               {}
+func (*DecryptRequest) Descriptor() ([]byte, []int) { return fileDescriptorService, []int{2} }
+
+func (m *DecryptRequest) GetCiphertext() []byte {
+	if m != nil {
+		return m.Ciphertext
+	}
+	return nil
+}
+
+func (m *DecryptRequest) GetUid() string {
+	if m != nil {
+		return m.Uid
+	}
+	return ""
+}
+
+func (m *DecryptRequest) GetKeyId() string {
+	if m != nil {
+		return m.KeyId
+	}
+	return ""
+}
+
+type DecryptResponse struct {
+	// The decrypted data.
+	Plaintext []byte `protobuf:"bytes,1,opt,name=plaintext,proto3" json:"plaintext,omitempty"`
+}
+
+func (m *DecryptResponse) Reset()                    { *m = DecryptResponse{} }
+func (m *DecryptResponse) String() string            { return proto.CompactTextString(m) }
+func (*DecryptResponse) ProtoMessage()               {}
+func (*DecryptResponse) Descriptor() ([]byte, []int) { return fileDescriptorService, []int{3} }
+
+func (m *DecryptResponse) GetPlaintext() []byte {
+	if m != nil {
+		return m.Plaintext
+	}
+	return nil
+}
+
+type EncryptRequest struct {
+	// The data to be encrypted.
+	Plaintext []byte `protobuf:"bytes,1,opt,name=plaintext,proto3" json:"plaintext,omitempty"`
+	// UID identifies the request for correlating logs of the KMS plugin.
+	Uid string `protobuf:"bytes,2,opt,name=uid,proto3" json:"uid,omitempty"`
+}
+
+func (m *EncryptRequest) Reset()                    { *m = EncryptRequest{} }
+func (m *EncryptRequest) String() string            { return proto.CompactTextString(m) }
+func (*EncryptRequest) ProtoMessage()               {}
+func (*EncryptRequest) Descriptor() ([]byte, []int) { return fileDescriptorService, []int{4} }
+
+func (m *EncryptRequest) GetPlaintext() []byte {
+	if m != nil {
+		return m.Plaintext
+	}
+	return nil
+}
+
+func (m *EncryptRequest) GetUid() string {
+	if m != nil {
+		return m.Uid
+	}
+	return ""
+}
+
+type EncryptResponse struct {
+	// The encrypted data.
+	Ciphertext []byte `protobuf:"bytes,1,opt,name=ciphertext,proto3" json:"ciphertext,omitempty"`
+	// KeyId is the ID of the key the data was encrypted with.
+	KeyId string `protobuf:"bytes,2,opt,name=key_id,json=keyId,proto3" json:"key_id,omitempty"`
+}
+
+func (m *EncryptResponse) Reset()                    { *m = EncryptResponse{} }
+func (m *EncryptResponse) String() string            { return proto.CompactTextString(m) }
+func (*EncryptResponse) ProtoMessage()               {}
+func (*EncryptResponse) Descriptor() ([]byte, []int) { return fileDescriptorService, []int{5} }
+
+func (m *EncryptResponse) GetCiphertext() []byte {
+	if m != nil {
+		return m.Ciphertext
+	}
+	return nil
+}
+
+func (m *EncryptResponse) GetKeyId() string {
+	if m != nil {
+		return m.KeyId
+	}
+	return ""
+}
+
+func init() {
+	proto.RegisterType((*StatusRequest)(nil), "v2alpha1.StatusRequest")
+	proto.RegisterType((*StatusResponse)(nil), "v2alpha1.StatusResponse")
+	proto.RegisterType((*DecryptRequest)(nil), "v2alpha1.DecryptRequest")
+	proto.RegisterType((*DecryptResponse)(nil), "v2alpha1.DecryptResponse")
+	proto.RegisterType((*EncryptRequest)(nil), "v2alpha1.EncryptRequest")
+	proto.RegisterType((*EncryptResponse)(nil), "v2alpha1.EncryptResponse")
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// Client API for KeyManagementService service
+
+type KeyManagementServiceClient interface {
+	// Status returns the version and health of the KMS plugin, and the ID of the key it currently encrypts with.
+	Status(ctx context.Context, in *StatusRequest, opts ...grpc.CallOption) (*StatusResponse, error)
+	// Execute decryption operation in KMS provider.
+	Decrypt(ctx context.Context, in *DecryptRequest, opts ...grpc.CallOption) (*DecryptResponse, error)
+	// Execute encryption operation in KMS provider.
+	Encrypt(ctx context.Context, in *EncryptRequest, opts ...grpc.CallOption) (*EncryptResponse, error)
+}
+
+type keyManagementServiceClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewKeyManagementServiceClient(cc *grpc.ClientConn) KeyManagementServiceClient {
+	return &keyManagementServiceClient{cc}
+}
+
+func (c *keyManagementServiceClient) Status(ctx context.Context, in *StatusRequest, opts ...grpc.CallOption) (*StatusResponse, error) {
+	out := new(StatusResponse)
+	err := grpc.Invoke(ctx, "/v2alpha1.KeyManagementService/Status", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyManagementServiceClient) Decrypt(ctx context.Context, in *DecryptRequest, opts ...grpc.CallOption) (*DecryptResponse, error) {
+	out := new(DecryptResponse)
+	err := grpc.Invoke(ctx, "/v2alpha1.KeyManagementService/Decrypt", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyManagementServiceClient) Encrypt(ctx context.Context, in *EncryptRequest, opts ...grpc.CallOption) (*EncryptResponse, error) {
+	out := new(EncryptResponse)
+	err := grpc.Invoke(ctx, "/v2alpha1.KeyManagementService/Encrypt", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Server API for KeyManagementService service
+
+type KeyManagementServiceServer interface {
+	// Status returns the version and health of the KMS plugin, and the ID of the key it currently encrypts with.
+	Status(context.Context, *StatusRequest) (*StatusResponse, error)
+	// Execute decryption operation in KMS provider.
+	Decrypt(context.Context, *DecryptRequest) (*DecryptResponse, error)
+	// Execute encryption operation in KMS provider.
+	Encrypt(context.Context, *EncryptRequest) (*EncryptResponse, error)
+}
+
+func RegisterKeyManagementServiceServer(s *grpc.Server, srv KeyManagementServiceServer) {
+	s.RegisterService(&_KeyManagementService_serviceDesc, srv)
+}
+
+func _KeyManagementService_Status_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyManagementServiceServer).Status(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v2alpha1.KeyManagementService/Status",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyManagementServiceServer).Status(ctx, req.(*StatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeyManagementService_Decrypt_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DecryptRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyManagementServiceServer).Decrypt(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v2alpha1.KeyManagementService/Decrypt",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyManagementServiceServer).Decrypt(ctx, req.(*DecryptRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeyManagementService_Encrypt_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EncryptRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyManagementServiceServer).Encrypt(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v2alpha1.KeyManagementService/Encrypt",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyManagementServiceServer).Encrypt(ctx, req.(*EncryptRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _KeyManagementService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "v2alpha1.KeyManagementService",
+	HandlerType: (*KeyManagementServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Status",
+			Handler:    _KeyManagementService_Status_Handler,
+		},
+		{
+			MethodName: "Decrypt",
+			Handler:    _KeyManagementService_Decrypt_Handler,
+		},
+		{
+			MethodName: "Encrypt",
+			Handler:    _KeyManagementService_Encrypt_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "service.proto",
+}
+
+func init() { proto.RegisterFile("service.proto", fileDescriptorService) }
+
+var fileDescriptorService = []byte{
+	// 301 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x92, 0x4f, 0x4f, 0xb3, 0x40,
+	0x10, 0xc6, 0x4b, 0x9b, 0x97, 0xbe, 0x9d, 0x58, 0x30, 0x1b, 0x8d, 0xd8, 0x18, 0x63, 0xf6, 0xe4,
+	0x09, 0x63, 0x3d, 0x9b, 0xf4, 0x20, 0x89, 0xc6, 0x78, 0xa1, 0x27, 0xe3, 0xc1, 0xac, 0x30, 0x91,
+	0x4d, 0x71, 0x59, 0xd9, 0x85, 0x88, 0xdf, 0xd4, 0x6f, 0x63, 0xca, 0x1f, 0x0b, 0x62, 0xf5, 0xc6,
+	0xcc, 0x33, 0xf9, 0xcd, 0xc3, 0x3c, 0x0b, 0x53, 0x85, 0x69, 0xce, 0x03, 0x74, 0x65, 0x9a, 0xe8,
+	0x84, 0xfc, 0xcf, 0xe7, 0x2c, 0x96, 0x11, 0x3b, 0xa7, 0x36, 0x4c, 0x97, 0x9a, 0xe9, 0x4c, 0xf9,
+	0xf8, 0x9a, 0xa1, 0xd2, 0xf4, 0x01, 0xac, 0xa6, 0xa1, 0x64, 0x22, 0x14, 0x12, 0x07, 0xc6, 0x39,
+	0xa6, 0x8a, 0x27, 0xc2, 0x31, 0x4e, 0x8c, 0xd3, 0x89, 0xdf, 0x94, 0x6b, 0x25, 0x42, 0x16, 0xeb,
+	0xe8, 0xdd, 0x19, 0x56, 0x4a, 0x5d, 0x92, 0x7d, 0x30, 0x57, 0x58, 0x3c, 0xf2, 0xd0, 0x19, 0x95,
+	0xc2, 0xbf, 0x15, 0x16, 0x37, 0x21, 0xbd, 0x07, 0xeb, 0x0a, 0x83, 0xb4, 0x90, 0xba, 0x5e, 0x47,
+	0x8e, 0x01, 0x02, 0x2e, 0x23, 0x4c, 0x35, 0xbe, 0xe9, 0x92, 0xbf, 0xe3, 0xb7, 0x3a, 0x64, 0x17,
+	0x46, 0x19, 0x0f, 0x6b, 0xfc, 0xfa, 0x73, 0x1b, 0xfa, 0x0c, 0xec, 0x2f, 0x74, 0x6d, 0xfc, 0x08,
+	0x26, 0x32, 0x66, 0x5c, 0xb4, 0xd0, 0x9b, 0x06, 0x5d, 0x80, 0xe5, 0x89, 0x8e, 0x97, 0x5f, 0xe7,
+	0xfb, 0x4e, 0xe8, 0x35, 0xd8, 0x9e, 0xe8, 0xae, 0xfc, 0xeb, 0x77, 0x36, 0xe6, 0x87, 0x2d, 0xf3,
+	0xf3, 0x0f, 0x03, 0xf6, 0x6e, 0xb1, 0xb8, 0x63, 0x82, 0x3d, 0xe3, 0x0b, 0x0a, 0xbd, 0xac, 0xe2,
+	0x22, 0x97, 0x60, 0x56, 0x69, 0x90, 0x03, 0xb7, 0xc9, 0xcc, 0xed, 0x04, 0x36, 0x73, 0xfa, 0x42,
+	0x65, 0x86, 0x0e, 0xc8, 0x02, 0xc6, 0xf5, 0x51, 0x48, 0x6b, 0xac, 0x1b, 0xc1, 0xec, 0xf0, 0x07,
+	0xa5, 0x4d, 0xf0, 0x44, 0x8f, 0xe0, 0x89, 0x6d, 0x84, 0x6f, 0x07, 0xa1, 0x83, 0x27, 0xb3, 0x7c,
+	0x72, 0x17, 0x9f, 0x03, 0x00, 0x53, 0x0b, 0xae, 0x93, 0x83, 0x02, 0x00, 0x00,
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package hkdf transforms values for storage at rest using an AEAD with a key
+// that is derived from a master key for every value.
+package hkdf
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	cryptohkdf "golang.org/x/crypto/hkdf"
+
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/value"
+)
+
+const (
+	// KeySize is the size of master keys and of the derived keys.
+	KeySize = 32
+	// saltSize is the size of the random salt stored at the beginning of
+	// every value.
+	saltSize = 32
+)
+
+// AEADFunc returns the AEAD used with a derived key of KeySize bytes.
+type AEADFunc func(key []byte) (cipher.AEAD, error)
+
+// hkdfTransformer implements authenticated encryption at rest with a key
+// that is derived with HKDF-SHA256 from a master key and a random 32 byte salt
+// for every value. The salt is placed at the beginning of the cipher text.
+//
+// Because every key encrypts a single value, the number of values that can be
+// encrypted under one master key is not limited by nonce collisions as with
+// AES-GCM and random nonces, and the nonce is always zero. The authenticated
+// data provided as part of the value.Context must match when the same value
+// is set to and loaded from storage.
+type hkdfTransformer struct {
+	masterKey []byte
+	info      []byte
+	newAEAD   AEADFunc
+}
+
+// NewHKDFTransformer takes the given master key and encrypts every value with
+// the AEAD returned by newAEAD for a key derived from it. info binds the
+// derived keys to their use, values written with one info cannot be read with
+// another one.
+func NewHKDFTransformer(masterKey []byte, info string, newAEAD AEADFunc) (value.Transformer, error) {
+	if len(masterKey) != KeySize {
+		return nil, fmt.Errorf("expected key size %d for hkdf provider, got %d", KeySize, len(masterKey))
+	}
+	return &hkdfTransformer{masterKey: masterKey, info: []byte(info), newAEAD: newAEAD}, nil
+}
+
+// NewAESGCMTransformer returns a transformer that encrypts every value with
+// AES-256-GCM and a key derived from masterKey.
+func NewAESGCMTransformer(masterKey []byte) (value.Transformer, error) {
+	return NewHKDFTransformer(masterKey, "aesgcm", NewAESGCM)
+}
+
+// NewChaCha20Poly1305Transformer returns a transformer that encrypts every
+// value with ChaCha20-Poly1305 and a key derived from masterKey.
+func NewChaCha20Poly1305Transformer(masterKey []byte) (value.Transformer, error) {
+	return NewHKDFTransformer(masterKey, "chacha20poly1305", chacha20poly1305.New)
+}
+
+// NewAESGCM returns an AES-GCM AEAD for key.
+func NewAESGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (t *hkdfTransformer) aead(salt []byte) (cipher.AEAD, error) {
+	key := make([]byte, KeySize)
+	if _, err := io.ReadFull(cryptohkdf.New(sha256.New, t.masterKey, salt, t.info), key); err != nil {
+		return nil, err
+	}
+	return t.newAEAD(key)
+}
+
+func (t *hkdfTransformer) TransformFromStorage(data []byte, context value.Context) ([]byte, bool, error) {
+	if len(data) < saltSize {
+		return nil, false, fmt.Errorf("the stored data was shorter than the required size")
+	}
+	aead, err := t.aead(data[:saltSize])
+	if err != nil {
+		return nil, false, err
+	}
+	result, err := aead.Open(nil, make([]byte, aead.NonceSize()), data[saltSize:], context.AuthenticatedData())
+	return result, false, err
+}
+
+func (t *hkdfTransformer) TransformToStorage(data []byte, context value.Context) ([]byte, error) {
+	salt := make([]byte, saltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, fmt.Errorf("unable to read sufficient random bytes")
+	}
+	aead, err := t.aead(salt)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]byte, saltSize, saltSize+len(data)+aead.Overhead())
+	copy(result, salt)
+	return aead.Seal(result, make([]byte, aead.NonceSize()), data, context.AuthenticatedData()), nil
+}
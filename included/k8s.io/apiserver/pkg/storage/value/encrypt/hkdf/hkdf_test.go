@@ -0,0 +1,207 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hkdf
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/value"
+)
+
+var (
+	key1 = bytes.Repeat([]byte{0x01}, KeySize)
+	key2 = bytes.Repeat([]byte{0x02}, KeySize)
+)
+
+var constructors = []struct {
+	name string
+	new  func(masterKey []byte) (value.Transformer, error)
+}{
+	{"aesgcm", NewAESGCMTransformer},
+	{"chacha20poly1305", NewChaCha20Poly1305Transformer},
+}
+
+func newTransformer(t testing.TB, new func([]byte) (value.Transformer, error), key []byte) value.Transformer {
+	transformer, err := new(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return transformer
+}
+
+func TestRoundTrip(t *testing.T) {
+	context := value.DefaultContext([]byte("authenticated_data"))
+	for _, c := range constructors {
+		t.Run(c.name, func(t *testing.T) {
+			transformer := newTransformer(t, c.new, key1)
+			for _, size := range []int{0, 1, 16, 1024, 1 << 20} {
+				data := bytes.Repeat([]byte{'a'}, size)
+				out, err := transformer.TransformToStorage(data, context)
+				if err != nil {
+					t.Fatalf("%d: %v", size, err)
+				}
+				from, stale, err := transformer.TransformFromStorage(out, context)
+				if err != nil {
+					t.Fatalf("%d: %v", size, err)
+				}
+				if stale || !bytes.Equal(data, from) {
+					t.Fatalf("%d: unexpected data: %t %q", size, stale, from)
+				}
+			}
+		})
+	}
+}
+
+func TestKeyPerWrite(t *testing.T) {
+	context := value.DefaultContext([]byte("authenticated_data"))
+	for _, c := range constructors {
+		t.Run(c.name, func(t *testing.T) {
+			transformer := newTransformer(t, c.new, key1)
+			first, err := transformer.TransformToStorage([]byte("value"), context)
+			if err != nil {
+				t.Fatal(err)
+			}
+			second, err := transformer.TransformToStorage([]byte("value"), context)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if bytes.Equal(first[:saltSize], second[:saltSize]) {
+				t.Fatal("two writes used the same salt")
+			}
+			if bytes.Equal(first[saltSize:], second[saltSize:]) {
+				t.Fatal("two writes of the same value produced the same cipher text")
+			}
+		})
+	}
+}
+
+func TestAuthentication(t *testing.T) {
+	context := value.DefaultContext([]byte("authenticated_data"))
+	for _, c := range constructors {
+		t.Run(c.name, func(t *testing.T) {
+			transformer := newTransformer(t, c.new, key1)
+			out, err := transformer.TransformToStorage([]byte("value"), context)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, _, err := transformer.TransformFromStorage(out, value.DefaultContext([]byte("incorrect_context"))); err == nil {
+				t.Error("expected an error for incorrect authenticated data")
+			}
+			for _, i := range []int{0, saltSize, len(out) - 1} {
+				tampered := append([]byte(nil), out...)
+				tampered[i] ^= 0xff
+				if _, _, err := transformer.TransformFromStorage(tampered, context); err == nil {
+					t.Errorf("expected an error for data modified at %d", i)
+				}
+			}
+			if _, _, err := transformer.TransformFromStorage(out[:saltSize-1], context); err == nil {
+				t.Error("expected an error for truncated data")
+			}
+			if _, _, err := newTransformer(t, c.new, key2).TransformFromStorage(out, context); err == nil {
+				t.Error("expected an error for another master key")
+			}
+		})
+	}
+
+	// Keys derived for one cipher are never used by another one.
+	out, err := newTransformer(t, NewAESGCMTransformer, key1).TransformToStorage([]byte("value"), context)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := NewHKDFTransformer(key1, "other", NewAESGCM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := other.TransformFromStorage(out, context); err == nil {
+		t.Error("expected an error for another info")
+	}
+}
+
+func TestInvalidKeySize(t *testing.T) {
+	for _, c := range constructors {
+		for _, size := range []int{0, 16, 24, 64} {
+			if _, err := c.new(make([]byte, size)); err == nil {
+				t.Errorf("%s: expected an error for a key of %d bytes", c.name, size)
+			}
+		}
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	testErr := fmt.Errorf("test error")
+	context := value.DefaultContext([]byte("authenticated_data"))
+	for _, c := range constructors {
+		t.Run(c.name, func(t *testing.T) {
+			p := value.NewPrefixTransformers(testErr,
+				value.PrefixTransformer{Prefix: []byte("first:"), Transformer: newTransformer(t, c.new, key1)},
+				value.PrefixTransformer{Prefix: []byte("second:"), Transformer: newTransformer(t, c.new, key2)},
+			)
+			out, err := p.TransformToStorage([]byte("firstvalue"), context)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.HasPrefix(out, []byte("first:")) {
+				t.Fatalf("unexpected prefix: %q", out)
+			}
+			from, stale, err := p.TransformFromStorage(out, context)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stale || !bytes.Equal([]byte("firstvalue"), from) {
+				t.Fatalf("unexpected data: %t %q", stale, from)
+			}
+
+			// reverse the order, use the second key
+			p = value.NewPrefixTransformers(testErr,
+				value.PrefixTransformer{Prefix: []byte("second:"), Transformer: newTransformer(t, c.new, key2)},
+				value.PrefixTransformer{Prefix: []byte("first:"), Transformer: newTransformer(t, c.new, key1)},
+			)
+			from, stale, err = p.TransformFromStorage(out, context)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !stale || !bytes.Equal([]byte("firstvalue"), from) {
+				t.Fatalf("unexpected data: %t %q", stale, from)
+			}
+			out, err = p.TransformToStorage([]byte("firstvalue"), context)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.HasPrefix(out, []byte("second:")) {
+				t.Fatalf("unexpected prefix: %q", out)
+			}
+		})
+	}
+}
+
+func BenchmarkWrite(b *testing.B) {
+	context := value.DefaultContext([]byte("authenticated_data"))
+	data := bytes.Repeat([]byte{'a'}, 1024)
+	for _, c := range constructors {
+		b.Run(c.name, func(b *testing.B) {
+			transformer := newTransformer(b, c.new, key1)
+			b.SetBytes(int64(len(data)))
+			for i := 0; i < b.N; i++ {
+				if _, err := transformer.TransformToStorage(data, context); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -85,6 +85,15 @@ var (
 		},
 	)
 
+	envelopeStaleKeyIDReadsTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "envelope_stale_key_id_reads_total",
+			Help:      "Total number of values read that were encrypted with a key other than the current key of the KMS provider.",
+		},
+	)
+
 	dataKeyGenerationLatencies = prometheus.NewHistogram(
 		prometheus.HistogramOpts{
 			Namespace: namespace,
@@ -122,6 +131,7 @@ func RegisterMetrics() {
 		prometheus.MustRegister(transformerOperationsTotal)
 		prometheus.MustRegister(deprecatedTransformerFailuresTotal)
 		prometheus.MustRegister(envelopeTransformationCacheMissTotal)
+		prometheus.MustRegister(envelopeStaleKeyIDReadsTotal)
 		prometheus.MustRegister(dataKeyGenerationLatencies)
 		prometheus.MustRegister(deprecatedDataKeyGenerationLatencies)
 		prometheus.MustRegister(dataKeyGenerationFailuresTotal)
@@ -147,6 +157,11 @@ func RecordCacheMiss() {
 	envelopeTransformationCacheMissTotal.Inc()
 }
 
+// RecordStaleKeyID records a read of a value encrypted with a KMS key other than the current one.
+func RecordStaleKeyID() {
+	envelopeStaleKeyIDReadsTotal.Inc()
+}
+
 // RecordDataKeyGeneration records latencies and count of Data Encryption Key generation operations.
 func RecordDataKeyGeneration(start time.Time, err error) {
 	if err != nil {
@@ -80,6 +80,15 @@ var (
 	minWatchTimeout = 5 * time.Minute
 )
 
+// ResourceVersionUpdater is implemented by stores that track the
+// resourceVersion the reflector observed, including the one of bookmarks,
+// which are not otherwise passed to the store.
+type ResourceVersionUpdater interface {
+	// UpdateResourceVersion is called every time the resourceVersion
+	// observed by the reflector is updated by a watch event.
+	UpdateResourceVersion(resourceVersion string)
+}
+
 // NewNamespaceKeyedIndexerAndReflector creates an Indexer and a Reflector
 // The indexer is configured to key on namespace
 func NewNamespaceKeyedIndexerAndReflector(lw ListerWatcher, expectedType interface{}, resyncPeriod time.Duration) (indexer Indexer, reflector *Reflector) {
@@ -373,6 +382,9 @@ loop:
 				utilruntime.HandleError(fmt.Errorf("%s: unable to understand watch event %#v", r.name, event))
 			}
 			*resourceVersion = newResourceVersion
+			if rvu, ok := r.store.(ResourceVersionUpdater); ok {
+				rvu.UpdateResourceVersion(newResourceVersion)
+			}
 			r.setLastSyncResourceVersion(newResourceVersion)
 			eventCount++
 		}
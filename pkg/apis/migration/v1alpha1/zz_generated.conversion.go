@@ -0,0 +1,258 @@
+// +build !ignore_autogenerated
+
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by conversion-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	unsafe "unsafe"
+
+	v1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/core/v1"
+	v1alpha1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/migration/v1alpha1"
+	conversion "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/conversion"
+	runtime "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
+	core "github.com/aaron-prindle/krmapiserver/pkg/apis/core"
+	migration "github.com/aaron-prindle/krmapiserver/pkg/apis/migration"
+)
+
+func init() {
+	localSchemeBuilder.Register(RegisterConversions)
+}
+
+// RegisterConversions adds conversion functions to the given scheme.
+// Public to allow building arbitrary schemes.
+func RegisterConversions(s *runtime.Scheme) error {
+	if err := s.AddGeneratedConversionFunc((*v1alpha1.GroupVersionResource)(nil), (*migration.GroupVersionResource)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_GroupVersionResource_To_migration_GroupVersionResource(a.(*v1alpha1.GroupVersionResource), b.(*migration.GroupVersionResource), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*migration.GroupVersionResource)(nil), (*v1alpha1.GroupVersionResource)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_migration_GroupVersionResource_To_v1alpha1_GroupVersionResource(a.(*migration.GroupVersionResource), b.(*v1alpha1.GroupVersionResource), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha1.MigrationCondition)(nil), (*migration.MigrationCondition)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_MigrationCondition_To_migration_MigrationCondition(a.(*v1alpha1.MigrationCondition), b.(*migration.MigrationCondition), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*migration.MigrationCondition)(nil), (*v1alpha1.MigrationCondition)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_migration_MigrationCondition_To_v1alpha1_MigrationCondition(a.(*migration.MigrationCondition), b.(*v1alpha1.MigrationCondition), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha1.StorageVersionMigration)(nil), (*migration.StorageVersionMigration)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_StorageVersionMigration_To_migration_StorageVersionMigration(a.(*v1alpha1.StorageVersionMigration), b.(*migration.StorageVersionMigration), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*migration.StorageVersionMigration)(nil), (*v1alpha1.StorageVersionMigration)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_migration_StorageVersionMigration_To_v1alpha1_StorageVersionMigration(a.(*migration.StorageVersionMigration), b.(*v1alpha1.StorageVersionMigration), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha1.StorageVersionMigrationList)(nil), (*migration.StorageVersionMigrationList)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_StorageVersionMigrationList_To_migration_StorageVersionMigrationList(a.(*v1alpha1.StorageVersionMigrationList), b.(*migration.StorageVersionMigrationList), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*migration.StorageVersionMigrationList)(nil), (*v1alpha1.StorageVersionMigrationList)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_migration_StorageVersionMigrationList_To_v1alpha1_StorageVersionMigrationList(a.(*migration.StorageVersionMigrationList), b.(*v1alpha1.StorageVersionMigrationList), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha1.StorageVersionMigrationSpec)(nil), (*migration.StorageVersionMigrationSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_StorageVersionMigrationSpec_To_migration_StorageVersionMigrationSpec(a.(*v1alpha1.StorageVersionMigrationSpec), b.(*migration.StorageVersionMigrationSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*migration.StorageVersionMigrationSpec)(nil), (*v1alpha1.StorageVersionMigrationSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_migration_StorageVersionMigrationSpec_To_v1alpha1_StorageVersionMigrationSpec(a.(*migration.StorageVersionMigrationSpec), b.(*v1alpha1.StorageVersionMigrationSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha1.StorageVersionMigrationStatus)(nil), (*migration.StorageVersionMigrationStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_StorageVersionMigrationStatus_To_migration_StorageVersionMigrationStatus(a.(*v1alpha1.StorageVersionMigrationStatus), b.(*migration.StorageVersionMigrationStatus), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*migration.StorageVersionMigrationStatus)(nil), (*v1alpha1.StorageVersionMigrationStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_migration_StorageVersionMigrationStatus_To_v1alpha1_StorageVersionMigrationStatus(a.(*migration.StorageVersionMigrationStatus), b.(*v1alpha1.StorageVersionMigrationStatus), scope)
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+func autoConvert_v1alpha1_GroupVersionResource_To_migration_GroupVersionResource(in *v1alpha1.GroupVersionResource, out *migration.GroupVersionResource, s conversion.Scope) error {
+	out.Group = in.Group
+	out.Version = in.Version
+	out.Resource = in.Resource
+	return nil
+}
+
+// Convert_v1alpha1_GroupVersionResource_To_migration_GroupVersionResource is an autogenerated conversion function.
+func Convert_v1alpha1_GroupVersionResource_To_migration_GroupVersionResource(in *v1alpha1.GroupVersionResource, out *migration.GroupVersionResource, s conversion.Scope) error {
+	return autoConvert_v1alpha1_GroupVersionResource_To_migration_GroupVersionResource(in, out, s)
+}
+
+func autoConvert_migration_GroupVersionResource_To_v1alpha1_GroupVersionResource(in *migration.GroupVersionResource, out *v1alpha1.GroupVersionResource, s conversion.Scope) error {
+	out.Group = in.Group
+	out.Version = in.Version
+	out.Resource = in.Resource
+	return nil
+}
+
+// Convert_migration_GroupVersionResource_To_v1alpha1_GroupVersionResource is an autogenerated conversion function.
+func Convert_migration_GroupVersionResource_To_v1alpha1_GroupVersionResource(in *migration.GroupVersionResource, out *v1alpha1.GroupVersionResource, s conversion.Scope) error {
+	return autoConvert_migration_GroupVersionResource_To_v1alpha1_GroupVersionResource(in, out, s)
+}
+
+func autoConvert_v1alpha1_MigrationCondition_To_migration_MigrationCondition(in *v1alpha1.MigrationCondition, out *migration.MigrationCondition, s conversion.Scope) error {
+	out.Type = migration.MigrationConditionType(in.Type)
+	out.Status = core.ConditionStatus(in.Status)
+	out.LastUpdateTime = in.LastUpdateTime
+	out.Reason = in.Reason
+	out.Message = in.Message
+	return nil
+}
+
+// Convert_v1alpha1_MigrationCondition_To_migration_MigrationCondition is an autogenerated conversion function.
+func Convert_v1alpha1_MigrationCondition_To_migration_MigrationCondition(in *v1alpha1.MigrationCondition, out *migration.MigrationCondition, s conversion.Scope) error {
+	return autoConvert_v1alpha1_MigrationCondition_To_migration_MigrationCondition(in, out, s)
+}
+
+func autoConvert_migration_MigrationCondition_To_v1alpha1_MigrationCondition(in *migration.MigrationCondition, out *v1alpha1.MigrationCondition, s conversion.Scope) error {
+	out.Type = v1alpha1.MigrationConditionType(in.Type)
+	out.Status = v1.ConditionStatus(in.Status)
+	out.LastUpdateTime = in.LastUpdateTime
+	out.Reason = in.Reason
+	out.Message = in.Message
+	return nil
+}
+
+// Convert_migration_MigrationCondition_To_v1alpha1_MigrationCondition is an autogenerated conversion function.
+func Convert_migration_MigrationCondition_To_v1alpha1_MigrationCondition(in *migration.MigrationCondition, out *v1alpha1.MigrationCondition, s conversion.Scope) error {
+	return autoConvert_migration_MigrationCondition_To_v1alpha1_MigrationCondition(in, out, s)
+}
+
+func autoConvert_v1alpha1_StorageVersionMigration_To_migration_StorageVersionMigration(in *v1alpha1.StorageVersionMigration, out *migration.StorageVersionMigration, s conversion.Scope) error {
+	out.ObjectMeta = in.ObjectMeta
+	if err := Convert_v1alpha1_StorageVersionMigrationSpec_To_migration_StorageVersionMigrationSpec(&in.Spec, &out.Spec, s); err != nil {
+		return err
+	}
+	if err := Convert_v1alpha1_StorageVersionMigrationStatus_To_migration_StorageVersionMigrationStatus(&in.Status, &out.Status, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1alpha1_StorageVersionMigration_To_migration_StorageVersionMigration is an autogenerated conversion function.
+func Convert_v1alpha1_StorageVersionMigration_To_migration_StorageVersionMigration(in *v1alpha1.StorageVersionMigration, out *migration.StorageVersionMigration, s conversion.Scope) error {
+	return autoConvert_v1alpha1_StorageVersionMigration_To_migration_StorageVersionMigration(in, out, s)
+}
+
+func autoConvert_migration_StorageVersionMigration_To_v1alpha1_StorageVersionMigration(in *migration.StorageVersionMigration, out *v1alpha1.StorageVersionMigration, s conversion.Scope) error {
+	out.ObjectMeta = in.ObjectMeta
+	if err := Convert_migration_StorageVersionMigrationSpec_To_v1alpha1_StorageVersionMigrationSpec(&in.Spec, &out.Spec, s); err != nil {
+		return err
+	}
+	if err := Convert_migration_StorageVersionMigrationStatus_To_v1alpha1_StorageVersionMigrationStatus(&in.Status, &out.Status, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_migration_StorageVersionMigration_To_v1alpha1_StorageVersionMigration is an autogenerated conversion function.
+func Convert_migration_StorageVersionMigration_To_v1alpha1_StorageVersionMigration(in *migration.StorageVersionMigration, out *v1alpha1.StorageVersionMigration, s conversion.Scope) error {
+	return autoConvert_migration_StorageVersionMigration_To_v1alpha1_StorageVersionMigration(in, out, s)
+}
+
+func autoConvert_v1alpha1_StorageVersionMigrationList_To_migration_StorageVersionMigrationList(in *v1alpha1.StorageVersionMigrationList, out *migration.StorageVersionMigrationList, s conversion.Scope) error {
+	out.ListMeta = in.ListMeta
+	out.Items = *(*[]migration.StorageVersionMigration)(unsafe.Pointer(&in.Items))
+	return nil
+}
+
+// Convert_v1alpha1_StorageVersionMigrationList_To_migration_StorageVersionMigrationList is an autogenerated conversion function.
+func Convert_v1alpha1_StorageVersionMigrationList_To_migration_StorageVersionMigrationList(in *v1alpha1.StorageVersionMigrationList, out *migration.StorageVersionMigrationList, s conversion.Scope) error {
+	return autoConvert_v1alpha1_StorageVersionMigrationList_To_migration_StorageVersionMigrationList(in, out, s)
+}
+
+func autoConvert_migration_StorageVersionMigrationList_To_v1alpha1_StorageVersionMigrationList(in *migration.StorageVersionMigrationList, out *v1alpha1.StorageVersionMigrationList, s conversion.Scope) error {
+	out.ListMeta = in.ListMeta
+	out.Items = *(*[]v1alpha1.StorageVersionMigration)(unsafe.Pointer(&in.Items))
+	return nil
+}
+
+// Convert_migration_StorageVersionMigrationList_To_v1alpha1_StorageVersionMigrationList is an autogenerated conversion function.
+func Convert_migration_StorageVersionMigrationList_To_v1alpha1_StorageVersionMigrationList(in *migration.StorageVersionMigrationList, out *v1alpha1.StorageVersionMigrationList, s conversion.Scope) error {
+	return autoConvert_migration_StorageVersionMigrationList_To_v1alpha1_StorageVersionMigrationList(in, out, s)
+}
+
+func autoConvert_v1alpha1_StorageVersionMigrationSpec_To_migration_StorageVersionMigrationSpec(in *v1alpha1.StorageVersionMigrationSpec, out *migration.StorageVersionMigrationSpec, s conversion.Scope) error {
+	if err := Convert_v1alpha1_GroupVersionResource_To_migration_GroupVersionResource(&in.Resource, &out.Resource, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1alpha1_StorageVersionMigrationSpec_To_migration_StorageVersionMigrationSpec is an autogenerated conversion function.
+func Convert_v1alpha1_StorageVersionMigrationSpec_To_migration_StorageVersionMigrationSpec(in *v1alpha1.StorageVersionMigrationSpec, out *migration.StorageVersionMigrationSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha1_StorageVersionMigrationSpec_To_migration_StorageVersionMigrationSpec(in, out, s)
+}
+
+func autoConvert_migration_StorageVersionMigrationSpec_To_v1alpha1_StorageVersionMigrationSpec(in *migration.StorageVersionMigrationSpec, out *v1alpha1.StorageVersionMigrationSpec, s conversion.Scope) error {
+	if err := Convert_migration_GroupVersionResource_To_v1alpha1_GroupVersionResource(&in.Resource, &out.Resource, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_migration_StorageVersionMigrationSpec_To_v1alpha1_StorageVersionMigrationSpec is an autogenerated conversion function.
+func Convert_migration_StorageVersionMigrationSpec_To_v1alpha1_StorageVersionMigrationSpec(in *migration.StorageVersionMigrationSpec, out *v1alpha1.StorageVersionMigrationSpec, s conversion.Scope) error {
+	return autoConvert_migration_StorageVersionMigrationSpec_To_v1alpha1_StorageVersionMigrationSpec(in, out, s)
+}
+
+func autoConvert_v1alpha1_StorageVersionMigrationStatus_To_migration_StorageVersionMigrationStatus(in *v1alpha1.StorageVersionMigrationStatus, out *migration.StorageVersionMigrationStatus, s conversion.Scope) error {
+	out.Conditions = *(*[]migration.MigrationCondition)(unsafe.Pointer(&in.Conditions))
+	out.StorageVersionHash = in.StorageVersionHash
+	out.ContinueToken = in.ContinueToken
+	out.MigratedObjects = in.MigratedObjects
+	return nil
+}
+
+// Convert_v1alpha1_StorageVersionMigrationStatus_To_migration_StorageVersionMigrationStatus is an autogenerated conversion function.
+func Convert_v1alpha1_StorageVersionMigrationStatus_To_migration_StorageVersionMigrationStatus(in *v1alpha1.StorageVersionMigrationStatus, out *migration.StorageVersionMigrationStatus, s conversion.Scope) error {
+	return autoConvert_v1alpha1_StorageVersionMigrationStatus_To_migration_StorageVersionMigrationStatus(in, out, s)
+}
+
+func autoConvert_migration_StorageVersionMigrationStatus_To_v1alpha1_StorageVersionMigrationStatus(in *migration.StorageVersionMigrationStatus, out *v1alpha1.StorageVersionMigrationStatus, s conversion.Scope) error {
+	out.Conditions = *(*[]v1alpha1.MigrationCondition)(unsafe.Pointer(&in.Conditions))
+	out.StorageVersionHash = in.StorageVersionHash
+	out.ContinueToken = in.ContinueToken
+	out.MigratedObjects = in.MigratedObjects
+	return nil
+}
+
+// Convert_migration_StorageVersionMigrationStatus_To_v1alpha1_StorageVersionMigrationStatus is an autogenerated conversion function.
+func Convert_migration_StorageVersionMigrationStatus_To_v1alpha1_StorageVersionMigrationStatus(in *migration.StorageVersionMigrationStatus, out *v1alpha1.StorageVersionMigrationStatus, s conversion.Scope) error {
+	return autoConvert_migration_StorageVersionMigrationStatus_To_v1alpha1_StorageVersionMigrationStatus(in, out, s)
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	apivalidation "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/api/validation"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/util/sets"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/util/validation/field"
+	api "github.com/aaron-prindle/krmapiserver/pkg/apis/core"
+	"github.com/aaron-prindle/krmapiserver/pkg/apis/migration"
+)
+
+var supportedConditionTypes = sets.NewString(
+	string(migration.MigrationRunning),
+	string(migration.MigrationSucceeded),
+	string(migration.MigrationFailed),
+)
+
+var supportedConditionStatuses = sets.NewString(
+	string(api.ConditionTrue),
+	string(api.ConditionFalse),
+	string(api.ConditionUnknown),
+)
+
+// ValidateStorageVersionMigration validates a StorageVersionMigration.
+func ValidateStorageVersionMigration(svm *migration.StorageVersionMigration) field.ErrorList {
+	allErrs := apivalidation.ValidateObjectMeta(&svm.ObjectMeta, false, apivalidation.NameIsDNSSubdomain, field.NewPath("metadata"))
+	allErrs = append(allErrs, validateGroupVersionResource(&svm.Spec.Resource, field.NewPath("spec", "resource"))...)
+	return allErrs
+}
+
+// ValidateStorageVersionMigrationUpdate validates an update of a
+// StorageVersionMigration. The resource of a migration is immutable.
+func ValidateStorageVersionMigrationUpdate(svm, old *migration.StorageVersionMigration) field.ErrorList {
+	allErrs := apivalidation.ValidateObjectMetaUpdate(&svm.ObjectMeta, &old.ObjectMeta, field.NewPath("metadata"))
+	allErrs = append(allErrs, apivalidation.ValidateImmutableField(svm.Spec, old.Spec, field.NewPath("spec"))...)
+	return allErrs
+}
+
+// ValidateStorageVersionMigrationStatusUpdate validates an update of the
+// status of a StorageVersionMigration.
+func ValidateStorageVersionMigrationStatusUpdate(svm, old *migration.StorageVersionMigration) field.ErrorList {
+	allErrs := apivalidation.ValidateObjectMetaUpdate(&svm.ObjectMeta, &old.ObjectMeta, field.NewPath("metadata"))
+	allErrs = append(allErrs, validateStatus(&svm.Status, field.NewPath("status"))...)
+	return allErrs
+}
+
+func validateGroupVersionResource(gvr *migration.GroupVersionResource, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if len(gvr.Resource) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("resource"), ""))
+	}
+	return allErrs
+}
+
+func validateStatus(status *migration.StorageVersionMigrationStatus, fldPath *field.Path) field.ErrorList {
+	allErrs := apivalidation.ValidateNonnegativeField(status.MigratedObjects, fldPath.Child("migratedObjects"))
+	seen := sets.NewString()
+	for i, c := range status.Conditions {
+		idxPath := fldPath.Child("conditions").Index(i)
+		if !supportedConditionTypes.Has(string(c.Type)) {
+			allErrs = append(allErrs, field.NotSupported(idxPath.Child("type"), c.Type, supportedConditionTypes.List()))
+		} else if seen.Has(string(c.Type)) {
+			allErrs = append(allErrs, field.Duplicate(idxPath.Child("type"), c.Type))
+		}
+		seen.Insert(string(c.Type))
+		if !supportedConditionStatuses.Has(string(c.Status)) {
+			allErrs = append(allErrs, field.NotSupported(idxPath.Child("status"), c.Status, supportedConditionStatuses.List()))
+		}
+	}
+	return allErrs
+}
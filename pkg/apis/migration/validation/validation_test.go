@@ -0,0 +1,133 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"testing"
+
+	metav1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1"
+	api "github.com/aaron-prindle/krmapiserver/pkg/apis/core"
+	"github.com/aaron-prindle/krmapiserver/pkg/apis/migration"
+)
+
+func TestValidateStorageVersionMigration(t *testing.T) {
+	tests := []struct {
+		name string
+		svm  *migration.StorageVersionMigration
+		errs int
+	}{
+		{
+			name: "valid",
+			svm:  newMigration("deployments.apps-abcde", "apps", "deployments"),
+		},
+		{
+			name: "core group",
+			svm:  newMigration("configmaps-abcde", "", "configmaps"),
+		},
+		{
+			name: "missing resource",
+			svm:  newMigration("apps-abcde", "apps", ""),
+			errs: 1,
+		},
+		{
+			name: "invalid name",
+			svm:  newMigration("Deployments", "apps", "deployments"),
+			errs: 1,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if errs := ValidateStorageVersionMigration(tc.svm); len(errs) != tc.errs {
+				t.Errorf("expected %d errors, got %v", tc.errs, errs)
+			}
+		})
+	}
+}
+
+func TestValidateStorageVersionMigrationUpdate(t *testing.T) {
+	old := newMigration("deployments.apps-abcde", "apps", "deployments")
+	svm := old.DeepCopy()
+	svm.Spec.Resource.Resource = "replicasets"
+	if errs := ValidateStorageVersionMigrationUpdate(svm, old); len(errs) != 1 {
+		t.Errorf("expected the resource to be immutable, got %v", errs)
+	}
+}
+
+func TestValidateStorageVersionMigrationStatusUpdate(t *testing.T) {
+	old := newMigration("deployments.apps-abcde", "apps", "deployments")
+	tests := []struct {
+		name   string
+		status migration.StorageVersionMigrationStatus
+		errs   int
+	}{
+		{
+			name: "valid",
+			status: migration.StorageVersionMigrationStatus{
+				Conditions: []migration.MigrationCondition{
+					{Type: migration.MigrationRunning, Status: api.ConditionFalse},
+					{Type: migration.MigrationSucceeded, Status: api.ConditionTrue},
+				},
+				StorageVersionHash: "8a0Ia4m8EXA=",
+				MigratedObjects:    10,
+			},
+		},
+		{
+			name: "unknown condition type and status",
+			status: migration.StorageVersionMigrationStatus{
+				Conditions: []migration.MigrationCondition{
+					{Type: "Paused", Status: "Maybe"},
+				},
+			},
+			errs: 2,
+		},
+		{
+			name: "duplicate condition",
+			status: migration.StorageVersionMigrationStatus{
+				Conditions: []migration.MigrationCondition{
+					{Type: migration.MigrationRunning, Status: api.ConditionTrue},
+					{Type: migration.MigrationRunning, Status: api.ConditionFalse},
+				},
+			},
+			errs: 1,
+		},
+		{
+			name: "negative count",
+			status: migration.StorageVersionMigrationStatus{
+				MigratedObjects: -1,
+			},
+			errs: 1,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			svm := old.DeepCopy()
+			svm.Status = tc.status
+			if errs := ValidateStorageVersionMigrationStatusUpdate(svm, old); len(errs) != tc.errs {
+				t.Errorf("expected %d errors, got %v", tc.errs, errs)
+			}
+		})
+	}
+}
+
+func newMigration(name, group, resource string) *migration.StorageVersionMigration {
+	return &migration.StorageVersionMigration{
+		ObjectMeta: metav1.ObjectMeta{Name: name, ResourceVersion: "1"},
+		Spec: migration.StorageVersionMigrationSpec{
+			Resource: migration.GroupVersionResource{Group: group, Version: "v1", Resource: resource},
+		},
+	}
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transaction
+
+import (
+	metav1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// Transaction creates, updates and deletes a list of objects atomically.
+type Transaction struct {
+	metav1.TypeMeta
+	// +optional
+	metav1.ObjectMeta
+
+	// Spec holds the operations of the transaction.
+	Spec TransactionSpec
+
+	// Status holds the results of the committed operations.
+	// +optional
+	Status TransactionStatus
+}
+
+// TransactionSpec is the list of operations of a transaction.
+type TransactionSpec struct {
+	// Operations are the operations of the transaction.
+	Operations []Operation
+}
+
+// OperationType is the type of an operation of a transaction.
+type OperationType string
+
+const (
+	// OperationCreate creates the object of the operation.
+	OperationCreate OperationType = "Create"
+	// OperationUpdate replaces an existing object with the object of the
+	// operation.
+	OperationUpdate OperationType = "Update"
+	// OperationDelete deletes an existing object.
+	OperationDelete OperationType = "Delete"
+)
+
+// Operation is the creation, update or deletion of an object.
+type Operation struct {
+	// Type is the type of the operation.
+	Type OperationType
+	// Group is the API group of the resource of the object.
+	// +optional
+	Group string
+	// Version is the API version of the resource of the object.
+	Version string
+	// Resource is the resource of the object.
+	Resource string
+	// Namespace is the namespace of the object.
+	// +optional
+	Namespace string
+	// Name is the name of the object.
+	// +optional
+	Name string
+	// Object is the object to create, or to replace the existing object with,
+	// encoded in Version.
+	// +optional
+	Object runtime.RawExtension
+	// Preconditions must be fulfilled by the object to delete.
+	// +optional
+	Preconditions *metav1.Preconditions
+}
+
+// TransactionStatus holds the results of a committed transaction.
+type TransactionStatus struct {
+	// Results holds the result of each operation, in the order of the
+	// operations.
+	// +optional
+	Results []OperationResult
+}
+
+// OperationResult is the result of a committed operation.
+type OperationResult struct {
+	// Object is the object stored by a creation or update, or the deleted
+	// object of a deletion, encoded in the version of the operation.
+	Object runtime.RawExtension
+}
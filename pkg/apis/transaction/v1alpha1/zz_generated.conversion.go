@@ -0,0 +1,217 @@
+// +build !ignore_autogenerated
+
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by conversion-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	unsafe "unsafe"
+
+	v1alpha1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/transaction/v1alpha1"
+	metav1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1"
+	conversion "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/conversion"
+	runtime "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
+	transaction "github.com/aaron-prindle/krmapiserver/pkg/apis/transaction"
+)
+
+func init() {
+	localSchemeBuilder.Register(RegisterConversions)
+}
+
+// RegisterConversions adds conversion functions to the given scheme.
+// Public to allow building arbitrary schemes.
+func RegisterConversions(s *runtime.Scheme) error {
+	if err := s.AddGeneratedConversionFunc((*v1alpha1.Operation)(nil), (*transaction.Operation)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_Operation_To_transaction_Operation(a.(*v1alpha1.Operation), b.(*transaction.Operation), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*transaction.Operation)(nil), (*v1alpha1.Operation)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_transaction_Operation_To_v1alpha1_Operation(a.(*transaction.Operation), b.(*v1alpha1.Operation), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha1.OperationResult)(nil), (*transaction.OperationResult)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_OperationResult_To_transaction_OperationResult(a.(*v1alpha1.OperationResult), b.(*transaction.OperationResult), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*transaction.OperationResult)(nil), (*v1alpha1.OperationResult)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_transaction_OperationResult_To_v1alpha1_OperationResult(a.(*transaction.OperationResult), b.(*v1alpha1.OperationResult), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha1.Transaction)(nil), (*transaction.Transaction)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_Transaction_To_transaction_Transaction(a.(*v1alpha1.Transaction), b.(*transaction.Transaction), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*transaction.Transaction)(nil), (*v1alpha1.Transaction)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_transaction_Transaction_To_v1alpha1_Transaction(a.(*transaction.Transaction), b.(*v1alpha1.Transaction), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha1.TransactionSpec)(nil), (*transaction.TransactionSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_TransactionSpec_To_transaction_TransactionSpec(a.(*v1alpha1.TransactionSpec), b.(*transaction.TransactionSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*transaction.TransactionSpec)(nil), (*v1alpha1.TransactionSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_transaction_TransactionSpec_To_v1alpha1_TransactionSpec(a.(*transaction.TransactionSpec), b.(*v1alpha1.TransactionSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha1.TransactionStatus)(nil), (*transaction.TransactionStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_TransactionStatus_To_transaction_TransactionStatus(a.(*v1alpha1.TransactionStatus), b.(*transaction.TransactionStatus), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*transaction.TransactionStatus)(nil), (*v1alpha1.TransactionStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_transaction_TransactionStatus_To_v1alpha1_TransactionStatus(a.(*transaction.TransactionStatus), b.(*v1alpha1.TransactionStatus), scope)
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+func autoConvert_v1alpha1_Operation_To_transaction_Operation(in *v1alpha1.Operation, out *transaction.Operation, s conversion.Scope) error {
+	out.Type = transaction.OperationType(in.Type)
+	out.Group = in.Group
+	out.Version = in.Version
+	out.Resource = in.Resource
+	out.Namespace = in.Namespace
+	out.Name = in.Name
+	out.Object = in.Object
+	out.Preconditions = (*metav1.Preconditions)(unsafe.Pointer(in.Preconditions))
+	return nil
+}
+
+// Convert_v1alpha1_Operation_To_transaction_Operation is an autogenerated conversion function.
+func Convert_v1alpha1_Operation_To_transaction_Operation(in *v1alpha1.Operation, out *transaction.Operation, s conversion.Scope) error {
+	return autoConvert_v1alpha1_Operation_To_transaction_Operation(in, out, s)
+}
+
+func autoConvert_transaction_Operation_To_v1alpha1_Operation(in *transaction.Operation, out *v1alpha1.Operation, s conversion.Scope) error {
+	out.Type = v1alpha1.OperationType(in.Type)
+	out.Group = in.Group
+	out.Version = in.Version
+	out.Resource = in.Resource
+	out.Namespace = in.Namespace
+	out.Name = in.Name
+	out.Object = in.Object
+	out.Preconditions = (*metav1.Preconditions)(unsafe.Pointer(in.Preconditions))
+	return nil
+}
+
+// Convert_transaction_Operation_To_v1alpha1_Operation is an autogenerated conversion function.
+func Convert_transaction_Operation_To_v1alpha1_Operation(in *transaction.Operation, out *v1alpha1.Operation, s conversion.Scope) error {
+	return autoConvert_transaction_Operation_To_v1alpha1_Operation(in, out, s)
+}
+
+func autoConvert_v1alpha1_OperationResult_To_transaction_OperationResult(in *v1alpha1.OperationResult, out *transaction.OperationResult, s conversion.Scope) error {
+	out.Object = in.Object
+	return nil
+}
+
+// Convert_v1alpha1_OperationResult_To_transaction_OperationResult is an autogenerated conversion function.
+func Convert_v1alpha1_OperationResult_To_transaction_OperationResult(in *v1alpha1.OperationResult, out *transaction.OperationResult, s conversion.Scope) error {
+	return autoConvert_v1alpha1_OperationResult_To_transaction_OperationResult(in, out, s)
+}
+
+func autoConvert_transaction_OperationResult_To_v1alpha1_OperationResult(in *transaction.OperationResult, out *v1alpha1.OperationResult, s conversion.Scope) error {
+	out.Object = in.Object
+	return nil
+}
+
+// Convert_transaction_OperationResult_To_v1alpha1_OperationResult is an autogenerated conversion function.
+func Convert_transaction_OperationResult_To_v1alpha1_OperationResult(in *transaction.OperationResult, out *v1alpha1.OperationResult, s conversion.Scope) error {
+	return autoConvert_transaction_OperationResult_To_v1alpha1_OperationResult(in, out, s)
+}
+
+func autoConvert_v1alpha1_Transaction_To_transaction_Transaction(in *v1alpha1.Transaction, out *transaction.Transaction, s conversion.Scope) error {
+	out.ObjectMeta = in.ObjectMeta
+	if err := Convert_v1alpha1_TransactionSpec_To_transaction_TransactionSpec(&in.Spec, &out.Spec, s); err != nil {
+		return err
+	}
+	if err := Convert_v1alpha1_TransactionStatus_To_transaction_TransactionStatus(&in.Status, &out.Status, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1alpha1_Transaction_To_transaction_Transaction is an autogenerated conversion function.
+func Convert_v1alpha1_Transaction_To_transaction_Transaction(in *v1alpha1.Transaction, out *transaction.Transaction, s conversion.Scope) error {
+	return autoConvert_v1alpha1_Transaction_To_transaction_Transaction(in, out, s)
+}
+
+func autoConvert_transaction_Transaction_To_v1alpha1_Transaction(in *transaction.Transaction, out *v1alpha1.Transaction, s conversion.Scope) error {
+	out.ObjectMeta = in.ObjectMeta
+	if err := Convert_transaction_TransactionSpec_To_v1alpha1_TransactionSpec(&in.Spec, &out.Spec, s); err != nil {
+		return err
+	}
+	if err := Convert_transaction_TransactionStatus_To_v1alpha1_TransactionStatus(&in.Status, &out.Status, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_transaction_Transaction_To_v1alpha1_Transaction is an autogenerated conversion function.
+func Convert_transaction_Transaction_To_v1alpha1_Transaction(in *transaction.Transaction, out *v1alpha1.Transaction, s conversion.Scope) error {
+	return autoConvert_transaction_Transaction_To_v1alpha1_Transaction(in, out, s)
+}
+
+func autoConvert_v1alpha1_TransactionSpec_To_transaction_TransactionSpec(in *v1alpha1.TransactionSpec, out *transaction.TransactionSpec, s conversion.Scope) error {
+	out.Operations = *(*[]transaction.Operation)(unsafe.Pointer(&in.Operations))
+	return nil
+}
+
+// Convert_v1alpha1_TransactionSpec_To_transaction_TransactionSpec is an autogenerated conversion function.
+func Convert_v1alpha1_TransactionSpec_To_transaction_TransactionSpec(in *v1alpha1.TransactionSpec, out *transaction.TransactionSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha1_TransactionSpec_To_transaction_TransactionSpec(in, out, s)
+}
+
+func autoConvert_transaction_TransactionSpec_To_v1alpha1_TransactionSpec(in *transaction.TransactionSpec, out *v1alpha1.TransactionSpec, s conversion.Scope) error {
+	out.Operations = *(*[]v1alpha1.Operation)(unsafe.Pointer(&in.Operations))
+	return nil
+}
+
+// Convert_transaction_TransactionSpec_To_v1alpha1_TransactionSpec is an autogenerated conversion function.
+func Convert_transaction_TransactionSpec_To_v1alpha1_TransactionSpec(in *transaction.TransactionSpec, out *v1alpha1.TransactionSpec, s conversion.Scope) error {
+	return autoConvert_transaction_TransactionSpec_To_v1alpha1_TransactionSpec(in, out, s)
+}
+
+func autoConvert_v1alpha1_TransactionStatus_To_transaction_TransactionStatus(in *v1alpha1.TransactionStatus, out *transaction.TransactionStatus, s conversion.Scope) error {
+	out.Results = *(*[]transaction.OperationResult)(unsafe.Pointer(&in.Results))
+	return nil
+}
+
+// Convert_v1alpha1_TransactionStatus_To_transaction_TransactionStatus is an autogenerated conversion function.
+func Convert_v1alpha1_TransactionStatus_To_transaction_TransactionStatus(in *v1alpha1.TransactionStatus, out *transaction.TransactionStatus, s conversion.Scope) error {
+	return autoConvert_v1alpha1_TransactionStatus_To_transaction_TransactionStatus(in, out, s)
+}
+
+func autoConvert_transaction_TransactionStatus_To_v1alpha1_TransactionStatus(in *transaction.TransactionStatus, out *v1alpha1.TransactionStatus, s conversion.Scope) error {
+	out.Results = *(*[]v1alpha1.OperationResult)(unsafe.Pointer(&in.Results))
+	return nil
+}
+
+// Convert_transaction_TransactionStatus_To_v1alpha1_TransactionStatus is an autogenerated conversion function.
+func Convert_transaction_TransactionStatus_To_v1alpha1_TransactionStatus(in *transaction.TransactionStatus, out *v1alpha1.TransactionStatus, s conversion.Scope) error {
+	return autoConvert_transaction_TransactionStatus_To_v1alpha1_TransactionStatus(in, out, s)
+}
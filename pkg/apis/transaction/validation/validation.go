@@ -0,0 +1,104 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"fmt"
+
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/util/sets"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/util/validation/field"
+	"github.com/aaron-prindle/krmapiserver/pkg/apis/transaction"
+)
+
+// maxOperations is the default maximum number of operations in an etcd
+// transaction.
+const maxOperations = 128
+
+var supportedOperationTypes = sets.NewString(
+	string(transaction.OperationCreate),
+	string(transaction.OperationUpdate),
+	string(transaction.OperationDelete),
+)
+
+// ValidateTransaction validates a Transaction.
+func ValidateTransaction(txn *transaction.Transaction) field.ErrorList {
+	return ValidateTransactionSpec(&txn.Spec, field.NewPath("spec"))
+}
+
+// ValidateTransactionSpec validates spec of Transaction.
+func ValidateTransactionSpec(spec *transaction.TransactionSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	opsPath := fldPath.Child("operations")
+	if len(spec.Operations) == 0 {
+		allErrs = append(allErrs, field.Required(opsPath, "at least one operation is required"))
+	}
+	if len(spec.Operations) > maxOperations {
+		allErrs = append(allErrs, field.Invalid(opsPath, len(spec.Operations), fmt.Sprintf("must have at most %d operations", maxOperations)))
+	}
+	targets := sets.NewString()
+	for i := range spec.Operations {
+		op := &spec.Operations[i]
+		idxPath := opsPath.Index(i)
+		allErrs = append(allErrs, ValidateOperation(op, idxPath)...)
+		if len(op.Name) == 0 {
+			continue
+		}
+		target := fmt.Sprintf("%s/%s/%s/%s", op.Group, op.Resource, op.Namespace, op.Name)
+		if targets.Has(target) {
+			allErrs = append(allErrs, field.Duplicate(idxPath, target))
+		}
+		targets.Insert(target)
+	}
+	return allErrs
+}
+
+// ValidateOperation validates an operation of a Transaction.
+func ValidateOperation(op *transaction.Operation, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if !supportedOperationTypes.Has(string(op.Type)) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("type"), op.Type, supportedOperationTypes.List()))
+	}
+	if len(op.Version) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("version"), ""))
+	}
+	if len(op.Resource) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("resource"), ""))
+	}
+
+	switch op.Type {
+	case transaction.OperationCreate, transaction.OperationUpdate:
+		if op.Type == transaction.OperationUpdate && len(op.Name) == 0 {
+			allErrs = append(allErrs, field.Required(fldPath.Child("name"), "required for updates"))
+		}
+		if len(op.Object.Raw) == 0 {
+			allErrs = append(allErrs, field.Required(fldPath.Child("object"), fmt.Sprintf("required for %s operations", op.Type)))
+		}
+		if op.Preconditions != nil {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("preconditions"), "only allowed for deletions"))
+		}
+	case transaction.OperationDelete:
+		if len(op.Name) == 0 {
+			allErrs = append(allErrs, field.Required(fldPath.Child("name"), "required for deletions"))
+		}
+		if len(op.Object.Raw) != 0 {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("object"), "not allowed for deletions"))
+		}
+	}
+	return allErrs
+}
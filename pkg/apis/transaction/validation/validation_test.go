@@ -0,0 +1,101 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"testing"
+
+	metav1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/types"
+	"github.com/aaron-prindle/krmapiserver/pkg/apis/transaction"
+)
+
+func TestValidateTransaction(t *testing.T) {
+	object := runtime.RawExtension{Raw: []byte(`{"kind":"ConfigMap","apiVersion":"v1"}`)}
+	uid := "uid"
+	tests := []struct {
+		name       string
+		operations []transaction.Operation
+		errs       int
+	}{
+		{
+			name: "valid",
+			operations: []transaction.Operation{
+				{Type: transaction.OperationCreate, Version: "v1", Resource: "configmaps", Namespace: "ns", Object: object},
+				{Type: transaction.OperationUpdate, Group: "example.com", Version: "v1", Resource: "foos", Namespace: "ns", Name: "foo", Object: object},
+				{Type: transaction.OperationDelete, Version: "v1", Resource: "secrets", Namespace: "ns", Name: "bar", Preconditions: &metav1.Preconditions{UID: (*types.UID)(&uid)}},
+			},
+		},
+		{
+			name: "no operations",
+			errs: 1,
+		},
+		{
+			name: "unknown type",
+			operations: []transaction.Operation{
+				{Type: "Patch", Version: "v1", Resource: "configmaps", Name: "foo"},
+			},
+			errs: 1,
+		},
+		{
+			name: "missing resource and version",
+			operations: []transaction.Operation{
+				{Type: transaction.OperationCreate, Object: object},
+			},
+			errs: 2,
+		},
+		{
+			name: "update without name and object",
+			operations: []transaction.Operation{
+				{Type: transaction.OperationUpdate, Version: "v1", Resource: "configmaps"},
+			},
+			errs: 2,
+		},
+		{
+			name: "delete with object",
+			operations: []transaction.Operation{
+				{Type: transaction.OperationDelete, Version: "v1", Resource: "configmaps", Name: "foo", Object: object},
+			},
+			errs: 1,
+		},
+		{
+			name: "create with preconditions",
+			operations: []transaction.Operation{
+				{Type: transaction.OperationCreate, Version: "v1", Resource: "configmaps", Object: object, Preconditions: &metav1.Preconditions{}},
+			},
+			errs: 1,
+		},
+		{
+			name: "duplicate target",
+			operations: []transaction.Operation{
+				{Type: transaction.OperationUpdate, Version: "v1", Resource: "configmaps", Namespace: "ns", Name: "foo", Object: object},
+				{Type: transaction.OperationDelete, Version: "v1beta1", Resource: "configmaps", Namespace: "ns", Name: "foo"},
+			},
+			errs: 1,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			txn := &transaction.Transaction{Spec: transaction.TransactionSpec{Operations: test.operations}}
+			errs := ValidateTransaction(txn)
+			if len(errs) != test.errs {
+				t.Errorf("expected %d errors, got: %v", test.errs, errs.ToAggregate())
+			}
+		})
+	}
+}
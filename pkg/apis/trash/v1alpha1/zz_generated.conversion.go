@@ -0,0 +1,187 @@
+// +build !ignore_autogenerated
+
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by conversion-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	unsafe "unsafe"
+
+	v1alpha1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/trash/v1alpha1"
+	conversion "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/conversion"
+	runtime "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
+	types "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/types"
+	trash "github.com/aaron-prindle/krmapiserver/pkg/apis/trash"
+)
+
+func init() {
+	localSchemeBuilder.Register(RegisterConversions)
+}
+
+// RegisterConversions adds conversion functions to the given scheme.
+// Public to allow building arbitrary schemes.
+func RegisterConversions(s *runtime.Scheme) error {
+	if err := s.AddGeneratedConversionFunc((*v1alpha1.DeletedObject)(nil), (*trash.DeletedObject)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_DeletedObject_To_trash_DeletedObject(a.(*v1alpha1.DeletedObject), b.(*trash.DeletedObject), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*trash.DeletedObject)(nil), (*v1alpha1.DeletedObject)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_trash_DeletedObject_To_v1alpha1_DeletedObject(a.(*trash.DeletedObject), b.(*v1alpha1.DeletedObject), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha1.DeletedObjectList)(nil), (*trash.DeletedObjectList)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_DeletedObjectList_To_trash_DeletedObjectList(a.(*v1alpha1.DeletedObjectList), b.(*trash.DeletedObjectList), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*trash.DeletedObjectList)(nil), (*v1alpha1.DeletedObjectList)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_trash_DeletedObjectList_To_v1alpha1_DeletedObjectList(a.(*trash.DeletedObjectList), b.(*v1alpha1.DeletedObjectList), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha1.GroupResource)(nil), (*trash.GroupResource)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_GroupResource_To_trash_GroupResource(a.(*v1alpha1.GroupResource), b.(*trash.GroupResource), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*trash.GroupResource)(nil), (*v1alpha1.GroupResource)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_trash_GroupResource_To_v1alpha1_GroupResource(a.(*trash.GroupResource), b.(*v1alpha1.GroupResource), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha1.ObjectReference)(nil), (*trash.ObjectReference)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_ObjectReference_To_trash_ObjectReference(a.(*v1alpha1.ObjectReference), b.(*trash.ObjectReference), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*trash.ObjectReference)(nil), (*v1alpha1.ObjectReference)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_trash_ObjectReference_To_v1alpha1_ObjectReference(a.(*trash.ObjectReference), b.(*v1alpha1.ObjectReference), scope)
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+func autoConvert_v1alpha1_DeletedObject_To_trash_DeletedObject(in *v1alpha1.DeletedObject, out *trash.DeletedObject, s conversion.Scope) error {
+	out.ObjectMeta = in.ObjectMeta
+	if err := Convert_v1alpha1_GroupResource_To_trash_GroupResource(&in.Resource, &out.Resource, s); err != nil {
+		return err
+	}
+	if err := Convert_v1alpha1_ObjectReference_To_trash_ObjectReference(&in.Object, &out.Object, s); err != nil {
+		return err
+	}
+	out.DeletedBy = in.DeletedBy
+	out.DeletedAt = in.DeletedAt
+	out.PurgeAt = in.PurgeAt
+	return nil
+}
+
+// Convert_v1alpha1_DeletedObject_To_trash_DeletedObject is an autogenerated conversion function.
+func Convert_v1alpha1_DeletedObject_To_trash_DeletedObject(in *v1alpha1.DeletedObject, out *trash.DeletedObject, s conversion.Scope) error {
+	return autoConvert_v1alpha1_DeletedObject_To_trash_DeletedObject(in, out, s)
+}
+
+func autoConvert_trash_DeletedObject_To_v1alpha1_DeletedObject(in *trash.DeletedObject, out *v1alpha1.DeletedObject, s conversion.Scope) error {
+	out.ObjectMeta = in.ObjectMeta
+	if err := Convert_trash_GroupResource_To_v1alpha1_GroupResource(&in.Resource, &out.Resource, s); err != nil {
+		return err
+	}
+	if err := Convert_trash_ObjectReference_To_v1alpha1_ObjectReference(&in.Object, &out.Object, s); err != nil {
+		return err
+	}
+	out.DeletedBy = in.DeletedBy
+	out.DeletedAt = in.DeletedAt
+	out.PurgeAt = in.PurgeAt
+	return nil
+}
+
+// Convert_trash_DeletedObject_To_v1alpha1_DeletedObject is an autogenerated conversion function.
+func Convert_trash_DeletedObject_To_v1alpha1_DeletedObject(in *trash.DeletedObject, out *v1alpha1.DeletedObject, s conversion.Scope) error {
+	return autoConvert_trash_DeletedObject_To_v1alpha1_DeletedObject(in, out, s)
+}
+
+func autoConvert_v1alpha1_DeletedObjectList_To_trash_DeletedObjectList(in *v1alpha1.DeletedObjectList, out *trash.DeletedObjectList, s conversion.Scope) error {
+	out.ListMeta = in.ListMeta
+	out.Items = *(*[]trash.DeletedObject)(unsafe.Pointer(&in.Items))
+	return nil
+}
+
+// Convert_v1alpha1_DeletedObjectList_To_trash_DeletedObjectList is an autogenerated conversion function.
+func Convert_v1alpha1_DeletedObjectList_To_trash_DeletedObjectList(in *v1alpha1.DeletedObjectList, out *trash.DeletedObjectList, s conversion.Scope) error {
+	return autoConvert_v1alpha1_DeletedObjectList_To_trash_DeletedObjectList(in, out, s)
+}
+
+func autoConvert_trash_DeletedObjectList_To_v1alpha1_DeletedObjectList(in *trash.DeletedObjectList, out *v1alpha1.DeletedObjectList, s conversion.Scope) error {
+	out.ListMeta = in.ListMeta
+	out.Items = *(*[]v1alpha1.DeletedObject)(unsafe.Pointer(&in.Items))
+	return nil
+}
+
+// Convert_trash_DeletedObjectList_To_v1alpha1_DeletedObjectList is an autogenerated conversion function.
+func Convert_trash_DeletedObjectList_To_v1alpha1_DeletedObjectList(in *trash.DeletedObjectList, out *v1alpha1.DeletedObjectList, s conversion.Scope) error {
+	return autoConvert_trash_DeletedObjectList_To_v1alpha1_DeletedObjectList(in, out, s)
+}
+
+func autoConvert_v1alpha1_GroupResource_To_trash_GroupResource(in *v1alpha1.GroupResource, out *trash.GroupResource, s conversion.Scope) error {
+	out.Group = in.Group
+	out.Resource = in.Resource
+	return nil
+}
+
+// Convert_v1alpha1_GroupResource_To_trash_GroupResource is an autogenerated conversion function.
+func Convert_v1alpha1_GroupResource_To_trash_GroupResource(in *v1alpha1.GroupResource, out *trash.GroupResource, s conversion.Scope) error {
+	return autoConvert_v1alpha1_GroupResource_To_trash_GroupResource(in, out, s)
+}
+
+func autoConvert_trash_GroupResource_To_v1alpha1_GroupResource(in *trash.GroupResource, out *v1alpha1.GroupResource, s conversion.Scope) error {
+	out.Group = in.Group
+	out.Resource = in.Resource
+	return nil
+}
+
+// Convert_trash_GroupResource_To_v1alpha1_GroupResource is an autogenerated conversion function.
+func Convert_trash_GroupResource_To_v1alpha1_GroupResource(in *trash.GroupResource, out *v1alpha1.GroupResource, s conversion.Scope) error {
+	return autoConvert_trash_GroupResource_To_v1alpha1_GroupResource(in, out, s)
+}
+
+func autoConvert_v1alpha1_ObjectReference_To_trash_ObjectReference(in *v1alpha1.ObjectReference, out *trash.ObjectReference, s conversion.Scope) error {
+	out.Namespace = in.Namespace
+	out.Name = in.Name
+	out.UID = types.UID(in.UID)
+	return nil
+}
+
+// Convert_v1alpha1_ObjectReference_To_trash_ObjectReference is an autogenerated conversion function.
+func Convert_v1alpha1_ObjectReference_To_trash_ObjectReference(in *v1alpha1.ObjectReference, out *trash.ObjectReference, s conversion.Scope) error {
+	return autoConvert_v1alpha1_ObjectReference_To_trash_ObjectReference(in, out, s)
+}
+
+func autoConvert_trash_ObjectReference_To_v1alpha1_ObjectReference(in *trash.ObjectReference, out *v1alpha1.ObjectReference, s conversion.Scope) error {
+	out.Namespace = in.Namespace
+	out.Name = in.Name
+	out.UID = types.UID(in.UID)
+	return nil
+}
+
+// Convert_trash_ObjectReference_To_v1alpha1_ObjectReference is an autogenerated conversion function.
+func Convert_trash_ObjectReference_To_v1alpha1_ObjectReference(in *trash.ObjectReference, out *v1alpha1.ObjectReference, s conversion.Scope) error {
+	return autoConvert_trash_ObjectReference_To_v1alpha1_ObjectReference(in, out, s)
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package usage
+
+import (
+	metav1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// StorageUsage is the number and size of the stored objects of a resource in
+// a namespace. It is named after the resource, e.g. "configmaps" or
+// "deployments.apps", and computed by the server from the accounting of the
+// writes to storage, so it is read-only.
+type StorageUsage struct {
+	metav1.TypeMeta
+	// +optional
+	metav1.ObjectMeta
+
+	// Resource is the resource whose objects are accounted.
+	Resource GroupResource
+	// Objects is the number of objects of the resource in the namespace.
+	Objects int64
+	// Bytes is the total size of the objects of the resource in the
+	// namespace, as they are stored, that is after they are encoded and
+	// transformed, e.g. encrypted or compressed.
+	Bytes int64
+}
+
+// GroupResource identifies a resource.
+type GroupResource struct {
+	// Group is the API group of the resource. The empty string is the core
+	// group.
+	// +optional
+	Group string
+	// Resource is the name of the resource, e.g. "configmaps".
+	Resource string
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// StorageUsageList is a list of StorageUsage objects.
+type StorageUsageList struct {
+	metav1.TypeMeta
+	// Standard list metadata.
+	// +optional
+	metav1.ListMeta
+
+	// Items is the list of StorageUsage objects.
+	Items []StorageUsage
+}
@@ -0,0 +1,257 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storageversionmigrator
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	migrationv1alpha1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/migration/v1alpha1"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime/schema"
+	utilruntime "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/util/runtime"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/util/wait"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/features"
+	utilfeature "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/util/feature"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/client-go/discovery"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/client-go/dynamic"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/client-go/dynamic/dynamicinformer"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/client-go/tools/cache"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/client-go/tools/leaderelection"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/client-go/tools/leaderelection/resourcelock"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/client-go/util/flowcontrol"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/client-go/util/workqueue"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/klog"
+	"github.com/aaron-prindle/krmapiserver/pkg/controller"
+)
+
+const (
+	// defaultPageSize is the number of objects listed from storage at once.
+	// The progress of a migration is recorded after every page.
+	defaultPageSize = 500
+	// defaultQPS and defaultBurst limit the rate at which objects are
+	// rewritten, across all migrations.
+	defaultQPS   = 100
+	defaultBurst = 200
+	// discoveryPeriod is how often the storage version hashes published in
+	// discovery are checked for changes.
+	discoveryPeriod = 10 * time.Minute
+	// maxRetries is how often a migration is retried before it fails.
+	maxRetries = 5
+
+	// The migrator runs on the apiserver holding its lease, so that objects
+	// are not migrated by several servers at once.
+	leaseDuration = 60 * time.Second
+	renewDeadline = 40 * time.Second
+	retryPeriod   = 10 * time.Second
+)
+
+var migrationsResource = migrationv1alpha1.SchemeGroupVersion.WithResource("storageversionmigrations")
+
+// Controller creates a StorageVersionMigration whenever the storage version
+// hash of a resource published in discovery changes, and migrates the
+// objects of the resources of all unfinished StorageVersionMigrations. An
+// object is migrated with a no-op update, which the storage writes encoded
+// in the current storage version of the resource.
+type Controller struct {
+	client    dynamic.ResourceInterface
+	discovery discovery.DiscoveryInterface
+	// lock, if not nil, is held while migrating.
+	lock resourcelock.Interface
+
+	informer cache.SharedIndexInformer
+	lister   cache.GenericLister
+	synced   cache.InformerSynced
+
+	queue    workqueue.RateLimitingInterface
+	limiter  flowcontrol.RateLimiter
+	pageSize int64
+
+	// hashes are the storage version hashes of the served resources, as
+	// last seen in discovery.
+	hashesLock sync.RWMutex
+	hashes     map[schema.GroupResource]string
+}
+
+// NewController returns a controller that manages StorageVersionMigrations
+// with client and reads the storage version hashes from discoveryClient. It
+// only migrates while it holds lock, if not nil.
+func NewController(client dynamic.Interface, discoveryClient discovery.DiscoveryInterface, lock resourcelock.Interface) *Controller {
+	informer := dynamicinformer.NewFilteredDynamicInformer(client, migrationsResource, "", 0, cache.Indexers{}, nil)
+	c := &Controller{
+		client:    client.Resource(migrationsResource),
+		discovery: discoveryClient,
+		lock:      lock,
+		informer:  informer.Informer(),
+		lister:    informer.Lister(),
+		synced:    informer.Informer().HasSynced,
+		queue:     workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "storage_version_migrator"),
+		limiter:   flowcontrol.NewTokenBucketRateLimiter(defaultQPS, defaultBurst),
+		pageSize:  defaultPageSize,
+		hashes:    map[schema.GroupResource]string{},
+	}
+	c.informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: c.enqueue,
+		UpdateFunc: func(_, obj interface{}) {
+			c.enqueue(obj)
+		},
+	})
+	return c
+}
+
+// Run migrates the objects of unfinished StorageVersionMigrations one at a
+// time, and creates StorageVersionMigrations for resources whose storage
+// version changed, while it holds its lock, until stopCh is closed.
+func (c *Controller) Run(stopCh <-chan struct{}) {
+	defer utilruntime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	klog.Infof("Starting storage version migrator")
+	defer klog.Infof("Shutting down storage version migrator")
+
+	go c.informer.Run(stopCh)
+	if !controller.WaitForCacheSync("storage version migrator", stopCh, c.synced) {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-stopCh
+		cancel()
+	}()
+
+	if c.lock == nil {
+		c.run(ctx)
+		return
+	}
+	// The lease is acquired again after it was lost, until stopCh is closed.
+	wait.Until(func() {
+		leaderelection.RunOrDie(ctx, leaderelection.LeaderElectionConfig{
+			Lock:            c.lock,
+			LeaseDuration:   leaseDuration,
+			RenewDeadline:   renewDeadline,
+			RetryPeriod:     retryPeriod,
+			ReleaseOnCancel: true,
+			Name:            "storage-version-migrator",
+			Callbacks: leaderelection.LeaderCallbacks{
+				OnStartedLeading: c.run,
+				OnStoppedLeading: func() {
+					klog.Infof("Storage version migrator %s does not hold the lease", c.lock.Identity())
+				},
+			},
+		})
+	}, time.Second, stopCh)
+}
+
+// run migrates and triggers migrations until ctx is done.
+func (c *Controller) run(ctx context.Context) {
+	klog.V(2).Infof("Running storage version migrations")
+	go wait.Until(func() {
+		for c.processNextItem(ctx) {
+		}
+	}, time.Second, ctx.Done())
+
+	if !utilfeature.DefaultFeatureGate.Enabled(features.StorageVersionHash) {
+		klog.Infof("Storage version hashes are not published in discovery, only storage version migrations created by users are run")
+		<-ctx.Done()
+		return
+	}
+	wait.Until(c.trigger, discoveryPeriod, ctx.Done())
+}
+
+func (c *Controller) enqueue(obj interface{}) {
+	svm, err := fromUnstructured(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	if !finished(svm) {
+		c.queue.Add(svm.Name)
+	}
+}
+
+func (c *Controller) processNextItem(ctx context.Context) bool {
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(key)
+
+	name := key.(string)
+	if ctx.Err() != nil {
+		// The lease was lost, the migration is run once it is acquired again.
+		c.queue.Add(key)
+		return false
+	}
+	err := c.migrate(ctx, name)
+	if err == nil {
+		c.queue.Forget(key)
+		return true
+	}
+	if ctx.Err() != nil {
+		c.queue.Add(key)
+		return false
+	}
+	if c.queue.NumRequeues(key) < maxRetries {
+		klog.Warningf("Failed to migrate the storage version migration %s, retrying: %v", name, err)
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	klog.Errorf("Failed to migrate the storage version migration %s, giving up: %v", name, err)
+	c.queue.Forget(key)
+	if err := c.fail(name, "MigrationFailed", err.Error()); err != nil {
+		utilruntime.HandleError(err)
+	}
+	return true
+}
+
+func (c *Controller) setHashes(hashes map[schema.GroupResource]string) {
+	c.hashesLock.Lock()
+	defer c.hashesLock.Unlock()
+	c.hashes = hashes
+}
+
+func (c *Controller) hash(gr schema.GroupResource) string {
+	c.hashesLock.RLock()
+	defer c.hashesLock.RUnlock()
+	return c.hashes[gr]
+}
+
+func fromUnstructured(obj interface{}) (*migrationv1alpha1.StorageVersionMigration, error) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, fmt.Errorf("expected an unstructured storage version migration, got %T", obj)
+	}
+	svm := &migrationv1alpha1.StorageVersionMigration{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), svm); err != nil {
+		return nil, err
+	}
+	return svm, nil
+}
+
+func toUnstructured(svm *migrationv1alpha1.StorageVersionMigration) (*unstructured.Unstructured, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(svm)
+	if err != nil {
+		return nil, err
+	}
+	u := &unstructured.Unstructured{Object: content}
+	u.SetGroupVersionKind(migrationv1alpha1.SchemeGroupVersion.WithKind("StorageVersionMigration"))
+	return u, nil
+}
@@ -0,0 +1,358 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storageversionmigrator
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/core/v1"
+	migrationv1alpha1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/migration/v1alpha1"
+	apierrors "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/api/errors"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/api/meta"
+	metav1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/fields"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/labels"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime/schema"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/util/validation"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/embedded"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/rewrite"
+	storagetesting "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/testing"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/value"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/client-go/dynamic"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/client-go/util/flowcontrol"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/client-go/util/workqueue"
+)
+
+// fakeMigrations keeps StorageVersionMigrations in memory. Only the methods
+// used by the controller are implemented.
+type fakeMigrations struct {
+	dynamic.ResourceInterface
+	objects       map[string]*unstructured.Unstructured
+	statusUpdates int
+	// conflict, if not nil, changes the stored migration before the status
+	// update number conflictAt, which then fails with a conflict.
+	conflict   func(svm *migrationv1alpha1.StorageVersionMigration)
+	conflictAt int
+}
+
+func (f *fakeMigrations) Get(name string, options metav1.GetOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	obj, ok := f.objects[name]
+	if !ok {
+		return nil, apierrors.NewNotFound(migrationsResource.GroupResource(), name)
+	}
+	return obj.DeepCopy(), nil
+}
+
+func (f *fakeMigrations) Create(obj *unstructured.Unstructured, options metav1.CreateOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	if _, ok := f.objects[obj.GetName()]; ok {
+		return nil, apierrors.NewAlreadyExists(migrationsResource.GroupResource(), obj.GetName())
+	}
+	f.objects[obj.GetName()] = obj.DeepCopy()
+	return obj, nil
+}
+
+func (f *fakeMigrations) UpdateStatus(obj *unstructured.Unstructured, options metav1.UpdateOptions) (*unstructured.Unstructured, error) {
+	if conflict := f.conflict; conflict != nil && f.statusUpdates+1 == f.conflictAt {
+		f.conflict = nil
+		svm, err := fromUnstructured(f.objects[obj.GetName()])
+		if err != nil {
+			return nil, err
+		}
+		conflict(svm)
+		if f.objects[obj.GetName()], err = toUnstructured(svm); err != nil {
+			return nil, err
+		}
+		return nil, apierrors.NewConflict(migrationsResource.GroupResource(), obj.GetName(), fmt.Errorf("changed"))
+	}
+	f.statusUpdates++
+	f.objects[obj.GetName()] = obj.DeepCopy()
+	return obj, nil
+}
+
+func (f *fakeMigrations) add(t *testing.T, svm *migrationv1alpha1.StorageVersionMigration) {
+	u, err := toUnstructured(svm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.objects[svm.Name] = u
+}
+
+func (f *fakeMigrations) get(t *testing.T, name string) *migrationv1alpha1.StorageVersionMigration {
+	svm, err := fromUnstructured(f.objects[name])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return svm
+}
+
+func newTestController(client *fakeMigrations) *Controller {
+	return &Controller{
+		client:   client,
+		queue:    workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+		limiter:  flowcontrol.NewFakeAlwaysRateLimiter(),
+		pageSize: 2,
+		hashes:   map[schema.GroupResource]string{{Resource: "pods"}: "new"},
+	}
+}
+
+func prefixTransformer(prefixes ...string) value.Transformer {
+	var transformers []value.PrefixTransformer
+	for _, prefix := range prefixes {
+		transformers = append(transformers, value.PrefixTransformer{Prefix: []byte(prefix), Transformer: value.IdentityTransformer})
+	}
+	return value.NewPrefixTransformers(nil, transformers...)
+}
+
+// newPods stores count pods with the "v1:" prefix, and registers them for
+// rewriting. The storage writes objects with the "v2:" prefix after the
+// returned transformer is switched, which stands in for a new storage
+// version.
+func newPods(t *testing.T, count int) (*rewrite.Resource, *value.MutableTransformer, func()) {
+	b := embedded.NewMemory()
+	transformer := value.NewMutableTransformer(prefixTransformer("v1:"))
+	s := embedded.New(b, storagetesting.Codec, "", transformer, true)
+	resource := &rewrite.Resource{
+		GroupResource:  schema.GroupResource{Resource: "pods"},
+		Storage:        s,
+		ResourcePrefix: "/pods",
+		KeyFunc: func(obj runtime.Object) (string, error) {
+			accessor, err := meta.Accessor(obj)
+			if err != nil {
+				return "", err
+			}
+			return path.Join("/pods", accessor.GetName()), nil
+		},
+		NewFunc:     func() runtime.Object { return &corev1.Pod{} },
+		NewListFunc: func() runtime.Object { return &corev1.PodList{} },
+	}
+	rewrite.Register(resource)
+	for i := 0; i < count; i++ {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("pod-%d", i), UID: "uid"}}
+		if err := s.Create(context.Background(), path.Join("/pods", pod.Name), pod, nil, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return resource, transformer, func() {
+		rewrite.Unregister(resource)
+		b.Close()
+	}
+}
+
+func newMigration(name string, gr schema.GroupResource) *migrationv1alpha1.StorageVersionMigration {
+	return &migrationv1alpha1.StorageVersionMigration{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: migrationv1alpha1.StorageVersionMigrationSpec{
+			Resource: migrationv1alpha1.GroupVersionResource{Group: gr.Group, Resource: gr.Resource},
+		},
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	const count = 5
+	resource, transformer, cleanup := newPods(t, count)
+	defer cleanup()
+	transformer.Set(prefixTransformer("v2:", "v1:"))
+
+	client := &fakeMigrations{objects: map[string]*unstructured.Unstructured{}}
+	client.add(t, newMigration("pods-1", resource.GroupResource))
+	c := newTestController(client)
+	if err := c.migrate(context.Background(), "pods-1"); err != nil {
+		t.Fatal(err)
+	}
+
+	svm := client.get(t, "pods-1")
+	if !isTrue(svm, migrationv1alpha1.MigrationSucceeded) || isTrue(svm, migrationv1alpha1.MigrationRunning) {
+		t.Errorf("expected the migration to succeed, got %#v", svm.Status.Conditions)
+	}
+	if svm.Status.MigratedObjects != count || svm.Status.StorageVersionHash != "new" || len(svm.Status.ContinueToken) != 0 {
+		t.Errorf("unexpected status %#v", svm.Status)
+	}
+	// One update to start, one per page and one to finish.
+	if client.statusUpdates != 5 {
+		t.Errorf("expected 5 status updates, got %d", client.statusUpdates)
+	}
+
+	transformer.Set(prefixTransformer("v2:"))
+	for i := 0; i < count; i++ {
+		if err := resource.Storage.Get(context.Background(), path.Join("/pods", fmt.Sprintf("pod-%d", i)), "", &corev1.Pod{}, false); err != nil {
+			t.Errorf("object was not migrated: %v", err)
+		}
+	}
+
+	// Finished migrations are not migrated again.
+	if err := c.migrate(context.Background(), "pods-1"); err != nil || client.statusUpdates != 5 {
+		t.Errorf("expected a finished migration to be skipped, got %d status updates, %v", client.statusUpdates, err)
+	}
+}
+
+// TestMigrateConflict checks that the progress recorded after a page is not
+// added to the progress recorded by a concurrent update of the migration when
+// it is retried.
+func TestMigrateConflict(t *testing.T) {
+	const count = 5
+	resource, _, cleanup := newPods(t, count)
+	defer cleanup()
+
+	client := &fakeMigrations{objects: map[string]*unstructured.Unstructured{}}
+	client.add(t, newMigration("pods-1", resource.GroupResource))
+	// The progress of the first page is recorded concurrently.
+	client.conflictAt = 2
+	client.conflict = func(svm *migrationv1alpha1.StorageVersionMigration) {
+		svm.Status.MigratedObjects += 2
+	}
+	c := newTestController(client)
+	if err := c.migrate(context.Background(), "pods-1"); err != nil {
+		t.Fatal(err)
+	}
+	if svm := client.get(t, "pods-1"); !isTrue(svm, migrationv1alpha1.MigrationSucceeded) || svm.Status.MigratedObjects != count {
+		t.Errorf("expected %d migrated objects, got status %#v", count, svm.Status)
+	}
+}
+
+func TestMigrateResume(t *testing.T) {
+	const count = 5
+	resource, transformer, cleanup := newPods(t, count)
+	defer cleanup()
+
+	// The first page was migrated before the migration was interrupted.
+	list := &corev1.PodList{}
+	pred := storage.SelectionPredicate{Label: labels.Everything(), Field: fields.Everything(), Limit: 2}
+	if err := resource.Storage.List(context.Background(), resource.ResourcePrefix, "", pred, list); err != nil {
+		t.Fatal(err)
+	}
+	transformer.Set(prefixTransformer("v2:", "v1:"))
+
+	svm := newMigration("pods-1", resource.GroupResource)
+	setCondition(&svm.Status, migrationv1alpha1.MigrationRunning, corev1.ConditionTrue, "", "")
+	svm.Status.StorageVersionHash = "new"
+	svm.Status.ContinueToken = list.Continue
+	svm.Status.MigratedObjects = 2
+	client := &fakeMigrations{objects: map[string]*unstructured.Unstructured{}}
+	client.add(t, svm)
+
+	c := newTestController(client)
+	if err := c.migrate(context.Background(), "pods-1"); err != nil {
+		t.Fatal(err)
+	}
+	svm = client.get(t, "pods-1")
+	if !isTrue(svm, migrationv1alpha1.MigrationSucceeded) || svm.Status.MigratedObjects != count {
+		t.Errorf("unexpected status %#v", svm.Status)
+	}
+
+	// Only the objects after the continue token were migrated.
+	transformer.Set(prefixTransformer("v2:"))
+	for i := 0; i < count; i++ {
+		err := resource.Storage.Get(context.Background(), path.Join("/pods", fmt.Sprintf("pod-%d", i)), "", &corev1.Pod{}, false)
+		if migrated := err == nil; migrated != (i >= 2) {
+			t.Errorf("pod-%d: expected migrated=%t, got %v", i, i >= 2, err)
+		}
+	}
+}
+
+func TestMigrateUnknownResource(t *testing.T) {
+	client := &fakeMigrations{objects: map[string]*unstructured.Unstructured{}}
+	client.add(t, newMigration("unknown-1", schema.GroupResource{Group: "example.com", Resource: "unknown"}))
+	c := newTestController(client)
+	if err := c.migrate(context.Background(), "unknown-1"); err != nil {
+		t.Fatal(err)
+	}
+	if svm := client.get(t, "unknown-1"); !isTrue(svm, migrationv1alpha1.MigrationFailed) {
+		t.Errorf("expected the migration of a resource that is not stored to fail, got %#v", svm.Status.Conditions)
+	}
+}
+
+func TestNeedsMigration(t *testing.T) {
+	gr := schema.GroupResource{Group: "apps", Resource: "deployments"}
+	created := time.Now()
+	succeeded := func(hash string) *migrationv1alpha1.StorageVersionMigration {
+		svm := newMigration("deployments.apps-"+hash, gr)
+		created = created.Add(time.Minute)
+		svm.CreationTimestamp = metav1.NewTime(created)
+		svm.Status.StorageVersionHash = hash
+		setCondition(&svm.Status, migrationv1alpha1.MigrationSucceeded, corev1.ConditionTrue, "", "")
+		return svm
+	}
+	running := newMigration("deployments.apps-running", gr)
+	other := newMigration("replicasets.apps-1", schema.GroupResource{Group: "apps", Resource: "replicasets"})
+
+	tests := []struct {
+		name       string
+		migrations []*migrationv1alpha1.StorageVersionMigration
+		expected   bool
+	}{
+		{name: "never migrated", migrations: []*migrationv1alpha1.StorageVersionMigration{other}, expected: true},
+		{name: "migrated to the hash", migrations: []*migrationv1alpha1.StorageVersionMigration{succeeded("old"), succeeded("new")}},
+		{name: "hash changed", migrations: []*migrationv1alpha1.StorageVersionMigration{succeeded("old")}, expected: true},
+		{name: "hash changed back", migrations: []*migrationv1alpha1.StorageVersionMigration{succeeded("new"), succeeded("old")}, expected: true},
+		{name: "migration running", migrations: []*migrationv1alpha1.StorageVersionMigration{succeeded("old"), running}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := needsMigration("new", migrationsOf(gr, tc.migrations)); got != tc.expected {
+				t.Errorf("expected %t, got %t", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestMigrationName(t *testing.T) {
+	for gr, prefix := range map[schema.GroupResource]string{
+		{Resource: "configmaps"}:                    "configmaps-",
+		{Group: "apps", Resource: "deployments"}:    "deployments.apps-",
+		{Group: "example.com", Resource: "Widgets"}: "widgets.example.com-",
+	} {
+		name := migrationName(gr, "YQk2/+w=", 2)
+		if !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, "-2") || len(name) != len(prefix)+10 {
+			t.Errorf("%s: expected %q followed by 8 hex digits and the generation, got %q", gr, prefix, name)
+		}
+		if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+			t.Errorf("%s: invalid name %q: %v", gr, name, errs)
+		}
+		if other := migrationName(gr, "ZZk2/+w=", 2); other == name {
+			t.Errorf("%s: expected different names for different hashes, got %q", gr, name)
+		}
+		if other := migrationName(gr, "YQk2/+w=", 3); other == name {
+			t.Errorf("%s: expected different names for different generations, got %q", gr, name)
+		}
+	}
+}
+
+// TestCreateOnEveryServer checks that the servers triggering the migration
+// of the same storage version hash create a single migration.
+func TestCreateOnEveryServer(t *testing.T) {
+	client := &fakeMigrations{objects: map[string]*unstructured.Unstructured{}}
+	gr := schema.GroupResource{Group: "apps", Resource: "deployments"}
+	for i := 0; i < 3; i++ {
+		if err := newTestController(client).create(gr, "new", 1); err != nil {
+			t.Fatalf("server %d: %v", i, err)
+		}
+	}
+	if len(client.objects) != 1 {
+		t.Fatalf("expected a single migration, got %d", len(client.objects))
+	}
+	if svm := client.get(t, migrationName(gr, "new", 1)); svm.Spec.Resource.Group != "apps" || svm.Spec.Resource.Resource != "deployments" {
+		t.Errorf("unexpected migration: %#v", svm.Spec)
+	}
+}
@@ -0,0 +1,241 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storageversionmigrator
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/core/v1"
+	migrationv1alpha1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/migration/v1alpha1"
+	apierrors "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/api/errors"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/api/meta"
+	metav1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/fields"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/labels"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime/schema"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/rewrite"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/client-go/util/retry"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/klog"
+)
+
+// migrate rewrites the objects of the resource of the migration name page by
+// page, starting at the continue token of its status, and records the
+// progress after every page.
+func (c *Controller) migrate(ctx context.Context, name string) error {
+	// The migration is read from the server rather than the cache, so that
+	// progress recorded by an earlier run is not lost.
+	u, err := c.client.Get(name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	svm, err := fromUnstructured(u)
+	if err != nil {
+		return err
+	}
+	if finished(svm) {
+		return nil
+	}
+
+	gr := schema.GroupResource{Group: svm.Spec.Resource.Group, Resource: svm.Spec.Resource.Resource}
+	resource := rewrite.Lookup(gr)
+	if resource == nil {
+		return c.fail(name, "ResourceNotServed", fmt.Sprintf("%s is not stored by this server", gr))
+	}
+
+	if !isTrue(svm, migrationv1alpha1.MigrationRunning) {
+		hash := c.hash(gr)
+		svm, err = c.updateStatus(svm, func(status *migrationv1alpha1.StorageVersionMigrationStatus) {
+			if len(hash) > 0 {
+				status.StorageVersionHash = hash
+			}
+			setCondition(status, migrationv1alpha1.MigrationRunning, corev1.ConditionTrue, "", "")
+		})
+		if err != nil {
+			return err
+		}
+		klog.V(2).Infof("Started storage version migration %s of %s", name, gr)
+	}
+
+	for {
+		continueToken := svm.Status.ContinueToken
+		listObj := resource.NewListFunc()
+		pred := storage.SelectionPredicate{
+			Label:    labels.Everything(),
+			Field:    fields.Everything(),
+			Limit:    c.pageSize,
+			Continue: continueToken,
+		}
+		err := resource.Storage.List(ctx, resource.ResourcePrefix, "", pred, listObj)
+		if apierrors.IsResourceExpired(err) && len(continueToken) > 0 {
+			// The objects of the token were compacted. Continue after the
+			// last migrated object at the latest resource version.
+			next := inconsistentContinue(err)
+			klog.V(2).Infof("The continue token of storage version migration %s expired, continuing with %q", name, next)
+			svm, err = c.updateStatus(svm, func(status *migrationv1alpha1.StorageVersionMigrationStatus) {
+				status.ContinueToken = next
+			})
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		var migrated int64
+		if err := meta.EachListItem(listObj, func(obj runtime.Object) error {
+			c.limiter.Accept()
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if _, err := rewrite.Object(ctx, resource, obj); err != nil {
+				return err
+			}
+			migrated++
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		listMeta, err := meta.ListAccessor(listObj)
+		if err != nil {
+			return err
+		}
+		next := listMeta.GetContinue()
+		// The total is computed once, so that the page is not counted again
+		// when the update is retried on a newer version of the migration.
+		total := svm.Status.MigratedObjects + migrated
+		svm, err = c.updateStatus(svm, func(status *migrationv1alpha1.StorageVersionMigrationStatus) {
+			status.ContinueToken = next
+			status.MigratedObjects = total
+		})
+		if err != nil {
+			return err
+		}
+		if len(next) == 0 {
+			break
+		}
+	}
+
+	_, err = c.updateStatus(svm, func(status *migrationv1alpha1.StorageVersionMigrationStatus) {
+		setCondition(status, migrationv1alpha1.MigrationRunning, corev1.ConditionFalse, "", "")
+		setCondition(status, migrationv1alpha1.MigrationSucceeded, corev1.ConditionTrue, "", "")
+	})
+	if err == nil {
+		klog.V(2).Infof("Finished storage version migration %s of %s, migrated %d objects", name, gr, svm.Status.MigratedObjects)
+	}
+	return err
+}
+
+// fail marks the migration name as failed.
+func (c *Controller) fail(name, reason, message string) error {
+	u, err := c.client.Get(name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	svm, err := fromUnstructured(u)
+	if err != nil {
+		return err
+	}
+	_, err = c.updateStatus(svm, func(status *migrationv1alpha1.StorageVersionMigrationStatus) {
+		setCondition(status, migrationv1alpha1.MigrationRunning, corev1.ConditionFalse, "", "")
+		setCondition(status, migrationv1alpha1.MigrationFailed, corev1.ConditionTrue, reason, message)
+	})
+	return err
+}
+
+// updateStatus applies update to the status of svm and writes it. On
+// conflicts, update is applied to the latest version of svm again.
+func (c *Controller) updateStatus(svm *migrationv1alpha1.StorageVersionMigration, update func(status *migrationv1alpha1.StorageVersionMigrationStatus)) (*migrationv1alpha1.StorageVersionMigration, error) {
+	var result *migrationv1alpha1.StorageVersionMigration
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		svm = svm.DeepCopy()
+		update(&svm.Status)
+		u, err := toUnstructured(svm)
+		if err != nil {
+			return err
+		}
+		updated, err := c.client.UpdateStatus(u, metav1.UpdateOptions{})
+		if apierrors.IsConflict(err) {
+			latest, getErr := c.client.Get(svm.Name, metav1.GetOptions{})
+			if getErr != nil {
+				return getErr
+			}
+			if svm, getErr = fromUnstructured(latest); getErr != nil {
+				return getErr
+			}
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		result, err = fromUnstructured(updated)
+		return err
+	})
+	return result, err
+}
+
+// inconsistentContinue returns the continue token of an expired list, which
+// continues the list at the latest resource version.
+func inconsistentContinue(err error) string {
+	if status, ok := err.(apierrors.APIStatus); ok {
+		return status.Status().ListMeta.Continue
+	}
+	return ""
+}
+
+// setCondition sets the condition of type t in status.
+func setCondition(status *migrationv1alpha1.StorageVersionMigrationStatus, t migrationv1alpha1.MigrationConditionType, s corev1.ConditionStatus, reason, message string) {
+	condition := migrationv1alpha1.MigrationCondition{
+		Type:           t,
+		Status:         s,
+		LastUpdateTime: metav1.Now(),
+		Reason:         reason,
+		Message:        message,
+	}
+	for i := range status.Conditions {
+		if status.Conditions[i].Type == t {
+			status.Conditions[i] = condition
+			return
+		}
+	}
+	status.Conditions = append(status.Conditions, condition)
+}
+
+func isTrue(svm *migrationv1alpha1.StorageVersionMigration, t migrationv1alpha1.MigrationConditionType) bool {
+	for _, c := range svm.Status.Conditions {
+		if c.Type == t {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// finished returns whether svm succeeded or failed.
+func finished(svm *migrationv1alpha1.StorageVersionMigration) bool {
+	return isTrue(svm, migrationv1alpha1.MigrationSucceeded) || isTrue(svm, migrationv1alpha1.MigrationFailed)
+}
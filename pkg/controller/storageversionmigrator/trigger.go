@@ -0,0 +1,171 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storageversionmigrator
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+
+	migrationv1alpha1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/migration/v1alpha1"
+	apierrors "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/api/errors"
+	metav1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/labels"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime/schema"
+	utilruntime "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/util/runtime"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/rewrite"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/client-go/discovery"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/klog"
+)
+
+// trigger creates a StorageVersionMigration for every resource stored by
+// this server whose storage version hash in discovery is not the hash of the
+// latest StorageVersionMigration of the resource. Resources without a
+// StorageVersionMigration are migrated too, because the version their
+// objects are stored in is not known. The migration has a name derived from
+// the hash and the number of earlier migrations of the resource, so that a
+// server that triggers it again before it sees it finds it exists already,
+// while changing back to an earlier storage version is migrated again.
+func (c *Controller) trigger() {
+	hashes, err := storageVersionHashes(c.discovery)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	c.setHashes(hashes)
+
+	objs, err := c.lister.List(labels.Everything())
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	migrations := make([]*migrationv1alpha1.StorageVersionMigration, 0, len(objs))
+	for _, obj := range objs {
+		svm, err := fromUnstructured(obj)
+		if err != nil {
+			utilruntime.HandleError(err)
+			continue
+		}
+		migrations = append(migrations, svm)
+	}
+
+	for _, gr := range rewrite.Resources() {
+		hash, ok := hashes[gr]
+		if !ok {
+			continue
+		}
+		resourceMigrations := migrationsOf(gr, migrations)
+		if !needsMigration(hash, resourceMigrations) {
+			continue
+		}
+		if err := c.create(gr, hash, len(resourceMigrations)+1); err != nil {
+			utilruntime.HandleError(err)
+		}
+	}
+}
+
+func (c *Controller) create(gr schema.GroupResource, hash string, generation int) error {
+	svm := &migrationv1alpha1.StorageVersionMigration{
+		ObjectMeta: metav1.ObjectMeta{Name: migrationName(gr, hash, generation)},
+		Spec: migrationv1alpha1.StorageVersionMigrationSpec{
+			Resource: migrationv1alpha1.GroupVersionResource{Group: gr.Group, Resource: gr.Resource},
+		},
+	}
+	u, err := toUnstructured(svm)
+	if err != nil {
+		return err
+	}
+	created, err := c.client.Create(u, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		klog.V(4).Infof("Storage version migration %s for %s exists already", svm.Name, gr)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	klog.V(2).Infof("Created storage version migration %s for %s", created.GetName(), gr)
+	return nil
+}
+
+// migrationsOf returns the migrations of gr, oldest first.
+func migrationsOf(gr schema.GroupResource, migrations []*migrationv1alpha1.StorageVersionMigration) []*migrationv1alpha1.StorageVersionMigration {
+	var result []*migrationv1alpha1.StorageVersionMigration
+	for _, svm := range migrations {
+		if svm.Spec.Resource.Group == gr.Group && svm.Spec.Resource.Resource == gr.Resource {
+			result = append(result, svm)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		ti, tj := result[i].CreationTimestamp, result[j].CreationTimestamp
+		if !ti.Equal(&tj) {
+			return ti.Before(&tj)
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// needsMigration returns whether a resource with the given migrations, oldest
+// first, needs a new migration for its storage version hash. A migration is
+// not needed if the latest one is for the hash already, or if one is still
+// running; it writes the objects in the current storage version anyway.
+func needsMigration(hash string, migrations []*migrationv1alpha1.StorageVersionMigration) bool {
+	for _, svm := range migrations {
+		if !finished(svm) {
+			return false
+		}
+	}
+	return len(migrations) == 0 || migrations[len(migrations)-1].Status.StorageVersionHash != hash
+}
+
+// storageVersionHashes returns the storage version hashes of all resources
+// in discovery. Groups that fail discovery, e.g. unavailable aggregated
+// APIs, are skipped.
+func storageVersionHashes(d discovery.DiscoveryInterface) (map[schema.GroupResource]string, error) {
+	lists, err := d.ServerResources()
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return nil, err
+	}
+	hashes := map[schema.GroupResource]string{}
+	for _, list := range lists {
+		if list == nil {
+			continue
+		}
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, r := range list.APIResources {
+			if strings.Contains(r.Name, "/") || len(r.StorageVersionHash) == 0 {
+				continue
+			}
+			hashes[gv.WithResource(r.Name).GroupResource()] = r.StorageVersionHash
+		}
+	}
+	return hashes, nil
+}
+
+// migrationName returns the name of the generation-th migration of gr, to the
+// storage version with the given hash, e.g. "deployments.apps-1a2b3c4d-2".
+// Storage version hashes are base64 encoded, so they are hashed again into a
+// valid name.
+func migrationName(gr schema.GroupResource, hash string, generation int) string {
+	h := fnv.New32a()
+	h.Write([]byte(hash))
+	return fmt.Sprintf("%s-%08x-%d", strings.ToLower(gr.String()), h.Sum32(), generation)
+}
@@ -507,11 +507,27 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"github.com/aaron-prindle/krmapiserver/included/k8s.io/api/extensions/v1beta1.ScaleSpec":                                                                     schema_k8sio_api_extensions_v1beta1_ScaleSpec(ref),
 		"github.com/aaron-prindle/krmapiserver/included/k8s.io/api/extensions/v1beta1.ScaleStatus":                                                                   schema_k8sio_api_extensions_v1beta1_ScaleStatus(ref),
 		"github.com/aaron-prindle/krmapiserver/included/k8s.io/api/extensions/v1beta1.SupplementalGroupsStrategyOptions":                                             schema_k8sio_api_extensions_v1beta1_SupplementalGroupsStrategyOptions(ref),
+		"github.com/aaron-prindle/krmapiserver/included/k8s.io/api/history/v1alpha1.ObjectHistory":                                                                   schema_k8sio_api_history_v1alpha1_ObjectHistory(ref),
+		"github.com/aaron-prindle/krmapiserver/included/k8s.io/api/history/v1alpha1.ObjectRevision":                                                                  schema_k8sio_api_history_v1alpha1_ObjectRevision(ref),
 		"github.com/aaron-prindle/krmapiserver/included/k8s.io/api/imagepolicy/v1alpha1.ImageReview":                                                                 schema_k8sio_api_imagepolicy_v1alpha1_ImageReview(ref),
 		"github.com/aaron-prindle/krmapiserver/included/k8s.io/api/imagepolicy/v1alpha1.ImageReviewContainerSpec":                                                    schema_k8sio_api_imagepolicy_v1alpha1_ImageReviewContainerSpec(ref),
 		"github.com/aaron-prindle/krmapiserver/included/k8s.io/api/imagepolicy/v1alpha1.ImageReviewSpec":                                                             schema_k8sio_api_imagepolicy_v1alpha1_ImageReviewSpec(ref),
 		"github.com/aaron-prindle/krmapiserver/included/k8s.io/api/imagepolicy/v1alpha1.ImageReviewStatus":                                                           schema_k8sio_api_imagepolicy_v1alpha1_ImageReviewStatus(ref),
 		"github.com/aaron-prindle/krmapiserver/included/k8s.io/api/networking/v1.IPBlock":                                                                            schema_k8sio_api_networking_v1_IPBlock(ref),
+		"github.com/aaron-prindle/krmapiserver/included/k8s.io/api/migration/v1alpha1.GroupVersionResource":                                                          schema_k8sio_api_migration_v1alpha1_GroupVersionResource(ref),
+		"github.com/aaron-prindle/krmapiserver/included/k8s.io/api/migration/v1alpha1.MigrationCondition":                                                            schema_k8sio_api_migration_v1alpha1_MigrationCondition(ref),
+		"github.com/aaron-prindle/krmapiserver/included/k8s.io/api/migration/v1alpha1.StorageVersionMigration":                                                       schema_k8sio_api_migration_v1alpha1_StorageVersionMigration(ref),
+		"github.com/aaron-prindle/krmapiserver/included/k8s.io/api/migration/v1alpha1.StorageVersionMigrationList":                                                   schema_k8sio_api_migration_v1alpha1_StorageVersionMigrationList(ref),
+		"github.com/aaron-prindle/krmapiserver/included/k8s.io/api/migration/v1alpha1.StorageVersionMigrationSpec":                                                   schema_k8sio_api_migration_v1alpha1_StorageVersionMigrationSpec(ref),
+		"github.com/aaron-prindle/krmapiserver/included/k8s.io/api/migration/v1alpha1.StorageVersionMigrationStatus":                                                 schema_k8sio_api_migration_v1alpha1_StorageVersionMigrationStatus(ref),
+		"github.com/aaron-prindle/krmapiserver/included/k8s.io/api/trash/v1alpha1.DeletedObject":                                                                     schema_k8sio_api_trash_v1alpha1_DeletedObject(ref),
+		"github.com/aaron-prindle/krmapiserver/included/k8s.io/api/trash/v1alpha1.DeletedObjectList":                                                                 schema_k8sio_api_trash_v1alpha1_DeletedObjectList(ref),
+		"github.com/aaron-prindle/krmapiserver/included/k8s.io/api/trash/v1alpha1.GroupResource":                                                                     schema_k8sio_api_trash_v1alpha1_GroupResource(ref),
+		"github.com/aaron-prindle/krmapiserver/included/k8s.io/api/trash/v1alpha1.ObjectReference":                                                                   schema_k8sio_api_trash_v1alpha1_ObjectReference(ref),
+		"github.com/aaron-prindle/krmapiserver/included/k8s.io/api/trash/v1alpha1.Undelete":                                                                          schema_k8sio_api_trash_v1alpha1_Undelete(ref),
+		"github.com/aaron-prindle/krmapiserver/included/k8s.io/api/usage/v1alpha1.GroupResource":                                                                     schema_k8sio_api_usage_v1alpha1_GroupResource(ref),
+		"github.com/aaron-prindle/krmapiserver/included/k8s.io/api/usage/v1alpha1.StorageUsage":                                                                      schema_k8sio_api_usage_v1alpha1_StorageUsage(ref),
+		"github.com/aaron-prindle/krmapiserver/included/k8s.io/api/usage/v1alpha1.StorageUsageList":                                                                  schema_k8sio_api_usage_v1alpha1_StorageUsageList(ref),
 		"github.com/aaron-prindle/krmapiserver/included/k8s.io/api/networking/v1.NetworkPolicy":                                                                      schema_k8sio_api_networking_v1_NetworkPolicy(ref),
 		"github.com/aaron-prindle/krmapiserver/included/k8s.io/api/networking/v1.NetworkPolicyEgressRule":                                                            schema_k8sio_api_networking_v1_NetworkPolicyEgressRule(ref),
 		"github.com/aaron-prindle/krmapiserver/included/k8s.io/api/networking/v1.NetworkPolicyIngressRule":                                                           schema_k8sio_api_networking_v1_NetworkPolicyIngressRule(ref),
@@ -629,6 +645,11 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"github.com/aaron-prindle/krmapiserver/included/k8s.io/api/storage/v1beta1.VolumeAttachmentSpec":                                                             schema_k8sio_api_storage_v1beta1_VolumeAttachmentSpec(ref),
 		"github.com/aaron-prindle/krmapiserver/included/k8s.io/api/storage/v1beta1.VolumeAttachmentStatus":                                                           schema_k8sio_api_storage_v1beta1_VolumeAttachmentStatus(ref),
 		"github.com/aaron-prindle/krmapiserver/included/k8s.io/api/storage/v1beta1.VolumeError":                                                                      schema_k8sio_api_storage_v1beta1_VolumeError(ref),
+		"github.com/aaron-prindle/krmapiserver/included/k8s.io/api/transaction/v1alpha1.Operation":                                                                   schema_k8sio_api_transaction_v1alpha1_Operation(ref),
+		"github.com/aaron-prindle/krmapiserver/included/k8s.io/api/transaction/v1alpha1.OperationResult":                                                             schema_k8sio_api_transaction_v1alpha1_OperationResult(ref),
+		"github.com/aaron-prindle/krmapiserver/included/k8s.io/api/transaction/v1alpha1.Transaction":                                                                 schema_k8sio_api_transaction_v1alpha1_Transaction(ref),
+		"github.com/aaron-prindle/krmapiserver/included/k8s.io/api/transaction/v1alpha1.TransactionSpec":                                                             schema_k8sio_api_transaction_v1alpha1_TransactionSpec(ref),
+		"github.com/aaron-prindle/krmapiserver/included/k8s.io/api/transaction/v1alpha1.TransactionStatus":                                                           schema_k8sio_api_transaction_v1alpha1_TransactionStatus(ref),
 		"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1.ConversionRequest":                             schema_pkg_apis_apiextensions_v1beta1_ConversionRequest(ref),
 		"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1.ConversionResponse":                            schema_pkg_apis_apiextensions_v1beta1_ConversionResponse(ref),
 		"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1.ConversionReview":                              schema_pkg_apis_apiextensions_v1beta1_ConversionReview(ref),
@@ -23703,6 +23724,111 @@ func schema_k8sio_api_extensions_v1beta1_SupplementalGroupsStrategyOptions(ref c
 	}
 }
 
+func schema_k8sio_api_history_v1alpha1_ObjectHistory(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ObjectHistory is the history of an object: its revisions replaced by updates and deletions that are still retained, oldest first. It is served by the history subresource of the resources whose history is kept.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Standard object's metadata, with the name and namespace of the object. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#metadata",
+							Ref:         ref("github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
+						},
+					},
+					"revisions": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Revisions are the retained revisions of the object, oldest first.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/aaron-prindle/krmapiserver/included/k8s.io/api/history/v1alpha1.ObjectRevision"),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"revisions"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/aaron-prindle/krmapiserver/included/k8s.io/api/history/v1alpha1.ObjectRevision", "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
+	}
+}
+
+func schema_k8sio_api_history_v1alpha1_ObjectRevision(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ObjectRevision is a revision of an object, and the operation that replaced it.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"resourceVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ResourceVersion is the resourceVersion of the object as of the revision.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"object": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Object is the object as of the revision.",
+							Ref:         ref("github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime.RawExtension"),
+						},
+					},
+					"operation": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Operation is the operation that replaced the revision, Update or Delete.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"user": {
+						SchemaProps: spec.SchemaProps{
+							Description: "User is the name of the user who wrote the revision.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"timestamp": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Timestamp is the time the revision was replaced.",
+							Ref:         ref("github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"patch": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Patch is the JSON merge patch from the revision to the next one, or to the current object for the last revision. It is not set for deletions.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"resourceVersion", "object", "operation", "timestamp"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1.Time", "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime.RawExtension"},
+	}
+}
+
 func schema_k8sio_api_imagepolicy_v1alpha1_ImageReview(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -23805,96 +23931,703 @@ func schema_k8sio_api_imagepolicy_v1alpha1_ImageReviewSpec(ref common.ReferenceC
 							},
 						},
 					},
-					"namespace": {
+					"namespace": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Namespace is the namespace the pod is being created in.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/aaron-prindle/krmapiserver/included/k8s.io/api/imagepolicy/v1alpha1.ImageReviewContainerSpec"},
+	}
+}
+
+func schema_k8sio_api_imagepolicy_v1alpha1_ImageReviewStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ImageReviewStatus is the result of the review for the pod creation request.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"allowed": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Allowed indicates that all images were allowed to be run.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"reason": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Reason should be empty unless Allowed is false in which case it may contain a short description of what is wrong.  Kubernetes may truncate excessively long errors when displaying to the user.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"auditAnnotations": {
+						SchemaProps: spec.SchemaProps{
+							Description: "AuditAnnotations will be added to the attributes object of the admission controller request using 'AddAnnotation'.  The keys should be prefix-less (i.e., the admission controller will add an appropriate prefix).",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"allowed"},
+			},
+		},
+	}
+}
+
+func schema_k8sio_api_networking_v1_IPBlock(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "IPBlock describes a particular CIDR (Ex. \"192.168.1.1/24\") that is allowed to the pods matched by a NetworkPolicySpec's podSelector. The except entry describes CIDRs that should not be included within this rule.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"cidr": {
+						SchemaProps: spec.SchemaProps{
+							Description: "CIDR is a string representing the IP Block Valid examples are \"192.168.1.1/24\"",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"except": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Except is a slice of CIDRs that should not be included within an IP Block Valid examples are \"192.168.1.1/24\" Except values will be rejected if they are outside the CIDR range",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"cidr"},
+			},
+		},
+	}
+}
+
+func schema_k8sio_api_migration_v1alpha1_GroupVersionResource(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "GroupVersionResource identifies a resource.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"group": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Group is the API group of the resource. The empty string is the core group.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"version": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Version is the API version of the resource. Objects are migrated to the storage version of the resource, whatever the version.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"resource": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Resource is the name of the resource, e.g. \"deployments\".",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"resource"},
+			},
+		},
+	}
+}
+
+func schema_k8sio_api_migration_v1alpha1_MigrationCondition(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "MigrationCondition describes the state of a migration at a certain point.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"type": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Type of the condition, one of Running, Succeeded or Failed.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Status of the condition, one of True, False or Unknown.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"lastUpdateTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "LastUpdateTime is the last time the condition was updated.",
+							Ref:         ref("github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"reason": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Reason is a machine readable reason for the condition's last transition.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"message": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Message is a human readable description of the details of the last transition.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"type", "status"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
+func schema_k8sio_api_migration_v1alpha1_StorageVersionMigration(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "StorageVersionMigration is the migration of the stored objects of a resource to its current storage version. Every object of the resource is rewritten in storage, so that it is encoded in the version the resource is stored in now. Migrations are created by the server whenever the storage version hash of a resource changes, and can be created by users.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Description: "More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#metadata",
+							Ref:         ref("github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
+						},
+					},
+					"spec": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Spec identifies the resource to migrate.",
+							Ref:         ref("github.com/aaron-prindle/krmapiserver/included/k8s.io/api/migration/v1alpha1.StorageVersionMigrationSpec"),
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Status is the progress of the migration.",
+							Ref:         ref("github.com/aaron-prindle/krmapiserver/included/k8s.io/api/migration/v1alpha1.StorageVersionMigrationStatus"),
+						},
+					},
+				},
+				Required: []string{"spec"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/aaron-prindle/krmapiserver/included/k8s.io/api/migration/v1alpha1.StorageVersionMigrationSpec", "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/migration/v1alpha1.StorageVersionMigrationStatus", "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
+	}
+}
+
+func schema_k8sio_api_migration_v1alpha1_StorageVersionMigrationList(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "StorageVersionMigrationList is a list of StorageVersionMigration objects.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Standard list metadata.",
+							Ref:         ref("github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"),
+						},
+					},
+					"items": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Items is the list of StorageVersionMigration objects.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/aaron-prindle/krmapiserver/included/k8s.io/api/migration/v1alpha1.StorageVersionMigration"),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"items"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/aaron-prindle/krmapiserver/included/k8s.io/api/migration/v1alpha1.StorageVersionMigration", "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"},
+	}
+}
+
+func schema_k8sio_api_migration_v1alpha1_StorageVersionMigrationSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "StorageVersionMigrationSpec identifies the resource to migrate.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"resource": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Resource is the resource whose objects are migrated. It is immutable.",
+							Ref:         ref("github.com/aaron-prindle/krmapiserver/included/k8s.io/api/migration/v1alpha1.GroupVersionResource"),
+						},
+					},
+				},
+				Required: []string{"resource"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/aaron-prindle/krmapiserver/included/k8s.io/api/migration/v1alpha1.GroupVersionResource"},
+	}
+}
+
+func schema_k8sio_api_migration_v1alpha1_StorageVersionMigrationStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "StorageVersionMigrationStatus is the progress of a migration.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"conditions": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Conditions are the latest observations of the state of the migration.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/aaron-prindle/krmapiserver/included/k8s.io/api/migration/v1alpha1.MigrationCondition"),
+									},
+								},
+							},
+						},
+					},
+					"storageVersionHash": {
+						SchemaProps: spec.SchemaProps{
+							Description: "StorageVersionHash is the storage version hash of the resource, as published in discovery, that the objects are migrated to.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"continueToken": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ContinueToken is the token of the next chunk of objects to migrate. A migration that is interrupted, e.g. by a restart of the server, resumes from it.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"migratedObjects": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MigratedObjects is the number of objects migrated so far.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/aaron-prindle/krmapiserver/included/k8s.io/api/migration/v1alpha1.MigrationCondition"},
+	}
+}
+
+func schema_k8sio_api_trash_v1alpha1_DeletedObject(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "DeletedObject is an object kept in the trash of its resource after its deletion, until it is restored by the undelete subresource of the resource or purged at the end of the retention of the trash. It is named after the UID of the deleted object and labeled with its labels. DeletedObjects are computed by the server from the trash of the resources, so they are read-only.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Description: "More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#metadata",
+							Ref:         ref("github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
+						},
+					},
+					"resource": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Resource is the resource of the deleted object.",
+							Ref:         ref("github.com/aaron-prindle/krmapiserver/included/k8s.io/api/trash/v1alpha1.GroupResource"),
+						},
+					},
+					"object": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Object identifies the deleted object.",
+							Ref:         ref("github.com/aaron-prindle/krmapiserver/included/k8s.io/api/trash/v1alpha1.ObjectReference"),
+						},
+					},
+					"deletedBy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DeletedBy is the name of the user who deleted the object.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"deletedAt": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DeletedAt is when the object was deleted.",
+							Ref:         ref("github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"purgeAt": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PurgeAt is when the object is purged from the trash, after which it can no longer be restored.",
+							Ref:         ref("github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+				},
+				Required: []string{"resource", "object", "deletedAt", "purgeAt"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/aaron-prindle/krmapiserver/included/k8s.io/api/trash/v1alpha1.GroupResource", "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/trash/v1alpha1.ObjectReference", "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta", "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
+func schema_k8sio_api_trash_v1alpha1_DeletedObjectList(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "DeletedObjectList is a list of DeletedObject objects.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Standard list metadata.",
+							Ref:         ref("github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"),
+						},
+					},
+					"items": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Items is the list of DeletedObject objects.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/aaron-prindle/krmapiserver/included/k8s.io/api/trash/v1alpha1.DeletedObject"),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"items"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/aaron-prindle/krmapiserver/included/k8s.io/api/trash/v1alpha1.DeletedObject", "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"},
+	}
+}
+
+func schema_k8sio_api_trash_v1alpha1_GroupResource(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "GroupResource identifies a resource.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"group": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Group is the API group of the resource. The empty string is the core group.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"resource": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Resource is the name of the resource, e.g. \"namespaces\".",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"resource"},
+			},
+		},
+	}
+}
+
+func schema_k8sio_api_trash_v1alpha1_ObjectReference(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ObjectReference identifies an object of a resource.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"namespace": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Namespace is the namespace of the object, empty for cluster-scoped resources.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name is the name of the object.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"uid": {
+						SchemaProps: spec.SchemaProps{
+							Description: "UID is the UID of the object.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"name", "uid"},
+			},
+		},
+	}
+}
+
+func schema_k8sio_api_trash_v1alpha1_Undelete(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "Undelete restores a deleted object from the trash of its resource, with its UID, labels and the rest of its state at its deletion. It is the body of the undelete subresource of the resources whose deleted objects are kept in the trash, and is named after the object.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Description: "More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#metadata",
+							Ref:         ref("github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
+						},
+					},
+					"uid": {
+						SchemaProps: spec.SchemaProps{
+							Description: "UID is the UID of the deleted object to restore, for when several objects with its name were deleted. The most recently deleted one is restored if empty.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
+	}
+}
+
+func schema_k8sio_api_usage_v1alpha1_GroupResource(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "GroupResource identifies a resource.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"group": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Group is the API group of the resource. The empty string is the core group.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"resource": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Namespace is the namespace the pod is being created in.",
+							Description: "Resource is the name of the resource, e.g. \"configmaps\".",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
 				},
+				Required: []string{"resource"},
 			},
 		},
-		Dependencies: []string{
-			"github.com/aaron-prindle/krmapiserver/included/k8s.io/api/imagepolicy/v1alpha1.ImageReviewContainerSpec"},
 	}
 }
 
-func schema_k8sio_api_imagepolicy_v1alpha1_ImageReviewStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_k8sio_api_usage_v1alpha1_StorageUsage(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "ImageReviewStatus is the result of the review for the pod creation request.",
+				Description: "StorageUsage is the number and size of the stored objects of a resource in a namespace. It is named after the resource, e.g. \"configmaps\" or \"deployments.apps\", and computed by the server from the accounting of the writes to storage, so it is read-only.",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"allowed": {
+					"kind": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Allowed indicates that all images were allowed to be run.",
-							Type:        []string{"boolean"},
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"reason": {
+					"apiVersion": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Reason should be empty unless Allowed is false in which case it may contain a short description of what is wrong.  Kubernetes may truncate excessively long errors when displaying to the user.",
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#resources",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"auditAnnotations": {
+					"metadata": {
 						SchemaProps: spec.SchemaProps{
-							Description: "AuditAnnotations will be added to the attributes object of the admission controller request using 'AddAnnotation'.  The keys should be prefix-less (i.e., the admission controller will add an appropriate prefix).",
-							Type:        []string{"object"},
-							AdditionalProperties: &spec.SchemaOrBool{
-								Allows: true,
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Type:   []string{"string"},
-										Format: "",
-									},
-								},
-							},
+							Description: "More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#metadata",
+							Ref:         ref("github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
+						},
+					},
+					"resource": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Resource is the resource whose objects are accounted.",
+							Ref:         ref("github.com/aaron-prindle/krmapiserver/included/k8s.io/api/usage/v1alpha1.GroupResource"),
+						},
+					},
+					"objects": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Objects is the number of objects of the resource in the namespace.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"bytes": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Bytes is the total size of the objects of the resource in the namespace, as they are stored, that is after they are encoded and transformed, e.g. encrypted or compressed.",
+							Type:        []string{"integer"},
+							Format:      "int64",
 						},
 					},
 				},
-				Required: []string{"allowed"},
+				Required: []string{"resource", "objects", "bytes"},
 			},
 		},
+		Dependencies: []string{
+			"github.com/aaron-prindle/krmapiserver/included/k8s.io/api/usage/v1alpha1.GroupResource", "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
 	}
 }
 
-func schema_k8sio_api_networking_v1_IPBlock(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_k8sio_api_usage_v1alpha1_StorageUsageList(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "IPBlock describes a particular CIDR (Ex. \"192.168.1.1/24\") that is allowed to the pods matched by a NetworkPolicySpec's podSelector. The except entry describes CIDRs that should not be included within this rule.",
+				Description: "StorageUsageList is a list of StorageUsage objects.",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"cidr": {
+					"kind": {
 						SchemaProps: spec.SchemaProps{
-							Description: "CIDR is a string representing the IP Block Valid examples are \"192.168.1.1/24\"",
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#types-kinds",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"except": {
+					"apiVersion": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Except is a slice of CIDRs that should not be included within an IP Block Valid examples are \"192.168.1.1/24\" Except values will be rejected if they are outside the CIDR range",
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Standard list metadata.",
+							Ref:         ref("github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"),
+						},
+					},
+					"items": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Items is the list of StorageUsage objects.",
 							Type:        []string{"array"},
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
-										Type:   []string{"string"},
-										Format: "",
+										Ref: ref("github.com/aaron-prindle/krmapiserver/included/k8s.io/api/usage/v1alpha1.StorageUsage"),
 									},
 								},
 							},
 						},
 					},
 				},
-				Required: []string{"cidr"},
+				Required: []string{"items"},
 			},
 		},
+		Dependencies: []string{
+			"github.com/aaron-prindle/krmapiserver/included/k8s.io/api/usage/v1alpha1.StorageUsage", "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"},
 	}
 }
 
@@ -29337,6 +30070,203 @@ func schema_k8sio_api_storage_v1beta1_VolumeError(ref common.ReferenceCallback)
 	}
 }
 
+func schema_k8sio_api_transaction_v1alpha1_Operation(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "Operation is the creation, update or deletion of an object.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"type": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Type is the type of the operation, one of Create, Update or Delete.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"group": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Group is the API group of the resource of the object. The empty string is the core group.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"version": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Version is the API version of the resource of the object, which is also the version of the object in Object and in the result.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"resource": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Resource is the resource of the object, e.g. \"configmaps\".",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"namespace": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Namespace is the namespace of the object, which must be set for namespaced resources only.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name is the name of the object. It is required for updates and deletions, and defaults to the name of the object for creations.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"object": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Object is the object to create, or to replace the existing object with. It is required for creations and updates, and must not be set for deletions.",
+							Ref:         ref("github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime.RawExtension"),
+						},
+					},
+					"preconditions": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Preconditions must be fulfilled by the object to delete. They must not be set for creations and updates.",
+							Ref:         ref("github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1.Preconditions"),
+						},
+					},
+				},
+				Required: []string{"type", "version", "resource"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1.Preconditions", "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime.RawExtension"},
+	}
+}
+
+func schema_k8sio_api_transaction_v1alpha1_OperationResult(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "OperationResult is the result of a committed operation.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"object": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Object is the object stored by a creation or update, or the deleted object of a deletion.",
+							Ref:         ref("github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime.RawExtension"),
+						},
+					},
+				},
+				Required: []string{"object"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime.RawExtension"},
+	}
+}
+
+func schema_k8sio_api_transaction_v1alpha1_Transaction(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "Transaction creates, updates and deletes a list of objects atomically: either all operations are committed at a single resourceVersion, or none of them is. The objects may be of different resources, including custom resources, but must be stored in the same storage backend. Every operation is authorized and admitted like the equivalent request on the object. Transactions are not persisted.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Description: "More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#metadata",
+							Ref:         ref("github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
+						},
+					},
+					"spec": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Spec holds the operations of the transaction.",
+							Ref:         ref("github.com/aaron-prindle/krmapiserver/included/k8s.io/api/transaction/v1alpha1.TransactionSpec"),
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Status is filled in by the server with the results of the committed operations.",
+							Ref:         ref("github.com/aaron-prindle/krmapiserver/included/k8s.io/api/transaction/v1alpha1.TransactionStatus"),
+						},
+					},
+				},
+				Required: []string{"spec"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/aaron-prindle/krmapiserver/included/k8s.io/api/transaction/v1alpha1.TransactionSpec", "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/transaction/v1alpha1.TransactionStatus", "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
+	}
+}
+
+func schema_k8sio_api_transaction_v1alpha1_TransactionSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TransactionSpec is the list of operations of a transaction.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"operations": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Operations are the operations of the transaction. An object may only be the target of one operation.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/aaron-prindle/krmapiserver/included/k8s.io/api/transaction/v1alpha1.Operation"),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"operations"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/aaron-prindle/krmapiserver/included/k8s.io/api/transaction/v1alpha1.Operation"},
+	}
+}
+
+func schema_k8sio_api_transaction_v1alpha1_TransactionStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TransactionStatus holds the results of a committed transaction.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"results": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Results holds the result of each operation, in the order of the operations.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/aaron-prindle/krmapiserver/included/k8s.io/api/transaction/v1alpha1.OperationResult"),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/aaron-prindle/krmapiserver/included/k8s.io/api/transaction/v1alpha1.OperationResult"},
+	}
+}
+
 func schema_pkg_apis_apiextensions_v1beta1_ConversionRequest(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -31592,6 +32522,13 @@ func schema_pkg_apis_meta_v1_ListOptions(ref common.ReferenceCallback) common.Op
 							Format:      "",
 						},
 					},
+					"annotationSelector": {
+						SchemaProps: spec.SchemaProps{
+							Description: "A selector to restrict the list of returned objects by their annotations. It uses the syntax of label selectors. Defaults to everything.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 					"watch": {
 						SchemaProps: spec.SchemaProps{
 							Description: "Watch for changes to the described resources and return them as a stream of add, update, and remove notifications. Specify resourceVersion.",
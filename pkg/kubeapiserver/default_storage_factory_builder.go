@@ -18,13 +18,17 @@ package kubeapiserver
 
 import (
 	"strings"
+	"time"
 
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime/schema"
+	genericapiserver "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/server"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/server/healthz"
 	serveroptions "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/server/options"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/server/options/encryptionconfig"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/server/resourceconfig"
 	serverstorage "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/server/storage"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/reencryption"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/storagebackend"
 	utilfeature "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/util/feature"
 	"github.com/aaron-prindle/krmapiserver/pkg/api/legacyscheme"
@@ -33,6 +37,7 @@ import (
 	api "github.com/aaron-prindle/krmapiserver/pkg/apis/core"
 	"github.com/aaron-prindle/krmapiserver/pkg/apis/events"
 	"github.com/aaron-prindle/krmapiserver/pkg/apis/extensions"
+	"github.com/aaron-prindle/krmapiserver/pkg/apis/migration"
 	"github.com/aaron-prindle/krmapiserver/pkg/apis/networking"
 	"github.com/aaron-prindle/krmapiserver/pkg/apis/policy"
 	apisstorage "github.com/aaron-prindle/krmapiserver/pkg/apis/storage"
@@ -81,19 +86,30 @@ type StorageFactoryConfig struct {
 	Serializer                       runtime.StorageSerializer
 	ResourceEncodingOverrides        []schema.GroupVersionResource
 	EtcdServersOverrides             []string
+	EtcdShardsOverrides              []string
 	EncryptionProviderConfigFilepath string
+
+	EncryptionProviderConfigAutomaticReload bool
+	EncryptionProviderConfigReloadInterval  time.Duration
 }
 
 func (c *StorageFactoryConfig) Complete(etcdOptions *serveroptions.EtcdOptions) (*completedStorageFactoryConfig, error) {
 	c.StorageConfig = etcdOptions.StorageConfig
 	c.DefaultStorageMediaType = etcdOptions.DefaultStorageMediaType
 	c.EtcdServersOverrides = etcdOptions.EtcdServersOverrides
+	c.EtcdShardsOverrides = etcdOptions.EtcdShardsOverrides
 	c.EncryptionProviderConfigFilepath = etcdOptions.EncryptionProviderConfigFilepath
-	return &completedStorageFactoryConfig{c}, nil
+	c.EncryptionProviderConfigAutomaticReload = etcdOptions.EncryptionProviderConfigAutomaticReload
+	c.EncryptionProviderConfigReloadInterval = etcdOptions.EncryptionProviderConfigReloadInterval
+	return &completedStorageFactoryConfig{StorageFactoryConfig: c}, nil
 }
 
 type completedStorageFactoryConfig struct {
 	*StorageFactoryConfig
+
+	// dynamicTransformers are the transformers of the encrypted resources if
+	// an encryption provider configuration is given.
+	dynamicTransformers *encryptionconfig.DynamicTransformers
 }
 
 func (c *completedStorageFactoryConfig) New() (*serverstorage.DefaultStorageFactory, error) {
@@ -114,6 +130,8 @@ func (c *completedStorageFactoryConfig) New() (*serverstorage.DefaultStorageFact
 	storageFactory.AddCohabitatingResources(api.Resource("replicationcontrollers"), extensions.Resource("replicationcontrollers")) // to make scale subresources equivalent
 	storageFactory.AddCohabitatingResources(policy.Resource("podsecuritypolicies"), extensions.Resource("podsecuritypolicies"))
 	storageFactory.AddCohabitatingResources(networking.Resource("ingresses"), extensions.Resource("ingresses"))
+	// storage version migrations have no protobuf encoding.
+	storageFactory.SetSerializer(migration.Resource("storageversionmigrations"), runtime.ContentTypeJSON, c.Serializer)
 
 	for _, override := range c.EtcdServersOverrides {
 		tokens := strings.Split(override, "#")
@@ -126,14 +144,59 @@ func (c *completedStorageFactoryConfig) New() (*serverstorage.DefaultStorageFact
 		servers := strings.Split(tokens[1], ";")
 		storageFactory.SetEtcdLocation(groupResource, servers)
 	}
+	shards := map[schema.GroupResource][][]string{}
+	for _, override := range c.EtcdShardsOverrides {
+		tokens := strings.Split(override, "#")
+		apiresource := strings.Split(tokens[0], "/")
+
+		groupResource := schema.GroupResource{Group: apiresource[0], Resource: apiresource[1]}
+		shards[groupResource] = append(shards[groupResource], strings.Split(tokens[1], ";"))
+	}
+	for groupResource, servers := range shards {
+		storageFactory.SetEtcdShards(groupResource, servers)
+	}
 	if len(c.EncryptionProviderConfigFilepath) != 0 {
-		transformerOverrides, err := encryptionconfig.GetTransformerOverrides(c.EncryptionProviderConfigFilepath)
+		dynamicTransformers, err := encryptionconfig.NewDynamicTransformers(c.EncryptionProviderConfigFilepath)
 		if err != nil {
 			return nil, err
 		}
-		for groupResource, transformer := range transformerOverrides {
-			storageFactory.SetTransformer(groupResource, transformer)
-		}
+		c.dynamicTransformers = dynamicTransformers
+		storageFactory.SetResourceTransformers(dynamicTransformers)
 	}
 	return storageFactory, nil
 }
+
+// EncryptionConfigPostStartHook returns the hook that polls the status of the
+// KMS providers of the encryption provider configuration and, if automatic
+// reload is enabled, reloads the configuration and re-encrypts the objects of
+// the resources whose providers changed. It returns nil if there is no
+// configuration. It must be called after New.
+func (c *completedStorageFactoryConfig) EncryptionConfigPostStartHook() genericapiserver.PostStartHookFunc {
+	if c.dynamicTransformers == nil {
+		return nil
+	}
+	dynamicTransformers := c.dynamicTransformers
+	automaticReload := c.EncryptionProviderConfigAutomaticReload
+	interval := c.EncryptionProviderConfigReloadInterval
+	return func(context genericapiserver.PostStartHookContext) error {
+		go dynamicTransformers.RunKMSStatusPolls(encryptionconfig.DefaultKMSStatusPollInterval, context.StopCh)
+		if !automaticReload {
+			return nil
+		}
+		controller := reencryption.NewController()
+		dynamicTransformers.AddListener(controller.Enqueue)
+		go controller.Run(context.StopCh)
+		go dynamicTransformers.Run(interval, context.StopCh)
+		return nil
+	}
+}
+
+// KMSHealthzChecker returns the healthz check of the KMS providers of the
+// encryption provider configuration, or nil if there is no configuration. It
+// must be called after New.
+func (c *completedStorageFactoryConfig) KMSHealthzChecker() healthz.HealthzChecker {
+	if c.dynamicTransformers == nil {
+		return nil
+	}
+	return c.dynamicTransformers.HealthzChecker()
+}
@@ -32,6 +32,7 @@ import (
 	_ "github.com/aaron-prindle/krmapiserver/pkg/apis/events/install"
 	_ "github.com/aaron-prindle/krmapiserver/pkg/apis/extensions/install"
 	_ "github.com/aaron-prindle/krmapiserver/pkg/apis/imagepolicy/install"
+	_ "github.com/aaron-prindle/krmapiserver/pkg/apis/migration/install"
 	_ "github.com/aaron-prindle/krmapiserver/pkg/apis/networking/install"
 	_ "github.com/aaron-prindle/krmapiserver/pkg/apis/node/install"
 	_ "github.com/aaron-prindle/krmapiserver/pkg/apis/policy/install"
@@ -39,4 +40,7 @@ import (
 	_ "github.com/aaron-prindle/krmapiserver/pkg/apis/scheduling/install"
 	_ "github.com/aaron-prindle/krmapiserver/pkg/apis/settings/install"
 	_ "github.com/aaron-prindle/krmapiserver/pkg/apis/storage/install"
+	_ "github.com/aaron-prindle/krmapiserver/pkg/apis/transaction/install"
+	_ "github.com/aaron-prindle/krmapiserver/pkg/apis/trash/install"
+	_ "github.com/aaron-prindle/krmapiserver/pkg/apis/usage/install"
 )
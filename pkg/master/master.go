@@ -45,6 +45,7 @@ import (
 	apiv1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/core/v1"
 	eventsv1beta1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/events/v1beta1"
 	extensionsapiv1beta1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/extensions/v1beta1"
+	migrationv1alpha1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/migration/v1alpha1"
 	networkingapiv1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/networking/v1"
 	networkingapiv1beta1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/networking/v1beta1"
 	nodev1alpha1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/node/v1alpha1"
@@ -60,6 +61,9 @@ import (
 	storageapiv1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/storage/v1"
 	storageapiv1alpha1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/storage/v1alpha1"
 	storageapiv1beta1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/storage/v1beta1"
+	transactionv1alpha1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/transaction/v1alpha1"
+	trashv1alpha1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/trash/v1alpha1"
+	usagev1alpha1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/usage/v1alpha1"
 	metav1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1"
 	utilnet "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/util/net"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/endpoints/discovery"
@@ -95,6 +99,7 @@ import (
 	corerest "github.com/aaron-prindle/krmapiserver/pkg/registry/core/rest"
 	eventsrest "github.com/aaron-prindle/krmapiserver/pkg/registry/events/rest"
 	extensionsrest "github.com/aaron-prindle/krmapiserver/pkg/registry/extensions/rest"
+	migrationrest "github.com/aaron-prindle/krmapiserver/pkg/registry/migration/rest"
 	networkingrest "github.com/aaron-prindle/krmapiserver/pkg/registry/networking/rest"
 	noderest "github.com/aaron-prindle/krmapiserver/pkg/registry/node/rest"
 	policyrest "github.com/aaron-prindle/krmapiserver/pkg/registry/policy/rest"
@@ -102,6 +107,9 @@ import (
 	schedulingrest "github.com/aaron-prindle/krmapiserver/pkg/registry/scheduling/rest"
 	settingsrest "github.com/aaron-prindle/krmapiserver/pkg/registry/settings/rest"
 	storagerest "github.com/aaron-prindle/krmapiserver/pkg/registry/storage/rest"
+	transactionrest "github.com/aaron-prindle/krmapiserver/pkg/registry/transaction/rest"
+	trashrest "github.com/aaron-prindle/krmapiserver/pkg/registry/trash/rest"
+	usagerest "github.com/aaron-prindle/krmapiserver/pkg/registry/usage/rest"
 )
 
 const (
@@ -121,6 +129,12 @@ type ExtraConfig struct {
 	EventTTL                 time.Duration
 	KubeletClientConfig      kubeletclient.KubeletClientConfig
 
+	// EncryptionConfigPostStartHook, if set, polls the status of the KMS
+	// providers of the encryption provider configuration and, if automatic
+	// reload is enabled, reloads the configuration and re-encrypts stored
+	// objects when it changes.
+	EncryptionConfigPostStartHook genericapiserver.PostStartHookFunc
+
 	// Used to start and monitor tunneling
 	Tunneler          tunneler.Tunneler
 	EnableLogsSupport bool
@@ -354,6 +368,10 @@ func (c completedConfig) New(delegationTarget genericapiserver.DelegationTarget)
 		schedulingrest.RESTStorageProvider{},
 		settingsrest.RESTStorageProvider{},
 		storagerest.RESTStorageProvider{},
+		transactionrest.RESTStorageProvider{StorageRegistry: c.GenericConfig.StorageRegistry, Admission: c.GenericConfig.AdmissionControl, Authorizer: c.GenericConfig.Authorization.Authorizer},
+		migrationrest.RESTStorageProvider{},
+		usagerest.RESTStorageProvider{},
+		trashrest.RESTStorageProvider{},
 		// keep apps after extensions so legacy clients resolve the extensions versions of shared resource names.
 		// See https://github.com/kubernetes/kubernetes/issues/42392
 		appsrest.RESTStorageProvider{},
@@ -367,6 +385,9 @@ func (c completedConfig) New(delegationTarget genericapiserver.DelegationTarget)
 	}
 
 	m.GenericAPIServer.AddPostStartHookOrDie("ca-registration", c.ExtraConfig.ClientCARegistrationHook.PostStartHook)
+	if c.ExtraConfig.EncryptionConfigPostStartHook != nil {
+		m.GenericAPIServer.AddPostStartHookOrDie("start-encryption-provider-config-controllers", c.ExtraConfig.EncryptionConfigPostStartHook)
+	}
 
 	return m, nil
 }
@@ -529,11 +550,15 @@ func DefaultAPIResourceConfigSource() *serverstorage.ResourceConfig {
 	ret.DisableVersions(
 		auditregistrationv1alpha1.SchemeGroupVersion,
 		batchapiv2alpha1.SchemeGroupVersion,
+		migrationv1alpha1.SchemeGroupVersion,
 		nodev1alpha1.SchemeGroupVersion,
 		rbacv1alpha1.SchemeGroupVersion,
 		schedulingv1alpha1.SchemeGroupVersion,
 		settingsv1alpha1.SchemeGroupVersion,
 		storageapiv1alpha1.SchemeGroupVersion,
+		transactionv1alpha1.SchemeGroupVersion,
+		trashv1alpha1.SchemeGroupVersion,
+		usagev1alpha1.SchemeGroupVersion,
 	)
 
 	return ret
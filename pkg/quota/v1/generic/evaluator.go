@@ -17,6 +17,7 @@ limitations under the License.
 package generic
 
 import (
+	"encoding/json"
 	"fmt"
 	"sync/atomic"
 
@@ -26,6 +27,8 @@ import (
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime/schema"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/admission"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/rewrite"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/usage"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/client-go/informers"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/client-go/tools/cache"
 	quota "github.com/aaron-prindle/krmapiserver/pkg/quota/v1"
@@ -130,6 +133,15 @@ func ObjectCountQuotaResourceNameFor(groupResource schema.GroupResource) corev1.
 	return corev1.ResourceName("count/" + groupResource.Resource + "." + groupResource.Group)
 }
 
+// StorageBytesQuotaResourceNameFor returns the quota name of the bytes the
+// objects of the specified groupResource take in storage
+func StorageBytesQuotaResourceNameFor(groupResource schema.GroupResource) corev1.ResourceName {
+	if len(groupResource.Group) == 0 {
+		return corev1.ResourceName("bytes/" + groupResource.Resource)
+	}
+	return corev1.ResourceName("bytes/" + groupResource.Resource + "." + groupResource.Group)
+}
+
 // ListFuncByNamespace knows how to list resources in a namespace
 type ListFuncByNamespace func(namespace string) ([]runtime.Object, error)
 
@@ -230,7 +242,8 @@ func CalculateUsageStats(options quota.UsageStatsOptions,
 
 // objectCountEvaluator provides an implementation for quota.Evaluator
 // that associates usage of the specified resource based on the number of items
-// returned by the specified listing function.
+// returned by the specified listing function, and on the bytes they take in
+// storage.
 type objectCountEvaluator struct {
 	// GroupResource that this evaluator tracks.
 	// It is used to construct a generic object count quota name
@@ -240,6 +253,9 @@ type objectCountEvaluator struct {
 	listFuncByNamespace ListFuncByNamespace
 	// Names associated with this resource in the quota for generic counting.
 	resourceNames []corev1.ResourceName
+	// Name associated with this resource in the quota for the bytes its
+	// objects take in storage.
+	bytesResourceName corev1.ResourceName
 }
 
 // Constraints returns an error if the configured resource name is not in the required set.
@@ -249,9 +265,10 @@ func (o *objectCountEvaluator) Constraints(required []corev1.ResourceName, item
 }
 
 // Handles returns true if the object count evaluator needs to track this attributes.
+// Updates change the bytes the object takes in storage.
 func (o *objectCountEvaluator) Handles(a admission.Attributes) bool {
 	operation := a.GetOperation()
-	return operation == admission.Create
+	return operation == admission.Create || operation == admission.Update
 }
 
 // Matches returns true if the evaluator matches the specified quota with the provided input item
@@ -261,7 +278,7 @@ func (o *objectCountEvaluator) Matches(resourceQuota *corev1.ResourceQuota, item
 
 // MatchingResources takes the input specified list of resources and returns the set of resources it matches.
 func (o *objectCountEvaluator) MatchingResources(input []corev1.ResourceName) []corev1.ResourceName {
-	return quota.Intersection(input, o.resourceNames)
+	return quota.Intersection(input, append([]corev1.ResourceName{o.bytesResourceName}, o.resourceNames...))
 }
 
 // MatchingScopes takes the input specified list of scopes and input object. Returns the set of scopes resource matches.
@@ -275,31 +292,106 @@ func (o *objectCountEvaluator) UncoveredQuotaScopes(limitedScopes []corev1.Scope
 	return []corev1.ScopedResourceSelectorRequirement{}, nil
 }
 
-// Usage returns the resource usage for the specified object
+// Usage returns the resource usage for the specified object. The bytes it
+// takes in storage are those of its encoding for the storage, in the units
+// the storage accounts them in, or estimated by the size of its JSON encoding
+// if the storage does not account them.
 func (o *objectCountEvaluator) Usage(object runtime.Object) (corev1.ResourceList, error) {
+	if reporter, ok := o.accountingStorage(); ok {
+		if _, ok := reporter.NamespaceUsage(); ok {
+			if sizer, ok := reporter.(usage.Sizer); ok {
+				return o.usage(object, sizer.StoredSize)
+			}
+		}
+	}
+	return o.usage(object, estimatedSize)
+}
+
+// usage returns the resource usage for the specified object, whose size in
+// storage is returned by size.
+func (o *objectCountEvaluator) usage(object runtime.Object, size func(runtime.Object) (int64, error)) (corev1.ResourceList, error) {
 	quantity := resource.NewQuantity(1, resource.DecimalSI)
 	resourceList := corev1.ResourceList{}
 	for _, resourceName := range o.resourceNames {
 		resourceList[resourceName] = *quantity
 	}
+	bytes, err := size(object)
+	if err != nil {
+		return nil, err
+	}
+	resourceList[o.bytesResourceName] = *resource.NewQuantity(bytes, resource.BinarySI)
 	return resourceList, nil
 }
 
+// estimatedSize estimates the size of object in storage by the size of its
+// JSON encoding.
+func estimatedSize(object runtime.Object) (int64, error) {
+	data, err := json.Marshal(object)
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(data)), nil
+}
+
 // GroupResource tracked by this evaluator
 func (o *objectCountEvaluator) GroupResource() schema.GroupResource {
 	return o.groupResource
 }
 
-// UsageStats calculates aggregate usage for the object.
+// UsageStats calculates aggregate usage for the object. The bytes accounted
+// by the storage are preferred over the estimate of the listed objects.
 func (o *objectCountEvaluator) UsageStats(options quota.UsageStatsOptions) (quota.UsageStats, error) {
-	return CalculateUsageStats(options, o.listFuncByNamespace, MatchesNoScopeFunc, o.Usage)
+	// The listed objects are only estimated, as the bytes accounted by the
+	// storage replace their sum if they are known.
+	stats, err := CalculateUsageStats(options, o.listFuncByNamespace, MatchesNoScopeFunc, func(object runtime.Object) (corev1.ResourceList, error) {
+		return o.usage(object, estimatedSize)
+	})
+	if err != nil {
+		return stats, err
+	}
+	if used, ok := o.StorageUsage(options.Namespace); ok {
+		for resourceName := range stats.Used {
+			if quantity, found := used[resourceName]; found {
+				stats.Used[resourceName] = quantity
+			}
+		}
+	}
+	return stats, nil
+}
+
+// StorageUsage returns the bytes the objects take in storage in namespace, as
+// accounted by the storage of the resource if it is served by this process.
+func (o *objectCountEvaluator) StorageUsage(namespace string) (corev1.ResourceList, bool) {
+	reporter, ok := o.accountingStorage()
+	if !ok {
+		return nil, false
+	}
+	namespaces, ok := reporter.NamespaceUsage()
+	if !ok {
+		return nil, false
+	}
+	return corev1.ResourceList{
+		o.bytesResourceName: *resource.NewQuantity(namespaces[namespace].Bytes, resource.BinarySI),
+	}, true
+}
+
+// accountingStorage returns the storage of the resource if it is served by
+// this process and may account the usage of the objects.
+func (o *objectCountEvaluator) accountingStorage() (usage.Reporter, bool) {
+	registered := rewrite.Lookup(o.groupResource)
+	if registered == nil {
+		return nil, false
+	}
+	reporter, ok := registered.Storage.(usage.Reporter)
+	return reporter, ok
 }
 
 // Verify implementation of interface at compile time.
 var _ quota.Evaluator = &objectCountEvaluator{}
+var _ quota.StorageUsageEvaluator = &objectCountEvaluator{}
 
 // NewObjectCountEvaluator returns an evaluator that can perform generic
-// object quota counting.  It allows an optional alias for backwards compatibility
+// object quota counting, and limit the bytes the objects take in storage.  It allows an optional alias for backwards compatibility
 // purposes for the legacy object counting names in quota.  Unless its supporting
 // backward compatibility, alias should not be used.
 func NewObjectCountEvaluator(
@@ -315,5 +407,6 @@ func NewObjectCountEvaluator(
 		groupResource:       groupResource,
 		listFuncByNamespace: listFuncByNamespace,
 		resourceNames:       resourceNames,
+		bytesResourceName:   StorageBytesQuotaResourceNameFor(groupResource),
 	}
 }
@@ -17,12 +17,19 @@ limitations under the License.
 package generic
 
 import (
+	"encoding/json"
 	"errors"
 	"testing"
 
+	corev1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/core/v1"
+	metav1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/labels"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/rewrite"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/usage"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/client-go/tools/cache"
+	quota "github.com/aaron-prindle/krmapiserver/pkg/quota/v1"
 )
 
 func TestCachedHasSynced(t *testing.T) {
@@ -129,3 +136,87 @@ func (f *fakeLister) Get(name string) (runtime.Object, error) {
 func (f *fakeLister) ByNamespace(namespace string) cache.GenericNamespaceLister {
 	panic("not implemented")
 }
+
+// fakeUsageStorage reports a fixed usage.
+type fakeUsageStorage struct {
+	storage.Interface
+	namespaces map[string]usage.Usage
+}
+
+func (f *fakeUsageStorage) NamespaceUsage() (map[string]usage.Usage, bool) {
+	return f.namespaces, true
+}
+
+func (f *fakeUsageStorage) StoredSize(obj runtime.Object) (int64, error) {
+	return 42, nil
+}
+
+func TestObjectCountEvaluatorBytes(t *testing.T) {
+	gr := corev1.Resource("configmaps")
+	bytesName := corev1.ResourceName("bytes/configmaps")
+	if name := StorageBytesQuotaResourceNameFor(gr); name != bytesName {
+		t.Fatalf("expected %s, got %s", bytesName, name)
+	}
+
+	items := []runtime.Object{
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "a"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "b"}, Data: map[string]string{"key": "value"}},
+	}
+	var size int64
+	for _, item := range items {
+		data, err := json.Marshal(item)
+		if err != nil {
+			t.Fatal(err)
+		}
+		size += int64(len(data))
+	}
+	evaluator := NewObjectCountEvaluator(gr, func(string) ([]runtime.Object, error) { return items, nil }, "")
+	if matched := evaluator.MatchingResources([]corev1.ResourceName{bytesName, "cpu"}); len(matched) != 1 || matched[0] != bytesName {
+		t.Errorf("expected to match %s, got %v", bytesName, matched)
+	}
+
+	options := quota.UsageStatsOptions{Namespace: "ns", Resources: []corev1.ResourceName{bytesName}}
+	stats, err := evaluator.UsageStats(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if used := stats.Used[bytesName]; used.Value() != size {
+		t.Errorf("expected the estimated usage %d, got %s", size, used.String())
+	}
+	used, err := evaluator.Usage(items[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes := used[bytesName]; bytes.Value() != size-int64(len(mustMarshal(t, items[0]))) {
+		t.Errorf("expected the estimated size of the object, got %s", bytes.String())
+	}
+
+	resource := &rewrite.Resource{
+		GroupResource: gr,
+		Storage:       &fakeUsageStorage{namespaces: map[string]usage.Usage{"ns": {Objects: 2, Bytes: 100}}},
+	}
+	rewrite.Register(resource)
+	defer rewrite.Unregister(resource)
+	stats, err = evaluator.UsageStats(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if used := stats.Used[bytesName]; used.Value() != 100 {
+		t.Errorf("expected the accounted usage 100, got %s", used.String())
+	}
+	used, err = evaluator.Usage(items[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes := used[bytesName]; bytes.Value() != 42 {
+		t.Errorf("expected the stored size 42 of the object, got %s", bytes.String())
+	}
+}
+
+func mustMarshal(t *testing.T, obj runtime.Object) []byte {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
@@ -64,6 +64,15 @@ type Evaluator interface {
 	UsageStats(options UsageStatsOptions) (UsageStats, error)
 }
 
+// StorageUsageEvaluator is implemented by the evaluators that know the usage
+// of some resources in a namespace from the accounting of the storage, which
+// is more current than the usage recorded in the status of the quotas.
+type StorageUsageEvaluator interface {
+	// StorageUsage returns the usage of the resources accounted by the
+	// storage in namespace, or false if it is not known.
+	StorageUsage(namespace string) (corev1.ResourceList, bool)
+}
+
 // Configuration defines how the quota system is configured.
 type Configuration interface {
 	// IgnoredResources are ignored by quota.
@@ -292,6 +292,14 @@ func (r *REST) StorageVersion() runtime.GroupVersioner {
 	return r.store.StorageVersion()
 }
 
+var _ rest.TrashProvider = &REST{}
+
+// UndeleteStorage implements rest.TrashProvider, restoring namespaces
+// deleted when their finalization completed.
+func (r *REST) UndeleteStorage() rest.Storage {
+	return r.store.UndeleteStorage()
+}
+
 func (r *StatusREST) New() runtime.Object {
 	return r.store.New()
 }
@@ -89,7 +89,7 @@ func NewStorage(optsGetter generic.RESTOptionsGetter, kubeletClientConfig client
 
 		TableConvertor: printerstorage.TableConvertor{TableGenerator: printers.NewTableGenerator().With(printersinternal.AddHandlers)},
 	}
-	options := &generic.StoreOptions{RESTOptions: optsGetter, AttrFunc: node.GetAttrs, TriggerFunc: node.NodeNameTriggerFunc}
+	options := &generic.StoreOptions{RESTOptions: optsGetter, AttrFunc: node.GetAttrs, Indexers: node.Indexers()}
 	if err := store.CompleteWithOptions(options); err != nil {
 		return nil, err
 	}
@@ -35,6 +35,7 @@ import (
 	pkgstorage "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/names"
 	utilfeature "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/util/feature"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/client-go/tools/cache"
 	"github.com/aaron-prindle/krmapiserver/pkg/api/legacyscheme"
 	api "github.com/aaron-prindle/krmapiserver/pkg/apis/core"
 	"github.com/aaron-prindle/krmapiserver/pkg/apis/core/validation"
@@ -199,10 +200,20 @@ func MatchNode(label labels.Selector, field fields.Selector) pkgstorage.Selectio
 	}
 }
 
-func NodeNameTriggerFunc(obj runtime.Object) []pkgstorage.MatchValue {
-	node := obj.(*api.Node)
-	result := pkgstorage.MatchValue{IndexName: "metadata.name", Value: node.ObjectMeta.Name}
-	return []pkgstorage.MatchValue{result}
+// NameIndexFunc return value name of given object.
+func NameIndexFunc(obj interface{}) ([]string, error) {
+	node, ok := obj.(*api.Node)
+	if !ok {
+		return nil, fmt.Errorf("not a node")
+	}
+	return []string{node.Name}, nil
+}
+
+// Indexers returns the indexers for node storage.
+func Indexers() *cache.Indexers {
+	return &cache.Indexers{
+		pkgstorage.FieldIndex("metadata.name"): NameIndexFunc,
+	}
 }
 
 // ResourceLocation returns a URL and transport which one can use to send traffic for the specified node.
@@ -37,6 +37,7 @@ import (
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/registry/generic"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/names"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/client-go/tools/cache"
 	"github.com/aaron-prindle/krmapiserver/pkg/api/legacyscheme"
 	podutil "github.com/aaron-prindle/krmapiserver/pkg/api/pod"
 	api "github.com/aaron-prindle/krmapiserver/pkg/apis/core"
@@ -193,10 +194,21 @@ func MatchPod(label labels.Selector, field fields.Selector) storage.SelectionPre
 	}
 }
 
-func NodeNameTriggerFunc(obj runtime.Object) []storage.MatchValue {
-	pod := obj.(*api.Pod)
-	result := storage.MatchValue{IndexName: "spec.nodeName", Value: pod.Spec.NodeName}
-	return []storage.MatchValue{result}
+// NodeNameIndexFunc return value spec.nodeName of given object.
+func NodeNameIndexFunc(obj interface{}) ([]string, error) {
+	pod, ok := obj.(*api.Pod)
+	if !ok {
+		return nil, fmt.Errorf("not a pod")
+	}
+	return []string{pod.Spec.NodeName}, nil
+}
+
+// Indexers returns the indexers for pod storage.
+func Indexers() *cache.Indexers {
+	return &cache.Indexers{
+		storage.FieldIndex("spec.nodeName"): NodeNameIndexFunc,
+		storage.NamespaceIndex:              storage.NamespaceIndexFunc,
+	}
 }
 
 // PodToSelectableFields returns a field set that represents the object
@@ -29,6 +29,7 @@ import (
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/registry/rest"
 	pkgstorage "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage"
 	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/names"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/client-go/tools/cache"
 	"github.com/aaron-prindle/krmapiserver/pkg/api/legacyscheme"
 	api "github.com/aaron-prindle/krmapiserver/pkg/apis/core"
 	"github.com/aaron-prindle/krmapiserver/pkg/apis/core/validation"
@@ -116,10 +117,20 @@ func Matcher(label labels.Selector, field fields.Selector) pkgstorage.SelectionP
 	}
 }
 
-func SecretNameTriggerFunc(obj runtime.Object) []pkgstorage.MatchValue {
-	secret := obj.(*api.Secret)
-	result := pkgstorage.MatchValue{IndexName: "metadata.name", Value: secret.ObjectMeta.Name}
-	return []pkgstorage.MatchValue{result}
+// NameIndexFunc return value name of given object.
+func NameIndexFunc(obj interface{}) ([]string, error) {
+	secret, ok := obj.(*api.Secret)
+	if !ok {
+		return nil, fmt.Errorf("not a secret")
+	}
+	return []string{secret.Name}, nil
+}
+
+// Indexers returns the indexers for secret storage.
+func Indexers() *cache.Indexers {
+	return &cache.Indexers{
+		pkgstorage.FieldIndex("metadata.name"): NameIndexFunc,
+	}
 }
 
 // SelectableFields returns a field set that can be used for filter selection
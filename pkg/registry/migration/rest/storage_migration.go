@@ -0,0 +1,108 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"os"
+
+	migrationv1alpha1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/migration/v1alpha1"
+	metav1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/util/uuid"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/registry/generic"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/registry/rest"
+	genericapiserver "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/server"
+	serverstorage "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/server/storage"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/client-go/discovery"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/client-go/dynamic"
+	coordinationv1client "github.com/aaron-prindle/krmapiserver/included/k8s.io/client-go/kubernetes/typed/coordination/v1"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/client-go/tools/leaderelection/resourcelock"
+	"github.com/aaron-prindle/krmapiserver/pkg/api/legacyscheme"
+	"github.com/aaron-prindle/krmapiserver/pkg/controller/storageversionmigrator"
+	svmstorage "github.com/aaron-prindle/krmapiserver/pkg/registry/migration/storageversionmigration/storage"
+)
+
+const (
+	// PostStartHookName is the name of the hook that starts the storage
+	// version migrator.
+	PostStartHookName = "start-storage-version-migrator"
+	// LeaseName is the name of the lease in kube-system held by the apiserver
+	// running the storage version migrator.
+	LeaseName = "storage-version-migrator"
+)
+
+// RESTStorageProvider is a REST storage provider for migration.k8s.io
+type RESTStorageProvider struct{}
+
+var _ genericapiserver.PostStartHookProvider = RESTStorageProvider{}
+
+// NewRESTStorage returns a RESTStorageProvider
+func (p RESTStorageProvider) NewRESTStorage(apiResourceConfigSource serverstorage.APIResourceConfigSource, restOptionsGetter generic.RESTOptionsGetter) (genericapiserver.APIGroupInfo, bool) {
+	apiGroupInfo := genericapiserver.NewDefaultAPIGroupInfo(migrationv1alpha1.GroupName, legacyscheme.Scheme, legacyscheme.ParameterCodec, legacyscheme.Codecs)
+
+	if apiResourceConfigSource.VersionEnabled(migrationv1alpha1.SchemeGroupVersion) {
+		apiGroupInfo.VersionedResourcesStorageMap[migrationv1alpha1.SchemeGroupVersion.Version] = p.v1alpha1Storage(apiResourceConfigSource, restOptionsGetter)
+	}
+	return apiGroupInfo, true
+}
+
+func (p RESTStorageProvider) v1alpha1Storage(apiResourceConfigSource serverstorage.APIResourceConfigSource, restOptionsGetter generic.RESTOptionsGetter) map[string]rest.Storage {
+	storage := map[string]rest.Storage{}
+	s, status := svmstorage.NewREST(restOptionsGetter)
+	storage["storageversionmigrations"] = s
+	storage["storageversionmigrations/status"] = status
+
+	return storage
+}
+
+// GroupName is the group name for the storage provider
+func (p RESTStorageProvider) GroupName() string {
+	return migrationv1alpha1.GroupName
+}
+
+// PostStartHook starts the storage version migrator, which migrates the
+// objects of resources whose storage version changed.
+func (p RESTStorageProvider) PostStartHook() (string, genericapiserver.PostStartHookFunc, error) {
+	return PostStartHookName, func(hookContext genericapiserver.PostStartHookContext) error {
+		client, err := dynamic.NewForConfig(hookContext.LoopbackClientConfig)
+		if err != nil {
+			return err
+		}
+		discoveryClient, err := discovery.NewDiscoveryClientForConfig(hookContext.LoopbackClientConfig)
+		if err != nil {
+			return err
+		}
+		coordinationClient, err := coordinationv1client.NewForConfig(hookContext.LoopbackClientConfig)
+		if err != nil {
+			return err
+		}
+		hostname, err := os.Hostname()
+		if err != nil {
+			return err
+		}
+		// Every apiserver runs the migrator, but only the one holding the
+		// lease migrates.
+		lock := &resourcelock.LeaseLock{
+			LeaseMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceSystem, Name: LeaseName},
+			Client:    coordinationClient,
+			LockConfig: resourcelock.ResourceLockConfig{
+				Identity: hostname + "_" + string(uuid.NewUUID()),
+			},
+		}
+		go storageversionmigrator.NewController(client, discoveryClient, lock).Run(hookContext.StopCh)
+		return nil
+	}, nil
+}
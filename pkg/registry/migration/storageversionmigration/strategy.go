@@ -0,0 +1,99 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storageversionmigration
+
+import (
+	"context"
+
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/util/validation/field"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/names"
+	"github.com/aaron-prindle/krmapiserver/pkg/api/legacyscheme"
+	"github.com/aaron-prindle/krmapiserver/pkg/apis/migration"
+	"github.com/aaron-prindle/krmapiserver/pkg/apis/migration/validation"
+)
+
+// storageVersionMigrationStrategy implements verification logic for StorageVersionMigration.
+type storageVersionMigrationStrategy struct {
+	runtime.ObjectTyper
+	names.NameGenerator
+}
+
+// Strategy is the default logic that applies when creating and updating StorageVersionMigration objects.
+var Strategy = storageVersionMigrationStrategy{legacyscheme.Scheme, names.SimpleNameGenerator}
+
+// NamespaceScoped returns false because migrations are cluster scoped.
+func (storageVersionMigrationStrategy) NamespaceScoped() bool {
+	return false
+}
+
+// PrepareForCreate clears the status of a StorageVersionMigration before creation.
+func (storageVersionMigrationStrategy) PrepareForCreate(ctx context.Context, obj runtime.Object) {
+	svm := obj.(*migration.StorageVersionMigration)
+	svm.Status = migration.StorageVersionMigrationStatus{}
+}
+
+// PrepareForUpdate clears fields that are not allowed to be set by end users on update.
+func (storageVersionMigrationStrategy) PrepareForUpdate(ctx context.Context, obj, old runtime.Object) {
+	newSVM := obj.(*migration.StorageVersionMigration)
+	oldSVM := old.(*migration.StorageVersionMigration)
+	newSVM.Status = oldSVM.Status
+}
+
+// Validate validates a new StorageVersionMigration.
+func (storageVersionMigrationStrategy) Validate(ctx context.Context, obj runtime.Object) field.ErrorList {
+	return validation.ValidateStorageVersionMigration(obj.(*migration.StorageVersionMigration))
+}
+
+// Canonicalize normalizes the object after validation.
+func (storageVersionMigrationStrategy) Canonicalize(obj runtime.Object) {
+}
+
+// AllowCreateOnUpdate is false for StorageVersionMigration; this means you may not create one with a PUT request.
+func (storageVersionMigrationStrategy) AllowCreateOnUpdate() bool {
+	return false
+}
+
+// ValidateUpdate is the default update validation for an end user.
+func (storageVersionMigrationStrategy) ValidateUpdate(ctx context.Context, obj, old runtime.Object) field.ErrorList {
+	return validation.ValidateStorageVersionMigrationUpdate(obj.(*migration.StorageVersionMigration), old.(*migration.StorageVersionMigration))
+}
+
+// AllowUnconditionalUpdate is the default update policy for StorageVersionMigration objects.
+func (storageVersionMigrationStrategy) AllowUnconditionalUpdate() bool {
+	return false
+}
+
+type storageVersionMigrationStatusStrategy struct {
+	storageVersionMigrationStrategy
+}
+
+// StatusStrategy is the default logic invoked when updating the status of a StorageVersionMigration.
+var StatusStrategy = storageVersionMigrationStatusStrategy{Strategy}
+
+// PrepareForUpdate clears fields that are not allowed to be set by end users on update of status.
+func (storageVersionMigrationStatusStrategy) PrepareForUpdate(ctx context.Context, obj, old runtime.Object) {
+	newSVM := obj.(*migration.StorageVersionMigration)
+	oldSVM := old.(*migration.StorageVersionMigration)
+	// status changes are not allowed to update spec
+	newSVM.Spec = oldSVM.Spec
+}
+
+// ValidateUpdate is the default update validation for an end user updating status.
+func (storageVersionMigrationStatusStrategy) ValidateUpdate(ctx context.Context, obj, old runtime.Object) field.ErrorList {
+	return validation.ValidateStorageVersionMigrationStatusUpdate(obj.(*migration.StorageVersionMigration), old.(*migration.StorageVersionMigration))
+}
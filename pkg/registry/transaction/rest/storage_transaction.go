@@ -0,0 +1,62 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	transactionv1alpha1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/api/transaction/v1alpha1"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/admission"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/authorization/authorizer"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/registry/generic"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/registry/rest"
+	genericapiserver "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/server"
+	serverstorage "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/server/storage"
+	"github.com/aaron-prindle/krmapiserver/pkg/api/legacyscheme"
+	transactionapi "github.com/aaron-prindle/krmapiserver/pkg/apis/transaction"
+	"github.com/aaron-prindle/krmapiserver/pkg/registry/transaction/transaction"
+)
+
+type RESTStorageProvider struct {
+	// StorageRegistry holds the storages of the resources whose objects can
+	// be changed in transactions.
+	StorageRegistry *rest.StorageRegistry
+	Admission       admission.Interface
+	Authorizer      authorizer.Authorizer
+}
+
+func (p RESTStorageProvider) NewRESTStorage(apiResourceConfigSource serverstorage.APIResourceConfigSource, restOptionsGetter generic.RESTOptionsGetter) (genericapiserver.APIGroupInfo, bool) {
+	apiGroupInfo := genericapiserver.NewDefaultAPIGroupInfo(transactionapi.GroupName, legacyscheme.Scheme, legacyscheme.ParameterCodec, legacyscheme.Codecs)
+	// If you add a version here, be sure to add an entry in `k8s.io/kubernetes/cmd/kube-apiserver/app/aggregator.go with specific priorities.
+	// TODO refactor the plumbing to provide the information in the APIGroupInfo
+
+	if apiResourceConfigSource.VersionEnabled(transactionv1alpha1.SchemeGroupVersion) {
+		apiGroupInfo.VersionedResourcesStorageMap[transactionv1alpha1.SchemeGroupVersion.Version] = p.v1alpha1Storage(apiResourceConfigSource, restOptionsGetter)
+	}
+
+	return apiGroupInfo, true
+}
+
+func (p RESTStorageProvider) v1alpha1Storage(apiResourceConfigSource serverstorage.APIResourceConfigSource, restOptionsGetter generic.RESTOptionsGetter) map[string]rest.Storage {
+	storage := map[string]rest.Storage{}
+	// transactions
+	storage["transactions"] = transaction.NewREST(p.StorageRegistry, p.Admission, p.Authorizer)
+
+	return storage
+}
+
+func (p RESTStorageProvider) GroupName() string {
+	return transactionapi.GroupName
+}
@@ -0,0 +1,327 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transaction
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/api/errors"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/api/meta"
+	metav1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime/schema"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/admission"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/authentication/user"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/authorization/authorizer"
+	genericapirequest "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/endpoints/request"
+	genericregistry "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/registry/generic/registry"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/registry/rest"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/util/dryrun"
+	transactionapi "github.com/aaron-prindle/krmapiserver/pkg/apis/transaction"
+	"github.com/aaron-prindle/krmapiserver/pkg/apis/transaction/validation"
+)
+
+// transactionalStorage is implemented by the storages whose objects can be
+// changed in transactions, i.e. by the storages embedding a
+// genericregistry.Store.
+type transactionalStorage interface {
+	rest.Getter
+	GetCreateStrategy() rest.RESTCreateStrategy
+	PrepareCreate(ctx context.Context, obj runtime.Object, createValidation rest.ValidateObjectFunc) (*genericregistry.TxnOperation, error)
+	PrepareUpdate(ctx context.Context, name string, obj runtime.Object, updateValidation rest.ValidateObjectUpdateFunc) (*genericregistry.TxnOperation, error)
+	PrepareDelete(ctx context.Context, name string, deleteValidation rest.ValidateObjectFunc, options *metav1.DeleteOptions) (*genericregistry.TxnOperation, error)
+}
+
+// REST implements a RESTStorage for transactions. Transactions are not
+// persisted: creating one commits its operations.
+type REST struct {
+	storages   *rest.StorageRegistry
+	admission  admission.Interface
+	authorizer authorizer.Authorizer
+}
+
+// NewREST returns a RESTStorage committing transactions on the resources of
+// storages. The operations of the transactions are authorized by authorizer
+// and admitted by admission as if they were requests on their objects.
+func NewREST(storages *rest.StorageRegistry, admission admission.Interface, authorizer authorizer.Authorizer) *REST {
+	return &REST{
+		storages:   storages,
+		admission:  admission,
+		authorizer: authorizer,
+	}
+}
+
+func (r *REST) NamespaceScoped() bool {
+	return false
+}
+
+func (r *REST) New() runtime.Object {
+	return &transactionapi.Transaction{}
+}
+
+func (r *REST) Create(ctx context.Context, obj runtime.Object, createValidation rest.ValidateObjectFunc, options *metav1.CreateOptions) (runtime.Object, error) {
+	txn, ok := obj.(*transactionapi.Transaction)
+	if !ok {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("not a Transaction: %#v", obj))
+	}
+	if errs := validation.ValidateTransaction(txn); len(errs) > 0 {
+		return nil, apierrors.NewInvalid(transactionapi.Kind("Transaction"), txn.Name, errs)
+	}
+	if createValidation != nil {
+		if err := createValidation(obj.DeepCopyObject()); err != nil {
+			return nil, err
+		}
+	}
+	userInfo, ok := genericapirequest.UserFrom(ctx)
+	if !ok {
+		return nil, apierrors.NewBadRequest("no user present on request")
+	}
+	dryRun := dryrun.IsDryRun(options.DryRun)
+	admit := admission.WithAudit(r.admission, genericapirequest.AuditEventFrom(ctx))
+
+	ops := make([]*genericregistry.TxnOperation, len(txn.Spec.Operations))
+	storages := make([]*rest.RegisteredStorage, len(txn.Spec.Operations))
+	for i := range txn.Spec.Operations {
+		op, registered, err := r.prepareOperation(ctx, &txn.Spec.Operations[i], userInfo, admit, options.DryRun)
+		if err != nil {
+			return nil, operationError(i, err)
+		}
+		ops[i] = op
+		storages[i] = registered
+	}
+
+	outs, err := genericregistry.Transact(ctx, ops, dryRun)
+	if err != nil {
+		if opErr, ok := err.(*storage.TxnOpError); ok {
+			return nil, operationError(opErr.Index, opErr.Err)
+		}
+		return nil, err
+	}
+
+	txn.Status.Results = make([]transactionapi.OperationResult, len(outs))
+	for i, out := range outs {
+		op := &txn.Spec.Operations[i]
+		data, err := encodeObject(storages[i], schema.GroupVersion{Group: op.Group, Version: op.Version}, out)
+		if err != nil {
+			return nil, operationError(i, err)
+		}
+		txn.Status.Results[i].Object = runtime.RawExtension{Raw: data}
+	}
+	return txn, nil
+}
+
+// prepareOperation authorizes and admits op and returns the storage operation
+// committing it, together with the storage of its resource.
+func (r *REST) prepareOperation(ctx context.Context, op *transactionapi.Operation, userInfo user.Info, admit admission.Interface, dryRun []string) (*genericregistry.TxnOperation, *rest.RegisteredStorage, error) {
+	resource := schema.GroupVersionResource{Group: op.Group, Version: op.Version, Resource: op.Resource}
+	registered, err := r.storages.Storage(resource)
+	if err != nil {
+		return nil, nil, err
+	}
+	if registered == nil {
+		return nil, nil, apierrors.NewBadRequest(fmt.Sprintf("the server could not find the resource %v", resource))
+	}
+	txnStorage, ok := registered.Storage.(transactionalStorage)
+	if !ok {
+		return nil, nil, apierrors.NewBadRequest(fmt.Sprintf("%v cannot be changed in a transaction", resource.GroupResource()))
+	}
+	if registered.NamespaceScoped && len(op.Namespace) == 0 {
+		return nil, nil, apierrors.NewBadRequest(fmt.Sprintf("a namespace is required for %v", resource.GroupResource()))
+	}
+	if !registered.NamespaceScoped && len(op.Namespace) != 0 {
+		return nil, nil, apierrors.NewBadRequest(fmt.Sprintf("%v is not namespaced", resource.GroupResource()))
+	}
+
+	var obj runtime.Object
+	name := op.Name
+	if op.Type != transactionapi.OperationDelete {
+		if obj, err = decodeObject(registered, op.Object.Raw); err != nil {
+			return nil, nil, err
+		}
+		if op.Type == transactionapi.OperationCreate {
+			if err := fillObjectMeta(txnStorage.GetCreateStrategy(), obj, op.Name); err != nil {
+				return nil, nil, err
+			}
+		}
+		if name, err = checkObjectName(obj, op.Name); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	verb := map[transactionapi.OperationType]string{
+		transactionapi.OperationCreate: "create",
+		transactionapi.OperationUpdate: "update",
+		transactionapi.OperationDelete: "delete",
+	}[op.Type]
+	apiPrefix := "apis"
+	if len(op.Group) == 0 {
+		apiPrefix = "api"
+	}
+	// The storage reads the resource of the object from the request info,
+	// which must therefore be the one of an equivalent request.
+	ctx = genericapirequest.WithNamespace(ctx, op.Namespace)
+	ctx = genericapirequest.WithRequestInfo(ctx, &genericapirequest.RequestInfo{
+		IsResourceRequest: true,
+		Verb:              verb,
+		APIPrefix:         apiPrefix,
+		APIGroup:          op.Group,
+		APIVersion:        op.Version,
+		Namespace:         op.Namespace,
+		Resource:          op.Resource,
+		Name:              op.Name,
+	})
+	if err := r.authorize(userInfo, verb, resource, op.Namespace, op.Name); err != nil {
+		return nil, nil, err
+	}
+
+	switch op.Type {
+	case transactionapi.OperationCreate:
+		attrs := admission.NewAttributesRecord(obj, nil, registered.Kind, op.Namespace, name, resource, "", admission.Create, &metav1.CreateOptions{DryRun: dryRun}, dryrun.IsDryRun(dryRun), userInfo)
+		if mutatingAdmission, ok := admit.(admission.MutationInterface); ok && mutatingAdmission.Handles(admission.Create) {
+			if err := mutatingAdmission.Admit(attrs, registered.ObjectInterfaces); err != nil {
+				return nil, nil, err
+			}
+		}
+		txnOp, err := txnStorage.PrepareCreate(ctx, obj, rest.AdmissionToValidateObjectFunc(admit, attrs, registered.ObjectInterfaces))
+		return txnOp, registered, err
+
+	case transactionapi.OperationUpdate:
+		oldObj, err := txnStorage.Get(ctx, name, &metav1.GetOptions{})
+		if err != nil {
+			return nil, nil, err
+		}
+		attrs := admission.NewAttributesRecord(obj, oldObj, registered.Kind, op.Namespace, name, resource, "", admission.Update, &metav1.UpdateOptions{DryRun: dryRun}, dryrun.IsDryRun(dryRun), userInfo)
+		if mutatingAdmission, ok := admit.(admission.MutationInterface); ok && mutatingAdmission.Handles(admission.Update) {
+			if err := mutatingAdmission.Admit(attrs, registered.ObjectInterfaces); err != nil {
+				return nil, nil, err
+			}
+		}
+		txnOp, err := txnStorage.PrepareUpdate(ctx, name, obj, rest.AdmissionToValidateObjectUpdateFunc(admit, attrs, registered.ObjectInterfaces))
+		return txnOp, registered, err
+
+	default:
+		options := &metav1.DeleteOptions{Preconditions: op.Preconditions, DryRun: dryRun}
+		attrs := admission.NewAttributesRecord(nil, nil, registered.Kind, op.Namespace, name, resource, "", admission.Delete, options, dryrun.IsDryRun(dryRun), userInfo)
+		txnOp, err := txnStorage.PrepareDelete(ctx, name, rest.AdmissionToValidateObjectDeleteFunc(admit, attrs, registered.ObjectInterfaces), options)
+		return txnOp, registered, err
+	}
+}
+
+// authorize checks that the user may perform the request equivalent to an
+// operation.
+func (r *REST) authorize(userInfo user.Info, verb string, resource schema.GroupVersionResource, namespace, name string) error {
+	if r.authorizer == nil {
+		return nil
+	}
+	attrs := authorizer.AttributesRecord{
+		User:            userInfo,
+		Verb:            verb,
+		Namespace:       namespace,
+		APIGroup:        resource.Group,
+		APIVersion:      resource.Version,
+		Resource:        resource.Resource,
+		Name:            name,
+		ResourceRequest: true,
+	}
+	decision, reason, err := r.authorizer.Authorize(attrs)
+	if err != nil {
+		return apierrors.NewInternalError(err)
+	}
+	if decision == authorizer.DecisionAllow {
+		return nil
+	}
+	msg := fmt.Sprintf("User %q cannot %s resource %q in API group %q", userInfo.GetName(), verb, resource.Resource, resource.Group)
+	if len(namespace) > 0 {
+		msg += fmt.Sprintf(" in the namespace %q", namespace)
+	}
+	if len(reason) > 0 {
+		msg += ": " + reason
+	}
+	return apierrors.NewForbidden(resource.GroupResource(), name, fmt.Errorf("%s", msg))
+}
+
+// decodeObject decodes the object of an operation, which is encoded as JSON
+// in the version of the operation, into the internal version of its storage.
+func decodeObject(registered *rest.RegisteredStorage, data []byte) (runtime.Object, error) {
+	info, ok := runtime.SerializerInfoForMediaType(registered.Serializer.SupportedMediaTypes(), runtime.ContentTypeJSON)
+	if !ok {
+		return nil, fmt.Errorf("no JSON serializer for %v", registered.Kind)
+	}
+	decoder := registered.Serializer.DecoderToVersion(info.Serializer, registered.HubGroupVersion)
+	defaultGVK := registered.Kind
+	obj, gvk, err := decoder.Decode(data, &defaultGVK, registered.Storage.New())
+	if err != nil {
+		return nil, apierrors.NewBadRequest(err.Error())
+	}
+	if *gvk != defaultGVK {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("the kind of the object (%v) does not match the expected kind (%v)", *gvk, defaultGVK))
+	}
+	return obj, nil
+}
+
+// encodeObject encodes obj as JSON in version gv.
+func encodeObject(registered *rest.RegisteredStorage, gv schema.GroupVersion, obj runtime.Object) ([]byte, error) {
+	info, ok := runtime.SerializerInfoForMediaType(registered.Serializer.SupportedMediaTypes(), runtime.ContentTypeJSON)
+	if !ok {
+		return nil, fmt.Errorf("no JSON serializer for %v", registered.Kind)
+	}
+	return runtime.Encode(registered.Serializer.EncoderForVersion(info.Serializer, gv), obj)
+}
+
+// fillObjectMeta fills the system fields of the object of a creation and
+// generates its name unless the object or the operation has one, as
+// rest.BeforeCreate does, so that the name is known to admission.
+func fillObjectMeta(strategy rest.RESTCreateStrategy, obj runtime.Object, name string) error {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return apierrors.NewBadRequest(err.Error())
+	}
+	rest.FillObjectMetaSystemFields(accessor)
+	if len(accessor.GetGenerateName()) > 0 && len(accessor.GetName()) == 0 && len(name) == 0 {
+		accessor.SetName(strategy.GenerateName(accessor.GetGenerateName()))
+	}
+	return nil
+}
+
+// checkObjectName returns the name of the object of an operation, which
+// defaults to the name of the operation.
+func checkObjectName(obj runtime.Object, name string) (string, error) {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return "", apierrors.NewBadRequest(err.Error())
+	}
+	if len(accessor.GetName()) == 0 {
+		accessor.SetName(name)
+		return name, nil
+	}
+	if len(name) > 0 && accessor.GetName() != name {
+		return "", apierrors.NewBadRequest(fmt.Sprintf("the name of the object (%s) does not match the name of the operation (%s)", accessor.GetName(), name))
+	}
+	return accessor.GetName(), nil
+}
+
+// operationError returns err as the error of the operation at index.
+func operationError(index int, err error) error {
+	if status, ok := err.(apierrors.APIStatus); ok {
+		s := status.Status()
+		s.Message = fmt.Sprintf("operation %d: %s", index, s.Message)
+		return &apierrors.StatusError{ErrStatus: s}
+	}
+	return fmt.Errorf("operation %d: %v", index, err)
+}
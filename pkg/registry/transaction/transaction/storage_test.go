@@ -0,0 +1,53 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transaction
+
+import (
+	"strings"
+	"testing"
+
+	metav1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1"
+	api "github.com/aaron-prindle/krmapiserver/pkg/apis/core"
+	"github.com/aaron-prindle/krmapiserver/pkg/registry/core/pod"
+)
+
+func TestFillObjectMeta(t *testing.T) {
+	// the name of a creation with generateName is known before admission
+	obj := &api.Pod{ObjectMeta: metav1.ObjectMeta{GenerateName: "foo-"}}
+	if err := fillObjectMeta(pod.Strategy, obj, ""); err != nil {
+		t.Fatal(err)
+	}
+	name, err := checkObjectName(obj, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(name, "foo-") || len(name) == len("foo-") || obj.Name != name {
+		t.Errorf("expected a generated name, got %q", name)
+	}
+	if len(obj.UID) == 0 || obj.CreationTimestamp.IsZero() {
+		t.Errorf("expected the system fields to be filled, got %#v", obj.ObjectMeta)
+	}
+
+	// the name of the operation takes precedence
+	obj = &api.Pod{ObjectMeta: metav1.ObjectMeta{GenerateName: "foo-"}}
+	if err := fillObjectMeta(pod.Strategy, obj, "bar"); err != nil {
+		t.Fatal(err)
+	}
+	if name, err := checkObjectName(obj, "bar"); err != nil || name != "bar" {
+		t.Errorf("expected the name of the operation, got %q: %v", name, err)
+	}
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deletedobject
+
+import (
+	"context"
+
+	apierrors "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/api/errors"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/api/meta"
+	metainternalversion "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1"
+	metav1beta1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1beta1"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/labels"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
+	genericregistry "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/registry/generic/registry"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/registry/rest"
+	"github.com/aaron-prindle/krmapiserver/pkg/apis/trash"
+)
+
+// REST serves the objects kept in the trash of the resources whose stores
+// keep their deleted objects.
+type REST struct {
+	tombstones     func(ctx context.Context) ([]genericregistry.Tombstone, error)
+	tableConvertor rest.TableConvertor
+}
+
+var _ rest.Getter = &REST{}
+var _ rest.Lister = &REST{}
+var _ rest.Scoper = &REST{}
+
+// NewREST returns a RESTStorage object that will work against the trash of
+// the served resources.
+func NewREST() *REST {
+	return &REST{
+		tombstones:     genericregistry.Tombstones,
+		tableConvertor: rest.NewDefaultTableConvertor(trash.Resource("deletedobjects")),
+	}
+}
+
+// NamespaceScoped returns false because the deleted objects of all
+// resources, namespaced or not, are served together.
+func (*REST) NamespaceScoped() bool {
+	return false
+}
+
+// New returns a new DeletedObject.
+func (*REST) New() runtime.Object {
+	return &trash.DeletedObject{}
+}
+
+// NewList returns a new DeletedObjectList.
+func (*REST) NewList() runtime.Object {
+	return &trash.DeletedObjectList{}
+}
+
+// Get returns the deleted object whose UID is name.
+func (r *REST) Get(ctx context.Context, name string, options *metav1.GetOptions) (runtime.Object, error) {
+	items, err := r.list(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range items {
+		if items[i].Name == name {
+			return &items[i], nil
+		}
+	}
+	return nil, apierrors.NewNotFound(trash.Resource("deletedobjects"), name)
+}
+
+// List returns the deleted objects of all resources, by resource and most
+// recently deleted first. The label selector applies to the labels of the
+// deleted objects, field selectors are not supported.
+func (r *REST) List(ctx context.Context, options *metainternalversion.ListOptions) (runtime.Object, error) {
+	items, err := r.list(ctx)
+	if err != nil {
+		return nil, err
+	}
+	label := labels.Everything()
+	if options != nil && options.LabelSelector != nil {
+		label = options.LabelSelector
+	}
+	list := &trash.DeletedObjectList{}
+	for _, item := range items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, nil
+}
+
+// ConvertToTable implements rest.TableConvertor.
+func (r *REST) ConvertToTable(ctx context.Context, object runtime.Object, tableOptions runtime.Object) (*metav1beta1.Table, error) {
+	return r.tableConvertor.ConvertToTable(ctx, object, tableOptions)
+}
+
+func (r *REST) list(ctx context.Context) ([]trash.DeletedObject, error) {
+	tombstones, err := r.tombstones(ctx)
+	if err != nil {
+		return nil, apierrors.NewInternalError(err)
+	}
+	items := make([]trash.DeletedObject, 0, len(tombstones))
+	for _, tombstone := range tombstones {
+		item, err := newDeletedObject(tombstone)
+		if err != nil {
+			return nil, apierrors.NewInternalError(err)
+		}
+		items = append(items, *item)
+	}
+	return items, nil
+}
+
+func newDeletedObject(tombstone genericregistry.Tombstone) (*trash.DeletedObject, error) {
+	accessor, err := meta.Accessor(tombstone.Object)
+	if err != nil {
+		return nil, err
+	}
+	return &trash.DeletedObject{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   string(accessor.GetUID()),
+			Labels: accessor.GetLabels(),
+		},
+		Resource: trash.GroupResource{Group: tombstone.Resource.Group, Resource: tombstone.Resource.Resource},
+		Object: trash.ObjectReference{
+			Namespace: accessor.GetNamespace(),
+			Name:      accessor.GetName(),
+			UID:       accessor.GetUID(),
+		},
+		DeletedBy: tombstone.DeletedBy,
+		DeletedAt: metav1.NewTime(tombstone.DeletedAt),
+		PurgeAt:   metav1.NewTime(tombstone.PurgeAt),
+	}, nil
+}
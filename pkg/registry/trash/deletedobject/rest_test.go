@@ -0,0 +1,104 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deletedobject
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	apierrors "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/api/errors"
+	metainternalversion "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/labels"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime/schema"
+	genericapirequest "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/endpoints/request"
+	genericregistry "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/registry/generic/registry"
+	"github.com/aaron-prindle/krmapiserver/pkg/apis/core"
+	"github.com/aaron-prindle/krmapiserver/pkg/apis/trash"
+)
+
+func TestGetAndList(t *testing.T) {
+	deletedAt := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+	tombstones := []genericregistry.Tombstone{
+		{
+			Resource: schema.GroupResource{Resource: "configmaps"},
+			Object: &core.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+				Namespace: "ns1", Name: "config", UID: "uid-1", Labels: map[string]string{"app": "web"},
+			}},
+			DeletedBy: "alice",
+			DeletedAt: deletedAt,
+			PurgeAt:   deletedAt.Add(time.Hour),
+		},
+		{
+			Resource:  schema.GroupResource{Resource: "namespaces"},
+			Object:    &core.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns2", UID: "uid-2"}},
+			DeletedAt: deletedAt,
+			PurgeAt:   deletedAt.Add(time.Hour),
+		},
+	}
+	r := NewREST()
+	r.tombstones = func(ctx context.Context) ([]genericregistry.Tombstone, error) {
+		return tombstones, nil
+	}
+	ctx := genericapirequest.NewContext()
+
+	obj, err := r.Get(ctx, "uid-1", &metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &trash.DeletedObject{
+		ObjectMeta: metav1.ObjectMeta{Name: "uid-1", Labels: map[string]string{"app": "web"}},
+		Resource:   trash.GroupResource{Resource: "configmaps"},
+		Object:     trash.ObjectReference{Namespace: "ns1", Name: "config", UID: "uid-1"},
+		DeletedBy:  "alice",
+		DeletedAt:  metav1.NewTime(deletedAt),
+		PurgeAt:    metav1.NewTime(deletedAt.Add(time.Hour)),
+	}
+	if !reflect.DeepEqual(obj, expected) {
+		t.Errorf("expected %#v, got %#v", expected, obj)
+	}
+	if _, err := r.Get(ctx, "uid-3", &metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected uid-3 not to be found, got %v", err)
+	}
+
+	for _, test := range []struct {
+		selector      string
+		expectedNames []string
+	}{
+		{selector: "", expectedNames: []string{"uid-1", "uid-2"}},
+		{selector: "app=web", expectedNames: []string{"uid-1"}},
+		{selector: "app=db", expectedNames: nil},
+	} {
+		selector, err := labels.Parse(test.selector)
+		if err != nil {
+			t.Fatal(err)
+		}
+		obj, err := r.List(ctx, &metainternalversion.ListOptions{LabelSelector: selector})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, item := range obj.(*trash.DeletedObjectList).Items {
+			names = append(names, item.Name)
+		}
+		if !reflect.DeepEqual(names, test.expectedNames) {
+			t.Errorf("selector %q: expected %v, got %v", test.selector, test.expectedNames, names)
+		}
+	}
+}
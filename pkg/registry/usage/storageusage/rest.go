@@ -0,0 +1,138 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storageusage
+
+import (
+	"context"
+	"sort"
+
+	apierrors "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/api/errors"
+	metainternalversion "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1"
+	metav1beta1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1beta1"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime/schema"
+	genericapirequest "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/endpoints/request"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/registry/rest"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/rewrite"
+	storageusage "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/usage"
+	"github.com/aaron-prindle/krmapiserver/pkg/apis/usage"
+)
+
+// REST serves the usage of the resources whose storage accounts it, as
+// registered for rewriting.
+type REST struct {
+	tableConvertor rest.TableConvertor
+}
+
+var _ rest.Getter = &REST{}
+var _ rest.Lister = &REST{}
+var _ rest.Scoper = &REST{}
+
+// NewREST returns a RESTStorage object that will work against the usage
+// accounted by the storage of the served resources.
+func NewREST() *REST {
+	return &REST{
+		tableConvertor: rest.NewDefaultTableConvertor(usage.Resource("storageusages")),
+	}
+}
+
+// NamespaceScoped returns true because the usage is accounted per namespace.
+func (*REST) NamespaceScoped() bool {
+	return true
+}
+
+// New returns a new StorageUsage.
+func (*REST) New() runtime.Object {
+	return &usage.StorageUsage{}
+}
+
+// NewList returns a new StorageUsageList.
+func (*REST) NewList() runtime.Object {
+	return &usage.StorageUsageList{}
+}
+
+// Get returns the usage of the resource name, e.g. "deployments.apps", in the
+// namespace of the request. It is not found if the usage of the resource is
+// not accounted, or not known yet.
+func (r *REST) Get(ctx context.Context, name string, options *metav1.GetOptions) (runtime.Object, error) {
+	namespace := genericapirequest.NamespaceValue(ctx)
+	gr := schema.ParseGroupResource(name)
+	namespaces, ok := namespaceUsage(gr)
+	if !ok {
+		return nil, apierrors.NewNotFound(usage.Resource("storageusages"), name)
+	}
+	return newStorageUsage(gr, namespace, namespaces[namespace]), nil
+}
+
+// List returns the usage of all resources in the namespace of the request, or
+// in all namespaces. The namespaces without objects of a resource are left
+// out, as are the resources whose usage is not known yet. Label and field
+// selectors are not supported.
+func (r *REST) List(ctx context.Context, options *metainternalversion.ListOptions) (runtime.Object, error) {
+	namespace := genericapirequest.NamespaceValue(ctx)
+	list := &usage.StorageUsageList{}
+	for _, gr := range rewrite.Resources() {
+		namespaces, ok := namespaceUsage(gr)
+		if !ok {
+			continue
+		}
+		for ns, u := range namespaces {
+			// The usage of cluster-scoped resources is accounted under the
+			// empty namespace.
+			if len(ns) == 0 || (len(namespace) > 0 && ns != namespace) {
+				continue
+			}
+			list.Items = append(list.Items, *newStorageUsage(gr, ns, u))
+		}
+	}
+	sort.Slice(list.Items, func(i, j int) bool {
+		if list.Items[i].Namespace != list.Items[j].Namespace {
+			return list.Items[i].Namespace < list.Items[j].Namespace
+		}
+		return list.Items[i].Name < list.Items[j].Name
+	})
+	return list, nil
+}
+
+// ConvertToTable implements rest.TableConvertor.
+func (r *REST) ConvertToTable(ctx context.Context, object runtime.Object, tableOptions runtime.Object) (*metav1beta1.Table, error) {
+	return r.tableConvertor.ConvertToTable(ctx, object, tableOptions)
+}
+
+// namespaceUsage returns the usage of gr per namespace, or false if it is not
+// known.
+func namespaceUsage(gr schema.GroupResource) (map[string]storageusage.Usage, bool) {
+	resource := rewrite.Lookup(gr)
+	if resource == nil {
+		return nil, false
+	}
+	reporter, ok := resource.Storage.(storageusage.Reporter)
+	if !ok {
+		return nil, false
+	}
+	return reporter.NamespaceUsage()
+}
+
+func newStorageUsage(gr schema.GroupResource, namespace string, u storageusage.Usage) *usage.StorageUsage {
+	return &usage.StorageUsage{
+		ObjectMeta: metav1.ObjectMeta{Name: gr.String(), Namespace: namespace},
+		Resource:   usage.GroupResource{Group: gr.Group, Resource: gr.Resource},
+		Objects:    u.Objects,
+		Bytes:      u.Bytes,
+	}
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storageusage
+
+import (
+	"reflect"
+	"testing"
+
+	apierrors "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/api/errors"
+	metav1 "github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/apis/meta/v1"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apimachinery/pkg/runtime/schema"
+	genericapirequest "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/endpoints/request"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage"
+	"github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/rewrite"
+	storageusage "github.com/aaron-prindle/krmapiserver/included/k8s.io/apiserver/pkg/storage/usage"
+	"github.com/aaron-prindle/krmapiserver/pkg/apis/usage"
+)
+
+// fakeStorage reports a fixed usage.
+type fakeStorage struct {
+	storage.Interface
+	namespaces map[string]storageusage.Usage
+}
+
+func (f *fakeStorage) NamespaceUsage() (map[string]storageusage.Usage, bool) {
+	return f.namespaces, f.namespaces != nil
+}
+
+func TestGetAndList(t *testing.T) {
+	resources := []*rewrite.Resource{
+		{
+			GroupResource: schema.GroupResource{Resource: "configmaps"},
+			Storage: &fakeStorage{namespaces: map[string]storageusage.Usage{
+				"ns1": {Objects: 2, Bytes: 200},
+				"ns2": {Objects: 1, Bytes: 50},
+			}},
+		},
+		{
+			GroupResource: schema.GroupResource{Group: "apps", Resource: "deployments"},
+			Storage:       &fakeStorage{namespaces: map[string]storageusage.Usage{"ns1": {Objects: 1, Bytes: 300}}},
+		},
+		{
+			GroupResource: schema.GroupResource{Resource: "nodes"},
+			Storage:       &fakeStorage{namespaces: map[string]storageusage.Usage{"": {Objects: 3, Bytes: 900}}},
+		},
+		{
+			GroupResource: schema.GroupResource{Resource: "secrets"},
+			Storage:       &fakeStorage{},
+		},
+	}
+	for _, resource := range resources {
+		rewrite.Register(resource)
+		defer rewrite.Unregister(resource)
+	}
+	r := NewREST()
+	ctx := genericapirequest.WithNamespace(genericapirequest.NewContext(), "ns1")
+
+	obj, err := r.Get(ctx, "deployments.apps", &metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := newStorageUsage(schema.GroupResource{Group: "apps", Resource: "deployments"}, "ns1", storageusage.Usage{Objects: 1, Bytes: 300})
+	if !reflect.DeepEqual(obj, expected) {
+		t.Errorf("expected %#v, got %#v", expected, obj)
+	}
+	if _, err := r.Get(ctx, "secrets", &metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected the usage of secrets not to be found, got %v", err)
+	}
+
+	obj, err = r.List(genericapirequest.NewContext(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, item := range obj.(*usage.StorageUsageList).Items {
+		names = append(names, item.Namespace+"/"+item.Name)
+	}
+	expectedNames := []string{"ns1/configmaps", "ns1/deployments.apps", "ns2/configmaps"}
+	if !reflect.DeepEqual(names, expectedNames) {
+		t.Errorf("expected %v, got %v", expectedNames, names)
+	}
+}
@@ -213,6 +213,8 @@ func (e *quotaEvaluator) checkAttributes(ns string, admissionAttributes []*admis
 //    and recurse into this method with the subset.  It's safe for us to evaluate ONLY the subset, because the other quota
 //    documents for these waiters have already been evaluated.  Step 1, will mark all the ones that should already have succeeded.
 func (e *quotaEvaluator) checkQuotas(quotas []corev1.ResourceQuota, admissionAttributes []*admissionWaiter, remainingRetries int) {
+	quotas = e.withStorageUsage(quotas, admissionAttributes)
+
 	// yet another copy to compare against originals to see if we actually have deltas
 	originalQuotas, err := copyQuotas(quotas)
 	if err != nil {
@@ -337,6 +339,54 @@ func copyQuotas(in []corev1.ResourceQuota) ([]corev1.ResourceQuota, error) {
 	return out, nil
 }
 
+// withStorageUsage returns quotas with their usage raised to the usage that
+// the evaluators of the admissionAttributes know from the accounting of the
+// storage, which may be more current than the usage in their status. The usage
+// in their status is never lowered, as it holds the usage reserved by the
+// requests that were admitted but are not stored yet.
+func (e *quotaEvaluator) withStorageUsage(quotas []corev1.ResourceQuota, admissionAttributes []*admissionWaiter) []corev1.ResourceQuota {
+	if len(quotas) == 0 {
+		return quotas
+	}
+	out := quotas
+	copied := false
+	seen := map[schema.GroupResource]bool{}
+	for _, admissionAttribute := range admissionAttributes {
+		gr := admissionAttribute.attributes.GetResource().GroupResource()
+		if seen[gr] {
+			continue
+		}
+		seen[gr] = true
+		evaluator, ok := e.registry.Get(gr).(quota.StorageUsageEvaluator)
+		if !ok {
+			continue
+		}
+		used, ok := evaluator.StorageUsage(quotas[0].Namespace)
+		if !ok {
+			continue
+		}
+		for i := range out {
+			for resourceName, quantity := range used {
+				if _, found := out[i].Status.Hard[resourceName]; !found {
+					continue
+				}
+				if current, found := out[i].Status.Used[resourceName]; found && current.Cmp(quantity) >= 0 {
+					continue
+				}
+				if !copied {
+					out, _ = copyQuotas(quotas)
+					copied = true
+				}
+				if out[i].Status.Used == nil {
+					out[i].Status.Used = corev1.ResourceList{}
+				}
+				out[i].Status.Used[resourceName] = quantity
+			}
+		}
+	}
+	return out
+}
+
 // filterLimitedResourcesByGroupResource filters the input that match the specified groupResource
 func filterLimitedResourcesByGroupResource(input []resourcequotaapi.LimitedResource, groupResource schema.GroupResource) []resourcequotaapi.LimitedResource {
 	result := []resourcequotaapi.LimitedResource{}